@@ -4,8 +4,10 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"golang-dining-ordering/config"
 	"golang-dining-ordering/internal/routes"
+	"golang-dining-ordering/pkg/encryption"
 	"golang-dining-ordering/pkg/middleware"
 	authHandler "golang-dining-ordering/services/auth/handler"
 	authRepo "golang-dining-ordering/services/auth/repository"
@@ -16,15 +18,21 @@ import (
 	mngRoutes "golang-dining-ordering/services/management/routes"
 	mngServices "golang-dining-ordering/services/management/services"
 	mngStorage "golang-dining-ordering/services/management/storage"
+	"golang-dining-ordering/services/orders/events"
 	ordersHandlers "golang-dining-ordering/services/orders/handlers"
+	"golang-dining-ordering/services/orders/mailer"
 	"golang-dining-ordering/services/orders/paymentproviders"
 	ordersRepo "golang-dining-ordering/services/orders/repository"
 	ordersRoutes "golang-dining-ordering/services/orders/routes"
 	ordersServices "golang-dining-ordering/services/orders/services"
+	"golang-dining-ordering/services/orders/sessions"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	authDB "golang-dining-ordering/services/auth/db/generated"
 	managementDB "golang-dining-ordering/services/management/db/generated"
@@ -57,25 +65,48 @@ func main() {
 	routes.AddSwaggerRoutes(e)
 	routes.AddFrontendRoutes(e)
 
-	setupAuth(e, &cfg, logger)
-	setupManagement(e, &cfg, logger)
-	setupOrders(e, &cfg, logger)
+	// deploys stop the server with SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := newApp()
+
+	setupAuth(e, &cfg, logger, app)
+	setupManagement(e, &cfg, logger, app)
+	setupOrders(e, &cfg, logger, app)
 
 	logger.Info("starting server on address " + cfg.HTTPAddress)
 
-	err = e.Start(cfg.HTTPAddress)
-	if err != nil {
-		logger.Error("server stopped", "error", err)
-	}
+	go func() {
+		err := e.Start(cfg.HTTPAddress)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server stopped", "error", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	logger.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second,
+	)
+	defer cancel()
+
+	app.shutdown(shutdownCtx, e, logger)
 }
 
-func setupAuth(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
+func setupAuth(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger, app *app) {
 	authConn, err := sql.Open("postgres", cfg.AuthDBURI)
 	if err != nil {
 		logger.Error("failed to prepare database connection", "error", err)
 		os.Exit(1)
 	}
 
+	app.closeOnShutdown(authConn)
+
 	err = authConn.PingContext(context.Background())
 	if err != nil {
 		logger.Error("failed to connect to auth database", "error", err)
@@ -98,13 +129,15 @@ func setupAuth(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
 	authRoutes.AddRoutes(context.Background(), e, authHandler)
 }
 
-func setupManagement(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
+func setupManagement(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger, app *app) {
 	db, err := sql.Open("postgres", cfg.ManagementDBURI)
 	if err != nil {
 		logger.Error("failed to prepare database connection", "error", err)
 		os.Exit(1)
 	}
 
+	app.closeOnShutdown(db)
+
 	err = db.PingContext(context.Background())
 	if err != nil {
 		logger.Error("failed to connect to management database", "error", err)
@@ -122,6 +155,7 @@ func setupManagement(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
 	menuRepo := mngRepos.NewMenuRepository(db, queries)
 	// storage := mngStorage.NewLocalStorage(cfg.MaxImageSizeBytes, cfg.UploadsDirectory)
 	storage := mngStorage.GetStorage(context.Background(), cfg.StorageType, cfg)
+	app.closeOnShutdown(storage)
 	menuSvc := mngServices.NewMenuService(menuRepo, restRepo, storage)
 	menuHandler := mngHandlers.NewMenuHandler(menuSvc)
 
@@ -132,13 +166,15 @@ func setupManagement(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
 	mngRoutes.AddMenuRoutes(e, menuHandler, cfg.AuthorizeEndpoint)
 }
 
-func setupOrders(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
+func setupOrders(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger, app *app) {
 	db, err := sql.Open("postgres", cfg.ManagementDBURI)
 	if err != nil {
 		logger.Error("failed to prepare orders db connection", "error", err)
 		os.Exit(1)
 	}
 
+	app.closeOnShutdown(db)
+
 	err = db.PingContext(context.Background())
 	if err != nil {
 		logger.Error("failed to connect to orders database", "error", err)
@@ -150,17 +186,108 @@ func setupOrders(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
 	queries := ordersDB.New(db)
 
 	ordRepo := ordersRepo.NewOrdersRepo(queries)
-	paymentsRepo := ordersRepo.NewPaymentsRepo(queries)
-	ordersSvc := ordersServices.NewOrdersService(ordRepo)
+	paymentsRepo := ordersRepo.NewPaymentsRepo(db, queries)
+	tipsRepo := ordersRepo.NewTipsRepo(queries)
+	promotionsRepo := ordersRepo.NewPromotionsRepo(db, queries)
+
+	orderEvents, err := events.GetPubSub(&cfg.WebsocketConfig, db, cfg.ManagementDBURI, logger)
+	if err != nil {
+		logger.Error("failed to prepare order events pub/sub", "error", err)
+		os.Exit(1)
+	}
+
+	tableSessions, err := sessions.NewTableSessions(
+		cfg.TableSessionConfig.Secret,
+		time.Duration(cfg.TableSessionConfig.TTLSeconds)*time.Second,
+	)
+	if err != nil {
+		logger.Error("failed to prepare table sessions", "error", err)
+		os.Exit(1)
+	}
+
+	ordersSvc := ordersServices.NewOrdersService(
+		ordRepo,
+		tipsRepo,
+		promotionsRepo,
+		orderEvents,
+		tableSessions,
+	)
 	ordersHandler := ordersHandlers.NewOrdersHandler(ordersSvc)
-	websocketHandler := ordersHandlers.NewWebsocketHandler(ordersSvc, &cfg.WebsocketConfig, logger)
+	assistanceSvc := ordersServices.NewAssistanceService(
+		ordRepo,
+		ordersRepo.NewAssistanceRepo(db, queries),
+		orderEvents,
+		tableSessions,
+	)
+	websocketHandler := ordersHandlers.NewWebsocketHandler(
+		ordersSvc,
+		assistanceSvc,
+		orderEvents,
+		&cfg.WebsocketConfig,
+		logger,
+	)
 
-	paymentsProvider := paymentproviders.NewStripePaymentProvider(
-		cfg.StripeSecretKey,
-		cfg.StripeWebhookSecret,
+	app.drainOnShutdown(websocketHandler)
+
+	go func() {
+		err := websocketHandler.Run(app.ctx)
+		if err != nil {
+			logger.Error("failed to broadcast order events", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	staffFeedHandler := ordersHandlers.NewStaffFeedHandler(
+		ordersServices.NewStaffFeedService(ordRepo),
+		orderEvents,
+		&cfg.WebsocketConfig,
+		logger,
+	)
+
+	app.drainOnShutdown(staffFeedHandler)
+
+	go func() {
+		err := staffFeedHandler.Run(app.ctx)
+		if err != nil {
+			logger.Error("failed to deliver staff events", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	cipher, err := encryption.NewCipher(cfg.PaymentsConfig.EncryptionKey)
+	if err != nil {
+		logger.Error("failed to prepare payment credentials encryption", "error", err)
+		os.Exit(1)
+	}
+
+	providersRepo := ordersRepo.NewPaymentProvidersRepo(db, queries, cipher)
+
+	platformProvider := paymentproviders.GetPlatformProvider(cfg)
+	providersRegistry := paymentproviders.NewRegistry(
+		providersRepo,
+		&paymentproviders.RegistryConfig{
+			WebhooksURL:  cfg.PaymentsConfig.WebhooksURL,
+			KlixAPIURL:   cfg.KlixConfig.APIURL,
+			StripeAPIURL: cfg.StripeAPIURL,
+		},
+		platformProvider,
+		ordersDB.OrdersPaymentProvider(cfg.PaymentsConfig.PlatformProvider),
+	)
+
+	paymentsSvc := ordersServices.NewPaymentsService(
+		ordRepo,
+		paymentsRepo,
+		providersRegistry,
+		orderEvents,
+		tableSessions,
 	)
-	paymentsSvc := ordersServices.NewPaymentsService(ordRepo, paymentsRepo, paymentsProvider)
 	paymentsHandler := ordersHandlers.NewPaymentsHandler(paymentsSvc)
+	providersSvc := ordersServices.NewPaymentProvidersService(
+		ordRepo,
+		providersRepo,
+		providersRegistry,
+	)
+	providersHandler := ordersHandlers.NewPaymentProvidersHandler(providersSvc)
 
 	ordersRoutes.AddOrdersRoutes(
 		e,
@@ -169,4 +296,50 @@ func setupOrders(e *echo.Echo, cfg *config.AppConfig, logger *slog.Logger) {
 		websocketHandler,
 		cfg.AuthorizeEndpoint,
 	)
+
+	ordersRoutes.AddPaymentProvidersRoutes(e, providersHandler, cfg.AuthorizeEndpoint)
+	ordersRoutes.AddStaffFeedRoutes(e, staffFeedHandler, cfg.AuthorizeEndpoint)
+	ordersRoutes.AddAssistanceRoutes(
+		e,
+		ordersHandlers.NewAssistanceHandler(assistanceSvc),
+		cfg.AuthorizeEndpoint,
+	)
+
+	tipsHandler := ordersHandlers.NewTipsHandler(ordersServices.NewTipsService(ordRepo, tipsRepo))
+	ordersRoutes.AddTipsRoutes(e, tipsHandler, cfg.AuthorizeEndpoint)
+
+	promotionsHandler := ordersHandlers.NewPromotionsHandler(
+		ordersServices.NewPromotionsService(ordRepo, promotionsRepo),
+	)
+	ordersRoutes.AddPromotionsRoutes(e, promotionsHandler, cfg.AuthorizeEndpoint)
+
+	receiptsMailer, err := mailer.GetMailer(&cfg.MailerConfig)
+	if err != nil {
+		logger.Error("failed to prepare receipts mailer", "error", err)
+		os.Exit(1)
+	}
+
+	receiptsSvc := ordersServices.NewReceiptsService(
+		ordRepo,
+		paymentsRepo,
+		ordersRepo.NewReceiptsRepo(queries),
+		receiptsMailer,
+		tableSessions,
+	)
+	receiptsHandler := ordersHandlers.NewReceiptsHandler(receiptsSvc)
+	ordersRoutes.AddReceiptsRoutes(e, receiptsHandler, cfg.AuthorizeEndpoint)
+
+	printingHandler := ordersHandlers.NewPrintingHandler(ordersServices.NewPrintingService(
+		ordRepo,
+		ordersRepo.NewPrintersRepo(db, queries),
+		receiptsSvc,
+		time.Duration(cfg.PrinterTimeoutSeconds)*time.Second,
+	))
+	ordersRoutes.AddPrintingRoutes(e, printingHandler, cfg.AuthorizeEndpoint)
+
+	mockProvider, ok := platformProvider.(*paymentproviders.MockPaymentProvider)
+	if ok {
+		logger.Info("using mock payment provider")
+		ordersRoutes.AddMockCheckoutRoutes(e, ordersHandlers.NewMockCheckoutHandler(mockProvider))
+	}
 }
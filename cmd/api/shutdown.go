@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+)
+
+// drainer closes long-lived connections, such as websockets and event streams, telling
+// clients to reconnect, and waits for their handlers to finish.
+type drainer interface {
+	Shutdown(ctx context.Context) error
+}
+
+// app holds what has to be stopped when the API shuts down. Background workers run with
+// app's context, which is cancelled once connections are drained.
+type app struct {
+	ctx      context.Context //nolint:containedctx
+	cancel   context.CancelFunc
+	drainers []drainer
+	closers  []io.Closer
+}
+
+func newApp() *app {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &app{
+		ctx:      ctx,
+		cancel:   cancel,
+		drainers: nil,
+		closers:  nil,
+	}
+}
+
+// drainOnShutdown registers connections drained when the API shuts down.
+func (a *app) drainOnShutdown(d drainer) {
+	a.drainers = append(a.drainers, d)
+}
+
+// closeOnShutdown registers a database pool or a client closed last when the API shuts down.
+func (a *app) closeOnShutdown(c io.Closer) {
+	a.closers = append(a.closers, c)
+}
+
+// shutdown stops the server from accepting connections, drains websockets and event
+// streams, and waits for requests in flight until the context is done. Database pools and
+// clients are closed after that, even when the deadline passed.
+func (a *app) shutdown(ctx context.Context, e *echo.Echo, logger *slog.Logger) {
+	// server closes its listeners right away, event streams it waits for end once drained
+	serverStopped := make(chan error, 1)
+
+	go func() {
+		serverStopped <- e.Shutdown(ctx)
+	}()
+
+	for _, d := range a.drainers {
+		err := d.Shutdown(ctx)
+		if err != nil {
+			logger.Error("failed to drain connections", "error", err)
+		}
+	}
+
+	err := <-serverStopped
+	if err != nil {
+		logger.Error("failed to wait for requests in flight", "error", err)
+	}
+
+	a.cancel()
+
+	for _, c := range a.closers {
+		err := c.Close()
+		if err != nil {
+			logger.Error("failed to close", "error", err)
+		}
+	}
+
+	logger.Info("server stopped")
+}
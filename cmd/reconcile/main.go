@@ -0,0 +1,216 @@
+// Package main is the entry point for the payments reconciliation job.
+// It lists provider's charges for a date range, matches them to our payments by provider's
+// payment id and writes missing, extra and amount-mismatched rows as JSON or CSV.
+// Exits with status 2 when differences are found, so scheduled runs can alert on it.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-dining-ordering/config"
+	"golang-dining-ordering/pkg/encryption"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	ordersRepo "golang-dining-ordering/services/orders/repository"
+	ordersServices "golang-dining-ordering/services/orders/services"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	ordersDB "golang-dining-ordering/services/orders/db/generated"
+
+	"github.com/google/uuid"
+	"github.com/ilyakaznacheev/cleanenv"
+	_ "github.com/lib/pq"
+)
+
+const (
+	dateLayout            = "2006-01-02"
+	exitCodeDifferences   = 2
+	reconciliationTimeout = 5 * time.Minute
+)
+
+var errInvalidFlag = errors.New("invalid flag")
+
+type flags struct {
+	provider     string
+	restaurantID string
+	from         string
+	to           string
+	format       string
+	out          string
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		AddSource:   false,
+		Level:       slog.LevelInfo,
+		ReplaceAttr: nil,
+	}))
+
+	var cfg config.AppConfig
+
+	err := cleanenv.ReadEnv(&cfg)
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(dateLayout)
+
+	var f flags
+
+	flag.StringVar(&f.provider, "provider", string(cfg.PaymentsConfig.PlatformProvider),
+		"payment provider to reconcile")
+	flag.StringVar(&f.restaurantID, "restaurant", "",
+		"restaurant whose own provider account is reconciled, platform account when empty")
+	flag.StringVar(&f.from, "from", yesterday, "first day of the range, YYYY-MM-DD in UTC")
+	flag.StringVar(&f.to, "to", "", "last day of the range, YYYY-MM-DD in UTC, defaults to -from")
+	flag.StringVar(&f.format, "format", formatJSON, "report format, json or csv")
+	flag.StringVar(&f.out, "out", "", "file report is written to, stdout when empty")
+	flag.Parse()
+
+	reqDto, err := parseFlags(&f)
+	if err != nil {
+		logger.Error("failed to parse flags", "error", err)
+		os.Exit(1)
+	}
+
+	report, err := reconcile(&cfg, reqDto)
+	if err != nil {
+		logger.Error("failed to reconcile payments", "error", err)
+		os.Exit(1)
+	}
+
+	err = writeReport(f.out, f.format, report)
+	if err != nil {
+		logger.Error("failed to write report", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info(
+		"payments reconciled",
+		"provider", report.Provider,
+		"matched", report.Matched,
+		"differences", len(report.Rows),
+	)
+
+	if len(report.Rows) > 0 {
+		os.Exit(exitCodeDifferences)
+	}
+}
+
+func parseFlags(f *flags) (*dto.ReconciliationRequestDto, error) {
+	if f.format != formatJSON && f.format != formatCSV {
+		return nil, fmt.Errorf("%w: unknown format %q", errInvalidFlag, f.format)
+	}
+
+	from, err := time.Parse(dateLayout, f.from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from: %w", errInvalidFlag, err)
+	}
+
+	to := from
+
+	if f.to != "" {
+		to, err = time.Parse(dateLayout, f.to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to: %w", errInvalidFlag, err)
+		}
+	}
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to is before from", errInvalidFlag)
+	}
+
+	reqDto := &dto.ReconciliationRequestDto{
+		Provider:     ordersDB.OrdersPaymentProvider(f.provider),
+		RestaurantID: nil,
+		From:         from,
+		To:           to.AddDate(0, 0, 1),
+	}
+
+	if f.restaurantID != "" {
+		restaurantID, err := uuid.Parse(f.restaurantID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: restaurant: %w", errInvalidFlag, err)
+		}
+
+		reqDto.RestaurantID = &restaurantID
+	}
+
+	return reqDto, nil
+}
+
+func reconcile(
+	cfg *config.AppConfig,
+	reqDto *dto.ReconciliationRequestDto,
+) (*dto.ReconciliationReportDto, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), reconciliationTimeout)
+	defer cancel()
+
+	db, err := sql.Open("postgres", cfg.ManagementDBURI)
+	if err != nil {
+		return nil, fmt.Errorf("preparing orders db connection: %w", err)
+	}
+	defer db.Close()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to orders database: %w", err)
+	}
+
+	cipher, err := encryption.NewCipher(cfg.PaymentsConfig.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("preparing payment credentials encryption: %w", err)
+	}
+
+	queries := ordersDB.New(db)
+
+	providersRegistry := paymentproviders.NewRegistry(
+		ordersRepo.NewPaymentProvidersRepo(db, queries, cipher),
+		&paymentproviders.RegistryConfig{
+			WebhooksURL:  cfg.PaymentsConfig.WebhooksURL,
+			KlixAPIURL:   cfg.KlixConfig.APIURL,
+			StripeAPIURL: cfg.StripeAPIURL,
+		},
+		paymentproviders.GetPlatformProvider(cfg),
+		ordersDB.OrdersPaymentProvider(cfg.PaymentsConfig.PlatformProvider),
+	)
+
+	svc := ordersServices.NewReconciliationService(
+		ordersRepo.NewReconciliationRepo(queries),
+		providersRegistry,
+	)
+
+	report, err := svc.Reconcile(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("reconciling: %w", err)
+	}
+
+	return report, nil
+}
+
+func writeReport(path, format string, report *dto.ReconciliationReportDto) error {
+	var w io.Writer = os.Stdout
+
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating report file: %w", err)
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if format == formatCSV {
+		return writeCSV(w, report)
+	}
+
+	return writeJSON(w, report)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"golang-dining-ordering/services/orders/dto"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+//nolint:gochecknoglobals
+var csvHeader = []string{
+	"issue",
+	"provider_payment_id",
+	"payment_id",
+	"order_id",
+	"amount_in_cents",
+	"currency",
+	"provider_amount_in_cents",
+	"provider_currency",
+	"created_at",
+}
+
+func writeJSON(w io.Writer, report *dto.ReconciliationReportDto) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(report)
+	if err != nil {
+		return fmt.Errorf("encoding json report: %w", err)
+	}
+
+	return nil
+}
+
+// writeCSV writes report rows only, range and provider are known from the job's arguments.
+func writeCSV(w io.Writer, report *dto.ReconciliationReportDto) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	for _, row := range report.Rows {
+		record := []string{
+			string(row.Issue),
+			row.ProviderPaymentID,
+			"",
+			"",
+			formatOptionalInt(row.AmountInCents),
+			row.Currency,
+			formatOptionalInt(row.ProviderAmountInCents),
+			row.ProviderCurrency,
+			row.CreatedAt.UTC().Format(time.RFC3339),
+		}
+
+		if row.PaymentID != nil {
+			record[2] = row.PaymentID.String()
+		}
+
+		if row.OrderID != nil {
+			record[3] = row.OrderID.String()
+		}
+
+		err = cw.Write(record)
+		if err != nil {
+			return fmt.Errorf("writing csv row: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	err = cw.Error()
+	if err != nil {
+		return fmt.Errorf("flushing csv report: %w", err)
+	}
+
+	return nil
+}
+
+func formatOptionalInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*v)
+}
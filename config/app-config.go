@@ -11,6 +11,38 @@ const (
 	StorageTypeS3 StorageType = "s3"
 )
 
+// PaymentProviderType represents the configured provider used for order payments.
+type PaymentProviderType string
+
+const (
+	// PaymentProviderTypeStripe indicates Stripe hosted checkout.
+	PaymentProviderTypeStripe PaymentProviderType = "stripe"
+	// PaymentProviderTypeMock indicates local mock checkout, used for development and tests.
+	PaymentProviderTypeMock PaymentProviderType = "mock"
+	// PaymentProviderTypeKlix indicates Klix bank-link checkout.
+	PaymentProviderTypeKlix PaymentProviderType = "klix"
+)
+
+// MailerType represents the configured backend used to send emails to guests.
+type MailerType string
+
+const (
+	// MailerTypeSMTP indicates sending emails through SMTP server.
+	MailerTypeSMTP MailerType = "smtp"
+	// MailerTypeFile indicates writing emails to files, used for development and tests.
+	MailerTypeFile MailerType = "file"
+)
+
+// PubSubType represents the configured backend used to deliver order events between instances.
+type PubSubType string
+
+const (
+	// PubSubTypePostgres indicates Postgres LISTEN/NOTIFY, which reaches every instance.
+	PubSubTypePostgres PubSubType = "postgres"
+	// PubSubTypeMemory indicates in-process delivery, used for a single instance and tests.
+	PubSubTypeMemory PubSubType = "memory"
+)
+
 // AppConfig defines environment-based configuration for the application.
 type AppConfig struct {
 	AuthDBURI                string      `env:"DINE_AUTH_DB_URI"`
@@ -25,8 +57,16 @@ type AppConfig struct {
 	StorageType              StorageType `env:"DINE_STORAGE_TYPE"`
 	StripeSecretKey          string      `env:"STRIPE_SECRET_KEY"`
 	StripeWebhookSecret      string      `env:"STRIPE_WEBHOOK_SECRET"`
+	StripeAPIURL             string      `env:"STRIPE_API_URL"`
+	PrinterTimeoutSeconds    int         `env:"DINE_PRINTER_TIMEOUT_SECONDS" env-default:"5"`
+	ShutdownTimeoutSeconds   int         `env:"DINE_SHUTDOWN_TIMEOUT_SECONDS" env-default:"30"`
 	S3Config                 S3Config
 	WebsocketConfig          WebsocketConfig
+	PaymentsConfig           PaymentsConfig
+	MockPaymentsConfig       MockPaymentsConfig
+	KlixConfig               KlixConfig
+	MailerConfig             MailerConfig
+	TableSessionConfig       TableSessionConfig
 }
 
 // S3Config holds credentials and connection info for S3/MinIO storage.
@@ -37,9 +77,57 @@ type S3Config struct {
 	Bucket string `env:"S3_BUCKET"`
 }
 
-// WebsocketConfig holds settings for websocket connections.
+// WebsocketConfig holds settings for websocket connections. Browsers may open connections
+// only from allowed origins, connections from the API's own origin are always allowed.
 type WebsocketConfig struct {
-	HandshakeTimeout int `env:"CHAT_HANDSHAKE_TIMEOUT" env-default:"5"`
-	ReadBufferSize   int `env:"CHAT_READ_BUFFER_SIZE"  env-default:"1024"`
-	WriteBufferSize  int `env:"CHAT_WRITE_BUFFER_SIZE" env-default:"1024"`
+	HandshakeTimeout int        `env:"CHAT_HANDSHAKE_TIMEOUT" env-default:"5"`
+	ReadBufferSize   int        `env:"CHAT_READ_BUFFER_SIZE"  env-default:"1024"`
+	WriteBufferSize  int        `env:"CHAT_WRITE_BUFFER_SIZE" env-default:"1024"`
+	WriteTimeout     int        `env:"CHAT_WRITE_TIMEOUT"     env-default:"10"`
+	PongTimeout      int        `env:"CHAT_PONG_TIMEOUT"      env-default:"60"`
+	MaxMessageSize   int64      `env:"CHAT_MAX_MESSAGE_SIZE"  env-default:"4096"`
+	SendQueueSize    int        `env:"CHAT_SEND_QUEUE_SIZE"   env-default:"32"`
+	ReplayEvents     int        `env:"CHAT_REPLAY_EVENTS"     env-default:"50"`
+	AllowedOrigins   []string   `env:"CHAT_ALLOWED_ORIGINS"   env-separator:","`
+	PubSub           PubSubType `env:"DINE_PUBSUB_TYPE"       env-default:"postgres"`
+}
+
+// TableSessionConfig holds settings for session tokens guests get for table's current order.
+type TableSessionConfig struct {
+	Secret     string `env:"DINE_TABLE_SESSION_SECRET"`
+	TTLSeconds int    `env:"DINE_TABLE_SESSION_TTL_SECONDS" env-default:"10800"`
+}
+
+// PaymentsConfig holds platform wide payment settings. Restaurants configure their own providers,
+// PlatformProvider is optional and used only for restaurants that haven't configured any.
+type PaymentsConfig struct {
+	PlatformProvider PaymentProviderType `env:"DINE_PAYMENT_PROVIDER"`
+	WebhooksURL      string              `env:"DINE_PAYMENTS_WEBHOOKS_URL"   env-default:"http://localhost:42069/api/v1/orders/webhooks"`
+	EncryptionKey    string              `env:"DINE_PAYMENTS_ENCRYPTION_KEY"`
+}
+
+// MockPaymentsConfig holds settings for the mock payment provider.
+type MockPaymentsConfig struct {
+	BaseURL       string `env:"MOCK_PAYMENTS_BASE_URL"       env-default:"http://localhost:42069"`
+	WebhookSecret string `env:"MOCK_PAYMENTS_WEBHOOK_SECRET" env-default:"mock-webhook-secret"`
+}
+
+// KlixConfig holds connection info and platform credentials for Klix payments.
+type KlixConfig struct {
+	APIURL    string `env:"KLIX_API_URL"    env-default:"https://portal.klix.app/api/v1"`
+	BrandID   string `env:"KLIX_BRAND_ID"`
+	SecretKey string `env:"KLIX_SECRET_KEY"`
+	PublicKey string `env:"KLIX_PUBLIC_KEY"`
+}
+
+// MailerConfig holds settings for emails sent to guests, e.g. receipts. Emails are disabled
+// when mailer type is not set.
+type MailerConfig struct {
+	Type         MailerType `env:"DINE_MAILER_TYPE"`
+	From         string     `env:"DINE_MAILER_FROM"      env-default:"receipts@dine.local"`
+	Directory    string     `env:"DINE_MAILER_DIRECTORY" env-default:"mail/"`
+	SMTPHost     string     `env:"SMTP_HOST"`
+	SMTPPort     int        `env:"SMTP_PORT"             env-default:"587"`
+	SMTPUsername string     `env:"SMTP_USERNAME"`
+	SMTPPassword string     `env:"SMTP_PASSWORD"`
 }
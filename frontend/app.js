@@ -8,6 +8,9 @@ function restaurantApp() {
     order: null,
     menu: null,
     tipAmount: 0.00,
+    tipPresets: [],
+    promoCode: '',
+    promoCodeError: null,
 
     SUCCESS_URL: null,
     CANCEL_URL: null,
@@ -96,6 +99,7 @@ function restaurantApp() {
         const resJson = await res.json()
 
         this.order = resJson.data
+        this.tipPresets = this.order.tip_presets || []
         this.setTipAmount(this.order.tip_amount_in_cents)
       } catch(err) {
         console.error('Failed to fetch order data: ', err)
@@ -172,6 +176,47 @@ function restaurantApp() {
       }
     },
 
+    async applyPromoCode() {
+      const code = this.promoCode.trim()
+      if (code === '') return
+
+      try {
+        this.promoCodeError = null
+
+        const res = await fetch(`/api/v1/orders/${this.order.id}/promo-code`, {
+          method: "PUT",
+          headers: {
+            "Content-Type": "application/json"
+          },
+          body: JSON.stringify({
+            "code": code
+          })
+        })
+        await this.raiseForStatus(res)
+
+        const resJson = await res.json()
+        this.promoCode = ''
+        this.updateCurrentOrder(resJson.data)
+      } catch(err) {
+        this.promoCodeError = 'Promo code cannot be applied'
+        console.error("failed to apply promo code: ", err)
+      }
+    },
+
+    async removePromoCode() {
+      try {
+        const res = await fetch(`/api/v1/orders/${this.order.id}/promo-code`, {
+          method: "DELETE"
+        })
+        await this.raiseForStatus(res)
+
+        const resJson = await res.json()
+        this.updateCurrentOrder(resJson.data)
+      } catch(err) {
+        console.error("failed to remove promo code: ", err)
+      }
+    },
+
     async lockOrder() {
       if (this.order.id == null) return
       
@@ -269,6 +314,10 @@ function restaurantApp() {
       this.setTipAmount(this.order.tip_amount_in_cents)
     },
 
+    tipPresetAmount(percent) {
+      return Math.round(this.order.total_price_in_cents * percent / 100)
+    },
+
     setTipAmount(amountInCents) {
       this.tipAmount = this.centsToFloat(amountInCents)
     },
@@ -5,6 +5,8 @@ function orderApp() {
         orderId: null,
         order: null,
         isSuccess: false,
+        receiptEmail: "",
+        receiptMessage: "",
 
         CURRENCIES_WITH_NO_CENTS: ['sek'],
 
@@ -29,6 +31,25 @@ function orderApp() {
             }
         },
 
+        async emailReceipt() {
+            try {
+                const sessionToken = sessionStorage.getItem(`tableSession:${this.orderId}`) ?? ""
+                const res = await fetch(`/api/v1/orders/${this.orderId}/receipt/email`, {
+                    method: "POST",
+                    headers: {
+                        "Content-Type": "application/json",
+                        "X-Table-Session": sessionToken,
+                    },
+                    body: JSON.stringify({ email: this.receiptEmail }),
+                })
+                await this.raiseForStatus(res)
+                this.receiptMessage = `Receipt sent to ${this.receiptEmail}`
+            } catch(err) {
+                console.error("failed to send receipt: ", err)
+                this.receiptMessage = "Failed to send receipt"
+            }
+        },
+
         async raiseForStatus(res) {
             if (!res.ok) {
                 let message;
@@ -10,6 +10,16 @@ function websocketApp() {
     tipAmount: 0.00,
 
     socket: null,
+    sessionToken: null,
+    // sequence number of the last order event received, sent when reconnecting
+    lastSeq: null,
+    // requests waiting for their ack or nack, by request_id
+    pendingRequests: {},
+    nextRequestId: 1,
+    // name shown to other guests at the table
+    displayName: localStorage.getItem('displayName') ?? '',
+    // guests and staff connected to the order
+    participants: [],
 
     SUCCESS_URL: null,
     CANCEL_URL: null,
@@ -69,12 +79,18 @@ function websocketApp() {
         const res = await fetch(`/api/v1/orders/current?tableId=${id}`)
         const resJson = await res.json()
         orderId = resJson.data.id
+        sessionToken = resJson.data.session_token
         console.log("latest order for table is: ", orderId)
       } catch (err) {
         console.error('Failed to fetch current order for table: ', err)
         return
       }
 
+      this.sessionToken = sessionToken
+      // order page sends receipts by email with the table session after checkout
+      sessionStorage.setItem(`tableSession:${orderId}`, sessionToken)
+      this.lastSeq = null
+
       this.fetchOrder(orderId)
       this.joinOrderWebsocket(orderId)
     },
@@ -89,20 +105,49 @@ function websocketApp() {
       const protocol = window.location.protocol === 'https:' ? 'wss' : 'ws'
       const host = window.location.host
 
-      this.socket = new WebSocket(`${protocol}://${host}/api/v1/orders/${orderId}/ws`)
+      let query = `session_token=${encodeURIComponent(this.sessionToken)}`
+      // after reconnecting the server replays missed events or sends the whole order
+      query += `&last_seq=${this.lastSeq ?? 0}`
+      if (this.displayName) query += `&display_name=${encodeURIComponent(this.displayName)}`
+
+      const socket = new WebSocket(`${protocol}://${host}/api/v1/orders/${orderId}/ws?${query}`)
+      this.socket = socket
 
-      this.socket.addEventListener("open", () => {
+      socket.addEventListener("open", () => {
           console.log("connected to order: ", orderId)
       })
 
-      this.socket.addEventListener("message", (event) => this.handleReceivedMsg(event));
+      socket.addEventListener("message", (event) => this.handleReceivedMsg(event));
+
+      socket.addEventListener("close", () => {
+          if (this.socket !== socket) return
+
+          console.log("disconnected from order, reconnecting: ", orderId)
+          setTimeout(() => {
+              if (this.socket === socket) this.joinOrderWebsocket(orderId)
+          }, 1000)
+      })
     },
 
     handleReceivedMsg(event) {
       try {
           const parsedMsg =JSON.parse(event.data)
 
-          if (parsedMsg.type === "error") throw new Error(parsedMsg.data)
+          if (parsedMsg.type === "ack" || parsedMsg.type === "nack") {
+              this.handleReply(parsedMsg)
+              return
+          }
+
+          if (parsedMsg.type === "error") throw new Error(parsedMsg.data.message)
+
+          if (this.handlePresenceMsg(parsedMsg)) return
+
+          // events already received are sent again when they are replayed
+          if (parsedMsg.seq) {
+              if (parsedMsg.type !== "sync" && parsedMsg.seq <= this.lastSeq) return
+
+              this.lastSeq = parsedMsg.seq
+          }
 
           this.order = parsedMsg.data
           this.tipAmount = this.centsToFloat(parsedMsg.data.tip_amount_in_cents)
@@ -111,20 +156,83 @@ function websocketApp() {
       }
     },
 
+    // handlePresenceMsg keeps participants up to date, it reports whether msg was about presence.
+    handlePresenceMsg(msg) {
+        switch (msg.type) {
+        case "presence":
+            this.participants = msg.data.participants
+            return true
+        case "participant_joined":
+            this.participants = this.participants.filter((p) => p.id !== msg.data.id)
+            this.participants.push(msg.data)
+            return true
+        case "participant_left":
+            this.participants = this.participants.filter((p) => p.id !== msg.data.id)
+            return true
+        }
+
+        return false
+    },
+
+    setDisplayName(name) {
+      this.displayName = name.trim()
+      localStorage.setItem('displayName', this.displayName)
+
+      // display name is sent only when connecting
+      if (this.order) this.joinOrderWebsocket(this.order.id)
+    },
+
+    // sendMessage resolves with server's reply to the request, an ack or a nack whose data
+    // has the error code, e.g. item_unavailable or order_locked.
     sendMessage(type, data) {
-        const msg = {
-            "type": type, 
-            "data": data
+        return new Promise((resolve) => {
+            if (!this.socket || this.socket.readyState !== WebSocket.OPEN) {
+                resolve(this.notConnectedReply(type))
+                return
+            }
+
+            const requestId = String(this.nextRequestId++)
+            this.pendingRequests[requestId] = resolve
+
+            this.socket.send(JSON.stringify({
+                "v": 1,
+                "request_id": requestId,
+                "type": type,
+                "data": data
+            }));
+        })
+    },
+
+    handleReply(msg) {
+        const resolve = this.pendingRequests[msg.request_id]
+        if (!resolve) return
+
+        delete this.pendingRequests[msg.request_id]
+
+        if (msg.type === "nack") {
+            console.error("request rejected: ", msg.data.code, msg.data.message)
         }
-        
-        if (this.socket && this.socket.readyState === WebSocket.OPEN) {
-            this.socket.send(JSON.stringify(msg));
+
+        resolve(msg)
+    },
+
+    notConnectedReply(type) {
+        return {
+            "type": "nack",
+            "data": { "type": type, "code": "not_connected", "message": "not connected to order" }
         }
     },
 
     leaveOrderWebsocket() {
-      this.socket.close(1000, "user left ws")
+      const socket = this.socket
       this.socket = null
+      this.participants = []
+      socket.close(1000, "user left ws")
+
+      for (const resolve of Object.values(this.pendingRequests)) {
+        resolve(this.notConnectedReply(null))
+      }
+      this.pendingRequests = {}
     },
 
     async fetchMenu(id) {
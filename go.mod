@@ -18,6 +18,7 @@ require (
 	github.com/stripe/stripe-go/v84 v84.0.0
 	github.com/swaggest/swgui v1.8.4
 	golang.org/x/crypto v0.42.0
+	golang.org/x/text v0.29.0
 )
 
 require (
@@ -54,7 +55,6 @@ require (
 	github.com/valyala/fasttemplate v1.2.2 // indirect
 	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.29.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
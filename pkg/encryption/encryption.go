@@ -0,0 +1,72 @@
+// Package encryption provides symmetric encryption of secrets stored in the database.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const keySizeInBytes = 32
+
+var (
+	// ErrInvalidKey is returned when encryption key is not a base64 encoded 32 byte key.
+	ErrInvalidKey = errors.New("encryption key must be base64 encoded 32 bytes")
+	// ErrMalformedCiphertext is returned when ciphertext is too short to contain a nonce.
+	ErrMalformedCiphertext = errors.New("malformed ciphertext")
+)
+
+// Cipher encrypts and decrypts data with AES-256-GCM, nonce is prepended to the ciphertext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a Cipher from base64 encoded 32 byte key.
+func NewCipher(base64Key string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(base64Key)
+	if err != nil || len(key) != keySizeInBytes {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating aes cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating gcm: %w", err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt encrypts plaintext with a random nonce.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt.
+func (c *Cipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrMalformedCiphertext
+	}
+
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+
+	return plaintext, nil
+}
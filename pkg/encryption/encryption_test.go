@@ -0,0 +1,61 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var testKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCipher(testKey)
+	require.NoError(t, err)
+
+	plaintext := []byte(`{"secret_key":"sk_test_123"}`)
+
+	ciphertext, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.NotContains(t, string(ciphertext), "sk_test_123")
+
+	again, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "nonce must be random")
+
+	decrypted, err := c.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestCipher_DecryptTampered(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewCipher(testKey)
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+
+	ciphertext[len(ciphertext)-1] ^= 0xff
+
+	_, err = c.Decrypt(ciphertext)
+	require.Error(t, err)
+
+	_, err = c.Decrypt([]byte("short"))
+	require.ErrorIs(t, err, ErrMalformedCiphertext)
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCipher("not base64!")
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewCipher(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"golang-dining-ordering/pkg/responses"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrTooManyRequests is returned when request is over the limit of its key.
+var ErrTooManyRequests = errors.New("too many requests")
+
+// RateLimiterKeyFunc returns key requests are counted by, e.g. route param or client's ip.
+type RateLimiterKeyFunc func(c echo.Context) string
+
+type rateWindow struct {
+	startedAt time.Time
+	count     int
+}
+
+// RateLimiter lets through at most limit requests of the same key in every window, requests
+// over the limit get 429 with Retry-After header. Counters are kept in memory, so every
+// instance of the API counts its own requests.
+func RateLimiter(limit int, window time.Duration, key RateLimiterKeyFunc) echo.MiddlewareFunc {
+	var (
+		mu        sync.Mutex
+		windows   = make(map[string]*rateWindow)
+		lastSweep = time.Now()
+	)
+
+	// allow returns how long the caller has to wait when key is over the limit
+	allow := func(k string, now time.Time) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// windows that ended are dropped once per window, so keys seen once don't pile up
+		if now.Sub(lastSweep) >= window {
+			for ended, w := range windows {
+				if now.Sub(w.startedAt) >= window {
+					delete(windows, ended)
+				}
+			}
+
+			lastSweep = now
+		}
+
+		w, ok := windows[k]
+		if !ok || now.Sub(w.startedAt) >= window {
+			windows[k] = &rateWindow{startedAt: now, count: 1}
+
+			return 0
+		}
+
+		if w.count >= limit {
+			return w.startedAt.Add(window).Sub(now)
+		}
+
+		w.count++
+
+		return 0
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			wait := allow(key(c), time.Now())
+			if wait > 0 {
+				seconds := int((wait + time.Second - 1) / time.Second)
+				c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+
+				return responses.JSONError(
+					c,
+					ErrTooManyRequests.Error(),
+					ErrTooManyRequests,
+					http.StatusTooManyRequests,
+				)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+
+	nextHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	h := RateLimiter(2, time.Minute, func(c echo.Context) string {
+		return c.Param("order_id")
+	})(nextHandler)
+
+	request := func(orderID string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("order_id")
+		c.SetParamValues(orderID)
+
+		return rec, h(c)
+	}
+
+	for range 2 {
+		rec, err := request("1")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec, err := request("1")
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	rec, err = request("2")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRateLimiter_WindowEnds(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+
+	nextHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	h := RateLimiter(1, 50*time.Millisecond, func(_ echo.Context) string {
+		return "key"
+	})(nextHandler)
+
+	request := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		_ = h(e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec))
+
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, request().Code)
+	require.Equal(t, http.StatusTooManyRequests, request().Code)
+
+	time.Sleep(60 * time.Millisecond)
+
+	require.Equal(t, http.StatusOK, request().Code)
+}
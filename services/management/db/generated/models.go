@@ -69,11 +69,12 @@ type ManagementRestaurantsWaiter struct {
 }
 
 type ManagementTable struct {
-	ID           uuid.UUID    `json:"id"`
-	RestaurantID uuid.UUID    `json:"restaurant_id"`
-	Name         string       `json:"name"`
-	Capacity     int          `json:"capacity"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	DeletedAt    sql.NullTime `json:"deleted_at"`
+	ID           uuid.UUID      `json:"id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id"`
+	Name         string         `json:"name"`
+	Capacity     int            `json:"capacity"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	Section      sql.NullString `json:"section"`
 }
@@ -18,23 +18,26 @@ INSERT INTO management.tables (
     id,
     restaurant_id,
     name,
-    capacity
-) VALUES ($1, $2, $3, $4)
-RETURNING id, restaurant_id, name, capacity
+    capacity,
+    section
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, restaurant_id, name, capacity, section
 `
 
 type CreateTableParams struct {
-	ID           uuid.UUID `json:"id"`
-	RestaurantID uuid.UUID `json:"restaurant_id"`
-	Name         string    `json:"name"`
-	Capacity     int       `json:"capacity"`
+	ID           uuid.UUID      `json:"id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id"`
+	Name         string         `json:"name"`
+	Capacity     int            `json:"capacity"`
+	Section      sql.NullString `json:"section"`
 }
 
 type CreateTableRow struct {
-	ID           uuid.UUID `json:"id"`
-	RestaurantID uuid.UUID `json:"restaurant_id"`
-	Name         string    `json:"name"`
-	Capacity     int       `json:"capacity"`
+	ID           uuid.UUID      `json:"id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id"`
+	Name         string         `json:"name"`
+	Capacity     int            `json:"capacity"`
+	Section      sql.NullString `json:"section"`
 }
 
 func (q *Queries) CreateTable(ctx context.Context, arg CreateTableParams) (CreateTableRow, error) {
@@ -43,6 +46,7 @@ func (q *Queries) CreateTable(ctx context.Context, arg CreateTableParams) (Creat
 		arg.RestaurantID,
 		arg.Name,
 		arg.Capacity,
+		arg.Section,
 	)
 	var i CreateTableRow
 	err := row.Scan(
@@ -50,6 +54,7 @@ func (q *Queries) CreateTable(ctx context.Context, arg CreateTableParams) (Creat
 		&i.RestaurantID,
 		&i.Name,
 		&i.Capacity,
+		&i.Section,
 	)
 	return i, err
 }
@@ -145,15 +150,16 @@ func (q *Queries) GetRestaurants(ctx context.Context, arg GetRestaurantsParams)
 }
 
 const getTables = `-- name: GetTables :many
-SELECT id, name, capacity
+SELECT id, name, capacity, section
 FROM management.tables
 WHERE restaurant_id = $1
 `
 
 type GetTablesRow struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Capacity int       `json:"capacity"`
+	ID       uuid.UUID      `json:"id"`
+	Name     string         `json:"name"`
+	Capacity int            `json:"capacity"`
+	Section  sql.NullString `json:"section"`
 }
 
 func (q *Queries) GetTables(ctx context.Context, restaurantID uuid.UUID) ([]GetTablesRow, error) {
@@ -165,7 +171,12 @@ func (q *Queries) GetTables(ctx context.Context, restaurantID uuid.UUID) ([]GetT
 	var items []GetTablesRow
 	for rows.Next() {
 		var i GetTablesRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.Capacity); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Capacity,
+			&i.Section,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
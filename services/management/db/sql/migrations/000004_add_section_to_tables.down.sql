@@ -0,0 +1,2 @@
+ALTER TABLE management.tables
+    DROP COLUMN IF EXISTS section;
@@ -0,0 +1,2 @@
+ALTER TABLE management.tables
+    ADD COLUMN section VARCHAR(40);
@@ -70,11 +70,12 @@ INSERT INTO management.tables (
     id,
     restaurant_id,
     name,
-    capacity
-) VALUES ($1, $2, $3, $4)
-RETURNING id, restaurant_id, name, capacity;
+    capacity,
+    section
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, restaurant_id, name, capacity, section;
 
 -- name: GetTables :many
-SELECT id, name, capacity
+SELECT id, name, capacity, section
 FROM management.tables
 WHERE restaurant_id = $1;
@@ -61,10 +61,12 @@ type UpdateRestaurantResponseDto struct {
 }
 
 // RestaurantTableDto represents a restaurant table used for both request payloads and responses.
+// Section groups tables served together, e.g. terrace, staff feed can be filtered by it.
 type RestaurantTableDto struct {
 	ID           uuid.UUID `json:"id"`
-	RestaurantID uuid.UUID `json:"-"        validate:"required"`
-	UserID       uuid.UUID `json:"-"        validate:"required"`
-	Name         string    `json:"name"     validate:"required"`
-	Capacity     int       `json:"capacity" validate:"required,gt=0,lt=100"`
+	RestaurantID uuid.UUID `json:"-"                 validate:"required"`
+	UserID       uuid.UUID `json:"-"                 validate:"required"`
+	Name         string    `json:"name"              validate:"required"`
+	Capacity     int       `json:"capacity"          validate:"required,gt=0,lt=100"`
+	Section      *string   `json:"section,omitempty" validate:"omitempty,max=40"`
 }
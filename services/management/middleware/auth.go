@@ -81,6 +81,21 @@ func AuthMiddleware(authServiceURL string, failOnMissingUser ...bool) echo.Middl
 	}
 }
 
+// TokenFromQueryMiddleware passes token from the query param as Authorization header when
+// the header is missing, for clients that can't set headers, e.g. browsers' websockets.
+func TokenFromQueryMiddleware(paramName string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := c.QueryParam(paramName)
+			if token != "" && c.Request().Header.Get("Authorization") == "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // RoleMiddleware returns an Echo middleware that allows access only to users
 // with one of the specified roles. It reads the authenticated user from the context.
 func RoleMiddleware(allowedRoles ...authDto.Role) echo.MiddlewareFunc {
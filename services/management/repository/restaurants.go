@@ -213,6 +213,7 @@ func (r *restaurantRepository) CreateTable(
 		RestaurantID: reqDto.RestaurantID,
 		Name:         reqDto.Name,
 		Capacity:     reqDto.Capacity,
+		Section:      nullString(reqDto.Section),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("inserting new table to database: %w", err)
@@ -241,6 +242,7 @@ func (r *restaurantRepository) GetTables(
 			UserID:       uuid.Nil,
 			Name:         r.Name,
 			Capacity:     r.Capacity,
+			Section:      ptrFromNullString(r.Section),
 		})
 	}
 
@@ -270,6 +272,14 @@ func nullString(s *string) sql.NullString {
 	return sql.NullString{String: *s, Valid: true}
 }
 
+func ptrFromNullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+
+	return &s.String
+}
+
 func nullBool(b *bool) sql.NullBool {
 	if b == nil {
 		return sql.NullBool{Bool: false, Valid: false}
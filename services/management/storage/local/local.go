@@ -130,3 +130,8 @@ func (s *localStorage) isFileJPGorPNG(file multipart.File) error {
 
 	return nil
 }
+
+// Close does nothing, files are opened and closed with each image.
+func (s *localStorage) Close() error {
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
+	"net/http"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,9 +16,10 @@ import (
 )
 
 type s3Storage struct {
-	s3Client *s3.Client
-	url      string
-	bucket   string
+	s3Client  *s3.Client
+	transport *http.Transport
+	url       string
+	bucket    string
 }
 
 // NewS3Storage initializes an S3/MinIO client for the specified bucket.
@@ -29,6 +31,10 @@ func NewS3Storage(ctx context.Context, key, secret, url, bucket string) *s3Stora
 		panic("loading s3 default config")
 	}
 
+	// client gets its own transport, so its connections can be closed on shutdown
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
 		o.Region = "eu-west-3"
 		o.Credentials = aws.NewCredentialsCache(
@@ -36,12 +42,22 @@ func NewS3Storage(ctx context.Context, key, secret, url, bucket string) *s3Stora
 		)
 		o.BaseEndpoint = aws.String(url)
 		o.UsePathStyle = true
+		o.HTTPClient = &http.Client{
+			Transport: transport,
+			// redirects aren't followed, like by SDK's own client
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Jar:     nil,
+			Timeout: 0,
+		}
 	})
 
 	return &s3Storage{
-		s3Client: client,
-		url:      url,
-		bucket:   bucket,
+		s3Client:  client,
+		transport: transport,
+		url:       url,
+		bucket:    bucket,
 	}
 }
 
@@ -86,3 +102,10 @@ func (s *s3Storage) DeleteMenuItemImage(ctx context.Context, fullURL string) err
 
 	return nil
 }
+
+// Close closes idle connections to S3, requests still running keep theirs until they finish.
+func (s *s3Storage) Close() error {
+	s.transport.CloseIdleConnections()
+
+	return nil
+}
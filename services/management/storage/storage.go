@@ -9,10 +9,12 @@ import (
 	"mime/multipart"
 )
 
-// Storage defines methods for storing and deleting menu item images.
+// Storage defines methods for storing and deleting menu item images. Close releases
+// storage's connections when the app shuts down.
 type Storage interface {
 	StoreMenuItemImage(ctx context.Context, fileHeader *multipart.FileHeader) (string, error)
 	DeleteMenuItemImage(ctx context.Context, path string) error
+	Close() error
 }
 
 // GetStorage returns the appropriate Storage implementation (S3 or local) based on storageType.
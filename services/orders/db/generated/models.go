@@ -7,6 +7,7 @@ package db
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -57,12 +58,229 @@ func (ns NullOrderStatus) Value() (driver.Value, error) {
 	return string(ns.OrderStatus), nil
 }
 
+type OrdersAssistanceRequestStatus string
+
+const (
+	OrdersAssistanceRequestStatusOpen         OrdersAssistanceRequestStatus = "open"
+	OrdersAssistanceRequestStatusAcknowledged OrdersAssistanceRequestStatus = "acknowledged"
+	OrdersAssistanceRequestStatusResolved     OrdersAssistanceRequestStatus = "resolved"
+)
+
+func (e *OrdersAssistanceRequestStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersAssistanceRequestStatus(s)
+	case string:
+		*e = OrdersAssistanceRequestStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersAssistanceRequestStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersAssistanceRequestStatus struct {
+	OrdersAssistanceRequestStatus OrdersAssistanceRequestStatus `json:"orders_assistance_request_status"`
+	Valid                         bool                          `json:"valid"` // Valid is true if OrdersAssistanceRequestStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersAssistanceRequestStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersAssistanceRequestStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersAssistanceRequestStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersAssistanceRequestStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersAssistanceRequestStatus), nil
+}
+
+type OrdersAssistanceRequestType string
+
+const (
+	OrdersAssistanceRequestTypeCallWaiter  OrdersAssistanceRequestType = "call_waiter"
+	OrdersAssistanceRequestTypeRequestBill OrdersAssistanceRequestType = "request_bill"
+	OrdersAssistanceRequestTypeCustom      OrdersAssistanceRequestType = "custom"
+)
+
+func (e *OrdersAssistanceRequestType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersAssistanceRequestType(s)
+	case string:
+		*e = OrdersAssistanceRequestType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersAssistanceRequestType: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersAssistanceRequestType struct {
+	OrdersAssistanceRequestType OrdersAssistanceRequestType `json:"orders_assistance_request_type"`
+	Valid                       bool                        `json:"valid"` // Valid is true if OrdersAssistanceRequestType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersAssistanceRequestType) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersAssistanceRequestType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersAssistanceRequestType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersAssistanceRequestType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersAssistanceRequestType), nil
+}
+
+type OrdersDiscountType string
+
+const (
+	OrdersDiscountTypePercentage OrdersDiscountType = "percentage"
+	OrdersDiscountTypeFixed      OrdersDiscountType = "fixed"
+)
+
+func (e *OrdersDiscountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersDiscountType(s)
+	case string:
+		*e = OrdersDiscountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersDiscountType: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersDiscountType struct {
+	OrdersDiscountType OrdersDiscountType `json:"orders_discount_type"`
+	Valid              bool               `json:"valid"` // Valid is true if OrdersDiscountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersDiscountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersDiscountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersDiscountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersDiscountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersDiscountType), nil
+}
+
+type OrdersParticipantRole string
+
+const (
+	OrdersParticipantRoleGuest OrdersParticipantRole = "guest"
+	OrdersParticipantRoleStaff OrdersParticipantRole = "staff"
+)
+
+func (e *OrdersParticipantRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersParticipantRole(s)
+	case string:
+		*e = OrdersParticipantRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersParticipantRole: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersParticipantRole struct {
+	OrdersParticipantRole OrdersParticipantRole `json:"orders_participant_role"`
+	Valid                 bool                  `json:"valid"` // Valid is true if OrdersParticipantRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersParticipantRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersParticipantRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersParticipantRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersParticipantRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersParticipantRole), nil
+}
+
+type OrdersPaymentAttemptStatus string
+
+const (
+	OrdersPaymentAttemptStatusPending   OrdersPaymentAttemptStatus = "pending"
+	OrdersPaymentAttemptStatusSucceeded OrdersPaymentAttemptStatus = "succeeded"
+	OrdersPaymentAttemptStatusFailed    OrdersPaymentAttemptStatus = "failed"
+	OrdersPaymentAttemptStatusExpired   OrdersPaymentAttemptStatus = "expired"
+	OrdersPaymentAttemptStatusCancelled OrdersPaymentAttemptStatus = "cancelled"
+)
+
+func (e *OrdersPaymentAttemptStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersPaymentAttemptStatus(s)
+	case string:
+		*e = OrdersPaymentAttemptStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersPaymentAttemptStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersPaymentAttemptStatus struct {
+	OrdersPaymentAttemptStatus OrdersPaymentAttemptStatus `json:"orders_payment_attempt_status"`
+	Valid                      bool                       `json:"valid"` // Valid is true if OrdersPaymentAttemptStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersPaymentAttemptStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersPaymentAttemptStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersPaymentAttemptStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersPaymentAttemptStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersPaymentAttemptStatus), nil
+}
+
 type OrdersPaymentProvider string
 
 const (
-	OrdersPaymentProviderStripe OrdersPaymentProvider = "stripe"
-	OrdersPaymentProviderMock   OrdersPaymentProvider = "mock"
-	OrdersPaymentProviderKlix   OrdersPaymentProvider = "klix"
+	OrdersPaymentProviderStripe   OrdersPaymentProvider = "stripe"
+	OrdersPaymentProviderMock     OrdersPaymentProvider = "mock"
+	OrdersPaymentProviderKlix     OrdersPaymentProvider = "klix"
+	OrdersPaymentProviderCash     OrdersPaymentProvider = "cash"
+	OrdersPaymentProviderTerminal OrdersPaymentProvider = "terminal"
 )
 
 func (e *OrdersPaymentProvider) Scan(src interface{}) error {
@@ -100,6 +318,220 @@ func (ns NullOrdersPaymentProvider) Value() (driver.Value, error) {
 	return string(ns.OrdersPaymentProvider), nil
 }
 
+type OrdersPaymentReview string
+
+const (
+	OrdersPaymentReviewUnderpaid OrdersPaymentReview = "underpaid"
+	OrdersPaymentReviewOverpaid  OrdersPaymentReview = "overpaid"
+	OrdersPaymentReviewDisputed  OrdersPaymentReview = "disputed"
+)
+
+func (e *OrdersPaymentReview) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersPaymentReview(s)
+	case string:
+		*e = OrdersPaymentReview(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersPaymentReview: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersPaymentReview struct {
+	OrdersPaymentReview OrdersPaymentReview `json:"orders_payment_review"`
+	Valid               bool                `json:"valid"` // Valid is true if OrdersPaymentReview is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersPaymentReview) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersPaymentReview, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersPaymentReview.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersPaymentReview) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersPaymentReview), nil
+}
+
+type OrdersPaymentStatus string
+
+const (
+	OrdersPaymentStatusSucceeded OrdersPaymentStatus = "succeeded"
+	OrdersPaymentStatusRefunded  OrdersPaymentStatus = "refunded"
+	OrdersPaymentStatusDisputed  OrdersPaymentStatus = "disputed"
+)
+
+func (e *OrdersPaymentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersPaymentStatus(s)
+	case string:
+		*e = OrdersPaymentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersPaymentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersPaymentStatus struct {
+	OrdersPaymentStatus OrdersPaymentStatus `json:"orders_payment_status"`
+	Valid               bool                `json:"valid"` // Valid is true if OrdersPaymentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersPaymentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersPaymentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersPaymentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersPaymentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersPaymentStatus), nil
+}
+
+type OrdersPromotionRuleType string
+
+const (
+	OrdersPromotionRuleTypePercentage OrdersPromotionRuleType = "percentage"
+	OrdersPromotionRuleTypeBuyXGetY   OrdersPromotionRuleType = "buy_x_get_y"
+)
+
+func (e *OrdersPromotionRuleType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersPromotionRuleType(s)
+	case string:
+		*e = OrdersPromotionRuleType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersPromotionRuleType: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersPromotionRuleType struct {
+	OrdersPromotionRuleType OrdersPromotionRuleType `json:"orders_promotion_rule_type"`
+	Valid                   bool                    `json:"valid"` // Valid is true if OrdersPromotionRuleType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersPromotionRuleType) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersPromotionRuleType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersPromotionRuleType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersPromotionRuleType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersPromotionRuleType), nil
+}
+
+type OrdersRefundStatus string
+
+const (
+	OrdersRefundStatusPending   OrdersRefundStatus = "pending"
+	OrdersRefundStatusSucceeded OrdersRefundStatus = "succeeded"
+	OrdersRefundStatusFailed    OrdersRefundStatus = "failed"
+)
+
+func (e *OrdersRefundStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersRefundStatus(s)
+	case string:
+		*e = OrdersRefundStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersRefundStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersRefundStatus struct {
+	OrdersRefundStatus OrdersRefundStatus `json:"orders_refund_status"`
+	Valid              bool               `json:"valid"` // Valid is true if OrdersRefundStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersRefundStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersRefundStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersRefundStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersRefundStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersRefundStatus), nil
+}
+
+type OrdersTipPoolRule string
+
+const (
+	OrdersTipPoolRuleEqual        OrdersTipPoolRule = "equal"
+	OrdersTipPoolRuleTimeAssigned OrdersTipPoolRule = "time_assigned"
+	OrdersTipPoolRuleShiftPool    OrdersTipPoolRule = "shift_pool"
+)
+
+func (e *OrdersTipPoolRule) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrdersTipPoolRule(s)
+	case string:
+		*e = OrdersTipPoolRule(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrdersTipPoolRule: %T", src)
+	}
+	return nil
+}
+
+type NullOrdersTipPoolRule struct {
+	OrdersTipPoolRule OrdersTipPoolRule `json:"orders_tip_pool_rule"`
+	Valid             bool              `json:"valid"` // Valid is true if OrdersTipPoolRule is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrdersTipPoolRule) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrdersTipPoolRule, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrdersTipPoolRule.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrdersTipPoolRule) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrdersTipPoolRule), nil
+}
+
 type ManagementCategory struct {
 	ID          uuid.UUID      `json:"id"`
 	MenuID      uuid.UUID      `json:"menu_id"`
@@ -158,33 +590,92 @@ type ManagementRestaurantsWaiter struct {
 }
 
 type ManagementTable struct {
-	ID           uuid.UUID    `json:"id"`
-	RestaurantID uuid.UUID    `json:"restaurant_id"`
-	Name         string       `json:"name"`
-	Capacity     int          `json:"capacity"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	DeletedAt    sql.NullTime `json:"deleted_at"`
+	ID           uuid.UUID      `json:"id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id"`
+	Name         string         `json:"name"`
+	Capacity     int            `json:"capacity"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	Section      sql.NullString `json:"section"`
+}
+
+type OrdersAssistanceRequest struct {
+	ID             uuid.UUID                     `json:"id"`
+	OrderID        uuid.UUID                     `json:"order_id"`
+	Type           OrdersAssistanceRequestType   `json:"type"`
+	Message        sql.NullString                `json:"message"`
+	Status         OrdersAssistanceRequestStatus `json:"status"`
+	ParticipantID  uuid.NullUUID                 `json:"participant_id"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	AcknowledgedAt sql.NullTime                  `json:"acknowledged_at"`
+	AcknowledgedBy uuid.NullUUID                 `json:"acknowledged_by"`
+	ResolvedAt     sql.NullTime                  `json:"resolved_at"`
+	ResolvedBy     uuid.NullUUID                 `json:"resolved_by"`
+}
+
+type OrdersKitchenTicketItem struct {
+	OrderItemID uuid.UUID `json:"order_item_id"`
+	SentAt      time.Time `json:"sent_at"`
 }
 
 type OrdersOrder struct {
-	ID               uuid.UUID     `json:"id"`
-	TableID          uuid.UUID     `json:"table_id"`
-	Status           OrderStatus   `json:"status"`
-	Currency         string        `json:"currency"`
-	TipAmountInCents sql.NullInt32 `json:"tip_amount_in_cents"`
-	CreatedAt        time.Time     `json:"created_at"`
-	UpdatedAt        time.Time     `json:"updated_at"`
+	ID               uuid.UUID               `json:"id"`
+	TableID          uuid.UUID               `json:"table_id"`
+	Status           OrderStatus             `json:"status"`
+	Currency         string                  `json:"currency"`
+	TipAmountInCents sql.NullInt32           `json:"tip_amount_in_cents"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+	PaymentReview    NullOrdersPaymentReview `json:"payment_review"`
+	CompletedAt      sql.NullTime            `json:"completed_at"`
+}
+
+type OrdersOrderDiscount struct {
+	OrderID   uuid.UUID       `json:"order_id"`
+	Lines     json.RawMessage `json:"lines"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type OrdersOrderEvent struct {
+	OrderID   uuid.UUID       `json:"order_id"`
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type OrdersOrderEventSequence struct {
+	OrderID uuid.UUID `json:"order_id"`
+	LastSeq int64     `json:"last_seq"`
+}
+
+type OrdersOrderParticipant struct {
+	ID          uuid.UUID             `json:"id"`
+	OrderID     uuid.UUID             `json:"order_id"`
+	UserID      uuid.NullUUID         `json:"user_id"`
+	DisplayName string                `json:"display_name"`
+	Role        OrdersParticipantRole `json:"role"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
 }
 
 type OrdersOrdersItem struct {
-	ID           uuid.UUID     `json:"id"`
-	OrderID      uuid.UUID     `json:"order_id"`
-	ItemID       uuid.NullUUID `json:"item_id"`
-	ItemName     string        `json:"item_name"`
-	PriceInCents int           `json:"price_in_cents"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
+	ID            uuid.UUID     `json:"id"`
+	OrderID       uuid.UUID     `json:"order_id"`
+	ItemID        uuid.NullUUID `json:"item_id"`
+	ItemName      string        `json:"item_name"`
+	PriceInCents  int           `json:"price_in_cents"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	ParticipantID uuid.NullUUID `json:"participant_id"`
+}
+
+type OrdersOrdersPromoCode struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	PromoCodeID uuid.UUID `json:"promo_code_id"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type OrdersOrdersWaiter struct {
@@ -206,4 +697,130 @@ type OrdersPayment struct {
 	CreatedAt         time.Time             `json:"created_at"`
 	UpdatedAt         time.Time             `json:"updated_at"`
 	RefundedAt        sql.NullTime          `json:"refunded_at"`
+	Status            OrdersPaymentStatus   `json:"status"`
+	TenderedInCents   sql.NullInt32         `json:"tendered_in_cents"`
+	ChangeDueInCents  sql.NullInt32         `json:"change_due_in_cents"`
+	RecordedBy        uuid.NullUUID         `json:"recorded_by"`
+}
+
+type OrdersPaymentAttempt struct {
+	ID                uuid.UUID                  `json:"id"`
+	OrderID           uuid.UUID                  `json:"order_id"`
+	Provider          OrdersPaymentProvider      `json:"provider"`
+	ProviderSessionID string                     `json:"provider_session_id"`
+	AmountInCents     int                        `json:"amount_in_cents"`
+	Currency          string                     `json:"currency"`
+	Status            OrdersPaymentAttemptStatus `json:"status"`
+	CreatedAt         time.Time                  `json:"created_at"`
+	UpdatedAt         time.Time                  `json:"updated_at"`
+}
+
+type OrdersPromoCode struct {
+	ID              uuid.UUID          `json:"id"`
+	RestaurantID    uuid.UUID          `json:"restaurant_id"`
+	Code            string             `json:"code"`
+	DiscountType    OrdersDiscountType `json:"discount_type"`
+	DiscountValue   int                `json:"discount_value"`
+	MinSpendInCents int                `json:"min_spend_in_cents"`
+	ValidFrom       sql.NullTime       `json:"valid_from"`
+	ValidUntil      sql.NullTime       `json:"valid_until"`
+	MaxUses         sql.NullInt32      `json:"max_uses"`
+	UsesCount       int                `json:"uses_count"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	DeletedAt       sql.NullTime       `json:"deleted_at"`
+}
+
+type OrdersPromotionRule struct {
+	ID           uuid.UUID               `json:"id"`
+	RestaurantID uuid.UUID               `json:"restaurant_id"`
+	Name         string                  `json:"name"`
+	RuleType     OrdersPromotionRuleType `json:"rule_type"`
+	CategoryID   uuid.NullUUID           `json:"category_id"`
+	PercentOff   int                     `json:"percent_off"`
+	BuyQuantity  int                     `json:"buy_quantity"`
+	FreeQuantity int                     `json:"free_quantity"`
+	StartsAt     time.Time               `json:"starts_at"`
+	EndsAt       time.Time               `json:"ends_at"`
+	Timezone     string                  `json:"timezone"`
+	IsActive     bool                    `json:"is_active"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+	DeletedAt    sql.NullTime            `json:"deleted_at"`
+}
+
+type OrdersReceipt struct {
+	ID            uuid.UUID       `json:"id"`
+	OrderID       uuid.UUID       `json:"order_id"`
+	RestaurantID  uuid.UUID       `json:"restaurant_id"`
+	ReceiptNumber int             `json:"receipt_number"`
+	TaxRate       float64         `json:"tax_rate"`
+	IssuedAt      time.Time       `json:"issued_at"`
+	Contents      json.RawMessage `json:"contents"`
+}
+
+type OrdersRefund struct {
+	ID               uuid.UUID             `json:"id"`
+	PaymentID        uuid.UUID             `json:"payment_id"`
+	OrderID          uuid.UUID             `json:"order_id"`
+	AmountInCents    int                   `json:"amount_in_cents"`
+	Currency         string                `json:"currency"`
+	Provider         OrdersPaymentProvider `json:"provider"`
+	ProviderRefundID sql.NullString        `json:"provider_refund_id"`
+	Status           OrdersRefundStatus    `json:"status"`
+	Reason           string                `json:"reason"`
+	OrderItemIds     []uuid.UUID           `json:"order_item_ids"`
+	RequestedBy      uuid.NullUUID         `json:"requested_by"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+	ConfirmedAt      sql.NullTime          `json:"confirmed_at"`
+}
+
+type OrdersRestaurantPaymentProvider struct {
+	RestaurantID uuid.UUID             `json:"restaurant_id"`
+	Provider     OrdersPaymentProvider `json:"provider"`
+	Credentials  []byte                `json:"credentials"`
+	IsDefault    bool                  `json:"is_default"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+type OrdersRestaurantPrinter struct {
+	ID             uuid.UUID   `json:"id"`
+	RestaurantID   uuid.UUID   `json:"restaurant_id"`
+	Name           string      `json:"name"`
+	Host           string      `json:"host"`
+	Port           int         `json:"port"`
+	CategoryIds    []uuid.UUID `json:"category_ids"`
+	PrintsReceipts bool        `json:"prints_receipts"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+type OrdersRestaurantReceiptCounter struct {
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	LastNumber   int       `json:"last_number"`
+}
+
+type OrdersRestaurantReceiptSetting struct {
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+	TaxRate      float64   `json:"tax_rate"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type OrdersRestaurantTipSetting struct {
+	RestaurantID     uuid.UUID         `json:"restaurant_id"`
+	Presets          []float64         `json:"presets"`
+	PoolRule         OrdersTipPoolRule `json:"pool_rule"`
+	ShiftLengthHours int               `json:"shift_length_hours"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+type OrdersWebhookEvent struct {
+	Provider     OrdersPaymentProvider `json:"provider"`
+	EventID      string                `json:"event_id"`
+	RestaurantID uuid.NullUUID         `json:"restaurant_id"`
+	ProcessedAt  time.Time             `json:"processed_at"`
 }
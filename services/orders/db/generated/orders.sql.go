@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const addOrderItem = `-- name: AddOrderItem :one
@@ -19,17 +20,19 @@ INSERT INTO orders.orders_items (
     order_id,
     item_id,
     item_name,
-    price_in_cents
-) VALUES ($1, $2, $3, $4, $5)
-RETURNING id, order_id, item_id, item_name, price_in_cents, created_at, updated_at
+    price_in_cents,
+    participant_id
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, order_id, item_id, item_name, price_in_cents, created_at, updated_at, participant_id
 `
 
 type AddOrderItemParams struct {
-	ID           uuid.UUID     `json:"id"`
-	OrderID      uuid.UUID     `json:"order_id"`
-	ItemID       uuid.NullUUID `json:"item_id"`
-	ItemName     string        `json:"item_name"`
-	PriceInCents int           `json:"price_in_cents"`
+	ID            uuid.UUID     `json:"id"`
+	OrderID       uuid.UUID     `json:"order_id"`
+	ItemID        uuid.NullUUID `json:"item_id"`
+	ItemName      string        `json:"item_name"`
+	PriceInCents  int           `json:"price_in_cents"`
+	ParticipantID uuid.NullUUID `json:"participant_id"`
 }
 
 func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (OrdersOrdersItem, error) {
@@ -39,6 +42,7 @@ func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (Ord
 		arg.ItemID,
 		arg.ItemName,
 		arg.PriceInCents,
+		arg.ParticipantID,
 	)
 	var i OrdersOrdersItem
 	err := row.Scan(
@@ -49,6 +53,7 @@ func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (Ord
 		&i.PriceInCents,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParticipantID,
 	)
 	return i, err
 }
@@ -107,7 +112,7 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (uuid.
 const deleteOrderItem = `-- name: DeleteOrderItem :one
 DELETE FROM orders.orders_items 
 WHERE id = $1 and order_id = $2
-RETURNING id, order_id, item_id, item_name, price_in_cents, created_at, updated_at
+RETURNING id, order_id, item_id, item_name, price_in_cents, created_at, updated_at, participant_id
 `
 
 type DeleteOrderItemParams struct {
@@ -126,6 +131,7 @@ func (q *Queries) DeleteOrderItem(ctx context.Context, arg DeleteOrderItemParams
 		&i.PriceInCents,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParticipantID,
 	)
 	return i, err
 }
@@ -153,7 +159,9 @@ SELECT
     i.id,
     m.id as restaurant_id,
     i.name,
-    i.price_in_cents
+    i.price_in_cents,
+    i.category_id,
+    i.is_available
 FROM management.items i 
     LEFT JOIN management.categories c on c.id = i.category_id
     LEFT JOIN management.menus m on m.id = c.menu_id
@@ -165,6 +173,8 @@ type GetMenuItemRow struct {
 	RestaurantID uuid.NullUUID `json:"restaurant_id"`
 	Name         string        `json:"name"`
 	PriceInCents int           `json:"price_in_cents"`
+	CategoryID   uuid.NullUUID `json:"category_id"`
+	IsAvailable  bool          `json:"is_available"`
 }
 
 func (q *Queries) GetMenuItem(ctx context.Context, id uuid.UUID) (GetMenuItemRow, error) {
@@ -175,6 +185,8 @@ func (q *Queries) GetMenuItem(ctx context.Context, id uuid.UUID) (GetMenuItemRow
 		&i.RestaurantID,
 		&i.Name,
 		&i.PriceInCents,
+		&i.CategoryID,
+		&i.IsAvailable,
 	)
 	return i, err
 }
@@ -187,30 +199,70 @@ SELECT
     o.status,
     o.currency,
     o.tip_amount_in_cents,
+    o.payment_review,
     o.updated_at,
+    (
+        COALESCE((
+            SELECT SUM(p.amount_in_cents)
+            FROM orders.payments p
+            WHERE p.order_id = o.id AND p.status <> 'refunded'
+        ), 0) - COALESCE((
+            SELECT SUM(r.amount_in_cents)
+            FROM orders.refunds r
+                JOIN orders.payments p ON p.id = r.payment_id
+            WHERE r.order_id = o.id AND r.status = 'succeeded' AND p.status <> 'refunded'
+        ), 0)
+    )::int as amount_paid_in_cents,
     i.id as order_item_id,
     i.item_id,
     i.item_name,
-    i.price_in_cents
+    i.price_in_cents,
+    i.created_at as item_created_at,
+    mi.category_id,
+    i.participant_id,
+    op.display_name as participant_display_name,
+    op.role as participant_role,
+    pc.id as promo_code_id,
+    pc.code as promo_code,
+    pc.discount_type as promo_discount_type,
+    pc.discount_value as promo_discount_value,
+    pc.min_spend_in_cents as promo_min_spend_in_cents
 FROM orders.orders o
     LEFT JOIN orders.orders_items i ON o.id = i.order_id
+    LEFT JOIN management.items mi on mi.id = i.item_id
+    LEFT JOIN orders.order_participants op on op.id = i.participant_id
     LEFT JOIN management.tables t on t.id = o.table_id
     LEFT JOIN management.restaurants r on r.id = t.restaurant_id
+    LEFT JOIN orders.orders_promo_codes opc on opc.order_id = o.id
+    LEFT JOIN orders.promo_codes pc on pc.id = opc.promo_code_id
 WHERE o.id = $1
+ORDER BY i.created_at
 `
 
 type GetOrderItemsRow struct {
-	ID               uuid.UUID      `json:"id"`
-	RestaurantID     uuid.NullUUID  `json:"restaurant_id"`
-	RestaurantName   sql.NullString `json:"restaurant_name"`
-	Status           OrderStatus    `json:"status"`
-	Currency         string         `json:"currency"`
-	TipAmountInCents sql.NullInt32  `json:"tip_amount_in_cents"`
-	UpdatedAt        time.Time      `json:"updated_at"`
-	OrderItemID      uuid.NullUUID  `json:"order_item_id"`
-	ItemID           uuid.NullUUID  `json:"item_id"`
-	ItemName         sql.NullString `json:"item_name"`
-	PriceInCents     sql.NullInt32  `json:"price_in_cents"`
+	ID                     uuid.UUID                 `json:"id"`
+	RestaurantID           uuid.NullUUID             `json:"restaurant_id"`
+	RestaurantName         sql.NullString            `json:"restaurant_name"`
+	Status                 OrderStatus               `json:"status"`
+	Currency               string                    `json:"currency"`
+	TipAmountInCents       sql.NullInt32             `json:"tip_amount_in_cents"`
+	PaymentReview          NullOrdersPaymentReview   `json:"payment_review"`
+	UpdatedAt              time.Time                 `json:"updated_at"`
+	AmountPaidInCents      int                       `json:"amount_paid_in_cents"`
+	OrderItemID            uuid.NullUUID             `json:"order_item_id"`
+	ItemID                 uuid.NullUUID             `json:"item_id"`
+	ItemName               sql.NullString            `json:"item_name"`
+	PriceInCents           sql.NullInt32             `json:"price_in_cents"`
+	ItemCreatedAt          sql.NullTime              `json:"item_created_at"`
+	CategoryID             uuid.NullUUID             `json:"category_id"`
+	ParticipantID          uuid.NullUUID             `json:"participant_id"`
+	ParticipantDisplayName sql.NullString            `json:"participant_display_name"`
+	ParticipantRole        NullOrdersParticipantRole `json:"participant_role"`
+	PromoCodeID            uuid.NullUUID             `json:"promo_code_id"`
+	PromoCode              sql.NullString            `json:"promo_code"`
+	PromoDiscountType      NullOrdersDiscountType    `json:"promo_discount_type"`
+	PromoDiscountValue     sql.NullInt32             `json:"promo_discount_value"`
+	PromoMinSpendInCents   sql.NullInt32             `json:"promo_min_spend_in_cents"`
 }
 
 func (q *Queries) GetOrderItems(ctx context.Context, id uuid.UUID) ([]GetOrderItemsRow, error) {
@@ -229,11 +281,23 @@ func (q *Queries) GetOrderItems(ctx context.Context, id uuid.UUID) ([]GetOrderIt
 			&i.Status,
 			&i.Currency,
 			&i.TipAmountInCents,
+			&i.PaymentReview,
 			&i.UpdatedAt,
+			&i.AmountPaidInCents,
 			&i.OrderItemID,
 			&i.ItemID,
 			&i.ItemName,
 			&i.PriceInCents,
+			&i.ItemCreatedAt,
+			&i.CategoryID,
+			&i.ParticipantID,
+			&i.ParticipantDisplayName,
+			&i.ParticipantRole,
+			&i.PromoCodeID,
+			&i.PromoCode,
+			&i.PromoDiscountType,
+			&i.PromoDiscountValue,
+			&i.PromoMinSpendInCents,
 		); err != nil {
 			return nil, err
 		}
@@ -248,6 +312,48 @@ func (q *Queries) GetOrderItems(ctx context.Context, id uuid.UUID) ([]GetOrderIt
 	return items, nil
 }
 
+const getOrderTable = `-- name: GetOrderTable :one
+SELECT
+    o.id,
+    t.id as table_id,
+    t.restaurant_id,
+    t.name as table_name,
+    t.section,
+    COALESCE(
+        array_agg(ow.user_id) FILTER (WHERE ow.user_id IS NOT NULL),
+        '{}'
+    )::uuid[] as waiter_ids
+FROM orders.orders o
+    JOIN management.tables t ON t.id = o.table_id
+    LEFT JOIN orders.orders_waiters ow ON ow.order_id = o.id
+WHERE o.id = $1
+GROUP BY o.id, t.id
+`
+
+type GetOrderTableRow struct {
+	ID           uuid.UUID      `json:"id"`
+	TableID      uuid.UUID      `json:"table_id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id"`
+	TableName    string         `json:"table_name"`
+	Section      sql.NullString `json:"section"`
+	WaiterIds    []uuid.UUID    `json:"waiter_ids"`
+}
+
+// Table the order is for and waiters assigned to it
+func (q *Queries) GetOrderTable(ctx context.Context, id uuid.UUID) (GetOrderTableRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderTable, id)
+	var i GetOrderTableRow
+	err := row.Scan(
+		&i.ID,
+		&i.TableID,
+		&i.RestaurantID,
+		&i.TableName,
+		&i.Section,
+		pq.Array(&i.WaiterIds),
+	)
+	return i, err
+}
+
 const getTableCurrency = `-- name: GetTableCurrency :one
 SELECT r.currency
 FROM management.restaurants r
@@ -261,6 +367,32 @@ func (q *Queries) GetTableCurrency(ctx context.Context, id uuid.UUID) (string, e
 	return currency, err
 }
 
+const isUserRestaurantManager = `-- name: IsUserRestaurantManager :one
+SELECT id, user_id, restaurant_id, created_at, updated_at
+FROM management.restaurants_managers
+WHERE user_id = $1
+  AND restaurant_id = $2
+`
+
+type IsUserRestaurantManagerParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	RestaurantID uuid.UUID `json:"restaurant_id"`
+}
+
+// Check if a user is a manager for a given restaurant
+func (q *Queries) IsUserRestaurantManager(ctx context.Context, arg IsUserRestaurantManagerParams) (ManagementRestaurantsManager, error) {
+	row := q.db.QueryRowContext(ctx, isUserRestaurantManager, arg.UserID, arg.RestaurantID)
+	var i ManagementRestaurantsManager
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RestaurantID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const isUserRestaurantWaiter = `-- name: IsUserRestaurantWaiter :one
 SELECT id, user_id, restaurant_id, created_at, updated_at
 FROM management.restaurants_waiters
@@ -287,6 +419,21 @@ func (q *Queries) IsUserRestaurantWaiter(ctx context.Context, arg IsUserRestaura
 	return i, err
 }
 
+const lockOrder = `-- name: LockOrder :one
+SELECT id
+FROM orders.orders
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks the order row until the transaction ends, so payments of the order are settled one
+// at a time
+func (q *Queries) LockOrder(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, lockOrder, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const removeWaiterFromOrder = `-- name: RemoveWaiterFromOrder :one
 DELETE FROM orders.orders_waiters 
 WHERE id = $1 and order_id = $2 and user_id = $3
@@ -313,14 +460,36 @@ func (q *Queries) RemoveWaiterFromOrder(ctx context.Context, arg RemoveWaiterFro
 	return i, err
 }
 
+const setOrderPaymentReview = `-- name: SetOrderPaymentReview :exec
+UPDATE orders.orders
+SET
+    payment_review = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetOrderPaymentReviewParams struct {
+	ID            uuid.UUID               `json:"id"`
+	PaymentReview NullOrdersPaymentReview `json:"payment_review"`
+}
+
+func (q *Queries) SetOrderPaymentReview(ctx context.Context, arg SetOrderPaymentReviewParams) error {
+	_, err := q.db.ExecContext(ctx, setOrderPaymentReview, arg.ID, arg.PaymentReview)
+	return err
+}
+
 const updateOrder = `-- name: UpdateOrder :one
 UPDATE orders.orders
 SET
     status = COALESCE($2, status),
     tip_amount_in_cents = COALESCE($3, tip_amount_in_cents),
+    completed_at = CASE
+        WHEN $2 = 'completed' THEN COALESCE(completed_at, NOW())
+        ELSE completed_at
+    END,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, table_id, status, currency, tip_amount_in_cents, created_at, updated_at
+RETURNING id, table_id, status, currency, tip_amount_in_cents, created_at, updated_at, payment_review, completed_at
 `
 
 type UpdateOrderParams struct {
@@ -329,6 +498,8 @@ type UpdateOrderParams struct {
 	TipAmountInCents sql.NullInt32   `json:"tip_amount_in_cents"`
 }
 
+// Updates status and tip of the order, completion time is set the first time the order is
+// completed
 func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (OrdersOrder, error) {
 	row := q.db.QueryRowContext(ctx, updateOrder, arg.ID, arg.Status, arg.TipAmountInCents)
 	var i OrdersOrder
@@ -340,6 +511,8 @@ func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order
 		&i.TipAmountInCents,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PaymentReview,
+		&i.CompletedAt,
 	)
 	return i, err
 }
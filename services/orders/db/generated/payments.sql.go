@@ -7,10 +7,205 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const getOrderAmountPaid = `-- name: GetOrderAmountPaid :one
+SELECT (
+    COALESCE((
+        SELECT SUM(p.amount_in_cents)
+        FROM orders.payments p
+        WHERE p.order_id = $1 AND p.status <> 'refunded'
+    ), 0) - COALESCE((
+        SELECT SUM(r.amount_in_cents)
+        FROM orders.refunds r
+            JOIN orders.payments p ON p.id = r.payment_id
+        WHERE r.order_id = $1 AND r.status = 'succeeded' AND p.status <> 'refunded'
+    ), 0)
+)::int as amount_paid_in_cents
+`
+
+// Amount paid for the order with succeeded refunds subtracted, payments provider reported as
+// fully refunded are left out together with their refunds
+func (q *Queries) GetOrderAmountPaid(ctx context.Context, orderID uuid.UUID) (int, error) {
+	row := q.db.QueryRowContext(ctx, getOrderAmountPaid, orderID)
+	var amount_paid_in_cents int
+	err := row.Scan(&amount_paid_in_cents)
+	return amount_paid_in_cents, err
+}
+
+const getOrderPaymentAttempts = `-- name: GetOrderPaymentAttempts :many
+SELECT id, order_id, provider, provider_session_id, amount_in_cents, currency, status, created_at, updated_at
+FROM orders.payment_attempts
+WHERE order_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetOrderPaymentAttempts(ctx context.Context, orderID uuid.UUID) ([]OrdersPaymentAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderPaymentAttempts, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrdersPaymentAttempt
+	for rows.Next() {
+		var i OrdersPaymentAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.Provider,
+			&i.ProviderSessionID,
+			&i.AmountInCents,
+			&i.Currency,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderPayments = `-- name: GetOrderPayments :many
+SELECT
+    p.id,
+    p.order_id,
+    p.amount_in_cents,
+    p.currency,
+    p.provider,
+    p.provider_payment_id,
+    p.status,
+    p.tendered_in_cents,
+    p.change_due_in_cents,
+    p.recorded_by,
+    p.created_at,
+    p.refunded_at,
+    COALESCE((
+        SELECT SUM(r.amount_in_cents)
+        FROM orders.refunds r
+        WHERE r.payment_id = p.id AND r.status <> 'failed'
+    ), 0)::int as refunded_amount_in_cents
+FROM orders.payments p
+WHERE p.order_id = $1
+ORDER BY p.created_at DESC
+`
+
+type GetOrderPaymentsRow struct {
+	ID                    uuid.UUID             `json:"id"`
+	OrderID               uuid.UUID             `json:"order_id"`
+	AmountInCents         int                   `json:"amount_in_cents"`
+	Currency              string                `json:"currency"`
+	Provider              OrdersPaymentProvider `json:"provider"`
+	ProviderPaymentID     string                `json:"provider_payment_id"`
+	Status                OrdersPaymentStatus   `json:"status"`
+	TenderedInCents       sql.NullInt32         `json:"tendered_in_cents"`
+	ChangeDueInCents      sql.NullInt32         `json:"change_due_in_cents"`
+	RecordedBy            uuid.NullUUID         `json:"recorded_by"`
+	CreatedAt             time.Time             `json:"created_at"`
+	RefundedAt            sql.NullTime          `json:"refunded_at"`
+	RefundedAmountInCents int                   `json:"refunded_amount_in_cents"`
+}
+
+func (q *Queries) GetOrderPayments(ctx context.Context, orderID uuid.UUID) ([]GetOrderPaymentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderPayments, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderPaymentsRow
+	for rows.Next() {
+		var i GetOrderPaymentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.AmountInCents,
+			&i.Currency,
+			&i.Provider,
+			&i.ProviderPaymentID,
+			&i.Status,
+			&i.TenderedInCents,
+			&i.ChangeDueInCents,
+			&i.RecordedBy,
+			&i.CreatedAt,
+			&i.RefundedAt,
+			&i.RefundedAmountInCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderRefundedItemIDs = `-- name: GetOrderRefundedItemIDs :many
+SELECT DISTINCT unnest(r.order_item_ids)::uuid AS order_item_id
+FROM orders.refunds r
+WHERE r.order_id = $1 AND r.status <> 'failed'
+`
+
+// Order items covered by refunds that are pending or succeeded
+func (q *Queries) GetOrderRefundedItemIDs(ctx context.Context, orderID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderRefundedItemIDs, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var order_item_id uuid.UUID
+		if err := rows.Scan(&order_item_id); err != nil {
+			return nil, err
+		}
+		items = append(items, order_item_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPaymentRefunded = `-- name: MarkPaymentRefunded :exec
+UPDATE orders.payments p
+SET
+    status = 'refunded',
+    refunded_at = NOW(),
+    updated_at = NOW()
+WHERE p.id = $1
+    AND p.refunded_at IS NULL
+    AND p.amount_in_cents <= (
+        SELECT COALESCE(SUM(r.amount_in_cents), 0)
+        FROM orders.refunds r
+        WHERE r.payment_id = p.id AND r.status = 'succeeded'
+    )
+`
+
+// Sets refunded_at once succeeded refunds cover the whole payment amount
+func (q *Queries) MarkPaymentRefunded(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markPaymentRefunded, id)
+	return err
+}
+
 const savePayment = `-- name: SavePayment :one
 INSERT INTO orders.payments (
     id,
@@ -18,9 +213,13 @@ INSERT INTO orders.payments (
     amount_in_cents,
     currency,
     provider,
-    provider_payment_id
-) VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, order_id, amount_in_cents, currency, provider, provider_payment_id, created_at, updated_at, refunded_at
+    provider_payment_id,
+    tendered_in_cents,
+    change_due_in_cents,
+    recorded_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (provider, provider_payment_id) DO NOTHING
+RETURNING id, order_id, amount_in_cents, currency, provider, provider_payment_id, created_at, updated_at, refunded_at, status, tendered_in_cents, change_due_in_cents, recorded_by
 `
 
 type SavePaymentParams struct {
@@ -30,6 +229,9 @@ type SavePaymentParams struct {
 	Currency          string                `json:"currency"`
 	Provider          OrdersPaymentProvider `json:"provider"`
 	ProviderPaymentID string                `json:"provider_payment_id"`
+	TenderedInCents   sql.NullInt32         `json:"tendered_in_cents"`
+	ChangeDueInCents  sql.NullInt32         `json:"change_due_in_cents"`
+	RecordedBy        uuid.NullUUID         `json:"recorded_by"`
 }
 
 func (q *Queries) SavePayment(ctx context.Context, arg SavePaymentParams) (OrdersPayment, error) {
@@ -40,6 +242,9 @@ func (q *Queries) SavePayment(ctx context.Context, arg SavePaymentParams) (Order
 		arg.Currency,
 		arg.Provider,
 		arg.ProviderPaymentID,
+		arg.TenderedInCents,
+		arg.ChangeDueInCents,
+		arg.RecordedBy,
 	)
 	var i OrdersPayment
 	err := row.Scan(
@@ -52,6 +257,305 @@ func (q *Queries) SavePayment(ctx context.Context, arg SavePaymentParams) (Order
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RefundedAt,
+		&i.Status,
+		&i.TenderedInCents,
+		&i.ChangeDueInCents,
+		&i.RecordedBy,
+	)
+	return i, err
+}
+
+const savePaymentAttempt = `-- name: SavePaymentAttempt :one
+INSERT INTO orders.payment_attempts (
+    id,
+    order_id,
+    provider,
+    provider_session_id,
+    amount_in_cents,
+    currency
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, order_id, provider, provider_session_id, amount_in_cents, currency, status, created_at, updated_at
+`
+
+type SavePaymentAttemptParams struct {
+	ID                uuid.UUID             `json:"id"`
+	OrderID           uuid.UUID             `json:"order_id"`
+	Provider          OrdersPaymentProvider `json:"provider"`
+	ProviderSessionID string                `json:"provider_session_id"`
+	AmountInCents     int                   `json:"amount_in_cents"`
+	Currency          string                `json:"currency"`
+}
+
+func (q *Queries) SavePaymentAttempt(ctx context.Context, arg SavePaymentAttemptParams) (OrdersPaymentAttempt, error) {
+	row := q.db.QueryRowContext(ctx, savePaymentAttempt,
+		arg.ID,
+		arg.OrderID,
+		arg.Provider,
+		arg.ProviderSessionID,
+		arg.AmountInCents,
+		arg.Currency,
+	)
+	var i OrdersPaymentAttempt
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.Provider,
+		&i.ProviderSessionID,
+		&i.AmountInCents,
+		&i.Currency,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const saveRefund = `-- name: SaveRefund :one
+INSERT INTO orders.refunds (
+    id,
+    payment_id,
+    order_id,
+    amount_in_cents,
+    currency,
+    provider,
+    provider_refund_id,
+    status,
+    reason,
+    order_item_ids,
+    requested_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, payment_id, order_id, amount_in_cents, currency, provider, provider_refund_id, status, reason, order_item_ids, requested_by, created_at, updated_at, confirmed_at
+`
+
+type SaveRefundParams struct {
+	ID               uuid.UUID             `json:"id"`
+	PaymentID        uuid.UUID             `json:"payment_id"`
+	OrderID          uuid.UUID             `json:"order_id"`
+	AmountInCents    int                   `json:"amount_in_cents"`
+	Currency         string                `json:"currency"`
+	Provider         OrdersPaymentProvider `json:"provider"`
+	ProviderRefundID sql.NullString        `json:"provider_refund_id"`
+	Status           OrdersRefundStatus    `json:"status"`
+	Reason           string                `json:"reason"`
+	OrderItemIds     []uuid.UUID           `json:"order_item_ids"`
+	RequestedBy      uuid.NullUUID         `json:"requested_by"`
+}
+
+func (q *Queries) SaveRefund(ctx context.Context, arg SaveRefundParams) (OrdersRefund, error) {
+	row := q.db.QueryRowContext(ctx, saveRefund,
+		arg.ID,
+		arg.PaymentID,
+		arg.OrderID,
+		arg.AmountInCents,
+		arg.Currency,
+		arg.Provider,
+		arg.ProviderRefundID,
+		arg.Status,
+		arg.Reason,
+		pq.Array(arg.OrderItemIds),
+		arg.RequestedBy,
+	)
+	var i OrdersRefund
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentID,
+		&i.OrderID,
+		&i.AmountInCents,
+		&i.Currency,
+		&i.Provider,
+		&i.ProviderRefundID,
+		&i.Status,
+		&i.Reason,
+		pq.Array(&i.OrderItemIds),
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const saveWebhookEvent = `-- name: SaveWebhookEvent :execrows
+INSERT INTO orders.webhook_events (
+    provider,
+    event_id,
+    restaurant_id
+) VALUES ($1, $2, $3)
+ON CONFLICT (provider, event_id) DO NOTHING
+`
+
+type SaveWebhookEventParams struct {
+	Provider     OrdersPaymentProvider `json:"provider"`
+	EventID      string                `json:"event_id"`
+	RestaurantID uuid.NullUUID         `json:"restaurant_id"`
+}
+
+// Records provider's webhook event, nothing is inserted when the event was already processed
+func (q *Queries) SaveWebhookEvent(ctx context.Context, arg SaveWebhookEventParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, saveWebhookEvent, arg.Provider, arg.EventID, arg.RestaurantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updatePaymentAttemptStatus = `-- name: UpdatePaymentAttemptStatus :exec
+UPDATE orders.payment_attempts
+SET
+    status = $5,
+    updated_at = NOW()
+WHERE order_id = $1
+    AND (id = $2 OR (provider = $3 AND provider_session_id = $4))
+    AND status IN ('pending', 'failed')
+`
+
+type UpdatePaymentAttemptStatusParams struct {
+	OrderID           uuid.UUID                  `json:"order_id"`
+	ID                uuid.UUID                  `json:"id"`
+	Provider          OrdersPaymentProvider      `json:"provider"`
+	ProviderSessionID string                     `json:"provider_session_id"`
+	Status            OrdersPaymentAttemptStatus `json:"status"`
+}
+
+// Attempt is found by its id echoed back by provider or by provider's session id,
+// only pending and failed attempts can still change
+func (q *Queries) UpdatePaymentAttemptStatus(ctx context.Context, arg UpdatePaymentAttemptStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updatePaymentAttemptStatus,
+		arg.OrderID,
+		arg.ID,
+		arg.Provider,
+		arg.ProviderSessionID,
+		arg.Status,
+	)
+	return err
+}
+
+const updatePaymentStatus = `-- name: UpdatePaymentStatus :one
+UPDATE orders.payments
+SET
+    status = $3,
+    refunded_at = CASE WHEN $3 = 'refunded' THEN COALESCE(refunded_at, NOW()) ELSE refunded_at END,
+    updated_at = NOW()
+WHERE provider = $1 AND provider_payment_id = $2
+RETURNING id, order_id, amount_in_cents, currency, provider, provider_payment_id, created_at, updated_at, refunded_at, status, tendered_in_cents, change_due_in_cents, recorded_by
+`
+
+type UpdatePaymentStatusParams struct {
+	Provider          OrdersPaymentProvider `json:"provider"`
+	ProviderPaymentID string                `json:"provider_payment_id"`
+	Status            OrdersPaymentStatus   `json:"status"`
+}
+
+func (q *Queries) UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (OrdersPayment, error) {
+	row := q.db.QueryRowContext(ctx, updatePaymentStatus, arg.Provider, arg.ProviderPaymentID, arg.Status)
+	var i OrdersPayment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.AmountInCents,
+		&i.Currency,
+		&i.Provider,
+		&i.ProviderPaymentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RefundedAt,
+		&i.Status,
+		&i.TenderedInCents,
+		&i.ChangeDueInCents,
+		&i.RecordedBy,
+	)
+	return i, err
+}
+
+const updateRefundFromProvider = `-- name: UpdateRefundFromProvider :one
+UPDATE orders.refunds
+SET
+    provider_refund_id = $1,
+    status = CASE
+        WHEN status = 'pending' THEN $2::orders.refund_status
+        ELSE status
+    END,
+    confirmed_at = CASE
+        WHEN status = 'pending' AND $2 = 'succeeded' THEN NOW()
+        ELSE confirmed_at
+    END,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING id, payment_id, order_id, amount_in_cents, currency, provider, provider_refund_id, status, reason, order_item_ids, requested_by, created_at, updated_at, confirmed_at
+`
+
+type UpdateRefundFromProviderParams struct {
+	ProviderRefundID sql.NullString     `json:"provider_refund_id"`
+	Status           OrdersRefundStatus `json:"status"`
+	ID               uuid.UUID          `json:"id"`
+}
+
+// Sets refund's id at the provider and the status it was created with, unless a webhook
+// already moved the refund on
+func (q *Queries) UpdateRefundFromProvider(ctx context.Context, arg UpdateRefundFromProviderParams) (OrdersRefund, error) {
+	row := q.db.QueryRowContext(ctx, updateRefundFromProvider, arg.ProviderRefundID, arg.Status, arg.ID)
+	var i OrdersRefund
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentID,
+		&i.OrderID,
+		&i.AmountInCents,
+		&i.Currency,
+		&i.Provider,
+		&i.ProviderRefundID,
+		&i.Status,
+		&i.Reason,
+		pq.Array(&i.OrderItemIds),
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const updateRefundStatus = `-- name: UpdateRefundStatus :one
+UPDATE orders.refunds
+SET
+    provider_refund_id = $2,
+    status = $3,
+    confirmed_at = CASE WHEN $3 = 'succeeded' THEN NOW() ELSE confirmed_at END,
+    updated_at = NOW()
+WHERE provider = $1 AND (provider_refund_id = $2 OR id = $4)
+RETURNING id, payment_id, order_id, amount_in_cents, currency, provider, provider_refund_id, status, reason, order_item_ids, requested_by, created_at, updated_at, confirmed_at
+`
+
+type UpdateRefundStatusParams struct {
+	Provider         OrdersPaymentProvider `json:"provider"`
+	ProviderRefundID sql.NullString        `json:"provider_refund_id"`
+	Status           OrdersRefundStatus    `json:"status"`
+	ID               uuid.UUID             `json:"id"`
+}
+
+// Refunds are matched by our id too, provider may confirm the refund before its id is set
+func (q *Queries) UpdateRefundStatus(ctx context.Context, arg UpdateRefundStatusParams) (OrdersRefund, error) {
+	row := q.db.QueryRowContext(ctx, updateRefundStatus,
+		arg.Provider,
+		arg.ProviderRefundID,
+		arg.Status,
+		arg.ID,
+	)
+	var i OrdersRefund
+	err := row.Scan(
+		&i.ID,
+		&i.PaymentID,
+		&i.OrderID,
+		&i.AmountInCents,
+		&i.Currency,
+		&i.Provider,
+		&i.ProviderRefundID,
+		&i.Status,
+		&i.Reason,
+		pq.Array(&i.OrderItemIds),
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ConfirmedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,2 @@
+ALTER TABLE orders.orders DROP COLUMN IF EXISTS payment_review;
+DROP TYPE IF EXISTS orders.payment_review;
@@ -0,0 +1,7 @@
+CREATE TYPE orders.payment_review AS ENUM (
+    'underpaid',
+    'overpaid'
+);
+
+ALTER TABLE orders.orders
+    ADD COLUMN payment_review orders.payment_review;
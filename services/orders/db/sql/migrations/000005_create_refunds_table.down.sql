@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orders.refunds;
+DROP TYPE IF EXISTS orders.refund_status;
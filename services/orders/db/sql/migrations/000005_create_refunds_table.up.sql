@@ -0,0 +1,36 @@
+CREATE TYPE orders.refund_status AS ENUM (
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE orders.refunds (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL,
+    order_id UUID NOT NULL,
+    amount_in_cents INT NOT NULL,
+    currency varchar(3) NOT NULL,
+    provider orders.payment_provider NOT NULL,
+    provider_refund_id varchar(50) NOT NULL,
+    status orders.refund_status NOT NULL DEFAULT 'pending',
+    reason varchar(255) NOT NULL,
+    order_item_ids UUID[] NOT NULL DEFAULT '{}',
+    requested_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    confirmed_at TIMESTAMPTZ,
+
+    CONSTRAINT fk_refund_payment FOREIGN KEY (payment_id)
+        REFERENCES orders.payments (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_refund_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_refund_user FOREIGN KEY (requested_by)
+        REFERENCES auth.users (id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT uq_provider_refund UNIQUE (provider, provider_refund_id)
+);
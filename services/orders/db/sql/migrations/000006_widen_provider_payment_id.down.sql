@@ -0,0 +1,2 @@
+ALTER TABLE orders.payments
+    ALTER COLUMN provider_payment_id TYPE varchar(30);
@@ -0,0 +1,3 @@
+-- klix purchase ids are UUIDs, which don't fit into the original stripe sized column
+ALTER TABLE orders.payments
+    ALTER COLUMN provider_payment_id TYPE varchar(50);
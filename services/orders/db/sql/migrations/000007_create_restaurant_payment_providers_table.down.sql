@@ -0,0 +1 @@
+DROP TABLE IF EXISTS orders.restaurant_payment_providers;
@@ -0,0 +1,18 @@
+CREATE TABLE orders.restaurant_payment_providers (
+    restaurant_id UUID NOT NULL,
+    provider orders.payment_provider NOT NULL,
+    credentials BYTEA NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (restaurant_id, provider),
+
+    CONSTRAINT fk_payment_provider_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_restaurant_default_payment_provider
+    ON orders.restaurant_payment_providers (restaurant_id)
+    WHERE is_default;
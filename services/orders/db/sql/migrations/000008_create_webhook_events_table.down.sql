@@ -0,0 +1,2 @@
+ALTER TABLE orders.payments DROP CONSTRAINT IF EXISTS uq_provider_payment;
+DROP TABLE IF EXISTS orders.webhook_events;
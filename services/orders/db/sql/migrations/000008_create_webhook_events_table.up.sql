@@ -0,0 +1,18 @@
+CREATE TABLE orders.webhook_events (
+    provider orders.payment_provider NOT NULL,
+    event_id varchar(255) NOT NULL,
+    restaurant_id UUID NOT NULL,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (provider, event_id)
+);
+
+-- retried webhooks could save the same provider payment more than once, keep the first one
+DELETE FROM orders.payments p
+USING orders.payments dup
+WHERE p.provider = dup.provider
+    AND p.provider_payment_id = dup.provider_payment_id
+    AND (p.created_at, p.id) > (dup.created_at, dup.id);
+
+ALTER TABLE orders.payments
+    ADD CONSTRAINT uq_provider_payment UNIQUE (provider, provider_payment_id);
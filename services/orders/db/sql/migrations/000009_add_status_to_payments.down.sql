@@ -0,0 +1,18 @@
+ALTER TABLE orders.payments DROP COLUMN IF EXISTS status;
+DROP TYPE IF EXISTS orders.payment_status;
+
+-- enum values can't be dropped, payment_review is recreated without 'disputed'
+UPDATE orders.orders SET payment_review = NULL WHERE payment_review = 'disputed';
+
+ALTER TYPE orders.payment_review RENAME TO payment_review_old;
+
+CREATE TYPE orders.payment_review AS ENUM (
+    'underpaid',
+    'overpaid'
+);
+
+ALTER TABLE orders.orders
+    ALTER COLUMN payment_review TYPE orders.payment_review
+    USING payment_review::text::orders.payment_review;
+
+DROP TYPE orders.payment_review_old;
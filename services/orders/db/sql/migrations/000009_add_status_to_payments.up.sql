@@ -0,0 +1,12 @@
+CREATE TYPE orders.payment_status AS ENUM (
+    'succeeded',
+    'refunded',
+    'disputed'
+);
+
+ALTER TABLE orders.payments
+    ADD COLUMN status orders.payment_status NOT NULL DEFAULT 'succeeded';
+
+UPDATE orders.payments SET status = 'refunded' WHERE refunded_at IS NOT NULL;
+
+ALTER TYPE orders.payment_review ADD VALUE 'disputed';
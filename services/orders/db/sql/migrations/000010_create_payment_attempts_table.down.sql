@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orders.payment_attempts;
+DROP TYPE IF EXISTS orders.payment_attempt_status;
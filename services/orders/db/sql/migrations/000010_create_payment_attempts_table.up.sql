@@ -0,0 +1,25 @@
+CREATE TYPE orders.payment_attempt_status AS ENUM (
+    'pending',
+    'succeeded',
+    'failed',
+    'expired',
+    'cancelled'
+);
+
+CREATE TABLE orders.payment_attempts (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL,
+    provider orders.payment_provider NOT NULL,
+    provider_session_id varchar(255) NOT NULL,
+    amount_in_cents INT NOT NULL,
+    currency varchar(3) NOT NULL,
+    status orders.payment_attempt_status NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_payment_attempt_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_provider_session UNIQUE (provider, provider_session_id)
+);
@@ -0,0 +1,39 @@
+ALTER TABLE orders.payments
+    DROP CONSTRAINT IF EXISTS fk_payment_recorded_by,
+    DROP COLUMN IF EXISTS recorded_by,
+    DROP COLUMN IF EXISTS change_due_in_cents,
+    DROP COLUMN IF EXISTS tendered_in_cents;
+
+-- enum values can't be dropped, payment_provider is recreated without 'cash' and 'terminal'
+DELETE FROM orders.refunds WHERE provider IN ('cash', 'terminal');
+DELETE FROM orders.payments WHERE provider IN ('cash', 'terminal');
+
+ALTER TYPE orders.payment_provider RENAME TO payment_provider_old;
+
+CREATE TYPE orders.payment_provider AS ENUM (
+    'stripe',
+    'mock',
+    'klix'
+);
+
+ALTER TABLE orders.payments
+    ALTER COLUMN provider TYPE orders.payment_provider
+    USING provider::text::orders.payment_provider;
+
+ALTER TABLE orders.refunds
+    ALTER COLUMN provider TYPE orders.payment_provider
+    USING provider::text::orders.payment_provider;
+
+ALTER TABLE orders.restaurant_payment_providers
+    ALTER COLUMN provider TYPE orders.payment_provider
+    USING provider::text::orders.payment_provider;
+
+ALTER TABLE orders.webhook_events
+    ALTER COLUMN provider TYPE orders.payment_provider
+    USING provider::text::orders.payment_provider;
+
+ALTER TABLE orders.payment_attempts
+    ALTER COLUMN provider TYPE orders.payment_provider
+    USING provider::text::orders.payment_provider;
+
+DROP TYPE orders.payment_provider_old;
@@ -0,0 +1,11 @@
+-- payments taken by waiters in cash or on restaurant's own card terminal
+ALTER TYPE orders.payment_provider ADD VALUE 'cash';
+ALTER TYPE orders.payment_provider ADD VALUE 'terminal';
+
+ALTER TABLE orders.payments
+    ADD COLUMN tendered_in_cents INT,
+    ADD COLUMN change_due_in_cents INT,
+    ADD COLUMN recorded_by UUID,
+    ADD CONSTRAINT fk_payment_recorded_by FOREIGN KEY (recorded_by)
+        REFERENCES auth.users (id)
+        ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orders.restaurant_tip_settings;
+DROP TYPE IF EXISTS orders.tip_pool_rule;
@@ -0,0 +1,19 @@
+CREATE TYPE orders.tip_pool_rule AS ENUM (
+    'equal',
+    'time_assigned',
+    'shift_pool'
+);
+
+CREATE TABLE orders.restaurant_tip_settings (
+    restaurant_id UUID PRIMARY KEY,
+    -- tip percentages offered to guests on the order page
+    presets NUMERIC(5, 2)[] NOT NULL,
+    pool_rule orders.tip_pool_rule NOT NULL DEFAULT 'equal',
+    shift_length_hours INT NOT NULL DEFAULT 8,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_tip_settings_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE
+);
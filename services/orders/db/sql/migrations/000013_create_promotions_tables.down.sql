@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS orders.orders_promo_codes;
+DROP TABLE IF EXISTS orders.promotion_rules;
+DROP TABLE IF EXISTS orders.promo_codes;
+DROP TYPE IF EXISTS orders.promotion_rule_type;
+DROP TYPE IF EXISTS orders.discount_type;
@@ -0,0 +1,75 @@
+CREATE TYPE orders.discount_type AS ENUM (
+    'percentage',
+    'fixed'
+);
+
+CREATE TYPE orders.promotion_rule_type AS ENUM (
+    'percentage',
+    'buy_x_get_y'
+);
+
+CREATE TABLE orders.promo_codes (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL,
+    -- codes are stored uppercase, guests can enter them in any case
+    code VARCHAR(32) NOT NULL,
+    discount_type orders.discount_type NOT NULL,
+    -- percent off for percentage codes, amount in cents for fixed ones
+    discount_value INT NOT NULL,
+    min_spend_in_cents INT NOT NULL DEFAULT 0,
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    max_uses INT,
+    uses_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_promo_code_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_promo_code UNIQUE (restaurant_id, code)
+);
+
+-- promotions applied automatically to items added during the daily time window
+CREATE TABLE orders.promotion_rules (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    rule_type orders.promotion_rule_type NOT NULL,
+    -- rule applies to all items of the restaurant when category is not set
+    category_id UUID,
+    percent_off INT NOT NULL DEFAULT 0,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    free_quantity INT NOT NULL DEFAULT 0,
+    -- window wraps past midnight when it ends before it starts
+    starts_at TIME NOT NULL,
+    ends_at TIME NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_promotion_rule_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_promotion_rule_category FOREIGN KEY (category_id)
+        REFERENCES management.categories (id)
+        ON DELETE CASCADE
+);
+
+-- promo code applied to an order, an order can have at most one
+CREATE TABLE orders.orders_promo_codes (
+    order_id UUID PRIMARY KEY,
+    promo_code_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_order_promo_code_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_order_promo_code_promo_code FOREIGN KEY (promo_code_id)
+        REFERENCES orders.promo_codes (id)
+        ON DELETE CASCADE
+);
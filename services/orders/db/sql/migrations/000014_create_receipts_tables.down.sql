@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS orders.receipts;
+DROP TABLE IF EXISTS orders.restaurant_receipt_counters;
+DROP TABLE IF EXISTS orders.restaurant_receipt_settings;
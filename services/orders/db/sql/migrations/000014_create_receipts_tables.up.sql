@@ -0,0 +1,42 @@
+CREATE TABLE orders.restaurant_receipt_settings (
+    restaurant_id UUID PRIMARY KEY,
+    -- VAT percentage included in menu prices, shown on receipts
+    tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_receipt_settings_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE
+);
+
+-- last receipt number issued by each restaurant
+CREATE TABLE orders.restaurant_receipt_counters (
+    restaurant_id UUID PRIMARY KEY,
+    last_number INT NOT NULL,
+
+    CONSTRAINT fk_receipt_counter_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE
+);
+
+-- receipt issued for a completed order, tax rate is kept as it was when receipt was issued
+CREATE TABLE orders.receipts (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL,
+    restaurant_id UUID NOT NULL,
+    receipt_number INT NOT NULL,
+    tax_rate NUMERIC(5, 2) NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_receipt_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_receipt_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_receipt_order UNIQUE (order_id),
+    CONSTRAINT uq_receipt_number UNIQUE (restaurant_id, receipt_number)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orders.kitchen_ticket_items;
+DROP TABLE IF EXISTS orders.restaurant_printers;
@@ -0,0 +1,30 @@
+CREATE TABLE orders.restaurant_printers (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    host VARCHAR(255) NOT NULL,
+    -- ESC/POS printers accept raw print jobs on port 9100
+    port INT NOT NULL DEFAULT 9100,
+    -- kitchen tickets of items in these categories are printed on the printer, printer
+    -- without categories prints items no other printer takes
+    category_ids UUID[] NOT NULL DEFAULT '{}',
+    prints_receipts BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_printer_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES management.restaurants (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_printer_name UNIQUE (restaurant_id, name)
+);
+
+-- order items already printed on kitchen tickets
+CREATE TABLE orders.kitchen_ticket_items (
+    order_item_id UUID PRIMARY KEY,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_kitchen_ticket_item_order_item FOREIGN KEY (order_item_id)
+        REFERENCES orders.orders_items (id)
+        ON DELETE CASCADE
+);
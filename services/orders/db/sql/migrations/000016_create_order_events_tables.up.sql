@@ -0,0 +1,25 @@
+-- last sequence number given to an order's events, the row is locked while the next number
+-- is taken, so events of an order are numbered in the order they are committed
+CREATE TABLE orders.order_event_sequences (
+    order_id UUID PRIMARY KEY,
+    last_seq BIGINT NOT NULL,
+
+    CONSTRAINT fk_order_event_sequence_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE
+);
+
+-- recent events of orders, replayed to clients reconnecting after they missed some
+CREATE TABLE orders.order_events (
+    order_id UUID NOT NULL,
+    seq BIGINT NOT NULL,
+    type VARCHAR(40) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (order_id, seq),
+
+    CONSTRAINT fk_order_event_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE orders.orders_items DROP COLUMN IF EXISTS participant_id;
+DROP TABLE IF EXISTS orders.order_participants;
+DROP TYPE IF EXISTS orders.participant_role;
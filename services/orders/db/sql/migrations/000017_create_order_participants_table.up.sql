@@ -0,0 +1,33 @@
+CREATE TYPE orders.participant_role AS ENUM (
+    'guest',
+    'staff'
+);
+
+-- people taking part in an order, guests are told apart by their table session and staff
+-- by their user
+CREATE TABLE orders.order_participants (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL,
+    user_id UUID,
+    display_name VARCHAR(40) NOT NULL,
+    role orders.participant_role NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_order_participant_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_order_participant_user FOREIGN KEY (user_id)
+        REFERENCES auth.users (id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT uq_order_participant_user UNIQUE (order_id, user_id)
+);
+
+-- participant who added the item, items added before participants were recorded have none
+ALTER TABLE orders.orders_items
+    ADD COLUMN participant_id UUID,
+    ADD CONSTRAINT fk_orders_items_participant FOREIGN KEY (participant_id)
+        REFERENCES orders.order_participants (id)
+        ON DELETE SET NULL;
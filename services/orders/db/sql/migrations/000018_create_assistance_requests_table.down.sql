@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS orders.assistance_requests;
+DROP TYPE IF EXISTS orders.assistance_request_status;
+DROP TYPE IF EXISTS orders.assistance_request_type;
@@ -0,0 +1,49 @@
+CREATE TYPE orders.assistance_request_type AS ENUM (
+    'call_waiter',
+    'request_bill',
+    'custom'
+);
+
+CREATE TYPE orders.assistance_request_status AS ENUM (
+    'open',
+    'acknowledged',
+    'resolved'
+);
+
+-- guests asking staff for help from the table, timestamps of acknowledging and resolving
+-- requests are kept to report how quickly staff responds
+CREATE TABLE orders.assistance_requests (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL,
+    type orders.assistance_request_type NOT NULL,
+    -- what guests asked for, set on custom requests only
+    message VARCHAR(200),
+    status orders.assistance_request_status NOT NULL DEFAULT 'open',
+    participant_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by UUID,
+    resolved_at TIMESTAMPTZ,
+    resolved_by UUID,
+
+    CONSTRAINT fk_assistance_request_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_assistance_request_participant FOREIGN KEY (participant_id)
+        REFERENCES orders.order_participants (id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_assistance_request_acknowledged_by FOREIGN KEY (acknowledged_by)
+        REFERENCES auth.users (id)
+        ON DELETE SET NULL,
+
+    CONSTRAINT fk_assistance_request_resolved_by FOREIGN KEY (resolved_by)
+        REFERENCES auth.users (id)
+        ON DELETE SET NULL
+);
+
+-- staff list requests that aren't resolved yet
+CREATE INDEX idx_unresolved_assistance_requests
+    ON orders.assistance_requests (created_at)
+    WHERE status <> 'resolved';
@@ -0,0 +1,3 @@
+DELETE FROM orders.refunds WHERE provider_refund_id IS NULL;
+ALTER TABLE orders.refunds
+    ALTER COLUMN provider_refund_id SET NOT NULL;
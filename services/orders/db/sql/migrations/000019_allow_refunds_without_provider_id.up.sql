@@ -0,0 +1,3 @@
+-- refunds are saved before provider is asked for them, its refund id is set once it answers
+ALTER TABLE orders.refunds
+    ALTER COLUMN provider_refund_id DROP NOT NULL;
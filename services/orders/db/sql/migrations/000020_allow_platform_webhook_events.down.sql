@@ -0,0 +1,3 @@
+DELETE FROM orders.webhook_events WHERE restaurant_id IS NULL;
+ALTER TABLE orders.webhook_events
+    ALTER COLUMN restaurant_id SET NOT NULL;
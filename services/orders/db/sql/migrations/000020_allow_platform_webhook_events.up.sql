@@ -0,0 +1,3 @@
+-- events sent to the platform webhook url aren't for a particular restaurant
+ALTER TABLE orders.webhook_events
+    ALTER COLUMN restaurant_id DROP NOT NULL;
@@ -0,0 +1,2 @@
+-- issued receipts are kept, their numbers may already be printed or sent to guests
+SELECT 1;
@@ -0,0 +1,38 @@
+-- receipts used to be issued the first time they were requested, now they are issued when
+-- order is completed, completed orders that don't have one yet get it here
+WITH pending AS (
+    SELECT
+        o.id AS order_id,
+        t.restaurant_id,
+        ROW_NUMBER() OVER (PARTITION BY t.restaurant_id ORDER BY o.updated_at, o.id) AS n
+    FROM orders.orders o
+        JOIN management.tables t ON t.id = o.table_id
+    WHERE o.status = 'completed'
+        AND NOT EXISTS (SELECT 1 FROM orders.receipts rc WHERE rc.order_id = o.id)
+)
+INSERT INTO orders.receipts (
+    id,
+    order_id,
+    restaurant_id,
+    receipt_number,
+    tax_rate
+)
+SELECT
+    gen_random_uuid(),
+    p.order_id,
+    p.restaurant_id,
+    COALESCE(c.last_number, 0) + p.n,
+    COALESCE(s.tax_rate, 0)
+FROM pending p
+    LEFT JOIN orders.restaurant_receipt_counters c ON c.restaurant_id = p.restaurant_id
+    LEFT JOIN orders.restaurant_receipt_settings s ON s.restaurant_id = p.restaurant_id;
+
+INSERT INTO orders.restaurant_receipt_counters AS c (
+    restaurant_id,
+    last_number
+)
+SELECT restaurant_id, MAX(receipt_number)
+FROM orders.receipts
+GROUP BY restaurant_id
+ON CONFLICT (restaurant_id) DO UPDATE SET
+    last_number = GREATEST(c.last_number, EXCLUDED.last_number);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS orders.idx_assistance_requests_order_type;
@@ -0,0 +1,3 @@
+-- guests asking for the same assistance again are looked up by order and type of request
+CREATE INDEX idx_assistance_requests_order_type
+    ON orders.assistance_requests (order_id, type, created_at);
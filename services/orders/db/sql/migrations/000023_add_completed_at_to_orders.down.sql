@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders.idx_orders_completed_at;
+ALTER TABLE orders.orders DROP COLUMN IF EXISTS completed_at;
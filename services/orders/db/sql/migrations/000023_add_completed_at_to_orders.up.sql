@@ -0,0 +1,21 @@
+-- time the order was completed, updated_at is bumped by later changes such as payment reviews
+ALTER TABLE orders.orders
+    ADD COLUMN completed_at TIMESTAMPTZ;
+
+-- completed orders get the time of their last payment, or of their last update without one
+UPDATE orders.orders o
+SET completed_at = COALESCE(
+    (
+        SELECT MAX(p.created_at)
+        FROM orders.payments p
+        WHERE p.order_id = o.id
+            AND p.status = 'succeeded'
+    ),
+    o.updated_at
+)
+WHERE o.status = 'completed';
+
+-- tip reports look up orders completed in a time range
+CREATE INDEX idx_orders_completed_at
+    ON orders.orders (completed_at)
+    WHERE completed_at IS NOT NULL;
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS orders.order_discounts;
+
+ALTER TABLE orders.orders_promo_codes
+    DROP CONSTRAINT IF EXISTS fk_order_promo_code_promo_code,
+    ADD CONSTRAINT fk_order_promo_code_promo_code FOREIGN KEY (promo_code_id)
+        REFERENCES orders.promo_codes (id)
+        ON DELETE CASCADE;
+
+-- codes and rules marked deleted are deleted for good
+DELETE FROM orders.promo_codes WHERE deleted_at IS NOT NULL;
+DELETE FROM orders.promotion_rules WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS orders.uq_promo_code;
+ALTER TABLE orders.promo_codes ADD CONSTRAINT uq_promo_code UNIQUE (restaurant_id, code);
+
+ALTER TABLE orders.promotion_rules DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE orders.promo_codes DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,33 @@
+-- promo codes and promotion rules are marked deleted instead of being deleted, so orders they
+-- were applied to still know what discounted them
+ALTER TABLE orders.promo_codes
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE orders.promotion_rules
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- restaurant can create a code again once the code is deleted
+ALTER TABLE orders.promo_codes
+    DROP CONSTRAINT uq_promo_code;
+
+CREATE UNIQUE INDEX uq_promo_code
+    ON orders.promo_codes (restaurant_id, code)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE orders.orders_promo_codes
+    DROP CONSTRAINT fk_order_promo_code_promo_code,
+    ADD CONSTRAINT fk_order_promo_code_promo_code FOREIGN KEY (promo_code_id)
+        REFERENCES orders.promo_codes (id);
+
+-- discount lines of the order saved when it stopped being open, later changes to promotions
+-- don't change what the order was discounted
+CREATE TABLE orders.order_discounts (
+    order_id UUID PRIMARY KEY,
+    lines JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_order_discounts_order FOREIGN KEY (order_id)
+        REFERENCES orders.orders (id)
+        ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE orders.receipts DROP COLUMN IF EXISTS contents;
@@ -0,0 +1,5 @@
+-- lines, discounts and totals of the receipt as they were when it was issued, later changes
+-- to the order or its promotions don't change receipts guests already have. Receipts issued
+-- before keep 'null' until they are requested for the first time
+ALTER TABLE orders.receipts
+    ADD COLUMN contents JSONB NOT NULL DEFAULT 'null';
@@ -0,0 +1,103 @@
+-- name: CreateAssistanceRequest :one
+-- Request is only created while the order is open or locked for payment and the participant
+-- hasn't asked for the same assistance since the time, no row is returned otherwise.
+INSERT INTO orders.assistance_requests (
+    id,
+    order_id,
+    type,
+    message,
+    participant_id
+)
+SELECT
+    sqlc.arg(id)::uuid,
+    o.id,
+    sqlc.arg(type)::orders.assistance_request_type,
+    sqlc.narg(message)::varchar,
+    sqlc.narg(participant_id)::uuid
+FROM orders.orders o
+WHERE o.id = sqlc.arg(order_id)
+    AND o.status IN ('open', 'locked')
+    AND NOT EXISTS (
+        SELECT 1
+        FROM orders.assistance_requests ar
+        WHERE ar.order_id = o.id
+            AND ar.type = sqlc.arg(type)::orders.assistance_request_type
+            AND ar.participant_id IS NOT DISTINCT FROM sqlc.narg(participant_id)::uuid
+            AND ar.created_at >= sqlc.arg(since)
+    )
+RETURNING *;
+
+-- name: GetAssistanceRequest :one
+SELECT
+    ar.*,
+    op.display_name AS participant_display_name,
+    op.role AS participant_role
+FROM orders.assistance_requests ar
+    LEFT JOIN orders.order_participants op ON op.id = ar.participant_id
+WHERE ar.id = $1 AND ar.order_id = $2;
+
+-- name: GetUnresolvedAssistanceRequests :many
+-- Requests of the restaurant's orders staff haven't resolved yet, oldest first.
+SELECT
+    ar.*,
+    op.display_name AS participant_display_name,
+    op.role AS participant_role
+FROM orders.assistance_requests ar
+    JOIN orders.orders o ON o.id = ar.order_id
+    JOIN management.tables t ON t.id = o.table_id
+    LEFT JOIN orders.order_participants op ON op.id = ar.participant_id
+WHERE t.restaurant_id = $1
+    AND ar.status <> 'resolved'
+ORDER BY ar.created_at;
+
+-- name: HasRecentAssistanceRequest :one
+-- Whether the participant asked for the same assistance at the order since the time,
+-- requests without participant count as the same participant's.
+SELECT EXISTS (
+    SELECT 1
+    FROM orders.assistance_requests ar
+    WHERE ar.order_id = sqlc.arg(order_id)
+        AND ar.type = sqlc.arg(type)
+        AND ar.participant_id IS NOT DISTINCT FROM sqlc.narg(participant_id)::uuid
+        AND ar.created_at >= sqlc.arg(since)
+);
+
+-- name: AcknowledgeAssistanceRequest :one
+UPDATE orders.assistance_requests SET
+    status = 'acknowledged',
+    acknowledged_at = NOW(),
+    acknowledged_by = sqlc.arg(user_id)::uuid
+WHERE id = sqlc.arg(id)
+    AND order_id = sqlc.arg(order_id)
+    AND status = 'open'
+RETURNING *;
+
+-- name: ResolveAssistanceRequest :one
+-- Request resolved without being acknowledged first counts as acknowledged by the same user.
+UPDATE orders.assistance_requests SET
+    status = 'resolved',
+    acknowledged_at = COALESCE(acknowledged_at, NOW()),
+    acknowledged_by = COALESCE(acknowledged_by, sqlc.arg(user_id)::uuid),
+    resolved_at = NOW(),
+    resolved_by = sqlc.arg(user_id)::uuid
+WHERE id = sqlc.arg(id)
+    AND order_id = sqlc.arg(order_id)
+    AND status <> 'resolved'
+RETURNING *;
+
+-- name: GetAssistanceRequestsCreatedBetween :many
+-- Requests of the restaurant's orders created in the period, with times staff took to
+-- respond to them.
+SELECT
+    ar.type,
+    ar.created_at,
+    ar.acknowledged_at,
+    ar.acknowledged_by,
+    ar.resolved_at
+FROM orders.assistance_requests ar
+    JOIN orders.orders o ON o.id = ar.order_id
+    JOIN management.tables t ON t.id = o.table_id
+WHERE t.restaurant_id = sqlc.arg(restaurant_id)
+    AND ar.created_at >= sqlc.arg(created_from)
+    AND ar.created_at < sqlc.arg(created_to)
+ORDER BY ar.created_at;
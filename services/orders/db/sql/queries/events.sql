@@ -0,0 +1,30 @@
+-- name: NotifyOrderEvent :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
+
+-- name: NextOrderEventSeq :one
+INSERT INTO orders.order_event_sequences (order_id, last_seq)
+VALUES ($1, 1)
+ON CONFLICT (order_id) DO UPDATE SET
+    last_seq = orders.order_event_sequences.last_seq + 1
+RETURNING last_seq;
+
+-- name: GetOrderLastEventSeq :one
+SELECT last_seq FROM orders.order_event_sequences
+WHERE order_id = $1;
+
+-- name: SaveOrderEvent :exec
+INSERT INTO orders.order_events (
+    order_id,
+    seq,
+    type,
+    payload
+) VALUES ($1, $2, $3, $4);
+
+-- name: DeleteOrderEventsUpTo :exec
+DELETE FROM orders.order_events
+WHERE order_id = $1 AND seq <= $2;
+
+-- name: GetOrderEventsAfter :many
+SELECT seq, payload FROM orders.order_events
+WHERE order_id = $1 AND seq > $2
+ORDER BY seq;
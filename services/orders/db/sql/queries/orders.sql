@@ -27,8 +27,9 @@ INSERT INTO orders.orders_items (
     order_id,
     item_id,
     item_name,
-    price_in_cents
-) VALUES ($1, $2, $3, $4, $5)
+    price_in_cents,
+    participant_id
+) VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetOrderItems :many
@@ -39,23 +40,53 @@ SELECT
     o.status,
     o.currency,
     o.tip_amount_in_cents,
+    o.payment_review,
     o.updated_at,
+    (
+        COALESCE((
+            SELECT SUM(p.amount_in_cents)
+            FROM orders.payments p
+            WHERE p.order_id = o.id AND p.status <> 'refunded'
+        ), 0) - COALESCE((
+            SELECT SUM(r.amount_in_cents)
+            FROM orders.refunds r
+                JOIN orders.payments p ON p.id = r.payment_id
+            WHERE r.order_id = o.id AND r.status = 'succeeded' AND p.status <> 'refunded'
+        ), 0)
+    )::int as amount_paid_in_cents,
     i.id as order_item_id,
     i.item_id,
     i.item_name,
-    i.price_in_cents
+    i.price_in_cents,
+    i.created_at as item_created_at,
+    mi.category_id,
+    i.participant_id,
+    op.display_name as participant_display_name,
+    op.role as participant_role,
+    pc.id as promo_code_id,
+    pc.code as promo_code,
+    pc.discount_type as promo_discount_type,
+    pc.discount_value as promo_discount_value,
+    pc.min_spend_in_cents as promo_min_spend_in_cents
 FROM orders.orders o
     LEFT JOIN orders.orders_items i ON o.id = i.order_id
+    LEFT JOIN management.items mi on mi.id = i.item_id
+    LEFT JOIN orders.order_participants op on op.id = i.participant_id
     LEFT JOIN management.tables t on t.id = o.table_id
     LEFT JOIN management.restaurants r on r.id = t.restaurant_id
-WHERE o.id = $1;
+    LEFT JOIN orders.orders_promo_codes opc on opc.order_id = o.id
+    LEFT JOIN orders.promo_codes pc on pc.id = opc.promo_code_id
+WHERE o.id = $1
+ORDER BY i.created_at;
 
 -- name: GetMenuItem :one
 SELECT 
     i.id,
     m.id as restaurant_id,
     i.name,
-    i.price_in_cents
+    i.price_in_cents,
+    i.category_id,
+    i.is_available
 FROM management.items i 
     LEFT JOIN management.categories c on c.id = i.category_id
     LEFT JOIN management.menus m on m.id = c.menu_id
@@ -67,10 +98,16 @@ WHERE id = $1 and order_id = $2
 RETURNING *;
 
 -- name: UpdateOrder :one
+-- Updates status and tip of the order, completion time is set the first time the order is
+-- completed
 UPDATE orders.orders
 SET
     status = COALESCE(sqlc.narg(status), status),
     tip_amount_in_cents = COALESCE(sqlc.narg(tip_amount_in_cents), tip_amount_in_cents),
+    completed_at = CASE
+        WHEN sqlc.narg(status) = 'completed' THEN COALESCE(completed_at, NOW())
+        ELSE completed_at
+    END,
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -82,6 +119,13 @@ FROM management.restaurants_waiters
 WHERE user_id = $1
   AND restaurant_id = $2;
 
+-- name: IsUserRestaurantManager :one
+-- Check if a user is a manager for a given restaurant
+SELECT id, user_id, restaurant_id, created_at, updated_at
+FROM management.restaurants_managers
+WHERE user_id = $1
+  AND restaurant_id = $2;
+
 -- name: AssignWaiterToOrder :one
 INSERT INTO orders.orders_waiters (
     id,
@@ -94,3 +138,36 @@ RETURNING *;
 DELETE FROM orders.orders_waiters 
 WHERE id = $1 and order_id = $2 and user_id = $3
 RETURNING *;
+
+-- name: SetOrderPaymentReview :exec
+UPDATE orders.orders
+SET
+    payment_review = sqlc.narg(payment_review),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: LockOrder :one
+-- Locks the order row until the transaction ends, so payments of the order are settled one
+-- at a time
+SELECT id
+FROM orders.orders
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetOrderTable :one
+-- Table the order is for and waiters assigned to it
+SELECT
+    o.id,
+    t.id as table_id,
+    t.restaurant_id,
+    t.name as table_name,
+    t.section,
+    COALESCE(
+        array_agg(ow.user_id) FILTER (WHERE ow.user_id IS NOT NULL),
+        '{}'
+    )::uuid[] as waiter_ids
+FROM orders.orders o
+    JOIN management.tables t ON t.id = o.table_id
+    LEFT JOIN orders.orders_waiters ow ON ow.order_id = o.id
+WHERE o.id = $1
+GROUP BY o.id, t.id;
@@ -0,0 +1,32 @@
+-- name: SaveGuestParticipant :one
+INSERT INTO orders.order_participants (
+    id,
+    order_id,
+    display_name,
+    role
+) VALUES ($1, $2, $3, 'guest')
+ON CONFLICT (id) DO UPDATE SET
+    display_name = EXCLUDED.display_name,
+    updated_at = NOW()
+WHERE orders.order_participants.order_id = EXCLUDED.order_id
+RETURNING *;
+
+-- name: SaveStaffParticipant :one
+INSERT INTO orders.order_participants (
+    id,
+    order_id,
+    user_id,
+    display_name,
+    role
+)
+SELECT sqlc.arg(id), sqlc.arg(order_id), u.id, u.name, 'staff'
+FROM auth.users u
+WHERE u.id = sqlc.arg(user_id)
+ON CONFLICT (order_id, user_id) DO UPDATE SET
+    display_name = EXCLUDED.display_name,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetOrderParticipant :one
+SELECT * FROM orders.order_participants
+WHERE id = $1 AND order_id = $2;
@@ -5,6 +5,161 @@ INSERT INTO orders.payments (
     amount_in_cents,
     currency,
     provider,
-    provider_payment_id
+    provider_payment_id,
+    tendered_in_cents,
+    change_due_in_cents,
+    recorded_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (provider, provider_payment_id) DO NOTHING
+RETURNING *;
+
+-- name: GetOrderAmountPaid :one
+-- Amount paid for the order with succeeded refunds subtracted, payments provider reported as
+-- fully refunded are left out together with their refunds
+SELECT (
+    COALESCE((
+        SELECT SUM(p.amount_in_cents)
+        FROM orders.payments p
+        WHERE p.order_id = $1 AND p.status <> 'refunded'
+    ), 0) - COALESCE((
+        SELECT SUM(r.amount_in_cents)
+        FROM orders.refunds r
+            JOIN orders.payments p ON p.id = r.payment_id
+        WHERE r.order_id = $1 AND r.status = 'succeeded' AND p.status <> 'refunded'
+    ), 0)
+)::int as amount_paid_in_cents;
+
+-- name: GetOrderPayments :many
+SELECT
+    p.id,
+    p.order_id,
+    p.amount_in_cents,
+    p.currency,
+    p.provider,
+    p.provider_payment_id,
+    p.status,
+    p.tendered_in_cents,
+    p.change_due_in_cents,
+    p.recorded_by,
+    p.created_at,
+    p.refunded_at,
+    COALESCE((
+        SELECT SUM(r.amount_in_cents)
+        FROM orders.refunds r
+        WHERE r.payment_id = p.id AND r.status <> 'failed'
+    ), 0)::int as refunded_amount_in_cents
+FROM orders.payments p
+WHERE p.order_id = $1
+ORDER BY p.created_at DESC;
+
+-- name: GetOrderRefundedItemIDs :many
+-- Order items covered by refunds that are pending or succeeded
+SELECT DISTINCT unnest(r.order_item_ids)::uuid AS order_item_id
+FROM orders.refunds r
+WHERE r.order_id = $1 AND r.status <> 'failed';
+
+-- name: SavePaymentAttempt :one
+INSERT INTO orders.payment_attempts (
+    id,
+    order_id,
+    provider,
+    provider_session_id,
+    amount_in_cents,
+    currency
 ) VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
+
+-- name: UpdatePaymentAttemptStatus :exec
+-- Attempt is found by its id echoed back by provider or by provider's session id,
+-- only pending and failed attempts can still change
+UPDATE orders.payment_attempts
+SET
+    status = $5,
+    updated_at = NOW()
+WHERE order_id = $1
+    AND (id = $2 OR (provider = $3 AND provider_session_id = $4))
+    AND status IN ('pending', 'failed');
+
+-- name: GetOrderPaymentAttempts :many
+SELECT *
+FROM orders.payment_attempts
+WHERE order_id = $1
+ORDER BY created_at DESC;
+
+-- name: SaveRefund :one
+INSERT INTO orders.refunds (
+    id,
+    payment_id,
+    order_id,
+    amount_in_cents,
+    currency,
+    provider,
+    provider_refund_id,
+    status,
+    reason,
+    order_item_ids,
+    requested_by
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: UpdateRefundFromProvider :one
+-- Sets refund's id at the provider and the status it was created with, unless a webhook
+-- already moved the refund on
+UPDATE orders.refunds
+SET
+    provider_refund_id = sqlc.narg(provider_refund_id),
+    status = CASE
+        WHEN status = 'pending' THEN sqlc.arg(status)::orders.refund_status
+        ELSE status
+    END,
+    confirmed_at = CASE
+        WHEN status = 'pending' AND sqlc.arg(status) = 'succeeded' THEN NOW()
+        ELSE confirmed_at
+    END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateRefundStatus :one
+-- Refunds are matched by our id too, provider may confirm the refund before its id is set
+UPDATE orders.refunds
+SET
+    provider_refund_id = $2,
+    status = $3,
+    confirmed_at = CASE WHEN $3 = 'succeeded' THEN NOW() ELSE confirmed_at END,
+    updated_at = NOW()
+WHERE provider = $1 AND (provider_refund_id = $2 OR id = $4)
+RETURNING *;
+
+-- name: MarkPaymentRefunded :exec
+-- Sets refunded_at once succeeded refunds cover the whole payment amount
+UPDATE orders.payments p
+SET
+    status = 'refunded',
+    refunded_at = NOW(),
+    updated_at = NOW()
+WHERE p.id = $1
+    AND p.refunded_at IS NULL
+    AND p.amount_in_cents <= (
+        SELECT COALESCE(SUM(r.amount_in_cents), 0)
+        FROM orders.refunds r
+        WHERE r.payment_id = p.id AND r.status = 'succeeded'
+    );
+
+-- name: UpdatePaymentStatus :one
+UPDATE orders.payments
+SET
+    status = $3,
+    refunded_at = CASE WHEN $3 = 'refunded' THEN COALESCE(refunded_at, NOW()) ELSE refunded_at END,
+    updated_at = NOW()
+WHERE provider = $1 AND provider_payment_id = $2
+RETURNING *;
+
+-- name: SaveWebhookEvent :execrows
+-- Records provider's webhook event, nothing is inserted when the event was already processed
+INSERT INTO orders.webhook_events (
+    provider,
+    event_id,
+    restaurant_id
+) VALUES ($1, $2, $3)
+ON CONFLICT (provider, event_id) DO NOTHING;
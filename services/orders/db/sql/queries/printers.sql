@@ -0,0 +1,53 @@
+-- name: CreatePrinter :one
+INSERT INTO orders.restaurant_printers (
+    id,
+    restaurant_id,
+    name,
+    host,
+    port,
+    category_ids,
+    prints_receipts
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (restaurant_id, name) DO NOTHING
+RETURNING *;
+
+-- name: DeletePrinter :execrows
+DELETE FROM orders.restaurant_printers
+WHERE id = $1 AND restaurant_id = $2;
+
+-- name: GetOrderItemsNotSentToKitchen :many
+-- Locks order items which weren't printed on kitchen tickets yet, so they are sent only once
+SELECT
+    i.id,
+    i.item_name,
+    i.created_at,
+    mi.category_id,
+    t.restaurant_id,
+    t.name AS table_name
+FROM orders.orders_items i
+    JOIN orders.orders o ON o.id = i.order_id
+    JOIN management.tables t ON t.id = o.table_id
+    LEFT JOIN management.items mi ON mi.id = i.item_id
+WHERE i.order_id = $1
+    AND NOT EXISTS (
+        SELECT 1
+        FROM orders.kitchen_ticket_items k
+        WHERE k.order_item_id = i.id
+    )
+ORDER BY i.created_at
+FOR UPDATE OF i;
+
+-- name: GetRestaurantPrinters :many
+SELECT *
+FROM orders.restaurant_printers
+WHERE restaurant_id = $1
+ORDER BY name;
+
+-- name: MarkOrderItemsSentToKitchen :exec
+INSERT INTO orders.kitchen_ticket_items (order_item_id)
+SELECT unnest(@order_item_ids::uuid[])
+ON CONFLICT (order_item_id) DO NOTHING;
+
+-- name: UnmarkOrderItemsSentToKitchen :exec
+DELETE FROM orders.kitchen_ticket_items
+WHERE order_item_id = ANY(@order_item_ids::uuid[]);
@@ -0,0 +1,137 @@
+-- name: GetRestaurantPromoCodes :many
+SELECT * FROM orders.promo_codes
+WHERE restaurant_id = $1 AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: GetPromoCodeByCode :one
+SELECT * FROM orders.promo_codes
+WHERE restaurant_id = $1 AND code = $2 AND deleted_at IS NULL;
+
+-- name: CreatePromoCode :one
+-- Creates promo code, nothing is inserted when restaurant already has the code
+INSERT INTO orders.promo_codes (
+    id,
+    restaurant_id,
+    code,
+    discount_type,
+    discount_value,
+    min_spend_in_cents,
+    valid_from,
+    valid_until,
+    max_uses
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (restaurant_id, code) WHERE deleted_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: DeletePromoCode :execrows
+-- Marks promo code deleted, orders it was applied to keep it
+UPDATE orders.promo_codes
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL;
+
+-- name: RedeemPromoCode :execrows
+-- Counts one more use of the code, nothing is updated when the code is used up or deleted
+UPDATE orders.promo_codes
+SET
+    uses_count = uses_count + 1,
+    updated_at = NOW()
+WHERE id = $1
+    AND deleted_at IS NULL
+    AND (max_uses IS NULL OR uses_count < max_uses);
+
+-- name: ReleasePromoCode :exec
+UPDATE orders.promo_codes
+SET
+    uses_count = GREATEST(uses_count - 1, 0),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RemovePromoCodeFromOpenOrders :exec
+-- Removes the promo code from orders that are still open, other orders keep it
+DELETE FROM orders.orders_promo_codes opc
+USING orders.orders o
+WHERE opc.promo_code_id = $1
+    AND o.id = opc.order_id
+    AND o.status = 'open';
+
+-- name: GetOrderPromoCodeID :one
+SELECT promo_code_id
+FROM orders.orders_promo_codes
+WHERE order_id = $1;
+
+-- name: SetOrderPromoCode :exec
+INSERT INTO orders.orders_promo_codes (
+    order_id,
+    promo_code_id
+) VALUES ($1, $2)
+ON CONFLICT (order_id) DO UPDATE SET
+    promo_code_id = EXCLUDED.promo_code_id,
+    created_at = NOW();
+
+-- name: DeleteOrderPromoCode :one
+DELETE FROM orders.orders_promo_codes
+WHERE order_id = $1
+RETURNING promo_code_id;
+
+-- name: GetRestaurantPromotionRules :many
+SELECT * FROM orders.promotion_rules
+WHERE restaurant_id = $1 AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: GetActivePromotionRules :many
+SELECT * FROM orders.promotion_rules
+WHERE restaurant_id = $1 AND is_active AND deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: CreatePromotionRule :one
+INSERT INTO orders.promotion_rules (
+    id,
+    restaurant_id,
+    name,
+    rule_type,
+    category_id,
+    percent_off,
+    buy_quantity,
+    free_quantity,
+    starts_at,
+    ends_at,
+    timezone
+) VALUES (
+    sqlc.arg(id),
+    sqlc.arg(restaurant_id),
+    sqlc.arg(name),
+    sqlc.arg(rule_type),
+    sqlc.narg(category_id),
+    sqlc.arg(percent_off),
+    sqlc.arg(buy_quantity),
+    sqlc.arg(free_quantity),
+    sqlc.arg(starts_at)::text::time,
+    sqlc.arg(ends_at)::text::time,
+    sqlc.arg(timezone)
+)
+RETURNING *;
+
+-- name: DeletePromotionRule :execrows
+-- Marks promotion rule deleted, discounts it gave to orders are kept
+UPDATE orders.promotion_rules
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND restaurant_id = $2 AND deleted_at IS NULL;
+
+-- name: GetOrderDiscounts :one
+SELECT lines
+FROM orders.order_discounts
+WHERE order_id = $1;
+
+-- name: SaveOrderDiscounts :exec
+-- Saves discount lines of the order, lines saved before are replaced
+INSERT INTO orders.order_discounts (
+    order_id,
+    lines
+) VALUES ($1, $2)
+ON CONFLICT (order_id) DO UPDATE SET
+    lines = EXCLUDED.lines,
+    updated_at = NOW();
@@ -0,0 +1,28 @@
+-- name: GetRestaurantPaymentProviders :many
+SELECT * FROM orders.restaurant_payment_providers
+WHERE restaurant_id = $1
+ORDER BY is_default DESC, provider;
+
+-- name: SaveRestaurantPaymentProvider :one
+INSERT INTO orders.restaurant_payment_providers (
+    restaurant_id,
+    provider,
+    credentials,
+    is_default
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (restaurant_id, provider) DO UPDATE SET
+    credentials = EXCLUDED.credentials,
+    is_default = EXCLUDED.is_default,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: UnsetDefaultPaymentProvider :exec
+UPDATE orders.restaurant_payment_providers
+SET
+    is_default = FALSE,
+    updated_at = NOW()
+WHERE restaurant_id = $1 AND provider <> $2 AND is_default;
+
+-- name: DeleteRestaurantPaymentProvider :execrows
+DELETE FROM orders.restaurant_payment_providers
+WHERE restaurant_id = $1 AND provider = $2;
@@ -0,0 +1,66 @@
+-- name: GetRestaurantReceiptSettings :one
+SELECT * FROM orders.restaurant_receipt_settings
+WHERE restaurant_id = $1;
+
+-- name: SaveRestaurantReceiptSettings :one
+INSERT INTO orders.restaurant_receipt_settings (
+    restaurant_id,
+    tax_rate
+) VALUES ($1, $2)
+ON CONFLICT (restaurant_id) DO UPDATE SET
+    tax_rate = EXCLUDED.tax_rate,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetOrderReceipt :one
+SELECT
+    rc.id,
+    rc.order_id,
+    rc.restaurant_id,
+    rc.receipt_number,
+    rc.tax_rate,
+    rc.issued_at,
+    rc.contents,
+    r.name AS restaurant_name,
+    r.address AS restaurant_address
+FROM orders.receipts rc
+    JOIN management.restaurants r ON r.id = rc.restaurant_id
+WHERE rc.order_id = $1;
+
+-- name: NextReceiptNumber :one
+-- Returns next receipt number of the restaurant, numbering starts at 1
+INSERT INTO orders.restaurant_receipt_counters AS c (
+    restaurant_id,
+    last_number
+) VALUES ($1, 1)
+ON CONFLICT (restaurant_id) DO UPDATE SET
+    last_number = c.last_number + 1
+RETURNING last_number;
+
+-- name: SaveReceiptContents :exec
+-- Saves contents of receipt issued before contents were saved with receipts, once
+UPDATE orders.receipts
+SET contents = $2
+WHERE id = $1 AND contents = 'null';
+
+-- name: CreateReceipt :exec
+-- Tax rate is taken from restaurant's receipt settings, restaurants without settings get 0
+INSERT INTO orders.receipts (
+    id,
+    order_id,
+    restaurant_id,
+    receipt_number,
+    contents,
+    tax_rate
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    COALESCE((
+        SELECT s.tax_rate
+        FROM orders.restaurant_receipt_settings s
+        WHERE s.restaurant_id = $3
+    ), 0)
+);
@@ -0,0 +1,28 @@
+-- name: GetProviderPayments :many
+-- Payments taken with restaurant's own provider account, or with platform's account when
+-- restaurant is not set, those belong to restaurants without own credentials for the provider
+SELECT
+    p.id,
+    p.order_id,
+    p.provider_payment_id,
+    p.amount_in_cents,
+    p.currency,
+    p.created_at
+FROM orders.payments p
+    JOIN orders.orders o ON o.id = p.order_id
+    JOIN management.tables t ON t.id = o.table_id
+WHERE p.provider = sqlc.arg(provider)
+    AND p.created_at >= sqlc.arg(created_from)
+    AND p.created_at < sqlc.arg(created_to)
+    AND (
+        t.restaurant_id = sqlc.narg(restaurant_id)
+        OR (
+            sqlc.narg(restaurant_id)::uuid IS NULL
+            AND NOT EXISTS (
+                SELECT 1
+                FROM orders.restaurant_payment_providers rpp
+                WHERE rpp.restaurant_id = t.restaurant_id AND rpp.provider = p.provider
+            )
+        )
+    )
+ORDER BY p.created_at;
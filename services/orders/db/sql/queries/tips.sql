@@ -0,0 +1,35 @@
+-- name: GetRestaurantTipSettings :one
+SELECT * FROM orders.restaurant_tip_settings
+WHERE restaurant_id = $1;
+
+-- name: SaveRestaurantTipSettings :one
+INSERT INTO orders.restaurant_tip_settings (
+    restaurant_id,
+    presets,
+    pool_rule,
+    shift_length_hours
+) VALUES ($1, $2, $3, $4)
+ON CONFLICT (restaurant_id) DO UPDATE SET
+    presets = EXCLUDED.presets,
+    pool_rule = EXCLUDED.pool_rule,
+    shift_length_hours = EXCLUDED.shift_length_hours,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetCompletedOrdersWaiters :many
+-- Completed orders of the restaurant with waiters assigned to them, an order without waiters
+-- is returned once with empty waiter columns.
+SELECT
+    o.id AS order_id,
+    COALESCE(o.tip_amount_in_cents, 0)::int AS tip_amount_in_cents,
+    o.completed_at::timestamptz AS completed_at,
+    ow.user_id,
+    ow.created_at AS assigned_at
+FROM orders.orders o
+    JOIN management.tables t ON t.id = o.table_id
+    LEFT JOIN orders.orders_waiters ow ON ow.order_id = o.id
+WHERE t.restaurant_id = sqlc.arg(restaurant_id)
+    AND o.status = 'completed'
+    AND o.completed_at >= sqlc.arg(completed_from)::timestamptz
+    AND o.completed_at < sqlc.arg(completed_to)::timestamptz
+ORDER BY o.completed_at, o.id, ow.created_at;
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxAssistanceMessageLength is the longest message guests can send with a custom request.
+const MaxAssistanceMessageLength = 200
+
+// AssistanceRequestDto represents guests asking staff of order's restaurant for help from
+// the table, e.g. to call the waiter or bring the bill. RequestedBy is set when the request
+// came from a participant connected to the order.
+type AssistanceRequestDto struct {
+	ID             uuid.UUID                        `json:"id"`
+	OrderID        uuid.UUID                        `json:"order_id"`
+	Type           db.OrdersAssistanceRequestType   `json:"type"`
+	Message        *string                          `json:"message,omitempty"`
+	Status         db.OrdersAssistanceRequestStatus `json:"status"`
+	RequestedBy    *ParticipantDto                  `json:"requested_by,omitempty"`
+	CreatedAt      time.Time                        `json:"created_at"`
+	AcknowledgedAt *time.Time                       `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *uuid.UUID                       `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time                       `json:"resolved_at,omitempty"`
+	ResolvedBy     *uuid.UUID                       `json:"resolved_by,omitempty"`
+}
+
+// CreateAssistanceRequestDto represents guests' request for assistance, message is required
+// for custom requests and not allowed for others. SessionToken is guest's table session when
+// the request is sent over http.
+type CreateAssistanceRequestDto struct {
+	OrderID      uuid.UUID                      `json:"-"`
+	Type         db.OrdersAssistanceRequestType `json:"type"    validate:"required,oneof=call_waiter request_bill custom"`
+	Message      *string                        `json:"message" validate:"required_if=Type custom,excluded_unless=Type custom,omitempty,min=1,max=200"`
+	RequestedBy  *ParticipantDto                `json:"-"`
+	SessionToken string                         `json:"-"`
+}
+
+// CustomAssistanceRequestDto is data of custom_request websocket message.
+type CustomAssistanceRequestDto struct {
+	Message string `json:"message" validate:"required,max=200"`
+}
+
+// UpdateAssistanceRequestDto represents staff acknowledging or resolving an assistance
+// request.
+type UpdateAssistanceRequestDto struct {
+	OrderID   uuid.UUID                        `json:"-"`
+	RequestID uuid.UUID                        `json:"-"`
+	Status    db.OrdersAssistanceRequestStatus `json:"status" validate:"required,oneof=acknowledged resolved"`
+}
+
+// AssistanceReportRequestDto represents manager's request for times staff took to respond
+// to assistance requests created between From and To.
+type AssistanceReportRequestDto struct {
+	RestaurantID uuid.UUID `json:"-"`
+	From         time.Time `json:"-" query:"from" validate:"required"`
+	To           time.Time `json:"-" query:"to"   validate:"required,gtfield=From"`
+}
+
+// AssistanceResponseTimeDto represents request of the report period with times staff took
+// to respond to it, AcknowledgedBy and the times are nil until it's handled.
+type AssistanceResponseTimeDto struct {
+	Type           db.OrdersAssistanceRequestType
+	CreatedAt      time.Time
+	AcknowledgedAt *time.Time
+	AcknowledgedBy *uuid.UUID
+	ResolvedAt     *time.Time
+}
+
+// AssistanceResponseTimesDto represents how many requests there were and how long staff
+// took on average to acknowledge and resolve them, in seconds.
+type AssistanceResponseTimesDto struct {
+	RequestsCount           int     `json:"requests_count"`
+	AcknowledgedCount       int     `json:"acknowledged_count"`
+	ResolvedCount           int     `json:"resolved_count"`
+	AvgSecondsToAcknowledge float64 `json:"avg_seconds_to_acknowledge"`
+	AvgSecondsToResolve     float64 `json:"avg_seconds_to_resolve"`
+}
+
+// AssistanceTypeTimesDto represents response times of requests of the type.
+type AssistanceTypeTimesDto struct {
+	Type db.OrdersAssistanceRequestType `json:"type"`
+	AssistanceResponseTimesDto
+}
+
+// WaiterAssistanceTimesDto represents response times of requests the waiter acknowledged.
+type WaiterAssistanceTimesDto struct {
+	UserID uuid.UUID `json:"user_id"`
+	AssistanceResponseTimesDto
+}
+
+// AssistanceReportDto represents times staff took to respond to assistance requests of
+// restaurant's orders, overall, by type of request and by waiter who acknowledged them.
+type AssistanceReportDto struct {
+	RestaurantID uuid.UUID                   `json:"restaurant_id"`
+	From         time.Time                   `json:"from"`
+	To           time.Time                   `json:"to"`
+	Overall      AssistanceResponseTimesDto  `json:"overall"`
+	Types        []*AssistanceTypeTimesDto   `json:"types"`
+	Waiters      []*WaiterAssistanceTimesDto `json:"waiters"`
+}
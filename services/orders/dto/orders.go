@@ -9,9 +9,12 @@ import (
 	"github.com/google/uuid"
 )
 
-// CurrentOrderDto represents the active order for a table.
+// CurrentOrderDto represents the active order for a table. Session token lets the guest
+// who scanned the table connect to the order's websocket until it expires.
 type CurrentOrderDto struct {
-	ID uuid.UUID `json:"id"`
+	ID               uuid.UUID  `json:"id"`
+	SessionToken     string     `json:"session_token,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
 }
 
 // OrderItemRequestDto represents a request to add or delete an item from an order.
@@ -19,26 +22,53 @@ type OrderItemRequestDto struct {
 	ItemID uuid.UUID `json:"item_id" validate:"required"`
 }
 
-// OrderDto represents a full order with items and totals.
+// OrderDto represents a full order with items and totals. Balance due is the total price
+// with tip, less discounts and the amount already paid.
 type OrderDto struct {
-	ID                uuid.UUID       `json:"id"`
-	RestaurantID      uuid.UUID       `json:"restaurant_id"`
-	RestaurantName    string          `json:"restaurant_name"`
-	Status            db.OrderStatus  `json:"status"`
-	Currency          string          `json:"currency"`
-	TipAmountInCents  int             `json:"tip_amount_in_cents"`
-	TotalPriceInCents int             `json:"total_price_in_cents"`
-	UpdatedAt         time.Time       `json:"updated_at"`
-	Items             []*OrderItemDto `json:"items"`
+	ID                uuid.UUID               `json:"id"`
+	RestaurantID      uuid.UUID               `json:"restaurant_id"`
+	RestaurantName    string                  `json:"restaurant_name"`
+	Status            db.OrderStatus          `json:"status"`
+	Currency          string                  `json:"currency"`
+	TipAmountInCents  int                     `json:"tip_amount_in_cents"`
+	TipPresets        []float64               `json:"tip_presets,omitempty"`
+	TotalPriceInCents int                     `json:"total_price_in_cents"`
+	DiscountInCents   int                     `json:"discount_in_cents"`
+	Discounts         []*DiscountLineDto      `json:"discounts,omitempty"`
+	PromoCode         *AppliedPromoCodeDto    `json:"promo_code,omitempty"`
+	PromotionRules    []*PromotionRuleDto     `json:"-"`
+	AmountPaidInCents int                     `json:"amount_paid_in_cents"`
+	BalanceDueInCents int                     `json:"balance_due_in_cents"`
+	PaymentReview     *db.OrdersPaymentReview `json:"payment_review,omitempty"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+	Items             []*OrderItemDto         `json:"items"`
 }
 
 // OrderItemDto represents a single item within an order.
 type OrderItemDto struct {
-	ID           uuid.UUID `json:"id"`
-	RestaurantID uuid.UUID `json:"-"`
-	ItemID       uuid.UUID `json:"item_id"`
-	Name         string    `json:"name"`
-	PriceInCents int       `json:"price_in_cents"`
+	ID           uuid.UUID       `json:"id"`
+	RestaurantID uuid.UUID       `json:"-"`
+	ItemID       uuid.UUID       `json:"item_id"`
+	Name         string          `json:"name"`
+	PriceInCents int             `json:"price_in_cents"`
+	CategoryID   *uuid.UUID      `json:"-"`
+	CreatedAt    time.Time       `json:"-"`
+	AddedBy      *ParticipantDto `json:"added_by,omitempty"`
+}
+
+// MaxDisplayNameLength is the longest display name guests can take part in an order under.
+const MaxDisplayNameLength = 40
+
+// ParticipantDto represents a guest or staff member taking part in an order.
+type ParticipantDto struct {
+	ID          uuid.UUID                `json:"id"`
+	DisplayName string                   `json:"display_name"`
+	Role        db.OrdersParticipantRole `json:"role"`
+}
+
+// PresenceDto lists participants currently connected to an order.
+type PresenceDto struct {
+	Participants []*ParticipantDto `json:"participants"`
 }
 
 // UpdateOrderReqDto represents a request payload to update order.
@@ -63,18 +93,115 @@ const (
 	MsgAddItem WSMessageType = "add_item"
 	// MsgDeleteItem to delete an item from an order.
 	MsgDeleteItem WSMessageType = "delete_item"
-	// MsgError indicating an error.
+	// MsgApplyPromoCode to apply a promo code to an order.
+	MsgApplyPromoCode WSMessageType = "apply_promo_code"
+	// MsgRemovePromoCode to remove promo code from an order.
+	MsgRemovePromoCode WSMessageType = "remove_promo_code"
+	// MsgCallWaiter to ask for the waiter to come to the table.
+	MsgCallWaiter WSMessageType = "call_waiter"
+	// MsgRequestBill to ask for the bill.
+	MsgRequestBill WSMessageType = "request_bill"
+	// MsgCustomRequest to ask staff for anything else, e.g. extra napkins.
+	MsgCustomRequest WSMessageType = "custom_request"
+	// MsgSync to catch up on events missed since last_seq, or to get the whole order.
+	MsgSync WSMessageType = "sync"
+	// MsgPresence listing participants connected to an order, sent after connecting.
+	MsgPresence WSMessageType = "presence"
+	// MsgParticipantJoined when a participant connects to an order.
+	MsgParticipantJoined WSMessageType = "participant_joined"
+	// MsgParticipantLeft when participant's last connection to an order closes.
+	MsgParticipantLeft WSMessageType = "participant_left"
+	// MsgOrderOpened in staff feed when a new order is opened at a table.
+	MsgOrderOpened WSMessageType = "order_opened"
+	// MsgItemsAdded in staff feed when items are added to an order.
+	MsgItemsAdded WSMessageType = "items_added"
+	// MsgOrderLocked in staff feed when an order is locked for payment.
+	MsgOrderLocked WSMessageType = "order_locked"
+	// MsgPaymentSucceeded in staff feed when a payment of an order succeeds.
+	MsgPaymentSucceeded WSMessageType = "payment_succeeded"
+	// MsgAssistanceRequested in staff feed when guests ask for assistance from the table.
+	MsgAssistanceRequested WSMessageType = "assistance_requested"
+	// MsgAssistanceAcknowledged in staff feed when staff acknowledges assistance request.
+	MsgAssistanceAcknowledged WSMessageType = "assistance_acknowledged"
+	// MsgAssistanceResolved in staff feed when staff resolves assistance request.
+	MsgAssistanceResolved WSMessageType = "assistance_resolved"
+	// MsgAck acknowledging that client's request succeeded.
+	MsgAck WSMessageType = "ack"
+	// MsgNack rejecting client's request, its data is WSErrorDto.
+	MsgNack WSMessageType = "nack"
+	// MsgError indicating an error not caused by any client's request.
 	MsgError WSMessageType = "error"
 )
 
-// WSReqMessage is the envelope for messages received from the client.
+// WSProtocolVersion is the version of websocket message schema, documented in
+// api/asyncapi/orders-websocket.yml. It's bumped on changes that break existing clients.
+const WSProtocolVersion = 1
+
+// WSReqMessage is the envelope for messages received from the client. RequestID is chosen
+// by the client and echoed back in ack or nack of the request. Messages without V are
+// treated as the current version.
 type WSReqMessage struct {
-	Type WSMessageType   `json:"type" validate:"required"`
-	Data json.RawMessage `json:"data" validate:"required"`
+	V         int             `json:"v"          validate:"omitempty,gte=1"`
+	RequestID string          `json:"request_id" validate:"omitempty,max=64"`
+	Type      WSMessageType   `json:"type"       validate:"required"`
+	Data      json.RawMessage `json:"data"`
 }
 
-// WSRespMessage is the envelope for messages sent back to the client.
+// WSRespMessage is the envelope for messages sent back to the client. Seq is set on order
+// events and sync messages, it's the sequence number of order's last event the client has.
+// RequestID is set on acks and nacks.
 type WSRespMessage struct {
+	V         int           `json:"v"`
+	RequestID string        `json:"request_id,omitempty"`
+	Type      WSMessageType `json:"type"`
+	Seq       int64         `json:"seq,omitempty"`
+	Data      any           `json:"data"`
+}
+
+// WSErrorCode is a machine readable reason of rejected request.
+type WSErrorCode string
+
+const (
+	// WSErrMalformedMessage is returned when message isn't a valid envelope.
+	WSErrMalformedMessage WSErrorCode = "malformed_message"
+	// WSErrUnsupportedVersion is returned when message is of a version server doesn't speak.
+	WSErrUnsupportedVersion WSErrorCode = "unsupported_version"
+	// WSErrUnknownType is returned when message type isn't known.
+	WSErrUnknownType WSErrorCode = "unknown_type"
+	// WSErrValidationFailed is returned when message data is invalid.
+	WSErrValidationFailed WSErrorCode = "validation_failed"
+	// WSErrItemUnavailable is returned when menu item can't be ordered.
+	WSErrItemUnavailable WSErrorCode = "item_unavailable"
+	// WSErrOrderLocked is returned when order isn't open for changes by the client.
+	WSErrOrderLocked WSErrorCode = "order_locked"
+	// WSErrOrderFinalized is returned when order is completed or cancelled.
+	WSErrOrderFinalized WSErrorCode = "order_finalized"
+	// WSErrPromoCodeRejected is returned when promo code can't be applied to the order.
+	WSErrPromoCodeRejected WSErrorCode = "promo_code_rejected"
+	// WSErrNotFound is returned when the referenced order item doesn't exist.
+	WSErrNotFound WSErrorCode = "not_found"
+	// WSErrForbidden is returned when the client isn't allowed to make the change.
+	WSErrForbidden WSErrorCode = "forbidden"
+	// WSErrRateLimited is returned when the client repeats the request too soon.
+	WSErrRateLimited WSErrorCode = "rate_limited"
+	// WSErrInternal is returned when request failed on server's side.
+	WSErrInternal WSErrorCode = "internal_error"
+)
+
+// WSAckDto is data of an ack, it repeats type of the acknowledged request.
+type WSAckDto struct {
 	Type WSMessageType `json:"type"`
-	Data any           `json:"data"`
+}
+
+// WSErrorDto is data of a nack or error message.
+type WSErrorDto struct {
+	Type    WSMessageType `json:"type,omitempty"`
+	Code    WSErrorCode   `json:"code"`
+	Message string        `json:"message"`
+}
+
+// SyncRequestDto represents client's request to sync with the order. Events after LastSeq
+// are replayed, the whole order is sent when LastSeq is missing or the events aren't kept.
+type SyncRequestDto struct {
+	LastSeq *int64 `json:"last_seq" validate:"omitempty,gte=0"`
 }
@@ -2,29 +2,106 @@ package dto
 
 import (
 	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // CheckoutSessionRequestDto represents the data needed to create a checkout session.
+// Provider is optional, restaurant's default provider is used when it's not set.
+// PaymentAttemptID is passed to providers that can echo it back in their webhook events.
 type CheckoutSessionRequestDto struct {
-	OrderDto   *OrderDto `json:"order"`
-	SuccessURL string    `json:"success_url" validate:"required"`
-	CancelURL  string    `json:"cancel_url"  validate:"required"`
+	OrderDto         *OrderDto                 `json:"order"`
+	SuccessURL       string                    `json:"success_url" validate:"required"`
+	CancelURL        string                    `json:"cancel_url"  validate:"required"`
+	Provider         *db.OrdersPaymentProvider `json:"provider"    validate:"omitempty,oneof=stripe mock klix"`
+	PaymentAttemptID uuid.UUID                 `json:"-"`
 }
 
 // CheckoutSessionResponseDto represents the response returned after creating a checkout session.
+// SessionID is provider's checkout session id, it's set by the provider.
 type CheckoutSessionResponseDto struct {
-	URL      string                   `json:"url"`
-	Provider db.OrdersPaymentProvider `json:"provider"`
+	URL              string                   `json:"url"`
+	Provider         db.OrdersPaymentProvider `json:"provider"`
+	SessionID        string                   `json:"-"`
+	PaymentAttemptID uuid.UUID                `json:"payment_attempt_id"`
+	AmountInCents    int                      `json:"amount_in_cents"`
+}
+
+// PaymentAttemptDto represents a single checkout session created for an order.
+type PaymentAttemptDto struct {
+	ID                uuid.UUID                     `json:"id"`
+	OrderID           uuid.UUID                     `json:"order_id"`
+	Provider          db.OrdersPaymentProvider      `json:"provider"`
+	ProviderSessionID string                        `json:"provider_session_id"`
+	AmountInCents     int                           `json:"amount_in_cents"`
+	Currency          string                        `json:"currency"`
+	Status            db.OrdersPaymentAttemptStatus `json:"status"`
+	CreatedAt         time.Time                     `json:"created_at"`
+	UpdatedAt         time.Time                     `json:"updated_at"`
+}
+
+// OrderPaymentsDto represents order's payment attempts and payments, latest first.
+type OrderPaymentsDto struct {
+	Attempts []*PaymentAttemptDto `json:"attempts"`
+	Payments []*PaymentDto        `json:"payments"`
 }
 
 // PaymentDto represents save payment request and response.
+// RefundedAmountInCents includes refunds that are still waiting for provider's confirmation.
+// Tendered amount, change due and waiter who recorded the payment are set for offline payments.
 type PaymentDto struct {
-	ID                uuid.UUID                `json:"id"`
-	OrderID           uuid.UUID                `json:"order_id"`
-	AmountInCents     int                      `json:"amount_in_cents"`
-	Provider          db.OrdersPaymentProvider `json:"provider"`
-	ProviderPaymentID string                   `json:"provider_payment_id"`
-	Currency          string                   `json:"currency"`
+	ID                    uuid.UUID                `json:"id"`
+	OrderID               uuid.UUID                `json:"order_id"`
+	AmountInCents         int                      `json:"amount_in_cents"`
+	Provider              db.OrdersPaymentProvider `json:"provider"`
+	ProviderPaymentID     string                   `json:"provider_payment_id"`
+	Currency              string                   `json:"currency"`
+	RefundedAmountInCents int                      `json:"refunded_amount_in_cents"`
+	Status                db.OrdersPaymentStatus   `json:"status"`
+	TenderedInCents       *int                     `json:"tendered_in_cents,omitempty"`
+	ChangeDueInCents      *int                     `json:"change_due_in_cents,omitempty"`
+	RecordedBy            *uuid.UUID               `json:"recorded_by,omitempty"`
+}
+
+// OfflinePaymentRequestDto represents waiter's request to record payment taken in cash or on
+// restaurant's own card terminal. Tendered amount defaults to the amount paid.
+type OfflinePaymentRequestDto struct {
+	OrderID         uuid.UUID                `json:"-"`
+	Method          db.OrdersPaymentProvider `json:"method"            validate:"required,oneof=cash terminal"`
+	AmountInCents   int                      `json:"amount_in_cents"   validate:"required,gt=0"`
+	TenderedInCents *int                     `json:"tendered_in_cents" validate:"omitempty,gtefield=AmountInCents"`
+}
+
+// RefundRequestDto represents manager's request to refund an order or specific order items.
+// When neither amount nor items are provided everything that is left of order payments is refunded.
+type RefundRequestDto struct {
+	OrderID       uuid.UUID   `json:"-"`
+	AmountInCents *int        `json:"amount_in_cents" validate:"omitempty,gt=0"`
+	OrderItemIDs  []uuid.UUID `json:"order_item_ids"  validate:"excluded_with=AmountInCents,unique"`
+	Reason        string      `json:"reason"          validate:"required,max=255"`
+}
+
+// ProviderRefundRequestDto represents the data payment provider needs to refund a payment.
+type ProviderRefundRequestDto struct {
+	RefundID      uuid.UUID
+	RestaurantID  uuid.UUID
+	Payment       *PaymentDto
+	AmountInCents int
+	Reason        string
+}
+
+// RefundDto represents a refund of a single payment.
+type RefundDto struct {
+	ID               uuid.UUID                `json:"id"`
+	PaymentID        uuid.UUID                `json:"payment_id"`
+	OrderID          uuid.UUID                `json:"order_id"`
+	AmountInCents    int                      `json:"amount_in_cents"`
+	Currency         string                   `json:"currency"`
+	Provider         db.OrdersPaymentProvider `json:"provider"`
+	ProviderRefundID string                   `json:"provider_refund_id"`
+	Status           db.OrdersRefundStatus    `json:"status"`
+	Reason           string                   `json:"reason"`
+	OrderItemIDs     []uuid.UUID              `json:"order_item_ids"`
+	RequestedBy      uuid.UUID                `json:"requested_by"`
 }
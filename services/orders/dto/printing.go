@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrinterDto represents restaurant's networked ESC/POS printer. Kitchen tickets of items in
+// printer's categories are printed on it, printer without categories prints items no other
+// printer takes.
+type PrinterDto struct {
+	ID             uuid.UUID   `json:"id"`
+	RestaurantID   uuid.UUID   `json:"restaurant_id"`
+	Name           string      `json:"name"`
+	Host           string      `json:"host"`
+	Port           int         `json:"port"`
+	CategoryIDs    []uuid.UUID `json:"category_ids"`
+	PrintsReceipts bool        `json:"prints_receipts"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// CreatePrinterRequestDto represents manager's request to add a printer, port defaults to 9100.
+type CreatePrinterRequestDto struct {
+	RestaurantID   uuid.UUID   `json:"-"`
+	Name           string      `json:"name"            validate:"required,max=50"`
+	Host           string      `json:"host"            validate:"required,max=255,hostname_rfc1123|ip"`
+	Port           int         `json:"port"            validate:"omitempty,min=1,max=65535"`
+	CategoryIDs    []uuid.UUID `json:"category_ids"    validate:"dive,required"`
+	PrintsReceipts bool        `json:"prints_receipts"`
+}
+
+// KitchenOrderDto represents order items which weren't printed on kitchen tickets yet.
+type KitchenOrderDto struct {
+	OrderID      uuid.UUID
+	RestaurantID uuid.UUID
+	TableName    string
+	Items        []*KitchenItemDto
+}
+
+// KitchenItemDto represents an order item to be printed on a kitchen ticket.
+type KitchenItemDto struct {
+	OrderItemID uuid.UUID
+	Name        string
+	CategoryID  *uuid.UUID
+	CreatedAt   time.Time
+}
+
+// KitchenTicketDto represents a kitchen ticket printed on one of restaurant's printers.
+type KitchenTicketDto struct {
+	PrinterID   uuid.UUID               `json:"printer_id"`
+	PrinterName string                  `json:"printer_name"`
+	OrderID     uuid.UUID               `json:"order_id"`
+	TableName   string                  `json:"table_name"`
+	PrintedAt   time.Time               `json:"printed_at"`
+	Lines       []*KitchenTicketLineDto `json:"lines"`
+}
+
+// KitchenTicketLineDto represents same items of an order on a kitchen ticket.
+type KitchenTicketLineDto struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
@@ -0,0 +1,104 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromoCodeDto represents restaurant's promo code. Discount value is percent off for
+// percentage codes and amount in cents for fixed ones.
+type PromoCodeDto struct {
+	ID              uuid.UUID             `json:"id"`
+	RestaurantID    uuid.UUID             `json:"restaurant_id"`
+	Code            string                `json:"code"`
+	DiscountType    db.OrdersDiscountType `json:"discount_type"`
+	DiscountValue   int                   `json:"discount_value"`
+	MinSpendInCents int                   `json:"min_spend_in_cents"`
+	ValidFrom       *time.Time            `json:"valid_from,omitempty"`
+	ValidUntil      *time.Time            `json:"valid_until,omitempty"`
+	MaxUses         *int                  `json:"max_uses,omitempty"`
+	UsesCount       int                   `json:"uses_count"`
+	CreatedAt       time.Time             `json:"created_at"`
+}
+
+// CreatePromoCodeRequestDto represents manager's request to create a promo code.
+type CreatePromoCodeRequestDto struct {
+	RestaurantID    uuid.UUID             `json:"-"`
+	Code            string                `json:"code"               validate:"required,alphanum,min=3,max=32"`
+	DiscountType    db.OrdersDiscountType `json:"discount_type"      validate:"required,oneof=percentage fixed"`
+	DiscountValue   int                   `json:"discount_value"     validate:"required,gt=0"`
+	MinSpendInCents int                   `json:"min_spend_in_cents" validate:"gte=0"`
+	ValidFrom       *time.Time            `json:"valid_from"`
+	ValidUntil      *time.Time            `json:"valid_until"`
+	MaxUses         *int                  `json:"max_uses"           validate:"omitempty,gt=0"`
+}
+
+// PromotionRuleDto represents restaurant's automatic promotion applied to items added to
+// orders between StartsAt and EndsAt in rule's timezone, e.g. 2-for-1 on drinks 17:00-19:00.
+type PromotionRuleDto struct {
+	ID           uuid.UUID                  `json:"id"`
+	RestaurantID uuid.UUID                  `json:"restaurant_id"`
+	Name         string                     `json:"name"`
+	RuleType     db.OrdersPromotionRuleType `json:"rule_type"`
+	CategoryID   *uuid.UUID                 `json:"category_id,omitempty"`
+	PercentOff   int                        `json:"percent_off"`
+	BuyQuantity  int                        `json:"buy_quantity"`
+	FreeQuantity int                        `json:"free_quantity"`
+	StartsAt     string                     `json:"starts_at"`
+	EndsAt       string                     `json:"ends_at"`
+	Timezone     string                     `json:"timezone"`
+	IsActive     bool                       `json:"is_active"`
+	CreatedAt    time.Time                  `json:"created_at"`
+}
+
+// CreatePromotionRuleRequestDto represents manager's request to create an automatic promotion.
+// Rule without category applies to all restaurant's items, timezone defaults to UTC.
+type CreatePromotionRuleRequestDto struct {
+	RestaurantID uuid.UUID                  `json:"-"`
+	Name         string                     `json:"name"          validate:"required,max=100"`
+	RuleType     db.OrdersPromotionRuleType `json:"rule_type"     validate:"required,oneof=percentage buy_x_get_y"`
+	CategoryID   *uuid.UUID                 `json:"category_id"`
+	PercentOff   int                        `json:"percent_off"   validate:"required_if=RuleType percentage,gte=0,lte=100"`
+	BuyQuantity  int                        `json:"buy_quantity"  validate:"required_if=RuleType buy_x_get_y,gte=0,lte=10"`
+	FreeQuantity int                        `json:"free_quantity" validate:"required_if=RuleType buy_x_get_y,gte=0,lte=10"`
+	StartsAt     string                     `json:"starts_at"     validate:"required,datetime=15:04"`
+	EndsAt       string                     `json:"ends_at"       validate:"required,datetime=15:04"`
+	Timezone     string                     `json:"timezone"      validate:"omitempty,timezone"`
+}
+
+// ApplyPromoCodeRequestDto represents guest's request to apply a promo code to an order.
+type ApplyPromoCodeRequestDto struct {
+	OrderID uuid.UUID `json:"-"`
+	Code    string    `json:"code" validate:"required,max=32"`
+}
+
+// AppliedPromoCodeDto represents promo code applied to an order.
+type AppliedPromoCodeDto struct {
+	ID              uuid.UUID             `json:"id"`
+	Code            string                `json:"code"`
+	DiscountType    db.OrdersDiscountType `json:"discount_type"`
+	DiscountValue   int                   `json:"discount_value"`
+	MinSpendInCents int                   `json:"min_spend_in_cents"`
+}
+
+// DiscountSource tells what a discount line of an order comes from.
+type DiscountSource string
+
+const (
+	// DiscountSourcePromoCode is a discount of promo code applied to the order.
+	DiscountSourcePromoCode DiscountSource = "promo_code"
+	// DiscountSourcePromotionRule is a discount of restaurant's automatic promotion.
+	DiscountSourcePromotionRule DiscountSource = "promotion_rule"
+)
+
+// DiscountLineDto represents a discount applied to an order, items discounted by automatic
+// promotion are listed in OrderItemIDs.
+type DiscountLineDto struct {
+	Source        DiscountSource `json:"source"`
+	PromotionID   uuid.UUID      `json:"promotion_id"`
+	Name          string         `json:"name"`
+	AmountInCents int            `json:"amount_in_cents"`
+	OrderItemIDs  []uuid.UUID    `json:"order_item_ids,omitempty"`
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProviderCredentialsDto holds restaurant's own payment provider credentials,
+// which fields are required depends on the provider.
+type ProviderCredentialsDto struct {
+	SecretKey     string `json:"secret_key,omitempty"`
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	BrandID       string `json:"brand_id,omitempty"`
+	PublicKey     string `json:"public_key,omitempty"`
+}
+
+// RestaurantPaymentProviderDto represents payment provider configured for a restaurant.
+// Credentials are never returned to the client.
+type RestaurantPaymentProviderDto struct {
+	RestaurantID uuid.UUID                `json:"restaurant_id"`
+	Provider     db.OrdersPaymentProvider `json:"provider"`
+	Credentials  *ProviderCredentialsDto  `json:"-"`
+	IsDefault    bool                     `json:"is_default"`
+	WebhookURL   string                   `json:"webhook_url"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// SetPaymentProviderRequestDto represents manager's request to configure restaurant's payment provider.
+type SetPaymentProviderRequestDto struct {
+	RestaurantID uuid.UUID                `json:"-"`
+	Provider     db.OrdersPaymentProvider `json:"-"`
+	Credentials  *ProviderCredentialsDto  `json:"credentials" validate:"required"`
+	IsDefault    bool                     `json:"is_default"`
+}
+
+// WebhookDto represents webhook request received from a payment provider on behalf of a restaurant.
+// RestaurantID is uuid.Nil for webhooks sent to the platform url.
+type WebhookDto struct {
+	Provider     db.OrdersPaymentProvider
+	RestaurantID uuid.UUID
+	Payload      []byte
+	Header       http.Header
+}
+
+// WebhookEventDto identifies provider's webhook event in the webhooks inbox.
+type WebhookEventDto struct {
+	Provider     db.OrdersPaymentProvider
+	EventID      string
+	RestaurantID uuid.UUID
+}
+
+// ProviderEventType is provider independent type of a verified webhook event.
+type ProviderEventType string
+
+const (
+	// ProviderEventPaymentSucceeded is sent when checkout is paid.
+	ProviderEventPaymentSucceeded ProviderEventType = "payment_succeeded"
+	// ProviderEventPaymentFailed is sent when payment attempt is declined, checkout stays open.
+	ProviderEventPaymentFailed ProviderEventType = "payment_failed"
+	// ProviderEventCheckoutExpired is sent when checkout expires without payment.
+	ProviderEventCheckoutExpired ProviderEventType = "checkout_expired"
+	// ProviderEventCheckoutCancelled is sent when customer cancels checkout without payment.
+	ProviderEventCheckoutCancelled ProviderEventType = "checkout_cancelled"
+	// ProviderEventPaymentRefunded is sent when payment is fully refunded on provider's side.
+	ProviderEventPaymentRefunded ProviderEventType = "payment_refunded"
+	// ProviderEventPaymentDisputed is sent when customer disputes the payment.
+	ProviderEventPaymentDisputed ProviderEventType = "payment_disputed"
+	// ProviderEventRefundUpdated is sent when status of our refund changes.
+	ProviderEventRefundUpdated ProviderEventType = "refund_updated"
+	// ProviderEventIgnored is any other event, it's acknowledged without doing anything.
+	ProviderEventIgnored ProviderEventType = "ignored"
+)
+
+// ProviderEventDto represents verified provider's webhook event.
+// OrderID is set for checkout events, ProviderPaymentID for payment refunded and disputed events,
+// Payment for payment succeeded and Refund for refund updated events.
+// Checkout events identify their payment attempt with PaymentAttemptID, SessionID or both.
+type ProviderEventDto struct {
+	ID                string            `json:"id"`
+	Type              ProviderEventType `json:"type"`
+	OrderID           uuid.UUID         `json:"order_id"`
+	PaymentAttemptID  uuid.UUID         `json:"payment_attempt_id"`
+	SessionID         string            `json:"session_id,omitempty"`
+	ProviderPaymentID string            `json:"provider_payment_id,omitempty"`
+	Payment           *PaymentDto       `json:"payment,omitempty"`
+	Refund            *RefundDto        `json:"refund,omitempty"`
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReceiptSettingsDto represents restaurant's receipt settings. Tax rate is VAT percentage
+// included in menu prices.
+type ReceiptSettingsDto struct {
+	RestaurantID uuid.UUID  `json:"restaurant_id"`
+	TaxRate      float64    `json:"tax_rate"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// SetReceiptSettingsRequestDto represents manager's request to configure restaurant's receipts.
+type SetReceiptSettingsRequestDto struct {
+	RestaurantID uuid.UUID `json:"-"`
+	TaxRate      *float64  `json:"tax_rate" validate:"required,gte=0,lt=100"`
+}
+
+// IssuedReceiptDto represents receipt number issued to an order together with restaurant
+// details printed on the receipt. Contents are nil for receipts issued before their contents
+// were saved with them.
+type IssuedReceiptDto struct {
+	ID                uuid.UUID
+	OrderID           uuid.UUID
+	RestaurantID      uuid.UUID
+	Number            int
+	TaxRate           float64
+	IssuedAt          time.Time
+	Contents          *ReceiptContentsDto
+	RestaurantName    string
+	RestaurantAddress string
+}
+
+// ReceiptContentsDto represents lines, discounts and totals of the order as they were when its
+// receipt was issued.
+type ReceiptContentsDto struct {
+	Currency        string             `json:"currency"`
+	Lines           []*ReceiptLineDto  `json:"lines"`
+	Discounts       []*DiscountLineDto `json:"discounts"`
+	SubtotalInCents int                `json:"subtotal_in_cents"`
+	DiscountInCents int                `json:"discount_in_cents"`
+	TipInCents      int                `json:"tip_in_cents"`
+}
+
+// ReceiptDto represents receipt of a completed order. Item prices include tax, total is
+// items total minus discounts plus tip.
+type ReceiptDto struct {
+	Number            int                  `json:"receipt_number"`
+	OrderID           uuid.UUID            `json:"order_id"`
+	IssuedAt          time.Time            `json:"issued_at"`
+	RestaurantName    string               `json:"restaurant_name"`
+	RestaurantAddress string               `json:"restaurant_address"`
+	Currency          string               `json:"currency"`
+	Lines             []*ReceiptLineDto    `json:"lines"`
+	Discounts         []*DiscountLineDto   `json:"discounts,omitempty"`
+	SubtotalInCents   int                  `json:"subtotal_in_cents"`
+	DiscountInCents   int                  `json:"discount_in_cents"`
+	TaxRate           float64              `json:"tax_rate"`
+	TaxInCents        int                  `json:"tax_in_cents"`
+	TipInCents        int                  `json:"tip_in_cents"`
+	TotalInCents      int                  `json:"total_in_cents"`
+	Payments          []*ReceiptPaymentDto `json:"payments"`
+}
+
+// ReceiptLineDto represents same menu items of an order at the same price.
+type ReceiptLineDto struct {
+	Name             string `json:"name"`
+	Quantity         int    `json:"quantity"`
+	UnitPriceInCents int    `json:"unit_price_in_cents"`
+	AmountInCents    int    `json:"amount_in_cents"`
+}
+
+// ReceiptPaymentDto represents payment of the order printed on its receipt.
+type ReceiptPaymentDto struct {
+	Method                db.OrdersPaymentProvider `json:"method"`
+	AmountInCents         int                      `json:"amount_in_cents"`
+	RefundedAmountInCents int                      `json:"refunded_amount_in_cents"`
+}
+
+// EmailReceiptRequestDto represents guest's request to receive order's receipt by email.
+type EmailReceiptRequestDto struct {
+	OrderID      uuid.UUID `json:"-"`
+	SessionToken string    `json:"-"`
+	Email        string    `json:"email" validate:"required,email,max=254"`
+}
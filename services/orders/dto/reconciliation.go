@@ -0,0 +1,78 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconciliationIssue is the kind of difference found between our payments and provider's charges.
+type ReconciliationIssue string
+
+const (
+	// ReconciliationMissing is our payment provider has no charge for.
+	ReconciliationMissing ReconciliationIssue = "missing"
+	// ReconciliationExtra is provider's charge we have no payment for.
+	ReconciliationExtra ReconciliationIssue = "extra"
+	// ReconciliationAmountMismatch is payment whose amount or currency differs from provider's charge.
+	ReconciliationAmountMismatch ReconciliationIssue = "amount_mismatch"
+)
+
+// ListChargesRequestDto represents request to list provider's settled charges created in [From, To).
+type ListChargesRequestDto struct {
+	From time.Time
+	To   time.Time
+}
+
+// ProviderChargeDto represents charge settled on provider's side, its id matches
+// provider_payment_id of our payment.
+type ProviderChargeDto struct {
+	ProviderPaymentID string
+	AmountInCents     int
+	Currency          string
+	CreatedAt         time.Time
+}
+
+// ReconciliationRequestDto represents request to reconcile payments of the provider in [From, To).
+// RestaurantID is optional, platform's provider account is reconciled when it's not set.
+type ReconciliationRequestDto struct {
+	Provider     db.OrdersPaymentProvider
+	RestaurantID *uuid.UUID
+	From         time.Time
+	To           time.Time
+}
+
+// ReconciliationPaymentDto represents our payment that is matched against provider's charges.
+type ReconciliationPaymentDto struct {
+	ID                uuid.UUID
+	OrderID           uuid.UUID
+	ProviderPaymentID string
+	AmountInCents     int
+	Currency          string
+	CreatedAt         time.Time
+}
+
+// ReconciliationRowDto represents a single difference between our payments and provider's charges.
+// Payment fields are empty for extra charges, provider's fields are empty for missing payments.
+type ReconciliationRowDto struct {
+	Issue                 ReconciliationIssue `json:"issue"`
+	ProviderPaymentID     string              `json:"provider_payment_id"`
+	PaymentID             *uuid.UUID          `json:"payment_id"`
+	OrderID               *uuid.UUID          `json:"order_id"`
+	AmountInCents         *int                `json:"amount_in_cents"`
+	Currency              string              `json:"currency"`
+	ProviderAmountInCents *int                `json:"provider_amount_in_cents"`
+	ProviderCurrency      string              `json:"provider_currency"`
+	CreatedAt             time.Time           `json:"created_at"`
+}
+
+// ReconciliationReportDto represents result of the reconciliation, rows only list differences.
+type ReconciliationReportDto struct {
+	Provider     db.OrdersPaymentProvider `json:"provider"`
+	RestaurantID *uuid.UUID               `json:"restaurant_id"`
+	From         time.Time                `json:"from"`
+	To           time.Time                `json:"to"`
+	Matched      int                      `json:"matched"`
+	Rows         []*ReconciliationRowDto  `json:"rows"`
+}
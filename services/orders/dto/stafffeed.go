@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderTableDto represents the table an order is for and waiters assigned to the order.
+type OrderTableDto struct {
+	TableID      uuid.UUID   `json:"table_id"`
+	RestaurantID uuid.UUID   `json:"-"`
+	TableName    string      `json:"table_name"`
+	Section      *string     `json:"section,omitempty"`
+	WaiterIDs    []uuid.UUID `json:"waiter_ids"`
+}
+
+// StaffEventDto represents an event of restaurant's order shown in staff feed. Table is
+// loaded when the event is published, items are set when items were added, payment
+// when a payment succeeded and assistance when assistance request was sent or handled.
+type StaffEventDto struct {
+	RestaurantID uuid.UUID             `json:"restaurant_id"`
+	OrderID      uuid.UUID             `json:"order_id"`
+	Table        *OrderTableDto        `json:"table,omitempty"`
+	Items        []*OrderItemDto       `json:"items,omitempty"`
+	Payment      *PaymentDto           `json:"payment,omitempty"`
+	Assistance   *AssistanceRequestDto `json:"assistance,omitempty"`
+	OccurredAt   time.Time             `json:"occurred_at"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TipSettingsDto represents restaurant's tip presets and the rule used to split tips
+// among waiters.
+type TipSettingsDto struct {
+	RestaurantID     uuid.UUID            `json:"restaurant_id"`
+	Presets          []float64            `json:"presets"`
+	PoolRule         db.OrdersTipPoolRule `json:"pool_rule"`
+	ShiftLengthHours int                  `json:"shift_length_hours"`
+	UpdatedAt        *time.Time           `json:"updated_at,omitempty"`
+}
+
+// SetTipSettingsRequestDto represents manager's request to configure restaurant's tips.
+// Shift length is only used by shift_pool rule and defaults to 8 hours.
+type SetTipSettingsRequestDto struct {
+	RestaurantID     uuid.UUID            `json:"-"`
+	Presets          []float64            `json:"presets"            validate:"required,min=1,max=5,unique,dive,gt=0,lte=100"`
+	PoolRule         db.OrdersTipPoolRule `json:"pool_rule"          validate:"required,oneof=equal time_assigned shift_pool"`
+	ShiftLengthHours int                  `json:"shift_length_hours" validate:"omitempty,oneof=1 2 3 4 6 8 12 24"`
+}
+
+// TipReportRequestDto represents manager's request for restaurant's tips per waiter
+// of orders completed between From and To.
+type TipReportRequestDto struct {
+	RestaurantID uuid.UUID `json:"-"`
+	From         time.Time `json:"-" query:"from" validate:"required"`
+	To           time.Time `json:"-" query:"to"   validate:"required,gtfield=From"`
+}
+
+// CompletedOrderWaiterDto represents waiter assigned to a completed order, order without
+// waiters has nil UserID.
+type CompletedOrderWaiterDto struct {
+	OrderID          uuid.UUID
+	TipAmountInCents int
+	CompletedAt      time.Time
+	UserID           *uuid.UUID
+	AssignedAt       time.Time
+}
+
+// WaiterTipsDto represents tips a waiter earned in the report period.
+type WaiterTipsDto struct {
+	UserID      uuid.UUID `json:"user_id"`
+	OrdersCount int       `json:"orders_count"`
+	TipsInCents int       `json:"tips_in_cents"`
+}
+
+// TipReportDto represents tips of restaurant's completed orders split among waiters.
+// Tips of orders nobody was assigned to are reported as unassigned.
+type TipReportDto struct {
+	RestaurantID          uuid.UUID            `json:"restaurant_id"`
+	From                  time.Time            `json:"from"`
+	To                    time.Time            `json:"to"`
+	PoolRule              db.OrdersTipPoolRule `json:"pool_rule"`
+	TotalTipsInCents      int                  `json:"total_tips_in_cents"`
+	UnassignedTipsInCents int                  `json:"unassigned_tips_in_cents"`
+	Waiters               []*WaiterTipsDto     `json:"waiters"`
+}
@@ -0,0 +1,204 @@
+// Package events publishes order changes to every API instance, so guests of the same order
+// see each other's changes no matter which instance their websocket is connected to.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/config"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownPubSubType is returned when pub/sub type in the config is not supported.
+	ErrUnknownPubSubType = errors.New("unknown pub/sub type")
+	// ErrEventsUnavailable is returned when events after the sequence number are no longer
+	// kept, the client has to sync with the whole order instead.
+	ErrEventsUnavailable = errors.New("order events are no longer available")
+)
+
+// subscriptionBuffer is how many events a subscriber can fall behind before events are
+// dropped or the subscription blocks.
+const subscriptionBuffer = 64
+
+// Event is a change of an order. Order is the order after the change, it can be left out,
+// e.g. when the change was made by payments, subscribers then load the order themselves.
+// Seq numbers events of an order one after another, starting from 1.
+//
+// Presence events tell that Participant joined or left the order over the connection, joined
+// events are published again while the connection stays open. Staff events tell staff
+// of order's restaurant what happened to the order, they are delivered to staff feeds only.
+// Neither of them changes the order, so they aren't numbered nor kept for replay.
+type Event struct {
+	OrderID      uuid.UUID           `json:"order_id"`
+	Seq          int64               `json:"seq"`
+	Type         dto.WSMessageType   `json:"type"`
+	Order        *dto.OrderDto       `json:"order,omitempty"`
+	Participant  *dto.ParticipantDto `json:"participant,omitempty"`
+	ConnectionID uuid.UUID           `json:"connection_id"`
+	Staff        *dto.StaffEventDto  `json:"staff,omitempty"`
+}
+
+// IsPresence reports whether the event is participant joining or leaving the order.
+func (e *Event) IsPresence() bool {
+	return e.Type == dto.MsgParticipantJoined || e.Type == dto.MsgParticipantLeft
+}
+
+// IsStaff reports whether the event is meant for staff feed of order's restaurant.
+func (e *Event) IsStaff() bool {
+	return e.Staff != nil
+}
+
+// IsNumbered reports whether the event is a change of the order, numbered and kept for
+// replay.
+func (e *Event) IsNumbered() bool {
+	return !e.IsPresence() && !e.IsStaff()
+}
+
+// Publisher defines method for publishing order events. Publishing numbers the event with
+// order's next sequence number, unless it's a presence or staff event. It's best effort,
+// failures are logged and don't fail the change that was already made.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event)
+}
+
+// Subscriber defines method for receiving order events published by any instance. Events
+// are received until the context is cancelled, then the channel is closed.
+type Subscriber interface {
+	Subscribe(ctx context.Context) (<-chan *Event, error)
+}
+
+// Log defines methods for reading recent events of an order, so clients that reconnect can
+// catch up on the events they missed.
+type Log interface {
+	// LastSeq returns sequence number of order's last event, 0 if it has none.
+	LastSeq(ctx context.Context, orderID uuid.UUID) (int64, error)
+	// EventsAfter returns order's events following the sequence number, ErrEventsUnavailable
+	// when some of them are no longer kept.
+	EventsAfter(ctx context.Context, orderID uuid.UUID, seq int64) ([]*Event, error)
+}
+
+// Feed receives order events and replays recent ones.
+type Feed interface {
+	Subscriber
+	Log
+}
+
+// PubSub publishes order events and receives them.
+type PubSub interface {
+	Publisher
+	Feed
+}
+
+// GetPubSub returns the PubSub implementation configured by the pub/sub type. Postgres
+// pub/sub listens on its own connection to the database at dbURI. Both keep as many recent
+// events of each order as the config allows to replay.
+//
+//nolint:ireturn
+func GetPubSub(
+	cfg *config.WebsocketConfig,
+	conn *sql.DB,
+	dbURI string,
+	logger *slog.Logger,
+) (PubSub, error) {
+	switch cfg.PubSub {
+	case config.PubSubTypePostgres:
+		return NewPostgresPubSub(conn, db.New(conn), dbURI, cfg.ReplayEvents, logger), nil
+	case config.PubSubTypeMemory:
+		return NewMemoryPubSub(cfg.ReplayEvents), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPubSubType, cfg.PubSub)
+	}
+}
+
+// NewOrderEvent returns event of the order's change.
+func NewOrderEvent(msgType dto.WSMessageType, order *dto.OrderDto) *Event {
+	return &Event{
+		OrderID:      order.ID,
+		Seq:          0,
+		Type:         msgType,
+		Order:        order,
+		Participant:  nil,
+		ConnectionID: uuid.Nil,
+		Staff:        nil,
+	}
+}
+
+// NewOrderChangedEvent returns event of a change that didn't return the order, subscribers
+// load the order themselves.
+func NewOrderChangedEvent(orderID uuid.UUID) *Event {
+	return &Event{
+		OrderID:      orderID,
+		Seq:          0,
+		Type:         dto.MsgUpdateOrder,
+		Order:        nil,
+		Participant:  nil,
+		ConnectionID: uuid.Nil,
+		Staff:        nil,
+	}
+}
+
+// NewPresenceEvent returns event of the participant joining or leaving the order over the
+// connection, msgType is MsgParticipantJoined or MsgParticipantLeft.
+func NewPresenceEvent(
+	msgType dto.WSMessageType,
+	orderID uuid.UUID,
+	participant *dto.ParticipantDto,
+	connectionID uuid.UUID,
+) *Event {
+	return &Event{
+		OrderID:      orderID,
+		Seq:          0,
+		Type:         msgType,
+		Order:        nil,
+		Participant:  participant,
+		ConnectionID: connectionID,
+		Staff:        nil,
+	}
+}
+
+// NewStaffEvent returns event for staff feed of order's restaurant, msgType is the kind of
+// the event, e.g. MsgOrderOpened.
+func NewStaffEvent(msgType dto.WSMessageType, staffEvent *dto.StaffEventDto) *Event {
+	return &Event{
+		OrderID:      staffEvent.OrderID,
+		Seq:          0,
+		Type:         msgType,
+		Order:        nil,
+		Participant:  nil,
+		ConnectionID: uuid.Nil,
+		Staff:        staffEvent,
+	}
+}
+
+// eventsAfter returns events of the log following the sequence number. Log holds the most
+// recent events in order and last is the sequence number of the last event published.
+func eventsAfter(log []*Event, last, seq int64) ([]*Event, error) {
+	if seq > last || seq < 0 {
+		return nil, ErrEventsUnavailable
+	}
+
+	if seq == last {
+		return []*Event{}, nil
+	}
+
+	missed := make([]*Event, 0, last-seq)
+
+	for _, event := range log {
+		if event.Seq > seq {
+			missed = append(missed, event)
+		}
+	}
+
+	if len(missed) == 0 || missed[0].Seq != seq+1 {
+		return nil, ErrEventsUnavailable
+	}
+
+	return missed, nil
+}
@@ -0,0 +1,229 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type eventsTestSuite struct {
+	suite.Suite
+}
+
+func TestEventsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(eventsTestSuite))
+}
+
+func newOrder(items int) *dto.OrderDto {
+	order := &dto.OrderDto{
+		ID:           uuid.New(),
+		RestaurantID: uuid.New(),
+		Status:       db.OrderStatusOpen,
+		Currency:     "eur",
+	}
+
+	for range items {
+		order.Items = append(order.Items, &dto.OrderItemDto{
+			ID:           uuid.New(),
+			ItemID:       uuid.New(),
+			Name:         "Cepelinai",
+			PriceInCents: 900,
+		})
+	}
+
+	return order
+}
+
+func (suite *eventsTestSuite) receive(events <-chan *Event) *Event {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		suite.Fail("event wasn't received")
+
+		return nil
+	}
+}
+
+func (suite *eventsTestSuite) TestMemoryPubSub() {
+	pubsub := NewMemoryPubSub(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	second, err := pubsub.Subscribe(context.Background())
+	suite.Require().NoError(err)
+
+	event := NewOrderEvent(dto.MsgAddItem, newOrder(1))
+	pubsub.Publish(context.Background(), event)
+
+	suite.Equal(event, suite.receive(first))
+	suite.Equal(event, suite.receive(second))
+
+	cancel()
+
+	suite.Eventually(func() bool {
+		_, ok := <-first
+
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	pubsub.Publish(context.Background(), NewOrderChangedEvent(event.OrderID))
+
+	changed := suite.receive(second)
+	suite.Equal(event.OrderID, changed.OrderID)
+	suite.Equal(int64(2), changed.Seq)
+	suite.Nil(changed.Order)
+}
+
+func (suite *eventsTestSuite) TestMemoryPubSub_EventsAfter() {
+	pubsub := NewMemoryPubSub(3)
+	order := newOrder(1)
+	ctx := context.Background()
+
+	seq, err := pubsub.LastSeq(ctx, order.ID)
+	suite.Require().NoError(err)
+	suite.Zero(seq)
+
+	missed, err := pubsub.EventsAfter(ctx, order.ID, 0)
+	suite.Require().NoError(err)
+	suite.Empty(missed)
+
+	for range 5 {
+		pubsub.Publish(ctx, NewOrderEvent(dto.MsgAddItem, order))
+	}
+
+	pubsub.Publish(ctx, NewOrderChangedEvent(uuid.New()))
+
+	seq, err = pubsub.LastSeq(ctx, order.ID)
+	suite.Require().NoError(err)
+	suite.Equal(int64(5), seq)
+
+	missed, err = pubsub.EventsAfter(ctx, order.ID, 3)
+	suite.Require().NoError(err)
+	suite.Require().Len(missed, 2)
+	suite.Equal(int64(4), missed[0].Seq)
+	suite.Equal(int64(5), missed[1].Seq)
+
+	missed, err = pubsub.EventsAfter(ctx, order.ID, 5)
+	suite.Require().NoError(err)
+	suite.Empty(missed)
+
+	// only the last 3 events are kept
+	_, err = pubsub.EventsAfter(ctx, order.ID, 1)
+	suite.Require().ErrorIs(err, ErrEventsUnavailable)
+
+	_, err = pubsub.EventsAfter(ctx, order.ID, 6)
+	suite.Require().ErrorIs(err, ErrEventsUnavailable)
+}
+
+func (suite *eventsTestSuite) TestMemoryPubSub_PresenceAndStaffEventsAreNotNumbered() {
+	pubsub := NewMemoryPubSub(10)
+	ctx := context.Background()
+	order := newOrder(1)
+
+	events, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	participant := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Tom",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+
+	pubsub.Publish(ctx, NewOrderEvent(dto.MsgAddItem, order))
+	pubsub.Publish(ctx, NewPresenceEvent(
+		dto.MsgParticipantJoined, order.ID, participant, uuid.New(),
+	))
+	pubsub.Publish(ctx, NewStaffEvent(dto.MsgOrderLocked, &dto.StaffEventDto{
+		RestaurantID: order.RestaurantID,
+		OrderID:      order.ID,
+		Table:        nil,
+		Items:        nil,
+		Payment:      nil,
+		Assistance:   nil,
+		OccurredAt:   time.Now(),
+	}))
+
+	suite.Equal(int64(1), suite.receive(events).Seq)
+
+	joined := suite.receive(events)
+	suite.True(joined.IsPresence())
+	suite.Equal(int64(0), joined.Seq)
+	suite.Equal(participant, joined.Participant)
+
+	locked := suite.receive(events)
+	suite.True(locked.IsStaff())
+	suite.Equal(int64(0), locked.Seq)
+	suite.Equal(order.ID, locked.Staff.OrderID)
+
+	lastSeq, err := pubsub.LastSeq(ctx, order.ID)
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), lastSeq)
+
+	missed, err := pubsub.EventsAfter(ctx, order.ID, 0)
+	suite.Require().NoError(err)
+	suite.Len(missed, 1)
+}
+
+func (suite *eventsTestSuite) TestMemoryPubSub_SlowSubscriberDoesNotBlock() {
+	pubsub := NewMemoryPubSub(10)
+
+	_, err := pubsub.Subscribe(context.Background())
+	suite.Require().NoError(err)
+
+	done := make(chan struct{})
+
+	go func() {
+		for range subscriptionBuffer + 1 {
+			pubsub.Publish(context.Background(), NewOrderChangedEvent(uuid.New()))
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		suite.Fail("publishing to a full subscription blocked")
+	}
+}
+
+func (suite *eventsTestSuite) TestEncodePayload() {
+	order := newOrder(1)
+
+	payload, err := encodePayload(NewOrderEvent(dto.MsgAddItem, order))
+	suite.Require().NoError(err)
+
+	var event Event
+
+	suite.Require().NoError(json.Unmarshal(payload, &event))
+	suite.Equal(order.ID, event.OrderID)
+	suite.Equal(dto.MsgAddItem, event.Type)
+	suite.Require().NotNil(event.Order)
+	suite.Len(event.Order.Items, 1)
+
+	order = newOrder(100)
+
+	payload, err = encodePayload(NewOrderEvent(dto.MsgAddItem, order))
+	suite.Require().NoError(err)
+	suite.LessOrEqual(len(payload), maxPayloadBytes)
+	suite.False(strings.Contains(string(payload), `"order":`))
+
+	var truncated Event
+
+	suite.Require().NoError(json.Unmarshal(payload, &truncated))
+	suite.Equal(order.ID, truncated.OrderID)
+	suite.Equal(dto.MsgAddItem, truncated.Type)
+	suite.Nil(truncated.Order)
+}
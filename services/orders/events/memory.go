@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type memoryPubSub struct {
+	mu           sync.Mutex
+	subscribers  map[chan *Event]struct{}
+	replayEvents int
+	lastSeqs     map[uuid.UUID]int64
+	logs         map[uuid.UUID][]*Event
+}
+
+// NewMemoryPubSub creates pub/sub which delivers events only within this process, it's
+// meant for a single instance and tests. It keeps replayEvents most recent events of each
+// order.
+//
+//revive:disable:unexported-return
+func NewMemoryPubSub(replayEvents int) *memoryPubSub {
+	return &memoryPubSub{
+		mu:           sync.Mutex{},
+		subscribers:  map[chan *Event]struct{}{},
+		replayEvents: replayEvents,
+		lastSeqs:     map[uuid.UUID]int64{},
+		logs:         map[uuid.UUID][]*Event{},
+	}
+}
+
+//revive:enable:unexported-return
+
+// Publish delivers the event to every subscriber, subscribers that fell behind by more than
+// the buffer miss it rather than block the publisher.
+func (p *memoryPubSub) Publish(_ context.Context, event *Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if event.IsNumbered() {
+		p.lastSeqs[event.OrderID]++
+		event.Seq = p.lastSeqs[event.OrderID]
+
+		log := append(p.logs[event.OrderID], event)
+		if len(log) > p.replayEvents {
+			log = log[len(log)-p.replayEvents:]
+		}
+
+		p.logs[event.OrderID] = log
+	}
+
+	for subscriber := range p.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (p *memoryPubSub) Subscribe(ctx context.Context) (<-chan *Event, error) {
+	subscriber := make(chan *Event, subscriptionBuffer)
+
+	p.mu.Lock()
+	p.subscribers[subscriber] = struct{}{}
+	p.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.subscribers, subscriber)
+		close(subscriber)
+	}()
+
+	return subscriber, nil
+}
+
+func (p *memoryPubSub) LastSeq(_ context.Context, orderID uuid.UUID) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastSeqs[orderID], nil
+}
+
+func (p *memoryPubSub) EventsAfter(
+	_ context.Context,
+	orderID uuid.UUID,
+	seq int64,
+) ([]*Event, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return eventsAfter(p.logs[orderID], p.lastSeqs[orderID], seq)
+}
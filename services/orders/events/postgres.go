@@ -0,0 +1,289 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// orderEventsChannel is the Postgres notification channel order events are published on.
+const orderEventsChannel = "order_events"
+
+// maxPayloadBytes is the longest notification payload Postgres accepts.
+const maxPayloadBytes = 7999
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+type postgresPubSub struct {
+	db           *sql.DB
+	q            *db.Queries
+	dbURI        string
+	replayEvents int
+	logger       *slog.Logger
+}
+
+// NewPostgresPubSub creates pub/sub on Postgres LISTEN/NOTIFY, events are delivered to
+// every instance listening on the same database. Events whose order doesn't fit into the
+// notification payload are published without the order. Events are numbered and the
+// replayEvents most recent events of each order are kept in orders.order_events.
+//
+//revive:disable:unexported-return
+func NewPostgresPubSub(
+	conn *sql.DB,
+	q *db.Queries,
+	dbURI string,
+	replayEvents int,
+	logger *slog.Logger,
+) *postgresPubSub {
+	return &postgresPubSub{
+		db:           conn,
+		q:            q,
+		dbURI:        dbURI,
+		replayEvents: replayEvents,
+		logger:       logger,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (p *postgresPubSub) Publish(ctx context.Context, event *Event) {
+	err := p.publish(ctx, event)
+	if err != nil {
+		p.logger.Error("failed to publish order event", "orderID", event.OrderID, "error", err)
+	}
+}
+
+// publish numbers and saves the event and notifies listeners in one transaction, so the
+// notifications are delivered in the order events are numbered in. Presence and staff
+// events are only notified.
+func (p *postgresPubSub) publish(ctx context.Context, event *Event) error {
+	if !event.IsNumbered() {
+		return notify(ctx, p.q, event)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := p.q.WithTx(tx)
+
+	seq, err := qtx.NextOrderEventSeq(ctx, event.OrderID)
+	if err != nil {
+		return fmt.Errorf("getting next event sequence number: %w", err)
+	}
+
+	event.Seq = seq
+
+	logged, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshaling order event: %w", err)
+	}
+
+	err = qtx.SaveOrderEvent(ctx, db.SaveOrderEventParams{
+		OrderID: event.OrderID,
+		Seq:     seq,
+		Type:    string(event.Type),
+		Payload: logged,
+	})
+	if err != nil {
+		return fmt.Errorf("saving order event: %w", err)
+	}
+
+	err = qtx.DeleteOrderEventsUpTo(ctx, db.DeleteOrderEventsUpToParams{
+		OrderID: event.OrderID,
+		Seq:     seq - int64(p.replayEvents),
+	})
+	if err != nil {
+		return fmt.Errorf("deleting old order events: %w", err)
+	}
+
+	err = notify(ctx, qtx, event)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func notify(ctx context.Context, q *db.Queries, event *Event) error {
+	payload, err := encodePayload(event)
+	if err != nil {
+		return err
+	}
+
+	err = q.NotifyOrderEvent(ctx, db.NotifyOrderEventParams{
+		Channel: orderEventsChannel,
+		Payload: string(payload),
+	})
+	if err != nil {
+		return fmt.Errorf("notifying order event: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresPubSub) LastSeq(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	seq, err := p.q.GetOrderLastEventSeq(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("getting last event sequence number: %w", err)
+	}
+
+	return seq, nil
+}
+
+func (p *postgresPubSub) EventsAfter(
+	ctx context.Context,
+	orderID uuid.UUID,
+	seq int64,
+) ([]*Event, error) {
+	last, err := p.LastSeq(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.q.GetOrderEventsAfter(ctx, db.GetOrderEventsAfterParams{
+		OrderID: orderID,
+		Seq:     seq,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting order events: %w", err)
+	}
+
+	log := make([]*Event, 0, len(rows))
+
+	for _, row := range rows {
+		var event Event
+
+		err = json.Unmarshal(row.Payload, &event)
+		if err != nil {
+			return nil, fmt.Errorf("decoding order event %d: %w", row.Seq, err)
+		}
+
+		log = append(log, &event)
+	}
+
+	// events published after the last sequence number was read are returned as well
+	if len(log) > 0 {
+		last = max(last, log[len(log)-1].Seq)
+	}
+
+	return eventsAfter(log, last, seq)
+}
+
+// Subscribe listens for order events on a dedicated connection, which is re-established
+// when it's lost. Events published while it was down are missed.
+func (p *postgresPubSub) Subscribe(ctx context.Context) (<-chan *Event, error) {
+	listener := pq.NewListener(
+		p.dbURI,
+		minReconnectInterval,
+		maxReconnectInterval,
+		func(eventType pq.ListenerEventType, err error) {
+			if err != nil {
+				p.logger.Error("order events listener failed", "event", eventType, "error", err)
+			}
+		},
+	)
+
+	err := listener.Listen(orderEventsChannel)
+	if err != nil {
+		_ = listener.Close()
+
+		return nil, fmt.Errorf("listening for order events: %w", err)
+	}
+
+	events := make(chan *Event, subscriptionBuffer)
+
+	go p.receive(ctx, listener, events)
+
+	return events, nil
+}
+
+func (p *postgresPubSub) receive(ctx context.Context, listener *pq.Listener, events chan *Event) {
+	defer close(events)
+	defer listener.Close() //nolint:errcheck
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			go func() { _ = listener.Ping() }()
+		case notification := <-listener.Notify:
+			// nil notification means the connection was re-established
+			if notification == nil {
+				p.logger.Warn("order events listener reconnected, events might have been missed")
+
+				continue
+			}
+
+			var event Event
+
+			err := json.Unmarshal([]byte(notification.Extra), &event)
+			if err != nil {
+				p.logger.Error("failed to decode order event", "error", err)
+
+				continue
+			}
+
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// encodePayload encodes the event as notification payload, the order is left out when the
+// payload would be too long.
+func encodePayload(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling order event: %w", err)
+	}
+
+	if len(payload) <= maxPayloadBytes {
+		return payload, nil
+	}
+
+	payload, err = json.Marshal(&Event{
+		OrderID:      event.OrderID,
+		Seq:          event.Seq,
+		Type:         event.Type,
+		Order:        nil,
+		Participant:  event.Participant,
+		ConnectionID: event.ConnectionID,
+		Staff:        event.Staff,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling order event: %w", err)
+	}
+
+	return payload, nil
+}
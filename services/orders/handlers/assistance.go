@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/services"
+	"golang-dining-ordering/services/orders/sessions"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const assistanceRequestIDParamName = "request_id"
+
+// AssistanceHandler handles HTTP requests of assistance guests ask for from the table and
+// staff responds to.
+type AssistanceHandler struct {
+	svc services.AssistanceService
+}
+
+// NewAssistanceHandler creates a new Handler for assistance requests.
+func NewAssistanceHandler(svc services.AssistanceService) *AssistanceHandler {
+	return &AssistanceHandler{
+		svc: svc,
+	}
+}
+
+// HandleRequestAssistance handles http request of guest with table session of the order, or
+// of restaurant's staff, to call the waiter, ask for the bill or for anything else.
+func (h *AssistanceHandler) HandleRequestAssistance(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c, false)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.CreateAssistanceRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+	reqDto.SessionToken = sessionTokenFromRequest(c)
+
+	respDto, err := h.svc.RequestAssistance(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to request assistance")
+	}
+
+	return responses.JSONSuccess(c, "assistance requested", respDto)
+}
+
+// HandleGetAssistanceRequests handles staff's http request to list assistance requests of
+// restaurant's orders that aren't resolved yet.
+func (h *AssistanceHandler) HandleGetAssistanceRequests(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetUnresolvedAssistanceRequests(
+		c.Request().Context(),
+		restaurantID,
+		user,
+	)
+	if err != nil {
+		return h.handleError(c, err, "failed to get assistance requests")
+	}
+
+	return responses.JSONSuccess(c, "assistance requests", respDto)
+}
+
+// HandleUpdateAssistanceRequest handles staff's http request to acknowledge or resolve an
+// assistance request.
+func (h *AssistanceHandler) HandleUpdateAssistanceRequest(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	requestID, err := hndl.GetUUUIDFromParams(c, assistanceRequestIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.UpdateAssistanceRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+	reqDto.RequestID = requestID
+
+	respDto, err := h.svc.UpdateAssistanceRequest(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to update assistance request")
+	}
+
+	return responses.JSONSuccess(c, "assistance request updated", respDto)
+}
+
+// HandleGetAssistanceReport handles manager's http request for times staff took to respond
+// to assistance requests in a period.
+func (h *AssistanceHandler) HandleGetAssistanceReport(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.AssistanceReportRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.GetAssistanceReport(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get assistance report")
+	}
+
+	return responses.JSONSuccess(c, "assistance report", respDto)
+}
+
+func (h *AssistanceHandler) handleError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrUserIsNotManager):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, services.ErrOrderConnectionUnauthorized),
+		errors.Is(err, sessions.ErrInvalidToken):
+		return responses.JSONError(c, err.Error(), err, http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTableSessionNotForOrder),
+		errors.Is(err, services.ErrUserIsNotRestaurantStaff):
+		return responses.JSONError(c, err.Error(), err, http.StatusForbidden)
+	case errors.Is(err, repository.ErrOrderDoesNotExist),
+		errors.Is(err, repository.ErrAssistanceRequestDoesNotExist):
+		return responses.JSONError(c, rootError(err).Error(), err, http.StatusNotFound)
+	case errors.Is(err, repository.ErrAssistanceRequestAlreadyHandled):
+		return responses.JSONError(c, rootError(err).Error(), err, http.StatusConflict)
+	case errors.Is(err, services.ErrAssistanceRequestedRecently):
+		return responses.JSONError(c, err.Error(), err, http.StatusTooManyRequests)
+	case errors.Is(err, services.ErrOrderFinalized):
+		return responses.JSONError(c, services.ErrOrderFinalized.Error(), err)
+	default:
+		return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	mock "golang-dining-ordering/test/mock/orders"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type assistanceHandlerTestSuite struct {
+	suite.Suite
+
+	handler *AssistanceHandler
+}
+
+func (suite *assistanceHandlerTestSuite) SetupTest() {
+	suite.handler = NewAssistanceHandler(newTestAssistanceService(events.NewMemoryPubSub(10)))
+}
+
+func TestAssistanceHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(assistanceHandlerTestSuite))
+}
+
+func (suite *assistanceHandlerTestSuite) newContext(
+	method, target, body string,
+	paramNames, paramValues []string,
+	userID uuid.UUID,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames(paramNames...)
+	c.SetParamValues(paramValues...)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+// newSession returns table session of a new guest of the order, orders with invalid id get
+// no session.
+func (suite *assistanceHandlerTestSuite) newSession(orderID string) string {
+	id, err := uuid.Parse(orderID)
+	if err != nil {
+		return ""
+	}
+
+	session, err := mock.NewTableSessions().Issue(id, uuid.New())
+	suite.Require().NoError(err)
+
+	return session.Token
+}
+
+func (suite *assistanceHandlerTestSuite) requestAssistance(
+	orderID, body, sessionToken string,
+	userID uuid.UUID,
+) (*dto.AssistanceRequestDto, *httptest.ResponseRecorder) {
+	c, rec := suite.newContext(
+		http.MethodPost,
+		"/",
+		body,
+		[]string{orderIDParamName},
+		[]string{orderID},
+		userID,
+	)
+
+	if sessionToken != "" {
+		c.Request().Header.Set(sessionTokenHeader, sessionToken)
+	}
+
+	err := suite.handler.HandleRequestAssistance(c)
+	if err != nil {
+		return nil, rec
+	}
+
+	var got struct {
+		Data *dto.AssistanceRequestDto `json:"data"`
+	}
+
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &got))
+
+	return got.Data, rec
+}
+
+func (suite *assistanceHandlerTestSuite) updateAssistanceRequest(
+	orderID, requestID, body string,
+	userID uuid.UUID,
+) *httptest.ResponseRecorder {
+	c, rec := suite.newContext(
+		http.MethodPatch,
+		"/",
+		body,
+		[]string{orderIDParamName, assistanceRequestIDParamName},
+		[]string{orderID, requestID},
+		userID,
+	)
+
+	_ = suite.handler.HandleUpdateAssistanceRequest(c)
+
+	return rec
+}
+
+func (suite *assistanceHandlerTestSuite) TestHandleRequestAssistance() {
+	tests := []struct {
+		desc       string
+		orderID    string
+		body       string
+		statusCode int
+	}{
+		{"call waiter", testOrderID.String(), `{"type": "call_waiter"}`, http.StatusOK},
+		{"request bill", testOrderID.String(), `{"type": "request_bill"}`, http.StatusOK},
+		{
+			"custom request",
+			testOrderID.String(),
+			`{"type": "custom", "message": "high chair please"}`,
+			http.StatusOK,
+		},
+		{"invalid order id", "invalid", `{"type": "call_waiter"}`, http.StatusBadRequest},
+		{"unknown type", testOrderID.String(), `{"type": "dance"}`, http.StatusBadRequest},
+		{
+			"custom without message",
+			testOrderID.String(),
+			`{"type": "custom"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"message of call waiter",
+			testOrderID.String(),
+			`{"type": "call_waiter", "message": "hurry"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"order does not exist",
+			uuid.New().String(),
+			`{"type": "call_waiter"}`,
+			http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			_, rec := suite.requestAssistance(
+				tt.orderID,
+				tt.body,
+				suite.newSession(tt.orderID),
+				uuid.Nil,
+			)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *assistanceHandlerTestSuite) TestHandleRequestAssistance_Authorization() {
+	session := suite.newSession(testOrderID.String())
+	callWaiter := `{"type": "call_waiter"}`
+
+	tests := []struct {
+		desc         string
+		sessionToken string
+		userID       uuid.UUID
+		statusCode   int
+	}{
+		{"neither session nor token", "", uuid.Nil, http.StatusUnauthorized},
+		{"invalid session", "invalid", uuid.Nil, http.StatusUnauthorized},
+		{
+			"session of another order",
+			suite.newSession(uuid.New().String()),
+			uuid.Nil,
+			http.StatusForbidden,
+		},
+		{"user is not staff", "", testUserFromAnotherRestaurantID, http.StatusForbidden},
+		{"guest with table session", session, uuid.Nil, http.StatusOK},
+		{"guest calls waiter again", session, uuid.Nil, http.StatusTooManyRequests},
+		{"another guest", suite.newSession(testOrderID.String()), uuid.Nil, http.StatusOK},
+		{"waiter", "", testUserID, http.StatusOK},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			_, rec := suite.requestAssistance(
+				testOrderID.String(),
+				callWaiter,
+				tt.sessionToken,
+				tt.userID,
+			)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *assistanceHandlerTestSuite) TestHandleUpdateAssistanceRequest() {
+	request, rec := suite.requestAssistance(
+		testOrderID.String(),
+		`{"type": "call_waiter"}`,
+		suite.newSession(testOrderID.String()),
+		uuid.Nil,
+	)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	requestID := request.ID.String()
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		requestID  string
+		body       string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{
+			"unknown status",
+			testOrderID.String(),
+			requestID,
+			`{"status": "open"}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"invalid request id",
+			testOrderID.String(),
+			"invalid",
+			`{"status": "acknowledged"}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not staff",
+			testOrderID.String(),
+			requestID,
+			`{"status": "acknowledged"}`,
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+		{
+			"request does not exist",
+			testOrderID.String(),
+			uuid.New().String(),
+			`{"status": "acknowledged"}`,
+			testUserID,
+			http.StatusNotFound,
+		},
+		{
+			"acknowledge",
+			testOrderID.String(),
+			requestID,
+			`{"status": "acknowledged"}`,
+			testUserID,
+			http.StatusOK,
+		},
+		{
+			"already acknowledged",
+			testOrderID.String(),
+			requestID,
+			`{"status": "acknowledged"}`,
+			testUserID,
+			http.StatusConflict,
+		},
+		{
+			"resolve",
+			testOrderID.String(),
+			requestID,
+			`{"status": "resolved"}`,
+			testUserID,
+			http.StatusOK,
+		},
+		{
+			"already resolved",
+			testOrderID.String(),
+			requestID,
+			`{"status": "resolved"}`,
+			testUserID,
+			http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			rec := suite.updateAssistanceRequest(tt.orderID, tt.requestID, tt.body, tt.userID)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *assistanceHandlerTestSuite) TestHandleGetAssistanceRequests() {
+	request, _ := suite.requestAssistance(
+		testOrderID.String(),
+		`{"type": "request_bill"}`,
+		suite.newSession(testOrderID.String()),
+		uuid.Nil,
+	)
+	suite.Require().NotNil(request)
+
+	c, rec := suite.newContext(
+		http.MethodGet,
+		"/",
+		"",
+		[]string{restaurantIDParamName},
+		[]string{testRestaurantID.String()},
+		testUserID,
+	)
+
+	err := suite.handler.HandleGetAssistanceRequests(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var got struct {
+		Data []*dto.AssistanceRequestDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+	suite.Require().Len(got.Data, 1)
+	suite.Equal(request.ID, got.Data[0].ID)
+	suite.Equal(db.OrdersAssistanceRequestTypeRequestBill, got.Data[0].Type)
+
+	c, rec = suite.newContext(
+		http.MethodGet,
+		"/",
+		"",
+		[]string{restaurantIDParamName},
+		[]string{testRestaurantID.String()},
+		testUserFromAnotherRestaurantID,
+	)
+
+	err = suite.handler.HandleGetAssistanceRequests(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *assistanceHandlerTestSuite) TestHandleGetAssistanceReport() {
+	tests := []struct {
+		desc       string
+		query      string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{
+			"report",
+			"?from=2025-12-01T00:00:00Z&to=2025-12-31T00:00:00Z",
+			testUserID,
+			http.StatusOK,
+		},
+		{"missing range", "", testUserID, http.StatusBadRequest},
+		{
+			"to before from",
+			"?from=2025-12-31T00:00:00Z&to=2025-12-01T00:00:00Z",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not manager",
+			"?from=2025-12-01T00:00:00Z&to=2025-12-31T00:00:00Z",
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			c, rec := suite.newContext(
+				http.MethodGet,
+				"/"+tt.query,
+				"",
+				[]string{restaurantIDParamName},
+				[]string{testRestaurantID.String()},
+				tt.userID,
+			)
+
+			_ = suite.handler.HandleGetAssistanceReport(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
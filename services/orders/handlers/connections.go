@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// errShuttingDown is returned when a client connects while the server is shutting down.
+var errShuttingDown = errors.New("server is shutting down")
+
+// restartCloseText tells clients closed on shutdown to connect again, to another instance.
+const restartCloseText = "server restarting, reconnect"
+
+// connections tracks clients connected to a handler and handlers serving them, so they are
+// closed and waited for when the server shuts down.
+type connections struct {
+	mu       sync.Mutex
+	clients  map[*client]struct{}
+	closing  bool
+	handlers sync.WaitGroup
+}
+
+func newConnections() *connections {
+	return &connections{
+		mu:       sync.Mutex{},
+		clients:  map[*client]struct{}{},
+		closing:  false,
+		handlers: sync.WaitGroup{},
+	}
+}
+
+// add starts tracking the client until its handler calls remove. Clients connecting after
+// shutdown started are closed right away.
+func (c *connections) add(client *client) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing {
+		client.closeWith(websocket.CloseServiceRestart, restartCloseText)
+
+		return errShuttingDown
+	}
+
+	c.clients[client] = struct{}{}
+	c.handlers.Add(1)
+
+	return nil
+}
+
+// remove stops tracking the client, once its handler is done with it.
+func (c *connections) remove(client *client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.clients[client]
+	if !ok {
+		return
+	}
+
+	delete(c.clients, client)
+	c.handlers.Done()
+}
+
+// shutdown closes every client with service restart code, so they reconnect, and waits for
+// their handlers to finish until the context is done.
+func (c *connections) shutdown(ctx context.Context) error {
+	c.mu.Lock()
+
+	c.closing = true
+
+	for client := range c.clients {
+		client.closeWith(websocket.CloseServiceRestart, restartCloseText)
+	}
+
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		c.handlers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for connections to close: %w", ctx.Err())
+	}
+}
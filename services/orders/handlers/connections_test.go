@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/services"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+
+	mock "golang-dining-ordering/test/mock/orders"
+)
+
+func (suite *websocketsHandlerTestSuite) TestShutdown_ClosesConnectionsWithRestartCode() {
+	svc := services.NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
+	handler := NewWebsocketHandler(
+		svc,
+		suite.handler.assistance,
+		events.NewMemoryPubSub(10),
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	e := echo.New()
+	e.GET("/orders/:order_id/ws", handler.HandleOrderWebsocket)
+	e.GET("/orders/:order_id/events", handler.HandleOrderEvents)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	query := "?session_token=" + suite.testSessionToken(testOrderID)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/" + testOrderID.String() +
+		"/ws" + query
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	suite.Require().NoError(err)
+
+	defer func() { _ = conn.Close() }()
+	defer func() { _ = resp.Body.Close() }()
+
+	req, err := http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		server.URL+"/orders/"+testOrderID.String()+"/events"+query,
+		nil,
+	)
+	suite.Require().NoError(err)
+
+	streamResp, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+
+	defer func() { _ = streamResp.Body.Close() }()
+
+	// both clients are connected once they got presence
+	_, _, err = conn.ReadMessage()
+	suite.Require().NoError(err)
+
+	stream := bufio.NewReader(streamResp.Body)
+	_, err = readEvent(stream)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	suite.Require().NoError(handler.Shutdown(ctx))
+
+	// websocket gets the close frame after messages queued before it
+	suite.Require().NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	for {
+		_, _, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+
+	var closeErr *websocket.CloseError
+	suite.Require().ErrorAs(err, &closeErr)
+	suite.Equal(websocket.CloseServiceRestart, closeErr.Code)
+	suite.Equal(restartCloseText, closeErr.Text)
+
+	// event stream ends after events queued before it
+	for {
+		_, err = readEvent(stream)
+		if err != nil {
+			break
+		}
+	}
+
+	suite.Require().ErrorIs(err, io.EOF)
+
+	// clients connecting during shutdown are told to reconnect too
+	conn, resp, err = websocket.DefaultDialer.Dial(wsURL, nil)
+	suite.Require().NoError(err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _, err = conn.ReadMessage()
+	suite.Require().ErrorAs(err, &closeErr)
+	suite.Equal(websocket.CloseServiceRestart, closeErr.Code)
+}
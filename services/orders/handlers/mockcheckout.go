@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const sessionIDParamName = "session_id"
+
+const (
+	mockCheckoutActionPay     = "pay"
+	mockCheckoutActionDecline = "decline"
+	mockCheckoutActionCancel  = "cancel"
+)
+
+//nolint:gochecknoglobals,lll
+var mockCheckoutTemplate = template.Must(template.New("mock-checkout").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Mock checkout</title>
+  <link href="https://cdn.jsdelivr.net/npm/bootstrap@5.3.3/dist/css/bootstrap.min.css" rel="stylesheet">
+</head>
+<body class="bg-light">
+  <div class="container py-5" style="max-width: 480px">
+    <div class="card shadow-sm">
+      <div class="card-body">
+        <h1 class="h4 card-title">Mock checkout</h1>
+        <p class="text-muted small">Local payment provider, no real money is charged.</p>
+        <p class="mb-1">Order <code>{{.OrderID}}</code></p>
+        <p class="display-6">{{.Amount}}</p>
+        {{if .Declined}}<div class="alert alert-danger">Your card was declined. Try again or cancel.</div>{{end}}
+        <form method="post" class="d-grid gap-2">
+          <button type="submit" name="action" value="pay" class="btn btn-success">Pay</button>
+          <button type="submit" name="action" value="decline" class="btn btn-outline-danger">Decline</button>
+          <button type="submit" name="action" value="cancel" class="btn btn-link">Cancel</button>
+        </form>
+      </div>
+    </div>
+  </div>
+</body>
+</html>
+`))
+
+type mockCheckoutPageData struct {
+	OrderID  string
+	Amount   string
+	Declined bool
+}
+
+// MockCheckoutHandler serves hosted checkout page of the mock payment provider.
+type MockCheckoutHandler struct {
+	provider *paymentproviders.MockPaymentProvider
+}
+
+// NewMockCheckoutHandler creates a new Handler for mock checkout page.
+func NewMockCheckoutHandler(provider *paymentproviders.MockPaymentProvider) *MockCheckoutHandler {
+	return &MockCheckoutHandler{
+		provider: provider,
+	}
+}
+
+// HandleGetCheckoutPage renders checkout page with pay, decline and cancel buttons.
+func (h *MockCheckoutHandler) HandleGetCheckoutPage(c echo.Context) error {
+	return h.renderCheckoutPage(c, false)
+}
+
+// HandleCheckoutAction handles button presses on the checkout page.
+func (h *MockCheckoutHandler) HandleCheckoutAction(c echo.Context) error {
+	sessionID := c.Param(sessionIDParamName)
+
+	var (
+		redirectURL string
+		err         error
+	)
+
+	switch c.FormValue("action") {
+	case mockCheckoutActionPay:
+		redirectURL, err = h.provider.PayCheckoutSession(c.Request().Context(), sessionID)
+	case mockCheckoutActionDecline:
+		err = h.provider.DeclineCheckoutSession(c.Request().Context(), sessionID)
+		if err == nil {
+			return h.renderCheckoutPage(c, true)
+		}
+	case mockCheckoutActionCancel:
+		redirectURL, err = h.provider.CancelCheckoutSession(c.Request().Context(), sessionID)
+	default:
+		return c.String(http.StatusBadRequest, "unknown checkout action")
+	}
+
+	if err != nil {
+		if errors.Is(err, paymentproviders.ErrCheckoutSessionNotFound) {
+			return c.String(http.StatusNotFound, err.Error())
+		}
+
+		return c.String(http.StatusBadGateway, "failed to complete checkout: "+err.Error())
+	}
+
+	return c.Redirect(http.StatusSeeOther, redirectURL)
+}
+
+func (h *MockCheckoutHandler) renderCheckoutPage(c echo.Context, declined bool) error {
+	s, err := h.provider.GetCheckoutSession(c.Param(sessionIDParamName))
+	if err != nil {
+		return c.String(http.StatusNotFound, err.Error())
+	}
+
+	var page strings.Builder
+
+	err = mockCheckoutTemplate.Execute(&page, &mockCheckoutPageData{
+		OrderID:  s.OrderID.String(),
+		Amount:   formatAmount(s.AmountInCents, s.Currency),
+		Declined: declined,
+	})
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "failed to render checkout page")
+	}
+
+	return c.HTML(http.StatusOK, page.String())
+}
+
+func formatAmount(amountInCents int, currency string) string {
+	//nolint:mnd
+	return fmt.Sprintf(
+		"%d.%02d %s",
+		amountInCents/100,
+		amountInCents%100,
+		strings.ToUpper(currency),
+	)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type mockCheckoutHandlerTestSuite struct {
+	suite.Suite
+
+	webhookServer *httptest.Server
+	webhookCalls  int
+	provider      *paymentproviders.MockPaymentProvider
+	handler       *MockCheckoutHandler
+}
+
+func (suite *mockCheckoutHandlerTestSuite) SetupTest() {
+	suite.webhookCalls = 0
+	suite.webhookServer = httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			suite.webhookCalls++
+
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	suite.provider = paymentproviders.NewMockPaymentProvider(
+		suite.webhookServer.URL,
+		"test-webhook-secret",
+	)
+	suite.handler = NewMockCheckoutHandler(suite.provider)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TearDownTest() {
+	suite.webhookServer.Close()
+}
+
+func TestMockCheckoutHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(mockCheckoutHandlerTestSuite))
+}
+
+func (suite *mockCheckoutHandlerTestSuite) createSession() string {
+	respDto, err := suite.provider.CreateCheckoutSession(
+		context.Background(),
+		&dto.CheckoutSessionRequestDto{
+			OrderDto: &dto.OrderDto{
+				ID:                testOrderID,
+				Currency:          testCurrency,
+				BalanceDueInCents: 1234,
+			},
+			SuccessURL: "https://fake-website.io?success=true",
+			CancelURL:  "https://fake-website.io?cancel=true",
+		},
+	)
+	suite.Require().NoError(err)
+
+	parts := strings.Split(respDto.URL, "/")
+
+	return parts[len(parts)-1]
+}
+
+func (suite *mockCheckoutHandlerTestSuite) newContext(
+	method string,
+	sessionID string,
+	action string,
+) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+
+	form := url.Values{}
+	form.Set("action", action)
+
+	req := httptest.NewRequest(method, "/", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames(sessionIDParamName)
+	c.SetParamValues(sessionID)
+
+	return c, rec
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleGetCheckoutPage_Success() {
+	c, rec := suite.newContext(http.MethodGet, suite.createSession(), "")
+
+	err := suite.handler.HandleGetCheckoutPage(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Contains(rec.Body.String(), "12.34 EUR")
+	suite.Contains(rec.Body.String(), testOrderID.String())
+	suite.Contains(rec.Body.String(), `value="pay"`)
+	suite.Contains(rec.Body.String(), `value="decline"`)
+	suite.Contains(rec.Body.String(), `value="cancel"`)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleGetCheckoutPage_NotFound() {
+	c, rec := suite.newContext(http.MethodGet, "mcs_unknown", "")
+
+	err := suite.handler.HandleGetCheckoutPage(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleCheckoutAction_Pay() {
+	c, rec := suite.newContext(http.MethodPost, suite.createSession(), "pay")
+
+	err := suite.handler.HandleCheckoutAction(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusSeeOther, rec.Code)
+	suite.Equal("https://fake-website.io?success=true", rec.Header().Get(echo.HeaderLocation))
+	suite.Equal(1, suite.webhookCalls)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleCheckoutAction_Decline() {
+	c, rec := suite.newContext(http.MethodPost, suite.createSession(), "decline")
+
+	err := suite.handler.HandleCheckoutAction(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Contains(rec.Body.String(), "declined")
+	suite.Equal(1, suite.webhookCalls)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleCheckoutAction_Cancel() {
+	c, rec := suite.newContext(http.MethodPost, suite.createSession(), "cancel")
+
+	err := suite.handler.HandleCheckoutAction(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusSeeOther, rec.Code)
+	suite.Equal("https://fake-website.io?cancel=true", rec.Header().Get(echo.HeaderLocation))
+	suite.Equal(1, suite.webhookCalls)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleCheckoutAction_SessionNotFound() {
+	c, rec := suite.newContext(http.MethodPost, "mcs_unknown", "pay")
+
+	err := suite.handler.HandleCheckoutAction(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *mockCheckoutHandlerTestSuite) TestHandleCheckoutAction_UnknownAction() {
+	c, rec := suite.newContext(http.MethodPost, suite.createSession(), "steal")
+
+	err := suite.handler.HandleCheckoutAction(c)
+	suite.Require().NoError(err)
+
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
@@ -8,6 +8,7 @@ import (
 	"golang-dining-ordering/pkg/validation"
 	hndl "golang-dining-ordering/services/management/handlers"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
 	"golang-dining-ordering/services/orders/services"
 	"net/http"
 
@@ -80,13 +81,18 @@ func (h *OrdersHandler) HandleAddItemToOrder(c echo.Context) error {
 		return responses.JSONError(c, err.Error(), err)
 	}
 
-	respDto, err := h.svc.AddItemToOrder(c.Request().Context(), orderID, reqDto.ItemID)
+	respDto, err := h.svc.AddItemToOrder(c.Request().Context(), orderID, reqDto.ItemID, nil)
 	if err != nil {
 		if errors.Is(err, services.ErrOrderIsNotOpen) ||
-			errors.Is(err, services.ErrItemDoesNotBelongToRestaurant) {
+			errors.Is(err, services.ErrItemDoesNotBelongToRestaurant) ||
+			errors.Is(err, repository.ErrMenuItemUnavailable) {
 			return responses.JSONError(c, err.Error(), err)
 		}
 
+		if errors.Is(err, repository.ErrMenuItemDoesNotExist) {
+			return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+		}
+
 		return responses.JSONError(
 			c,
 			"failed to add item to order",
@@ -118,6 +124,10 @@ func (h *OrdersHandler) HandleDeleteItemFromOrder(c echo.Context) error {
 			return responses.JSONError(c, err.Error(), err)
 		}
 
+		if errors.Is(err, repository.ErrOrderItemDoesNotExist) {
+			return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+		}
+
 		return responses.JSONError(
 			c,
 			"failed to delete item from order",
@@ -212,3 +222,78 @@ func (h *OrdersHandler) HandleRemoveWaiter(c echo.Context) error {
 
 	return responses.JSONSuccess(c, "waiter removed from order", nil)
 }
+
+// HandleApplyPromoCode handles http request to apply a promo code to an order.
+func (h *OrdersHandler) HandleApplyPromoCode(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.ApplyPromoCodeRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+
+	respDto, err := h.svc.ApplyPromoCode(c.Request().Context(), &reqDto)
+	if err != nil {
+		if errors.Is(err, repository.ErrPromoCodeDoesNotExist) {
+			return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+		}
+
+		if isPromoCodeRejected(err) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to apply promo code",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	return responses.JSONSuccess(c, "promo code applied to order", respDto)
+}
+
+// HandleRemovePromoCode handles http request to remove promo code from an order.
+func (h *OrdersHandler) HandleRemovePromoCode(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.RemovePromoCode(c.Request().Context(), orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderHasNoPromoCode) {
+			return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+		}
+
+		if errors.Is(err, services.ErrOrderIsNotOpen) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to remove promo code",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	return responses.JSONSuccess(c, "promo code removed from order", respDto)
+}
+
+// isPromoCodeRejected reports whether promo code can't be applied to the order, as opposed
+// to failing to apply it.
+func isPromoCodeRejected(err error) bool {
+	return errors.Is(err, repository.ErrPromoCodeDoesNotExist) ||
+		errors.Is(err, repository.ErrPromoCodeUsedUp) ||
+		errors.Is(err, services.ErrPromoCodeNotActive) ||
+		errors.Is(err, services.ErrPromoCodeMinSpendNotReached) ||
+		errors.Is(err, services.ErrOrderIsNotOpen)
+}
@@ -9,6 +9,7 @@ import (
 	"golang-dining-ordering/services/management/middleware"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
 	"golang-dining-ordering/services/orders/services"
 	"net/http"
 	"net/http/httptest"
@@ -30,7 +31,13 @@ type ordersHandlerTestSuite struct {
 
 func (suite *ordersHandlerTestSuite) SetupSuite() {
 	mockOrdersRepo := mock.NewMockOrdersRepo()
-	svc := services.NewOrdersService(mockOrdersRepo)
+	svc := services.NewOrdersService(
+		mockOrdersRepo,
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
 
 	suite.handler = NewOrdersHandler(svc)
 
@@ -42,6 +49,7 @@ func (suite *ordersHandlerTestSuite) SetupSuite() {
 		Currency:          testCurrency,
 		TipAmountInCents:  testAmount,
 		TotalPriceInCents: testAmount,
+		BalanceDueInCents: testAmount * 2,
 		UpdatedAt:         testDateTime,
 		Items: []*dto.OrderItemDto{
 			{
@@ -69,19 +77,24 @@ func (suite *ordersHandlerTestSuite) TestHandleGetCurrentTableOrder_Success() {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	want := responses.SuccessResponse{
-		Message: "fetched current order",
-		Data: dto.CurrentOrderDto{
-			ID: testOrderID,
-		},
-	}
-	wantJSON, err := json.Marshal(want)
+	err := suite.handler.HandleGetCurrentTableOrder(c)
 	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
 
-	err = suite.handler.HandleGetCurrentTableOrder(c)
+	var got struct {
+		Message string              `json:"message"`
+		Data    dto.CurrentOrderDto `json:"data"`
+	}
+
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &got))
+	suite.Equal("fetched current order", got.Message)
+	suite.Equal(testOrderID, got.Data.ID)
+	suite.NotNil(got.Data.SessionExpiresAt)
+
+	session, err := mock.NewTableSessions().Verify(got.Data.SessionToken)
 	suite.Require().NoError(err)
-	suite.JSONEq(string(wantJSON), rec.Body.String())
-	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal(testOrderID, session.OrderID)
+	suite.Equal(testTableID, session.TableID)
 }
 
 func (suite *ordersHandlerTestSuite) TestHandleGetCurrentTableOrder_Error() {
@@ -120,9 +133,12 @@ func (suite *ordersHandlerTestSuite) TestHandleGetOrder_Success() {
 	c.SetParamNames(orderIDParamName)
 	c.SetParamValues(testOrderID.String())
 
+	order := suite.order
+	order.TipPresets = []float64{5, 12.5}
+
 	want := responses.SuccessResponse{
 		Message: "fetched order details",
-		Data:    &suite.order,
+		Data:    &order,
 	}
 	wantJSON, err := json.Marshal(want)
 	suite.Require().NoError(err)
@@ -189,6 +205,7 @@ func (suite *ordersHandlerTestSuite) TestHandleAddItemToOrder_Success() {
 		PriceInCents: testAmount,
 	})
 	updatedOrder.TotalPriceInCents += 10
+	updatedOrder.BalanceDueInCents += 10
 	want := &responses.SuccessResponse{
 		Message: "item added to order",
 		Data:    &updatedOrder,
@@ -219,6 +236,12 @@ func (suite *ordersHandlerTestSuite) TestHandleAddItemToOrder_Error() {
 			testItemID.String(),
 			http.StatusBadRequest,
 		},
+		{
+			"cant add unavailable item",
+			testOrderID.String(),
+			testUnavailableItemID.String(),
+			http.StatusBadRequest,
+		},
 		{"service error", uuid.Max.String(), testItemID.String(), http.StatusInternalServerError},
 	}
 	for _, tt := range tests {
@@ -263,6 +286,7 @@ func (suite *ordersHandlerTestSuite) TestHandleDeleteItemFromOrder_Success() {
 	updatedOrder := suite.order
 	updatedOrder.Items = []*dto.OrderItemDto{}
 	updatedOrder.TotalPriceInCents = 0
+	updatedOrder.BalanceDueInCents = testAmount
 	want := &responses.SuccessResponse{
 		Message: "deleted item from order",
 		Data:    &updatedOrder,
@@ -517,3 +541,96 @@ func (suite *ordersHandlerTestSuite) TestHandleRemoveWaiter_Error() {
 		})
 	}
 }
+
+func (suite *ordersHandlerTestSuite) TestHandleApplyPromoCode() {
+	e := echo.New()
+
+	svc := services.NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
+	handler := NewOrdersHandler(svc)
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		code       string
+		statusCode int
+	}{
+		{"success", testOrderID.String(), "save50", http.StatusOK},
+		{"invalid id in params", "invalid-id", "SAVE50", http.StatusBadRequest},
+		{"invalid dto", testOrderID.String(), "", http.StatusBadRequest},
+		{"code does not exist", testOrderID.String(), "NOPE", http.StatusNotFound},
+		{"code expired", testOrderID.String(), "EXPIRED", http.StatusBadRequest},
+		{"code used up", testOrderID.String(), "USEDUP", http.StatusBadRequest},
+		{"completed order", testCompletedOrderID.String(), "SAVE50", http.StatusBadRequest},
+		{"service error", uuid.Max.String(), "SAVE50", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			body := fmt.Sprintf(`{"code": "%s"}`, tt.code)
+
+			req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(tt.orderID)
+
+			err := handler.HandleApplyPromoCode(c)
+			suite.Equal(tt.statusCode, rec.Code)
+
+			if tt.statusCode != http.StatusOK {
+				suite.Require().Error(err)
+
+				return
+			}
+
+			suite.Require().NoError(err)
+
+			var resp struct {
+				Data dto.OrderDto `json:"data"`
+			}
+
+			suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+			suite.Equal(testAmount/2, resp.Data.DiscountInCents)
+			suite.Equal(testAmount*2-testAmount/2, resp.Data.BalanceDueInCents)
+			suite.Require().Len(resp.Data.Discounts, 1)
+			suite.Equal("SAVE50", resp.Data.Discounts[0].Name)
+		})
+	}
+}
+
+func (suite *ordersHandlerTestSuite) TestHandleRemovePromoCode() {
+	e := echo.New()
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		statusCode int
+	}{
+		{"invalid id in params", "invalid-id", http.StatusBadRequest},
+		{"order has no promo code", testOrderID.String(), http.StatusNotFound},
+		{"completed order", testCompletedOrderID.String(), http.StatusBadRequest},
+		{"service error", uuid.Max.String(), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(tt.orderID)
+
+			err := suite.handler.HandleRemovePromoCode(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"golang-dining-ordering/pkg/responses"
 	"golang-dining-ordering/pkg/validation"
 	hndl "golang-dining-ordering/services/management/handlers"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
 	"golang-dining-ordering/services/orders/services"
+	"golang-dining-ordering/services/orders/sessions"
 	"io"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -40,6 +45,13 @@ func (h *PaymentsHandler) HandleCreateCheckout(c echo.Context) error {
 
 	respDto, err := h.svc.CreateCheckout(c.Request().Context(), orderID, &reqDto)
 	if err != nil {
+		if errors.Is(err, services.ErrOrderFinalized) ||
+			errors.Is(err, services.ErrOrderPriceIsZero) ||
+			errors.Is(err, services.ErrOrderAlreadyPaid) ||
+			errors.Is(err, paymentproviders.ErrProviderNotConfigured) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
 		return responses.JSONError(
 			c,
 			"failed to create checkout session",
@@ -51,19 +63,193 @@ func (h *PaymentsHandler) HandleCreateCheckout(c echo.Context) error {
 	return responses.JSONSuccess(c, "checkout session created", respDto)
 }
 
-// HandleWebhookSuccess handles webhook events for successful payments.
-func (h *PaymentsHandler) HandleWebhookSuccess(c echo.Context) error {
+// HandleRecordOfflinePayment handles waiter's http request to record cash or card terminal payment.
+func (h *PaymentsHandler) HandleRecordOfflinePayment(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.OfflinePaymentRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+
+	respDto, err := h.svc.RecordOfflinePayment(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		if errors.Is(err, services.ErrUserIsNotWaiter) {
+			return responses.JSONError(
+				c,
+				services.ErrUserIsNotWaiter.Error(),
+				err,
+				http.StatusForbidden,
+			)
+		}
+
+		if errors.Is(err, services.ErrOrderFinalized) ||
+			errors.Is(err, services.ErrOrderPriceIsZero) ||
+			errors.Is(err, services.ErrOrderAlreadyPaid) ||
+			errors.Is(err, services.ErrPaymentExceedsBalanceDue) ||
+			errors.Is(err, services.ErrChangeOnTerminalPayment) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to record payment",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	return responses.JSONSuccess(c, "payment recorded", respDto)
+}
+
+// HandleGetOrderPayments handles http request of guest with table session of the order, or of
+// restaurant's staff, to list order's payment attempts and payments.
+func (h *PaymentsHandler) HandleGetOrderPayments(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c, false)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetOrderPayments(
+		c.Request().Context(),
+		orderID,
+		sessionTokenFromRequest(c),
+		user,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOrderConnectionUnauthorized),
+			errors.Is(err, sessions.ErrInvalidToken):
+			return responses.JSONError(c, err.Error(), err, http.StatusUnauthorized)
+		case errors.Is(err, services.ErrTableSessionNotForOrder),
+			errors.Is(err, services.ErrUserIsNotRestaurantStaff):
+			return responses.JSONError(c, err.Error(), err, http.StatusForbidden)
+		case errors.Is(err, repository.ErrOrderDoesNotExist):
+			return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to fetch order payments",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	return responses.JSONSuccess(c, "fetched order payments", respDto)
+}
+
+// HandleRefund handles manager's http request to refund an order or specific order items.
+func (h *PaymentsHandler) HandleRefund(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.RefundRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+
+	respDto, err := h.svc.RefundOrder(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		if errors.Is(err, services.ErrUserIsNotManager) {
+			return responses.JSONError(
+				c,
+				services.ErrUserIsNotManager.Error(),
+				err,
+				http.StatusForbidden,
+			)
+		}
+
+		if errors.Is(err, services.ErrNothingToRefund) ||
+			errors.Is(err, services.ErrRefundExceedsAmountPaid) ||
+			errors.Is(err, services.ErrItemNotInOrder) ||
+			errors.Is(err, services.ErrItemAlreadyRefunded) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
+		return responses.JSONError(c, "failed to refund order", err, http.StatusInternalServerError)
+	}
+
+	return responses.JSONSuccess(c, "refund requested", respDto)
+}
+
+// HandleWebhook handles payment provider's webhook events. Ignored and already handled
+// events are acknowledged with 200, so provider doesn't retry them.
+func (h *PaymentsHandler) HandleWebhook(c echo.Context) error {
 	payload, err := io.ReadAll(c.Request().Body)
 	if err != nil {
 		return responses.JSONError(c, "failed to read request payload", err)
 	}
 
-	header := c.Request().Header
+	reqDto, err := webhookDtoFromRequest(c, payload)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.HandleWebhook(c.Request().Context(), reqDto)
+	if errors.Is(err, services.ErrWebhookAlreadyHandled) {
+		return responses.JSONSuccess(c, "webhook event already handled", nil)
+	}
 
-	respDto, err := h.svc.HandleWebhookSuccess(c.Request().Context(), payload, header)
 	if err != nil {
-		return responses.JSONError(c, "failed to verify payment", err)
+		return responses.JSONError(c, "failed to handle webhook event", err)
+	}
+
+	if respDto.Type == dto.ProviderEventIgnored {
+		return responses.JSONSuccess(c, "webhook event ignored", respDto)
+	}
+
+	return responses.JSONSuccess(c, "webhook event handled", respDto)
+}
+
+func webhookDtoFromRequest(c echo.Context, payload []byte) (*dto.WebhookDto, error) {
+	provider, err := getProviderFromParams(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// webhooks sent to the platform url have no restaurant
+	restaurantID := uuid.Nil
+
+	if c.Param(restaurantIDParamName) != "" {
+		restaurantID, err = hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return responses.JSONSuccess(c, "payment verified and saved", respDto)
+	return &dto.WebhookDto{
+		Provider:     provider,
+		RestaurantID: restaurantID,
+		Payload:      payload,
+		Header:       c.Request().Header,
+	}, nil
 }
@@ -2,15 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"golang-dining-ordering/pkg/responses"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
 	"golang-dining-ordering/services/orders/services"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,9 +41,12 @@ var (
 	testItemName          = "Test Menu Item"
 	testOrderItemID       = uuid.MustParse("aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa")
 	testItemID            = uuid.MustParse("bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb")
+	testUnavailableItemID = uuid.MustParse("bbbbbbbb-bbbb-4bbb-8bbb-cccccccccccc")
 	testCheckoutURL       = "http://fake-checkout-session.com/1"
 	testPaymentProvider   = db.OrdersPaymentProviderMock
 	testProviderPaymentID = "pi_123456"
+
+	testUserFromAnotherRestaurantID = uuid.MustParse("69696969-6969-6969-6969-696969696969")
 )
 
 type paymentsHandlerTestSuite struct {
@@ -50,8 +58,14 @@ type paymentsHandlerTestSuite struct {
 func (suite *paymentsHandlerTestSuite) SetupSuite() {
 	mockOrdersRepo := mock.NewMockOrdersRepo()
 	mockPaymentsRepo := mock.NewMockPaymentsRepo()
-	mockPaymentsProvider := mock.NewMockPaymentsProvider()
-	svc := services.NewPaymentsService(mockOrdersRepo, mockPaymentsRepo, mockPaymentsProvider)
+	mockProvidersRegistry := mock.NewMockProvidersRegistry()
+	svc := services.NewPaymentsService(
+		mockOrdersRepo,
+		mockPaymentsRepo,
+		mockProvidersRegistry,
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
 
 	suite.handler = NewPaymentsHandler(svc)
 }
@@ -72,16 +86,6 @@ func (suite *paymentsHandlerTestSuite) TestHandleCreateCheckout_Success() {
 	bodyBytes, err := json.Marshal(reqDto)
 	suite.Require().NoError(err)
 
-	expectedResponseDto := responses.SuccessResponse{
-		Message: "checkout session created",
-		Data: &dto.CheckoutSessionResponseDto{
-			URL:      testCheckoutURL,
-			Provider: testPaymentProvider,
-		},
-	}
-	expectedJSON, err := json.Marshal(expectedResponseDto)
-	suite.Require().NoError(err)
-
 	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
 
@@ -94,7 +98,99 @@ func (suite *paymentsHandlerTestSuite) TestHandleCreateCheckout_Success() {
 	err = suite.handler.HandleCreateCheckout(c)
 	suite.Require().NoError(err)
 	suite.Equal(http.StatusOK, rec.Code)
-	suite.JSONEq(string(expectedJSON), rec.Body.String())
+
+	var got struct {
+		Message string                         `json:"message"`
+		Data    dto.CheckoutSessionResponseDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+
+	suite.Equal("checkout session created", got.Message)
+	suite.Equal(testCheckoutURL, got.Data.URL)
+	suite.Equal(testPaymentProvider, got.Data.Provider)
+	suite.Equal(testAmount*2, got.Data.AmountInCents)
+	suite.NotEqual(uuid.Nil, got.Data.PaymentAttemptID)
+	suite.NotContains(rec.Body.String(), "session_id")
+}
+
+func (suite *paymentsHandlerTestSuite) TestHandleGetOrderPayments() {
+	session, err := mock.NewTableSessions().Issue(testOrderID, uuid.New())
+	suite.Require().NoError(err)
+
+	otherSession, err := mock.NewTableSessions().Issue(testCompletedOrderID, uuid.New())
+	suite.Require().NoError(err)
+
+	tests := []struct {
+		desc           string
+		orderID        string
+		sessionToken   string
+		userID         uuid.UUID
+		statusCode     int
+		wantRecordedBy bool
+	}{
+		{"fetched by staff", testOrderID.String(), "", testUserID, http.StatusOK, true},
+		{"fetched by guest", testOrderID.String(), session.Token, uuid.Nil, http.StatusOK, false},
+		{"invalid url params", "invalid-id", "", testUserID, http.StatusBadRequest, false},
+		{"no session or token", testOrderID.String(), "", uuid.Nil, http.StatusUnauthorized, false},
+		{
+			"session of another order",
+			testOrderID.String(),
+			otherSession.Token,
+			uuid.Nil,
+			http.StatusForbidden,
+			false,
+		},
+		{
+			"user is not staff",
+			testOrderID.String(),
+			"",
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+			false,
+		},
+		{
+			"service error",
+			uuid.Max.String(),
+			"",
+			testUserID,
+			http.StatusInternalServerError,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(sessionTokenHeader, tt.sessionToken)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(tt.orderID)
+
+			if tt.userID != uuid.Nil {
+				c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{UserID: tt.userID})
+			}
+
+			_ = suite.handler.HandleGetOrderPayments(c)
+			suite.Equal(tt.statusCode, rec.Code)
+
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Data dto.OrderPaymentsDto `json:"data"`
+			}
+
+			err := json.Unmarshal(rec.Body.Bytes(), &got)
+			suite.Require().NoError(err)
+			suite.Len(got.Data.Attempts, 1)
+			suite.Require().Len(got.Data.Payments, 1)
+			suite.Equal(tt.wantRecordedBy, got.Data.Payments[0].RecordedBy != nil)
+		})
+	}
 }
 
 func (suite *paymentsHandlerTestSuite) TestHandleCreateCheckout_Error() {
@@ -149,34 +245,88 @@ func (suite *paymentsHandlerTestSuite) TestHandleCreateCheckout_Error() {
 	}
 }
 
-func (suite *paymentsHandlerTestSuite) TestHandleWebhookSuccess_Success() {
-	e := echo.New()
+func (suite *paymentsHandlerTestSuite) TestHandleWebhook_Success() {
+	tests := []struct {
+		desc        string
+		payload     string
+		wantMessage string
+		wantType    dto.ProviderEventType
+	}{
+		{
+			"payment succeeded",
+			`{"type": "payment_succeeded"}`,
+			"webhook event handled",
+			dto.ProviderEventPaymentSucceeded,
+		},
+		{
+			"refund updated",
+			`{"type": "refund_updated"}`,
+			"webhook event handled",
+			dto.ProviderEventRefundUpdated,
+		},
+		{"ignored", `{"type": "ignored"}`, "webhook event ignored", dto.ProviderEventIgnored},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.payload))
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames(providerParamName, restaurantIDParamName)
+			c.SetParamValues(string(testPaymentProvider), testRestaurantID.String())
 
-	payload := []byte(`{"payment_secret": "secret"}`)
+			err := suite.handler.HandleWebhook(c)
+			suite.Require().NoError(err)
+			suite.Equal(http.StatusOK, rec.Code)
 
-	wantDto := responses.SuccessResponse{
-		Message: "payment verified and saved",
-		Data: &dto.PaymentDto{
-			ID:                testPaymentID,
-			OrderID:           testOrderID,
-			AmountInCents:     testAmount,
-			Provider:          testPaymentProvider,
-			ProviderPaymentID: testProviderPaymentID,
-			Currency:          testCurrency,
-		},
+			var got struct {
+				Message string                `json:"message"`
+				Data    *dto.ProviderEventDto `json:"data"`
+			}
+
+			err = json.Unmarshal(rec.Body.Bytes(), &got)
+			suite.Require().NoError(err)
+			suite.Equal(tt.wantMessage, got.Message)
+			suite.Equal(tt.wantType, got.Data.Type)
+		})
 	}
-	wantJSON, err := json.Marshal(wantDto)
-	suite.Require().NoError(err)
+}
+
+func (suite *paymentsHandlerTestSuite) TestHandleWebhook_AlreadyHandled() {
+	e := echo.New()
+
+	payload := []byte(`{"type": "payment_succeeded"}`)
 
 	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req = req.WithContext(
+		context.WithValue(req.Context(), mock.CtxWebhookEventProcessed, true),
+	)
 
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.SetParamNames(providerParamName, restaurantIDParamName)
+	c.SetParamValues(string(testPaymentProvider), testRestaurantID.String())
+
+	err := suite.handler.HandleWebhook(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Contains(rec.Body.String(), "webhook event already handled")
+}
+
+func (suite *paymentsHandlerTestSuite) TestHandleWebhook_Platform() {
+	payload := `{"type": "payment_succeeded"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
 
-	err = suite.handler.HandleWebhookSuccess(c)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames(providerParamName)
+	c.SetParamValues(string(testPaymentProvider))
+
+	err := suite.handler.HandleWebhook(c)
 	suite.Require().NoError(err)
 	suite.Equal(http.StatusOK, rec.Code)
-	suite.JSONEq(string(wantJSON), rec.Body.String())
+	suite.Contains(rec.Body.String(), "webhook event handled")
 }
 
 type errorReader struct{}
@@ -187,15 +337,28 @@ func (e errorReader) Read(_ []byte) (int, error) {
 	return 0, ErrRead
 }
 
-func (suite *paymentsHandlerTestSuite) TestHandleWebhookSuccess_Error() {
+func (suite *paymentsHandlerTestSuite) TestHandleWebhook_Error() {
 	e := echo.New()
 
+	payload := []byte(`{"type": "payment_succeeded"}`)
+	mockProvider := string(testPaymentProvider)
+
 	tests := []struct {
-		desc   string
-		reader io.Reader
+		desc         string
+		reader       io.Reader
+		provider     string
+		restaurantID string
 	}{
-		{"empty payload", nil},
-		{"invalid payload", errorReader{}},
+		{"empty payload", nil, mockProvider, testRestaurantID.String()},
+		{"invalid payload", errorReader{}, mockProvider, testRestaurantID.String()},
+		{"unknown provider", bytes.NewReader(payload), "paypal", testRestaurantID.String()},
+		{"invalid restaurant id", bytes.NewReader(payload), mockProvider, "invalid"},
+		{
+			"order of another restaurant",
+			bytes.NewReader(payload),
+			mockProvider,
+			testUserFromAnotherRestaurantID.String(),
+		},
 	}
 	for _, tt := range tests {
 		suite.T().Run(tt.desc, func(_ *testing.T) {
@@ -203,10 +366,218 @@ func (suite *paymentsHandlerTestSuite) TestHandleWebhookSuccess_Error() {
 
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
+			c.SetParamNames(providerParamName, restaurantIDParamName)
+			c.SetParamValues(tt.provider, tt.restaurantID)
 
-			err := suite.handler.HandleWebhookSuccess(c)
+			err := suite.handler.HandleWebhook(c)
 			suite.Require().Error(err)
 			suite.Equal(http.StatusBadRequest, rec.Code)
 		})
 	}
 }
+
+func (suite *paymentsHandlerTestSuite) TestHandleRefund_Success() {
+	e := echo.New()
+
+	body := []byte(`{"reason": "cold soup"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	c.SetParamNames(orderIDParamName)
+	c.SetParamValues(testOrderID.String())
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: testUserID,
+	})
+
+	err := suite.handler.HandleRefund(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var got struct {
+		Data []*dto.RefundDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+	suite.Require().Len(got.Data, 1)
+	suite.Equal(testAmount*2, got.Data[0].AmountInCents)
+	suite.Equal("cold soup", got.Data[0].Reason)
+}
+
+func (suite *paymentsHandlerTestSuite) TestHandleRefund_Error() {
+	e := echo.New()
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		userID     uuid.UUID
+		body       string
+		statusCode int
+	}{
+		{"invalid order id", "invalid-id", testUserID, `{"reason": "r"}`, http.StatusBadRequest},
+		{"missing user", testOrderID.String(), uuid.Nil, `{"reason": "r"}`, http.StatusBadRequest},
+		{"missing reason", testOrderID.String(), testUserID, `{}`, http.StatusBadRequest},
+		{
+			"amount and items both provided",
+			testOrderID.String(),
+			testUserID,
+			fmt.Sprintf(
+				`{"reason": "r", "amount_in_cents": 5, "order_item_ids": ["%s"]}`,
+				testOrderItemID,
+			),
+			http.StatusBadRequest,
+		},
+		{
+			"duplicate items",
+			testOrderID.String(),
+			testUserID,
+			fmt.Sprintf(
+				`{"reason": "r", "order_item_ids": ["%s", "%s"]}`,
+				testOrderItemID,
+				testOrderItemID,
+			),
+			http.StatusBadRequest,
+		},
+		{
+			"amount exceeds paid",
+			testOrderID.String(),
+			testUserID,
+			`{"reason": "r", "amount_in_cents": 1000}`,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not manager",
+			testOrderID.String(),
+			testUserFromAnotherRestaurantID,
+			`{"reason": "r"}`,
+			http.StatusForbidden,
+		},
+		{
+			"service error",
+			uuid.Max.String(),
+			testUserID,
+			`{"reason": "r"}`,
+			http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(tt.orderID)
+
+			c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+				UserID: tt.userID,
+			})
+
+			err := suite.handler.HandleRefund(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *paymentsHandlerTestSuite) TestHandleRecordOfflinePayment() {
+	e := echo.New()
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		userID     uuid.UUID
+		body       string
+		statusCode int
+	}{
+		{
+			"cash with change",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "cash", "amount_in_cents": 20, "tendered_in_cents": 50}`,
+			http.StatusOK,
+		},
+		{
+			"terminal",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "terminal", "amount_in_cents": 10}`,
+			http.StatusOK,
+		},
+		{
+			"invalid order id",
+			"invalid-id",
+			testUserID,
+			`{"method": "cash", "amount_in_cents": 10}`,
+			http.StatusBadRequest,
+		},
+		{
+			"online provider",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "stripe", "amount_in_cents": 10}`,
+			http.StatusBadRequest,
+		},
+		{
+			"tendered less than amount",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "cash", "amount_in_cents": 10, "tendered_in_cents": 5}`,
+			http.StatusBadRequest,
+		},
+		{
+			"amount exceeds balance due",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "cash", "amount_in_cents": 1000}`,
+			http.StatusBadRequest,
+		},
+		{
+			"change on terminal payment",
+			testOrderID.String(),
+			testUserID,
+			`{"method": "terminal", "amount_in_cents": 10, "tendered_in_cents": 15}`,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not waiter",
+			testOrderID.String(),
+			testUserFromAnotherRestaurantID,
+			`{"method": "cash", "amount_in_cents": 10}`,
+			http.StatusForbidden,
+		},
+		{
+			"service error",
+			uuid.Max.String(),
+			testUserID,
+			`{"method": "cash", "amount_in_cents": 10}`,
+			http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(tt.orderID)
+
+			c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+				UserID: tt.userID,
+			})
+
+			_ = suite.handler.HandleRecordOfflinePayment(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"cmp"
+	"golang-dining-ordering/services/orders/dto"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// presenceHeartbeatPeriod is how often instances announce their connections again.
+	presenceHeartbeatPeriod = 30 * time.Second
+	// presenceTTL is how long a connection is present without being announced, connections of
+	// an instance that crashed without telling they closed are dropped after it.
+	presenceTTL = 3 * presenceHeartbeatPeriod
+)
+
+// presence tracks participants connected to orders. It's built from presence events of
+// every instance, participant with several connections, e.g. in two browser tabs, is
+// present until the last one closes or stops being announced.
+type presence struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	orders map[uuid.UUID]map[uuid.UUID]*presentParticipant
+}
+
+type presentParticipant struct {
+	participant *dto.ParticipantDto
+	// conns holds when each connection of the participant was last announced
+	conns map[uuid.UUID]time.Time
+}
+
+func newPresence(ttl time.Duration) *presence {
+	return &presence{
+		mu:     sync.Mutex{},
+		ttl:    ttl,
+		orders: map[uuid.UUID]map[uuid.UUID]*presentParticipant{},
+	}
+}
+
+// join counts participant's connection to the order as announced now, connections already
+// counted are refreshed. It reports whether the participant wasn't present before.
+func (p *presence) join(
+	orderID uuid.UUID,
+	participant *dto.ParticipantDto,
+	connectionID uuid.UUID,
+	now time.Time,
+) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	participants, ok := p.orders[orderID]
+	if !ok {
+		participants = map[uuid.UUID]*presentParticipant{}
+		p.orders[orderID] = participants
+	}
+
+	present, ok := participants[participant.ID]
+	if !ok {
+		participants[participant.ID] = &presentParticipant{
+			participant: participant,
+			conns:       map[uuid.UUID]time.Time{connectionID: now},
+		}
+
+		return true
+	}
+
+	// participant could have changed display name when connecting again
+	present.participant = participant
+	present.conns[connectionID] = now
+
+	return false
+}
+
+// leave counts closing of participant's connection to the order, it reports whether it was
+// participant's last connection.
+func (p *presence) leave(orderID, participantID, connectionID uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	present, ok := p.orders[orderID][participantID]
+	if !ok {
+		return false
+	}
+
+	_, ok = present.conns[connectionID]
+	if !ok {
+		return false
+	}
+
+	delete(present.conns, connectionID)
+
+	if len(present.conns) > 0 {
+		return false
+	}
+
+	p.remove(orderID, participantID)
+
+	return true
+}
+
+// expire drops connections that weren't announced within ttl before now, it returns
+// participants left without connections by orders.
+func (p *presence) expire(now time.Time) map[uuid.UUID][]*dto.ParticipantDto {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expired := map[uuid.UUID][]*dto.ParticipantDto{}
+
+	for orderID, participants := range p.orders {
+		for participantID, present := range participants {
+			for connectionID, announcedAt := range present.conns {
+				if now.Sub(announcedAt) > p.ttl {
+					delete(present.conns, connectionID)
+				}
+			}
+
+			if len(present.conns) == 0 {
+				p.remove(orderID, participantID)
+				expired[orderID] = append(expired[orderID], present.participant)
+			}
+		}
+	}
+
+	return expired
+}
+
+// remove drops the participant from the order, the caller holds the lock.
+func (p *presence) remove(orderID, participantID uuid.UUID) {
+	delete(p.orders[orderID], participantID)
+
+	if len(p.orders[orderID]) == 0 {
+		delete(p.orders, orderID)
+	}
+}
+
+// participants returns participants present at the order, sorted by display name.
+func (p *presence) participants(orderID uuid.UUID) []*dto.ParticipantDto {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	participants := make([]*dto.ParticipantDto, 0, len(p.orders[orderID]))
+
+	for _, present := range p.orders[orderID] {
+		participants = append(participants, present.participant)
+	}
+
+	slices.SortFunc(participants, func(a, b *dto.ParticipantDto) int {
+		return cmp.Or(
+			cmp.Compare(a.DisplayName, b.DisplayName),
+			cmp.Compare(a.ID.String(), b.ID.String()),
+		)
+	})
+
+	return participants
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const printerIDParamName = "printer_id"
+
+// PrintingHandler handles restaurants' printers, kitchen tickets and printed receipts HTTP
+// requests.
+type PrintingHandler struct {
+	svc services.PrintingService
+}
+
+// NewPrintingHandler creates a new Handler for printing.
+func NewPrintingHandler(svc services.PrintingService) *PrintingHandler {
+	return &PrintingHandler{
+		svc: svc,
+	}
+}
+
+// HandleGetPrinters handles manager's http request to list restaurant's printers.
+func (h *PrintingHandler) HandleGetPrinters(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetPrinters(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get printers")
+	}
+
+	return responses.JSONSuccess(c, "printers", respDto)
+}
+
+// HandleCreatePrinter handles manager's http request to add a printer.
+func (h *PrintingHandler) HandleCreatePrinter(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.CreatePrinterRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.CreatePrinter(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to create printer")
+	}
+
+	return responses.JSONSuccess(c, "printer created", respDto)
+}
+
+// HandleDeletePrinter handles manager's http request to delete a printer.
+func (h *PrintingHandler) HandleDeletePrinter(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	printerID, err := hndl.GetUUUIDFromParams(c, printerIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.DeletePrinter(c.Request().Context(), restaurantID, printerID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to delete printer")
+	}
+
+	return responses.JSONSuccess(c, "printer deleted", nil)
+}
+
+// HandlePrintKitchenTickets handles waiter's http request to send order's new items to the
+// kitchen printers.
+func (h *PrintingHandler) HandlePrintKitchenTickets(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.PrintKitchenTickets(c.Request().Context(), orderID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to print kitchen tickets")
+	}
+
+	return responses.JSONSuccess(c, "kitchen tickets printed", respDto)
+}
+
+// HandlePrintReceipt handles waiter's http request to print receipt of a completed order.
+func (h *PrintingHandler) HandlePrintReceipt(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.PrintReceipt(c.Request().Context(), orderID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to print receipt")
+	}
+
+	return responses.JSONSuccess(c, "receipt printed", nil)
+}
+
+func (h *PrintingHandler) handleError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrUserIsNotManager):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, services.ErrUserIsNotWaiter):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotWaiter.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, repository.ErrPrinterDoesNotExist),
+		errors.Is(err, repository.ErrOrderDoesNotExist):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+	case errors.Is(err, repository.ErrPrinterAlreadyExists),
+		errors.Is(err, services.ErrNothingToPrint),
+		errors.Is(err, services.ErrOrderIsCancelled),
+		errors.Is(err, services.ErrOrderIsNotCompleted):
+		return responses.JSONError(c, err.Error(), err, http.StatusConflict)
+	case errors.Is(err, services.ErrNoKitchenPrinter),
+		errors.Is(err, services.ErrNoReceiptPrinter):
+		return responses.JSONError(c, err.Error(), err, http.StatusUnprocessableEntity)
+	case errors.Is(err, services.ErrPrinterUnavailable):
+		return responses.JSONError(c, err.Error(), err, http.StatusBadGateway)
+	default:
+		return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+	}
+}
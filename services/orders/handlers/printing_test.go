@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/services"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock "golang-dining-ordering/test/mock/orders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type printingHandlerTestSuite struct {
+	suite.Suite
+
+	addr *net.TCPAddr
+}
+
+// SetupSuite starts a stand-in for restaurant's networked printers, which accepts and
+// discards print jobs.
+func (suite *printingHandlerTestSuite) SetupSuite() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_, _ = io.Copy(io.Discard, conn)
+			_ = conn.Close()
+		}
+	}()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	suite.Require().True(ok)
+
+	suite.addr = addr
+}
+
+func TestPrintingHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(printingHandlerTestSuite))
+}
+
+func (suite *printingHandlerTestSuite) newHandler() *PrintingHandler {
+	return NewPrintingHandler(services.NewPrintingService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPrintersRepo(suite.addr.IP.String(), suite.addr.Port),
+		services.NewReceiptsService(
+			mock.NewMockOrdersRepo(),
+			mock.NewMockPaymentsRepo(),
+			mock.NewMockReceiptsRepo(),
+			nil,
+			mock.NewTableSessions(),
+		),
+		time.Second,
+	))
+}
+
+func (suite *printingHandlerTestSuite) newContext(
+	method, body string,
+	paramNames, paramValues []string,
+	userID uuid.UUID,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames(paramNames...)
+	c.SetParamValues(paramValues...)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+func (suite *printingHandlerTestSuite) TestHandleCreatePrinter() {
+	tests := []struct {
+		desc       string
+		body       string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{
+			"created",
+			`{"name": "Grill", "host": "192.168.1.50", "category_ids": []}`,
+			testUserID,
+			http.StatusOK,
+		},
+		{
+			"invalid host",
+			`{"name": "Grill", "host": "not a host"}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"invalid port",
+			`{"name": "Grill", "host": "pos", "port": 70000}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{"duplicate name", `{"name": "Kitchen", "host": "pos"}`, testUserID, http.StatusConflict},
+		{
+			"user is not manager",
+			`{"name": "Grill", "host": "pos"}`,
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				tt.body,
+				[]string{restaurantIDParamName},
+				[]string{testRestaurantID.String()},
+				tt.userID,
+			)
+
+			_ = suite.newHandler().HandleCreatePrinter(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *printingHandlerTestSuite) TestHandleGetAndDeletePrinter() {
+	handler := suite.newHandler()
+
+	c, rec := suite.newContext(
+		http.MethodGet,
+		"",
+		[]string{restaurantIDParamName},
+		[]string{testRestaurantID.String()},
+		testUserID,
+	)
+
+	err := handler.HandleGetPrinters(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data []*dto.PrinterDto `json:"data"`
+	}
+
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &resp))
+	suite.Require().Len(resp.Data, 2)
+
+	for _, statusCode := range []int{http.StatusOK, http.StatusNotFound} {
+		c, rec = suite.newContext(
+			http.MethodDelete,
+			"",
+			[]string{restaurantIDParamName, printerIDParamName},
+			[]string{testRestaurantID.String(), resp.Data[0].ID.String()},
+			testUserID,
+		)
+
+		_ = handler.HandleDeletePrinter(c)
+		suite.Equal(statusCode, rec.Code)
+	}
+}
+
+func (suite *printingHandlerTestSuite) TestHandlePrintKitchenTickets() {
+	handler := suite.newHandler()
+
+	tests := []struct {
+		desc       string
+		orderID    string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{"invalid order id", "invalid", testUserID, http.StatusBadRequest},
+		{
+			"user is not waiter",
+			testOrderID.String(),
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+		{"printed", testOrderID.String(), testUserID, http.StatusOK},
+		{"nothing to print", testOrderID.String(), testUserID, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				"",
+				[]string{orderIDParamName},
+				[]string{tt.orderID},
+				tt.userID,
+			)
+
+			_ = handler.HandlePrintKitchenTickets(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *printingHandlerTestSuite) TestHandlePrintReceipt() {
+	tests := []struct {
+		desc       string
+		orderID    string
+		statusCode int
+	}{
+		{"printed", testCompletedOrderID.String(), http.StatusOK},
+		{"order not completed", testOrderID.String(), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				"",
+				[]string{orderIDParamName},
+				[]string{tt.orderID},
+				testUserID,
+			)
+
+			_ = suite.newHandler().HandlePrintReceipt(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
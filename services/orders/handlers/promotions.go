@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	promoCodeIDParamName     = "promo_code_id"
+	promotionRuleIDParamName = "rule_id"
+)
+
+// PromotionsHandler handles restaurants' promo codes and automatic promotions HTTP requests.
+type PromotionsHandler struct {
+	svc services.PromotionsService
+}
+
+// NewPromotionsHandler creates a new Handler for restaurants' promotions.
+func NewPromotionsHandler(svc services.PromotionsService) *PromotionsHandler {
+	return &PromotionsHandler{
+		svc: svc,
+	}
+}
+
+// HandleGetPromoCodes handles manager's http request to list restaurant's promo codes.
+func (h *PromotionsHandler) HandleGetPromoCodes(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetPromoCodes(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get promo codes")
+	}
+
+	return responses.JSONSuccess(c, "promo codes", respDto)
+}
+
+// HandleCreatePromoCode handles manager's http request to create a promo code.
+func (h *PromotionsHandler) HandleCreatePromoCode(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.CreatePromoCodeRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.CreatePromoCode(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to create promo code")
+	}
+
+	return responses.JSONSuccess(c, "promo code created", respDto)
+}
+
+// HandleDeletePromoCode handles manager's http request to delete a promo code.
+func (h *PromotionsHandler) HandleDeletePromoCode(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	promoCodeID, err := hndl.GetUUUIDFromParams(c, promoCodeIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.DeletePromoCode(c.Request().Context(), restaurantID, promoCodeID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to delete promo code")
+	}
+
+	return responses.JSONSuccess(c, "promo code deleted", nil)
+}
+
+// HandleGetPromotionRules handles manager's http request to list restaurant's automatic
+// promotions.
+func (h *PromotionsHandler) HandleGetPromotionRules(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetPromotionRules(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get promotion rules")
+	}
+
+	return responses.JSONSuccess(c, "promotion rules", respDto)
+}
+
+// HandleCreatePromotionRule handles manager's http request to create an automatic promotion.
+func (h *PromotionsHandler) HandleCreatePromotionRule(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.CreatePromotionRuleRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.CreatePromotionRule(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to create promotion rule")
+	}
+
+	return responses.JSONSuccess(c, "promotion rule created", respDto)
+}
+
+// HandleDeletePromotionRule handles manager's http request to delete an automatic promotion.
+func (h *PromotionsHandler) HandleDeletePromotionRule(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	ruleID, err := hndl.GetUUUIDFromParams(c, promotionRuleIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.DeletePromotionRule(c.Request().Context(), restaurantID, ruleID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to delete promotion rule")
+	}
+
+	return responses.JSONSuccess(c, "promotion rule deleted", nil)
+}
+
+func (h *PromotionsHandler) handleError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrUserIsNotManager):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, repository.ErrPromoCodeDoesNotExist),
+		errors.Is(err, repository.ErrPromotionRuleDoesNotExist):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+	case errors.Is(err, repository.ErrPromoCodeAlreadyExists):
+		return responses.JSONError(c, err.Error(), err, http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidDiscountValue),
+		errors.Is(err, services.ErrInvalidValidityWindow):
+		return responses.JSONError(c, err.Error(), err)
+	default:
+		return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock "golang-dining-ordering/test/mock/orders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+//nolint:gochecknoglobals
+var (
+	testPromoCodeID     = uuid.MustParse("c0c0c0c0-c0c0-4c0c-8c0c-c0c0c0c0c0c0")
+	testPromotionRuleID = uuid.MustParse("d0d0d0d0-d0d0-4d0d-8d0d-d0d0d0d0d0d0")
+)
+
+type promotionsHandlerTestSuite struct {
+	suite.Suite
+
+	handler *PromotionsHandler
+}
+
+func (suite *promotionsHandlerTestSuite) SetupSuite() {
+	svc := services.NewPromotionsService(mock.NewMockOrdersRepo(), mock.NewMockPromotionsRepo())
+
+	suite.handler = NewPromotionsHandler(svc)
+}
+
+func TestPromotionsHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(promotionsHandlerTestSuite))
+}
+
+func (suite *promotionsHandlerTestSuite) newContext(
+	method, body string,
+	userID uuid.UUID,
+	params ...string,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	names := []string{restaurantIDParamName, promoCodeIDParamName, promotionRuleIDParamName}
+	c.SetParamNames(names[:len(params)]...)
+	c.SetParamValues(params...)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleGetPromoCodes() {
+	c, rec := suite.newContext(http.MethodGet, "", testUserID, testRestaurantID.String())
+
+	err := suite.handler.HandleGetPromoCodes(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	c, rec = suite.newContext(
+		http.MethodGet,
+		"",
+		testUserFromAnotherRestaurantID,
+		testRestaurantID.String(),
+	)
+
+	err = suite.handler.HandleGetPromoCodes(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleCreatePromoCode() {
+	tests := []struct {
+		desc       string
+		body       string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{
+			"success",
+			`{"code": "summer25", "discount_type": "percentage", "discount_value": 25}`,
+			testUserID,
+			http.StatusOK,
+		},
+		{
+			"invalid dto",
+			`{"code": "no spaces", "discount_type": "percentage", "discount_value": 25}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"percentage over 100",
+			`{"code": "FREE", "discount_type": "percentage", "discount_value": 150}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"validity ends before it starts",
+			`{"code": "FREE", "discount_type": "fixed", "discount_value": 500,
+			"valid_from": "2025-12-05T19:00:00Z", "valid_until": "2025-12-04T19:00:00Z"}`,
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"code already exists",
+			`{"code": "SAVE50", "discount_type": "percentage", "discount_value": 50}`,
+			testUserID,
+			http.StatusConflict,
+		},
+		{
+			"user is not manager",
+			`{"code": "summer25", "discount_type": "percentage", "discount_value": 25}`,
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				tt.body,
+				tt.userID,
+				testRestaurantID.String(),
+			)
+
+			err := suite.handler.HandleCreatePromoCode(c)
+			suite.Equal(tt.statusCode, rec.Code)
+
+			if tt.statusCode == http.StatusOK {
+				suite.Require().NoError(err)
+				suite.Contains(rec.Body.String(), `"code":"SUMMER25"`)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleDeletePromoCode() {
+	c, rec := suite.newContext(
+		http.MethodDelete,
+		"",
+		testUserID,
+		testRestaurantID.String(),
+		testPromoCodeID.String(),
+	)
+
+	err := suite.handler.HandleDeletePromoCode(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	c, rec = suite.newContext(
+		http.MethodDelete,
+		"",
+		testUserID,
+		testRestaurantID.String(),
+		uuid.NewString(),
+	)
+
+	err = suite.handler.HandleDeletePromoCode(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleGetPromotionRules() {
+	c, rec := suite.newContext(http.MethodGet, "", testUserID, testRestaurantID.String())
+
+	err := suite.handler.HandleGetPromotionRules(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Contains(rec.Body.String(), `"rule_type":"buy_x_get_y"`)
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleCreatePromotionRule() {
+	tests := []struct {
+		desc       string
+		body       string
+		statusCode int
+	}{
+		{
+			"success",
+			`{"name": "2-for-1 drinks", "rule_type": "buy_x_get_y", "buy_quantity": 1,
+			"free_quantity": 1, "starts_at": "17:00", "ends_at": "19:00"}`,
+			http.StatusOK,
+		},
+		{
+			"missing quantities",
+			`{"name": "2-for-1 drinks", "rule_type": "buy_x_get_y",
+			"starts_at": "17:00", "ends_at": "19:00"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"invalid time",
+			`{"name": "Drinks 10% off", "rule_type": "percentage", "percent_off": 10,
+			"starts_at": "5pm", "ends_at": "19:00"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"invalid timezone",
+			`{"name": "Drinks 10% off", "rule_type": "percentage", "percent_off": 10,
+			"starts_at": "17:00", "ends_at": "19:00", "timezone": "Mars/Olympus"}`,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				tt.body,
+				testUserID,
+				testRestaurantID.String(),
+			)
+
+			err := suite.handler.HandleCreatePromotionRule(c)
+			suite.Equal(tt.statusCode, rec.Code)
+
+			if tt.statusCode == http.StatusOK {
+				suite.Require().NoError(err)
+				suite.Contains(rec.Body.String(), `"timezone":"UTC"`)
+			} else {
+				suite.Require().Error(err)
+			}
+		})
+	}
+}
+
+func (suite *promotionsHandlerTestSuite) TestHandleDeletePromotionRule() {
+	c, rec := suite.newContext(
+		http.MethodDelete,
+		"",
+		testUserID,
+		testRestaurantID.String(),
+		"",
+		testPromotionRuleID.String(),
+	)
+
+	err := suite.handler.HandleDeletePromotionRule(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	c, rec = suite.newContext(
+		http.MethodDelete,
+		"",
+		testUserFromAnotherRestaurantID,
+		testRestaurantID.String(),
+		"",
+		testPromotionRuleID.String(),
+	)
+
+	err = suite.handler.HandleDeletePromotionRule(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	restaurantIDParamName = "restaurant_id"
+	providerParamName     = "provider"
+)
+
+var errUnknownProvider = errors.New("unknown payment provider")
+
+// PaymentProvidersHandler handles restaurants' payment provider settings HTTP requests.
+type PaymentProvidersHandler struct {
+	svc services.PaymentProvidersService
+}
+
+// NewPaymentProvidersHandler creates a new Handler for restaurants' payment providers.
+func NewPaymentProvidersHandler(svc services.PaymentProvidersService) *PaymentProvidersHandler {
+	return &PaymentProvidersHandler{
+		svc: svc,
+	}
+}
+
+// HandleGetPaymentProviders handles manager's http request to list restaurant's payment providers.
+func (h *PaymentProvidersHandler) HandleGetPaymentProviders(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetRestaurantPaymentProviders(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get payment providers")
+	}
+
+	return responses.JSONSuccess(c, "payment providers", respDto)
+}
+
+// HandleSetPaymentProvider handles manager's http request to configure restaurant's payment provider.
+func (h *PaymentProvidersHandler) HandleSetPaymentProvider(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	provider, err := getProviderFromParams(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.SetPaymentProviderRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+	reqDto.Provider = provider
+
+	respDto, err := h.svc.SetRestaurantPaymentProvider(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to save payment provider")
+	}
+
+	return responses.JSONSuccess(c, "payment provider saved", respDto)
+}
+
+// HandleDeletePaymentProvider handles manager's http request to remove restaurant's payment provider.
+func (h *PaymentProvidersHandler) HandleDeletePaymentProvider(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	provider, err := getProviderFromParams(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.DeleteRestaurantPaymentProvider(
+		c.Request().Context(),
+		restaurantID,
+		provider,
+		user,
+	)
+	if err != nil {
+		return h.handleError(c, err, "failed to delete payment provider")
+	}
+
+	return responses.JSONSuccess(c, "payment provider deleted", nil)
+}
+
+func (h *PaymentProvidersHandler) handleError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrUserIsNotManager):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, repository.ErrPaymentProviderDoesNotExist):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+	case errors.Is(err, paymentproviders.ErrMissingCredentials),
+		errors.Is(err, paymentproviders.ErrProviderNotSupported),
+		errors.Is(err, paymentproviders.ErrInvalidKlixPublicKey):
+		return responses.JSONError(c, err.Error(), err)
+	default:
+		return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+	}
+}
+
+func getProviderFromParams(c echo.Context) (db.OrdersPaymentProvider, error) {
+	provider := db.OrdersPaymentProvider(c.Param(providerParamName))
+
+	switch provider {
+	case db.OrdersPaymentProviderStripe,
+		db.OrdersPaymentProviderKlix,
+		db.OrdersPaymentProviderMock:
+		return provider, nil
+	case db.OrdersPaymentProviderCash,
+		db.OrdersPaymentProviderTerminal:
+		// offline payments are recorded by waiters, they have no webhooks or credentials
+		return "", responses.JSONError(
+			c,
+			"invalid payment provider in url",
+			fmt.Errorf("%w: %s", errUnknownProvider, provider),
+		)
+	default:
+		return "", responses.JSONError(
+			c,
+			"invalid payment provider in url",
+			fmt.Errorf("%w: %s", errUnknownProvider, provider),
+		)
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock "golang-dining-ordering/test/mock/orders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type paymentProvidersHandlerTestSuite struct {
+	suite.Suite
+
+	handler *PaymentProvidersHandler
+}
+
+func (suite *paymentProvidersHandlerTestSuite) SetupSuite() {
+	svc := services.NewPaymentProvidersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentProvidersRepo(),
+		mock.NewMockProvidersRegistry(),
+	)
+
+	suite.handler = NewPaymentProvidersHandler(svc)
+}
+
+func TestPaymentProvidersHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(paymentProvidersHandlerTestSuite))
+}
+
+func (suite *paymentProvidersHandlerTestSuite) newContext(
+	body, restaurantID, provider string,
+	userID uuid.UUID,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames(restaurantIDParamName, providerParamName)
+	c.SetParamValues(restaurantID, provider)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+func (suite *paymentProvidersHandlerTestSuite) TestHandleGetPaymentProviders_Success() {
+	c, rec := suite.newContext("", testRestaurantID.String(), "", testUserID)
+
+	err := suite.handler.HandleGetPaymentProviders(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var got struct {
+		Data []*dto.RestaurantPaymentProviderDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+	suite.Require().Len(got.Data, 1)
+	suite.Equal(db.OrdersPaymentProviderStripe, got.Data[0].Provider)
+	suite.NotContains(rec.Body.String(), "secret_key")
+}
+
+func (suite *paymentProvidersHandlerTestSuite) TestHandleGetPaymentProviders_Error() {
+	c, rec := suite.newContext("", testRestaurantID.String(), "", testUserFromAnotherRestaurantID)
+
+	err := suite.handler.HandleGetPaymentProviders(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *paymentProvidersHandlerTestSuite) TestHandleSetPaymentProvider_Success() {
+	body := `{
+		"credentials": {"secret_key": "sk_test", "webhook_secret": "whsec"},
+		"is_default": true
+	}`
+
+	c, rec := suite.newContext(body, testRestaurantID.String(), "stripe", testUserID)
+
+	err := suite.handler.HandleSetPaymentProvider(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.NotContains(rec.Body.String(), "sk_test")
+}
+
+func (suite *paymentProvidersHandlerTestSuite) TestHandleSetPaymentProvider_Error() {
+	validBody := `{"credentials": {"secret_key": "sk_test", "webhook_secret": "whsec"}}`
+
+	tests := []struct {
+		desc         string
+		body         string
+		restaurantID string
+		provider     string
+		userID       uuid.UUID
+		statusCode   int
+	}{
+		{
+			"invalid restaurant id",
+			validBody,
+			"invalid",
+			"stripe",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"unknown provider",
+			validBody,
+			testRestaurantID.String(),
+			"paypal",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"missing credentials",
+			`{"is_default": true}`,
+			testRestaurantID.String(),
+			"stripe",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"incomplete credentials",
+			`{"credentials": {"secret_key": "sk_test"}}`,
+			testRestaurantID.String(),
+			"stripe",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"mock provider",
+			validBody,
+			testRestaurantID.String(),
+			"mock",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not manager",
+			validBody,
+			testRestaurantID.String(),
+			"stripe",
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			c, rec := suite.newContext(tt.body, tt.restaurantID, tt.provider, tt.userID)
+
+			err := suite.handler.HandleSetPaymentProvider(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *paymentProvidersHandlerTestSuite) TestHandleDeletePaymentProvider() {
+	tests := []struct {
+		desc       string
+		provider   string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{"deleted", "stripe", testUserID, http.StatusOK},
+		{"not configured", "klix", testUserID, http.StatusNotFound},
+		{"user is not manager", "stripe", testUserFromAnotherRestaurantID, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			c, rec := suite.newContext("", testRestaurantID.String(), tt.provider, tt.userID)
+
+			_ = suite.handler.HandleDeletePaymentProvider(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
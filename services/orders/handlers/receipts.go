@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/receipts"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/services"
+	"golang-dining-ordering/services/orders/sessions"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const receiptFormatQueryParam = "format"
+
+// ReceiptsHandler handles receipts of completed orders and restaurants' receipt settings
+// HTTP requests.
+type ReceiptsHandler struct {
+	svc services.ReceiptsService
+}
+
+// NewReceiptsHandler creates a new Handler for receipts.
+func NewReceiptsHandler(svc services.ReceiptsService) *ReceiptsHandler {
+	return &ReceiptsHandler{
+		svc: svc,
+	}
+}
+
+// HandleGetReceipt handles http request for receipt of a completed order, the receipt is
+// rendered as HTML page unless text or pdf format is requested.
+func (h *ReceiptsHandler) HandleGetReceipt(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	format := receipts.Format(c.QueryParam(receiptFormatQueryParam))
+	if format == "" {
+		format = receipts.FormatHTML
+	}
+
+	receipt, err := h.svc.GetReceipt(c.Request().Context(), orderID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get receipt")
+	}
+
+	body, err := receipts.Render(receipt, format)
+	if err != nil {
+		if errors.Is(err, receipts.ErrUnsupportedFormat) {
+			return responses.JSONError(c, err.Error(), err)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to render receipt",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	if format != receipts.FormatHTML {
+		c.Response().Header().Set(
+			echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=%q", receipts.Filename(receipt, format)),
+		)
+	}
+
+	return c.Blob(http.StatusOK, receipts.ContentType(format), body)
+}
+
+// HandleEmailReceipt handles http request to send receipt of a completed order by email,
+// made by guest with table session of the order or by staff of its restaurant.
+func (h *ReceiptsHandler) HandleEmailReceipt(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c, false)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.EmailReceiptRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.OrderID = orderID
+	reqDto.SessionToken = sessionTokenFromRequest(c)
+
+	err = h.svc.EmailReceipt(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to send receipt")
+	}
+
+	return responses.JSONSuccess(c, "receipt sent", nil)
+}
+
+// HandleGetReceiptSettings handles manager's http request to get restaurant's receipt settings.
+func (h *ReceiptsHandler) HandleGetReceiptSettings(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetReceiptSettings(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get receipt settings")
+	}
+
+	return responses.JSONSuccess(c, "receipt settings", respDto)
+}
+
+// HandleSetReceiptSettings handles manager's http request to configure restaurant's receipts.
+func (h *ReceiptsHandler) HandleSetReceiptSettings(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.SetReceiptSettingsRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.SetReceiptSettings(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to save receipt settings")
+	}
+
+	return responses.JSONSuccess(c, "receipt settings saved", respDto)
+}
+
+func (h *ReceiptsHandler) handleError(c echo.Context, err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrUserIsNotManager):
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	case errors.Is(err, services.ErrOrderConnectionUnauthorized),
+		errors.Is(err, sessions.ErrInvalidToken):
+		return responses.JSONError(c, err.Error(), err, http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTableSessionNotForOrder),
+		errors.Is(err, services.ErrUserIsNotRestaurantStaff):
+		return responses.JSONError(c, err.Error(), err, http.StatusForbidden)
+	case errors.Is(err, repository.ErrOrderDoesNotExist),
+		errors.Is(err, repository.ErrReceiptDoesNotExist):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+	case errors.Is(err, services.ErrOrderIsNotCompleted):
+		return responses.JSONError(c, err.Error(), err, http.StatusConflict)
+	case errors.Is(err, services.ErrReceiptEmailDisabled):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotImplemented)
+	default:
+		return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+	}
+}
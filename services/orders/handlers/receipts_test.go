@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock "golang-dining-ordering/test/mock/orders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type receiptsHandlerTestSuite struct {
+	suite.Suite
+
+	handler *ReceiptsHandler
+}
+
+func (suite *receiptsHandlerTestSuite) SetupSuite() {
+	svc := services.NewReceiptsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		mock.NewMockReceiptsRepo(),
+		nil,
+		mock.NewTableSessions(),
+	)
+
+	suite.handler = NewReceiptsHandler(svc)
+}
+
+func TestReceiptsHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(receiptsHandlerTestSuite))
+}
+
+func (suite *receiptsHandlerTestSuite) newContext(
+	method, target, body, paramName, paramValue string,
+	userID uuid.UUID,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames(paramName)
+	c.SetParamValues(paramValue)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+func (suite *receiptsHandlerTestSuite) TestHandleGetReceipt_Success() {
+	tests := []struct {
+		desc        string
+		target      string
+		contentType string
+		disposition string
+		body        string
+	}{
+		{"html by default", "/", "text/html; charset=utf-8", "", "<!DOCTYPE html>"},
+		{
+			"plain text",
+			"/?format=text",
+			"text/plain; charset=utf-8",
+			`attachment; filename="receipt-000042.txt"`,
+			"TOTAL EUR",
+		},
+		{
+			"pdf",
+			"/?format=pdf",
+			"application/pdf",
+			`attachment; filename="receipt-000042.pdf"`,
+			"%PDF-1.4",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodGet,
+				tt.target,
+				"",
+				orderIDParamName,
+				testCompletedOrderID.String(),
+				uuid.Nil,
+			)
+
+			err := suite.handler.HandleGetReceipt(c)
+			suite.Require().NoError(err)
+			suite.Equal(http.StatusOK, rec.Code)
+			suite.Equal(tt.contentType, rec.Header().Get(echo.HeaderContentType))
+			suite.Equal(tt.disposition, rec.Header().Get(echo.HeaderContentDisposition))
+			suite.Contains(rec.Body.String(), tt.body)
+		})
+	}
+}
+
+func (suite *receiptsHandlerTestSuite) TestHandleGetReceipt_Error() {
+	tests := []struct {
+		desc       string
+		target     string
+		orderID    string
+		statusCode int
+	}{
+		{"invalid order id", "/", "invalid", http.StatusBadRequest},
+		{"order not completed", "/", testOrderID.String(), http.StatusConflict},
+		{"unknown format", "/?format=docx", testCompletedOrderID.String(), http.StatusBadRequest},
+		{"repo failed", "/", uuid.NewString(), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodGet,
+				tt.target,
+				"",
+				orderIDParamName,
+				tt.orderID,
+				uuid.Nil,
+			)
+
+			err := suite.handler.HandleGetReceipt(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *receiptsHandlerTestSuite) TestHandleEmailReceipt_Error() {
+	session, err := mock.NewTableSessions().Issue(testCompletedOrderID, uuid.New())
+	suite.Require().NoError(err)
+
+	otherSession, err := mock.NewTableSessions().Issue(testOrderID, uuid.New())
+	suite.Require().NoError(err)
+
+	tests := []struct {
+		desc         string
+		body         string
+		sessionToken string
+		userID       uuid.UUID
+		statusCode   int
+	}{
+		{"invalid email", `{"email": "guest"}`, session.Token, uuid.Nil, http.StatusBadRequest},
+		{
+			"email disabled",
+			`{"email": "guest@example.com"}`,
+			session.Token,
+			uuid.Nil,
+			http.StatusNotImplemented,
+		},
+		{
+			"staff with email disabled",
+			`{"email": "guest@example.com"}`,
+			"",
+			testUserID,
+			http.StatusNotImplemented,
+		},
+		{
+			"no session or token",
+			`{"email": "guest@example.com"}`,
+			"",
+			uuid.Nil,
+			http.StatusUnauthorized,
+		},
+		{
+			"invalid session",
+			`{"email": "guest@example.com"}`,
+			"invalid",
+			uuid.Nil,
+			http.StatusUnauthorized,
+		},
+		{
+			"session of another order",
+			`{"email": "guest@example.com"}`,
+			otherSession.Token,
+			uuid.Nil,
+			http.StatusForbidden,
+		},
+		{
+			"user is not staff",
+			`{"email": "guest@example.com"}`,
+			"",
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPost,
+				"/",
+				tt.body,
+				orderIDParamName,
+				testCompletedOrderID.String(),
+				tt.userID,
+			)
+			c.Request().Header.Set(sessionTokenHeader, tt.sessionToken)
+
+			err := suite.handler.HandleEmailReceipt(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *receiptsHandlerTestSuite) TestHandleGetReceiptSettings() {
+	c, rec := suite.newContext(
+		http.MethodGet,
+		"/",
+		"",
+		restaurantIDParamName,
+		testRestaurantID.String(),
+		testUserID,
+	)
+
+	err := suite.handler.HandleGetReceiptSettings(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	c, rec = suite.newContext(
+		http.MethodGet,
+		"/",
+		"",
+		restaurantIDParamName,
+		testRestaurantID.String(),
+		testUserFromAnotherRestaurantID,
+	)
+
+	err = suite.handler.HandleGetReceiptSettings(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *receiptsHandlerTestSuite) TestHandleSetReceiptSettings() {
+	c, rec := suite.newContext(
+		http.MethodPut,
+		"/",
+		`{"tax_rate": 12.5}`,
+		restaurantIDParamName,
+		testRestaurantID.String(),
+		testUserID,
+	)
+
+	err := suite.handler.HandleSetReceiptSettings(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var got struct {
+		Data *dto.ReceiptSettingsDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+	suite.InDelta(12.5, got.Data.TaxRate, 0)
+
+	tests := []struct {
+		desc       string
+		body       string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{"missing tax rate", `{}`, testUserID, http.StatusBadRequest},
+		{"tax rate too high", `{"tax_rate": 100}`, testUserID, http.StatusBadRequest},
+		{"not a manager", `{"tax_rate": 5}`, testUserFromAnotherRestaurantID, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			c, rec := suite.newContext(
+				http.MethodPut,
+				"/",
+				tt.body,
+				restaurantIDParamName,
+				testRestaurantID.String(),
+				tt.userID,
+			)
+
+			err := suite.handler.HandleSetReceiptSettings(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
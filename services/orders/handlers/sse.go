@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/pkg/responses"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errInvalidLastEventID is returned when Last-Event-ID isn't a non-negative integer.
+var errInvalidLastEventID = errors.New("last event id must be a non-negative integer")
+
+const (
+	// lastEventIDHeader is set by browsers reconnecting to an event stream, to the id of the
+	// last event they got, which is the event's sequence number.
+	lastEventIDHeader = "Last-Event-ID"
+	// eventStreamContentType is content type of server-sent events.
+	eventStreamContentType = "text/event-stream"
+)
+
+// HandleOrderEvents streams order events as server-sent events, for clients whose network
+// doesn't let websockets through. It's authorized the same way as the websocket and gets
+// the same messages, each with type of the message as event name and sequence number of
+// numbered events as id. Changes are made through the REST endpoints. Clients reconnecting
+// with Last-Event-ID header, or last_seq query param, get the events they missed.
+func (h *WebsocketHandler) HandleOrderEvents(c echo.Context) error {
+	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
+	if err != nil {
+		return err
+	}
+
+	lastSeq, err := parseLastEventID(c)
+	if err != nil {
+		return responses.JSONError(c, "failed to parse last event id", err)
+	}
+
+	_, participant, err := h.authorizeConnection(c, orderID)
+	if err != nil {
+		return err
+	}
+
+	client := newClient(nil, h.clientCfg)
+	client.participant = participant
+
+	defer client.close()
+
+	err = h.conns.add(client)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err, http.StatusServiceUnavailable)
+	}
+
+	defer h.conns.remove(client)
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, eventStreamContentType)
+	resp.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// proxies such as nginx would otherwise buffer the stream
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+
+	defer h.attach(c.Request().Context(), client, orderID, lastSeq)()
+
+	err = client.streamEvents(c.Request().Context(), resp)
+	if err != nil {
+		h.logger.Error("event stream write failed", "orderID", orderID, "error", err)
+	}
+
+	return nil
+}
+
+// parseLastEventID returns sequence number of the last event the client got, browsers send
+// it in Last-Event-ID header when reconnecting, clients may pass last_seq when connecting.
+func parseLastEventID(c echo.Context) (*int64, error) {
+	header := c.Request().Header.Get(lastEventIDHeader)
+	if header == "" {
+		return parseLastSeq(c)
+	}
+
+	lastSeq, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || lastSeq < 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidLastEventID, header)
+	}
+
+	return &lastSeq, nil
+}
+
+// streamEvents writes queued messages to the response as server-sent events, and comments
+// as heartbeats, until the client is closed, the request is done or a write fails.
+func (c *client) streamEvents(ctx context.Context, w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// connection may serve further requests once the stream ends
+	defer func() { _ = rc.SetWriteDeadline(time.Time{}) }()
+
+	ticker := time.NewTicker(c.cfg.pingInterval)
+	defer ticker.Stop()
+
+	// headers are sent right away, so the client knows it's connected
+	err := c.writeEvent(rc, w, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case msg := <-c.send:
+			frame, err := eventFrame(msg)
+			if err != nil {
+				return err
+			}
+
+			err = c.writeEvent(rc, w, frame)
+			if err != nil {
+				return fmt.Errorf("writing event to client: %w", err)
+			}
+		case <-ticker.C:
+			err := c.writeEvent(rc, w, []byte(": ping\n\n"))
+			if err != nil {
+				return fmt.Errorf("pinging client: %w", err)
+			}
+		case <-c.done:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (c *client) writeEvent(
+	rc *http.ResponseController,
+	w http.ResponseWriter,
+	frame []byte,
+) error {
+	err := rc.SetWriteDeadline(time.Now().Add(c.cfg.writeTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("setting write deadline: %w", err)
+	}
+
+	_, err = w.Write(frame)
+	if err != nil {
+		return fmt.Errorf("writing to response: %w", err)
+	}
+
+	err = rc.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing response: %w", err)
+	}
+
+	return nil
+}
+
+// eventFrame formats the message as a server-sent event named by type of the message, with
+// sequence number as its id when it's a numbered event. Data is the same message websocket
+// clients get.
+func eventFrame(msg []byte) ([]byte, error) {
+	var envelope struct {
+		Type string `json:"type"`
+		Seq  int64  `json:"seq"`
+	}
+
+	err := json.Unmarshal(msg, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling queued message: %w", err)
+	}
+
+	var frame bytes.Buffer
+
+	if envelope.Seq > 0 {
+		frame.WriteString("id: " + strconv.FormatInt(envelope.Seq, 10) + "\n")
+	}
+
+	frame.WriteString("event: " + envelope.Type + "\n")
+	frame.WriteString("data: ")
+	frame.Write(msg)
+	frame.WriteString("\n\n")
+
+	return frame.Bytes(), nil
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/services"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	mock "golang-dining-ordering/test/mock/orders"
+)
+
+func (suite *websocketsHandlerTestSuite) TestEventFrame() {
+	tests := []struct {
+		name     string
+		msg      string
+		expected string
+	}{
+		{
+			"numbered event",
+			`{"v":1,"type":"add_item","seq":7,"data":{}}`,
+			"id: 7\nevent: add_item\n" +
+				"data: {\"v\":1,\"type\":\"add_item\",\"seq\":7,\"data\":{}}\n\n",
+		},
+		{
+			"presence",
+			`{"v":1,"type":"presence","data":{}}`,
+			"event: presence\ndata: {\"v\":1,\"type\":\"presence\",\"data\":{}}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			frame, err := eventFrame([]byte(tt.msg))
+			suite.Require().NoError(err)
+			suite.Equal(tt.expected, string(frame))
+		})
+	}
+
+	_, err := eventFrame([]byte("not json"))
+	suite.Require().Error(err)
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleOrderEvents_InvalidLastEventID() {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(lastEventIDHeader, "abc")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames(orderIDParamName)
+	c.SetParamValues(testOrderID.String())
+
+	err := suite.handler.HandleOrderEvents(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+// readEvent reads the next server-sent event, skipping heartbeat comments, and returns its
+// fields by name.
+func readEvent(reader *bufio.Reader) (map[string]string, error) {
+	fields := map[string]string{}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && len(fields) > 0:
+			return fields, nil
+		case line == "", strings.HasPrefix(line, ":"):
+			continue
+		default:
+			name, value, _ := strings.Cut(line, ": ")
+			fields[name] = value
+		}
+	}
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleOrderEvents_StreamsOrderEvents() {
+	pubsub := &subscribedPubSub{PubSub: events.NewMemoryPubSub(10), subscribed: make(chan struct{})}
+	svc := services.NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		pubsub,
+		mock.NewTableSessions(),
+	)
+	handler := NewWebsocketHandler(
+		svc,
+		newTestAssistanceService(pubsub),
+		pubsub,
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	// streams end with the context, so a missing event fails the test instead of hanging it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() { _ = handler.Run(ctx) }()
+
+	e := echo.New()
+	e.GET("/orders/:order_id/events", handler.HandleOrderEvents)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	<-pubsub.subscribed
+
+	url := server.URL + "/orders/" + testOrderID.String() + "/events?display_name=Tom&" +
+		"session_token=" + suite.testSessionToken(testOrderID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	suite.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	suite.Require().NoError(err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal("text/event-stream", resp.Header.Get(echo.HeaderContentType))
+
+	reader := bufio.NewReader(resp.Body)
+
+	event, err := readEvent(reader)
+	suite.Require().NoError(err)
+	suite.Equal("presence", event["event"])
+
+	event, err = readEvent(reader)
+	suite.Require().NoError(err)
+	suite.Equal("participant_joined", event["event"])
+	suite.Contains(event["data"], `"display_name":"Tom"`)
+
+	// change made through REST, the stream gets the same message as websockets
+	_, err = svc.AddItemToOrder(context.Background(), testOrderID, testItemID, nil)
+	suite.Require().NoError(err)
+
+	event, err = readEvent(reader)
+	suite.Require().NoError(err)
+	suite.Equal("add_item", event["event"])
+	suite.Equal("1", event["id"])
+	suite.Contains(event["data"], `"seq":1`)
+	suite.Contains(event["data"], testItemName)
+
+	// browser reconnecting with the id of an earlier event gets the events after it replayed
+	resumeReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	suite.Require().NoError(err)
+	resumeReq.Header.Set(lastEventIDHeader, "0")
+
+	resumed, err := http.DefaultClient.Do(resumeReq)
+	suite.Require().NoError(err)
+
+	defer func() { _ = resumed.Body.Close() }()
+
+	resumedReader := bufio.NewReader(resumed.Body)
+
+	event, err = readEvent(resumedReader)
+	suite.Require().NoError(err)
+	suite.Equal("presence", event["event"])
+
+	// same participant connecting again isn't announced, the missed event comes next
+	event, err = readEvent(resumedReader)
+	suite.Require().NoError(err)
+	suite.Equal("add_item", event["event"])
+	suite.Equal("1", event["id"])
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/config"
+	"golang-dining-ordering/pkg/responses"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/services"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// errInvalidWaiterID is returned when waiter_id filter isn't a valid uuid.
+var errInvalidWaiterID = errors.New("waiter_id must be a valid uuid")
+
+const (
+	// sectionQueryParam filters staff feed by section of order's table, it may be repeated.
+	sectionQueryParam = "section"
+	// waiterIDQueryParam filters staff feed by waiters assigned to the order, it may be
+	// repeated.
+	waiterIDQueryParam = "waiter_id"
+)
+
+// StaffFeedHandler handles staff feeds, websocket connections waiters and managers get live
+// events of every order in their restaurant through.
+type StaffFeedHandler struct {
+	svc       services.StaffFeedService
+	events    events.Subscriber
+	upgrader  *websocket.Upgrader
+	clientCfg *clientConfig
+	logger    *slog.Logger
+	mu        sync.Mutex
+	feeds     map[uuid.UUID]map[*client]*feedFilter
+	conns     *connections
+}
+
+// NewStaffFeedHandler creates a new Handler for staff feeds, staff events are delivered to
+// its connections once Run is started.
+func NewStaffFeedHandler(
+	svc services.StaffFeedService,
+	orderEvents events.Subscriber,
+	cfg *config.WebsocketConfig,
+	logger *slog.Logger,
+) *StaffFeedHandler {
+	return &StaffFeedHandler{
+		svc:       svc,
+		events:    orderEvents,
+		upgrader:  newUpgrader(cfg),
+		clientCfg: newClientConfig(cfg),
+		logger:    logger,
+		mu:        sync.Mutex{},
+		feeds:     map[uuid.UUID]map[*client]*feedFilter{},
+		conns:     newConnections(),
+	}
+}
+
+// feedFilter limits staff feed to orders at tables of the sections or orders assigned to
+// the waiters. Both have to match when both are set, empty filter matches every order.
+type feedFilter struct {
+	sections  []string
+	waiterIDs []uuid.UUID
+}
+
+func (f *feedFilter) matches(table *dto.OrderTableDto) bool {
+	// events published without table reach only feeds that aren't filtered
+	if table == nil {
+		return len(f.sections) == 0 && len(f.waiterIDs) == 0
+	}
+
+	if len(f.sections) > 0 {
+		if table.Section == nil {
+			return false
+		}
+
+		if !slices.ContainsFunc(f.sections, func(section string) bool {
+			return strings.EqualFold(section, *table.Section)
+		}) {
+			return false
+		}
+	}
+
+	if len(f.waiterIDs) > 0 {
+		return slices.ContainsFunc(table.WaiterIDs, func(waiterID uuid.UUID) bool {
+			return slices.Contains(f.waiterIDs, waiterID)
+		})
+	}
+
+	return true
+}
+
+func parseFeedFilter(c echo.Context) (*feedFilter, error) {
+	params := c.QueryParams()
+
+	filter := &feedFilter{
+		sections:  make([]string, 0, len(params[sectionQueryParam])),
+		waiterIDs: make([]uuid.UUID, 0, len(params[waiterIDQueryParam])),
+	}
+
+	for _, section := range params[sectionQueryParam] {
+		section = strings.TrimSpace(section)
+		if section != "" {
+			filter.sections = append(filter.sections, section)
+		}
+	}
+
+	for _, param := range params[waiterIDQueryParam] {
+		waiterID, err := uuid.Parse(param)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidWaiterID, param)
+		}
+
+		filter.waiterIDs = append(filter.waiterIDs, waiterID)
+	}
+
+	return filter, nil
+}
+
+// Run delivers staff events published by any instance to staff feeds of their restaurants,
+// until the context is cancelled.
+func (h *StaffFeedHandler) Run(ctx context.Context) error {
+	orderEvents, err := h.events.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscribing to order events: %w", err)
+	}
+
+	for event := range orderEvents {
+		if event.IsStaff() {
+			h.deliverEvent(event)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes staff feeds, telling clients to reconnect, and waits for their handlers
+// to finish until the context is done.
+func (h *StaffFeedHandler) Shutdown(ctx context.Context) error {
+	return h.conns.shutdown(ctx)
+}
+
+// HandleStaffFeedWebsocket handles staff feed connections of waiters and managers of the
+// restaurant. Feed can be filtered by section and waiter_id query params.
+func (h *StaffFeedHandler) HandleStaffFeedWebsocket(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFeedFilter(c)
+	if err != nil {
+		return responses.JSONError(c, "failed to parse staff feed filter", err)
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.svc.AuthorizeStaffFeed(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		if errors.Is(err, services.ErrUserIsNotRestaurantStaff) {
+			return responses.JSONError(c, err.Error(), err, http.StatusForbidden)
+		}
+
+		return responses.JSONError(
+			c,
+			"failed to authorize staff feed",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return responses.JSONError(
+			c,
+			"failed to upgrade websocket",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+
+	client := newClient(conn, h.clientCfg)
+
+	defer client.close()
+
+	go func() {
+		err := client.writePump()
+		if err != nil {
+			h.logger.Error("staff feed write failed", "restaurantID", restaurantID, "error", err)
+		}
+	}()
+
+	// client connecting during shutdown is closed by its write pump
+	err = h.conns.add(client)
+	if err != nil {
+		return nil
+	}
+
+	defer h.conns.remove(client)
+
+	h.subscribe(restaurantID, client, filter)
+	defer h.unsubscribe(restaurantID, client)
+
+	// staff feed is read only, messages are read for pongs and to notice disconnects
+	client.startReading()
+
+	for {
+		_, err = client.read()
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func (h *StaffFeedHandler) subscribe(restaurantID uuid.UUID, feed *client, filter *feedFilter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	feeds, ok := h.feeds[restaurantID]
+	if !ok {
+		feeds = map[*client]*feedFilter{}
+		h.feeds[restaurantID] = feeds
+	}
+
+	feeds[feed] = filter
+}
+
+func (h *StaffFeedHandler) unsubscribe(restaurantID uuid.UUID, feed *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.feeds[restaurantID], feed)
+
+	if len(h.feeds[restaurantID]) == 0 {
+		delete(h.feeds, restaurantID)
+	}
+}
+
+// subscribers returns staff feeds of the restaurant whose filter matches the table.
+func (h *StaffFeedHandler) subscribers(
+	restaurantID uuid.UUID,
+	table *dto.OrderTableDto,
+) []*client {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	feeds := make([]*client, 0, len(h.feeds[restaurantID]))
+
+	for feed, filter := range h.feeds[restaurantID] {
+		if filter.matches(table) {
+			feeds = append(feeds, feed)
+		}
+	}
+
+	return feeds
+}
+
+func (h *StaffFeedHandler) hasSubscribers(restaurantID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.feeds[restaurantID]) > 0
+}
+
+// deliverEvent sends the staff event to matching feeds of its restaurant on this instance,
+// feeds are matched by the table the event was published with. Feeds too slow to keep up
+// are evicted.
+func (h *StaffFeedHandler) deliverEvent(event *events.Event) {
+	staffEvent := event.Staff
+	if staffEvent == nil || !h.hasSubscribers(staffEvent.RestaurantID) {
+		return
+	}
+
+	msg, err := marshalMsg(&dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: "",
+		Type:      event.Type,
+		Seq:       0,
+		Data:      staffEvent,
+	})
+	if err != nil {
+		h.logger.Error("failed to marshal staff event", "orderID", staffEvent.OrderID, "error", err)
+
+		return
+	}
+
+	for _, feed := range h.subscribers(staffEvent.RestaurantID, staffEvent.Table) {
+		err := feed.enqueue(msg)
+		if err != nil {
+			h.logger.Warn(
+				"evicting staff feed",
+				"restaurantID",
+				staffEvent.RestaurantID,
+				"error",
+				err,
+			)
+
+			feed.close()
+			h.unsubscribe(staffEvent.RestaurantID, feed)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/services"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+
+	mock "golang-dining-ordering/test/mock/orders"
+)
+
+// testWaiterID is the waiter mock repo assigns to testOrderID.
+var testWaiterID = uuid.MustParse("12121212-1212-4121-8121-121212121212")
+
+type staffFeedHandlerTestSuite struct {
+	suite.Suite
+}
+
+func TestStaffFeedHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(staffFeedHandlerTestSuite))
+}
+
+func (suite *staffFeedHandlerTestSuite) TestFeedFilter_Matches() {
+	section := "Terrace"
+	table := &dto.OrderTableDto{
+		TableID:      uuid.New(),
+		RestaurantID: testRestaurantID,
+		TableName:    "T1",
+		Section:      &section,
+		WaiterIDs:    []uuid.UUID{testWaiterID},
+	}
+	tableWithoutSection := &dto.OrderTableDto{
+		TableID:      uuid.New(),
+		RestaurantID: testRestaurantID,
+		TableName:    "T2",
+		Section:      nil,
+		WaiterIDs:    nil,
+	}
+
+	tests := []struct {
+		name     string
+		filter   feedFilter
+		table    *dto.OrderTableDto
+		expected bool
+	}{
+		{"empty filter", feedFilter{sections: nil, waiterIDs: nil}, tableWithoutSection, true},
+		{"section", feedFilter{sections: []string{"terrace"}, waiterIDs: nil}, table, true},
+		{"other section", feedFilter{sections: []string{"Bar"}, waiterIDs: nil}, table, false},
+		{
+			"section of table without one",
+			feedFilter{sections: []string{"Terrace"}, waiterIDs: nil},
+			tableWithoutSection,
+			false,
+		},
+		{"waiter", feedFilter{sections: nil, waiterIDs: []uuid.UUID{testWaiterID}}, table, true},
+		{
+			"other waiter",
+			feedFilter{sections: nil, waiterIDs: []uuid.UUID{testUserID}},
+			table,
+			false,
+		},
+		{
+			"section and other waiter",
+			feedFilter{sections: []string{"Terrace"}, waiterIDs: []uuid.UUID{testUserID}},
+			table,
+			false,
+		},
+		{"empty filter without table", feedFilter{sections: nil, waiterIDs: nil}, nil, true},
+		{
+			"section without table",
+			feedFilter{sections: []string{"Terrace"}, waiterIDs: nil},
+			nil,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Equal(tt.expected, tt.filter.matches(tt.table))
+		})
+	}
+}
+
+func (suite *staffFeedHandlerTestSuite) TestHandleStaffFeedWebsocket_BadRequest() {
+	handler := NewStaffFeedHandler(
+		services.NewStaffFeedService(mock.NewMockOrdersRepo()),
+		events.NewMemoryPubSub(10),
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	tests := []struct {
+		name         string
+		query        string
+		userID       uuid.UUID
+		ctxKey       mock.CtxKey
+		expectedCode int
+	}{
+		{"invalid waiter_id", "?waiter_id=abc", testUserID, "none", http.StatusBadRequest},
+		{
+			"not restaurant staff",
+			"",
+			testUserFromAnotherRestaurantID,
+			"none",
+			http.StatusForbidden,
+		},
+		{
+			"staff check failed",
+			"",
+			testUserID,
+			mock.CtxFailIsUserRestaurantMember,
+			http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), tt.ctxKey, true))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames(restaurantIDParamName)
+			c.SetParamValues(testRestaurantID.String())
+			c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{UserID: tt.userID})
+
+			_ = handler.HandleStaffFeedWebsocket(c)
+			suite.Equal(tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func (suite *staffFeedHandlerTestSuite) TestRun_DeliversFilteredStaffEvents() {
+	pubsub := &subscribedPubSub{PubSub: events.NewMemoryPubSub(10), subscribed: make(chan struct{})}
+	handler := NewStaffFeedHandler(
+		services.NewStaffFeedService(mock.NewMockOrdersRepo()),
+		pubsub,
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = handler.Run(ctx) }()
+
+	e := echo.New()
+	e.GET(
+		"/restaurants/:restaurant_id/feed/ws",
+		handler.HandleStaffFeedWebsocket,
+		func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{UserID: testUserID})
+
+				return next(c)
+			}
+		},
+	)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/restaurants/" +
+		testRestaurantID.String() + "/feed/ws?section=terrace"
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	suite.Require().NoError(err)
+
+	defer func() { _ = conn.Close() }()
+	defer func() { _ = resp.Body.Close() }()
+
+	<-pubsub.subscribed
+	suite.Eventually(func() bool {
+		return handler.hasSubscribers(testRestaurantID)
+	}, time.Second, 10*time.Millisecond)
+
+	bar := "Bar"
+	opened := &dto.StaffEventDto{
+		RestaurantID: testRestaurantID,
+		OrderID:      uuid.New(),
+		Table: &dto.OrderTableDto{
+			TableID:      uuid.New(),
+			RestaurantID: testRestaurantID,
+			TableName:    "B1",
+			Section:      &bar,
+			WaiterIDs:    nil,
+		},
+		Items:      nil,
+		Payment:    nil,
+		Assistance: nil,
+		OccurredAt: time.Now(),
+	}
+	terrace := "Terrace"
+	locked := &dto.StaffEventDto{
+		RestaurantID: testRestaurantID,
+		OrderID:      testOrderID,
+		Table: &dto.OrderTableDto{
+			TableID:      uuid.New(),
+			RestaurantID: testRestaurantID,
+			TableName:    "T1",
+			Section:      &terrace,
+			WaiterIDs:    []uuid.UUID{testWaiterID},
+		},
+		Items:      nil,
+		Payment:    nil,
+		Assistance: nil,
+		OccurredAt: time.Now(),
+	}
+	withoutTable := &dto.StaffEventDto{
+		RestaurantID: testRestaurantID,
+		OrderID:      uuid.New(),
+		Table:        nil,
+		Items:        nil,
+		Payment:      nil,
+		Assistance:   nil,
+		OccurredAt:   time.Now(),
+	}
+
+	// order at the bar and event without table are filtered out
+	pubsub.Publish(ctx, events.NewStaffEvent(dto.MsgOrderOpened, opened))
+	pubsub.Publish(ctx, events.NewStaffEvent(dto.MsgOrderOpened, withoutTable))
+	pubsub.Publish(ctx, events.NewStaffEvent(dto.MsgOrderLocked, locked))
+
+	var msg struct {
+		Type string             `json:"type"`
+		Data *dto.StaffEventDto `json:"data"`
+	}
+
+	suite.Require().NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+	suite.Require().NoError(conn.ReadJSON(&msg))
+	suite.Equal("order_locked", msg.Type)
+	suite.Equal(testOrderID, msg.Data.OrderID)
+	suite.Require().NotNil(msg.Data.Table)
+	suite.Equal([]uuid.UUID{testWaiterID}, msg.Data.Table.WaiterIDs)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"golang-dining-ordering/pkg/responses"
+	"golang-dining-ordering/pkg/validation"
+	hndl "golang-dining-ordering/services/management/handlers"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TipsHandler handles restaurants' tip settings and tip report HTTP requests.
+type TipsHandler struct {
+	svc services.TipsService
+}
+
+// NewTipsHandler creates a new Handler for restaurants' tips.
+func NewTipsHandler(svc services.TipsService) *TipsHandler {
+	return &TipsHandler{
+		svc: svc,
+	}
+}
+
+// HandleGetTipSettings handles manager's http request to get restaurant's tip settings.
+func (h *TipsHandler) HandleGetTipSettings(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	respDto, err := h.svc.GetTipSettings(c.Request().Context(), restaurantID, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get tip settings")
+	}
+
+	return responses.JSONSuccess(c, "tip settings", respDto)
+}
+
+// HandleSetTipSettings handles manager's http request to configure tip presets and pool rule.
+func (h *TipsHandler) HandleSetTipSettings(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.SetTipSettingsRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.SetTipSettings(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to save tip settings")
+	}
+
+	return responses.JSONSuccess(c, "tip settings saved", respDto)
+}
+
+// HandleGetTipReport handles manager's http request for tips each waiter earned in a period.
+func (h *TipsHandler) HandleGetTipReport(c echo.Context) error {
+	restaurantID, err := hndl.GetUUUIDFromParams(c, restaurantIDParamName)
+	if err != nil {
+		return err
+	}
+
+	user, err := hndl.GetUserFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	var reqDto dto.TipReportRequestDto
+
+	err = validation.ValidateDto(c, &reqDto)
+	if err != nil {
+		return responses.JSONError(c, err.Error(), err)
+	}
+
+	reqDto.RestaurantID = restaurantID
+
+	respDto, err := h.svc.GetTipReport(c.Request().Context(), &reqDto, user)
+	if err != nil {
+		return h.handleError(c, err, "failed to get tip report")
+	}
+
+	return responses.JSONSuccess(c, "tip report", respDto)
+}
+
+func (h *TipsHandler) handleError(c echo.Context, err error, msg string) error {
+	if errors.Is(err, services.ErrUserIsNotManager) {
+		return responses.JSONError(
+			c,
+			services.ErrUserIsNotManager.Error(),
+			err,
+			http.StatusForbidden,
+		)
+	}
+
+	return responses.JSONError(c, msg, err, http.StatusInternalServerError)
+}
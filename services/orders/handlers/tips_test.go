@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock "golang-dining-ordering/test/mock/orders"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type tipsHandlerTestSuite struct {
+	suite.Suite
+
+	handler *TipsHandler
+}
+
+func (suite *tipsHandlerTestSuite) SetupSuite() {
+	svc := services.NewTipsService(mock.NewMockOrdersRepo(), mock.NewMockTipsRepo())
+
+	suite.handler = NewTipsHandler(svc)
+}
+
+func TestTipsHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(tipsHandlerTestSuite))
+}
+
+func (suite *tipsHandlerTestSuite) newContext(
+	method, target, body, restaurantID string,
+	userID uuid.UUID,
+) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	c.SetParamNames(restaurantIDParamName)
+	c.SetParamValues(restaurantID)
+
+	c.Set(middleware.ContextKeyAuthUser, &authDto.TokenClaimsDto{
+		UserID: userID,
+	})
+
+	return c, rec
+}
+
+func (suite *tipsHandlerTestSuite) TestHandleGetTipSettings() {
+	c, rec := suite.newContext(http.MethodGet, "/", "", testRestaurantID.String(), testUserID)
+
+	err := suite.handler.HandleGetTipSettings(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	c, rec = suite.newContext(
+		http.MethodGet,
+		"/",
+		"",
+		testRestaurantID.String(),
+		testUserFromAnotherRestaurantID,
+	)
+
+	err = suite.handler.HandleGetTipSettings(c)
+	suite.Require().Error(err)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *tipsHandlerTestSuite) TestHandleSetTipSettings_Success() {
+	body := `{"presets": [10, 12.5, 15], "pool_rule": "time_assigned"}`
+
+	c, rec := suite.newContext(http.MethodPut, "/", body, testRestaurantID.String(), testUserID)
+
+	err := suite.handler.HandleSetTipSettings(c)
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var got struct {
+		Data *dto.TipSettingsDto `json:"data"`
+	}
+
+	err = json.Unmarshal(rec.Body.Bytes(), &got)
+	suite.Require().NoError(err)
+	suite.Equal([]float64{10, 12.5, 15}, got.Data.Presets)
+	suite.Equal(db.OrdersTipPoolRuleTimeAssigned, got.Data.PoolRule)
+}
+
+func (suite *tipsHandlerTestSuite) TestHandleSetTipSettings_Error() {
+	tests := []struct {
+		desc         string
+		body         string
+		restaurantID string
+		userID       uuid.UUID
+		statusCode   int
+	}{
+		{
+			"invalid restaurant id",
+			`{"presets": [10], "pool_rule": "equal"}`,
+			"invalid",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"no presets",
+			`{"presets": [], "pool_rule": "equal"}`,
+			testRestaurantID.String(),
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"preset over 100 percent",
+			`{"presets": [150], "pool_rule": "equal"}`,
+			testRestaurantID.String(),
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"duplicate presets",
+			`{"presets": [10, 10], "pool_rule": "equal"}`,
+			testRestaurantID.String(),
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"unknown pool rule",
+			`{"presets": [10], "pool_rule": "by_seniority"}`,
+			testRestaurantID.String(),
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"shift length not dividing a day",
+			`{"presets": [10], "pool_rule": "shift_pool", "shift_length_hours": 7}`,
+			testRestaurantID.String(),
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not manager",
+			`{"presets": [10], "pool_rule": "equal"}`,
+			testRestaurantID.String(),
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			c, rec := suite.newContext(http.MethodPut, "/", tt.body, tt.restaurantID, tt.userID)
+
+			err := suite.handler.HandleSetTipSettings(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
+
+func (suite *tipsHandlerTestSuite) TestHandleGetTipReport() {
+	tests := []struct {
+		desc       string
+		query      string
+		userID     uuid.UUID
+		statusCode int
+	}{
+		{
+			"report",
+			"?from=2025-12-01T00:00:00Z&to=2025-12-31T00:00:00Z",
+			testUserID,
+			http.StatusOK,
+		},
+		{"missing range", "", testUserID, http.StatusBadRequest},
+		{
+			"to before from",
+			"?from=2025-12-31T00:00:00Z&to=2025-12-01T00:00:00Z",
+			testUserID,
+			http.StatusBadRequest,
+		},
+		{
+			"user is not manager",
+			"?from=2025-12-01T00:00:00Z&to=2025-12-31T00:00:00Z",
+			testUserFromAnotherRestaurantID,
+			http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		suite.T().Run(tt.desc, func(_ *testing.T) {
+			c, rec := suite.newContext(
+				http.MethodGet,
+				"/"+tt.query,
+				"",
+				testRestaurantID.String(),
+				tt.userID,
+			)
+
+			_ = suite.handler.HandleGetTipReport(c)
+			suite.Equal(tt.statusCode, rec.Code)
+		})
+	}
+}
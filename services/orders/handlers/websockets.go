@@ -3,15 +3,24 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"golang-dining-ordering/config"
 	"golang-dining-ordering/pkg/responses"
 	authDto "golang-dining-ordering/services/auth/dto"
 	hndl "golang-dining-ordering/services/management/handlers"
+	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
 	"golang-dining-ordering/services/orders/services"
+	"golang-dining-ordering/services/orders/sessions"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,49 +30,174 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// WebsocketHandler handles orders-related websocket requests.
+var (
+	// errInvalidLastSeq is returned when last_seq isn't a non-negative integer.
+	errInvalidLastSeq = errors.New("last_seq must be a non-negative integer")
+	// errMalformedMessage is returned when client's message isn't a valid envelope.
+	errMalformedMessage = errors.New("malformed websocket message")
+	// errUnsupportedVersion is returned when client's message is of another protocol version.
+	errUnsupportedVersion = errors.New("unsupported websocket protocol version")
+	// errUnknownMessageType is returned when type of client's message isn't known.
+	errUnknownMessageType = errors.New("unknown request type")
+	// errInvalidMessageData is returned when data of client's message fails validation.
+	errInvalidMessageData = errors.New("invalid message data")
+)
+
+const (
+	// sessionTokenQueryParam is where browsers pass table session token, since they can't set
+	// headers of websocket requests.
+	sessionTokenQueryParam = "session_token"
+	sessionTokenHeader     = "X-Table-Session"
+	// lastSeqQueryParam is sequence number of the last event client got before reconnecting.
+	lastSeqQueryParam = "last_seq"
+	// displayNameQueryParam is the name guest is shown under to other participants.
+	displayNameQueryParam = "display_name"
+	// anyOrigin in allowed origins lets browsers connect from every origin.
+	anyOrigin = "*"
+)
+
+// WebsocketHandler handles orders-related websocket requests and event streams. Changes of
+// orders reach connections through order events, so connections on every instance receive
+// them, and so do participants joining and leaving orders. Assistance guests request over
+// websocket is pushed to staff feeds.
 type WebsocketHandler struct {
 	svc        services.OrdersService
+	assistance services.AssistanceService
+	events     events.PubSub
 	upgrader   *websocket.Upgrader
+	clientCfg  *clientConfig
 	logger     *slog.Logger
 	orderConns sync.Map
+	presence   *presence
+	conns      *connections
 }
 
-// NewWebsocketHandler creates a new Handler for orders websockets.
+// NewWebsocketHandler creates a new Handler for orders websockets, order events are
+// broadcast to its connections once Run is started.
 func NewWebsocketHandler(
 	svc services.OrdersService,
+	assistanceSvc services.AssistanceService,
+	orderEvents events.PubSub,
 	cfg *config.WebsocketConfig,
 	logger *slog.Logger,
 ) *WebsocketHandler {
-	upgrader := websocket.Upgrader{
-		CheckOrigin: func(_ *http.Request) bool {
-			return true
-		},
-		HandshakeTimeout:  time.Duration(cfg.HandshakeTimeout) * time.Second,
-		ReadBufferSize:    cfg.ReadBufferSize,
-		WriteBufferSize:   cfg.WriteBufferSize,
-		WriteBufferPool:   nil,
-		Subprotocols:      nil,
-		Error:             nil,
-		EnableCompression: false,
-	}
-
 	return &WebsocketHandler{
 		svc:        svc,
-		upgrader:   &upgrader,
+		assistance: assistanceSvc,
+		events:     orderEvents,
+		upgrader:   newUpgrader(cfg),
+		clientCfg:  newClientConfig(cfg),
 		logger:     logger,
 		orderConns: sync.Map{},
+		presence:   newPresence(presenceTTL),
+		conns:      newConnections(),
 	}
 }
 
-// HandleOrderWebsocket handles websocket connections for ordering.
+// Run broadcasts order events published by any instance to connections of their orders,
+// until the context is cancelled.
+func (h *WebsocketHandler) Run(ctx context.Context) error {
+	orderEvents, err := h.events.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscribing to order events: %w", err)
+	}
+
+	go h.keepPresence(ctx, presenceHeartbeatPeriod)
+
+	for event := range orderEvents {
+		h.broadcastEvent(ctx, event)
+	}
+
+	return nil
+}
+
+// keepPresence announces connections of this instance every period, so other instances keep
+// them present, and drops connections no instance announced within presence ttl, e.g. those
+// of an instance that crashed. It runs until the context is cancelled.
+func (h *WebsocketHandler) keepPresence(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.announcePresence(ctx)
+			h.expirePresence(now)
+		}
+	}
+}
+
+// announcePresence publishes joined event of every connection of this instance again.
+func (h *WebsocketHandler) announcePresence(ctx context.Context) {
+	h.orderConns.Range(func(key, inner any) bool {
+		orderID, ok := key.(uuid.UUID)
+		if !ok {
+			return true
+		}
+
+		clientsMap, ok := inner.(*sync.Map)
+		if !ok {
+			return true
+		}
+
+		clientsMap.Range(func(key, _ any) bool {
+			c, ok := key.(*client)
+			if ok && c.participant != nil {
+				h.events.Publish(ctx, events.NewPresenceEvent(
+					dto.MsgParticipantJoined,
+					orderID,
+					c.participant,
+					c.id,
+				))
+			}
+
+			return true
+		})
+
+		return true
+	})
+}
+
+// expirePresence drops connections that stopped being announced and tells clients of their
+// orders that participants left without connections are gone.
+func (h *WebsocketHandler) expirePresence(now time.Time) {
+	for orderID, participants := range h.presence.expire(now) {
+		for _, participant := range participants {
+			h.broadcastMessage(orderID, &dto.WSRespMessage{
+				V:         dto.WSProtocolVersion,
+				RequestID: "",
+				Type:      dto.MsgParticipantLeft,
+				Seq:       0,
+				Data:      participant,
+			})
+		}
+	}
+}
+
+// Shutdown closes websockets and event streams of orders, telling clients to reconnect, and
+// waits for their handlers to finish until the context is done.
+func (h *WebsocketHandler) Shutdown(ctx context.Context) error {
+	return h.conns.shutdown(ctx)
+}
+
+// HandleOrderWebsocket handles websocket connections for ordering. Guests connect with
+// table session of the order and optional display name, waiters and managers of order's
+// restaurant with their token. Clients get participants present at the order after
+// connecting, and clients reconnecting with last_seq get the events they missed.
 func (h *WebsocketHandler) HandleOrderWebsocket(c echo.Context) error {
 	orderID, err := hndl.GetUUUIDFromParams(c, orderIDParamName)
 	if err != nil {
 		return err
 	}
 
-	user, err := hndl.GetUserFromContext(c, false)
+	lastSeq, err := parseLastSeq(c)
+	if err != nil {
+		return responses.JSONError(c, "failed to parse last_seq from url", err)
+	}
+
+	user, participant, err := h.authorizeConnection(c, orderID)
 	if err != nil {
 		return err
 	}
@@ -73,12 +207,188 @@ func (h *WebsocketHandler) HandleOrderWebsocket(c echo.Context) error {
 		return err
 	}
 
-	defer func() { _ = conn.Close() }()
+	client := newClient(conn, h.clientCfg)
+	client.participant = participant
+
+	defer client.close()
+
+	go func() {
+		err := client.writePump()
+		if err != nil {
+			h.logger.Error("websocket client write failed", "orderID", orderID, "error", err)
+		}
+	}()
+
+	// client connecting during shutdown is closed by its write pump
+	err = h.conns.add(client)
+	if err != nil {
+		return nil
+	}
+
+	defer h.conns.remove(client)
+
+	defer h.attach(c.Request().Context(), client, orderID, lastSeq)()
+
+	return h.readMessages(c, client, orderID, user)
+}
+
+// authorizeConnection checks that the guest's table session or the staff member's token
+// is for the order, it returns the user, if any, and the participant who connects.
+func (h *WebsocketHandler) authorizeConnection(
+	c echo.Context,
+	orderID uuid.UUID,
+) (*authDto.TokenClaimsDto, *dto.ParticipantDto, error) {
+	user, err := hndl.GetUserFromContext(c, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participant, err := h.svc.AuthorizeOrderConnection(
+		c.Request().Context(),
+		orderID,
+		sessionTokenFromRequest(c),
+		c.QueryParam(displayNameQueryParam),
+		user,
+	)
+	if err != nil {
+		return nil, nil, h.handleAuthorizationError(c, err)
+	}
+
+	return user, participant, nil
+}
 
-	h.joinOrder(orderID, conn)
-	defer h.leaveOrder(orderID, conn)
+// sessionTokenFromRequest returns guest's table session token from the query or the header.
+func sessionTokenFromRequest(c echo.Context) string {
+	sessionToken := c.QueryParam(sessionTokenQueryParam)
+	if sessionToken == "" {
+		sessionToken = c.Request().Header.Get(sessionTokenHeader)
+	}
 
-	return h.readMessages(c, conn, orderID, user)
+	return sessionToken
+}
+
+// attach adds the client to connections of the order, whether it's a websocket or an event
+// stream, so both get the same messages. The client gets participants present at the order
+// and, with lastSeq, the events it missed, others are told the participant joined. Returned
+// func detaches the client once it disconnects.
+func (h *WebsocketHandler) attach(
+	ctx context.Context,
+	client *client,
+	orderID uuid.UUID,
+	lastSeq *int64,
+) func() {
+	h.joinOrder(orderID, client)
+
+	err := h.sendMsg(client, dto.MsgPresence, &dto.PresenceDto{
+		Participants: h.presence.participants(orderID),
+	})
+	if err != nil {
+		h.logger.Error("failed to send presence", "orderID", orderID, "error", err)
+	}
+
+	// leaving is published even when the request's context is cancelled on disconnect
+	publishCtx := context.WithoutCancel(ctx)
+	h.events.Publish(
+		publishCtx,
+		events.NewPresenceEvent(dto.MsgParticipantJoined, orderID, client.participant, client.id),
+	)
+
+	// client joins before syncing, so no event falls between the two
+	if lastSeq != nil {
+		err = h.sync(ctx, client, orderID, lastSeq)
+		if err != nil {
+			h.logger.Error("failed to sync client", "orderID", orderID, "error", err)
+			_ = h.sendMsg(client, dto.MsgError, dto.WSErrorDto{
+				Type:    dto.MsgSync,
+				Code:    dto.WSErrInternal,
+				Message: "failed to sync order",
+			})
+		}
+	}
+
+	// client is no longer announced once it leaves, so no heartbeat follows the left event
+	return func() {
+		h.leaveOrder(orderID, client)
+		h.events.Publish(
+			publishCtx,
+			events.NewPresenceEvent(
+				dto.MsgParticipantLeft,
+				orderID,
+				client.participant,
+				client.id,
+			),
+		)
+	}
+}
+
+func (h *WebsocketHandler) handleAuthorizationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrOrderConnectionUnauthorized),
+		errors.Is(err, sessions.ErrInvalidToken):
+		return responses.JSONError(c, err.Error(), err, http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTableSessionNotForOrder),
+		errors.Is(err, services.ErrUserIsNotRestaurantStaff),
+		errors.Is(err, repository.ErrParticipantOfAnotherOrder):
+		return responses.JSONError(c, err.Error(), err, http.StatusForbidden)
+	case errors.Is(err, services.ErrDisplayNameTooLong):
+		return responses.JSONError(c, err.Error(), err)
+	case errors.Is(err, repository.ErrOrderDoesNotExist):
+		return responses.JSONError(c, err.Error(), err, http.StatusNotFound)
+	default:
+		return responses.JSONError(
+			c,
+			"failed to authorize websocket connection",
+			err,
+			http.StatusInternalServerError,
+		)
+	}
+}
+
+// newUpgrader returns websocket upgrader of the config, shared by order connections and
+// staff feeds.
+func newUpgrader(cfg *config.WebsocketConfig) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		CheckOrigin:       newOriginChecker(cfg.AllowedOrigins),
+		HandshakeTimeout:  time.Duration(cfg.HandshakeTimeout) * time.Second,
+		ReadBufferSize:    cfg.ReadBufferSize,
+		WriteBufferSize:   cfg.WriteBufferSize,
+		WriteBufferPool:   nil,
+		Subprotocols:      nil,
+		Error:             nil,
+		EnableCompression: false,
+	}
+}
+
+// newOriginChecker returns origin check of websocket upgrades. Requests without an origin
+// don't come from browsers and are allowed, browsers may connect from the API's own origin
+// or allowed origins.
+func newOriginChecker(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make([]string, 0, len(allowedOrigins))
+
+	for _, origin := range allowedOrigins {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		if origin != "" {
+			allowed = append(allowed, origin)
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := strings.ToLower(r.Header.Get("Origin"))
+		if origin == "" {
+			return true
+		}
+
+		if slices.Contains(allowed, anyOrigin) || slices.Contains(allowed, origin) {
+			return true
+		}
+
+		originURL, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+
+		return strings.EqualFold(originURL.Host, r.Host)
+	}
 }
 
 func (h *WebsocketHandler) upgradeConnection(c echo.Context) (*websocket.Conn, error) {
@@ -97,19 +407,21 @@ func (h *WebsocketHandler) upgradeConnection(c echo.Context) (*websocket.Conn, e
 
 func (h *WebsocketHandler) readMessages(
 	c echo.Context,
-	conn *websocket.Conn,
+	client *client,
 	orderID uuid.UUID,
 	user *authDto.TokenClaimsDto,
 ) error {
+	client.startReading()
+
 	for {
-		_, msg, err := conn.ReadMessage()
+		msg, err := client.read()
 		if err != nil {
 			h.logger.Error("failed to read message", "error", err)
 
 			return err
 		}
 
-		err = h.handleMessage(c, conn, orderID, user, msg)
+		err = h.handleMessage(c, client, orderID, user, msg)
 		if err != nil {
 			h.logger.Error("failed to handle message", "error", err)
 
@@ -120,7 +432,7 @@ func (h *WebsocketHandler) readMessages(
 
 func (h *WebsocketHandler) handleMessage(
 	c echo.Context,
-	conn *websocket.Conn,
+	client *client,
 	orderID uuid.UUID,
 	user *authDto.TokenClaimsDto,
 	msg []byte,
@@ -128,57 +440,171 @@ func (h *WebsocketHandler) handleMessage(
 	var wsDto dto.WSReqMessage
 
 	err := json.Unmarshal(msg, &wsDto)
+	if err == nil {
+		err = validator.New().Struct(&wsDto)
+	}
+
+	if err != nil {
+		h.nack(client, &wsDto, dto.WSErrMalformedMessage, "failed to parse message envelope")
+
+		return fmt.Errorf("%w: %w", errMalformedMessage, err)
+	}
+
+	if wsDto.V != 0 && wsDto.V != dto.WSProtocolVersion {
+		h.nack(client, &wsDto, dto.WSErrUnsupportedVersion, errUnsupportedVersion.Error())
+
+		return fmt.Errorf("%w: %d", errUnsupportedVersion, wsDto.V)
+	}
+
+	err = h.handleRequest(c.Request().Context(), client, orderID, user, &wsDto)
 	if err != nil {
-		return h.sendMsg(conn, dto.MsgError, "failed to unmarshal message")
+		code, message := wsError(err)
+		h.nack(client, &wsDto, code, message)
+
+		return err
 	}
 
+	return h.send(client, &dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: wsDto.RequestID,
+		Type:      dto.MsgAck,
+		Seq:       0,
+		Data:      dto.WSAckDto{Type: wsDto.Type},
+	})
+}
+
+func (h *WebsocketHandler) handleRequest(
+	ctx context.Context,
+	client *client,
+	orderID uuid.UUID,
+	user *authDto.TokenClaimsDto,
+	wsDto *dto.WSReqMessage,
+) error {
 	switch wsDto.Type {
 	case dto.MsgAddItem:
-		return h.handleAddItem(c.Request().Context(), conn, orderID, wsDto.Data)
+		return h.handleAddItem(ctx, orderID, client.participant, wsDto.Data)
 	case dto.MsgDeleteItem:
-		return h.handleDeleteItem(c.Request().Context(), conn, orderID, wsDto.Data)
+		return h.handleDeleteItem(ctx, orderID, wsDto.Data)
 	case dto.MsgUpdateOrder:
-		return h.handleUpdateOrder(c.Request().Context(), conn, orderID, user, wsDto.Data)
+		return h.handleUpdateOrder(ctx, orderID, user, wsDto.Data)
+	case dto.MsgApplyPromoCode:
+		return h.handleApplyPromoCode(ctx, orderID, wsDto.Data)
+	case dto.MsgRemovePromoCode:
+		return h.handleRemovePromoCode(ctx, orderID)
+	case dto.MsgSync:
+		return h.handleSync(ctx, client, orderID, wsDto.Data)
+	case dto.MsgCallWaiter:
+		return h.requestAssistance(
+			ctx,
+			orderID,
+			client.participant,
+			db.OrdersAssistanceRequestTypeCallWaiter,
+			nil,
+		)
+	case dto.MsgRequestBill:
+		return h.requestAssistance(
+			ctx,
+			orderID,
+			client.participant,
+			db.OrdersAssistanceRequestTypeRequestBill,
+			nil,
+		)
+	case dto.MsgCustomRequest:
+		return h.handleCustomRequest(ctx, orderID, client.participant, wsDto.Data)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownMessageType, wsDto.Type)
+	}
+}
+
+// nack rejects client's request, the client is closed if it can't take the message.
+func (h *WebsocketHandler) nack(
+	client *client,
+	wsDto *dto.WSReqMessage,
+	code dto.WSErrorCode,
+	message string,
+) {
+	err := h.send(client, &dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: wsDto.RequestID,
+		Type:      dto.MsgNack,
+		Seq:       0,
+		Data:      dto.WSErrorDto{Type: wsDto.Type, Code: code, Message: message},
+	})
+	if err != nil {
+		h.logger.Error("failed to send nack", "error", err)
+	}
+}
+
+// wsError maps error of a request to the code and message of its nack. Messages of
+// unexpected errors aren't shown to clients.
+func wsError(err error) (dto.WSErrorCode, string) {
+	switch {
+	case errors.Is(err, errUnknownMessageType):
+		return dto.WSErrUnknownType, errUnknownMessageType.Error()
+	case errors.Is(err, errInvalidMessageData):
+		return dto.WSErrValidationFailed, err.Error()
+	case errors.Is(err, services.ErrPayloadEmpty):
+		return dto.WSErrValidationFailed, services.ErrPayloadEmpty.Error()
+	case errors.Is(err, repository.ErrMenuItemUnavailable),
+		errors.Is(err, repository.ErrMenuItemDoesNotExist),
+		errors.Is(err, services.ErrItemDoesNotBelongToRestaurant):
+		return dto.WSErrItemUnavailable, rootError(err).Error()
+	case errors.Is(err, services.ErrOrderIsNotOpen),
+		errors.Is(err, services.ErrUserCannotEditLockedOrder):
+		return dto.WSErrOrderLocked, rootError(err).Error()
+	case errors.Is(err, services.ErrOrderFinalized):
+		return dto.WSErrOrderFinalized, services.ErrOrderFinalized.Error()
+	case isPromoCodeRejected(err):
+		return dto.WSErrPromoCodeRejected, rootError(err).Error()
+	case errors.Is(err, repository.ErrOrderDoesNotExist),
+		errors.Is(err, repository.ErrOrderItemDoesNotExist),
+		errors.Is(err, repository.ErrOrderHasNoPromoCode):
+		return dto.WSErrNotFound, rootError(err).Error()
+	case errors.Is(err, services.ErrUserCannotEditStatus):
+		return dto.WSErrForbidden, services.ErrUserCannotEditStatus.Error()
+	case errors.Is(err, services.ErrAssistanceRequestedRecently):
+		return dto.WSErrRateLimited, services.ErrAssistanceRequestedRecently.Error()
 	default:
-		return h.sendMsg(conn, dto.MsgError, "unknown request type")
+		return dto.WSErrInternal, "failed to handle request"
+	}
+}
+
+// rootError returns the innermost error wrapped by err, without the context added on the
+// way up.
+func rootError(err error) error {
+	for {
+		wrapped := errors.Unwrap(err)
+		if wrapped == nil {
+			return err
+		}
+
+		err = wrapped
 	}
 }
 
 func (h *WebsocketHandler) handleAddItem(
 	ctx context.Context,
-	conn *websocket.Conn,
 	orderID uuid.UUID,
+	participant *dto.ParticipantDto,
 	data json.RawMessage,
 ) error {
-	h.logger.Info("Order", "orderid", orderID)
-
 	var reqDto dto.OrderItemRequestDto
 
 	err := h.validateDto(data, &reqDto)
 	if err != nil {
-		h.logger.Error("dto validation failed", "error", err)
-		_ = h.sendMsg(conn, dto.MsgError, err.Error())
-
 		return err
 	}
 
-	respDto, err := h.svc.AddItemToOrder(ctx, orderID, reqDto.ItemID)
+	_, err = h.svc.AddItemToOrder(ctx, orderID, reqDto.ItemID, participant)
 	if err != nil {
-		h.logger.Error("failed to add item to order", "error", err)
-
-		_ = h.sendMsg(conn, dto.MsgError, "failed to add item to order")
-
-		return err
+		return fmt.Errorf("adding item to order: %w", err)
 	}
 
-	h.broadcastMessage(orderID, dto.MsgAddItem, respDto)
-
 	return nil
 }
 
 func (h *WebsocketHandler) handleDeleteItem(
 	ctx context.Context,
-	conn *websocket.Conn,
 	orderID uuid.UUID,
 	data json.RawMessage,
 ) error {
@@ -186,28 +612,19 @@ func (h *WebsocketHandler) handleDeleteItem(
 
 	err := h.validateDto(data, &reqDto)
 	if err != nil {
-		h.logger.Error("dto validation failed", "error", err)
-		_ = h.sendMsg(conn, dto.MsgError, err.Error())
-
 		return err
 	}
 
-	respDto, err := h.svc.DeleteOrderItem(ctx, reqDto.ItemID, orderID)
+	_, err = h.svc.DeleteOrderItem(ctx, reqDto.ItemID, orderID)
 	if err != nil {
-		h.logger.Error("failed to delete item from an order", "error", err)
-		_ = h.sendMsg(conn, dto.MsgError, "failed to delete item from an order")
-
-		return err
+		return fmt.Errorf("deleting item from order: %w", err)
 	}
 
-	h.broadcastMessage(orderID, dto.MsgDeleteItem, respDto)
-
 	return nil
 }
 
 func (h *WebsocketHandler) handleUpdateOrder(
 	ctx context.Context,
-	conn *websocket.Conn,
 	orderID uuid.UUID,
 	user *authDto.TokenClaimsDto,
 	data json.RawMessage,
@@ -218,26 +635,194 @@ func (h *WebsocketHandler) handleUpdateOrder(
 
 	err := h.validateDto(data, &reqDto)
 	if err != nil {
-		h.logger.Error("dto validation failed", "error", err)
-		_ = h.sendMsg(conn, dto.MsgError, err.Error())
+		return err
+	}
+
+	_, err = h.svc.UpdateOrder(ctx, &reqDto, user)
+	if err != nil {
+		return fmt.Errorf("updating order: %w", err)
+	}
+
+	return nil
+}
+
+func (h *WebsocketHandler) handleApplyPromoCode(
+	ctx context.Context,
+	orderID uuid.UUID,
+	data json.RawMessage,
+) error {
+	var reqDto dto.ApplyPromoCodeRequestDto
 
+	err := h.validateDto(data, &reqDto)
+	if err != nil {
 		return err
 	}
 
-	respDto, err := h.svc.UpdateOrder(ctx, &reqDto, user)
+	reqDto.OrderID = orderID
+
+	_, err = h.svc.ApplyPromoCode(ctx, &reqDto)
+	if err != nil {
+		return fmt.Errorf("applying promo code: %w", err)
+	}
+
+	return nil
+}
+
+func (h *WebsocketHandler) handleRemovePromoCode(ctx context.Context, orderID uuid.UUID) error {
+	_, err := h.svc.RemovePromoCode(ctx, orderID)
 	if err != nil {
-		h.logger.Error("failed to update order", "error", err)
-		_ = h.sendMsg(conn, dto.MsgError, "failed to update an order")
+		return fmt.Errorf("removing promo code: %w", err)
+	}
 
+	return nil
+}
+
+func (h *WebsocketHandler) handleCustomRequest(
+	ctx context.Context,
+	orderID uuid.UUID,
+	participant *dto.ParticipantDto,
+	data json.RawMessage,
+) error {
+	var reqDto dto.CustomAssistanceRequestDto
+
+	err := h.validateDto(data, &reqDto)
+	if err != nil {
 		return err
 	}
 
-	h.broadcastMessage(orderID, dto.MsgUpdateOrder, respDto)
+	return h.requestAssistance(
+		ctx,
+		orderID,
+		participant,
+		db.OrdersAssistanceRequestTypeCustom,
+		&reqDto.Message,
+	)
+}
+
+// requestAssistance sends participant's request for assistance to staff of order's
+// restaurant.
+func (h *WebsocketHandler) requestAssistance(
+	ctx context.Context,
+	orderID uuid.UUID,
+	participant *dto.ParticipantDto,
+	requestType db.OrdersAssistanceRequestType,
+	message *string,
+) error {
+	_, err := h.assistance.RequestParticipantAssistance(ctx, &dto.CreateAssistanceRequestDto{
+		OrderID:      orderID,
+		Type:         requestType,
+		Message:      message,
+		RequestedBy:  participant,
+		SessionToken: "",
+	})
+	if err != nil {
+		return fmt.Errorf("requesting assistance: %w", err)
+	}
+
+	return nil
+}
+
+func (h *WebsocketHandler) handleSync(
+	ctx context.Context,
+	client *client,
+	orderID uuid.UUID,
+	data json.RawMessage,
+) error {
+	var reqDto dto.SyncRequestDto
+
+	if len(data) > 0 {
+		err := h.validateDto(data, &reqDto)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := h.sync(ctx, client, orderID, reqDto.LastSeq)
+	if err != nil {
+		return fmt.Errorf("syncing order: %w", err)
+	}
 
 	return nil
 }
 
-func (h *WebsocketHandler) joinOrder(orderID uuid.UUID, conn *websocket.Conn) {
+// sync replays events the client missed after lastSeq. The whole order is sent instead in
+// a sync message when lastSeq is nil, the events are no longer kept or there are more of
+// them than the client's queue has room for.
+func (h *WebsocketHandler) sync(
+	ctx context.Context,
+	client *client,
+	orderID uuid.UUID,
+	lastSeq *int64,
+) error {
+	if lastSeq != nil {
+		missed, err := h.events.EventsAfter(ctx, orderID, *lastSeq)
+		if err != nil && !errors.Is(err, events.ErrEventsUnavailable) {
+			return fmt.Errorf("getting missed order events: %w", err)
+		}
+
+		if err == nil && canReplay(missed, client.free()) {
+			for _, event := range missed {
+				err = h.send(client, eventMessage(event, event.Order))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	// sequence number is read before the order, so the order is at least as new as it
+	seq, err := h.events.LastSeq(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("getting last order event: %w", err)
+	}
+
+	order, err := h.svc.GetOrder(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("getting order: %w", err)
+	}
+
+	return h.send(client, &dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: "",
+		Type:      dto.MsgSync,
+		Seq:       seq,
+		Data:      order,
+	})
+}
+
+// canReplay reports whether the missed events carry their orders and fit into the room
+// left in client's queue.
+func canReplay(missed []*events.Event, room int) bool {
+	if len(missed) > room {
+		return false
+	}
+
+	for _, event := range missed {
+		if event.Order == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseLastSeq(c echo.Context) (*int64, error) {
+	param := c.QueryParam(lastSeqQueryParam)
+	if param == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	lastSeq, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || lastSeq < 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidLastSeq, param)
+	}
+
+	return &lastSeq, nil
+}
+
+func (h *WebsocketHandler) joinOrder(orderID uuid.UUID, client *client) {
 	inner, _ := h.orderConns.LoadOrStore(orderID, &sync.Map{})
 
 	innerMap, ok := inner.(*sync.Map)
@@ -247,10 +832,10 @@ func (h *WebsocketHandler) joinOrder(orderID uuid.UUID, conn *websocket.Conn) {
 		return
 	}
 
-	innerMap.Store(conn, struct{}{})
+	innerMap.Store(client, struct{}{})
 }
 
-func (h *WebsocketHandler) leaveOrder(orderID uuid.UUID, conn *websocket.Conn) {
+func (h *WebsocketHandler) leaveOrder(orderID uuid.UUID, client *client) {
 	inner, ok := h.orderConns.Load(orderID)
 	if !ok {
 		return
@@ -263,7 +848,7 @@ func (h *WebsocketHandler) leaveOrder(orderID uuid.UUID, conn *websocket.Conn) {
 		return
 	}
 
-	innerMap.Delete(conn)
+	innerMap.Delete(client)
 
 	empty := true
 
@@ -278,11 +863,82 @@ func (h *WebsocketHandler) leaveOrder(orderID uuid.UUID, conn *websocket.Conn) {
 	}
 }
 
-func (h *WebsocketHandler) broadcastMessage(
-	orderID uuid.UUID,
-	msgType dto.WSMessageType,
-	data any,
-) {
+// broadcastEvent sends the changed order to connections of the order on this instance.
+// Events published without the order are sent with the order loaded again. Staff events
+// are left to staff feeds.
+func (h *WebsocketHandler) broadcastEvent(ctx context.Context, event *events.Event) {
+	if event.IsStaff() {
+		return
+	}
+
+	if event.IsPresence() {
+		h.updatePresence(event)
+
+		return
+	}
+
+	_, ok := h.orderConns.Load(event.OrderID)
+	if !ok {
+		return
+	}
+
+	order := event.Order
+	if order == nil {
+		var err error
+
+		order, err = h.svc.GetOrder(ctx, event.OrderID)
+		if err != nil {
+			h.logger.Error("failed to load order of event", "orderID", event.OrderID, "error", err)
+
+			return
+		}
+	}
+
+	h.broadcastMessage(event.OrderID, eventMessage(event, order))
+}
+
+// updatePresence counts participant's connections to the order on every instance, clients
+// are told when participant joins with the first connection and leaves with the last one.
+// Joined events of connections already counted are heartbeats, they keep them present.
+func (h *WebsocketHandler) updatePresence(event *events.Event) {
+	if event.Participant == nil {
+		return
+	}
+
+	changed := false
+
+	switch event.Type {
+	case dto.MsgParticipantJoined:
+		changed = h.presence.join(event.OrderID, event.Participant, event.ConnectionID, time.Now())
+	case dto.MsgParticipantLeft:
+		changed = h.presence.leave(event.OrderID, event.Participant.ID, event.ConnectionID)
+	default:
+	}
+
+	if changed {
+		h.broadcastMessage(event.OrderID, &dto.WSRespMessage{
+			V:         dto.WSProtocolVersion,
+			RequestID: "",
+			Type:      event.Type,
+			Seq:       0,
+			Data:      event.Participant,
+		})
+	}
+}
+
+func eventMessage(event *events.Event, order *dto.OrderDto) *dto.WSRespMessage {
+	return &dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: "",
+		Type:      event.Type,
+		Seq:       event.Seq,
+		Data:      order,
+	}
+}
+
+// broadcastMessage queues the message to every client of the order. Clients whose queue is
+// full are too slow to keep up, they are evicted and have to reconnect.
+func (h *WebsocketHandler) broadcastMessage(orderID uuid.UUID, resp *dto.WSRespMessage) {
 	inner, ok := h.orderConns.Load(orderID)
 	if !ok {
 		return
@@ -295,67 +951,88 @@ func (h *WebsocketHandler) broadcastMessage(
 		return
 	}
 
+	msg, err := marshalMsg(resp)
+	if err != nil {
+		h.logger.Error("failed to marshal broadcast message", "orderID", orderID, "error", err)
+
+		return
+	}
+
 	clientsMap.Range(func(key, _ any) bool {
-		client, ok := key.(*websocket.Conn)
+		client, ok := key.(*client)
 		if !ok {
 			h.logger.Error(
 				"broadcastMessage: unexpected type stored in connection clients",
 				"orderID",
 				orderID,
 			)
-			clientsMap.Delete(client)
+			clientsMap.Delete(key)
 
 			return true
 		}
 
-		err := h.sendMsg(client, msgType, data)
+		err := client.enqueue(msg)
 		if err != nil {
-			h.logger.Error("failed to send message to client", "error", err)
-
-			closeErr := client.Close()
-			if closeErr != nil {
-				h.logger.Error("failed to close client", "error", closeErr)
-			}
+			h.logger.Warn("evicting websocket client", "orderID", orderID, "error", err)
 
-			clientsMap.Delete(client)
+			client.close()
+			h.leaveOrder(orderID, client)
 		}
 
 		return true
 	})
 }
 
+// sendMsg queues the message to a single client, slow client is closed the same way as
+// when broadcasting.
 func (h *WebsocketHandler) sendMsg(
-	conn *websocket.Conn,
+	client *client,
 	msgType dto.WSMessageType,
 	data any,
 ) error {
-	respDto := &dto.WSRespMessage{
-		Type: msgType,
-		Data: data,
-	}
+	return h.send(client, &dto.WSRespMessage{
+		V:         dto.WSProtocolVersion,
+		RequestID: "",
+		Type:      msgType,
+		Seq:       0,
+		Data:      data,
+	})
+}
 
-	respJSON, err := json.Marshal(respDto)
+func (h *WebsocketHandler) send(client *client, resp *dto.WSRespMessage) error {
+	msg, err := marshalMsg(resp)
 	if err != nil {
-		return fmt.Errorf("marshaling response to json: %w", err)
+		return err
 	}
 
-	err = conn.WriteMessage(websocket.TextMessage, respJSON)
+	err = client.enqueue(msg)
 	if err != nil {
-		return fmt.Errorf("writing message to client: %w", err)
+		client.close()
+
+		return fmt.Errorf("sending message to client: %w", err)
 	}
 
 	return nil
 }
 
+func marshalMsg(resp *dto.WSRespMessage) ([]byte, error) {
+	respJSON, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling response to json: %w", err)
+	}
+
+	return respJSON, nil
+}
+
 func (h *WebsocketHandler) validateDto(data json.RawMessage, dto any) error {
 	err := json.Unmarshal(data, &dto)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal message: %w", err)
+		return fmt.Errorf("%w: failed to unmarshal message: %w", errInvalidMessageData, err)
 	}
 
 	err = validator.New().Struct(dto)
 	if err != nil {
-		return fmt.Errorf("dto validation failed: %w", err)
+		return fmt.Errorf("%w: dto validation failed: %w", errInvalidMessageData, err)
 	}
 
 	return nil
@@ -7,12 +7,18 @@ import (
 	"fmt"
 	"golang-dining-ordering/config"
 	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/management/middleware"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
 	"golang-dining-ordering/services/orders/services"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -30,19 +36,38 @@ type websocketsHandlerTestSuite struct {
 
 func (suite *websocketsHandlerTestSuite) SetupSuite() {
 	mockOrdersRepo := mock.NewMockOrdersRepo()
-	svc := services.NewOrdersService(mockOrdersRepo)
+	svc := services.NewOrdersService(
+		mockOrdersRepo,
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
 
-	cfg := &config.WebsocketConfig{
-		HandshakeTimeout: 5,
-		ReadBufferSize:   1024,
-		WriteBufferSize:  1024,
-	}
+	cfg := newTestWebsocketConfig()
 
 	buf := &bytes.Buffer{}
 	noopHandler := slog.NewTextHandler(buf, nil)
 	logger := slog.New(noopHandler)
 
-	suite.handler = NewWebsocketHandler(svc, cfg, logger)
+	suite.handler = NewWebsocketHandler(
+		svc,
+		newTestAssistanceService(events.NewMemoryPubSub(10)),
+		events.NewMemoryPubSub(10),
+		cfg,
+		logger,
+	)
+}
+
+// newTestAssistanceService returns assistance service publishing to staff feeds with the
+// publisher.
+func newTestAssistanceService(publisher events.Publisher) services.AssistanceService {
+	return services.NewAssistanceService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockAssistanceRepo(),
+		publisher,
+		mock.NewTableSessions(),
+	)
 }
 
 func TestWebsocketsHandlerTestSuite(t *testing.T) {
@@ -50,6 +75,31 @@ func TestWebsocketsHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(websocketsHandlerTestSuite))
 }
 
+func newTestWebsocketConfig() *config.WebsocketConfig {
+	return &config.WebsocketConfig{
+		HandshakeTimeout: 5,
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+		WriteTimeout:     1,
+		PongTimeout:      5,
+		MaxMessageSize:   4096,
+		SendQueueSize:    2,
+	}
+}
+
+// testSessionToken returns table session token for the order.
+func (suite *websocketsHandlerTestSuite) testSessionToken(orderID uuid.UUID) string {
+	session, err := mock.NewTableSessions().Issue(orderID, uuid.New())
+	suite.Require().NoError(err)
+
+	return session.Token
+}
+
+// newTestClient returns a client without connection, its messages stay in the send queue.
+func (suite *websocketsHandlerTestSuite) newTestClient() *client {
+	return newClient(&websocket.Conn{}, suite.handler.clientCfg)
+}
+
 func countConnections(orderConns *sync.Map) int {
 	count := 0
 
@@ -65,7 +115,7 @@ func countConnections(orderConns *sync.Map) int {
 func (suite *websocketsHandlerTestSuite) TestJoinOrder() {
 	orderID := uuid.New()
 
-	conn1 := &websocket.Conn{}
+	conn1 := suite.newTestClient()
 
 	suite.handler.joinOrder(orderID, conn1)
 
@@ -73,7 +123,7 @@ func (suite *websocketsHandlerTestSuite) TestJoinOrder() {
 	suite.Require().True(ok)
 	suite.Equal(1, countConnections(conns.(*sync.Map)))
 
-	conn2 := &websocket.Conn{}
+	conn2 := suite.newTestClient()
 	suite.handler.joinOrder(orderID, conn2)
 	conns, ok = suite.handler.orderConns.Load(orderID)
 	suite.Require().True(ok)
@@ -83,8 +133,8 @@ func (suite *websocketsHandlerTestSuite) TestJoinOrder() {
 func (suite *websocketsHandlerTestSuite) TestLeaveOrder() {
 	orderID := uuid.New()
 
-	conn1 := &websocket.Conn{}
-	conn2 := &websocket.Conn{}
+	conn1 := suite.newTestClient()
+	conn2 := suite.newTestClient()
 
 	suite.handler.joinOrder(orderID, conn1)
 	suite.handler.joinOrder(orderID, conn2)
@@ -140,7 +190,6 @@ func (suite *websocketsHandlerTestSuite) TestHandleUpdateOrder_Success() {
 
 	err := suite.handler.handleUpdateOrder(
 		context.Background(),
-		&websocket.Conn{},
 		testOrderID,
 		&authDto.TokenClaimsDto{},
 		data,
@@ -153,7 +202,6 @@ func (suite *websocketsHandlerTestSuite) TestHandleDeleteItemdateOrder_Success()
 
 	err := suite.handler.handleDeleteItem(
 		context.Background(),
-		&websocket.Conn{},
 		testOrderID,
 		data,
 	)
@@ -165,8 +213,8 @@ func (suite *websocketsHandlerTestSuite) TestHandleAddItem_Success() {
 
 	err := suite.handler.handleAddItem(
 		context.Background(),
-		&websocket.Conn{},
 		testOrderID,
+		nil,
 		data,
 	)
 	suite.Require().NoError(err)
@@ -199,7 +247,7 @@ func (suite *websocketsHandlerTestSuite) TesthandleMessage_Success() {
 
 		err := suite.handler.handleMessage(
 			c,
-			&websocket.Conn{},
+			suite.newTestClient(),
 			testOrderID,
 			&authDto.TokenClaimsDto{},
 			msg,
@@ -211,6 +259,7 @@ func (suite *websocketsHandlerTestSuite) TesthandleMessage_Success() {
 func (suite *websocketsHandlerTestSuite) TestHandleOrderWebsocket_BadRequest() {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set(sessionTokenHeader, suite.testSessionToken(testOrderID))
 	rec := httptest.NewRecorder()
 
 	c := e.NewContext(req, rec)
@@ -237,3 +286,697 @@ func (suite *websocketsHandlerTestSuite) TestHandleOrderWebsocket_BadRequest() {
 		suite.Contains(err.Error(), tt.expectedError)
 	}
 }
+
+// subscribedPubSub signals when the handler subscribes, so tests publish only after that.
+type subscribedPubSub struct {
+	events.PubSub
+
+	subscribed chan struct{}
+}
+
+func (p *subscribedPubSub) Subscribe(ctx context.Context) (<-chan *events.Event, error) {
+	subscription, err := p.PubSub.Subscribe(ctx)
+	close(p.subscribed)
+
+	return subscription, err
+}
+
+func (suite *websocketsHandlerTestSuite) TestRun_BroadcastsOrderEvents() {
+	pubsub := &subscribedPubSub{PubSub: events.NewMemoryPubSub(10), subscribed: make(chan struct{})}
+	svc := services.NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		pubsub,
+		mock.NewTableSessions(),
+	)
+	handler := NewWebsocketHandler(
+		svc,
+		newTestAssistanceService(pubsub),
+		pubsub,
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = handler.Run(ctx) }()
+
+	e := echo.New()
+	e.GET("/orders/:order_id/ws", handler.HandleOrderWebsocket)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/" + testOrderID.String() +
+		"/ws?display_name=Tom&session_token=" + suite.testSessionToken(testOrderID)
+
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	suite.Require().NoError(err)
+
+	defer func() { _ = conn.Close() }()
+	defer func() { _ = resp.Body.Close() }()
+
+	<-pubsub.subscribed
+	suite.Eventually(func() bool {
+		_, ok := handler.orderConns.Load(testOrderID)
+
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	var msg struct {
+		Type      string          `json:"type"`
+		RequestID string          `json:"request_id"`
+		Seq       int64           `json:"seq"`
+		Data      json.RawMessage `json:"data"`
+	}
+
+	// participants present when connecting, then the client itself joining
+	suite.Require().NoError(conn.SetReadDeadline(time.Now().Add(time.Second)))
+	suite.Require().NoError(conn.ReadJSON(&msg))
+	suite.Equal("presence", msg.Type)
+	suite.Require().NoError(conn.ReadJSON(&msg))
+	suite.Equal("participant_joined", msg.Type)
+	suite.Contains(string(msg.Data), `"display_name":"Tom"`)
+
+	// change made through REST on another instance, the order is loaded again
+	pubsub.Publish(context.Background(), events.NewOrderChangedEvent(testOrderID))
+
+	suite.Require().NoError(conn.ReadJSON(&msg))
+	suite.Equal("update_order", msg.Type)
+	suite.Equal(int64(1), msg.Seq)
+	suite.Contains(string(msg.Data), testOrderID.String())
+
+	// change made through this connection
+	err = conn.WriteJSON(map[string]any{
+		"request_id": "add-1",
+		"type":       "add_item",
+		"data":       map[string]string{"item_id": testItemID.String()},
+	})
+	suite.Require().NoError(err)
+
+	// the ack and the event are sent from different goroutines, in either order
+	acked := false
+
+	for range 2 {
+		suite.Require().NoError(conn.ReadJSON(&msg))
+
+		if msg.Type == "ack" {
+			suite.Equal("add-1", msg.RequestID)
+
+			acked = true
+
+			continue
+		}
+
+		suite.Equal("add_item", msg.Type)
+		suite.Equal(int64(2), msg.Seq)
+		suite.Contains(string(msg.Data), testItemName)
+		suite.Contains(string(msg.Data), `"display_name":"Tom"`)
+	}
+
+	suite.True(acked)
+}
+
+func (suite *websocketsHandlerTestSuite) TestUpdatePresence() {
+	orderID := uuid.New()
+	watcher := suite.newTestClient()
+	suite.handler.joinOrder(orderID, watcher)
+
+	defer suite.handler.leaveOrder(orderID, watcher)
+
+	participant := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Tom",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+	firstConn, secondConn := uuid.New(), uuid.New()
+	joinedFirst := events.NewPresenceEvent(
+		dto.MsgParticipantJoined, orderID, participant, firstConn,
+	)
+	joinedSecond := events.NewPresenceEvent(
+		dto.MsgParticipantJoined, orderID, participant, secondConn,
+	)
+	leftFirst := events.NewPresenceEvent(dto.MsgParticipantLeft, orderID, participant, firstConn)
+	leftSecond := events.NewPresenceEvent(dto.MsgParticipantLeft, orderID, participant, secondConn)
+
+	tests := []struct {
+		name      string
+		event     *events.Event
+		wantMsgs  []dto.WSMessageType
+		wantCount int
+	}{
+		{"first connection joins", joinedFirst, []dto.WSMessageType{dto.MsgParticipantJoined}, 1},
+		{"second connection", joinedSecond, nil, 1},
+		{"heartbeat of connection", joinedSecond, nil, 1},
+		{"one of connections closes", leftFirst, nil, 1},
+		{"closed connection closes again", leftFirst, nil, 1},
+		{"last connection closes", leftSecond, []dto.WSMessageType{dto.MsgParticipantLeft}, 0},
+		{"unknown participant leaves", leftSecond, nil, 0},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.handler.broadcastEvent(context.Background(), tt.event)
+
+			msgs := suite.readQueued(watcher)
+			suite.Require().Len(msgs, len(tt.wantMsgs))
+
+			for i, msg := range msgs {
+				suite.Equal(tt.wantMsgs[i], msg.Type)
+				suite.Equal(int64(0), msg.Seq)
+			}
+
+			suite.Len(suite.handler.presence.participants(orderID), tt.wantCount)
+		})
+	}
+}
+
+func (suite *websocketsHandlerTestSuite) TestExpirePresence() {
+	orderID := uuid.New()
+	watcher := suite.newTestClient()
+	suite.handler.joinOrder(orderID, watcher)
+
+	defer suite.handler.leaveOrder(orderID, watcher)
+
+	crashed := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Tom",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+	announced := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Ann",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+
+	announcedConn := uuid.New()
+	start := time.Now()
+	suite.handler.presence.join(orderID, crashed, uuid.New(), start)
+	suite.handler.presence.join(orderID, announced, announcedConn, start)
+
+	// only the connection of announced participant keeps getting heartbeats
+	suite.handler.presence.join(
+		orderID, announced, announcedConn, start.Add(presenceHeartbeatPeriod),
+	)
+
+	suite.handler.expirePresence(start.Add(presenceTTL + time.Second))
+
+	msgs := suite.readQueued(watcher)
+	suite.Require().Len(msgs, 1)
+	suite.Equal(dto.MsgParticipantLeft, msgs[0].Type)
+
+	data, ok := msgs[0].Data.(map[string]any)
+	suite.Require().True(ok)
+	suite.Equal(crashed.ID.String(), data["id"])
+
+	participants := suite.handler.presence.participants(orderID)
+	suite.Require().Len(participants, 1)
+	suite.Equal(announced.ID, participants[0].ID)
+}
+
+func (suite *websocketsHandlerTestSuite) TestBroadcastMessage_EvictsSlowClient() {
+	orderID := uuid.New()
+
+	slow := suite.newTestClient()
+	fast := newClient(&websocket.Conn{}, &clientConfig{
+		writeTimeout:   time.Second,
+		pongTimeout:    time.Second,
+		pingInterval:   time.Second,
+		maxMessageSize: 4096,
+		sendQueueSize:  10,
+	})
+
+	suite.handler.joinOrder(orderID, slow)
+	suite.handler.joinOrder(orderID, fast)
+
+	for range 3 {
+		msg := &dto.WSRespMessage{Type: dto.MsgUpdateOrder, Seq: 0, Data: nil}
+		suite.handler.broadcastMessage(orderID, msg)
+	}
+
+	conns, ok := suite.handler.orderConns.Load(orderID)
+	suite.Require().True(ok)
+	suite.Equal(1, countConnections(conns.(*sync.Map)))
+
+	_, ok = conns.(*sync.Map).Load(fast)
+	suite.True(ok)
+	suite.Len(fast.send, 3)
+
+	select {
+	case <-slow.done:
+	default:
+		suite.Fail("slow client wasn't closed")
+	}
+
+	suite.Require().ErrorIs(slow.enqueue([]byte("{}")), errClientClosed)
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleOrderWebsocket_Heartbeats() {
+	cfg := newTestWebsocketConfig()
+	cfg.PongTimeout = 1
+
+	handler := NewWebsocketHandler(
+		suite.handler.svc,
+		suite.handler.assistance,
+		events.NewMemoryPubSub(10),
+		cfg,
+		slog.New(slog.DiscardHandler),
+	)
+
+	e := echo.New()
+	e.GET("/orders/:order_id/ws", handler.HandleOrderWebsocket)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	alive := uuid.New()
+	dead := uuid.New()
+
+	dial := func(orderID uuid.UUID) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/" + orderID.String() +
+			"/ws?session_token=" + suite.testSessionToken(orderID)
+
+		conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		suite.Require().NoError(err)
+		suite.Require().NoError(resp.Body.Close())
+
+		return conn
+	}
+
+	// reading connection answers pings with pongs, the other one never reads
+	aliveConn := dial(alive)
+	defer func() { _ = aliveConn.Close() }()
+
+	pinged := make(chan struct{}, 10)
+
+	aliveConn.SetPingHandler(func(data string) error {
+		pinged <- struct{}{}
+
+		deadline := time.Now().Add(time.Second)
+
+		return aliveConn.WriteControl(websocket.PongMessage, []byte(data), deadline)
+	})
+
+	go func() {
+		for {
+			_, _, err := aliveConn.ReadMessage()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	deadConn := dial(dead)
+	defer func() { _ = deadConn.Close() }()
+
+	suite.Eventually(func() bool {
+		_, ok := handler.orderConns.Load(dead)
+
+		return !ok
+	}, 3*time.Second, 50*time.Millisecond)
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		suite.Fail("connection wasn't pinged")
+	}
+
+	_, ok := handler.orderConns.Load(alive)
+	suite.True(ok)
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleOrderWebsocket_Authorization() {
+	tests := []struct {
+		name         string
+		sessionToken string
+		user         *authDto.TokenClaimsDto
+		wantCode     int
+	}{
+		{"no session or staff token", "", &authDto.TokenClaimsDto{}, http.StatusUnauthorized},
+		{"invalid session token", "invalid", &authDto.TokenClaimsDto{}, http.StatusUnauthorized},
+		{
+			"session of another order",
+			suite.testSessionToken(uuid.New()),
+			&authDto.TokenClaimsDto{},
+			http.StatusForbidden,
+		},
+		{
+			"staff of another restaurant",
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+			http.StatusForbidden,
+		},
+		// authorized connections fail only later, because test request isn't a websocket
+		{
+			"session of the order",
+			suite.testSessionToken(testOrderID),
+			&authDto.TokenClaimsDto{},
+			http.StatusBadRequest,
+		},
+		{
+			"staff of order's restaurant",
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserID},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/?session_token="+tt.sessionToken, nil)
+			rec := httptest.NewRecorder()
+
+			c := e.NewContext(req, rec)
+			c.SetParamNames(orderIDParamName)
+			c.SetParamValues(testOrderID.String())
+			c.Set(middleware.ContextKeyAuthUser, tt.user)
+
+			err := suite.handler.HandleOrderWebsocket(c)
+			suite.Require().Error(err)
+			suite.Equal(tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func (suite *websocketsHandlerTestSuite) TestOriginChecker() {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"no origin", nil, "", true},
+		{"same origin", nil, "http://api.dine.local", true},
+		{"other origin", nil, "https://evil.example", false},
+		{"allowed origin", []string{" https://dine.example/ "}, "https://DINE.example", true},
+		{"not allowed origin", []string{"https://dine.example"}, "https://evil.example", false},
+		{"any origin", []string{"*"}, "https://evil.example", true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			req := httptest.NewRequest(http.MethodGet, "http://api.dine.local/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			suite.Equal(tt.want, newOriginChecker(tt.allowed)(req))
+		})
+	}
+}
+
+// readQueued decodes the messages queued to the client.
+func (suite *websocketsHandlerTestSuite) readQueued(client *client) []*dto.WSRespMessage {
+	var msgs []*dto.WSRespMessage
+
+	for len(client.send) > 0 {
+		var msg dto.WSRespMessage
+
+		suite.Require().NoError(json.Unmarshal(<-client.send, &msg))
+		msgs = append(msgs, &msg)
+	}
+
+	return msgs
+}
+
+func (suite *websocketsHandlerTestSuite) TestSync() {
+	pubsub := events.NewMemoryPubSub(10)
+	handler := NewWebsocketHandler(
+		suite.handler.svc,
+		newTestAssistanceService(pubsub),
+		pubsub,
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	order := &dto.OrderDto{ID: testOrderID}
+
+	for range 3 {
+		pubsub.Publish(context.Background(), events.NewOrderEvent(dto.MsgAddItem, order))
+	}
+
+	lastSeq := func(seq int64) *int64 { return &seq }
+
+	tests := []struct {
+		name     string
+		lastSeq  *int64
+		wantType dto.WSMessageType
+		wantSeqs []int64
+	}{
+		{"missed events are replayed", lastSeq(1), dto.MsgAddItem, []int64{2, 3}},
+		{"nothing missed", lastSeq(3), "", nil},
+		{"more missed events than queue holds", lastSeq(0), dto.MsgSync, []int64{3}},
+		{"events are no longer kept", lastSeq(10), dto.MsgSync, []int64{3}},
+		{"no last seq", nil, dto.MsgSync, []int64{3}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			client := newClient(&websocket.Conn{}, handler.clientCfg)
+
+			err := handler.sync(context.Background(), client, testOrderID, tt.lastSeq)
+			suite.Require().NoError(err)
+
+			msgs := suite.readQueued(client)
+			suite.Require().Len(msgs, len(tt.wantSeqs))
+
+			for i, msg := range msgs {
+				suite.Equal(tt.wantType, msg.Type)
+				suite.Equal(tt.wantSeqs[i], msg.Seq)
+				suite.NotNil(msg.Data)
+			}
+		})
+	}
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleMessage_Acknowledgements() {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	tests := []struct {
+		name     string
+		orderID  uuid.UUID
+		msg      string
+		wantType dto.WSMessageType
+		wantCode dto.WSErrorCode
+	}{
+		{
+			"request is acknowledged",
+			testOrderID,
+			fmt.Sprintf(
+				`{"v":1,"request_id":"r1","type":"add_item","data":{"item_id":"%s"}}`,
+				testItemID,
+			),
+			dto.MsgAck,
+			"",
+		},
+		{
+			"malformed envelope",
+			testOrderID,
+			`{"request_id":"r1","data":{}}`,
+			dto.MsgNack,
+			dto.WSErrMalformedMessage,
+		},
+		{
+			"unsupported version",
+			testOrderID,
+			`{"v":2,"request_id":"r1","type":"sync"}`,
+			dto.MsgNack,
+			dto.WSErrUnsupportedVersion,
+		},
+		{
+			"unknown type",
+			testOrderID,
+			`{"request_id":"r1","type":"dance"}`,
+			dto.MsgNack,
+			dto.WSErrUnknownType,
+		},
+		{
+			"invalid data",
+			testOrderID,
+			`{"request_id":"r1","type":"sync","data":{"last_seq":-1}}`,
+			dto.MsgNack,
+			dto.WSErrValidationFailed,
+		},
+		{
+			"item unavailable",
+			testOrderID,
+			fmt.Sprintf(
+				`{"request_id":"r1","type":"add_item","data":{"item_id":"%s"}}`,
+				testUnavailableItemID,
+			),
+			dto.MsgNack,
+			dto.WSErrItemUnavailable,
+		},
+		{
+			"order locked",
+			testCompletedOrderID,
+			fmt.Sprintf(
+				`{"request_id":"r1","type":"add_item","data":{"item_id":"%s"}}`,
+				testItemID,
+			),
+			dto.MsgNack,
+			dto.WSErrOrderLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			client := suite.newTestClient()
+
+			err := suite.handler.handleMessage(
+				c,
+				client,
+				tt.orderID,
+				&authDto.TokenClaimsDto{},
+				[]byte(tt.msg),
+			)
+			if tt.wantCode == "" {
+				suite.Require().NoError(err)
+			} else {
+				suite.Require().Error(err)
+			}
+
+			msgs := suite.readQueued(client)
+			suite.Require().Len(msgs, 1)
+			suite.Equal(tt.wantType, msgs[0].Type)
+			suite.Equal("r1", msgs[0].RequestID)
+			suite.Equal(dto.WSProtocolVersion, msgs[0].V)
+
+			if tt.wantCode != "" {
+				data, ok := msgs[0].Data.(map[string]any)
+				suite.Require().True(ok)
+				suite.Equal(string(tt.wantCode), data["code"])
+			}
+		})
+	}
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleMessage_AssistanceRequests() {
+	pubsub := events.NewMemoryPubSub(10)
+	handler := NewWebsocketHandler(
+		suite.handler.svc,
+		newTestAssistanceService(pubsub),
+		pubsub,
+		newTestWebsocketConfig(),
+		slog.New(slog.DiscardHandler),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	c := echo.New().NewContext(
+		httptest.NewRequest(http.MethodGet, "/ws", nil),
+		httptest.NewRecorder(),
+	)
+
+	tests := []struct {
+		name     string
+		orderID  uuid.UUID
+		msg      string
+		wantType db.OrdersAssistanceRequestType
+		wantCode dto.WSErrorCode
+	}{
+		{
+			"call waiter",
+			testOrderID,
+			`{"request_id":"r1","type":"call_waiter"}`,
+			db.OrdersAssistanceRequestTypeCallWaiter,
+			"",
+		},
+		{
+			"request bill",
+			testOrderID,
+			`{"request_id":"r1","type":"request_bill"}`,
+			db.OrdersAssistanceRequestTypeRequestBill,
+			"",
+		},
+		{
+			"custom request",
+			testOrderID,
+			`{"request_id":"r1","type":"custom_request","data":{"message":"high chair"}}`,
+			db.OrdersAssistanceRequestTypeCustom,
+			"",
+		},
+		{
+			"custom request without message",
+			testOrderID,
+			`{"request_id":"r1","type":"custom_request","data":{"message":""}}`,
+			"",
+			dto.WSErrValidationFailed,
+		},
+		{
+			"call waiter again",
+			testOrderID,
+			`{"request_id":"r1","type":"call_waiter"}`,
+			"",
+			dto.WSErrRateLimited,
+		},
+		{
+			"order does not exist",
+			uuid.New(),
+			`{"request_id":"r1","type":"call_waiter"}`,
+			"",
+			dto.WSErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			client := newClient(&websocket.Conn{}, handler.clientCfg)
+
+			err := handler.handleMessage(
+				c,
+				client,
+				tt.orderID,
+				&authDto.TokenClaimsDto{},
+				[]byte(tt.msg),
+			)
+
+			msgs := suite.readQueued(client)
+			suite.Require().Len(msgs, 1)
+
+			if tt.wantCode != "" {
+				suite.Require().Error(err)
+				suite.Equal(dto.MsgNack, msgs[0].Type)
+
+				data, ok := msgs[0].Data.(map[string]any)
+				suite.Require().True(ok)
+				suite.Equal(string(tt.wantCode), data["code"])
+
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Equal(dto.MsgAck, msgs[0].Type)
+
+			event := <-published
+			suite.Equal(dto.MsgAssistanceRequested, event.Type)
+			suite.Require().NotNil(event.Staff)
+			suite.Equal(tt.wantType, event.Staff.Assistance.Type)
+		})
+	}
+
+	suite.Empty(published)
+}
+
+func (suite *websocketsHandlerTestSuite) TestHandleOrderWebsocket_InvalidLastSeq() {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?last_seq=abc", nil)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetParamNames(orderIDParamName)
+	c.SetParamValues(testOrderID.String())
+
+	err := suite.handler.HandleOrderWebsocket(c)
+	suite.Require().ErrorIs(err, errInvalidLastSeq)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
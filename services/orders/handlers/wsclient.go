@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"golang-dining-ordering/config"
+	"golang-dining-ordering/services/orders/dto"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// errClientQueueFull is returned when a message can't be queued because the client doesn't
+// read messages as fast as they are sent.
+var errClientQueueFull = errors.New("client send queue is full")
+
+// errClientClosed is returned when a message is sent to a client that was already closed.
+var errClientClosed = errors.New("client is closed")
+
+// pingIntervalPercent is how often pings are sent, as a percentage of pong timeout, so the
+// next ping arrives before the previous one times out.
+const pingIntervalPercent = 90
+
+// clientConfig holds timeouts and limits of websocket clients.
+type clientConfig struct {
+	writeTimeout   time.Duration
+	pongTimeout    time.Duration
+	pingInterval   time.Duration
+	maxMessageSize int64
+	sendQueueSize  int
+}
+
+func newClientConfig(cfg *config.WebsocketConfig) *clientConfig {
+	pongTimeout := time.Duration(cfg.PongTimeout) * time.Second
+
+	return &clientConfig{
+		writeTimeout:   time.Duration(cfg.WriteTimeout) * time.Second,
+		pongTimeout:    pongTimeout,
+		pingInterval:   pongTimeout * pingIntervalPercent / 100, //nolint:mnd
+		maxMessageSize: cfg.MaxMessageSize,
+		sendQueueSize:  cfg.SendQueueSize,
+	}
+}
+
+// client is a websocket connection to an order. Messages are queued and written by the
+// client's write pump only, since websocket connection doesn't support concurrent writers.
+// Event stream clients have no connection, their messages are written by streamEvents.
+// ID tells client's connection apart in presence of its participant. Participant is who is
+// connected, it's set once the connection is authorized. Close
+// message is what the connection is closed with, it's set once when the client is closed.
+type client struct {
+	id           uuid.UUID
+	conn         *websocket.Conn
+	cfg          *clientConfig
+	send         chan []byte
+	done         chan struct{}
+	closeOnce    sync.Once
+	closeMessage []byte
+	participant  *dto.ParticipantDto
+}
+
+func newClient(conn *websocket.Conn, cfg *clientConfig) *client {
+	return &client{
+		id:           uuid.New(),
+		conn:         conn,
+		cfg:          cfg,
+		send:         make(chan []byte, cfg.sendQueueSize),
+		done:         make(chan struct{}),
+		closeOnce:    sync.Once{},
+		closeMessage: nil,
+		participant:  nil,
+	}
+}
+
+// enqueue queues the message without blocking, it fails when the queue is full or the client
+// is closed.
+func (c *client) enqueue(msg []byte) error {
+	select {
+	case <-c.done:
+		return errClientClosed
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return nil
+	default:
+		return errClientQueueFull
+	}
+}
+
+// free returns how many more messages can be queued before the queue is full.
+func (c *client) free() int {
+	return cap(c.send) - len(c.send)
+}
+
+// close stops the write pump, which closes the connection normally. It's safe to call more
+// than once, only the first call decides how the connection is closed.
+func (c *client) close() {
+	c.closeWith(websocket.CloseNormalClosure, "")
+}
+
+// closeWith stops the write pump, which closes the connection with the code and text.
+func (c *client) closeWith(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeMessage = websocket.FormatCloseMessage(code, text)
+		close(c.done)
+	})
+}
+
+// writePump writes queued messages and pings to the connection until the client is closed
+// or a write fails, then it closes the connection, which makes the reader stop too.
+func (c *client) writePump() error {
+	ticker := time.NewTicker(c.cfg.pingInterval)
+
+	defer func() {
+		ticker.Stop()
+		c.close()
+		_ = c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-c.send:
+			err := c.write(websocket.TextMessage, msg)
+			if err != nil {
+				return fmt.Errorf("writing message to client: %w", err)
+			}
+		case <-ticker.C:
+			err := c.write(websocket.PingMessage, nil)
+			if err != nil {
+				return fmt.Errorf("pinging client: %w", err)
+			}
+		case <-c.done:
+			_ = c.write(websocket.CloseMessage, c.closeMessage)
+
+			return nil
+		}
+	}
+}
+
+func (c *client) write(messageType int, data []byte) error {
+	err := c.conn.SetWriteDeadline(time.Now().Add(c.cfg.writeTimeout))
+	if err != nil {
+		return fmt.Errorf("setting write deadline: %w", err)
+	}
+
+	err = c.conn.WriteMessage(messageType, data)
+	if err != nil {
+		return fmt.Errorf("writing to connection: %w", err)
+	}
+
+	return nil
+}
+
+// read reads the next message. Connection is considered dead when neither a message nor
+// a pong arrives within the pong timeout.
+func (c *client) read() ([]byte, error) {
+	err := c.conn.SetReadDeadline(time.Now().Add(c.cfg.pongTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("setting read deadline: %w", err)
+	}
+
+	_, msg, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf("reading message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// startReading limits size of messages and extends read deadline whenever a pong arrives.
+func (c *client) startReading() {
+	c.conn.SetReadLimit(c.cfg.maxMessageSize)
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.pongTimeout))
+	})
+}
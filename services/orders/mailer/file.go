@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	mailDirectoryPermissions = 0o750
+	mailFilePermissions      = 0o600
+)
+
+type fileMailer struct {
+	directory string
+	from      string
+}
+
+// NewFileMailer creates a mailer which writes emails to .eml files in the directory instead
+// of sending them, it stands in for a real mailer in development.
+//
+//revive:disable:unexported-return
+func NewFileMailer(directory, from string) *fileMailer {
+	return &fileMailer{
+		directory: directory,
+		from:      from,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (m *fileMailer) Send(_ context.Context, message *Message) error {
+	now := time.Now()
+
+	email, err := buildMIME(m.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(m.directory, mailDirectoryPermissions)
+	if err != nil {
+		return fmt.Errorf("creating mail directory: %w", err)
+	}
+
+	filename := fmt.Sprintf(
+		"%s-%s-%s.eml",
+		now.UTC().Format("20060102T150405"),
+		sanitizeFilename(message.To),
+		uuid.NewString()[:8],
+	)
+
+	err = os.WriteFile(filepath.Join(m.directory, filename), email, mailFilePermissions)
+	if err != nil {
+		return fmt.Errorf("writing email file: %w", err)
+	}
+
+	return nil
+}
+
+// sanitizeFilename replaces characters of email address which aren't safe in file names.
+func sanitizeFilename(address string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '@', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, address)
+}
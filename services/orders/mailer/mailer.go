@@ -0,0 +1,191 @@
+// Package mailer sends emails to guests, e.g. receipts of their orders.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/config"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrUnknownMailerType is returned when mailer type in the config is not supported.
+var ErrUnknownMailerType = errors.New("unknown mailer type")
+
+// base64LineLength is the maximum length of base64 encoded lines allowed by RFC 2045.
+const base64LineLength = 76
+
+// Mailer defines method for sending emails.
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// Message is an email with plain text and HTML bodies and optional attachments.
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []*Attachment
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// GetMailer returns the Mailer implementation configured by the mailer type. It returns nil
+// when mailer type is not set, which means emails are disabled.
+//
+//nolint:ireturn
+func GetMailer(cfg *config.MailerConfig) (Mailer, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil //nolint:nilnil
+	case config.MailerTypeFile:
+		return NewFileMailer(cfg.Directory, cfg.From), nil
+	case config.MailerTypeSMTP:
+		return NewSMTPMailer(
+			cfg.SMTPHost,
+			cfg.SMTPPort,
+			cfg.SMTPUsername,
+			cfg.SMTPPassword,
+			cfg.From,
+		), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMailerType, cfg.Type)
+	}
+}
+
+// buildMIME builds the message as multipart MIME email, the bodies are sent as alternatives
+// followed by attachments.
+func buildMIME(from string, message *Message, now time.Time) ([]byte, error) {
+	var (
+		body            bytes.Buffer
+		alternativeBody bytes.Buffer
+	)
+
+	mixed := multipart.NewWriter(&body)
+	alternative := multipart.NewWriter(&alternativeBody)
+
+	err := writePart(alternative, "text/plain; charset=utf-8", "", []byte(message.Text))
+	if err != nil {
+		return nil, err
+	}
+
+	err = writePart(alternative, "text/html; charset=utf-8", "", []byte(message.HTML))
+	if err != nil {
+		return nil, err
+	}
+
+	err = alternative.Close()
+	if err != nil {
+		return nil, fmt.Errorf("closing alternative part: %w", err)
+	}
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating alternative part: %w", err)
+	}
+
+	_, err = part.Write(alternativeBody.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("writing alternative part: %w", err)
+	}
+
+	for _, attachment := range message.Attachments {
+		disposition := mime.FormatMediaType(
+			"attachment",
+			map[string]string{"filename": attachment.Filename},
+		)
+
+		err = writePart(mixed, attachment.ContentType, disposition, attachment.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mixed.Close()
+	if err != nil {
+		return nil, fmt.Errorf("closing message: %w", err)
+	}
+
+	messageID, err := newMessageID(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var email bytes.Buffer
+
+	fmt.Fprintf(&email, "From: %s\r\n", from)
+	fmt.Fprintf(&email, "To: %s\r\n", message.To)
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&email, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&email, "Message-ID: %s\r\n", messageID)
+	email.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&email, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+	email.Write(body.Bytes())
+
+	return email.Bytes(), nil
+}
+
+// writePart writes base64 encoded part of multipart message.
+func writePart(writer *multipart.Writer, contentType, disposition string, data []byte) error {
+	header := textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if disposition != "" {
+		header.Set("Content-Disposition", disposition)
+	}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("creating %s part: %w", contentType, err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	for len(encoded) > 0 {
+		line := encoded[:min(base64LineLength, len(encoded))]
+		encoded = encoded[len(line):]
+
+		_, err = part.Write([]byte(line + "\r\n"))
+		if err != nil {
+			return fmt.Errorf("writing %s part: %w", contentType, err)
+		}
+	}
+
+	return nil
+}
+
+// newMessageID generates unique Message-ID header in the domain of the sender.
+func newMessageID(from string) (string, error) {
+	random := make([]byte, 16) //nolint:mnd
+
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", fmt.Errorf("generating message id: %w", err)
+	}
+
+	domain := "localhost"
+
+	address, err := mail.ParseAddress(from)
+	if err == nil {
+		domain = address.Address[strings.LastIndex(address.Address, "@")+1:]
+	}
+
+	return fmt.Sprintf("<%x@%s>", random, domain), nil
+}
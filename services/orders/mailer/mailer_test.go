@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"context"
+	"golang-dining-ordering/config"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type mailerTestSuite struct {
+	suite.Suite
+}
+
+func TestMailerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(mailerTestSuite))
+}
+
+func (suite *mailerTestSuite) TestGetMailer() {
+	got, err := GetMailer(&config.MailerConfig{}) //nolint:exhaustruct
+	suite.Require().NoError(err)
+	suite.Nil(got)
+
+	got, err = GetMailer(&config.MailerConfig{Type: config.MailerTypeFile}) //nolint:exhaustruct
+	suite.Require().NoError(err)
+	suite.IsType(&fileMailer{}, got) //nolint:exhaustruct
+
+	got, err = GetMailer(&config.MailerConfig{Type: config.MailerTypeSMTP}) //nolint:exhaustruct
+	suite.Require().NoError(err)
+	suite.IsType(&smtpMailer{}, got) //nolint:exhaustruct
+
+	_, err = GetMailer(&config.MailerConfig{Type: "pigeon"}) //nolint:exhaustruct
+	suite.Require().ErrorIs(err, ErrUnknownMailerType)
+}
+
+func (suite *mailerTestSuite) TestFileMailer_Send() {
+	dir := filepath.Join(suite.T().TempDir(), "mail")
+
+	err := NewFileMailer(dir, "Dine <receipts@dine.test>").Send(context.Background(), &Message{
+		To:      "guest@example.com",
+		Subject: "Receipt 000042 from Café",
+		Text:    "Thank you!",
+		HTML:    "<p>Thank you!</p>",
+		Attachments: []*Attachment{{
+			Filename:    "receipt-000042.pdf",
+			ContentType: "application/pdf",
+			Data:        []byte("%PDF-1.4"),
+		}},
+	})
+	suite.Require().NoError(err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-guest@example.com-*.eml"))
+	suite.Require().NoError(err)
+	suite.Require().Len(files, 1)
+
+	file, err := os.Open(files[0])
+	suite.Require().NoError(err)
+
+	defer file.Close()
+
+	message, err := mail.ReadMessage(file)
+	suite.Require().NoError(err)
+	suite.Equal("guest@example.com", message.Header.Get("To"))
+	suite.True(strings.HasSuffix(message.Header.Get("Message-Id"), "@dine.test>"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	suite.Require().NoError(err)
+	suite.Equal("Receipt 000042 from Café", subject)
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	suite.Require().NoError(err)
+	suite.Equal("multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+
+	alternative, err := reader.NextPart()
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(alternative.Header.Get("Content-Type"), "multipart/alternative"))
+
+	attachment, err := reader.NextPart()
+	suite.Require().NoError(err)
+	suite.Equal("receipt-000042.pdf", attachment.FileName())
+
+	data, err := io.ReadAll(attachment)
+	suite.Require().NoError(err)
+	suite.Equal("JVBERi0xLjQ=\r\n", string(data))
+
+	_, err = reader.NextPart()
+	suite.Require().ErrorIs(err, io.EOF)
+}
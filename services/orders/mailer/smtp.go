@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type smtpMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer creates a mailer which sends emails through the SMTP server, the server is
+// authenticated with PLAIN auth when username is set.
+//
+//revive:disable:unexported-return
+func NewSMTPMailer(host string, port int, username, password, from string) *smtpMailer {
+	return &smtpMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (m *smtpMailer) Send(_ context.Context, message *Message) error {
+	email, err := buildMIME(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	err = smtp.SendMail(m.addr, auth, m.from, []string{message.To}, email)
+	if err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+
+	return nil
+}
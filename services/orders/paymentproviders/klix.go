@@ -0,0 +1,545 @@
+package paymentproviders
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrKlixRequestFailed is returned when Klix API responds with non successful status.
+	ErrKlixRequestFailed = errors.New("klix request failed")
+	// ErrInvalidKlixPublicKey is returned when configured Klix public key is not a PEM encoded RSA key.
+	ErrInvalidKlixPublicKey = errors.New("invalid klix public key")
+	// ErrPurchaseNotPaid is returned when Klix callback is received for a purchase that is not paid.
+	ErrPurchaseNotPaid = errors.New("klix purchase is not paid")
+)
+
+const (
+	// KlixSignatureHeader is the header Klix callbacks are signed with.
+	KlixSignatureHeader = "X-Signature"
+
+	klixEventPurchasePaid      = "purchase.paid"
+	klixEventPurchaseFailed    = "purchase.payment_failure"
+	klixEventPurchaseCancelled = "purchase.cancelled"
+	klixEventPaymentRefund     = "payment.refunded"
+	klixStatusPaid             = "paid"
+	klixStatusRefunded         = "refunded"
+	klixPaymentTypeRefund      = "refund"
+	klixRequestTimeout         = 15 * time.Second
+	klixMaxErrorBodyInBytes    = 1024
+)
+
+type klixProduct struct {
+	Name     string `json:"name"`
+	Price    int    `json:"price"`
+	Quantity int    `json:"quantity"`
+}
+
+type klixPurchaseDetails struct {
+	Currency string         `json:"currency"`
+	Products []*klixProduct `json:"products"`
+	Total    int            `json:"total,omitempty"`
+}
+
+type klixPurchaseRequest struct {
+	BrandID         string               `json:"brand_id"`
+	Reference       string               `json:"reference"`
+	Purchase        *klixPurchaseDetails `json:"purchase"`
+	SuccessRedirect string               `json:"success_redirect"`
+	FailureRedirect string               `json:"failure_redirect"`
+	CancelRedirect  string               `json:"cancel_redirect"`
+	SuccessCallback string               `json:"success_callback"`
+}
+
+type klixPurchase struct {
+	ID          string               `json:"id"`
+	EventType   string               `json:"event_type"`
+	Status      string               `json:"status"`
+	Reference   string               `json:"reference"`
+	CheckoutURL string               `json:"checkout_url"`
+	CreatedOn   int64                `json:"created_on"`
+	Purchase    *klixPurchaseDetails `json:"purchase"`
+}
+
+type klixPurchaseList struct {
+	Results []*klixPurchase `json:"results"`
+	Next    *string         `json:"next"`
+}
+
+type klixCallback struct {
+	ID        string `json:"id"`
+	EventType string `json:"event_type"`
+}
+
+type klixRefundRequest struct {
+	Amount int `json:"amount"`
+}
+
+type klixPayment struct {
+	ID          string `json:"id"`
+	EventType   string `json:"event_type"`
+	PaymentType string `json:"payment_type"`
+	Amount      int    `json:"amount"`
+	Currency    string `json:"currency"`
+	IsPaid      bool   `json:"is_paid"`
+}
+
+// KlixPaymentProvider implements the PaymentProvider interface for Klix bank-link payments.
+type KlixPaymentProvider struct {
+	apiURL      string
+	brandID     string
+	secretKey   string
+	publicKey   *rsa.PublicKey
+	webhooksURL string
+	client      *http.Client
+}
+
+// NewKlixPaymentProvider creates an instance of klix payment provider
+// it panics if credentials are not provided or public key can't be parsed.
+func NewKlixPaymentProvider(
+	apiURL, brandID, secretKey, publicKeyPEM, webhooksURL string,
+) *KlixPaymentProvider {
+	if brandID == "" || secretKey == "" || publicKeyPEM == "" {
+		panic("brandID, secretKey and publicKey is required for KlixPaymentProvider")
+	}
+
+	publicKey, err := parseKlixPublicKey(publicKeyPEM)
+	if err != nil {
+		panic(err)
+	}
+
+	return &KlixPaymentProvider{
+		apiURL:      strings.TrimSuffix(apiURL, "/"),
+		brandID:     brandID,
+		secretKey:   secretKey,
+		publicKey:   publicKey,
+		webhooksURL: strings.TrimSuffix(webhooksURL, "/"),
+		client:      &http.Client{Timeout: klixRequestTimeout}, //nolint:exhaustruct
+	}
+}
+
+// CreateCheckoutSession creates Klix purchase for provided order items and returns it's checkout url.
+func (p *KlixPaymentProvider) CreateCheckoutSession(
+	ctx context.Context,
+	reqDto *dto.CheckoutSessionRequestDto,
+) (*dto.CheckoutSessionResponseDto, error) {
+	order := reqDto.OrderDto
+
+	body := &klixPurchaseRequest{
+		BrandID:   p.brandID,
+		Reference: order.ID.String(),
+		Purchase: &klixPurchaseDetails{
+			Currency: strings.ToUpper(order.Currency),
+			Products: p.createProducts(order),
+			Total:    0,
+		},
+		SuccessRedirect: reqDto.SuccessURL,
+		FailureRedirect: reqDto.CancelURL,
+		CancelRedirect:  reqDto.CancelURL,
+		SuccessCallback: WebhookURL(
+			p.webhooksURL,
+			db.OrdersPaymentProviderKlix,
+			order.RestaurantID,
+		),
+	}
+
+	var purchase klixPurchase
+
+	err := p.doRequest(ctx, http.MethodPost, "/purchases/", body, &purchase)
+	if err != nil {
+		return nil, fmt.Errorf("creating klix purchase: %w", err)
+	}
+
+	respDto := &dto.CheckoutSessionResponseDto{
+		URL:              purchase.CheckoutURL,
+		Provider:         db.OrdersPaymentProviderKlix,
+		SessionID:        purchase.ID,
+		PaymentAttemptID: reqDto.PaymentAttemptID,
+		AmountInCents:    0,
+	}
+
+	return respDto, nil
+}
+
+// Refund refunds provided amount of the Klix purchase, the refund is confirmed later with a callback.
+func (p *KlixPaymentProvider) Refund(
+	ctx context.Context,
+	reqDto *dto.ProviderRefundRequestDto,
+) (*dto.RefundDto, error) {
+	path := "/purchases/" + reqDto.Payment.ProviderPaymentID + "/refund/"
+
+	var payment klixPayment
+
+	err := p.doRequest(
+		ctx,
+		http.MethodPost,
+		path,
+		&klixRefundRequest{Amount: reqDto.AmountInCents},
+		&payment,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating klix refund: %w", err)
+	}
+
+	respDto := &dto.RefundDto{
+		ID:               reqDto.RefundID,
+		PaymentID:        reqDto.Payment.ID,
+		OrderID:          reqDto.Payment.OrderID,
+		AmountInCents:    payment.Amount,
+		Currency:         strings.ToLower(payment.Currency),
+		Provider:         db.OrdersPaymentProviderKlix,
+		ProviderRefundID: payment.ID,
+		Status:           refundStatusFromKlix(&payment),
+		Reason:           reqDto.Reason,
+	}
+
+	return respDto, nil
+}
+
+// ListCharges lists paid Klix purchases created in the range, following result pages.
+// Refunded purchases were paid before, so they are listed too.
+func (p *KlixPaymentProvider) ListCharges(
+	ctx context.Context,
+	reqDto *dto.ListChargesRequestDto,
+) ([]*dto.ProviderChargeDto, error) {
+	query := url.Values{}
+	query.Set("created_from", strconv.FormatInt(reqDto.From.Unix(), 10))
+	query.Set("created_to", strconv.FormatInt(reqDto.To.Unix(), 10))
+
+	var charges []*dto.ProviderChargeDto
+
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var list klixPurchaseList
+
+		err := p.doRequest(ctx, http.MethodGet, "/purchases/?"+query.Encode(), nil, &list)
+		if err != nil {
+			return nil, fmt.Errorf("listing klix purchases: %w", err)
+		}
+
+		for _, purchase := range list.Results {
+			isPaid := purchase.Status == klixStatusPaid || purchase.Status == klixStatusRefunded
+			if !isPaid || purchase.Purchase == nil {
+				continue
+			}
+
+			charges = append(charges, &dto.ProviderChargeDto{
+				ProviderPaymentID: purchase.ID,
+				AmountInCents:     purchase.Purchase.Total,
+				Currency:          strings.ToLower(purchase.Purchase.Currency),
+				CreatedAt:         time.Unix(purchase.CreatedOn, 0),
+			})
+		}
+
+		if list.Next == nil || *list.Next == "" {
+			return charges, nil
+		}
+	}
+}
+
+// ParseWebhookEvent verifies Klix callback signature and maps the callback to ProviderEventDto.
+// Klix callbacks carry no event id, every purchase or payment reaches each state only once,
+// so event type and object id together identify the event. Purchase is Klix's checkout session,
+// its id matches the payment attempt.
+func (p *KlixPaymentProvider) ParseWebhookEvent(
+	payload []byte,
+	header http.Header,
+) (*dto.ProviderEventDto, error) {
+	err := p.verifySignature(payload, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var callback klixCallback
+
+	err = json.Unmarshal(payload, &callback)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling klix callback: %w", err)
+	}
+
+	// success callback set on the purchase is sent without event type
+	if callback.EventType == "" {
+		callback.EventType = klixEventPurchasePaid
+	}
+
+	respDto := &dto.ProviderEventDto{
+		ID:                callback.EventType + ":" + callback.ID,
+		Type:              dto.ProviderEventIgnored,
+		OrderID:           uuid.Nil,
+		PaymentAttemptID:  uuid.Nil,
+		SessionID:         "",
+		ProviderPaymentID: "",
+		Payment:           nil,
+		Refund:            nil,
+	}
+
+	switch callback.EventType {
+	case klixEventPurchasePaid:
+		err = parseKlixPurchasePaid(payload, respDto)
+	case klixEventPurchaseFailed:
+		err = parseKlixPurchase(payload, dto.ProviderEventPaymentFailed, respDto)
+	case klixEventPurchaseCancelled:
+		err = parseKlixPurchase(payload, dto.ProviderEventCheckoutCancelled, respDto)
+	case klixEventPaymentRefund:
+		err = parseKlixPaymentRefunded(payload, respDto)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing klix %s callback: %w", callback.EventType, err)
+	}
+
+	return respDto, nil
+}
+
+func parseKlixPurchasePaid(payload []byte, event *dto.ProviderEventDto) error {
+	var purchase klixPurchase
+
+	err := json.Unmarshal(payload, &purchase)
+	if err != nil {
+		return fmt.Errorf("unmarshaling klix purchase: %w", err)
+	}
+
+	if purchase.Status != klixStatusPaid {
+		return fmt.Errorf("%w: %s", ErrPurchaseNotPaid, purchase.Status)
+	}
+
+	orderID, err := orderIDFromKlixPurchase(&purchase)
+	if err != nil {
+		return err
+	}
+
+	event.Type = dto.ProviderEventPaymentSucceeded
+	event.OrderID = orderID
+	event.SessionID = purchase.ID
+	event.ProviderPaymentID = purchase.ID
+	event.Payment = &dto.PaymentDto{
+		ID:                    uuid.New(),
+		OrderID:               orderID,
+		AmountInCents:         purchase.Purchase.Total,
+		Provider:              db.OrdersPaymentProviderKlix,
+		ProviderPaymentID:     purchase.ID,
+		Currency:              strings.ToLower(purchase.Purchase.Currency),
+		RefundedAmountInCents: 0,
+		Status:                db.OrdersPaymentStatusSucceeded,
+		TenderedInCents:       nil,
+		ChangeDueInCents:      nil,
+		RecordedBy:            nil,
+	}
+
+	return nil
+}
+
+func parseKlixPurchase(
+	payload []byte,
+	eventType dto.ProviderEventType,
+	event *dto.ProviderEventDto,
+) error {
+	var purchase klixPurchase
+
+	err := json.Unmarshal(payload, &purchase)
+	if err != nil {
+		return fmt.Errorf("unmarshaling klix purchase: %w", err)
+	}
+
+	orderID, err := orderIDFromKlixPurchase(&purchase)
+	if err != nil {
+		return err
+	}
+
+	event.Type = eventType
+	event.OrderID = orderID
+	event.SessionID = purchase.ID
+	event.ProviderPaymentID = purchase.ID
+
+	return nil
+}
+
+func parseKlixPaymentRefunded(payload []byte, event *dto.ProviderEventDto) error {
+	var payment klixPayment
+
+	err := json.Unmarshal(payload, &payment)
+	if err != nil {
+		return fmt.Errorf("unmarshaling klix payment: %w", err)
+	}
+
+	if payment.PaymentType != klixPaymentTypeRefund {
+		return nil
+	}
+
+	event.Type = dto.ProviderEventRefundUpdated
+	event.Refund = &dto.RefundDto{
+		AmountInCents:    payment.Amount,
+		Currency:         strings.ToLower(payment.Currency),
+		Provider:         db.OrdersPaymentProviderKlix,
+		ProviderRefundID: payment.ID,
+		Status:           refundStatusFromKlix(&payment),
+	}
+
+	return nil
+}
+
+func orderIDFromKlixPurchase(purchase *klixPurchase) (uuid.UUID, error) {
+	if purchase.Reference == "" || purchase.Purchase == nil {
+		return uuid.Nil, ErrOrderIDMissingInMetadata
+	}
+
+	orderID, err := uuid.Parse(purchase.Reference)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("parsing orderID from klix purchase reference: %w", err)
+	}
+
+	return orderID, nil
+}
+
+// verifySignature checks base64 encoded RSA PKCS#1 v1.5 SHA-256 signature of the callback body.
+func (p *KlixPaymentProvider) verifySignature(payload []byte, header http.Header) error {
+	signature, err := base64.StdEncoding.DecodeString(header.Get(KlixSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return fmt.Errorf(
+			"%w: malformed %s header",
+			ErrInvalidWebhookSignature,
+			KlixSignatureHeader,
+		)
+	}
+
+	digest := sha256.Sum256(payload)
+
+	err = rsa.VerifyPKCS1v15(p.publicKey, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookSignature, err)
+	}
+
+	return nil
+}
+
+func (p *KlixPaymentProvider) doRequest(
+	ctx context.Context,
+	method string,
+	path string,
+	body any,
+	out any,
+) error {
+	var reqBody io.Reader
+
+	if body != nil {
+		rawBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request body: %w", err)
+		}
+
+		reqBody = bytes.NewReader(rawBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, klixMaxErrorBodyInBytes))
+
+		return fmt.Errorf("%w: status %d: %s", ErrKlixRequestFailed, resp.StatusCode, errBody)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("decoding response body: %w", err)
+	}
+
+	return nil
+}
+
+func (p *KlixPaymentProvider) createProducts(order *dto.OrderDto) []*klixProduct {
+	if order.AmountPaidInCents > 0 {
+		// order is partially paid already, so only outstanding balance is charged
+		return []*klixProduct{
+			{Name: "Outstanding balance", Price: order.BalanceDueInCents, Quantity: 1},
+		}
+	}
+
+	products := make([]*klixProduct, 0, len(order.Items)+1)
+
+	if order.DiscountInCents > 0 {
+		// items are charged as one product, so that discounts can be taken off their total
+		products = append(products, &klixProduct{
+			Name:     "Order items with discounts",
+			Price:    order.TotalPriceInCents - order.DiscountInCents,
+			Quantity: 1,
+		})
+	} else {
+		for _, item := range order.Items {
+			products = append(products, &klixProduct{
+				Name:     item.Name,
+				Price:    item.PriceInCents,
+				Quantity: 1,
+			})
+		}
+	}
+
+	if order.TipAmountInCents > 0 {
+		products = append(products, &klixProduct{
+			Name:     "Tip for the staff",
+			Price:    order.TipAmountInCents,
+			Quantity: 1,
+		})
+	}
+
+	return products
+}
+
+func refundStatusFromKlix(payment *klixPayment) db.OrdersRefundStatus {
+	if payment.IsPaid {
+		return db.OrdersRefundStatusSucceeded
+	}
+
+	return db.OrdersRefundStatusPending
+}
+
+func parseKlixPublicKey(publicKeyPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, ErrInvalidKlixPublicKey
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKlixPublicKey, err)
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidKlixPublicKey)
+	}
+
+	return publicKey, nil
+}
@@ -0,0 +1,464 @@
+package paymentproviders
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testKlixBrandID     = "brand-123"
+	testKlixSecretKey   = "klix-secret"
+	testKlixPurchaseID  = "d6c4fd5d-4b1e-4a43-8d5b-2b4f4d9c1c11"
+	testKlixRefundID    = "0b4c7a3e-5f0d-4f3a-9c3e-7e3e2c1a9b22"
+	testKlixCheckoutURL = "https://portal.klix.test/p/d6c4fd5d/"
+	testKlixWebhooksURL = "http://dine.test/api/v1/orders/webhooks"
+)
+
+type klixProviderTestSuite struct {
+	suite.Suite
+
+	privateKey *rsa.PrivateKey
+	server     *httptest.Server
+	provider   *KlixPaymentProvider
+
+	lastPurchaseRequest *klixPurchaseRequest
+	lastRefundRequest   *klixRefundRequest
+}
+
+func TestKlixProviderTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(klixProviderTestSuite))
+}
+
+func (suite *klixProviderTestSuite) SetupSuite() {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:mnd
+	suite.Require().NoError(err)
+
+	suite.privateKey = privateKey
+}
+
+func (suite *klixProviderTestSuite) SetupTest() {
+	suite.lastPurchaseRequest = nil
+	suite.lastRefundRequest = nil
+	suite.server = httptest.NewServer(suite.klixStandIn())
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&suite.privateKey.PublicKey)
+	suite.Require().NoError(err)
+
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	suite.provider = NewKlixPaymentProvider(
+		suite.server.URL+"/api/v1",
+		testKlixBrandID,
+		testKlixSecretKey,
+		string(publicKeyPEM),
+		testKlixWebhooksURL+"/",
+	)
+}
+
+func (suite *klixProviderTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// klixStandIn imitates the part of Klix API used by the provider.
+func (suite *klixProviderTestSuite) klixStandIn() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/v1/purchases/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testKlixSecretKey {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		var req klixPurchaseRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.BrandID != testKlixBrandID {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		suite.lastPurchaseRequest = &req
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&klixPurchase{
+			ID:          testKlixPurchaseID,
+			Status:      "created",
+			Reference:   req.Reference,
+			CheckoutURL: testKlixCheckoutURL,
+			Purchase:    req.Purchase,
+		})
+	})
+
+	mux.HandleFunc(
+		"POST /api/v1/purchases/{id}/refund/",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("id") != testKlixPurchaseID {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			var req klixRefundRequest
+
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			suite.lastRefundRequest = &req
+
+			_ = json.NewEncoder(w).Encode(&klixPayment{
+				ID:          testKlixRefundID,
+				PaymentType: klixPaymentTypeRefund,
+				Amount:      req.Amount,
+				Currency:    "EUR",
+			})
+		},
+	)
+
+	mux.HandleFunc("GET /api/v1/purchases/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testKlixSecretKey {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("created_from") == "" || query.Get("created_to") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		details := &klixPurchaseDetails{Currency: "EUR", Products: nil, Total: 1500}
+		next := "/api/v1/purchases/?page=2"
+
+		list := &klixPurchaseList{
+			Results: []*klixPurchase{
+				{ID: testKlixPurchaseID, Status: klixStatusPaid, CreatedOn: 1, Purchase: details},
+				{ID: "unpaid", Status: "created", CreatedOn: 1, Purchase: details},
+			},
+			Next: &next,
+		}
+
+		if query.Get("page") == "2" {
+			list = &klixPurchaseList{
+				Results: []*klixPurchase{
+					{ID: "refunded", Status: klixStatusRefunded, CreatedOn: 2, Purchase: details},
+				},
+				Next: nil,
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(list)
+	})
+
+	return mux
+}
+
+func (suite *klixProviderTestSuite) signedHeader(payload []byte) http.Header {
+	digest := sha256.Sum256(payload)
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, suite.privateKey, crypto.SHA256, digest[:])
+	suite.Require().NoError(err)
+
+	header := http.Header{}
+	header.Set(KlixSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+
+	return header
+}
+
+func (suite *klixProviderTestSuite) TestCreateCheckoutSession_Success() {
+	orderID := uuid.New()
+
+	respDto, err := suite.provider.CreateCheckoutSession(
+		context.Background(),
+		&dto.CheckoutSessionRequestDto{
+			OrderDto: &dto.OrderDto{
+				ID:               orderID,
+				RestaurantID:     testRestaurantID,
+				Currency:         testCurrency,
+				TipAmountInCents: testTipAmount,
+				Items: []*dto.OrderItemDto{
+					{Name: testItem1Name, PriceInCents: testItem1Price},
+					{Name: testItem2Name, PriceInCents: testItem2Price},
+				},
+			},
+			SuccessURL: "http://localhost/success",
+			CancelURL:  "http://localhost/cancel",
+		},
+	)
+	suite.Require().NoError(err)
+
+	suite.Equal(testKlixCheckoutURL, respDto.URL)
+	suite.Equal(db.OrdersPaymentProviderKlix, respDto.Provider)
+	suite.Equal(testKlixPurchaseID, respDto.SessionID)
+
+	req := suite.lastPurchaseRequest
+	suite.Require().NotNil(req)
+	suite.Equal(orderID.String(), req.Reference)
+	suite.Equal("EUR", req.Purchase.Currency)
+	suite.Len(req.Purchase.Products, 3)
+	suite.Equal(testItem1Name, req.Purchase.Products[0].Name)
+	suite.Equal(testTipAmount, req.Purchase.Products[2].Price)
+	suite.Equal("http://localhost/success", req.SuccessRedirect)
+	suite.Equal("http://localhost/cancel", req.CancelRedirect)
+	suite.Equal(
+		testKlixWebhooksURL+"/klix/"+testRestaurantID.String(),
+		req.SuccessCallback,
+	)
+}
+
+func (suite *klixProviderTestSuite) TestCreateCheckoutSession_Discounts() {
+	const discount = 1500
+
+	_, err := suite.provider.CreateCheckoutSession(
+		context.Background(),
+		&dto.CheckoutSessionRequestDto{
+			OrderDto: &dto.OrderDto{
+				ID:                uuid.New(),
+				RestaurantID:      testRestaurantID,
+				Currency:          testCurrency,
+				TipAmountInCents:  testTipAmount,
+				TotalPriceInCents: testItem1Price + testItem2Price,
+				DiscountInCents:   discount,
+				Items: []*dto.OrderItemDto{
+					{Name: testItem1Name, PriceInCents: testItem1Price},
+					{Name: testItem2Name, PriceInCents: testItem2Price},
+				},
+			},
+			SuccessURL: "http://localhost/success",
+			CancelURL:  "http://localhost/cancel",
+		},
+	)
+	suite.Require().NoError(err)
+
+	products := suite.lastPurchaseRequest.Purchase.Products
+	suite.Require().Len(products, 2)
+	suite.Equal(testItem1Price+testItem2Price-discount, products[0].Price)
+	suite.Equal(testTipAmount, products[1].Price)
+}
+
+func (suite *klixProviderTestSuite) TestCreateCheckoutSession_Unauthorized() {
+	suite.provider.secretKey = "wrong"
+
+	_, err := suite.provider.CreateCheckoutSession(
+		context.Background(),
+		&dto.CheckoutSessionRequestDto{
+			OrderDto:   &dto.OrderDto{ID: uuid.New(), Currency: testCurrency},
+			SuccessURL: "http://localhost/success",
+			CancelURL:  "http://localhost/cancel",
+		},
+	)
+	suite.Require().ErrorIs(err, ErrKlixRequestFailed)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_PurchasePaid() {
+	orderID := uuid.New()
+
+	payload, err := json.Marshal(&klixPurchase{
+		ID:        testKlixPurchaseID,
+		EventType: klixEventPurchasePaid,
+		Status:    klixStatusPaid,
+		Reference: orderID.String(),
+		Purchase:  &klixPurchaseDetails{Currency: "EUR", Total: testItem1Price},
+	})
+	suite.Require().NoError(err)
+
+	event, err := suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+	suite.Require().NoError(err)
+
+	suite.Equal(dto.ProviderEventPaymentSucceeded, event.Type)
+	suite.Equal(klixEventPurchasePaid+":"+testKlixPurchaseID, event.ID)
+
+	payment := event.Payment
+	suite.Equal(orderID, payment.OrderID)
+	suite.Equal(testItem1Price, payment.AmountInCents)
+	suite.Equal(testCurrency, payment.Currency)
+	suite.Equal(testKlixPurchaseID, payment.ProviderPaymentID)
+	suite.Equal(db.OrdersPaymentProviderKlix, payment.Provider)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_InvalidSignature() {
+	payload := []byte(`{"id":"1","status":"paid"}`)
+	header := suite.signedHeader([]byte(`{"id":"2","status":"paid"}`))
+
+	_, err := suite.provider.ParseWebhookEvent(payload, header)
+	suite.Require().ErrorIs(err, ErrInvalidWebhookSignature)
+
+	_, err = suite.provider.ParseWebhookEvent(payload, http.Header{})
+	suite.Require().ErrorIs(err, ErrInvalidWebhookSignature)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_NotPaid() {
+	payload, err := json.Marshal(&klixPurchase{
+		ID:        testKlixPurchaseID,
+		Status:    "error",
+		Reference: uuid.NewString(),
+		Purchase:  &klixPurchaseDetails{Currency: "EUR", Total: testItem1Price},
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+	suite.Require().ErrorIs(err, ErrPurchaseNotPaid)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_MissingReference() {
+	payload, err := json.Marshal(&klixPurchase{
+		ID:       testKlixPurchaseID,
+		Status:   klixStatusPaid,
+		Purchase: &klixPurchaseDetails{Currency: "EUR", Total: testItem1Price},
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+	suite.Require().ErrorIs(err, ErrOrderIDMissingInMetadata)
+}
+
+func (suite *klixProviderTestSuite) TestRefund_Success() {
+	payment := &dto.PaymentDto{
+		ID:                uuid.New(),
+		OrderID:           uuid.New(),
+		AmountInCents:     testItem1Price,
+		Currency:          testCurrency,
+		ProviderPaymentID: testKlixPurchaseID,
+	}
+	refundID := uuid.New()
+
+	refund, err := suite.provider.Refund(context.Background(), &dto.ProviderRefundRequestDto{
+		RefundID:      refundID,
+		Payment:       payment,
+		AmountInCents: testItem2Price,
+		Reason:        "cold soup",
+	})
+	suite.Require().NoError(err)
+
+	suite.Equal(testItem2Price, suite.lastRefundRequest.Amount)
+	suite.Equal(refundID, refund.ID)
+	suite.Equal(testKlixRefundID, refund.ProviderRefundID)
+	suite.Equal(testItem2Price, refund.AmountInCents)
+	suite.Equal(testCurrency, refund.Currency)
+	suite.Equal(db.OrdersRefundStatusPending, refund.Status)
+}
+
+func (suite *klixProviderTestSuite) TestRefund_UnknownPurchase() {
+	_, err := suite.provider.Refund(context.Background(), &dto.ProviderRefundRequestDto{
+		RefundID:      uuid.New(),
+		Payment:       &dto.PaymentDto{ProviderPaymentID: "unknown"},
+		AmountInCents: testItem2Price,
+	})
+	suite.Require().ErrorIs(err, ErrKlixRequestFailed)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_PurchaseFailedAndCancelled() {
+	orderID := uuid.New()
+
+	tests := []struct {
+		eventType string
+		wantType  dto.ProviderEventType
+	}{
+		{klixEventPurchaseFailed, dto.ProviderEventPaymentFailed},
+		{klixEventPurchaseCancelled, dto.ProviderEventCheckoutCancelled},
+	}
+
+	for _, tt := range tests {
+		payload, err := json.Marshal(&klixPurchase{
+			ID:        testKlixPurchaseID,
+			EventType: tt.eventType,
+			Status:    "error",
+			Reference: orderID.String(),
+			Purchase:  &klixPurchaseDetails{Currency: "EUR", Total: testItem1Price},
+		})
+		suite.Require().NoError(err)
+
+		event, err := suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+		suite.Require().NoError(err)
+
+		suite.Equal(tt.wantType, event.Type)
+		suite.Equal(orderID, event.OrderID)
+		suite.Equal(testKlixPurchaseID, event.SessionID)
+		suite.Equal(tt.eventType+":"+testKlixPurchaseID, event.ID)
+	}
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_PaymentRefunded() {
+	payload, err := json.Marshal(&klixPayment{
+		ID:          testKlixRefundID,
+		EventType:   klixEventPaymentRefund,
+		PaymentType: klixPaymentTypeRefund,
+		Amount:      testItem2Price,
+		Currency:    "EUR",
+		IsPaid:      true,
+	})
+	suite.Require().NoError(err)
+
+	event, err := suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+	suite.Require().NoError(err)
+
+	suite.Equal(dto.ProviderEventRefundUpdated, event.Type)
+
+	refund := event.Refund
+	suite.Equal(testKlixRefundID, refund.ProviderRefundID)
+	suite.Equal(testItem2Price, refund.AmountInCents)
+	suite.Equal(db.OrdersRefundStatusSucceeded, refund.Status)
+}
+
+func (suite *klixProviderTestSuite) TestParseWebhookEvent_UnknownEvent() {
+	payload := []byte(`{"id":"1","event_type":"purchase.created"}`)
+
+	event, err := suite.provider.ParseWebhookEvent(payload, suite.signedHeader(payload))
+	suite.Require().NoError(err)
+	suite.Equal(dto.ProviderEventIgnored, event.Type)
+}
+
+func (suite *klixProviderTestSuite) TestListCharges() {
+	reqDto := &dto.ListChargesRequestDto{
+		From: time.Unix(0, 0),
+		To:   time.Unix(10, 0), //nolint:mnd
+	}
+
+	charges, err := suite.provider.ListCharges(context.Background(), reqDto)
+	suite.Require().NoError(err)
+	suite.Require().Len(charges, 2)
+
+	suite.Equal(&dto.ProviderChargeDto{
+		ProviderPaymentID: testKlixPurchaseID,
+		AmountInCents:     1500,
+		Currency:          "eur",
+		CreatedAt:         time.Unix(1, 0),
+	}, charges[0])
+	suite.Equal("refunded", charges[1].ProviderPaymentID)
+
+	suite.provider.secretKey = "wrong"
+
+	_, err = suite.provider.ListCharges(context.Background(), reqDto)
+	suite.Require().ErrorIs(err, ErrKlixRequestFailed)
+}
+
+func TestNewKlixPaymentProvider_InvalidPublicKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewKlixPaymentProvider("http://klix.test", "brand", "secret", "not a key", "")
+	})
+
+	_, err := parseKlixPublicKey("not a key")
+	require.ErrorIs(t, err, ErrInvalidKlixPublicKey)
+}
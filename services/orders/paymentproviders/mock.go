@@ -0,0 +1,573 @@
+package paymentproviders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCheckoutSessionNotFound is returned when mock checkout session doesn't exist or is already completed.
+	ErrCheckoutSessionNotFound = errors.New("checkout session not found")
+	// ErrInvalidWebhookSignature is returned when mock webhook signature header is missing or doesn't match.
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrWebhookRejected is returned when our own webhook endpoint doesn't accept mock event.
+	ErrWebhookRejected = errors.New("webhook rejected")
+)
+
+const (
+	// MockSignatureHeader is the header mock webhook requests are signed with.
+	MockSignatureHeader = "Mock-Signature"
+
+	mockCheckoutPath = "/api/v1/orders/mock-checkout/"
+	mockWebhooksPath = "/api/v1/orders/webhooks"
+
+	mockEventPaymentSucceeded  = "payment.succeeded"
+	mockEventPaymentFailed     = "payment.failed"
+	mockEventCheckoutExpired   = "checkout.expired"
+	mockEventCheckoutCancelled = "checkout.cancelled"
+	mockEventRefundSucceeded   = "refund.succeeded"
+
+	mockSignatureTolerance  = 5 * time.Minute
+	mockRefundWebhookDelay  = 2 * time.Second
+	mockWebhookTimeout      = 10 * time.Second
+	mockRandomIDLengthBytes = 12
+)
+
+// MockCheckoutSession is a pending checkout created by MockPaymentProvider.
+type MockCheckoutSession struct {
+	ID            string
+	OrderID       uuid.UUID
+	RestaurantID  uuid.UUID
+	AmountInCents int
+	Currency      string
+	SuccessURL    string
+	CancelURL     string
+}
+
+type mockEvent struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Created int64           `json:"created"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type mockPayment struct {
+	ID            string    `json:"id"`
+	SessionID     string    `json:"session_id"`
+	OrderID       uuid.UUID `json:"order_id"`
+	AmountInCents int       `json:"amount_in_cents"`
+	Currency      string    `json:"currency"`
+}
+
+type mockCheckout struct {
+	ID      string    `json:"id"`
+	OrderID uuid.UUID `json:"order_id"`
+}
+
+type mockRefund struct {
+	ID            string    `json:"id"`
+	RefundID      uuid.UUID `json:"refund_id"`
+	PaymentID     string    `json:"payment_id"`
+	AmountInCents int       `json:"amount_in_cents"`
+	Currency      string    `json:"currency"`
+}
+
+// MockPaymentProvider implements the PaymentProvider interface without any external service.
+// Checkout sessions are kept in memory and paid on a local checkout page, which then
+// calls our own webhook endpoints with payloads signed the same way real providers do.
+type MockPaymentProvider struct {
+	baseURL            string
+	webhookSecret      string
+	client             *http.Client
+	refundWebhookDelay time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*MockCheckoutSession
+	charges  []*dto.ProviderChargeDto
+}
+
+// NewMockPaymentProvider creates an instance of mock payment provider
+// it panics if webhookSecret is not provided.
+func NewMockPaymentProvider(baseURL, webhookSecret string) *MockPaymentProvider {
+	if webhookSecret == "" {
+		panic("webhookSecret is required for MockPaymentProvider")
+	}
+
+	return &MockPaymentProvider{
+		baseURL:            strings.TrimSuffix(baseURL, "/"),
+		webhookSecret:      webhookSecret,
+		client:             &http.Client{Timeout: mockWebhookTimeout}, //nolint:exhaustruct
+		refundWebhookDelay: mockRefundWebhookDelay,
+		mu:                 sync.Mutex{},
+		sessions:           make(map[string]*MockCheckoutSession),
+		charges:            nil,
+	}
+}
+
+// CreateCheckoutSession stores checkout session in memory and returns local checkout page url.
+func (p *MockPaymentProvider) CreateCheckoutSession(
+	_ context.Context,
+	reqDto *dto.CheckoutSessionRequestDto,
+) (*dto.CheckoutSessionResponseDto, error) {
+	sessionID, err := mockRandomID("mcs_")
+	if err != nil {
+		return nil, err
+	}
+
+	order := reqDto.OrderDto
+
+	p.mu.Lock()
+	p.sessions[sessionID] = &MockCheckoutSession{
+		ID:            sessionID,
+		OrderID:       order.ID,
+		RestaurantID:  order.RestaurantID,
+		AmountInCents: order.BalanceDueInCents,
+		Currency:      order.Currency,
+		SuccessURL:    reqDto.SuccessURL,
+		CancelURL:     reqDto.CancelURL,
+	}
+	p.mu.Unlock()
+
+	respDto := &dto.CheckoutSessionResponseDto{
+		URL:              p.baseURL + mockCheckoutPath + sessionID,
+		Provider:         db.OrdersPaymentProviderMock,
+		SessionID:        sessionID,
+		PaymentAttemptID: reqDto.PaymentAttemptID,
+		AmountInCents:    0,
+	}
+
+	return respDto, nil
+}
+
+// GetCheckoutSession returns pending checkout session.
+func (p *MockPaymentProvider) GetCheckoutSession(sessionID string) (*MockCheckoutSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.sessions[sessionID]
+	if !ok {
+		return nil, ErrCheckoutSessionNotFound
+	}
+
+	return s, nil
+}
+
+// PayCheckoutSession pays for checkout session, sends signed payment webhook
+// and returns url the customer should be redirected to.
+func (p *MockPaymentProvider) PayCheckoutSession(
+	ctx context.Context,
+	sessionID string,
+) (string, error) {
+	s, err := p.GetCheckoutSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	paymentID, err := mockRandomID("mpi_")
+	if err != nil {
+		return "", err
+	}
+
+	payment := &mockPayment{
+		ID:            paymentID,
+		SessionID:     s.ID,
+		OrderID:       s.OrderID,
+		AmountInCents: s.AmountInCents,
+		Currency:      s.Currency,
+	}
+
+	err = p.sendWebhook(ctx, p.webhookURL(s.RestaurantID), mockEventPaymentSucceeded, payment)
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.charges = append(p.charges, &dto.ProviderChargeDto{
+		ProviderPaymentID: payment.ID,
+		AmountInCents:     payment.AmountInCents,
+		Currency:          payment.Currency,
+		CreatedAt:         time.Now(),
+	})
+	p.mu.Unlock()
+
+	p.removeCheckoutSession(sessionID)
+
+	return s.SuccessURL, nil
+}
+
+// DeclineCheckoutSession sends signed payment failed webhook, checkout session stays open,
+// so the customer can try to pay again.
+func (p *MockPaymentProvider) DeclineCheckoutSession(ctx context.Context, sessionID string) error {
+	s, err := p.GetCheckoutSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	paymentID, err := mockRandomID("mpi_")
+	if err != nil {
+		return err
+	}
+
+	payment := &mockPayment{
+		ID:            paymentID,
+		SessionID:     s.ID,
+		OrderID:       s.OrderID,
+		AmountInCents: s.AmountInCents,
+		Currency:      s.Currency,
+	}
+
+	return p.sendWebhook(ctx, p.webhookURL(s.RestaurantID), mockEventPaymentFailed, payment)
+}
+
+// CancelCheckoutSession removes checkout session, sends signed checkout cancelled webhook
+// and returns url the customer should be redirected to.
+func (p *MockPaymentProvider) CancelCheckoutSession(
+	ctx context.Context,
+	sessionID string,
+) (string, error) {
+	s, err := p.GetCheckoutSession(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	checkout := &mockCheckout{
+		ID:      s.ID,
+		OrderID: s.OrderID,
+	}
+
+	err = p.sendWebhook(ctx, p.webhookURL(s.RestaurantID), mockEventCheckoutCancelled, checkout)
+	if err != nil {
+		return "", err
+	}
+
+	p.removeCheckoutSession(sessionID)
+
+	return s.CancelURL, nil
+}
+
+// Refund accepts the refund right away and confirms it with a webhook shortly after,
+// once refund's id is saved on our side.
+func (p *MockPaymentProvider) Refund(
+	_ context.Context,
+	reqDto *dto.ProviderRefundRequestDto,
+) (*dto.RefundDto, error) {
+	refundID, err := mockRandomID("mre_")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &mockRefund{
+		ID:            refundID,
+		RefundID:      reqDto.RefundID,
+		PaymentID:     reqDto.Payment.ProviderPaymentID,
+		AmountInCents: reqDto.AmountInCents,
+		Currency:      reqDto.Payment.Currency,
+	}
+
+	url := p.webhookURL(reqDto.RestaurantID)
+
+	time.AfterFunc(p.refundWebhookDelay, func() {
+		_ = p.sendWebhook(context.Background(), url, mockEventRefundSucceeded, r)
+	})
+
+	respDto := &dto.RefundDto{
+		ID:               reqDto.RefundID,
+		PaymentID:        reqDto.Payment.ID,
+		OrderID:          reqDto.Payment.OrderID,
+		AmountInCents:    reqDto.AmountInCents,
+		Currency:         reqDto.Payment.Currency,
+		Provider:         db.OrdersPaymentProviderMock,
+		ProviderRefundID: refundID,
+		Status:           db.OrdersRefundStatusPending,
+		Reason:           reqDto.Reason,
+	}
+
+	return respDto, nil
+}
+
+// ListCharges lists payments made on the checkout page since the provider was created.
+func (p *MockPaymentProvider) ListCharges(
+	_ context.Context,
+	reqDto *dto.ListChargesRequestDto,
+) ([]*dto.ProviderChargeDto, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var charges []*dto.ProviderChargeDto
+
+	for _, charge := range p.charges {
+		if charge.CreatedAt.Before(reqDto.From) || !charge.CreatedAt.Before(reqDto.To) {
+			continue
+		}
+
+		charges = append(charges, charge)
+	}
+
+	return charges, nil
+}
+
+// ParseWebhookEvent verifies mock webhook signature and maps the event to ProviderEventDto.
+func (p *MockPaymentProvider) ParseWebhookEvent(
+	payload []byte,
+	header http.Header,
+) (*dto.ProviderEventDto, error) {
+	event, err := p.constructEvent(payload, header)
+	if err != nil {
+		return nil, err
+	}
+
+	respDto := &dto.ProviderEventDto{
+		ID:                event.ID,
+		Type:              dto.ProviderEventIgnored,
+		OrderID:           uuid.Nil,
+		PaymentAttemptID:  uuid.Nil,
+		SessionID:         "",
+		ProviderPaymentID: "",
+		Payment:           nil,
+		Refund:            nil,
+	}
+
+	switch event.Type {
+	case mockEventPaymentSucceeded, mockEventPaymentFailed:
+		err = parseMockPayment(event, respDto)
+	case mockEventCheckoutExpired, mockEventCheckoutCancelled:
+		err = parseMockCheckout(event, respDto)
+	case mockEventRefundSucceeded:
+		err = parseMockRefundSucceeded(event, respDto)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing mock %s event: %w", event.Type, err)
+	}
+
+	return respDto, nil
+}
+
+func parseMockPayment(event *mockEvent, respDto *dto.ProviderEventDto) error {
+	var payment mockPayment
+
+	err := json.Unmarshal(event.Data, &payment)
+	if err != nil {
+		return fmt.Errorf("unmarshaling mock payment: %w", err)
+	}
+
+	if payment.OrderID == uuid.Nil {
+		return ErrOrderIDMissingInMetadata
+	}
+
+	respDto.OrderID = payment.OrderID
+	respDto.SessionID = payment.SessionID
+	respDto.ProviderPaymentID = payment.ID
+
+	if event.Type == mockEventPaymentFailed {
+		respDto.Type = dto.ProviderEventPaymentFailed
+
+		return nil
+	}
+
+	respDto.Type = dto.ProviderEventPaymentSucceeded
+	respDto.Payment = &dto.PaymentDto{
+		ID:                    uuid.New(),
+		OrderID:               payment.OrderID,
+		AmountInCents:         payment.AmountInCents,
+		Provider:              db.OrdersPaymentProviderMock,
+		ProviderPaymentID:     payment.ID,
+		Currency:              payment.Currency,
+		RefundedAmountInCents: 0,
+		Status:                db.OrdersPaymentStatusSucceeded,
+		TenderedInCents:       nil,
+		ChangeDueInCents:      nil,
+		RecordedBy:            nil,
+	}
+
+	return nil
+}
+
+func parseMockCheckout(event *mockEvent, respDto *dto.ProviderEventDto) error {
+	var checkout mockCheckout
+
+	err := json.Unmarshal(event.Data, &checkout)
+	if err != nil {
+		return fmt.Errorf("unmarshaling mock checkout: %w", err)
+	}
+
+	if checkout.OrderID == uuid.Nil {
+		return ErrOrderIDMissingInMetadata
+	}
+
+	respDto.Type = dto.ProviderEventCheckoutExpired
+	respDto.OrderID = checkout.OrderID
+	respDto.SessionID = checkout.ID
+
+	if event.Type == mockEventCheckoutCancelled {
+		respDto.Type = dto.ProviderEventCheckoutCancelled
+	}
+
+	return nil
+}
+
+func parseMockRefundSucceeded(event *mockEvent, respDto *dto.ProviderEventDto) error {
+	var r mockRefund
+
+	err := json.Unmarshal(event.Data, &r)
+	if err != nil {
+		return fmt.Errorf("unmarshaling mock refund: %w", err)
+	}
+
+	respDto.Type = dto.ProviderEventRefundUpdated
+	respDto.Refund = &dto.RefundDto{
+		ID:               r.RefundID,
+		AmountInCents:    r.AmountInCents,
+		Currency:         r.Currency,
+		Provider:         db.OrdersPaymentProviderMock,
+		ProviderRefundID: r.ID,
+		Status:           db.OrdersRefundStatusSucceeded,
+	}
+
+	return nil
+}
+
+func (p *MockPaymentProvider) removeCheckoutSession(sessionID string) {
+	p.mu.Lock()
+	delete(p.sessions, sessionID)
+	p.mu.Unlock()
+}
+
+func (p *MockPaymentProvider) webhookURL(restaurantID uuid.UUID) string {
+	return WebhookURL(p.baseURL+mockWebhooksPath, db.OrdersPaymentProviderMock, restaurantID)
+}
+
+func (p *MockPaymentProvider) sendWebhook(
+	ctx context.Context,
+	url string,
+	eventType string,
+	data any,
+) error {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshaling mock event data: %w", err)
+	}
+
+	eventID, err := mockRandomID("mevt_")
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&mockEvent{
+		ID:      eventID,
+		Type:    eventType,
+		Created: time.Now().Unix(),
+		Data:    rawData,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling mock event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url,
+		bytes.NewReader(payload),
+	)
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(MockSignatureHeader, p.sign(payload, time.Now()))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s responded with %d", ErrWebhookRejected, url, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sign returns signature header value in format 't=<unix timestamp>,v1=<hex hmac>'.
+func (p *MockPaymentProvider) sign(payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + timestamp + ",v1=" + p.computeSignature(timestamp, payload)
+}
+
+func (p *MockPaymentProvider) computeSignature(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *MockPaymentProvider) constructEvent(
+	payload []byte,
+	header http.Header,
+) (*mockEvent, error) {
+	var timestamp, signature string
+
+	for part := range strings.SplitSeq(header.Get(MockSignatureHeader), ",") {
+		key, value, _ := strings.Cut(part, "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad timestamp", ErrInvalidWebhookSignature)
+	}
+
+	if time.Since(time.Unix(unix, 0)).Abs() > mockSignatureTolerance {
+		return nil, fmt.Errorf("%w: timestamp outside of tolerance", ErrInvalidWebhookSignature)
+	}
+
+	expected := p.computeSignature(timestamp, payload)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var event mockEvent
+
+	err = json.Unmarshal(payload, &event)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling mock event: %w", err)
+	}
+
+	return &event, nil
+}
+
+func mockRandomID(prefix string) (string, error) {
+	b := make([]byte, mockRandomIDLengthBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating random id: %w", err)
+	}
+
+	return prefix + hex.EncodeToString(b), nil
+}
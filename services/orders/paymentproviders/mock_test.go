@@ -0,0 +1,305 @@
+package paymentproviders
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMockWebhookSecret = "test-webhook-secret"
+
+type receivedWebhook struct {
+	path    string
+	payload []byte
+	header  http.Header
+}
+
+// newMockWebhookServer starts local stand-in for our webhook endpoints, which records received requests.
+func newMockWebhookServer(t *testing.T) (*httptest.Server, chan *receivedWebhook) {
+	t.Helper()
+
+	received := make(chan *receivedWebhook, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		received <- &receivedWebhook{path: r.URL.Path, payload: payload, header: r.Header}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+}
+
+func newTestCheckoutSession(
+	t *testing.T,
+	provider *MockPaymentProvider,
+	orderID uuid.UUID,
+) string {
+	t.Helper()
+
+	reqDto := &dto.CheckoutSessionRequestDto{
+		OrderDto: &dto.OrderDto{
+			ID:                orderID,
+			RestaurantID:      testRestaurantID,
+			Currency:          testCurrency,
+			BalanceDueInCents: testItem1Price,
+		},
+		SuccessURL: "http://localhost/success",
+		CancelURL:  "http://localhost/cancel",
+	}
+
+	respDto, err := provider.CreateCheckoutSession(context.Background(), reqDto)
+	require.NoError(t, err)
+	assert.Equal(t, db.OrdersPaymentProviderMock, respDto.Provider)
+
+	sessionID, ok := strings.CutPrefix(respDto.URL, "http://dine.test"+mockCheckoutPath)
+	require.True(t, ok, respDto.URL)
+	assert.Equal(t, sessionID, respDto.SessionID)
+
+	return sessionID
+}
+
+func TestMockPaymentProvider_PayCheckoutSession(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMockWebhookServer(t)
+	provider := NewMockPaymentProvider("http://dine.test/", testMockWebhookSecret)
+	orderID := uuid.New()
+
+	sessionID := newTestCheckoutSession(t, provider, orderID)
+
+	s, err := provider.GetCheckoutSession(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, orderID, s.OrderID)
+	assert.Equal(t, testItem1Price, s.AmountInCents)
+
+	provider.baseURL = server.URL
+
+	redirectURL, err := provider.PayCheckoutSession(context.Background(), sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/success", redirectURL)
+
+	webhook := <-received
+	assert.Equal(t, mockWebhooksPath+"/mock/"+testRestaurantID.String(), webhook.path)
+
+	event, err := provider.ParseWebhookEvent(webhook.payload, webhook.header)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ProviderEventPaymentSucceeded, event.Type)
+	assert.Equal(t, sessionID, event.SessionID)
+
+	payment := event.Payment
+	assert.Equal(t, orderID, payment.OrderID)
+	assert.Equal(t, testItem1Price, payment.AmountInCents)
+	assert.Equal(t, testCurrency, payment.Currency)
+	assert.Equal(t, db.OrdersPaymentProviderMock, payment.Provider)
+	assert.True(t, strings.HasPrefix(payment.ProviderPaymentID, "mpi_"))
+
+	_, err = provider.GetCheckoutSession(sessionID)
+	require.ErrorIs(t, err, ErrCheckoutSessionNotFound)
+}
+
+func TestMockPaymentProvider_ListCharges(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMockWebhookServer(t)
+	provider := NewMockPaymentProvider("http://dine.test/", testMockWebhookSecret)
+
+	sessionID := newTestCheckoutSession(t, provider, uuid.New())
+	provider.baseURL = server.URL
+
+	_, err := provider.PayCheckoutSession(context.Background(), sessionID)
+	require.NoError(t, err)
+
+	webhook := <-received
+
+	event, err := provider.ParseWebhookEvent(webhook.payload, webhook.header)
+	require.NoError(t, err)
+
+	charges, err := provider.ListCharges(context.Background(), &dto.ListChargesRequestDto{
+		From: time.Now().Add(-time.Hour),
+		To:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, charges, 1)
+	assert.Equal(t, event.ProviderPaymentID, charges[0].ProviderPaymentID)
+	assert.Equal(t, testItem1Price, charges[0].AmountInCents)
+
+	charges, err = provider.ListCharges(context.Background(), &dto.ListChargesRequestDto{
+		From: time.Now().Add(-2 * time.Hour),
+		To:   time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, charges)
+}
+
+func TestMockPaymentProvider_PayCheckoutSession_WebhookRejected(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewMockPaymentProvider("http://dine.test", testMockWebhookSecret)
+	sessionID := newTestCheckoutSession(t, provider, uuid.New())
+	provider.baseURL = server.URL
+
+	_, err := provider.PayCheckoutSession(context.Background(), sessionID)
+	require.ErrorIs(t, err, ErrWebhookRejected)
+
+	// session stays open, so the customer can try again
+	_, err = provider.GetCheckoutSession(sessionID)
+	require.NoError(t, err)
+}
+
+func TestMockPaymentProvider_DeclineCheckoutSession(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMockWebhookServer(t)
+	provider := NewMockPaymentProvider("http://dine.test", testMockWebhookSecret)
+	orderID := uuid.New()
+	sessionID := newTestCheckoutSession(t, provider, orderID)
+	provider.baseURL = server.URL
+
+	err := provider.DeclineCheckoutSession(context.Background(), sessionID)
+	require.NoError(t, err)
+
+	webhook := <-received
+
+	event, err := provider.ParseWebhookEvent(webhook.payload, webhook.header)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ProviderEventPaymentFailed, event.Type)
+	assert.Equal(t, orderID, event.OrderID)
+	assert.Nil(t, event.Payment)
+
+	// session stays open, so the customer can try again
+	_, err = provider.GetCheckoutSession(sessionID)
+	require.NoError(t, err)
+}
+
+func TestMockPaymentProvider_CancelCheckoutSession(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMockWebhookServer(t)
+	provider := NewMockPaymentProvider("http://dine.test", testMockWebhookSecret)
+	orderID := uuid.New()
+	sessionID := newTestCheckoutSession(t, provider, orderID)
+	provider.baseURL = server.URL
+
+	redirectURL, err := provider.CancelCheckoutSession(context.Background(), sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/cancel", redirectURL)
+
+	webhook := <-received
+
+	event, err := provider.ParseWebhookEvent(webhook.payload, webhook.header)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ProviderEventCheckoutCancelled, event.Type)
+	assert.Equal(t, orderID, event.OrderID)
+	assert.Equal(t, sessionID, event.SessionID)
+
+	_, err = provider.CancelCheckoutSession(context.Background(), sessionID)
+	require.ErrorIs(t, err, ErrCheckoutSessionNotFound)
+}
+
+func TestMockPaymentProvider_Refund(t *testing.T) {
+	t.Parallel()
+
+	server, received := newMockWebhookServer(t)
+	provider := NewMockPaymentProvider(server.URL, testMockWebhookSecret)
+	provider.refundWebhookDelay = 0
+
+	payment := &dto.PaymentDto{
+		ID:                uuid.New(),
+		OrderID:           uuid.New(),
+		AmountInCents:     testItem1Price,
+		Currency:          testCurrency,
+		ProviderPaymentID: "mpi_123",
+	}
+
+	refund, err := provider.Refund(context.Background(), &dto.ProviderRefundRequestDto{
+		RefundID:      uuid.New(),
+		RestaurantID:  testRestaurantID,
+		Payment:       payment,
+		AmountInCents: testItem2Price,
+		Reason:        "cold soup",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.OrdersRefundStatusPending, refund.Status)
+
+	var webhook *receivedWebhook
+	select {
+	case webhook = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("refund webhook was not sent")
+	}
+
+	assert.Equal(t, mockWebhooksPath+"/mock/"+testRestaurantID.String(), webhook.path)
+
+	event, err := provider.ParseWebhookEvent(webhook.payload, webhook.header)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ProviderEventRefundUpdated, event.Type)
+
+	confirmed := event.Refund
+	assert.Equal(t, refund.ProviderRefundID, confirmed.ProviderRefundID)
+	assert.Equal(t, testItem2Price, confirmed.AmountInCents)
+	assert.Equal(t, db.OrdersRefundStatusSucceeded, confirmed.Status)
+}
+
+func TestMockPaymentProvider_ParseWebhookEvent_UnknownEvent(t *testing.T) {
+	t.Parallel()
+
+	provider := NewMockPaymentProvider("http://dine.test", testMockWebhookSecret)
+	payload := []byte(`{"id":"mevt_1","type":"payment.created","data":{}}`)
+
+	header := http.Header{}
+	header.Set(MockSignatureHeader, provider.sign(payload, time.Now()))
+
+	event, err := provider.ParseWebhookEvent(payload, header)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ProviderEventIgnored, event.Type)
+	assert.Equal(t, "mevt_1", event.ID)
+}
+
+func TestMockPaymentProvider_VerifyWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	provider := NewMockPaymentProvider("http://dine.test", testMockWebhookSecret)
+	payload := []byte(`{"id":"mevt_1","type":"payment.succeeded","data":{}}`)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "missing header", header: ""},
+		{name: "wrong secret", header: (&MockPaymentProvider{webhookSecret: "nope"}).sign(
+			payload,
+			time.Now(),
+		)},
+		{name: "expired", header: provider.sign(payload, time.Now().Add(-time.Hour))},
+		{name: "bad timestamp", header: "t=abc,v1=123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := http.Header{}
+			header.Set(MockSignatureHeader, tt.header)
+
+			_, err := provider.ParseWebhookEvent(payload, header)
+			require.ErrorIs(t, err, ErrInvalidWebhookSignature)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package paymentproviders
 
 import (
 	"context"
+	"golang-dining-ordering/config"
 	"golang-dining-ordering/services/orders/dto"
 	"net/http"
 )
@@ -13,8 +14,42 @@ type PaymentProvider interface {
 		ctx context.Context,
 		reqDto *dto.CheckoutSessionRequestDto,
 	) (*dto.CheckoutSessionResponseDto, error)
-	VerifySuccessWebhookEvent(
-		payload []byte,
-		header http.Header,
-	) (*dto.PaymentDto, error)
+	Refund(ctx context.Context, reqDto *dto.ProviderRefundRequestDto) (*dto.RefundDto, error)
+	// ParseWebhookEvent verifies webhook signature and maps provider's event to ProviderEventDto,
+	// events we don't handle are returned with ProviderEventIgnored type instead of an error.
+	ParseWebhookEvent(payload []byte, header http.Header) (*dto.ProviderEventDto, error)
+	// ListCharges lists charges settled on provider's side, used to reconcile our payments.
+	ListCharges(
+		ctx context.Context,
+		reqDto *dto.ListChargesRequestDto,
+	) ([]*dto.ProviderChargeDto, error)
+}
+
+// GetPlatformProvider returns the platform wide PaymentProvider implementation (Stripe, Klix or mock)
+// based on config, or nil when platform provider is not configured.
+//
+//nolint:ireturn
+func GetPlatformProvider(cfg *config.AppConfig) PaymentProvider {
+	switch cfg.PaymentsConfig.PlatformProvider {
+	case config.PaymentProviderTypeMock:
+		return NewMockPaymentProvider(
+			cfg.MockPaymentsConfig.BaseURL,
+			cfg.MockPaymentsConfig.WebhookSecret,
+		)
+	case config.PaymentProviderTypeKlix:
+		return NewKlixPaymentProvider(
+			cfg.KlixConfig.APIURL,
+			cfg.KlixConfig.BrandID,
+			cfg.KlixConfig.SecretKey,
+			cfg.KlixConfig.PublicKey,
+			cfg.PaymentsConfig.WebhooksURL,
+		)
+	case config.PaymentProviderTypeStripe:
+		return NewStripePaymentProvider(
+			NewStripeClient(cfg.StripeSecretKey, cfg.StripeAPIURL),
+			cfg.StripeWebhookSecret,
+		)
+	default:
+		return nil
+	}
 }
@@ -0,0 +1,256 @@
+package paymentproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrProviderNotConfigured is returned when restaurant has no usable payment provider.
+	ErrProviderNotConfigured = errors.New("payment provider is not configured for this restaurant")
+	// ErrProviderNotSupported is returned when provider can't be configured by restaurants.
+	ErrProviderNotSupported = errors.New("payment provider can't be configured per restaurant")
+	// ErrMissingCredentials is returned when required provider credentials are not provided.
+	ErrMissingCredentials = errors.New("payment provider credentials are incomplete")
+)
+
+// CredentialsStore loads payment providers restaurants have configured, with decrypted credentials.
+type CredentialsStore interface {
+	GetRestaurantPaymentProviders(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+	) ([]*dto.RestaurantPaymentProviderDto, error)
+}
+
+// Registry resolves PaymentProvider that should be used for a restaurant.
+type Registry interface {
+	Get(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		provider db.OrdersPaymentProvider,
+	) (PaymentProvider, error)
+	GetOwn(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		provider db.OrdersPaymentProvider,
+	) (PaymentProvider, error)
+	GetDefault(ctx context.Context, restaurantID uuid.UUID) (PaymentProvider, error)
+	GetPlatform(provider db.OrdersPaymentProvider) (PaymentProvider, error)
+	WebhookURL(restaurantID uuid.UUID, provider db.OrdersPaymentProvider) string
+}
+
+// RegistryConfig holds platform wide settings used to build restaurants' providers.
+type RegistryConfig struct {
+	WebhooksURL  string
+	KlixAPIURL   string
+	StripeAPIURL string
+}
+
+type cachedProvider struct {
+	updatedAt time.Time
+	provider  PaymentProvider
+}
+
+type registry struct {
+	store            CredentialsStore
+	cfg              *RegistryConfig
+	platform         PaymentProvider
+	platformProvider db.OrdersPaymentProvider
+	cache            sync.Map
+}
+
+// NewRegistry creates a registry of restaurants' payment providers. Platform provider is optional,
+// when it's set it's used for restaurants that haven't configured a provider of their own.
+//
+//revive:disable:unexported-return
+func NewRegistry(
+	store CredentialsStore,
+	cfg *RegistryConfig,
+	platform PaymentProvider,
+	platformProvider db.OrdersPaymentProvider,
+) *registry {
+	return &registry{
+		store:            store,
+		cfg:              cfg,
+		platform:         platform,
+		platformProvider: platformProvider,
+		cache:            sync.Map{},
+	}
+}
+
+//revive:enable:unexported-return
+
+// Get returns restaurant's configured provider of requested type, or platform provider when
+// restaurant hasn't configured one.
+//
+//nolint:ireturn
+func (r *registry) Get(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) (PaymentProvider, error) {
+	p, err := r.GetOwn(ctx, restaurantID, provider)
+	if errors.Is(err, ErrProviderNotConfigured) {
+		return r.GetPlatform(provider)
+	}
+
+	return p, err
+}
+
+// GetOwn returns provider of requested type built with restaurant's own credentials.
+//
+//nolint:ireturn
+func (r *registry) GetOwn(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) (PaymentProvider, error) {
+	providers, err := r.store.GetRestaurantPaymentProviders(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting restaurant payment providers: %w", err)
+	}
+
+	for _, p := range providers {
+		if p.Provider == provider {
+			return r.build(p)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrProviderNotConfigured, provider)
+}
+
+// GetPlatform returns platform provider when it's of requested type.
+//
+//nolint:ireturn
+func (r *registry) GetPlatform(provider db.OrdersPaymentProvider) (PaymentProvider, error) {
+	if r.platform != nil && r.platformProvider == provider {
+		return r.platform, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrProviderNotConfigured, provider)
+}
+
+// GetDefault returns restaurant's default provider, or the only one it has configured.
+//
+//nolint:ireturn
+func (r *registry) GetDefault(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (PaymentProvider, error) {
+	providers, err := r.store.GetRestaurantPaymentProviders(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting restaurant payment providers: %w", err)
+	}
+
+	// providers are ordered with the default one first
+	if len(providers) > 0 {
+		return r.build(providers[0])
+	}
+
+	if r.platform != nil {
+		return r.platform, nil
+	}
+
+	return nil, ErrProviderNotConfigured
+}
+
+// WebhookURL returns the url restaurant should register in provider's dashboard.
+func (r *registry) WebhookURL(restaurantID uuid.UUID, provider db.OrdersPaymentProvider) string {
+	return WebhookURL(r.cfg.WebhooksURL, provider, restaurantID)
+}
+
+//nolint:ireturn
+func (r *registry) build(p *dto.RestaurantPaymentProviderDto) (PaymentProvider, error) {
+	key := p.RestaurantID.String() + "/" + string(p.Provider)
+
+	cached, ok := r.cache.Load(key)
+	if ok && cached.(*cachedProvider).updatedAt.Equal(p.UpdatedAt) { //nolint:forcetypeassert
+		return cached.(*cachedProvider).provider, nil //nolint:forcetypeassert
+	}
+
+	err := ValidateCredentials(p.Provider, p.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider PaymentProvider
+
+	switch p.Provider {
+	case db.OrdersPaymentProviderStripe:
+		provider = NewStripePaymentProvider(
+			NewStripeClient(p.Credentials.SecretKey, r.cfg.StripeAPIURL),
+			p.Credentials.WebhookSecret,
+		)
+	case db.OrdersPaymentProviderKlix:
+		provider = NewKlixPaymentProvider(
+			r.cfg.KlixAPIURL,
+			p.Credentials.BrandID,
+			p.Credentials.SecretKey,
+			p.Credentials.PublicKey,
+			r.cfg.WebhooksURL,
+		)
+	case db.OrdersPaymentProviderMock,
+		db.OrdersPaymentProviderCash,
+		db.OrdersPaymentProviderTerminal:
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotSupported, p.Provider)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotSupported, p.Provider)
+	}
+
+	r.cache.Store(key, &cachedProvider{updatedAt: p.UpdatedAt, provider: provider})
+
+	return provider, nil
+}
+
+// ValidateCredentials checks that credentials contain everything provider needs.
+func ValidateCredentials(
+	provider db.OrdersPaymentProvider,
+	credentials *dto.ProviderCredentialsDto,
+) error {
+	if credentials == nil {
+		return ErrMissingCredentials
+	}
+
+	switch provider {
+	case db.OrdersPaymentProviderStripe:
+		if credentials.SecretKey == "" || credentials.WebhookSecret == "" {
+			return fmt.Errorf("%w: stripe requires secret_key and webhook_secret",
+				ErrMissingCredentials)
+		}
+	case db.OrdersPaymentProviderKlix:
+		if credentials.BrandID == "" || credentials.SecretKey == "" ||
+			credentials.PublicKey == "" {
+			return fmt.Errorf("%w: klix requires brand_id, secret_key and public_key",
+				ErrMissingCredentials)
+		}
+
+		_, err := parseKlixPublicKey(credentials.PublicKey)
+		if err != nil {
+			return err
+		}
+	case db.OrdersPaymentProviderMock,
+		db.OrdersPaymentProviderCash,
+		db.OrdersPaymentProviderTerminal:
+		return fmt.Errorf("%w: %s", ErrProviderNotSupported, provider)
+	default:
+		return fmt.Errorf("%w: %s", ErrProviderNotSupported, provider)
+	}
+
+	return nil
+}
+
+// WebhookURL returns provider's webhook url for the restaurant: '<webhooksURL>/<provider>/<restaurant_id>'.
+func WebhookURL(
+	webhooksURL string,
+	provider db.OrdersPaymentProvider,
+	restaurantID uuid.UUID,
+) string {
+	return webhooksURL + "/" + string(provider) + "/" + restaurantID.String()
+}
@@ -0,0 +1,217 @@
+package paymentproviders
+
+import (
+	"context"
+	"errors"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStoreFailed = errors.New("store failed")
+
+type fakeCredentialsStore struct {
+	providers map[uuid.UUID][]*dto.RestaurantPaymentProviderDto
+}
+
+func (s *fakeCredentialsStore) GetRestaurantPaymentProviders(
+	_ context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.RestaurantPaymentProviderDto, error) {
+	if restaurantID == uuid.Max {
+		return nil, errStoreFailed
+	}
+
+	return s.providers[restaurantID], nil
+}
+
+func newTestRegistry(platform PaymentProvider) *registry {
+	store := &fakeCredentialsStore{
+		providers: map[uuid.UUID][]*dto.RestaurantPaymentProviderDto{
+			testRestaurantID: {
+				{
+					RestaurantID: testRestaurantID,
+					Provider:     db.OrdersPaymentProviderStripe,
+					Credentials: &dto.ProviderCredentialsDto{
+						SecretKey:     "sk_test",
+						WebhookSecret: "whsec_test",
+					},
+					IsDefault: true,
+					UpdatedAt: time.Now(),
+				},
+			},
+		},
+	}
+
+	return NewRegistry(
+		store,
+		&RegistryConfig{
+			WebhooksURL:  "http://dine.test/webhooks",
+			KlixAPIURL:   "",
+			StripeAPIURL: "",
+		},
+		platform,
+		db.OrdersPaymentProviderMock,
+	)
+}
+
+func TestRegistry_Get(t *testing.T) {
+	t.Parallel()
+
+	platform := NewMockPaymentProvider("http://dine.test", "secret")
+	r := newTestRegistry(platform)
+
+	provider, err := r.Get(context.Background(), testRestaurantID, db.OrdersPaymentProviderStripe)
+	require.NoError(t, err)
+	assert.IsType(t, &StripePaymentProvider{}, provider)
+
+	cached, err := r.Get(context.Background(), testRestaurantID, db.OrdersPaymentProviderStripe)
+	require.NoError(t, err)
+	assert.Same(t, provider, cached)
+
+	provider, err = r.Get(context.Background(), uuid.New(), db.OrdersPaymentProviderMock)
+	require.NoError(t, err)
+	assert.Same(t, platform, provider)
+
+	_, err = r.Get(context.Background(), testRestaurantID, db.OrdersPaymentProviderKlix)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+
+	_, err = r.Get(context.Background(), uuid.Max, db.OrdersPaymentProviderStripe)
+	require.ErrorIs(t, err, errStoreFailed)
+}
+
+func TestRegistry_GetOwn(t *testing.T) {
+	t.Parallel()
+
+	platform := NewMockPaymentProvider("http://dine.test", "secret")
+	r := newTestRegistry(platform)
+
+	provider, err := r.GetOwn(
+		context.Background(),
+		testRestaurantID,
+		db.OrdersPaymentProviderStripe,
+	)
+	require.NoError(t, err)
+	assert.IsType(t, &StripePaymentProvider{}, provider)
+
+	_, err = r.GetOwn(context.Background(), uuid.New(), db.OrdersPaymentProviderMock)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+
+	_, err = r.GetOwn(context.Background(), uuid.Max, db.OrdersPaymentProviderStripe)
+	require.ErrorIs(t, err, errStoreFailed)
+}
+
+func TestRegistry_GetDefault(t *testing.T) {
+	t.Parallel()
+
+	platform := NewMockPaymentProvider("http://dine.test", "secret")
+	r := newTestRegistry(platform)
+
+	provider, err := r.GetDefault(context.Background(), testRestaurantID)
+	require.NoError(t, err)
+	assert.IsType(t, &StripePaymentProvider{}, provider)
+
+	provider, err = r.GetDefault(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Same(t, platform, provider)
+
+	_, err = newTestRegistry(nil).GetDefault(context.Background(), uuid.New())
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+}
+
+func TestRegistry_GetPlatform(t *testing.T) {
+	t.Parallel()
+
+	platform := NewMockPaymentProvider("http://dine.test", "secret")
+	r := newTestRegistry(platform)
+
+	provider, err := r.GetPlatform(db.OrdersPaymentProviderMock)
+	require.NoError(t, err)
+	assert.Same(t, platform, provider)
+
+	_, err = r.GetPlatform(db.OrdersPaymentProviderStripe)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+
+	_, err = newTestRegistry(nil).GetPlatform(db.OrdersPaymentProviderMock)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+}
+
+func TestRegistry_WebhookURL(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRegistry(nil)
+
+	assert.Equal(
+		t,
+		"http://dine.test/webhooks/klix/"+testRestaurantID.String(),
+		r.WebhookURL(testRestaurantID, db.OrdersPaymentProviderKlix),
+	)
+}
+
+func TestValidateCredentials(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		provider    db.OrdersPaymentProvider
+		credentials *dto.ProviderCredentialsDto
+		wantErr     error
+	}{
+		{
+			"stripe",
+			db.OrdersPaymentProviderStripe,
+			&dto.ProviderCredentialsDto{SecretKey: "sk", WebhookSecret: "whsec"},
+			nil,
+		},
+		{
+			"no credentials",
+			db.OrdersPaymentProviderStripe,
+			nil,
+			ErrMissingCredentials,
+		},
+		{
+			"stripe without webhook secret",
+			db.OrdersPaymentProviderStripe,
+			&dto.ProviderCredentialsDto{SecretKey: "sk"},
+			ErrMissingCredentials,
+		},
+		{
+			"klix without public key",
+			db.OrdersPaymentProviderKlix,
+			&dto.ProviderCredentialsDto{BrandID: "brand", SecretKey: "secret"},
+			ErrMissingCredentials,
+		},
+		{
+			"klix with invalid public key",
+			db.OrdersPaymentProviderKlix,
+			&dto.ProviderCredentialsDto{BrandID: "brand", SecretKey: "secret", PublicKey: "key"},
+			ErrInvalidKlixPublicKey,
+		},
+		{
+			"mock",
+			db.OrdersPaymentProviderMock,
+			&dto.ProviderCredentialsDto{SecretKey: "sk"},
+			ErrProviderNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateCredentials(tt.provider, tt.credentials)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
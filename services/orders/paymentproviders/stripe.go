@@ -8,130 +8,465 @@ import (
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v84"
-	"github.com/stripe/stripe-go/v84/checkout/session"
-	"github.com/stripe/stripe-go/v84/webhook"
 )
 
-var (
-	// ErrUnknownWebhookEventType is returned when unknown Stripe webhook event is received.
-	ErrUnknownWebhookEventType = errors.New("unhandled event type")
-	// ErrOrderIDMissingInMetadata is returned when 'order_id' is missing from Stripe payment intent metadata.
-	ErrOrderIDMissingInMetadata = errors.New("order_id missing from payment metadata")
-)
+// ErrOrderIDMissingInMetadata is returned when 'order_id' is missing from provider's payment metadata.
+var ErrOrderIDMissingInMetadata = errors.New("order_id missing from payment metadata")
 
-const metadataKeyOrderID = "order_id"
+const (
+	metadataKeyOrderID          = "order_id"
+	metadataKeyPaymentAttemptID = "payment_attempt_id"
+	metadataKeyRefundID         = "refund_id"
+	metadataKeyRefundReason     = "refund_reason"
+)
 
 // StripePaymentProvider implements the PaymentProvider interface.
+// Every instance uses its own client, so restaurants are paid into their own Stripe accounts.
 type StripePaymentProvider struct {
 	webhookSecret string
+	client        *stripe.Client
 }
 
-// NewStripePaymentProvider creates an instance of stripe payment provider
-// it panics if secretKey is not provided.
-func NewStripePaymentProvider(secretKey, webhookSecret string) *StripePaymentProvider {
-	if secretKey == "" || webhookSecret == "" {
-		panic("secretKey and webhookSecret is required for StripePaymentProvider")
+// NewStripeClient creates Stripe API client for the secret key, apiURL replaces Stripe's API url
+// and is meant for local stand-ins, it's empty in production.
+// It panics if secretKey is not provided.
+func NewStripeClient(secretKey, apiURL string) *stripe.Client {
+	if secretKey == "" {
+		panic("secretKey is required for stripe client")
+	}
+
+	if apiURL == "" {
+		return stripe.NewClient(secretKey)
 	}
 
-	stripe.Key = secretKey
+	backends := stripe.NewBackendsWithConfig(&stripe.BackendConfig{
+		URL: stripe.String(strings.TrimSuffix(apiURL, "/")),
+	})
+
+	return stripe.NewClient(secretKey, stripe.WithBackends(backends))
+}
+
+// NewStripePaymentProvider creates an instance of stripe payment provider on top of the client
+// it panics if client or webhookSecret is not provided.
+func NewStripePaymentProvider(client *stripe.Client, webhookSecret string) *StripePaymentProvider {
+	if client == nil || webhookSecret == "" {
+		panic("client and webhookSecret is required for StripePaymentProvider")
+	}
 
 	return &StripePaymentProvider{
 		webhookSecret: webhookSecret,
+		client:        client,
 	}
 }
 
 // CreateCheckoutSession creates checkout session for provided order items and returns it's url.
 func (p *StripePaymentProvider) CreateCheckoutSession(
-	_ context.Context,
+	ctx context.Context,
 	reqDto *dto.CheckoutSessionRequestDto,
 ) (*dto.CheckoutSessionResponseDto, error) {
 	order := reqDto.OrderDto
 
 	lineItems := p.createLineItems(order)
 
-	params := &stripe.CheckoutSessionParams{
+	params := &stripe.CheckoutSessionCreateParams{
 		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
 		SuccessURL: stripe.String(reqDto.SuccessURL),
 		CancelURL:  stripe.String(reqDto.CancelURL),
-		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+		// order id is set on the session too, expired sessions never get a payment intent
+		Metadata: map[string]string{
+			metadataKeyOrderID:          reqDto.OrderDto.ID.String(),
+			metadataKeyPaymentAttemptID: reqDto.PaymentAttemptID.String(),
+		},
+		PaymentIntentData: &stripe.CheckoutSessionCreatePaymentIntentDataParams{
 			Metadata: map[string]string{
-				metadataKeyOrderID: reqDto.OrderDto.ID.String(),
+				metadataKeyOrderID:          reqDto.OrderDto.ID.String(),
+				metadataKeyPaymentAttemptID: reqDto.PaymentAttemptID.String(),
 			},
 		},
 		LineItems: lineItems,
 	}
 
-	s, err := session.New(params)
+	if order.DiscountInCents > 0 && order.AmountPaidInCents == 0 {
+		// outstanding balance line of partially paid order already has discounts taken off
+		couponID, err := p.createDiscountCoupon(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Discounts = []*stripe.CheckoutSessionCreateDiscountParams{
+			{Coupon: stripe.String(couponID)},
+		}
+	}
+
+	s, err := p.client.V1CheckoutSessions.Create(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("creating stripe checkout session: %w", err)
 	}
 
 	respDto := &dto.CheckoutSessionResponseDto{
-		URL:      s.URL,
-		Provider: db.OrdersPaymentProviderStripe,
+		URL:              s.URL,
+		Provider:         db.OrdersPaymentProviderStripe,
+		SessionID:        s.ID,
+		PaymentAttemptID: reqDto.PaymentAttemptID,
+		AmountInCents:    0,
 	}
 
 	return respDto, nil
 }
 
-// VerifySuccessWebhookEvent handles successful payment webhook request.
-func (p *StripePaymentProvider) VerifySuccessWebhookEvent(
+// Refund refunds provided amount of the payment, the refund is confirmed later with a webhook.
+func (p *StripePaymentProvider) Refund(
+	ctx context.Context,
+	reqDto *dto.ProviderRefundRequestDto,
+) (*dto.RefundDto, error) {
+	params := &stripe.RefundCreateParams{
+		PaymentIntent: stripe.String(reqDto.Payment.ProviderPaymentID),
+		Amount:        stripe.Int64(int64(reqDto.AmountInCents)),
+		Reason:        stripe.String(string(stripe.RefundReasonRequestedByCustomer)),
+		Metadata: map[string]string{
+			metadataKeyOrderID:      reqDto.Payment.OrderID.String(),
+			metadataKeyRefundID:     reqDto.RefundID.String(),
+			metadataKeyRefundReason: reqDto.Reason,
+		},
+	}
+
+	r, err := p.client.V1Refunds.Create(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("creating stripe refund: %w", err)
+	}
+
+	respDto := &dto.RefundDto{
+		ID:               reqDto.RefundID,
+		PaymentID:        reqDto.Payment.ID,
+		OrderID:          reqDto.Payment.OrderID,
+		AmountInCents:    int(r.Amount),
+		Currency:         string(r.Currency),
+		Provider:         db.OrdersPaymentProviderStripe,
+		ProviderRefundID: r.ID,
+		Status:           refundStatusFromStripe(r.Status),
+		Reason:           reqDto.Reason,
+	}
+
+	return respDto, nil
+}
+
+// ListCharges lists succeeded payment intents created in the range, payment intent's id is what
+// payments are saved with. Refunded payment intents stay succeeded, so they are listed too.
+func (p *StripePaymentProvider) ListCharges(
+	ctx context.Context,
+	reqDto *dto.ListChargesRequestDto,
+) ([]*dto.ProviderChargeDto, error) {
+	params := &stripe.PaymentIntentListParams{
+		CreatedRange: &stripe.RangeQueryParams{
+			GreaterThanOrEqual: reqDto.From.Unix(),
+			LesserThan:         reqDto.To.Unix(),
+		},
+	}
+
+	var charges []*dto.ProviderChargeDto
+
+	for pi, err := range p.client.V1PaymentIntents.List(ctx, params) {
+		if err != nil {
+			return nil, fmt.Errorf("listing stripe payment intents: %w", err)
+		}
+
+		if pi.Status != stripe.PaymentIntentStatusSucceeded {
+			continue
+		}
+
+		charges = append(charges, &dto.ProviderChargeDto{
+			ProviderPaymentID: pi.ID,
+			AmountInCents:     int(pi.AmountReceived),
+			Currency:          string(pi.Currency),
+			CreatedAt:         time.Unix(pi.Created, 0),
+		})
+	}
+
+	return charges, nil
+}
+
+// ParseWebhookEvent verifies Stripe webhook signature and maps the event to ProviderEventDto.
+// Partial charge refunds are ignored, those are either ours and tracked with refund events,
+// or done from Stripe dashboard and have to be reconciled manually.
+func (p *StripePaymentProvider) ParseWebhookEvent(
 	payload []byte,
 	header http.Header,
-) (*dto.PaymentDto, error) {
+) (*dto.ProviderEventDto, error) {
 	sigHeader := header.Get("Stripe-Signature")
 
-	event, err := webhook.ConstructEvent(payload, sigHeader, p.webhookSecret)
+	event, err := p.client.ConstructEvent(payload, sigHeader, p.webhookSecret)
 	if err != nil {
 		return nil, fmt.Errorf("veryfing stripe webhook signature: %w", err)
 	}
 
-	if event.Type != "payment_intent.succeeded" {
-		return nil, fmt.Errorf("%w: %s", ErrUnknownWebhookEventType, event.Type)
+	respDto := &dto.ProviderEventDto{
+		ID:                event.ID,
+		Type:              dto.ProviderEventIgnored,
+		OrderID:           uuid.Nil,
+		PaymentAttemptID:  uuid.Nil,
+		SessionID:         "",
+		ProviderPaymentID: "",
+		Payment:           nil,
+		Refund:            nil,
+	}
+
+	switch event.Type {
+	case stripe.EventTypePaymentIntentSucceeded:
+		err = parseStripePaymentSucceeded(event.Data.Raw, respDto)
+	case stripe.EventTypePaymentIntentPaymentFailed:
+		err = parseStripePaymentFailed(event.Data.Raw, respDto)
+	case stripe.EventTypeCheckoutSessionExpired:
+		err = parseStripeCheckoutExpired(event.Data.Raw, respDto)
+	case stripe.EventTypeChargeRefunded:
+		err = parseStripeChargeRefunded(event.Data.Raw, respDto)
+	case stripe.EventTypeChargeDisputeCreated:
+		err = parseStripeDisputeCreated(event.Data.Raw, respDto)
+	case stripe.EventTypeRefundCreated, stripe.EventTypeRefundUpdated, stripe.EventTypeRefundFailed:
+		err = parseStripeRefundUpdated(event.Data.Raw, respDto)
 	}
 
+	if err != nil {
+		return nil, fmt.Errorf("parsing stripe %s event: %w", event.Type, err)
+	}
+
+	return respDto, nil
+}
+
+func parseStripePaymentSucceeded(raw []byte, event *dto.ProviderEventDto) error {
 	var pi stripe.PaymentIntent
 
-	err = json.Unmarshal(event.Data.Raw, &pi)
+	err := json.Unmarshal(raw, &pi)
 	if err != nil {
-		return nil, fmt.Errorf("unmarhsaling payment intent: %w", err)
+		return fmt.Errorf("unmarhsaling payment intent: %w", err)
 	}
 
-	orderIDstr, ok := pi.Metadata[metadataKeyOrderID]
+	orderID, err := orderIDFromMetadata(pi.Metadata)
+	if err != nil {
+		return err
+	}
+
+	event.Type = dto.ProviderEventPaymentSucceeded
+	event.OrderID = orderID
+	event.PaymentAttemptID = paymentAttemptIDFromMetadata(pi.Metadata)
+	event.ProviderPaymentID = pi.ID
+	event.Payment = &dto.PaymentDto{
+		ID:                    uuid.New(),
+		OrderID:               orderID,
+		AmountInCents:         int(pi.AmountReceived),
+		Provider:              db.OrdersPaymentProviderStripe,
+		ProviderPaymentID:     pi.ID,
+		Currency:              string(pi.Currency),
+		RefundedAmountInCents: 0,
+		Status:                db.OrdersPaymentStatusSucceeded,
+		TenderedInCents:       nil,
+		ChangeDueInCents:      nil,
+		RecordedBy:            nil,
+	}
+
+	return nil
+}
+
+func parseStripePaymentFailed(raw []byte, event *dto.ProviderEventDto) error {
+	var pi stripe.PaymentIntent
+
+	err := json.Unmarshal(raw, &pi)
+	if err != nil {
+		return fmt.Errorf("unmarhsaling payment intent: %w", err)
+	}
+
+	orderID, err := orderIDFromMetadata(pi.Metadata)
+	if err != nil {
+		return err
+	}
+
+	event.Type = dto.ProviderEventPaymentFailed
+	event.OrderID = orderID
+	event.PaymentAttemptID = paymentAttemptIDFromMetadata(pi.Metadata)
+	event.ProviderPaymentID = pi.ID
+
+	return nil
+}
+
+func parseStripeCheckoutExpired(raw []byte, event *dto.ProviderEventDto) error {
+	var s stripe.CheckoutSession
+
+	err := json.Unmarshal(raw, &s)
+	if err != nil {
+		return fmt.Errorf("unmarhsaling checkout session: %w", err)
+	}
+
+	orderID, err := orderIDFromMetadata(s.Metadata)
+	if err != nil {
+		return err
+	}
+
+	event.Type = dto.ProviderEventCheckoutExpired
+	event.OrderID = orderID
+	event.PaymentAttemptID = paymentAttemptIDFromMetadata(s.Metadata)
+	event.SessionID = s.ID
+
+	return nil
+}
+
+func parseStripeChargeRefunded(raw []byte, event *dto.ProviderEventDto) error {
+	var charge stripe.Charge
+
+	err := json.Unmarshal(raw, &charge)
+	if err != nil {
+		return fmt.Errorf("unmarhsaling charge: %w", err)
+	}
+
+	if !charge.Refunded || charge.PaymentIntent == nil {
+		return nil
+	}
+
+	event.Type = dto.ProviderEventPaymentRefunded
+	event.ProviderPaymentID = charge.PaymentIntent.ID
+
+	return nil
+}
+
+func parseStripeDisputeCreated(raw []byte, event *dto.ProviderEventDto) error {
+	var dispute stripe.Dispute
+
+	err := json.Unmarshal(raw, &dispute)
+	if err != nil {
+		return fmt.Errorf("unmarhsaling dispute: %w", err)
+	}
+
+	if dispute.PaymentIntent == nil {
+		return nil
+	}
+
+	event.Type = dto.ProviderEventPaymentDisputed
+	event.ProviderPaymentID = dispute.PaymentIntent.ID
+
+	return nil
+}
+
+func parseStripeRefundUpdated(raw []byte, event *dto.ProviderEventDto) error {
+	var r stripe.Refund
+
+	err := json.Unmarshal(raw, &r)
+	if err != nil {
+		return fmt.Errorf("unmarhsaling refund: %w", err)
+	}
+
+	event.Type = dto.ProviderEventRefundUpdated
+	event.Refund = &dto.RefundDto{
+		ID:               refundIDFromMetadata(r.Metadata),
+		AmountInCents:    int(r.Amount),
+		Currency:         string(r.Currency),
+		Provider:         db.OrdersPaymentProviderStripe,
+		ProviderRefundID: r.ID,
+		Status:           refundStatusFromStripe(r.Status),
+	}
+
+	return nil
+}
+
+func orderIDFromMetadata(metadata map[string]string) (uuid.UUID, error) {
+	orderIDstr, ok := metadata[metadataKeyOrderID]
 	if !ok || orderIDstr == "" {
-		return nil, ErrOrderIDMissingInMetadata
+		return uuid.Nil, ErrOrderIDMissingInMetadata
 	}
 
 	orderID, err := uuid.Parse(orderIDstr)
 	if err != nil {
-		return nil, fmt.Errorf("parsing orderID from payment intent: %w", err)
+		return uuid.Nil, fmt.Errorf("parsing orderID from metadata: %w", err)
 	}
 
-	respDto := &dto.PaymentDto{
-		ID:                uuid.New(),
-		OrderID:           orderID,
-		AmountInCents:     int(pi.AmountReceived),
-		Provider:          db.OrdersPaymentProviderStripe,
-		ProviderPaymentID: pi.ID,
-		Currency:          string(pi.Currency),
+	return orderID, nil
+}
+
+// paymentAttemptIDFromMetadata returns uuid.Nil for sessions created before attempts were tracked,
+// those are still matched by session id when possible.
+func paymentAttemptIDFromMetadata(metadata map[string]string) uuid.UUID {
+	attemptID, err := uuid.Parse(metadata[metadataKeyPaymentAttemptID])
+	if err != nil {
+		return uuid.Nil
 	}
 
-	return respDto, nil
+	return attemptID
+}
+
+// refundIDFromMetadata returns uuid.Nil for refunds made outside of the app, those are matched
+// by provider's refund id only.
+func refundIDFromMetadata(metadata map[string]string) uuid.UUID {
+	refundID, err := uuid.Parse(metadata[metadataKeyRefundID])
+	if err != nil {
+		return uuid.Nil
+	}
+
+	return refundID
+}
+
+func refundStatusFromStripe(status stripe.RefundStatus) db.OrdersRefundStatus {
+	switch status {
+	case stripe.RefundStatusSucceeded:
+		return db.OrdersRefundStatusSucceeded
+	case stripe.RefundStatusFailed, stripe.RefundStatusCanceled:
+		return db.OrdersRefundStatusFailed
+	case stripe.RefundStatusPending, stripe.RefundStatusRequiresAction:
+		return db.OrdersRefundStatusPending
+	default:
+		return db.OrdersRefundStatusPending
+	}
+}
+
+// createDiscountCoupon creates one-off coupon taking order's discounts off the session total.
+func (p *StripePaymentProvider) createDiscountCoupon(
+	ctx context.Context,
+	order *dto.OrderDto,
+) (string, error) {
+	coupon, err := p.client.V1Coupons.Create(ctx, &stripe.CouponCreateParams{
+		AmountOff:      stripe.Int64(int64(order.DiscountInCents)),
+		Currency:       stripe.String(order.Currency),
+		Duration:       stripe.String(string(stripe.CouponDurationOnce)),
+		MaxRedemptions: stripe.Int64(1),
+		Name:           stripe.String("Order discounts"),
+		Metadata: map[string]string{
+			metadataKeyOrderID: order.ID.String(),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating stripe coupon for order discounts: %w", err)
+	}
+
+	return coupon.ID, nil
 }
 
 func (p *StripePaymentProvider) createLineItems(
 	order *dto.OrderDto,
-) []*stripe.CheckoutSessionLineItemParams {
-	lineItems := make([]*stripe.CheckoutSessionLineItemParams, 0, len(order.Items)+1)
+) []*stripe.CheckoutSessionCreateLineItemParams {
+	if order.AmountPaidInCents > 0 {
+		// order is partially paid already, so only outstanding balance is charged
+		return []*stripe.CheckoutSessionCreateLineItemParams{
+			{
+				PriceData: &stripe.CheckoutSessionCreateLineItemPriceDataParams{
+					Currency: stripe.String(order.Currency),
+					ProductData: &stripe.CheckoutSessionCreateLineItemPriceDataProductDataParams{
+						Name: stripe.String("Outstanding balance"),
+					},
+					UnitAmount: stripe.Int64(int64(order.BalanceDueInCents)),
+				},
+				Quantity: stripe.Int64(1),
+			},
+		}
+	}
+
+	lineItems := make([]*stripe.CheckoutSessionCreateLineItemParams, 0, len(order.Items)+1)
 
 	for _, item := range order.Items {
-		lineItem := &stripe.CheckoutSessionLineItemParams{
-			PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+		lineItem := &stripe.CheckoutSessionCreateLineItemParams{
+			PriceData: &stripe.CheckoutSessionCreateLineItemPriceDataParams{
 				Currency: stripe.String(order.Currency),
-				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+				ProductData: &stripe.CheckoutSessionCreateLineItemPriceDataProductDataParams{
 					Name: stripe.String(item.Name),
 				},
 				UnitAmount: stripe.Int64(int64(item.PriceInCents)),
@@ -142,10 +477,10 @@ func (p *StripePaymentProvider) createLineItems(
 		lineItems = append(lineItems, lineItem)
 	}
 
-	tipLineItem := &stripe.CheckoutSessionLineItemParams{
-		PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+	tipLineItem := &stripe.CheckoutSessionCreateLineItemParams{
+		PriceData: &stripe.CheckoutSessionCreateLineItemPriceDataParams{
 			Currency: stripe.String(order.Currency),
-			ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+			ProductData: &stripe.CheckoutSessionCreateLineItemPriceDataProductDataParams{
 				Name: stripe.String("Tip for the staff"),
 			},
 			UnitAmount: stripe.Int64(int64(order.TipAmountInCents)),
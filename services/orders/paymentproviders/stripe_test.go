@@ -1,10 +1,32 @@
 package paymentproviders
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/stripe/stripe-go/v84"
+	"github.com/stripe/stripe-go/v84/webhook"
+)
+
+const (
+	testStripeSecretKey     = "sk_test_123"
+	testStripeWebhookSecret = "whsec_test_123"
+	testStripeSessionID     = "cs_test_123"
+	testStripePaymentID     = "pi_test_123"
+	testStripeCheckoutURL   = "https://checkout.stripe.test/c/pay/cs_test_123"
+	testStripeCouponID      = "coupon_test_123"
 )
 
 //nolint:gochecknoglobals
@@ -15,6 +37,8 @@ var (
 	testItem1Price = 5000
 	testItem2Name  = "Bananinis Miau"
 	testItem2Price = 4000
+
+	testRestaurantID = uuid.MustParse("11111111-1111-4111-8111-111111111111")
 )
 
 func TestCreateLineItems(t *testing.T) {
@@ -50,3 +74,534 @@ func TestCreateLineItems(t *testing.T) {
 		assert.Equal(t, int64(1), *li.Quantity, "line item %d quantity", i)
 	}
 }
+
+func TestCreateLineItems_PartiallyPaid(t *testing.T) {
+	t.Parallel()
+
+	provider := &StripePaymentProvider{}
+
+	order := &dto.OrderDto{
+		Currency:          testCurrency,
+		TipAmountInCents:  testTipAmount,
+		TotalPriceInCents: testItem1Price + testItem2Price,
+		AmountPaidInCents: testItem1Price,
+		BalanceDueInCents: testItem2Price + testTipAmount,
+		Items: []*dto.OrderItemDto{
+			{Name: testItem1Name, PriceInCents: testItem1Price},
+			{Name: testItem2Name, PriceInCents: testItem2Price},
+		},
+	}
+
+	lineItems := provider.createLineItems(order)
+	assert.Len(t, lineItems, 1)
+
+	assert.Equal(t, "Outstanding balance", *lineItems[0].PriceData.ProductData.Name)
+	assert.Equal(t, int64(testItem2Price+testTipAmount), *lineItems[0].PriceData.UnitAmount)
+	assert.Equal(t, testCurrency, *lineItems[0].PriceData.Currency)
+	assert.Equal(t, int64(1), *lineItems[0].Quantity)
+}
+
+func TestRefundStatusFromStripe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status stripe.RefundStatus
+		want   db.OrdersRefundStatus
+	}{
+		{stripe.RefundStatusSucceeded, db.OrdersRefundStatusSucceeded},
+		{stripe.RefundStatusPending, db.OrdersRefundStatusPending},
+		{stripe.RefundStatusRequiresAction, db.OrdersRefundStatusPending},
+		{stripe.RefundStatusFailed, db.OrdersRefundStatusFailed},
+		{stripe.RefundStatusCanceled, db.OrdersRefundStatusFailed},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, refundStatusFromStripe(tt.status), "stripe status %s", tt.status)
+	}
+}
+
+func TestStripeParseWebhookEvent(t *testing.T) {
+	t.Parallel()
+
+	const webhookSecret = "whsec_test"
+
+	provider := NewStripePaymentProvider(NewStripeClient("sk_test", ""), webhookSecret)
+	orderID := uuid.New()
+	attemptID := uuid.New()
+	metadata := fmt.Sprintf(`{"order_id": %q, "payment_attempt_id": %q}`, orderID, attemptID)
+
+	tests := []struct {
+		eventType   stripe.EventType
+		object      string
+		wantType    dto.ProviderEventType
+		wantOrderID uuid.UUID
+		wantPayment string
+	}{
+		{
+			stripe.EventTypePaymentIntentSucceeded,
+			`{"id": "pi_1", "amount_received": 5000, "metadata": ` + metadata + `}`,
+			dto.ProviderEventPaymentSucceeded,
+			orderID,
+			"pi_1",
+		},
+		{
+			stripe.EventTypePaymentIntentPaymentFailed,
+			`{"id": "pi_1", "metadata": ` + metadata + `}`,
+			dto.ProviderEventPaymentFailed,
+			orderID,
+			"pi_1",
+		},
+		{
+			stripe.EventTypeCheckoutSessionExpired,
+			`{"id": "cs_1", "metadata": ` + metadata + `}`,
+			dto.ProviderEventCheckoutExpired,
+			orderID,
+			"",
+		},
+		{
+			stripe.EventTypeChargeRefunded,
+			`{"id": "ch_1", "refunded": true, "payment_intent": "pi_1"}`,
+			dto.ProviderEventPaymentRefunded,
+			uuid.Nil,
+			"pi_1",
+		},
+		{
+			stripe.EventTypeChargeRefunded,
+			`{"id": "ch_1", "refunded": false, "payment_intent": "pi_1"}`,
+			dto.ProviderEventIgnored,
+			uuid.Nil,
+			"",
+		},
+		{
+			stripe.EventTypeChargeDisputeCreated,
+			`{"id": "dp_1", "payment_intent": "pi_1"}`,
+			dto.ProviderEventPaymentDisputed,
+			uuid.Nil,
+			"pi_1",
+		},
+		{
+			stripe.EventTypeRefundUpdated,
+			`{"id": "re_1", "amount": 1000, "currency": "eur", "status": "succeeded"}`,
+			dto.ProviderEventRefundUpdated,
+			uuid.Nil,
+			"",
+		},
+		{
+			stripe.EventTypeCustomerCreated,
+			`{"id": "cus_1"}`,
+			dto.ProviderEventIgnored,
+			uuid.Nil,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.eventType)+" "+string(tt.wantType), func(t *testing.T) {
+			t.Parallel()
+
+			payload := fmt.Appendf(
+				nil,
+				`{"id": "evt_1", "object": "event", "api_version": %q, "type": %q, `+
+					`"data": {"object": %s}}`,
+				stripe.APIVersion,
+				tt.eventType,
+				tt.object,
+			)
+			signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+				Payload: payload,
+				Secret:  webhookSecret,
+			})
+
+			header := http.Header{}
+			header.Set("Stripe-Signature", signed.Header)
+
+			event, err := provider.ParseWebhookEvent(payload, header)
+			require.NoError(t, err)
+
+			assert.Equal(t, "evt_1", event.ID)
+			assert.Equal(t, tt.wantType, event.Type)
+			assert.Equal(t, tt.wantOrderID, event.OrderID)
+			assert.Equal(t, tt.wantPayment, event.ProviderPaymentID)
+
+			if tt.wantOrderID != uuid.Nil {
+				assert.Equal(t, attemptID, event.PaymentAttemptID)
+			}
+		})
+	}
+}
+
+func TestStripeParseWebhookEvent_InvalidSignature(t *testing.T) {
+	t.Parallel()
+
+	provider := NewStripePaymentProvider(NewStripeClient("sk_test", ""), "whsec_test")
+
+	_, err := provider.ParseWebhookEvent([]byte(`{"id": "evt_1"}`), http.Header{})
+	require.Error(t, err)
+}
+
+type stripeProviderTestSuite struct {
+	suite.Suite
+
+	server   *httptest.Server
+	provider *StripePaymentProvider
+
+	lastSessionForm url.Values
+	lastRefundForm  url.Values
+	lastCouponForm  url.Values
+}
+
+func TestStripeProviderTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(stripeProviderTestSuite))
+}
+
+func (suite *stripeProviderTestSuite) SetupTest() {
+	suite.lastSessionForm = nil
+	suite.lastRefundForm = nil
+	suite.lastCouponForm = nil
+	suite.server = httptest.NewServer(suite.stripeStandIn())
+
+	suite.provider = NewStripePaymentProvider(
+		NewStripeClient(testStripeSecretKey, suite.server.URL),
+		testStripeWebhookSecret,
+	)
+}
+
+func (suite *stripeProviderTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// stripeStandIn imitates the part of Stripe API used by the provider.
+func (suite *stripeProviderTestSuite) stripeStandIn() http.Handler {
+	mux := http.NewServeMux()
+
+	authorized := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+testStripeSecretKey {
+				writeStripeError(w, http.StatusUnauthorized, "Invalid API Key provided")
+
+				return
+			}
+
+			next(w, r)
+		}
+	}
+
+	mux.HandleFunc("POST /v1/checkout/sessions", authorized(
+		func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			suite.lastSessionForm = r.PostForm
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":     testStripeSessionID,
+				"object": "checkout.session",
+				"url":    testStripeCheckoutURL,
+				"metadata": map[string]string{
+					metadataKeyOrderID:          r.PostForm.Get("metadata[order_id]"),
+					metadataKeyPaymentAttemptID: r.PostForm.Get("metadata[payment_attempt_id]"),
+				},
+			})
+		},
+	))
+
+	mux.HandleFunc("POST /v1/coupons", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		suite.lastCouponForm = r.PostForm
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":     testStripeCouponID,
+			"object": "coupon",
+		})
+	}))
+
+	mux.HandleFunc("POST /v1/refunds", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		suite.lastRefundForm = r.PostForm
+
+		if r.PostForm.Get("payment_intent") != testStripePaymentID {
+			writeStripeError(w, http.StatusNotFound, "No such payment_intent")
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":       "re_test_123",
+			"object":   "refund",
+			"amount":   json.Number(r.PostForm.Get("amount")),
+			"currency": "eur",
+			"status":   "pending",
+		})
+	}))
+
+	mux.HandleFunc("GET /v1/payment_intents", authorized(
+		func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if query.Get("created[gte]") == "" || query.Get("created[lt]") == "" {
+				writeStripeError(w, http.StatusBadRequest, "created range is required")
+
+				return
+			}
+
+			page := map[string]any{
+				"object":   "list",
+				"url":      "/v1/payment_intents",
+				"has_more": true,
+				"data": []map[string]any{
+					stripePaymentIntent(testStripePaymentID, "succeeded", 1500),
+					stripePaymentIntent("pi_unpaid", "requires_payment_method", 0),
+				},
+			}
+
+			if query.Get("starting_after") != "" {
+				page["has_more"] = false
+				page["data"] = []map[string]any{stripePaymentIntent("pi_second", "succeeded", 700)}
+			}
+
+			_ = json.NewEncoder(w).Encode(page)
+		},
+	))
+
+	return mux
+}
+
+func writeStripeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]string{"type": "invalid_request_error", "message": message},
+	})
+}
+
+func stripePaymentIntent(id, status string, amount int) map[string]any {
+	return map[string]any{
+		"id":              id,
+		"object":          "payment_intent",
+		"status":          status,
+		"amount_received": amount,
+		"currency":        "eur",
+		"created":         1,
+	}
+}
+
+// signedEvent returns Stripe event with provided object, signed the way Stripe signs webhooks.
+func (suite *stripeProviderTestSuite) signedEvent(
+	eventType stripe.EventType,
+	object map[string]any,
+	secret string,
+) ([]byte, http.Header) {
+	payload, err := json.Marshal(map[string]any{
+		"id":          "evt_test_123",
+		"object":      "event",
+		"api_version": stripe.APIVersion,
+		"type":        eventType,
+		"data":        map[string]any{"object": object},
+	})
+	suite.Require().NoError(err)
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: payload,
+		Secret:  secret,
+	})
+
+	header := http.Header{}
+	header.Set("Stripe-Signature", signed.Header)
+
+	return payload, header
+}
+
+func (suite *stripeProviderTestSuite) createCheckoutSession() *dto.CheckoutSessionRequestDto {
+	reqDto := &dto.CheckoutSessionRequestDto{
+		OrderDto: &dto.OrderDto{
+			ID:               uuid.New(),
+			RestaurantID:     testRestaurantID,
+			Currency:         testCurrency,
+			TipAmountInCents: testTipAmount,
+			Items: []*dto.OrderItemDto{
+				{Name: testItem1Name, PriceInCents: testItem1Price},
+			},
+		},
+		SuccessURL:       "http://localhost/success",
+		CancelURL:        "http://localhost/cancel",
+		Provider:         nil,
+		PaymentAttemptID: uuid.New(),
+	}
+
+	respDto, err := suite.provider.CreateCheckoutSession(context.Background(), reqDto)
+	suite.Require().NoError(err)
+
+	suite.Equal(testStripeCheckoutURL, respDto.URL)
+	suite.Equal(testStripeSessionID, respDto.SessionID)
+	suite.Equal(db.OrdersPaymentProviderStripe, respDto.Provider)
+	suite.Equal(reqDto.PaymentAttemptID, respDto.PaymentAttemptID)
+
+	return reqDto
+}
+
+func (suite *stripeProviderTestSuite) TestCreateCheckoutSession_Success() {
+	reqDto := suite.createCheckoutSession()
+
+	form := suite.lastSessionForm
+	suite.Require().NotNil(form)
+	suite.Equal("payment", form.Get("mode"))
+	suite.Equal(reqDto.SuccessURL, form.Get("success_url"))
+	suite.Equal(reqDto.OrderDto.ID.String(), form.Get("metadata[order_id]"))
+	suite.Equal(
+		reqDto.PaymentAttemptID.String(),
+		form.Get("payment_intent_data[metadata][payment_attempt_id]"),
+	)
+	suite.Equal(testItem1Name, form.Get("line_items[0][price_data][product_data][name]"))
+	suite.Equal(fmt.Sprint(testItem1Price), form.Get("line_items[0][price_data][unit_amount]"))
+	suite.Equal(fmt.Sprint(testTipAmount), form.Get("line_items[1][price_data][unit_amount]"))
+}
+
+func (suite *stripeProviderTestSuite) TestCreateCheckoutSession_NoDiscounts() {
+	suite.createCheckoutSession()
+
+	suite.Nil(suite.lastCouponForm)
+	suite.Empty(suite.lastSessionForm.Get("discounts[0][coupon]"))
+}
+
+func (suite *stripeProviderTestSuite) TestCreateCheckoutSession_Discounts() {
+	const discount = 1500
+
+	reqDto := &dto.CheckoutSessionRequestDto{
+		OrderDto: &dto.OrderDto{
+			ID:                uuid.New(),
+			RestaurantID:      testRestaurantID,
+			Currency:          testCurrency,
+			TipAmountInCents:  testTipAmount,
+			TotalPriceInCents: testItem1Price,
+			DiscountInCents:   discount,
+			Items: []*dto.OrderItemDto{
+				{Name: testItem1Name, PriceInCents: testItem1Price},
+			},
+		},
+		SuccessURL:       "http://localhost/success",
+		CancelURL:        "http://localhost/cancel",
+		Provider:         nil,
+		PaymentAttemptID: uuid.New(),
+	}
+
+	_, err := suite.provider.CreateCheckoutSession(context.Background(), reqDto)
+	suite.Require().NoError(err)
+
+	coupon := suite.lastCouponForm
+	suite.Require().NotNil(coupon)
+	suite.Equal(fmt.Sprint(discount), coupon.Get("amount_off"))
+	suite.Equal(testCurrency, coupon.Get("currency"))
+	suite.Equal("once", coupon.Get("duration"))
+	suite.Equal("1", coupon.Get("max_redemptions"))
+
+	suite.Equal(testStripeCouponID, suite.lastSessionForm.Get("discounts[0][coupon]"))
+	suite.Equal(
+		testItem1Name,
+		suite.lastSessionForm.Get("line_items[0][price_data][product_data][name]"),
+	)
+}
+
+func (suite *stripeProviderTestSuite) TestCreateCheckoutSession_InvalidKey() {
+	provider := NewStripePaymentProvider(
+		NewStripeClient("sk_test_wrong", suite.server.URL),
+		testStripeWebhookSecret,
+	)
+
+	_, err := provider.CreateCheckoutSession(context.Background(), &dto.CheckoutSessionRequestDto{
+		OrderDto:         &dto.OrderDto{ID: uuid.New(), Currency: testCurrency},
+		SuccessURL:       "http://localhost/success",
+		CancelURL:        "http://localhost/cancel",
+		Provider:         nil,
+		PaymentAttemptID: uuid.New(),
+	})
+	suite.Require().Error(err)
+
+	var stripeErr *stripe.Error
+	suite.Require().ErrorAs(err, &stripeErr)
+	suite.Equal(http.StatusUnauthorized, stripeErr.HTTPStatusCode)
+}
+
+func (suite *stripeProviderTestSuite) TestCheckoutPaid_Webhook() {
+	reqDto := suite.createCheckoutSession()
+
+	// metadata set on payment intent data is copied to the payment intent by Stripe
+	pi := stripePaymentIntent(testStripePaymentID, "succeeded", testItem1Price+testTipAmount)
+	pi["metadata"] = map[string]string{
+		metadataKeyOrderID: suite.lastSessionForm.Get(
+			"payment_intent_data[metadata][order_id]",
+		),
+		metadataKeyPaymentAttemptID: suite.lastSessionForm.Get(
+			"payment_intent_data[metadata][payment_attempt_id]",
+		),
+	}
+
+	payload, header := suite.signedEvent(
+		stripe.EventTypePaymentIntentSucceeded,
+		pi,
+		testStripeWebhookSecret,
+	)
+
+	event, err := suite.provider.ParseWebhookEvent(payload, header)
+	suite.Require().NoError(err)
+
+	suite.Equal(dto.ProviderEventPaymentSucceeded, event.Type)
+	suite.Equal(reqDto.OrderDto.ID, event.OrderID)
+	suite.Equal(reqDto.PaymentAttemptID, event.PaymentAttemptID)
+	suite.Equal(testStripePaymentID, event.Payment.ProviderPaymentID)
+	suite.Equal(testItem1Price+testTipAmount, event.Payment.AmountInCents)
+
+	_, err = suite.provider.ParseWebhookEvent(append(payload, ' '), header)
+	suite.Require().Error(err, "tampered payload")
+
+	payload, header = suite.signedEvent(
+		stripe.EventTypePaymentIntentSucceeded,
+		pi,
+		"whsec_someone_else",
+	)
+
+	_, err = suite.provider.ParseWebhookEvent(payload, header)
+	suite.Require().Error(err, "signed with another secret")
+}
+
+func (suite *stripeProviderTestSuite) TestRefund() {
+	reqDto := &dto.ProviderRefundRequestDto{
+		RefundID:      uuid.New(),
+		RestaurantID:  testRestaurantID,
+		AmountInCents: 500,
+		Reason:        "cold soup",
+		Payment: &dto.PaymentDto{
+			ID:                uuid.New(),
+			OrderID:           uuid.New(),
+			ProviderPaymentID: testStripePaymentID,
+		},
+	}
+
+	refund, err := suite.provider.Refund(context.Background(), reqDto)
+	suite.Require().NoError(err)
+
+	suite.Equal("re_test_123", refund.ProviderRefundID)
+	suite.Equal(500, refund.AmountInCents)
+	suite.Equal(db.OrdersRefundStatusPending, refund.Status)
+	suite.Equal(reqDto.RefundID.String(), suite.lastRefundForm.Get("metadata[refund_id]"))
+
+	reqDto.Payment.ProviderPaymentID = "pi_unknown"
+
+	_, err = suite.provider.Refund(context.Background(), reqDto)
+	suite.Require().Error(err)
+}
+
+func (suite *stripeProviderTestSuite) TestListCharges() {
+	charges, err := suite.provider.ListCharges(context.Background(), &dto.ListChargesRequestDto{
+		From: time.Unix(1, 0),
+		To:   time.Unix(10, 0), //nolint:mnd
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(charges, 2)
+
+	suite.Equal(&dto.ProviderChargeDto{
+		ProviderPaymentID: testStripePaymentID,
+		AmountInCents:     1500,
+		Currency:          "eur",
+		CreatedAt:         time.Unix(1, 0),
+	}, charges[0])
+	suite.Equal("pi_second", charges[1].ProviderPaymentID)
+}
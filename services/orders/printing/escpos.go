@@ -0,0 +1,131 @@
+// Package printing renders kitchen tickets and receipts as ESC/POS print jobs and sends them
+// to restaurants' networked thermal printers.
+package printing
+
+import (
+	"bytes"
+	"golang-dining-ordering/services/orders/receipts"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ESC/POS control characters.
+const (
+	esc = 0x1b
+	gs  = 0x1d
+	lf  = 0x0a
+)
+
+// codepageWPC1257 is ESC/POS number of Windows-1257 (Baltic) code page, it has all
+// Lithuanian letters.
+const codepageWPC1257 = 51
+
+// Alignment is horizontal alignment of printed lines.
+type Alignment byte
+
+const (
+	// AlignLeft aligns lines to the left edge of the paper.
+	AlignLeft Alignment = 0
+	// AlignCenter centers lines on the paper.
+	AlignCenter Alignment = 1
+	// AlignRight aligns lines to the right edge of the paper.
+	AlignRight Alignment = 2
+)
+
+// Document builds an ESC/POS print job, text is printed in Windows-1257 code page.
+type Document struct {
+	buf bytes.Buffer
+}
+
+// NewDocument starts a print job, it resets the printer and selects the code page.
+func NewDocument() *Document {
+	d := &Document{buf: bytes.Buffer{}}
+	d.buf.Write([]byte{esc, '@', esc, 't', codepageWPC1257})
+
+	return d
+}
+
+// Bold turns emphasized printing on or off.
+func (d *Document) Bold(on bool) *Document {
+	d.buf.Write([]byte{esc, 'E', flag(on)})
+
+	return d
+}
+
+// DoubleHeight turns double height characters on or off, lines keep their width.
+func (d *Document) DoubleHeight(on bool) *Document {
+	d.buf.Write([]byte{gs, '!', flag(on)})
+
+	return d
+}
+
+// Align sets alignment of the following lines.
+func (d *Document) Align(alignment Alignment) *Document {
+	d.buf.Write([]byte{esc, 'a', byte(alignment)})
+
+	return d
+}
+
+// Line prints a line of text, control characters in the text are replaced with spaces so
+// they can't be interpreted as printer commands.
+func (d *Document) Line(text string) *Document {
+	text = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+
+		return r
+	}, text)
+
+	d.buf.Write(receipts.Encode(text, charmap.Windows1257))
+	d.buf.WriteByte(lf)
+
+	return d
+}
+
+// Wrapped prints text wrapped to lines of at most width characters, lines after the first
+// are indented.
+func (d *Document) Wrapped(text string, width int, indent string) *Document {
+	lines := receipts.Wrap(text, width)
+	if len(lines) == 0 {
+		return d
+	}
+
+	d.Line(lines[0])
+
+	rest := strings.Join(lines[1:], " ")
+	for _, line := range receipts.Wrap(rest, width-utf8.RuneCountInString(indent)) {
+		d.Line(indent + line)
+	}
+
+	return d
+}
+
+// Feed feeds the paper by the number of lines.
+func (d *Document) Feed(lines byte) *Document {
+	d.buf.Write([]byte{esc, 'd', lines})
+
+	return d
+}
+
+// Cut feeds the paper to the cutter and cuts it, leaving a small uncut point.
+func (d *Document) Cut() *Document {
+	d.buf.Write([]byte{gs, 'V', 'B', 0})
+
+	return d
+}
+
+// Bytes returns the print job.
+func (d *Document) Bytes() []byte {
+	return d.buf.Bytes()
+}
+
+func flag(on bool) byte {
+	if on {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,58 @@
+package printing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultPort is the port ESC/POS printers accept raw print jobs on.
+const DefaultPort = 9100
+
+// Printer defines method for sending print jobs to a printer.
+type Printer interface {
+	Print(ctx context.Context, job []byte) error
+}
+
+type tcpPrinter struct {
+	addr    string
+	timeout time.Duration
+}
+
+// NewTCPPrinter creates a printer which sends raw print jobs over TCP, the way networked
+// ESC/POS printers accept them on port 9100. Connecting and sending the job each have to
+// finish within the timeout.
+//
+//revive:disable:unexported-return
+func NewTCPPrinter(host string, port int, timeout time.Duration) *tcpPrinter {
+	return &tcpPrinter{
+		addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		timeout: timeout,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (p *tcpPrinter) Print(ctx context.Context, job []byte) error {
+	dialer := net.Dialer{Timeout: p.timeout} //nolint:exhaustruct
+
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("connecting to printer %s: %w", p.addr, err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	err = conn.SetDeadline(time.Now().Add(p.timeout))
+	if err != nil {
+		return fmt.Errorf("setting printer connection deadline: %w", err)
+	}
+
+	_, err = conn.Write(job)
+	if err != nil {
+		return fmt.Errorf("sending print job to printer %s: %w", p.addr, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package printing
+
+import (
+	"bytes"
+	"context"
+	"golang-dining-ordering/services/orders/dto"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type printingTestSuite struct {
+	suite.Suite
+}
+
+func TestPrintingTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(printingTestSuite))
+}
+
+// listen starts a stand-in for a networked printer, every print job it receives is sent to
+// the returned channel.
+func (suite *printingTestSuite) listen() (*net.TCPAddr, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = listener.Close() })
+
+	jobs := make(chan []byte, 1)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			job, _ := io.ReadAll(conn)
+			_ = conn.Close()
+			jobs <- job
+		}
+	}()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	suite.Require().True(ok)
+
+	return addr, jobs
+}
+
+func (suite *printingTestSuite) TestDocument() {
+	got := NewDocument().
+		Align(AlignCenter).
+		Bold(true).
+		DoubleHeight(true).
+		Line("Hi\x1b@").
+		Feed(3).
+		Cut().
+		Bytes()
+
+	suite.Equal([]byte{
+		0x1b, '@', 0x1b, 't', 51,
+		0x1b, 'a', 1,
+		0x1b, 'E', 1,
+		0x1d, '!', 1,
+		'H', 'i', ' ', '@', '\n',
+		0x1b, 'd', 3,
+		0x1d, 'V', 'B', 0,
+	}, got)
+}
+
+func (suite *printingTestSuite) TestLine_LithuanianLetters() {
+	got := NewDocument().Line("ąčęėįšųūž ĄČĘĖĮŠŲŪŽ").Bytes()
+
+	suite.Equal(append([]byte{
+		0x1b, '@', 0x1b, 't', 51,
+		0xe0, 0xe8, 0xe6, 0xeb, 0xe1, 0xf0, 0xf8, 0xfb, 0xfe, ' ',
+		0xc0, 0xc8, 0xc6, 0xcb, 0xc1, 0xd0, 0xd8, 0xdb, 0xde,
+	}, '\n'), got)
+}
+
+func (suite *printingTestSuite) TestWrapped() {
+	got := NewDocument().Wrapped("1 x Cepelinai with sour cream", 12, "  ").Bytes()
+
+	suite.Equal("1 x\n  Cepelinai\n  with sour\n  cream\n", string(got[5:]))
+}
+
+func (suite *printingTestSuite) TestKitchenTicket() {
+	got := KitchenTicket(&dto.KitchenTicketDto{
+		PrinterID:   uuid.New(),
+		PrinterName: "Kitchen",
+		OrderID:     uuid.MustParse("99999999-9999-4999-9999-999999999999"),
+		TableName:   "T1",
+		PrintedAt:   time.Date(2025, time.December, 5, 19, 0, 0, 0, time.UTC),
+		Lines: []*dto.KitchenTicketLineDto{
+			{Name: "Šaltibarščiai", Quantity: 2},
+		},
+	})
+
+	suite.Contains(string(got), "KITCHEN\nTable T1\n")
+	suite.Contains(string(got), "Order 99999999  19:00 UTC\n")
+	suite.True(bytes.Contains(got, []byte{'2', ' ', 'x', ' ', 0xd0, 'a', 'l', 't', 'i'}))
+	suite.True(bytes.HasSuffix(got, []byte{0x1d, 'V', 'B', 0}))
+}
+
+func (suite *printingTestSuite) TestReceipt() {
+	got := Receipt(&dto.ReceiptDto{
+		Number:            42,
+		OrderID:           uuid.MustParse("99999999-9999-4999-9999-999999999999"),
+		IssuedAt:          time.Date(2025, time.December, 5, 19, 0, 0, 0, time.UTC),
+		RestaurantName:    "Test Restaurant",
+		RestaurantAddress: "Gedimino pr. 1, Vilnius",
+		Currency:          "eur",
+		Lines: []*dto.ReceiptLineDto{
+			{Name: "Cepelinai", Quantity: 1, UnitPriceInCents: 900, AmountInCents: 900},
+		},
+		Discounts:       nil,
+		SubtotalInCents: 900,
+		DiscountInCents: 0,
+		TaxRate:         21,
+		TaxInCents:      156,
+		TipInCents:      0,
+		TotalInCents:    900,
+		Payments:        nil,
+	})
+
+	suite.Contains(string(got), "Test Restaurant")
+	suite.Contains(string(got), "Cepelinai")
+	suite.Contains(string(got), "\x1bE\x01\x1d!\x01TOTAL EUR")
+	suite.True(bytes.HasSuffix(got, []byte{0x1d, 'V', 'B', 0}))
+}
+
+func (suite *printingTestSuite) TestTicketLines() {
+	got := TicketLines([]*dto.KitchenItemDto{
+		{OrderItemID: uuid.New(), Name: "Soup", CategoryID: nil, CreatedAt: time.Now()},
+		{OrderItemID: uuid.New(), Name: "Salad", CategoryID: nil, CreatedAt: time.Now()},
+		{OrderItemID: uuid.New(), Name: "Soup", CategoryID: nil, CreatedAt: time.Now()},
+	})
+
+	suite.Equal([]*dto.KitchenTicketLineDto{
+		{Name: "Soup", Quantity: 2},
+		{Name: "Salad", Quantity: 1},
+	}, got)
+}
+
+func (suite *printingTestSuite) TestRouteKitchenItems() {
+	drinks := uuid.New()
+	mains := uuid.New()
+	desserts := uuid.New()
+
+	bar := &dto.PrinterDto{Name: "Bar", CategoryIDs: []uuid.UUID{drinks}, PrintsReceipts: true}
+	receipts := &dto.PrinterDto{Name: "Receipts", CategoryIDs: nil, PrintsReceipts: true}
+	kitchen := &dto.PrinterDto{Name: "Kitchen", CategoryIDs: nil, PrintsReceipts: false}
+	grill := &dto.PrinterDto{Name: "Grill", CategoryIDs: []uuid.UUID{mains}, PrintsReceipts: false}
+
+	beer := &dto.KitchenItemDto{Name: "Beer", CategoryID: &drinks}
+	steak := &dto.KitchenItemDto{Name: "Steak", CategoryID: &mains}
+	cake := &dto.KitchenItemDto{Name: "Cake", CategoryID: &desserts}
+	bread := &dto.KitchenItemDto{Name: "Bread", CategoryID: nil}
+
+	items := []*dto.KitchenItemDto{beer, steak, cake, bread}
+
+	routes, unrouted := RouteKitchenItems(items, []*dto.PrinterDto{bar, receipts, kitchen, grill})
+	suite.Empty(unrouted)
+	suite.Equal([]*Route{
+		{Printer: bar, Items: []*dto.KitchenItemDto{beer}},
+		{Printer: kitchen, Items: []*dto.KitchenItemDto{cake, bread}},
+		{Printer: grill, Items: []*dto.KitchenItemDto{steak}},
+	}, routes)
+
+	routes, unrouted = RouteKitchenItems(items, []*dto.PrinterDto{bar, receipts})
+	suite.Equal([]*Route{{Printer: bar, Items: []*dto.KitchenItemDto{beer}}}, routes)
+	suite.Equal([]*dto.KitchenItemDto{steak, cake, bread}, unrouted)
+}
+
+func (suite *printingTestSuite) TestTCPPrinter_Print() {
+	addr, jobs := suite.listen()
+	job := NewDocument().Line("Table T1").Cut().Bytes()
+
+	err := NewTCPPrinter(addr.IP.String(), addr.Port, time.Second).Print(context.Background(), job)
+	suite.Require().NoError(err)
+
+	select {
+	case got := <-jobs:
+		suite.Equal(job, got)
+	case <-time.After(time.Second):
+		suite.Fail("printer didn't receive the job")
+	}
+}
+
+func (suite *printingTestSuite) TestTCPPrinter_Unavailable() {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	suite.Require().True(ok)
+	suite.Require().NoError(listener.Close())
+
+	err = NewTCPPrinter(addr.IP.String(), addr.Port, time.Second).Print(context.Background(), nil)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "connecting to printer")
+}
@@ -0,0 +1,66 @@
+package printing
+
+import (
+	"golang-dining-ordering/services/orders/dto"
+	"slices"
+)
+
+// Route is a kitchen ticket's items routed to one of restaurant's printers.
+type Route struct {
+	Printer *dto.PrinterDto
+	Items   []*dto.KitchenItemDto
+}
+
+// RouteKitchenItems routes each item to the first printer taking item's category. Items no
+// printer takes go to the first printer without categories that doesn't print receipts,
+// items that can't be routed at all are returned separately. Routes keep printers' order.
+func RouteKitchenItems(
+	items []*dto.KitchenItemDto,
+	printers []*dto.PrinterDto,
+) ([]*Route, []*dto.KitchenItemDto) {
+	var fallback *dto.PrinterDto
+
+	for _, printer := range printers {
+		if len(printer.CategoryIDs) == 0 && !printer.PrintsReceipts {
+			fallback = printer
+
+			break
+		}
+	}
+
+	byPrinter := make(map[*dto.PrinterDto][]*dto.KitchenItemDto, len(printers))
+
+	var unrouted []*dto.KitchenItemDto
+
+	for _, item := range items {
+		printer := fallback
+
+		if item.CategoryID != nil {
+			for _, candidate := range printers {
+				if slices.Contains(candidate.CategoryIDs, *item.CategoryID) {
+					printer = candidate
+
+					break
+				}
+			}
+		}
+
+		if printer == nil {
+			unrouted = append(unrouted, item)
+
+			continue
+		}
+
+		byPrinter[printer] = append(byPrinter[printer], item)
+	}
+
+	routes := make([]*Route, 0, len(byPrinter))
+
+	for _, printer := range printers {
+		if routed, ok := byPrinter[printer]; ok {
+			routes = append(routes, &Route{Printer: printer, Items: routed})
+		}
+	}
+
+	return routes, unrouted
+}
@@ -0,0 +1,94 @@
+package printing
+
+import (
+	"fmt"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/receipts"
+	"strings"
+)
+
+// ticketFeedLines is how many lines are fed after the ticket, so its end passes the cutter.
+const ticketFeedLines = 3
+
+// KitchenTicket renders the kitchen ticket as ESC/POS print job. Items are printed in bold
+// double height so cooks can read them from a distance.
+func KitchenTicket(ticket *dto.KitchenTicketDto) []byte {
+	separator := strings.Repeat("-", receipts.LineWidth)
+
+	doc := NewDocument().
+		Align(AlignCenter).
+		Bold(true).
+		DoubleHeight(true).
+		Line(strings.ToUpper(ticket.PrinterName)).
+		Line("Table " + ticket.TableName).
+		DoubleHeight(false).
+		Bold(false).
+		Line(fmt.Sprintf(
+			"Order %s  %s",
+			ticket.OrderID.String()[:8],
+			ticket.PrintedAt.UTC().Format("15:04 MST"),
+		)).
+		Align(AlignLeft).
+		Line(separator).
+		Bold(true).
+		DoubleHeight(true)
+
+	for _, line := range ticket.Lines {
+		doc.Wrapped(fmt.Sprintf("%d x %s", line.Quantity, line.Name), receipts.LineWidth, "    ")
+	}
+
+	return doc.
+		DoubleHeight(false).
+		Bold(false).
+		Line(separator).
+		Feed(ticketFeedLines).
+		Cut().
+		Bytes()
+}
+
+// Receipt renders the receipt as ESC/POS print job with the same layout as plain text
+// receipt, restaurant's name and the total are printed in bold.
+func Receipt(receipt *dto.ReceiptDto) []byte {
+	lines := receipts.TextLines(receipt)
+	separator := strings.Repeat("-", receipts.LineWidth)
+	doc := NewDocument()
+
+	header := true
+
+	for _, line := range lines {
+		if line == separator {
+			header = false
+		}
+
+		bold := header || strings.HasPrefix(line, "TOTAL ")
+
+		doc.Bold(bold).DoubleHeight(bold && !header).Line(line)
+	}
+
+	return doc.
+		Bold(false).
+		DoubleHeight(false).
+		Feed(ticketFeedLines).
+		Cut().
+		Bytes()
+}
+
+// TicketLines groups kitchen items of the same name into ticket lines, in the order they
+// were first added.
+func TicketLines(items []*dto.KitchenItemDto) []*dto.KitchenTicketLineDto {
+	lines := make([]*dto.KitchenTicketLineDto, 0, len(items))
+	byName := make(map[string]*dto.KitchenTicketLineDto, len(items))
+
+	for _, item := range items {
+		line, ok := byName[item.Name]
+		if !ok {
+			line = &dto.KitchenTicketLineDto{Name: item.Name, Quantity: 0}
+			byName[item.Name] = line
+			lines = append(lines, line)
+		}
+
+		line.Quantity++
+	}
+
+	return lines
+}
@@ -0,0 +1,248 @@
+// Package promotions calculates discounts of restaurants' automatic promotions and promo codes
+// applied to orders.
+package promotions
+
+import (
+	"cmp"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimeLayout is the layout of promotion rules' daily window bounds.
+const TimeLayout = "15:04"
+
+const minutesInHour = 60
+
+// ApplyDiscounts calculates order's discount lines from its promotion rules and promo code
+// and sets order's total discount. Rules are applied first, in the order they were created,
+// and each item is discounted by at most one rule. Promo code then discounts what is left
+// of the items total, as long as the items total reaches code's minimum spend.
+func ApplyDiscounts(order *dto.OrderDto) {
+	order.Discounts = nil
+	order.DiscountInCents = 0
+
+	discounted := make(map[uuid.UUID]bool, len(order.Items))
+
+	for _, rule := range order.PromotionRules {
+		line := ruleDiscount(rule, order.Items, discounted)
+		if line == nil {
+			continue
+		}
+
+		order.Discounts = append(order.Discounts, line)
+		order.DiscountInCents += line.AmountInCents
+	}
+
+	code := order.PromoCode
+	if code == nil || order.TotalPriceInCents < code.MinSpendInCents {
+		return
+	}
+
+	amount := codeDiscount(code, order.TotalPriceInCents-order.DiscountInCents)
+	if amount <= 0 {
+		return
+	}
+
+	order.Discounts = append(order.Discounts, &dto.DiscountLineDto{
+		Source:        dto.DiscountSourcePromoCode,
+		PromotionID:   code.ID,
+		Name:          code.Code,
+		AmountInCents: amount,
+		OrderItemIDs:  nil,
+	})
+	order.DiscountInCents += amount
+}
+
+// NetItemPrices returns price of each order item after order's discount lines. Discount of an
+// automatic promotion is split among items it lists and promo code discount among all items,
+// in proportion to what is left of their prices.
+func NetItemPrices(order *dto.OrderDto) map[uuid.UUID]int {
+	prices := make(map[uuid.UUID]int, len(order.Items))
+	allItemIDs := make([]uuid.UUID, 0, len(order.Items))
+
+	for _, item := range order.Items {
+		prices[item.ID] = item.PriceInCents
+		allItemIDs = append(allItemIDs, item.ID)
+	}
+
+	for _, line := range order.Discounts {
+		itemIDs := line.OrderItemIDs
+		if len(itemIDs) == 0 {
+			itemIDs = allItemIDs
+		}
+
+		splitDiscount(prices, itemIDs, line.AmountInCents)
+	}
+
+	return prices
+}
+
+// splitDiscount subtracts shares of the discount from prices of the items, the last item
+// gets what is left after rounding.
+func splitDiscount(prices map[uuid.UUID]int, itemIDs []uuid.UUID, amount int) {
+	total := 0
+	for _, id := range itemIDs {
+		total += prices[id]
+	}
+
+	if total <= 0 {
+		return
+	}
+
+	left := amount
+
+	for i, id := range itemIDs {
+		share := amount * prices[id] / total
+		if i == len(itemIDs)-1 {
+			share = left
+		}
+
+		share = min(share, prices[id])
+		prices[id] -= share
+		left -= share
+	}
+}
+
+// isActiveAt reports whether t falls into rule's daily window in rule's timezone. Window that
+// ends before it starts wraps past midnight, window that ends when it starts lasts all day.
+func isActiveAt(rule *dto.PromotionRuleDto, t time.Time) bool {
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	start, err := minuteOfDay(rule.StartsAt)
+	if err != nil {
+		return false
+	}
+
+	end, err := minuteOfDay(rule.EndsAt)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*minutesInHour + local.Minute()
+
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return minute >= start && minute < end
+	default:
+		return minute >= start || minute < end
+	}
+}
+
+// ruleDiscount discounts items the rule applies to that weren't discounted by other rules yet,
+// nil is returned when none of the items get a discount.
+func ruleDiscount(
+	rule *dto.PromotionRuleDto,
+	items []*dto.OrderItemDto,
+	discounted map[uuid.UUID]bool,
+) *dto.DiscountLineDto {
+	matching := make([]*dto.OrderItemDto, 0, len(items))
+
+	for _, item := range items {
+		if !discounted[item.ID] && appliesTo(rule, item) {
+			matching = append(matching, item)
+		}
+	}
+
+	line := &dto.DiscountLineDto{
+		Source:        dto.DiscountSourcePromotionRule,
+		PromotionID:   rule.ID,
+		Name:          rule.Name,
+		AmountInCents: 0,
+		OrderItemIDs:  nil,
+	}
+
+	switch rule.RuleType {
+	case db.OrdersPromotionRuleTypePercentage:
+		for _, item := range matching {
+			discounted[item.ID] = true
+			addItem(line, item, item.PriceInCents*rule.PercentOff/100) //nolint:mnd
+		}
+	case db.OrdersPromotionRuleTypeBuyXGetY:
+		buyXGetY(rule, matching, discounted, line)
+	}
+
+	if line.AmountInCents == 0 {
+		return nil
+	}
+
+	return line
+}
+
+// buyXGetY groups items from the most expensive one, the cheapest items of each full group
+// are free. Items of incomplete group are left for other rules.
+func buyXGetY(
+	rule *dto.PromotionRuleDto,
+	items []*dto.OrderItemDto,
+	discounted map[uuid.UUID]bool,
+	line *dto.DiscountLineDto,
+) {
+	if rule.BuyQuantity <= 0 || rule.FreeQuantity <= 0 {
+		return
+	}
+
+	slices.SortStableFunc(items, func(a, b *dto.OrderItemDto) int {
+		return cmp.Compare(b.PriceInCents, a.PriceInCents)
+	})
+
+	size := rule.BuyQuantity + rule.FreeQuantity
+
+	for start := 0; start+size <= len(items); start += size {
+		for i, item := range items[start : start+size] {
+			discounted[item.ID] = true
+
+			if i >= rule.BuyQuantity {
+				addItem(line, item, item.PriceInCents)
+			}
+		}
+	}
+}
+
+// addItem adds item's discount to the discount line.
+func addItem(line *dto.DiscountLineDto, item *dto.OrderItemDto, amount int) {
+	if amount <= 0 {
+		return
+	}
+
+	line.AmountInCents += amount
+	line.OrderItemIDs = append(line.OrderItemIDs, item.ID)
+}
+
+func appliesTo(rule *dto.PromotionRuleDto, item *dto.OrderItemDto) bool {
+	if rule.CategoryID != nil && (item.CategoryID == nil || *item.CategoryID != *rule.CategoryID) {
+		return false
+	}
+
+	return isActiveAt(rule, item.CreatedAt)
+}
+
+// codeDiscount returns discount of the promo code for the subtotal, fixed discount is capped
+// at the subtotal.
+func codeDiscount(code *dto.AppliedPromoCodeDto, subtotal int) int {
+	switch code.DiscountType {
+	case db.OrdersDiscountTypePercentage:
+		return subtotal * code.DiscountValue / 100 //nolint:mnd
+	case db.OrdersDiscountTypeFixed:
+		return min(code.DiscountValue, subtotal)
+	}
+
+	return 0
+}
+
+func minuteOfDay(value string) (int, error) {
+	t, err := time.Parse(TimeLayout, value)
+	if err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+
+	return t.Hour()*minutesInHour + t.Minute(), nil
+}
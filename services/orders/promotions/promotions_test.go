@@ -0,0 +1,264 @@
+package promotions
+
+import (
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+//nolint:gochecknoglobals
+var (
+	testDrinksCategoryID = uuid.MustParse("e0e0e0e0-e0e0-4e0e-8e0e-e0e0e0e0e0e0")
+	testHappyHour        = time.Date(2025, 12, 5, 17, 30, 0, 0, time.UTC)
+	testEvening          = time.Date(2025, 12, 5, 21, 0, 0, 0, time.UTC)
+)
+
+type promotionsTestSuite struct {
+	suite.Suite
+}
+
+func TestPromotionsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(promotionsTestSuite))
+}
+
+func newItem(price int, categoryID *uuid.UUID, createdAt time.Time) *dto.OrderItemDto {
+	return &dto.OrderItemDto{
+		ID:           uuid.New(),
+		PriceInCents: price,
+		CategoryID:   categoryID,
+		CreatedAt:    createdAt,
+	}
+}
+
+func newOrder(items ...*dto.OrderItemDto) *dto.OrderDto {
+	total := 0
+	for _, item := range items {
+		total += item.PriceInCents
+	}
+
+	return &dto.OrderDto{
+		ID:                uuid.New(),
+		Items:             items,
+		TotalPriceInCents: total,
+	}
+}
+
+func happyHourRule() *dto.PromotionRuleDto {
+	return &dto.PromotionRuleDto{
+		ID:           uuid.New(),
+		Name:         "2-for-1 drinks",
+		RuleType:     db.OrdersPromotionRuleTypeBuyXGetY,
+		CategoryID:   &testDrinksCategoryID,
+		BuyQuantity:  1,
+		FreeQuantity: 1,
+		StartsAt:     "17:00",
+		EndsAt:       "19:00",
+		Timezone:     "UTC",
+		IsActive:     true,
+	}
+}
+
+func (suite *promotionsTestSuite) TestApplyDiscounts_NoPromotions() {
+	order := newOrder(newItem(1000, nil, testHappyHour))
+	order.Discounts = []*dto.DiscountLineDto{{AmountInCents: 100}}
+	order.DiscountInCents = 100
+
+	ApplyDiscounts(order)
+
+	suite.Empty(order.Discounts)
+	suite.Zero(order.DiscountInCents)
+}
+
+func (suite *promotionsTestSuite) TestApplyDiscounts_BuyXGetY() {
+	beer := newItem(500, &testDrinksCategoryID, testHappyHour)
+	wine := newItem(800, &testDrinksCategoryID, testHappyHour)
+	cola := newItem(300, &testDrinksCategoryID, testHappyHour)
+	lateBeer := newItem(500, &testDrinksCategoryID, testEvening)
+	burger := newItem(1500, nil, testHappyHour)
+
+	order := newOrder(beer, wine, cola, lateBeer, burger)
+	rule := happyHourRule()
+	order.PromotionRules = []*dto.PromotionRuleDto{rule}
+
+	ApplyDiscounts(order)
+
+	// wine and beer make a full pair, cheaper beer is free, cola has no pair
+	suite.Require().Len(order.Discounts, 1)
+	suite.Equal(500, order.DiscountInCents)
+	suite.Equal(dto.DiscountSourcePromotionRule, order.Discounts[0].Source)
+	suite.Equal(rule.ID, order.Discounts[0].PromotionID)
+	suite.Equal(rule.Name, order.Discounts[0].Name)
+	suite.Equal([]uuid.UUID{beer.ID}, order.Discounts[0].OrderItemIDs)
+
+	// items keep their order
+	suite.Equal(beer.ID, order.Items[0].ID)
+}
+
+func (suite *promotionsTestSuite) TestApplyDiscounts_PercentageRule() {
+	drink := newItem(500, &testDrinksCategoryID, testHappyHour)
+	burger := newItem(1500, nil, testHappyHour)
+	order := newOrder(drink, burger)
+
+	order.PromotionRules = []*dto.PromotionRuleDto{
+		happyHourRule(),
+		{
+			ID:         uuid.New(),
+			Name:       "Everything 10% off",
+			RuleType:   db.OrdersPromotionRuleTypePercentage,
+			PercentOff: 10,
+			StartsAt:   "00:00",
+			EndsAt:     "00:00",
+			Timezone:   "UTC",
+			IsActive:   true,
+		},
+	}
+
+	ApplyDiscounts(order)
+
+	// single drink is not discounted by happy hour and is left for the next rule
+	suite.Require().Len(order.Discounts, 1)
+	suite.Equal(200, order.DiscountInCents)
+	suite.ElementsMatch([]uuid.UUID{drink.ID, burger.ID}, order.Discounts[0].OrderItemIDs)
+}
+
+func (suite *promotionsTestSuite) TestApplyDiscounts_ItemDiscountedOnce() {
+	first := newItem(500, &testDrinksCategoryID, testHappyHour)
+	second := newItem(500, &testDrinksCategoryID, testHappyHour)
+	order := newOrder(first, second)
+
+	order.PromotionRules = []*dto.PromotionRuleDto{
+		happyHourRule(),
+		{
+			ID:         uuid.New(),
+			Name:       "Drinks 50% off",
+			RuleType:   db.OrdersPromotionRuleTypePercentage,
+			CategoryID: &testDrinksCategoryID,
+			PercentOff: 50,
+			StartsAt:   "17:00",
+			EndsAt:     "19:00",
+			Timezone:   "UTC",
+			IsActive:   true,
+		},
+	}
+
+	ApplyDiscounts(order)
+
+	suite.Require().Len(order.Discounts, 1)
+	suite.Equal(500, order.DiscountInCents)
+}
+
+func (suite *promotionsTestSuite) TestApplyDiscounts_PromoCode() {
+	tests := []struct {
+		name         string
+		discountType db.OrdersDiscountType
+		value        int
+		minSpend     int
+		want         int
+	}{
+		{"percentage", db.OrdersDiscountTypePercentage, 10, 0, 150},
+		{"fixed", db.OrdersDiscountTypeFixed, 500, 0, 500},
+		{"fixed capped at subtotal", db.OrdersDiscountTypeFixed, 5000, 0, 1500},
+		{"minimum spend reached", db.OrdersDiscountTypeFixed, 500, 2000, 500},
+		{"minimum spend not reached", db.OrdersDiscountTypeFixed, 500, 2001, 0},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			drink := newItem(500, &testDrinksCategoryID, testHappyHour)
+			beer := newItem(500, &testDrinksCategoryID, testHappyHour)
+			burger := newItem(1000, nil, testHappyHour)
+			order := newOrder(drink, beer, burger)
+
+			order.PromotionRules = []*dto.PromotionRuleDto{happyHourRule()}
+			order.PromoCode = &dto.AppliedPromoCodeDto{
+				ID:              uuid.New(),
+				Code:            "SAVE",
+				DiscountType:    tt.discountType,
+				DiscountValue:   tt.value,
+				MinSpendInCents: tt.minSpend,
+			}
+
+			ApplyDiscounts(order)
+
+			// promo code discounts what is left after happy hour
+			suite.Equal(500+tt.want, order.DiscountInCents)
+
+			if tt.want == 0 {
+				suite.Len(order.Discounts, 1)
+
+				return
+			}
+
+			suite.Require().Len(order.Discounts, 2)
+			suite.Equal(dto.DiscountSourcePromoCode, order.Discounts[1].Source)
+			suite.Equal(order.PromoCode.ID, order.Discounts[1].PromotionID)
+			suite.Equal("SAVE", order.Discounts[1].Name)
+			suite.Equal(tt.want, order.Discounts[1].AmountInCents)
+		})
+	}
+}
+
+func (suite *promotionsTestSuite) TestNetItemPrices() {
+	drink := newItem(500, &testDrinksCategoryID, testHappyHour)
+	beer := newItem(500, &testDrinksCategoryID, testHappyHour)
+	burger := newItem(1000, nil, testHappyHour)
+	order := newOrder(drink, beer, burger)
+
+	order.PromotionRules = []*dto.PromotionRuleDto{happyHourRule()}
+	order.PromoCode = &dto.AppliedPromoCodeDto{
+		ID:            uuid.New(),
+		Code:          "SAVE",
+		DiscountType:  db.OrdersDiscountTypeFixed,
+		DiscountValue: 301,
+	}
+
+	ApplyDiscounts(order)
+
+	got := NetItemPrices(order)
+
+	// free beer is left out of promo code discount, which is split between drink and burger
+	suite.Equal(400, got[drink.ID])
+	suite.Equal(0, got[beer.ID])
+	suite.Equal(799, got[burger.ID])
+	suite.Equal(order.TotalPriceInCents-order.DiscountInCents, got[drink.ID]+got[burger.ID])
+}
+
+func (suite *promotionsTestSuite) TestIsActiveAt() {
+	tests := []struct {
+		name     string
+		startsAt string
+		endsAt   string
+		timezone string
+		at       time.Time
+		want     bool
+	}{
+		{"inside window", "17:00", "19:00", "UTC", testHappyHour, true},
+		{"window start", "17:00", "19:00", "UTC", testHappyHour.Add(-30 * time.Minute), true},
+		{"window end", "17:00", "19:00", "UTC", testHappyHour.Add(90 * time.Minute), false},
+		{"outside window", "17:00", "19:00", "UTC", testEvening, false},
+		{"all day", "00:00", "00:00", "UTC", testEvening, true},
+		{"past midnight before", "22:00", "02:00", "UTC", testEvening.Add(2 * time.Hour), true},
+		{"past midnight after", "22:00", "02:00", "UTC", testEvening.Add(4 * time.Hour), true},
+		{"past midnight outside", "22:00", "02:00", "UTC", testEvening, false},
+		// 17:30 UTC is 19:30 in Riga in winter
+		{"timezone outside", "17:00", "19:00", "Europe/Riga", testHappyHour, false},
+		{"timezone inside", "19:00", "21:00", "Europe/Riga", testHappyHour, true},
+		{"invalid time", "5pm", "19:00", "UTC", testHappyHour, false},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			rule := happyHourRule()
+			rule.StartsAt = tt.startsAt
+			rule.EndsAt = tt.endsAt
+			rule.Timezone = tt.timezone
+
+			suite.Equal(tt.want, isActiveAt(rule, tt.at))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"golang-dining-ordering/services/orders/dto"
+	"html/template"
+	"strings"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Receipt {{ number . }} - {{ .RestaurantName }}</title>
+  <style>
+    body { font-family: sans-serif; max-width: 420px; margin: 2rem auto; color: #222; }
+    header { text-align: center; margin-bottom: 1rem; }
+    h1 { font-size: 1.4rem; margin: 0; }
+    table { width: 100%; border-collapse: collapse; }
+    td { padding: 0.2rem 0; vertical-align: top; }
+    td.amount { text-align: right; white-space: nowrap; }
+    tr.separator td { border-top: 1px dashed #999; padding-top: 0.5rem; }
+    tr.total td { font-weight: bold; font-size: 1.1rem; }
+    .muted { color: #777; font-size: 0.9rem; }
+  </style>
+</head>
+<body>
+  <header>
+    <h1>{{ .RestaurantName }}</h1>
+    <div class="muted">{{ .RestaurantAddress }}</div>
+  </header>
+  <table>
+    <tr><td>Receipt No.</td><td class="amount">{{ number . }}</td></tr>
+    <tr><td>Date</td><td class="amount">{{ .IssuedAt.UTC.Format "2006-01-02 15:04 MST" }}</td></tr>
+    <tr><td>Order</td><td class="amount muted">{{ .OrderID }}</td></tr>
+    {{- range $i, $line := .Lines }}
+    <tr{{ if eq $i 0 }} class="separator"{{ end }}>
+      <td>
+        {{ if gt $line.Quantity 1 }}{{ $line.Quantity }} x {{ end }}{{ $line.Name }}
+        {{- if gt $line.Quantity 1 }}
+        <div class="muted">@ {{ amount $line.UnitPriceInCents }}</div>
+        {{- end }}
+      </td>
+      <td class="amount">{{ amount $line.AmountInCents }}</td>
+    </tr>
+    {{- end }}
+    <tr class="separator"><td>Subtotal</td><td class="amount">{{ amount .SubtotalInCents }}</td></tr>
+    {{- range .Discounts }}
+    <tr><td>{{ .Name }}</td><td class="amount">{{ amount (negate .AmountInCents) }}</td></tr>
+    {{- end }}
+    {{- if gt .TipInCents 0 }}
+    <tr><td>Tip</td><td class="amount">{{ amount .TipInCents }}</td></tr>
+    {{- end }}
+    <tr class="total"><td>Total {{ upper .Currency }}</td><td class="amount">{{ amount .TotalInCents }}</td></tr>
+    <tr class="muted"><td>incl. VAT {{ taxRate .TaxRate }}</td><td class="amount">{{ amount .TaxInCents }}</td></tr>
+    {{- range $i, $payment := .Payments }}
+    <tr{{ if eq $i 0 }} class="separator"{{ end }}>
+      <td>Paid by {{ paymentMethod $payment.Method }}</td>
+      <td class="amount">{{ amount $payment.AmountInCents }}</td>
+    </tr>
+    {{- if gt $payment.RefundedAmountInCents 0 }}
+    <tr class="muted"><td>Refunded</td><td class="amount">{{ amount (negate $payment.RefundedAmountInCents) }}</td></tr>
+    {{- end }}
+    {{- end }}
+  </table>
+  <p style="text-align: center;">Thank you!</p>
+</body>
+</html>
+`
+
+func renderHTML(receipt *dto.ReceiptDto) ([]byte, error) {
+	amount := func(amountInCents int) string {
+		return Amount(amountInCents, receipt.Currency)
+	}
+
+	tmpl, err := template.New("receipt").Funcs(template.FuncMap{
+		"number":        Number,
+		"amount":        amount,
+		"negate":        func(amount int) int { return -amount },
+		"upper":         strings.ToUpper,
+		"taxRate":       TaxRate,
+		"paymentMethod": PaymentMethod,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing receipt template: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.Execute(&buf, receipt)
+	if err != nil {
+		return nil, fmt.Errorf("executing receipt template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,142 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Receipt is printed in 10pt Courier, where every character is 6pt wide, on a single page
+// as narrow as the receipt line and as long as the receipt.
+const (
+	pdfFontSize  = 10
+	pdfCharWidth = 6
+	pdfLeading   = 12
+	pdfMargin    = 18
+)
+
+// renderPDF writes lines as a single page PDF document using standard Courier font, which
+// PDF readers provide themselves, so nothing has to be embedded.
+func renderPDF(lines []string) []byte {
+	width := LineWidth*pdfCharWidth + 2*pdfMargin               //nolint:mnd
+	height := len(lines)*pdfLeading + 2*pdfMargin + pdfFontSize //nolint:mnd
+
+	var content bytes.Buffer
+
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n", pdfFontSize, pdfLeading)
+	fmt.Fprintf(&content, "%d %d Td\n", pdfMargin, height-pdfMargin-pdfFontSize)
+
+	for _, line := range lines {
+		content.WriteString("(")
+		content.Write(escapePDFString(EncodeWindows1252(line)))
+		content.WriteString(") Tj T*\n")
+	}
+
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+				"/Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+			width,
+			height,
+		),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var doc bytes.Buffer
+
+	doc.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, 0, len(objects))
+
+	for i, object := range objects {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(
+		&doc,
+		"trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1,
+		xref,
+	)
+
+	return doc.Bytes()
+}
+
+// escapePDFString escapes bytes of PDF literal string, bytes outside of ASCII are written
+// as octal codes.
+func escapePDFString(text []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, b := range text {
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(b)
+		case b < ' ' || b > '~':
+			fmt.Fprintf(&buf, "\\%03o", b)
+		default:
+			buf.WriteByte(b)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// EncodeWindows1252 encodes text in Windows-1252 code page used by PDF standard fonts.
+func EncodeWindows1252(text string) []byte {
+	return Encode(text, charmap.Windows1252)
+}
+
+// Encode encodes text in the single byte code page, e.g. the one selected on receipt printer.
+// Letters missing from the code page lose their accents, e.g. "ą" is encoded as "a" in
+// Windows-1252, other characters are replaced with "?".
+func Encode(text string, codepage *charmap.Charmap) []byte {
+	encoded := make([]byte, 0, len(text))
+
+	for _, r := range text {
+		b, ok := codepage.EncodeRune(r)
+		if ok {
+			encoded = append(encoded, b)
+
+			continue
+		}
+
+		base, ok := codepage.EncodeRune(baseLetter(r))
+		if !ok {
+			base = '?'
+		}
+
+		encoded = append(encoded, base)
+	}
+
+	return encoded
+}
+
+// baseLetter returns the letter without accents or the rune itself when it has no
+// decomposition.
+func baseLetter(r rune) rune {
+	for _, decomposed := range norm.NFD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, decomposed) {
+			return decomposed
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,248 @@
+// Package receipts renders receipts of completed orders as HTML, plain text and PDF.
+package receipts
+
+import (
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is a format receipts are rendered in.
+type Format string
+
+const (
+	// FormatHTML renders receipt as a standalone HTML page.
+	FormatHTML Format = "html"
+	// FormatText renders receipt as plain text laid out like a printed receipt.
+	FormatText Format = "text"
+	// FormatPDF renders plain text receipt as a single page PDF document.
+	FormatPDF Format = "pdf"
+)
+
+// ErrUnsupportedFormat is returned when receipt is rendered in unknown format.
+var ErrUnsupportedFormat = errors.New("unsupported receipt format")
+
+// LineWidth is the number of characters in a line of plain text receipt.
+const LineWidth = 42
+
+const centsInUnit = 100
+
+// Render renders the receipt in the given format.
+func Render(receipt *dto.ReceiptDto, format Format) ([]byte, error) {
+	switch format {
+	case FormatHTML:
+		return renderHTML(receipt)
+	case FormatText:
+		return []byte(strings.Join(TextLines(receipt), "\n") + "\n"), nil
+	case FormatPDF:
+		return renderPDF(TextLines(receipt)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// ContentType returns media type of the receipt rendered in the format.
+func ContentType(format Format) string {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Filename returns name of the receipt file rendered in the format.
+func Filename(receipt *dto.ReceiptDto, format Format) string {
+	extension := string(format)
+	if format == FormatText {
+		extension = "txt"
+	}
+
+	return fmt.Sprintf("receipt-%s.%s", Number(receipt), extension)
+}
+
+// Number returns receipt's number padded the way it's printed.
+func Number(receipt *dto.ReceiptDto) string {
+	return fmt.Sprintf("%06d", receipt.Number)
+}
+
+// TextLines lays the receipt out in lines of LineWidth characters.
+func TextLines(receipt *dto.ReceiptDto) []string {
+	separator := strings.Repeat("-", LineWidth)
+	currency := receipt.Currency
+
+	var lines []string
+
+	for _, text := range []string{receipt.RestaurantName, receipt.RestaurantAddress} {
+		for _, line := range Wrap(text, LineWidth) {
+			lines = append(lines, center(line))
+		}
+	}
+
+	lines = append(lines, separator)
+	lines = append(lines, columns("Receipt No.", Number(receipt))...)
+	lines = append(lines, columns("Date", receipt.IssuedAt.UTC().Format("2006-01-02 15:04 MST"))...)
+	lines = append(lines, columns("Order", receipt.OrderID.String()[:8])...)
+	lines = append(lines, separator)
+
+	for _, line := range receipt.Lines {
+		if line.Quantity == 1 {
+			lines = append(lines, columns(line.Name, Amount(line.AmountInCents, currency))...)
+
+			continue
+		}
+
+		name := fmt.Sprintf("%d x %s", line.Quantity, line.Name)
+		lines = append(lines, columns(name, Amount(line.AmountInCents, currency))...)
+		lines = append(lines, "  @ "+Amount(line.UnitPriceInCents, currency))
+	}
+
+	lines = append(lines, separator)
+	lines = append(lines, columns("Subtotal", Amount(receipt.SubtotalInCents, currency))...)
+
+	for _, discount := range receipt.Discounts {
+		lines = append(lines, columns(discount.Name, Amount(-discount.AmountInCents, currency))...)
+	}
+
+	if receipt.TipInCents > 0 {
+		lines = append(lines, columns("Tip", Amount(receipt.TipInCents, currency))...)
+	}
+
+	total := "TOTAL " + strings.ToUpper(currency)
+	lines = append(lines, columns(total, Amount(receipt.TotalInCents, currency))...)
+	tax := "incl. VAT " + TaxRate(receipt.TaxRate)
+	lines = append(lines, columns(tax, Amount(receipt.TaxInCents, currency))...)
+
+	if len(receipt.Payments) > 0 {
+		lines = append(lines, separator)
+	}
+
+	for _, payment := range receipt.Payments {
+		method := "Paid by " + PaymentMethod(payment.Method)
+		lines = append(lines, columns(method, Amount(payment.AmountInCents, currency))...)
+
+		if payment.RefundedAmountInCents > 0 {
+			refunded := Amount(-payment.RefundedAmountInCents, currency)
+			lines = append(lines, columns("Refunded", refunded)...)
+		}
+	}
+
+	return append(lines, separator, center("Thank you!"))
+}
+
+// Amount formats amount in cents of the currency, currencies without cents are formatted
+// as whole numbers.
+func Amount(amountInCents int, currency string) string {
+	if slices.Contains(currenciesWithNoCents(), strings.ToLower(currency)) {
+		return fmt.Sprintf("%d", amountInCents)
+	}
+
+	sign := ""
+	if amountInCents < 0 {
+		sign = "-"
+	}
+
+	cents := int(math.Abs(float64(amountInCents)))
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsInUnit, cents%centsInUnit)
+}
+
+// TaxRate formats tax percentage without trailing zeros.
+func TaxRate(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".") + "%"
+}
+
+// PaymentMethod returns human readable name of the payment method.
+func PaymentMethod(method db.OrdersPaymentProvider) string {
+	switch method {
+	case db.OrdersPaymentProviderStripe:
+		return "card (Stripe)"
+	case db.OrdersPaymentProviderKlix:
+		return "bank link (Klix)"
+	case db.OrdersPaymentProviderMock:
+		return "card (test)"
+	case db.OrdersPaymentProviderCash:
+		return "cash"
+	case db.OrdersPaymentProviderTerminal:
+		return "card terminal"
+	default:
+		return string(method)
+	}
+}
+
+// currenciesWithNoCents lists currencies the order page shows without cents.
+func currenciesWithNoCents() []string {
+	return []string{"sek"}
+}
+
+// columns aligns left text to the start and right text to the end of a line, left text
+// that doesn't fit is wrapped to the lines above.
+func columns(left, right string) []string {
+	space := LineWidth - utf8.RuneCountInString(right) - 1
+
+	lines := Wrap(left, space)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	last := lines[len(lines)-1]
+	padding := max(LineWidth-utf8.RuneCountInString(last)-utf8.RuneCountInString(right), 1)
+	lines[len(lines)-1] = last + strings.Repeat(" ", padding) + right
+
+	return lines
+}
+
+func center(text string) string {
+	length := utf8.RuneCountInString(text)
+	if length >= LineWidth {
+		return text
+	}
+
+	return strings.Repeat(" ", (LineWidth-length)/2) + text //nolint:mnd
+}
+
+// Wrap splits text to lines of at most width characters on spaces, words longer than the
+// width are split.
+func Wrap(text string, width int) []string {
+	var (
+		lines   []string
+		current string
+	)
+
+	for _, word := range strings.Fields(text) {
+		for runes := []rune(word); len(runes) > width; runes = []rune(word) {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+
+	if current != "" {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
@@ -0,0 +1,160 @@
+package receipts
+
+import (
+	"bytes"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type receiptsTestSuite struct {
+	suite.Suite
+}
+
+func TestReceiptsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(receiptsTestSuite))
+}
+
+func newReceipt() *dto.ReceiptDto {
+	return &dto.ReceiptDto{
+		Number:            42,
+		OrderID:           uuid.MustParse("99999999-9999-4999-9999-999999999999"),
+		IssuedAt:          time.Date(2025, time.December, 5, 19, 0, 0, 0, time.UTC),
+		RestaurantName:    "Café Rīga",
+		RestaurantAddress: "Brīvības iela 1, Rīga",
+		Currency:          "eur",
+		Lines: []*dto.ReceiptLineDto{
+			{Name: "Espresso", Quantity: 2, UnitPriceInCents: 250, AmountInCents: 500},
+			{
+				Name:             "Grilled salmon with seasonal vegetables and lemon butter sauce",
+				Quantity:         1,
+				UnitPriceInCents: 1850,
+				AmountInCents:    1850,
+			},
+		},
+		Discounts: []*dto.DiscountLineDto{
+			{Source: dto.DiscountSourcePromoCode, Name: "SAVE10", AmountInCents: 235},
+		},
+		SubtotalInCents: 2350,
+		DiscountInCents: 235,
+		TaxRate:         21,
+		TaxInCents:      367,
+		TipInCents:      200,
+		TotalInCents:    2315,
+		Payments: []*dto.ReceiptPaymentDto{
+			{
+				Method:                db.OrdersPaymentProviderStripe,
+				AmountInCents:         2315,
+				RefundedAmountInCents: 500,
+			},
+		},
+	}
+}
+
+func (suite *receiptsTestSuite) TestTextLines() {
+	lines := TextLines(newReceipt())
+
+	for _, line := range lines {
+		suite.LessOrEqual(utf8.RuneCountInString(line), LineWidth, line)
+	}
+
+	text := strings.Join(lines, "\n")
+
+	suite.Contains(text, "Café Rīga")
+	suite.Contains(text, "Receipt No."+strings.Repeat(" ", 25)+"000042")
+	suite.Contains(text, "2025-12-05 19:00 UTC")
+	suite.Contains(text, "Order"+strings.Repeat(" ", 29)+"99999999")
+	suite.Contains(text, "2 x Espresso"+strings.Repeat(" ", 26)+"5.00")
+	suite.Contains(text, "  @ 2.50")
+	suite.Contains(text, "Grilled salmon with seasonal\nvegetables and lemon butter sauce    18.50")
+	suite.Contains(text, "SAVE10"+strings.Repeat(" ", 31)+"-2.35")
+	suite.Contains(text, "TOTAL EUR")
+	suite.Contains(text, "incl. VAT 21%")
+	suite.Contains(text, "Paid by card (Stripe)")
+	suite.Contains(text, "Refunded"+strings.Repeat(" ", 29)+"-5.00")
+}
+
+func (suite *receiptsTestSuite) TestTextLines_NoTipNoPayments() {
+	receipt := newReceipt()
+	receipt.TipInCents = 0
+	receipt.Payments = nil
+
+	text := strings.Join(TextLines(receipt), "\n")
+
+	suite.NotContains(text, "Tip")
+	suite.NotContains(text, "Paid by")
+}
+
+func (suite *receiptsTestSuite) TestRender_HTML() {
+	body, err := Render(newReceipt(), FormatHTML)
+	suite.Require().NoError(err)
+
+	html := string(body)
+	suite.Contains(html, "<title>Receipt 000042 - Café Rīga</title>")
+	suite.Contains(html, "2 x Espresso")
+	suite.Contains(html, "-2.35")
+	suite.Contains(html, "Paid by card (Stripe)")
+	suite.Contains(html, "incl. VAT 21%")
+}
+
+func (suite *receiptsTestSuite) TestRender_PDF() {
+	body, err := Render(newReceipt(), FormatPDF)
+	suite.Require().NoError(err)
+
+	suite.True(bytes.HasPrefix(body, []byte("%PDF-1.4\n")))
+	suite.True(bytes.HasSuffix(body, []byte("%%EOF\n")))
+	suite.Contains(string(body), "(                Caf\\351 Riga) Tj")
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(body)
+	suite.Require().Len(startxref, 2)
+
+	offset, err := strconv.Atoi(string(startxref[1]))
+	suite.Require().NoError(err)
+	suite.True(bytes.HasPrefix(body[offset:], []byte("xref\n0 6\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(body, -1)
+	suite.Require().Len(entries, 5)
+
+	for i, entry := range entries {
+		offset, err = strconv.Atoi(string(entry[1]))
+		suite.Require().NoError(err)
+		suite.True(bytes.HasPrefix(body[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")))
+	}
+}
+
+func (suite *receiptsTestSuite) TestRender_UnsupportedFormat() {
+	_, err := Render(newReceipt(), Format("docx"))
+	suite.Require().ErrorIs(err, ErrUnsupportedFormat)
+}
+
+func (suite *receiptsTestSuite) TestFilename() {
+	suite.Equal("receipt-000042.pdf", Filename(newReceipt(), FormatPDF))
+	suite.Equal("receipt-000042.txt", Filename(newReceipt(), FormatText))
+}
+
+func (suite *receiptsTestSuite) TestAmount() {
+	suite.Equal("12.05", Amount(1205, "eur"))
+	suite.Equal("-0.05", Amount(-5, "eur"))
+	suite.Equal("1205", Amount(1205, "SEK"))
+}
+
+func (suite *receiptsTestSuite) TestTaxRate() {
+	suite.Equal("21%", TaxRate(21))
+	suite.Equal("5.5%", TaxRate(5.5))
+	suite.Equal("0%", TaxRate(0))
+}
+
+func (suite *receiptsTestSuite) TestEncodeWindows1252() {
+	suite.Equal([]byte("Caf\xe9 \x80 5"), EncodeWindows1252("Café € 5"))
+	suite.Equal([]byte("Riga Lodz"), EncodeWindows1252("Rīga Lodź"))
+	suite.Equal([]byte("?"), EncodeWindows1252("☕"))
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrAssistanceRequestDoesNotExist is returned if assistance request isn't in the order.
+	ErrAssistanceRequestDoesNotExist = errors.New("assistance request with this id does not exist")
+	// ErrAssistanceRequestAlreadyHandled is returned if assistance request was already
+	// acknowledged or resolved by the time staff tried to.
+	ErrAssistanceRequestAlreadyHandled = errors.New("assistance request was already handled")
+	// ErrOrderIsFinalized is returned if assistance is requested at a completed or cancelled
+	// order.
+	ErrOrderIsFinalized = errors.New("order is completed or cancelled")
+	// ErrAssistanceRequestedRecently is returned if participant already asked for the same
+	// assistance at the order since the time.
+	ErrAssistanceRequestedRecently = errors.New("the same assistance was requested recently")
+)
+
+// AssistanceRepo defines methods for accessing assistance requests guests send to staff.
+type AssistanceRepo interface {
+	CreateAssistanceRequest(
+		ctx context.Context,
+		reqDto *dto.CreateAssistanceRequestDto,
+		since time.Time,
+	) (*dto.AssistanceRequestDto, error)
+	GetAssistanceRequest(
+		ctx context.Context,
+		orderID, requestID uuid.UUID,
+	) (*dto.AssistanceRequestDto, error)
+	GetUnresolvedAssistanceRequests(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+	) ([]*dto.AssistanceRequestDto, error)
+	AcknowledgeAssistanceRequest(
+		ctx context.Context,
+		orderID, requestID, userID uuid.UUID,
+	) (*dto.AssistanceRequestDto, error)
+	ResolveAssistanceRequest(
+		ctx context.Context,
+		orderID, requestID, userID uuid.UUID,
+	) (*dto.AssistanceRequestDto, error)
+	GetAssistanceResponseTimes(
+		ctx context.Context,
+		reqDto *dto.AssistanceReportRequestDto,
+	) ([]*dto.AssistanceResponseTimeDto, error)
+}
+
+type assistanceRepo struct {
+	db *sql.DB
+	q  *db.Queries
+}
+
+// NewAssistanceRepo creates a new assistance requests reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewAssistanceRepo(db *sql.DB, q *db.Queries) *assistanceRepo {
+	return &assistanceRepo{
+		db: db,
+		q:  q,
+	}
+}
+
+//revive:enable:unexported-return
+
+// CreateAssistanceRequest saves the request unless participant of the request already asked
+// for the same assistance at the order since the time. The order is locked while the request
+// is saved, so concurrent requests of the participant see each other and only one is saved.
+func (r *assistanceRepo) CreateAssistanceRequest(
+	ctx context.Context,
+	reqDto *dto.CreateAssistanceRequestDto,
+	since time.Time,
+) (*dto.AssistanceRequestDto, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	_, err = qtx.LockOrder(ctx, reqDto.OrderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderDoesNotExist
+		}
+
+		return nil, fmt.Errorf("locking order in database: %w", err)
+	}
+
+	row, err := qtx.CreateAssistanceRequest(ctx, db.CreateAssistanceRequestParams{
+		ID:            uuid.New(),
+		Type:          reqDto.Type,
+		Message:       nullStringFromPtr(reqDto.Message),
+		ParticipantID: requesterID(reqDto),
+		OrderID:       reqDto.OrderID,
+		Since:         since,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.assistanceRequestRejected(ctx, qtx, reqDto, since)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("inserting assistance request to database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing database transaction: %w", err)
+	}
+
+	respDto := assistanceRequestFromRow(&row)
+	respDto.RequestedBy = reqDto.RequestedBy
+
+	return respDto, nil
+}
+
+// assistanceRequestRejected returns why the request wasn't saved, participant asked for the
+// same assistance recently or the order is finalized.
+func (r *assistanceRepo) assistanceRequestRejected(
+	ctx context.Context,
+	qtx *db.Queries,
+	reqDto *dto.CreateAssistanceRequestDto,
+	since time.Time,
+) error {
+	recent, err := qtx.HasRecentAssistanceRequest(ctx, db.HasRecentAssistanceRequestParams{
+		OrderID:       reqDto.OrderID,
+		Type:          reqDto.Type,
+		ParticipantID: requesterID(reqDto),
+		Since:         since,
+	})
+	if err != nil {
+		return fmt.Errorf("checking recent assistance requests in database: %w", err)
+	}
+
+	if recent {
+		return ErrAssistanceRequestedRecently
+	}
+
+	return ErrOrderIsFinalized
+}
+
+func (r *assistanceRepo) GetAssistanceRequest(
+	ctx context.Context,
+	orderID, requestID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	row, err := r.q.GetAssistanceRequest(ctx, db.GetAssistanceRequestParams{
+		ID:      requestID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAssistanceRequestDoesNotExist
+		}
+
+		return nil, fmt.Errorf("getting assistance request from database: %w", err)
+	}
+
+	return assistanceRequestFromJoinedRow(&row), nil
+}
+
+func (r *assistanceRepo) GetUnresolvedAssistanceRequests(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.AssistanceRequestDto, error) {
+	rows, err := r.q.GetUnresolvedAssistanceRequests(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting unresolved assistance requests from database: %w", err)
+	}
+
+	requests := make([]*dto.AssistanceRequestDto, 0, len(rows))
+
+	for _, row := range rows {
+		joined := db.GetAssistanceRequestRow(row)
+		requests = append(requests, assistanceRequestFromJoinedRow(&joined))
+	}
+
+	return requests, nil
+}
+
+func (r *assistanceRepo) AcknowledgeAssistanceRequest(
+	ctx context.Context,
+	orderID, requestID, userID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	row, err := r.q.AcknowledgeAssistanceRequest(ctx, db.AcknowledgeAssistanceRequestParams{
+		UserID:  userID,
+		ID:      requestID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAssistanceRequestAlreadyHandled
+		}
+
+		return nil, fmt.Errorf("acknowledging assistance request in database: %w", err)
+	}
+
+	return assistanceRequestFromRow(&row), nil
+}
+
+func (r *assistanceRepo) ResolveAssistanceRequest(
+	ctx context.Context,
+	orderID, requestID, userID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	row, err := r.q.ResolveAssistanceRequest(ctx, db.ResolveAssistanceRequestParams{
+		UserID:  userID,
+		ID:      requestID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAssistanceRequestAlreadyHandled
+		}
+
+		return nil, fmt.Errorf("resolving assistance request in database: %w", err)
+	}
+
+	return assistanceRequestFromRow(&row), nil
+}
+
+func (r *assistanceRepo) GetAssistanceResponseTimes(
+	ctx context.Context,
+	reqDto *dto.AssistanceReportRequestDto,
+) ([]*dto.AssistanceResponseTimeDto, error) {
+	rows, err := r.q.GetAssistanceRequestsCreatedBetween(
+		ctx,
+		db.GetAssistanceRequestsCreatedBetweenParams{
+			RestaurantID: reqDto.RestaurantID,
+			CreatedFrom:  reqDto.From,
+			CreatedTo:    reqDto.To,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting assistance requests from database: %w", err)
+	}
+
+	times := make([]*dto.AssistanceResponseTimeDto, 0, len(rows))
+
+	for _, row := range rows {
+		times = append(times, &dto.AssistanceResponseTimeDto{
+			Type:           row.Type,
+			CreatedAt:      row.CreatedAt,
+			AcknowledgedAt: ptrFromNullTime(row.AcknowledgedAt),
+			AcknowledgedBy: ptrFromNullUUID(row.AcknowledgedBy),
+			ResolvedAt:     ptrFromNullTime(row.ResolvedAt),
+		})
+	}
+
+	return times, nil
+}
+
+func assistanceRequestFromRow(row *db.OrdersAssistanceRequest) *dto.AssistanceRequestDto {
+	return &dto.AssistanceRequestDto{
+		ID:             row.ID,
+		OrderID:        row.OrderID,
+		Type:           row.Type,
+		Message:        ptrFromNullString(row.Message),
+		Status:         row.Status,
+		RequestedBy:    nil,
+		CreatedAt:      row.CreatedAt,
+		AcknowledgedAt: ptrFromNullTime(row.AcknowledgedAt),
+		AcknowledgedBy: ptrFromNullUUID(row.AcknowledgedBy),
+		ResolvedAt:     ptrFromNullTime(row.ResolvedAt),
+		ResolvedBy:     ptrFromNullUUID(row.ResolvedBy),
+	}
+}
+
+// assistanceRequestFromJoinedRow maps assistance request with participant who sent it.
+func assistanceRequestFromJoinedRow(row *db.GetAssistanceRequestRow) *dto.AssistanceRequestDto {
+	request := assistanceRequestFromRow(&db.OrdersAssistanceRequest{
+		ID:             row.ID,
+		OrderID:        row.OrderID,
+		Type:           row.Type,
+		Message:        row.Message,
+		Status:         row.Status,
+		ParticipantID:  row.ParticipantID,
+		CreatedAt:      row.CreatedAt,
+		AcknowledgedAt: row.AcknowledgedAt,
+		AcknowledgedBy: row.AcknowledgedBy,
+		ResolvedAt:     row.ResolvedAt,
+		ResolvedBy:     row.ResolvedBy,
+	})
+
+	if row.ParticipantID.Valid {
+		request.RequestedBy = &dto.ParticipantDto{
+			ID:          row.ParticipantID.UUID,
+			DisplayName: row.ParticipantDisplayName.String,
+			Role:        row.ParticipantRole.OrdersParticipantRole,
+		}
+	}
+
+	return request
+}
+
+// requesterID returns id of participant who asked for assistance, when it's known.
+func requesterID(reqDto *dto.CreateAssistanceRequestDto) uuid.NullUUID {
+	if reqDto.RequestedBy == nil {
+		return uuid.NullUUID{UUID: uuid.Nil, Valid: false}
+	}
+
+	return uuid.NullUUID{UUID: reqDto.RequestedBy.ID, Valid: true}
+}
@@ -4,10 +4,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/promotions"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,6 +20,20 @@ var (
 	ErrNoCurrentOrder = errors.New("current order for this table doesnt exist")
 	// ErrOrderDoesNotExist is returned if order doesn't exist in database.
 	ErrOrderDoesNotExist = errors.New("order with this id does not exist")
+	// ErrMenuItemDoesNotExist is returned if menu item doesn't exist in database.
+	ErrMenuItemDoesNotExist = errors.New("menu item with this id does not exist")
+	// ErrMenuItemUnavailable is returned if menu item is currently marked as unavailable.
+	ErrMenuItemUnavailable = errors.New("menu item is currently unavailable")
+	// ErrOrderItemDoesNotExist is returned if item isn't in the order.
+	ErrOrderItemDoesNotExist = errors.New("order item with this id does not exist")
+	// ErrParticipantOfAnotherOrder is returned if participant is already taking part in
+	// another order.
+	ErrParticipantOfAnotherOrder = errors.New("participant belongs to another order")
+	// ErrParticipantDoesNotExist is returned if participant isn't taking part in the order.
+	ErrParticipantDoesNotExist = errors.New("participant does not exist")
+	// ErrUserIsNotRestaurantMember is returned if user isn't waiter or manager the restaurant
+	// was asked about.
+	ErrUserIsNotRestaurantMember = errors.New("user is not a member of this restaurant")
 )
 
 // OrdersRepo defines methods for accessing and managing orders data.
@@ -32,14 +49,46 @@ type OrdersRepo interface {
 		ctx context.Context,
 		orderID uuid.UUID,
 		item *dto.OrderItemDto,
+		addedBy *dto.ParticipantDto,
 	) (*dto.OrderItemDto, error)
 	GetOrderItems(ctx context.Context, orderID uuid.UUID) (*dto.OrderDto, error)
+	GetOrderTable(ctx context.Context, orderID uuid.UUID) (*dto.OrderTableDto, error)
+	LockOrder(ctx context.Context, orderID uuid.UUID) error
+	SaveOrderDiscounts(
+		ctx context.Context,
+		orderID uuid.UUID,
+		discounts []*dto.DiscountLineDto,
+	) error
+	SetOrderPaymentReview(
+		ctx context.Context,
+		orderID uuid.UUID,
+		review *db.OrdersPaymentReview,
+	) error
 	GetMenuItem(ctx context.Context, itemID uuid.UUID) (*dto.OrderItemDto, error)
 	DeleteOrderItem(ctx context.Context, orderItemID, orderID uuid.UUID) (*dto.OrderItemDto, error)
 	UpdateOrder(ctx context.Context, reqDto *dto.UpdateOrderReqDto) (*dto.OrderDto, error)
 	IsUserRestaurantWaiter(ctx context.Context, userID, restaurantID uuid.UUID) error
+	IsUserRestaurantManager(ctx context.Context, userID, restaurantID uuid.UUID) error
 	AssignWaiter(ctx context.Context, orderID, userID uuid.UUID) error
 	RemoveWaiter(ctx context.Context, orderID, userID, assignID uuid.UUID) error
+	GetOrderParticipant(
+		ctx context.Context,
+		orderID, participantID uuid.UUID,
+	) (*dto.ParticipantDto, error)
+	SaveGuestParticipant(
+		ctx context.Context,
+		orderID, participantID uuid.UUID,
+		displayName string,
+	) (*dto.ParticipantDto, error)
+	SaveStaffParticipant(
+		ctx context.Context,
+		orderID, userID uuid.UUID,
+	) (*dto.ParticipantDto, error)
+	IssueReceipt(
+		ctx context.Context,
+		orderID, restaurantID uuid.UUID,
+		contents *dto.ReceiptContentsDto,
+	) (*dto.IssuedReceiptDto, error)
 }
 
 type ordersRepo struct {
@@ -99,17 +148,26 @@ func (r *ordersRepo) GetTableCurrency(ctx context.Context, tableID uuid.UUID) (s
 	return currency, nil
 }
 
+// AddItemToOrder adds the menu item to the order, addedBy is nil when it's not known who
+// added it.
 func (r *ordersRepo) AddItemToOrder(
 	ctx context.Context,
 	orderID uuid.UUID,
 	item *dto.OrderItemDto,
+	addedBy *dto.ParticipantDto,
 ) (*dto.OrderItemDto, error) {
+	participantID := uuid.NullUUID{UUID: uuid.Nil, Valid: false}
+	if addedBy != nil {
+		participantID = uuid.NullUUID{UUID: addedBy.ID, Valid: true}
+	}
+
 	row, err := r.q.AddOrderItem(ctx, db.AddOrderItemParams{
-		ID:           uuid.New(),
-		OrderID:      orderID,
-		ItemID:       uuid.NullUUID{UUID: item.ID, Valid: true},
-		ItemName:     item.Name,
-		PriceInCents: item.PriceInCents,
+		ID:            uuid.New(),
+		OrderID:       orderID,
+		ItemID:        uuid.NullUUID{UUID: item.ID, Valid: true},
+		ItemName:      item.Name,
+		PriceInCents:  item.PriceInCents,
+		ParticipantID: participantID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("inserting order item into database: %w", err)
@@ -121,6 +179,9 @@ func (r *ordersRepo) AddItemToOrder(
 		ItemID:       row.ItemID.UUID,
 		Name:         row.ItemName,
 		PriceInCents: row.PriceInCents,
+		CategoryID:   item.CategoryID,
+		CreatedAt:    row.CreatedAt,
+		AddedBy:      addedBy,
 	}
 
 	return respDto, nil
@@ -138,6 +199,11 @@ func (r *ordersRepo) GetOrderItems(ctx context.Context, orderID uuid.UUID) (*dto
 
 	firstRow := rows[0]
 
+	var review *db.OrdersPaymentReview
+	if firstRow.PaymentReview.Valid {
+		review = &firstRow.PaymentReview.OrdersPaymentReview
+	}
+
 	respDto := &dto.OrderDto{
 		ID:                firstRow.ID,
 		RestaurantID:      firstRow.RestaurantID.UUID,
@@ -145,13 +211,23 @@ func (r *ordersRepo) GetOrderItems(ctx context.Context, orderID uuid.UUID) (*dto
 		Status:            firstRow.Status,
 		Currency:          firstRow.Currency,
 		TipAmountInCents:  int(firstRow.TipAmountInCents.Int32),
+		TipPresets:        nil,
 		TotalPriceInCents: 0,
+		DiscountInCents:   0,
+		Discounts:         nil,
+		PromoCode:         appliedPromoCodeFromRow(&firstRow),
+		PromotionRules:    nil,
+		AmountPaidInCents: firstRow.AmountPaidInCents,
+		BalanceDueInCents: 0,
+		PaymentReview:     review,
 		UpdatedAt:         firstRow.UpdatedAt,
 		Items:             make([]*dto.OrderItemDto, 0, len(rows)),
 	}
 
 	if !firstRow.ItemID.Valid {
 		// this means this order doesnt have any items added yet
+		respDto.BalanceDueInCents = respDto.TipAmountInCents - respDto.AmountPaidInCents
+
 		return respDto, nil
 	}
 
@@ -164,26 +240,188 @@ func (r *ordersRepo) GetOrderItems(ctx context.Context, orderID uuid.UUID) (*dto
 			ItemID:       row.ID,
 			Name:         row.ItemName.String,
 			PriceInCents: int(row.PriceInCents.Int32),
+			CategoryID:   ptrFromNullUUID(row.CategoryID),
+			CreatedAt:    row.ItemCreatedAt.Time,
+			AddedBy:      nil,
+		}
+
+		if row.ParticipantID.Valid {
+			item.AddedBy = &dto.ParticipantDto{
+				ID:          row.ParticipantID.UUID,
+				DisplayName: row.ParticipantDisplayName.String,
+				Role:        row.ParticipantRole.OrdersParticipantRole,
+			}
 		}
 
 		respDto.Items = append(respDto.Items, item)
 	}
 
+	err = r.setOrderDiscounts(ctx, respDto)
+	if err != nil {
+		return nil, err
+	}
+
+	respDto.BalanceDueInCents = respDto.TotalPriceInCents +
+		respDto.TipAmountInCents -
+		respDto.DiscountInCents -
+		respDto.AmountPaidInCents
+
 	return respDto, nil
 }
 
+// setOrderDiscounts sets discounts saved when the order stopped being open, discounts of open
+// orders and of orders closed before discounts were saved are calculated from current
+// promotions.
+func (r *ordersRepo) setOrderDiscounts(ctx context.Context, order *dto.OrderDto) error {
+	if order.Status != db.OrderStatusOpen {
+		lines, err := r.q.GetOrderDiscounts(ctx, order.ID)
+		if err == nil {
+			return setSavedDiscounts(order, lines)
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("getting order discounts from database: %w", err)
+		}
+	}
+
+	ruleRows, err := r.q.GetActivePromotionRules(ctx, order.RestaurantID)
+	if err != nil {
+		return fmt.Errorf("getting restaurant promotion rules from database: %w", err)
+	}
+
+	order.PromotionRules = promotionRulesFromRows(ruleRows)
+
+	promotions.ApplyDiscounts(order)
+
+	return nil
+}
+
+func setSavedDiscounts(order *dto.OrderDto, lines json.RawMessage) error {
+	err := json.Unmarshal(lines, &order.Discounts)
+	if err != nil {
+		return fmt.Errorf("decoding order discounts: %w", err)
+	}
+
+	order.DiscountInCents = 0
+	for _, line := range order.Discounts {
+		order.DiscountInCents += line.AmountInCents
+	}
+
+	return nil
+}
+
+// SaveOrderDiscounts saves order's discount lines, they are used instead of current
+// promotions once the order isn't open anymore.
+func (r *ordersRepo) SaveOrderDiscounts(
+	ctx context.Context,
+	orderID uuid.UUID,
+	discounts []*dto.DiscountLineDto,
+) error {
+	if discounts == nil {
+		discounts = []*dto.DiscountLineDto{}
+	}
+
+	lines, err := json.Marshal(discounts)
+	if err != nil {
+		return fmt.Errorf("encoding order discounts: %w", err)
+	}
+
+	err = r.q.SaveOrderDiscounts(ctx, db.SaveOrderDiscountsParams{
+		OrderID: orderID,
+		Lines:   lines,
+	})
+	if err != nil {
+		return fmt.Errorf("saving order discounts to database: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrderTable returns the table the order is for together with waiters assigned to it.
+func (r *ordersRepo) GetOrderTable(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.OrderTableDto, error) {
+	row, err := r.q.GetOrderTable(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderDoesNotExist
+		}
+
+		return nil, fmt.Errorf("getting order table from database: %w", err)
+	}
+
+	return &dto.OrderTableDto{
+		TableID:      row.TableID,
+		RestaurantID: row.RestaurantID,
+		TableName:    row.TableName,
+		Section:      ptrFromNullString(row.Section),
+		WaiterIDs:    row.WaiterIds,
+	}, nil
+}
+
+// LockOrder locks the order until the end of the transaction the repo is bound to, other
+// transactions locking it wait until then and see what it committed.
+func (r *ordersRepo) LockOrder(ctx context.Context, orderID uuid.UUID) error {
+	_, err := r.q.LockOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderDoesNotExist
+		}
+
+		return fmt.Errorf("locking order in database: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ordersRepo) SetOrderPaymentReview(
+	ctx context.Context,
+	orderID uuid.UUID,
+	review *db.OrdersPaymentReview,
+) error {
+	var reviewValue db.OrdersPaymentReview
+	if review != nil {
+		reviewValue = *review
+	}
+
+	err := r.q.SetOrderPaymentReview(ctx, db.SetOrderPaymentReviewParams{
+		ID: orderID,
+		PaymentReview: db.NullOrdersPaymentReview{
+			OrdersPaymentReview: reviewValue,
+			Valid:               review != nil,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("updating order payment review in database: %w", err)
+	}
+
+	return nil
+}
+
 func (r *ordersRepo) GetMenuItem(ctx context.Context, itemID uuid.UUID) (*dto.OrderItemDto, error) {
 	row, err := r.q.GetMenuItem(ctx, itemID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMenuItemDoesNotExist
+		}
+
 		return nil, fmt.Errorf("fetching menu item from database: %w", err)
 	}
 
+	if !row.IsAvailable {
+		return nil, ErrMenuItemUnavailable
+	}
+
 	item := &dto.OrderItemDto{
 		ID:           row.ID,
 		RestaurantID: row.RestaurantID.UUID,
 		ItemID:       row.ID,
 		Name:         row.Name,
 		PriceInCents: row.PriceInCents,
+		CategoryID:   ptrFromNullUUID(row.CategoryID),
+		CreatedAt:    time.Time{},
+		AddedBy:      nil,
 	}
 
 	return item, nil
@@ -198,6 +436,10 @@ func (r *ordersRepo) DeleteOrderItem(
 		OrderID: orderID,
 	})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderItemDoesNotExist
+		}
+
 		return nil, fmt.Errorf("deleting order item from database: %w", err)
 	}
 
@@ -207,6 +449,9 @@ func (r *ordersRepo) DeleteOrderItem(
 		ItemID:       row.ItemID.UUID,
 		Name:         row.ItemName,
 		PriceInCents: row.PriceInCents,
+		CategoryID:   nil,
+		CreatedAt:    row.CreatedAt,
+		AddedBy:      nil,
 	}
 
 	return deletedItem, nil
@@ -279,8 +524,114 @@ func (r *ordersRepo) IsUserRestaurantWaiter(
 		RestaurantID: restaurantID,
 	})
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserIsNotRestaurantMember
+		}
+
+		return fmt.Errorf("confirming if user is restaurant waiter: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ordersRepo) IsUserRestaurantManager(
+	ctx context.Context,
+	userID, restaurantID uuid.UUID,
+) error {
+	_, err := r.q.IsUserRestaurantManager(ctx, db.IsUserRestaurantManagerParams{
+		UserID:       userID,
+		RestaurantID: restaurantID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserIsNotRestaurantMember
+		}
+
 		return fmt.Errorf("confirming if user is restaurant manager: %w", err)
 	}
 
 	return nil
 }
+
+// appliedPromoCodeFromRow returns promo code applied to the order, nil when there is none.
+func appliedPromoCodeFromRow(row *db.GetOrderItemsRow) *dto.AppliedPromoCodeDto {
+	if !row.PromoCodeID.Valid {
+		return nil
+	}
+
+	return &dto.AppliedPromoCodeDto{
+		ID:              row.PromoCodeID.UUID,
+		Code:            row.PromoCode.String,
+		DiscountType:    row.PromoDiscountType.OrdersDiscountType,
+		DiscountValue:   int(row.PromoDiscountValue.Int32),
+		MinSpendInCents: int(row.PromoMinSpendInCents.Int32),
+	}
+}
+
+// GetOrderParticipant returns participant taking part in the order.
+func (r *ordersRepo) GetOrderParticipant(
+	ctx context.Context,
+	orderID, participantID uuid.UUID,
+) (*dto.ParticipantDto, error) {
+	row, err := r.q.GetOrderParticipant(ctx, db.GetOrderParticipantParams{
+		ID:      participantID,
+		OrderID: orderID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrParticipantDoesNotExist
+		}
+
+		return nil, fmt.Errorf("getting participant from database: %w", err)
+	}
+
+	return participantFromRow(&row), nil
+}
+
+// SaveGuestParticipant saves guest taking part in the order under the display name, guest
+// joining again keeps the id and gets the new name.
+func (r *ordersRepo) SaveGuestParticipant(
+	ctx context.Context,
+	orderID, participantID uuid.UUID,
+	displayName string,
+) (*dto.ParticipantDto, error) {
+	row, err := r.q.SaveGuestParticipant(ctx, db.SaveGuestParticipantParams{
+		ID:          participantID,
+		OrderID:     orderID,
+		DisplayName: displayName,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrParticipantOfAnotherOrder
+		}
+
+		return nil, fmt.Errorf("saving guest participant to database: %w", err)
+	}
+
+	return participantFromRow(&row), nil
+}
+
+// SaveStaffParticipant saves staff member taking part in the order, under their name.
+func (r *ordersRepo) SaveStaffParticipant(
+	ctx context.Context,
+	orderID, userID uuid.UUID,
+) (*dto.ParticipantDto, error) {
+	row, err := r.q.SaveStaffParticipant(ctx, db.SaveStaffParticipantParams{
+		ID:      uuid.New(),
+		OrderID: orderID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving staff participant to database: %w", err)
+	}
+
+	return participantFromRow(&row), nil
+}
+
+func participantFromRow(row *db.OrdersOrderParticipant) *dto.ParticipantDto {
+	return &dto.ParticipantDto{
+		ID:          row.ID,
+		DisplayName: row.DisplayName,
+		Role:        row.Role,
+	}
+}
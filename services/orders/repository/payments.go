@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
@@ -9,48 +11,470 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrPaymentAlreadySaved is returned when provider's payment is already saved.
+	ErrPaymentAlreadySaved = errors.New("payment is already saved")
+	// ErrPaymentDoesNotExist is returned when provider's payment is not saved.
+	ErrPaymentDoesNotExist = errors.New("payment does not exist")
+	// ErrRefundDoesNotExist is returned when provider's refund is not saved.
+	ErrRefundDoesNotExist = errors.New("refund does not exist")
+	// ErrWebhookEventAlreadyProcessed is returned when provider's webhook event is already
+	// recorded in the webhooks inbox.
+	ErrWebhookEventAlreadyProcessed = errors.New("webhook event is already processed")
+)
+
+// TxFunc runs with repos bound to a single database transaction.
+type TxFunc func(ordersRepo OrdersRepo, paymentsRepo PaymentsRepo) error
+
 // PaymentsRepo defines methods for accessing and managing payments data.
 type PaymentsRepo interface {
+	RunInTx(ctx context.Context, fn TxFunc) error
+	ProcessWebhookEvent(
+		ctx context.Context,
+		event *dto.WebhookEventDto,
+		handle TxFunc,
+	) error
 	SavePayment(ctx context.Context, reqDto *dto.PaymentDto) (*dto.PaymentDto, error)
+	GetOrderAmountPaid(ctx context.Context, orderID uuid.UUID) (int, error)
+	GetOrderPayments(ctx context.Context, orderID uuid.UUID) ([]*dto.PaymentDto, error)
+	GetOrderRefundedItemIDs(ctx context.Context, orderID uuid.UUID) ([]uuid.UUID, error)
+	SaveRefund(ctx context.Context, reqDto *dto.RefundDto) (*dto.RefundDto, error)
+	UpdateRefundFromProvider(ctx context.Context, reqDto *dto.RefundDto) (*dto.RefundDto, error)
+	UpdateRefundStatus(ctx context.Context, reqDto *dto.RefundDto) (*dto.RefundDto, error)
+	MarkPaymentRefunded(ctx context.Context, paymentID uuid.UUID) error
+	UpdatePaymentStatus(ctx context.Context, reqDto *dto.PaymentDto) (*dto.PaymentDto, error)
+	SavePaymentAttempt(
+		ctx context.Context,
+		reqDto *dto.PaymentAttemptDto,
+	) (*dto.PaymentAttemptDto, error)
+	UpdatePaymentAttemptStatus(ctx context.Context, reqDto *dto.PaymentAttemptDto) error
+	GetOrderPaymentAttempts(
+		ctx context.Context,
+		orderID uuid.UUID,
+	) ([]*dto.PaymentAttemptDto, error)
 }
 
 type paymentsRepo struct {
-	q *db.Queries
+	db *sql.DB
+	q  *db.Queries
 }
 
 // NewPaymentsRepo creates a new payments reposiotry instance.
 //
 //revive:disable:unexported-return
-func NewPaymentsRepo(q *db.Queries) *paymentsRepo {
+func NewPaymentsRepo(db *sql.DB, q *db.Queries) *paymentsRepo {
 	return &paymentsRepo{
-		q: q,
+		db: db,
+		q:  q,
 	}
 }
 
 //revive:enable:unexported-return
 
+// RunInTx runs fn in a transaction, which is committed only when fn succeeds.
+func (r *paymentsRepo) RunInTx(ctx context.Context, fn TxFunc) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	err = fn(NewOrdersRepo(qtx), &paymentsRepo{db: r.db, q: qtx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing database transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessWebhookEvent records the event in the webhooks inbox and handles it in the same
+// transaction. The event stays unrecorded when handle fails, so provider's retry is processed
+// again, while already recorded events are rejected with ErrWebhookEventAlreadyProcessed.
+func (r *paymentsRepo) ProcessWebhookEvent(
+	ctx context.Context,
+	event *dto.WebhookEventDto,
+	handle TxFunc,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	// events sent to the platform url have no restaurant
+	restaurantID := uuid.NullUUID{UUID: event.RestaurantID, Valid: event.RestaurantID != uuid.Nil}
+
+	recorded, err := qtx.SaveWebhookEvent(ctx, db.SaveWebhookEventParams{
+		Provider:     event.Provider,
+		EventID:      event.EventID,
+		RestaurantID: restaurantID,
+	})
+	if err != nil {
+		return fmt.Errorf("saving webhook event to database: %w", err)
+	}
+
+	if recorded == 0 {
+		return fmt.Errorf(
+			"%w: %s %s",
+			ErrWebhookEventAlreadyProcessed,
+			event.Provider,
+			event.EventID,
+		)
+	}
+
+	err = handle(NewOrdersRepo(qtx), &paymentsRepo{db: r.db, q: qtx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing webhook event transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (r *paymentsRepo) SavePayment(
 	ctx context.Context,
 	reqDto *dto.PaymentDto,
 ) (*dto.PaymentDto, error) {
+	// payments reported by providers get their id here, offline ones come with it
+	id := reqDto.ID
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
 	row, err := r.q.SavePayment(ctx, db.SavePaymentParams{
-		ID:                uuid.New(),
+		ID:                id,
 		OrderID:           reqDto.OrderID,
 		AmountInCents:     reqDto.AmountInCents,
 		Currency:          reqDto.Currency,
 		Provider:          reqDto.Provider,
 		ProviderPaymentID: reqDto.ProviderPaymentID,
+		TenderedInCents:   nullInt32FromPtr(reqDto.TenderedInCents),
+		ChangeDueInCents:  nullInt32FromPtr(reqDto.ChangeDueInCents),
+		RecordedBy:        nullUUIDFromPtr(reqDto.RecordedBy),
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentAlreadySaved, reqDto.ProviderPaymentID)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("saving payment %+v to database: %w", reqDto, err)
 	}
 
+	return paymentFromRow(row), nil
+}
+
+func (r *paymentsRepo) GetOrderAmountPaid(ctx context.Context, orderID uuid.UUID) (int, error) {
+	amount, err := r.q.GetOrderAmountPaid(ctx, orderID)
+	if err != nil {
+		return 0, fmt.Errorf("fetching order amount paid from database: %w", err)
+	}
+
+	return amount, nil
+}
+
+func (r *paymentsRepo) GetOrderPayments(
+	ctx context.Context,
+	orderID uuid.UUID,
+) ([]*dto.PaymentDto, error) {
+	rows, err := r.q.GetOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching order payments from database: %w", err)
+	}
+
+	payments := make([]*dto.PaymentDto, 0, len(rows))
+
+	for _, row := range rows {
+		payments = append(payments, &dto.PaymentDto{
+			ID:                    row.ID,
+			OrderID:               row.OrderID,
+			AmountInCents:         row.AmountInCents,
+			Provider:              row.Provider,
+			ProviderPaymentID:     row.ProviderPaymentID,
+			Currency:              row.Currency,
+			RefundedAmountInCents: row.RefundedAmountInCents,
+			Status:                row.Status,
+			TenderedInCents:       ptrFromNullInt32(row.TenderedInCents),
+			ChangeDueInCents:      ptrFromNullInt32(row.ChangeDueInCents),
+			RecordedBy:            ptrFromNullUUID(row.RecordedBy),
+		})
+	}
+
+	return payments, nil
+}
+
+func (r *paymentsRepo) GetOrderRefundedItemIDs(
+	ctx context.Context,
+	orderID uuid.UUID,
+) ([]uuid.UUID, error) {
+	itemIDs, err := r.q.GetOrderRefundedItemIDs(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching order refunded items from database: %w", err)
+	}
+
+	return itemIDs, nil
+}
+
+func (r *paymentsRepo) SaveRefund(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	row, err := r.q.SaveRefund(ctx, db.SaveRefundParams{
+		ID:               reqDto.ID,
+		PaymentID:        reqDto.PaymentID,
+		OrderID:          reqDto.OrderID,
+		AmountInCents:    reqDto.AmountInCents,
+		Currency:         reqDto.Currency,
+		Provider:         reqDto.Provider,
+		ProviderRefundID: nullStringFromEmpty(reqDto.ProviderRefundID),
+		Status:           reqDto.Status,
+		Reason:           reqDto.Reason,
+		OrderItemIds:     reqDto.OrderItemIDs,
+		RequestedBy:      uuid.NullUUID{UUID: reqDto.RequestedBy, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving refund %+v to database: %w", reqDto, err)
+	}
+
+	return refundFromRow(row), nil
+}
+
+// UpdateRefundFromProvider sets refund's id at the provider and the status provider created it
+// with, status isn't changed when a webhook already updated it.
+func (r *paymentsRepo) UpdateRefundFromProvider(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	row, err := r.q.UpdateRefundFromProvider(ctx, db.UpdateRefundFromProviderParams{
+		ProviderRefundID: nullStringFromEmpty(reqDto.ProviderRefundID),
+		Status:           reqDto.Status,
+		ID:               reqDto.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updating refund %s from provider in database: %w", reqDto.ID, err)
+	}
+
+	return refundFromRow(row), nil
+}
+
+// UpdateRefundStatus sets status of provider's refund, which is matched by provider's refund id
+// or by our id.
+func (r *paymentsRepo) UpdateRefundStatus(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	row, err := r.q.UpdateRefundStatus(ctx, db.UpdateRefundStatusParams{
+		Provider:         reqDto.Provider,
+		ProviderRefundID: nullStringFromEmpty(reqDto.ProviderRefundID),
+		Status:           reqDto.Status,
+		ID:               reqDto.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRefundDoesNotExist, reqDto.ProviderRefundID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("updating refund status in database: %w", err)
+	}
+
+	return refundFromRow(row), nil
+}
+
+func (r *paymentsRepo) MarkPaymentRefunded(ctx context.Context, paymentID uuid.UUID) error {
+	err := r.q.MarkPaymentRefunded(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("marking payment as refunded in database: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePaymentStatus sets status of provider's payment, refunded_at is set for refunded payments.
+func (r *paymentsRepo) UpdatePaymentStatus(
+	ctx context.Context,
+	reqDto *dto.PaymentDto,
+) (*dto.PaymentDto, error) {
+	row, err := r.q.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{
+		Provider:          reqDto.Provider,
+		ProviderPaymentID: reqDto.ProviderPaymentID,
+		Status:            reqDto.Status,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentDoesNotExist, reqDto.ProviderPaymentID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("updating payment status in database: %w", err)
+	}
+
+	return paymentFromRow(row), nil
+}
+
+func (r *paymentsRepo) SavePaymentAttempt(
+	ctx context.Context,
+	reqDto *dto.PaymentAttemptDto,
+) (*dto.PaymentAttemptDto, error) {
+	row, err := r.q.SavePaymentAttempt(ctx, db.SavePaymentAttemptParams{
+		ID:                reqDto.ID,
+		OrderID:           reqDto.OrderID,
+		Provider:          reqDto.Provider,
+		ProviderSessionID: reqDto.ProviderSessionID,
+		AmountInCents:     reqDto.AmountInCents,
+		Currency:          reqDto.Currency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving payment attempt %+v to database: %w", reqDto, err)
+	}
+
+	return paymentAttemptFromRow(row), nil
+}
+
+// UpdatePaymentAttemptStatus sets status of order's attempt matching either its id or provider's
+// session id. Attempts that are already finalized or were never saved are left as they are.
+func (r *paymentsRepo) UpdatePaymentAttemptStatus(
+	ctx context.Context,
+	reqDto *dto.PaymentAttemptDto,
+) error {
+	err := r.q.UpdatePaymentAttemptStatus(ctx, db.UpdatePaymentAttemptStatusParams{
+		OrderID:           reqDto.OrderID,
+		ID:                reqDto.ID,
+		Provider:          reqDto.Provider,
+		ProviderSessionID: reqDto.ProviderSessionID,
+		Status:            reqDto.Status,
+	})
+	if err != nil {
+		return fmt.Errorf("updating payment attempt status in database: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentsRepo) GetOrderPaymentAttempts(
+	ctx context.Context,
+	orderID uuid.UUID,
+) ([]*dto.PaymentAttemptDto, error) {
+	rows, err := r.q.GetOrderPaymentAttempts(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching order payment attempts from database: %w", err)
+	}
+
+	attempts := make([]*dto.PaymentAttemptDto, 0, len(rows))
+
+	for _, row := range rows {
+		attempts = append(attempts, paymentAttemptFromRow(row))
+	}
+
+	return attempts, nil
+}
+
+func paymentFromRow(row db.OrdersPayment) *dto.PaymentDto {
 	return &dto.PaymentDto{
+		ID:                    row.ID,
+		OrderID:               row.OrderID,
+		AmountInCents:         row.AmountInCents,
+		Currency:              row.Currency,
+		Provider:              row.Provider,
+		ProviderPaymentID:     row.ProviderPaymentID,
+		RefundedAmountInCents: 0,
+		Status:                row.Status,
+		TenderedInCents:       ptrFromNullInt32(row.TenderedInCents),
+		ChangeDueInCents:      ptrFromNullInt32(row.ChangeDueInCents),
+		RecordedBy:            ptrFromNullUUID(row.RecordedBy),
+	}
+}
+
+func refundFromRow(row db.OrdersRefund) *dto.RefundDto {
+	return &dto.RefundDto{
+		ID:               row.ID,
+		PaymentID:        row.PaymentID,
+		OrderID:          row.OrderID,
+		AmountInCents:    row.AmountInCents,
+		Currency:         row.Currency,
+		Provider:         row.Provider,
+		ProviderRefundID: row.ProviderRefundID.String,
+		Status:           row.Status,
+		Reason:           row.Reason,
+		OrderItemIDs:     row.OrderItemIds,
+		RequestedBy:      row.RequestedBy.UUID,
+	}
+}
+
+func paymentAttemptFromRow(row db.OrdersPaymentAttempt) *dto.PaymentAttemptDto {
+	return &dto.PaymentAttemptDto{
 		ID:                row.ID,
 		OrderID:           row.OrderID,
+		Provider:          row.Provider,
+		ProviderSessionID: row.ProviderSessionID,
 		AmountInCents:     row.AmountInCents,
 		Currency:          row.Currency,
-		Provider:          row.Provider,
-		ProviderPaymentID: row.ProviderPaymentID,
-	}, nil
+		Status:            row.Status,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+}
+
+func nullInt32FromPtr(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{Int32: 0, Valid: false}
+	}
+
+	return sql.NullInt32{Int32: int32(*v), Valid: true} //nolint:gosec
+}
+
+func ptrFromNullInt32(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+
+	i := int(v.Int32)
+
+	return &i
+}
+
+func nullUUIDFromPtr(v *uuid.UUID) uuid.NullUUID {
+	if v == nil {
+		return uuid.NullUUID{UUID: uuid.Nil, Valid: false}
+	}
+
+	return uuid.NullUUID{UUID: *v, Valid: true}
+}
+
+func ptrFromNullUUID(v uuid.NullUUID) *uuid.UUID {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.UUID
+}
+
+func nullStringFromPtr(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{String: "", Valid: false}
+	}
+
+	return sql.NullString{String: *v, Valid: true}
+}
+
+func nullStringFromEmpty(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+func ptrFromNullString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.String
 }
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPrinterDoesNotExist is returned if restaurant doesn't have the printer.
+	ErrPrinterDoesNotExist = errors.New("printer does not exist")
+	// ErrPrinterAlreadyExists is returned when restaurant already has printer with that name.
+	ErrPrinterAlreadyExists = errors.New("printer already exists")
+)
+
+// PrintersRepo defines methods for accessing restaurants' printers and tracking which order
+// items were printed on kitchen tickets.
+type PrintersRepo interface {
+	GetPrinters(ctx context.Context, restaurantID uuid.UUID) ([]*dto.PrinterDto, error)
+	CreatePrinter(ctx context.Context, reqDto *dto.CreatePrinterRequestDto) (*dto.PrinterDto, error)
+	DeletePrinter(ctx context.Context, restaurantID, printerID uuid.UUID) error
+	ClaimKitchenItems(ctx context.Context, orderID uuid.UUID) (*dto.KitchenOrderDto, error)
+	ReleaseKitchenItems(ctx context.Context, orderItemIDs []uuid.UUID) error
+}
+
+type printersRepo struct {
+	db *sql.DB
+	q  *db.Queries
+}
+
+// NewPrintersRepo creates a new printers reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewPrintersRepo(db *sql.DB, q *db.Queries) *printersRepo {
+	return &printersRepo{
+		db: db,
+		q:  q,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *printersRepo) GetPrinters(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.PrinterDto, error) {
+	rows, err := r.q.GetRestaurantPrinters(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching restaurant printers from database: %w", err)
+	}
+
+	printers := make([]*dto.PrinterDto, 0, len(rows))
+	for i := range rows {
+		printers = append(printers, printerFromRow(&rows[i]))
+	}
+
+	return printers, nil
+}
+
+func (r *printersRepo) CreatePrinter(
+	ctx context.Context,
+	reqDto *dto.CreatePrinterRequestDto,
+) (*dto.PrinterDto, error) {
+	row, err := r.q.CreatePrinter(ctx, db.CreatePrinterParams{
+		ID:             uuid.New(),
+		RestaurantID:   reqDto.RestaurantID,
+		Name:           reqDto.Name,
+		Host:           reqDto.Host,
+		Port:           reqDto.Port,
+		CategoryIds:    reqDto.CategoryIDs,
+		PrintsReceipts: reqDto.PrintsReceipts,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPrinterAlreadyExists, reqDto.Name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("inserting printer into database: %w", err)
+	}
+
+	return printerFromRow(&row), nil
+}
+
+func (r *printersRepo) DeletePrinter(
+	ctx context.Context,
+	restaurantID, printerID uuid.UUID,
+) error {
+	deleted, err := r.q.DeletePrinter(ctx, db.DeletePrinterParams{
+		ID:           printerID,
+		RestaurantID: restaurantID,
+	})
+	if err != nil {
+		return fmt.Errorf("deleting printer from database: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrPrinterDoesNotExist
+	}
+
+	return nil
+}
+
+// ClaimKitchenItems marks order items which weren't printed on kitchen tickets yet as sent to
+// the kitchen and returns them, items of tickets that failed to print have to be released.
+func (r *printersRepo) ClaimKitchenItems(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.KitchenOrderDto, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	rows, err := qtx.GetOrderItemsNotSentToKitchen(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order items not sent to kitchen: %w", err)
+	}
+
+	kitchenOrder := &dto.KitchenOrderDto{
+		OrderID:      orderID,
+		RestaurantID: uuid.Nil,
+		TableName:    "",
+		Items:        make([]*dto.KitchenItemDto, 0, len(rows)),
+	}
+
+	if len(rows) == 0 {
+		return kitchenOrder, nil
+	}
+
+	kitchenOrder.RestaurantID = rows[0].RestaurantID
+	kitchenOrder.TableName = rows[0].TableName
+
+	orderItemIDs := make([]uuid.UUID, 0, len(rows))
+
+	for _, row := range rows {
+		orderItemIDs = append(orderItemIDs, row.ID)
+		kitchenOrder.Items = append(kitchenOrder.Items, &dto.KitchenItemDto{
+			OrderItemID: row.ID,
+			Name:        row.ItemName,
+			CategoryID:  ptrFromNullUUID(row.CategoryID),
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+
+	err = qtx.MarkOrderItemsSentToKitchen(ctx, orderItemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("marking order items sent to kitchen: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return kitchenOrder, nil
+}
+
+func (r *printersRepo) ReleaseKitchenItems(ctx context.Context, orderItemIDs []uuid.UUID) error {
+	err := r.q.UnmarkOrderItemsSentToKitchen(ctx, orderItemIDs)
+	if err != nil {
+		return fmt.Errorf("unmarking order items sent to kitchen: %w", err)
+	}
+
+	return nil
+}
+
+func printerFromRow(row *db.OrdersRestaurantPrinter) *dto.PrinterDto {
+	return &dto.PrinterDto{
+		ID:             row.ID,
+		RestaurantID:   row.RestaurantID,
+		Name:           row.Name,
+		Host:           row.Host,
+		Port:           row.Port,
+		CategoryIDs:    row.CategoryIds,
+		PrintsReceipts: row.PrintsReceipts,
+		CreatedAt:      row.CreatedAt,
+	}
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/promotions"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPromoCodeDoesNotExist is returned if restaurant doesn't have the promo code.
+	ErrPromoCodeDoesNotExist = errors.New("promo code does not exist")
+	// ErrPromoCodeAlreadyExists is returned when restaurant already has promo code with that code.
+	ErrPromoCodeAlreadyExists = errors.New("promo code already exists")
+	// ErrPromoCodeUsedUp is returned when promo code reached its usage limit.
+	ErrPromoCodeUsedUp = errors.New("promo code usage limit is reached")
+	// ErrPromotionRuleDoesNotExist is returned if restaurant doesn't have the promotion rule.
+	ErrPromotionRuleDoesNotExist = errors.New("promotion rule does not exist")
+	// ErrOrderHasNoPromoCode is returned when removing promo code from order without one.
+	ErrOrderHasNoPromoCode = errors.New("order has no promo code applied")
+)
+
+// PromotionsRepo defines methods for accessing restaurants' promo codes and automatic
+// promotions, and promo codes applied to orders.
+type PromotionsRepo interface {
+	GetPromoCodes(ctx context.Context, restaurantID uuid.UUID) ([]*dto.PromoCodeDto, error)
+	GetPromoCodeByCode(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		code string,
+	) (*dto.PromoCodeDto, error)
+	CreatePromoCode(
+		ctx context.Context,
+		reqDto *dto.CreatePromoCodeRequestDto,
+	) (*dto.PromoCodeDto, error)
+	DeletePromoCode(ctx context.Context, restaurantID, promoCodeID uuid.UUID) error
+	GetPromotionRules(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+	) ([]*dto.PromotionRuleDto, error)
+	CreatePromotionRule(
+		ctx context.Context,
+		reqDto *dto.CreatePromotionRuleRequestDto,
+	) (*dto.PromotionRuleDto, error)
+	DeletePromotionRule(ctx context.Context, restaurantID, ruleID uuid.UUID) error
+	ApplyPromoCodeToOrder(ctx context.Context, orderID, promoCodeID uuid.UUID) error
+	RemovePromoCodeFromOrder(ctx context.Context, orderID uuid.UUID) error
+}
+
+type promotionsRepo struct {
+	db *sql.DB
+	q  *db.Queries
+}
+
+// NewPromotionsRepo creates a new promotions reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewPromotionsRepo(db *sql.DB, q *db.Queries) *promotionsRepo {
+	return &promotionsRepo{
+		db: db,
+		q:  q,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *promotionsRepo) GetPromoCodes(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.PromoCodeDto, error) {
+	rows, err := r.q.GetRestaurantPromoCodes(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching restaurant promo codes from database: %w", err)
+	}
+
+	codes := make([]*dto.PromoCodeDto, 0, len(rows))
+	for _, row := range rows {
+		codes = append(codes, promoCodeFromRow(&row))
+	}
+
+	return codes, nil
+}
+
+func (r *promotionsRepo) GetPromoCodeByCode(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	code string,
+) (*dto.PromoCodeDto, error) {
+	row, err := r.q.GetPromoCodeByCode(ctx, db.GetPromoCodeByCodeParams{
+		RestaurantID: restaurantID,
+		Code:         code,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPromoCodeDoesNotExist
+		}
+
+		return nil, fmt.Errorf("fetching promo code from database: %w", err)
+	}
+
+	return promoCodeFromRow(&row), nil
+}
+
+func (r *promotionsRepo) CreatePromoCode(
+	ctx context.Context,
+	reqDto *dto.CreatePromoCodeRequestDto,
+) (*dto.PromoCodeDto, error) {
+	row, err := r.q.CreatePromoCode(ctx, db.CreatePromoCodeParams{
+		ID:              uuid.New(),
+		RestaurantID:    reqDto.RestaurantID,
+		Code:            reqDto.Code,
+		DiscountType:    reqDto.DiscountType,
+		DiscountValue:   reqDto.DiscountValue,
+		MinSpendInCents: reqDto.MinSpendInCents,
+		ValidFrom:       nullTimeFromPtr(reqDto.ValidFrom),
+		ValidUntil:      nullTimeFromPtr(reqDto.ValidUntil),
+		MaxUses:         nullInt32FromPtr(reqDto.MaxUses),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPromoCodeAlreadyExists, reqDto.Code)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("inserting promo code into database: %w", err)
+	}
+
+	return promoCodeFromRow(&row), nil
+}
+
+// DeletePromoCode marks the promo code deleted and removes it from open orders in one
+// transaction. Orders that aren't open anymore keep the code and discount it gave them.
+func (r *promotionsRepo) DeletePromoCode(
+	ctx context.Context,
+	restaurantID, promoCodeID uuid.UUID,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	deleted, err := qtx.DeletePromoCode(ctx, db.DeletePromoCodeParams{
+		ID:           promoCodeID,
+		RestaurantID: restaurantID,
+	})
+	if err != nil {
+		return fmt.Errorf("deleting promo code from database: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrPromoCodeDoesNotExist
+	}
+
+	err = qtx.RemovePromoCodeFromOpenOrders(ctx, promoCodeID)
+	if err != nil {
+		return fmt.Errorf("removing promo code from open orders in database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing database transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *promotionsRepo) GetPromotionRules(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.PromotionRuleDto, error) {
+	rows, err := r.q.GetRestaurantPromotionRules(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching restaurant promotion rules from database: %w", err)
+	}
+
+	return promotionRulesFromRows(rows), nil
+}
+
+func (r *promotionsRepo) CreatePromotionRule(
+	ctx context.Context,
+	reqDto *dto.CreatePromotionRuleRequestDto,
+) (*dto.PromotionRuleDto, error) {
+	row, err := r.q.CreatePromotionRule(ctx, db.CreatePromotionRuleParams{
+		ID:           uuid.New(),
+		RestaurantID: reqDto.RestaurantID,
+		Name:         reqDto.Name,
+		RuleType:     reqDto.RuleType,
+		CategoryID:   nullUUIDFromPtr(reqDto.CategoryID),
+		PercentOff:   reqDto.PercentOff,
+		BuyQuantity:  reqDto.BuyQuantity,
+		FreeQuantity: reqDto.FreeQuantity,
+		StartsAt:     reqDto.StartsAt,
+		EndsAt:       reqDto.EndsAt,
+		Timezone:     reqDto.Timezone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("inserting promotion rule into database: %w", err)
+	}
+
+	return promotionRuleFromRow(&row), nil
+}
+
+// DeletePromotionRule marks the promotion rule deleted, open orders stop getting its discount
+// while orders that aren't open anymore keep it.
+func (r *promotionsRepo) DeletePromotionRule(
+	ctx context.Context,
+	restaurantID, ruleID uuid.UUID,
+) error {
+	deleted, err := r.q.DeletePromotionRule(ctx, db.DeletePromotionRuleParams{
+		ID:           ruleID,
+		RestaurantID: restaurantID,
+	})
+	if err != nil {
+		return fmt.Errorf("deleting promotion rule from database: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrPromotionRuleDoesNotExist
+	}
+
+	return nil
+}
+
+// ApplyPromoCodeToOrder counts a use of the promo code and applies it to the order in one
+// transaction. Code that was applied to the order before is replaced and its use is released,
+// applying the same code again doesn't count another use.
+func (r *promotionsRepo) ApplyPromoCodeToOrder(
+	ctx context.Context,
+	orderID, promoCodeID uuid.UUID,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	previousID, err := qtx.GetOrderPromoCodeID(ctx, orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		previousID = uuid.Nil
+	} else if err != nil {
+		return fmt.Errorf("fetching order promo code from database: %w", err)
+	}
+
+	if previousID == promoCodeID {
+		return nil
+	}
+
+	redeemed, err := qtx.RedeemPromoCode(ctx, promoCodeID)
+	if err != nil {
+		return fmt.Errorf("redeeming promo code in database: %w", err)
+	}
+
+	if redeemed == 0 {
+		return ErrPromoCodeUsedUp
+	}
+
+	if previousID != uuid.Nil {
+		err = qtx.ReleasePromoCode(ctx, previousID)
+		if err != nil {
+			return fmt.Errorf("releasing previous promo code in database: %w", err)
+		}
+	}
+
+	err = qtx.SetOrderPromoCode(ctx, db.SetOrderPromoCodeParams{
+		OrderID:     orderID,
+		PromoCodeID: promoCodeID,
+	})
+	if err != nil {
+		return fmt.Errorf("setting order promo code in database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing database transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePromoCodeFromOrder removes order's promo code and releases its use.
+func (r *promotionsRepo) RemovePromoCodeFromOrder(ctx context.Context, orderID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	promoCodeID, err := qtx.DeleteOrderPromoCode(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrderHasNoPromoCode
+		}
+
+		return fmt.Errorf("deleting order promo code from database: %w", err)
+	}
+
+	err = qtx.ReleasePromoCode(ctx, promoCodeID)
+	if err != nil {
+		return fmt.Errorf("releasing promo code in database: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing database transaction: %w", err)
+	}
+
+	return nil
+}
+
+func promoCodeFromRow(row *db.OrdersPromoCode) *dto.PromoCodeDto {
+	return &dto.PromoCodeDto{
+		ID:              row.ID,
+		RestaurantID:    row.RestaurantID,
+		Code:            row.Code,
+		DiscountType:    row.DiscountType,
+		DiscountValue:   row.DiscountValue,
+		MinSpendInCents: row.MinSpendInCents,
+		ValidFrom:       ptrFromNullTime(row.ValidFrom),
+		ValidUntil:      ptrFromNullTime(row.ValidUntil),
+		MaxUses:         ptrFromNullInt32(row.MaxUses),
+		UsesCount:       row.UsesCount,
+		CreatedAt:       row.CreatedAt,
+	}
+}
+
+func promotionRulesFromRows(rows []db.OrdersPromotionRule) []*dto.PromotionRuleDto {
+	rules := make([]*dto.PromotionRuleDto, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, promotionRuleFromRow(&row))
+	}
+
+	return rules
+}
+
+func promotionRuleFromRow(row *db.OrdersPromotionRule) *dto.PromotionRuleDto {
+	return &dto.PromotionRuleDto{
+		ID:           row.ID,
+		RestaurantID: row.RestaurantID,
+		Name:         row.Name,
+		RuleType:     row.RuleType,
+		CategoryID:   ptrFromNullUUID(row.CategoryID),
+		PercentOff:   row.PercentOff,
+		BuyQuantity:  row.BuyQuantity,
+		FreeQuantity: row.FreeQuantity,
+		StartsAt:     row.StartsAt.Format(promotions.TimeLayout),
+		EndsAt:       row.EndsAt.Format(promotions.TimeLayout),
+		Timezone:     row.Timezone,
+		IsActive:     row.IsActive,
+		CreatedAt:    row.CreatedAt,
+	}
+}
+
+func nullTimeFromPtr(v *time.Time) sql.NullTime {
+	if v == nil {
+		return sql.NullTime{Time: time.Time{}, Valid: false}
+	}
+
+	return sql.NullTime{Time: *v, Valid: true}
+}
+
+func ptrFromNullTime(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Time
+}
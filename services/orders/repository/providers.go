@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/pkg/encryption"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+
+	"github.com/google/uuid"
+)
+
+// ErrPaymentProviderDoesNotExist is returned if restaurant hasn't configured the payment provider.
+var ErrPaymentProviderDoesNotExist = errors.New("payment provider is not configured for restaurant")
+
+// PaymentProvidersRepo defines methods for accessing and managing restaurants' payment providers.
+// Provider credentials are encrypted before they are stored and decrypted when read.
+type PaymentProvidersRepo interface {
+	GetRestaurantPaymentProviders(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+	) ([]*dto.RestaurantPaymentProviderDto, error)
+	SaveRestaurantPaymentProvider(
+		ctx context.Context,
+		reqDto *dto.SetPaymentProviderRequestDto,
+	) (*dto.RestaurantPaymentProviderDto, error)
+	DeleteRestaurantPaymentProvider(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		provider db.OrdersPaymentProvider,
+	) error
+}
+
+type paymentProvidersRepo struct {
+	db     *sql.DB
+	q      *db.Queries
+	cipher *encryption.Cipher
+}
+
+// NewPaymentProvidersRepo creates a new payment providers reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewPaymentProvidersRepo(
+	db *sql.DB,
+	q *db.Queries,
+	cipher *encryption.Cipher,
+) *paymentProvidersRepo {
+	return &paymentProvidersRepo{
+		db:     db,
+		q:      q,
+		cipher: cipher,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *paymentProvidersRepo) GetRestaurantPaymentProviders(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.RestaurantPaymentProviderDto, error) {
+	rows, err := r.q.GetRestaurantPaymentProviders(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting restaurant payment providers: %w", err)
+	}
+
+	providers := make([]*dto.RestaurantPaymentProviderDto, 0, len(rows))
+
+	for _, row := range rows {
+		provider, err := r.providerFromRow(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+func (r *paymentProvidersRepo) SaveRestaurantPaymentProvider(
+	ctx context.Context,
+	reqDto *dto.SetPaymentProviderRequestDto,
+) (*dto.RestaurantPaymentProviderDto, error) {
+	rawCredentials, err := json.Marshal(reqDto.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling payment provider credentials: %w", err)
+	}
+
+	credentials, err := r.cipher.Encrypt(rawCredentials)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting payment provider credentials: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting database transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	qtx := r.q.WithTx(tx)
+
+	if reqDto.IsDefault {
+		err = qtx.UnsetDefaultPaymentProvider(ctx, db.UnsetDefaultPaymentProviderParams{
+			RestaurantID: reqDto.RestaurantID,
+			Provider:     reqDto.Provider,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unsetting default payment provider: %w", err)
+		}
+	}
+
+	row, err := qtx.SaveRestaurantPaymentProvider(ctx, db.SaveRestaurantPaymentProviderParams{
+		RestaurantID: reqDto.RestaurantID,
+		Provider:     reqDto.Provider,
+		Credentials:  credentials,
+		IsDefault:    reqDto.IsDefault,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving restaurant payment provider: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("committing save payment provider transaction: %w", err)
+	}
+
+	return &dto.RestaurantPaymentProviderDto{
+		RestaurantID: row.RestaurantID,
+		Provider:     row.Provider,
+		Credentials:  reqDto.Credentials,
+		IsDefault:    row.IsDefault,
+		WebhookURL:   "",
+		UpdatedAt:    row.UpdatedAt,
+	}, nil
+}
+
+func (r *paymentProvidersRepo) DeleteRestaurantPaymentProvider(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) error {
+	deleted, err := r.q.DeleteRestaurantPaymentProvider(
+		ctx,
+		db.DeleteRestaurantPaymentProviderParams{
+			RestaurantID: restaurantID,
+			Provider:     provider,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("deleting restaurant payment provider: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrPaymentProviderDoesNotExist
+	}
+
+	return nil
+}
+
+func (r *paymentProvidersRepo) providerFromRow(
+	row *db.OrdersRestaurantPaymentProvider,
+) (*dto.RestaurantPaymentProviderDto, error) {
+	rawCredentials, err := r.cipher.Decrypt(row.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s credentials: %w", row.Provider, err)
+	}
+
+	var credentials dto.ProviderCredentialsDto
+
+	err = json.Unmarshal(rawCredentials, &credentials)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling %s credentials: %w", row.Provider, err)
+	}
+
+	return &dto.RestaurantPaymentProviderDto{
+		RestaurantID: row.RestaurantID,
+		Provider:     row.Provider,
+		Credentials:  &credentials,
+		IsDefault:    row.IsDefault,
+		WebhookURL:   "",
+		UpdatedAt:    row.UpdatedAt,
+	}, nil
+}
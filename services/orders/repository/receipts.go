@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrReceiptSettingsDoNotExist is returned if restaurant hasn't configured its receipts.
+	ErrReceiptSettingsDoNotExist = errors.New("receipt settings are not configured for restaurant")
+	// ErrReceiptDoesNotExist is returned if receipt wasn't issued for the order.
+	ErrReceiptDoesNotExist = errors.New("receipt is not issued for this order")
+)
+
+// ReceiptsRepo defines methods for accessing restaurants' receipt settings and receipts
+// issued to orders.
+type ReceiptsRepo interface {
+	GetReceiptSettings(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+	) (*dto.ReceiptSettingsDto, error)
+	SaveReceiptSettings(
+		ctx context.Context,
+		reqDto *dto.SetReceiptSettingsRequestDto,
+	) (*dto.ReceiptSettingsDto, error)
+	GetReceipt(ctx context.Context, orderID uuid.UUID) (*dto.IssuedReceiptDto, error)
+	SaveReceiptContents(
+		ctx context.Context,
+		receiptID uuid.UUID,
+		contents *dto.ReceiptContentsDto,
+	) error
+}
+
+type receiptsRepo struct {
+	q *db.Queries
+}
+
+// NewReceiptsRepo creates a new receipts reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewReceiptsRepo(q *db.Queries) *receiptsRepo {
+	return &receiptsRepo{
+		q: q,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *receiptsRepo) GetReceiptSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (*dto.ReceiptSettingsDto, error) {
+	row, err := r.q.GetRestaurantReceiptSettings(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReceiptSettingsDoNotExist
+		}
+
+		return nil, fmt.Errorf("getting restaurant receipt settings: %w", err)
+	}
+
+	return receiptSettingsFromRow(&row), nil
+}
+
+func (r *receiptsRepo) SaveReceiptSettings(
+	ctx context.Context,
+	reqDto *dto.SetReceiptSettingsRequestDto,
+) (*dto.ReceiptSettingsDto, error) {
+	row, err := r.q.SaveRestaurantReceiptSettings(ctx, db.SaveRestaurantReceiptSettingsParams{
+		RestaurantID: reqDto.RestaurantID,
+		TaxRate:      *reqDto.TaxRate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving restaurant receipt settings: %w", err)
+	}
+
+	return receiptSettingsFromRow(&row), nil
+}
+
+// GetReceipt returns receipt issued for the order when it was completed.
+func (r *receiptsRepo) GetReceipt(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.IssuedReceiptDto, error) {
+	row, err := r.q.GetOrderReceipt(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrReceiptDoesNotExist, orderID)
+		}
+
+		return nil, fmt.Errorf("getting order receipt: %w", err)
+	}
+
+	return issuedReceiptFromRow(&row)
+}
+
+// SaveReceiptContents saves contents of the receipt issued before contents were saved with
+// receipts, receipts that already have contents keep them.
+func (r *receiptsRepo) SaveReceiptContents(
+	ctx context.Context,
+	receiptID uuid.UUID,
+	contents *dto.ReceiptContentsDto,
+) error {
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("encoding receipt contents: %w", err)
+	}
+
+	err = r.q.SaveReceiptContents(ctx, db.SaveReceiptContentsParams{
+		ID:       receiptID,
+		Contents: data,
+	})
+	if err != nil {
+		return fmt.Errorf("saving receipt contents: %w", err)
+	}
+
+	return nil
+}
+
+// IssueReceipt gives the order next receipt number of its restaurant and saves contents of the
+// receipt, orders that already have a receipt keep it. It runs in the transaction that
+// completes the order, which holds order's row locked, so concurrent completions issue a
+// single receipt.
+func (r *ordersRepo) IssueReceipt(
+	ctx context.Context,
+	orderID, restaurantID uuid.UUID,
+	contents *dto.ReceiptContentsDto,
+) (*dto.IssuedReceiptDto, error) {
+	row, err := r.q.GetOrderReceipt(ctx, orderID)
+	if err == nil {
+		return issuedReceiptFromRow(&row)
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("getting order receipt: %w", err)
+	}
+
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return nil, fmt.Errorf("encoding receipt contents: %w", err)
+	}
+
+	number, err := r.q.NextReceiptNumber(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting next receipt number: %w", err)
+	}
+
+	err = r.q.CreateReceipt(ctx, db.CreateReceiptParams{
+		ID:            uuid.New(),
+		OrderID:       orderID,
+		RestaurantID:  restaurantID,
+		ReceiptNumber: number,
+		Contents:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating receipt: %w", err)
+	}
+
+	row, err = r.q.GetOrderReceipt(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting created receipt: %w", err)
+	}
+
+	return issuedReceiptFromRow(&row)
+}
+
+func receiptSettingsFromRow(row *db.OrdersRestaurantReceiptSetting) *dto.ReceiptSettingsDto {
+	updatedAt := row.UpdatedAt
+
+	return &dto.ReceiptSettingsDto{
+		RestaurantID: row.RestaurantID,
+		TaxRate:      row.TaxRate,
+		UpdatedAt:    &updatedAt,
+	}
+}
+
+func issuedReceiptFromRow(row *db.GetOrderReceiptRow) (*dto.IssuedReceiptDto, error) {
+	var contents *dto.ReceiptContentsDto
+
+	err := json.Unmarshal(row.Contents, &contents)
+	if err != nil {
+		return nil, fmt.Errorf("decoding receipt contents: %w", err)
+	}
+
+	return &dto.IssuedReceiptDto{
+		ID:                row.ID,
+		OrderID:           row.OrderID,
+		RestaurantID:      row.RestaurantID,
+		Number:            row.ReceiptNumber,
+		TaxRate:           row.TaxRate,
+		IssuedAt:          row.IssuedAt,
+		Contents:          contents,
+		RestaurantName:    row.RestaurantName,
+		RestaurantAddress: row.RestaurantAddress,
+	}, nil
+}
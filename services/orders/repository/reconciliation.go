@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+)
+
+// ReconciliationRepo defines methods for reading payments that are reconciled with providers.
+type ReconciliationRepo interface {
+	GetProviderPayments(
+		ctx context.Context,
+		reqDto *dto.ReconciliationRequestDto,
+	) ([]*dto.ReconciliationPaymentDto, error)
+}
+
+type reconciliationRepo struct {
+	q *db.Queries
+}
+
+// NewReconciliationRepo creates a new reconciliation reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewReconciliationRepo(q *db.Queries) *reconciliationRepo {
+	return &reconciliationRepo{
+		q: q,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *reconciliationRepo) GetProviderPayments(
+	ctx context.Context,
+	reqDto *dto.ReconciliationRequestDto,
+) ([]*dto.ReconciliationPaymentDto, error) {
+	rows, err := r.q.GetProviderPayments(ctx, db.GetProviderPaymentsParams{
+		Provider:     reqDto.Provider,
+		CreatedFrom:  reqDto.From,
+		CreatedTo:    reqDto.To,
+		RestaurantID: nullUUIDFromPtr(reqDto.RestaurantID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting provider payments: %w", err)
+	}
+
+	payments := make([]*dto.ReconciliationPaymentDto, 0, len(rows))
+
+	for _, row := range rows {
+		payments = append(payments, &dto.ReconciliationPaymentDto{
+			ID:                row.ID,
+			OrderID:           row.OrderID,
+			ProviderPaymentID: row.ProviderPaymentID,
+			AmountInCents:     row.AmountInCents,
+			Currency:          row.Currency,
+			CreatedAt:         row.CreatedAt,
+		})
+	}
+
+	return payments, nil
+}
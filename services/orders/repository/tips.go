@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+
+	"github.com/google/uuid"
+)
+
+// ErrTipSettingsDoNotExist is returned if restaurant hasn't configured its tips.
+var ErrTipSettingsDoNotExist = errors.New("tip settings are not configured for restaurant")
+
+// TipsRepo defines methods for accessing restaurants' tip settings and tips of completed orders.
+type TipsRepo interface {
+	GetTipSettings(ctx context.Context, restaurantID uuid.UUID) (*dto.TipSettingsDto, error)
+	SaveTipSettings(
+		ctx context.Context,
+		reqDto *dto.SetTipSettingsRequestDto,
+	) (*dto.TipSettingsDto, error)
+	GetCompletedOrdersWaiters(
+		ctx context.Context,
+		reqDto *dto.TipReportRequestDto,
+	) ([]*dto.CompletedOrderWaiterDto, error)
+}
+
+type tipsRepo struct {
+	q *db.Queries
+}
+
+// NewTipsRepo creates a new tips reposiotry instance.
+//
+//revive:disable:unexported-return
+func NewTipsRepo(q *db.Queries) *tipsRepo {
+	return &tipsRepo{
+		q: q,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (r *tipsRepo) GetTipSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (*dto.TipSettingsDto, error) {
+	row, err := r.q.GetRestaurantTipSettings(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTipSettingsDoNotExist
+		}
+
+		return nil, fmt.Errorf("getting restaurant tip settings: %w", err)
+	}
+
+	return tipSettingsFromRow(&row), nil
+}
+
+func (r *tipsRepo) SaveTipSettings(
+	ctx context.Context,
+	reqDto *dto.SetTipSettingsRequestDto,
+) (*dto.TipSettingsDto, error) {
+	row, err := r.q.SaveRestaurantTipSettings(ctx, db.SaveRestaurantTipSettingsParams{
+		RestaurantID:     reqDto.RestaurantID,
+		Presets:          reqDto.Presets,
+		PoolRule:         reqDto.PoolRule,
+		ShiftLengthHours: reqDto.ShiftLengthHours,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving restaurant tip settings: %w", err)
+	}
+
+	return tipSettingsFromRow(&row), nil
+}
+
+func (r *tipsRepo) GetCompletedOrdersWaiters(
+	ctx context.Context,
+	reqDto *dto.TipReportRequestDto,
+) ([]*dto.CompletedOrderWaiterDto, error) {
+	rows, err := r.q.GetCompletedOrdersWaiters(ctx, db.GetCompletedOrdersWaitersParams{
+		RestaurantID:  reqDto.RestaurantID,
+		CompletedFrom: reqDto.From,
+		CompletedTo:   reqDto.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting completed orders waiters: %w", err)
+	}
+
+	waiters := make([]*dto.CompletedOrderWaiterDto, 0, len(rows))
+
+	for _, row := range rows {
+		var userID *uuid.UUID
+		if row.UserID.Valid {
+			userID = &row.UserID.UUID
+		}
+
+		waiters = append(waiters, &dto.CompletedOrderWaiterDto{
+			OrderID:          row.OrderID,
+			TipAmountInCents: row.TipAmountInCents,
+			CompletedAt:      row.CompletedAt,
+			UserID:           userID,
+			AssignedAt:       row.AssignedAt.Time,
+		})
+	}
+
+	return waiters, nil
+}
+
+func tipSettingsFromRow(row *db.OrdersRestaurantTipSetting) *dto.TipSettingsDto {
+	updatedAt := row.UpdatedAt
+
+	return &dto.TipSettingsDto{
+		RestaurantID:     row.RestaurantID,
+		Presets:          row.Presets,
+		PoolRule:         row.PoolRule,
+		ShiftLengthHours: row.ShiftLengthHours,
+		UpdatedAt:        &updatedAt,
+	}
+}
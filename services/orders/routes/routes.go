@@ -3,13 +3,21 @@ package routes
 
 import (
 	// "golang-dining-ordering/pkg/responses"
-	// authDto "golang-dining-ordering/services/auth/dto".
+	pkgMiddleware "golang-dining-ordering/pkg/middleware"
+	authDto "golang-dining-ordering/services/auth/dto"
 	"golang-dining-ordering/services/management/middleware"
 	"golang-dining-ordering/services/orders/handlers"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// receiptEmailsLimit is how many receipt emails one order can send in receiptEmailsWindow.
+	receiptEmailsLimit  = 5
+	receiptEmailsWindow = time.Hour
+)
+
 // AddOrdersRoutes registers orders related HTTP routes.
 func AddOrdersRoutes(
 	e *echo.Echo,
@@ -24,6 +32,8 @@ func AddOrdersRoutes(
 	publicAPI.GET("/:order_id", ordersHandler.HandleGetOrder)
 	publicAPI.POST("/:order_id/items", ordersHandler.HandleAddItemToOrder)
 	publicAPI.DELETE("/:order_id/items", ordersHandler.HandleDeleteItemFromOrder)
+	publicAPI.PUT("/:order_id/promo-code", ordersHandler.HandleApplyPromoCode)
+	publicAPI.DELETE("/:order_id/promo-code", ordersHandler.HandleRemovePromoCode)
 	publicAPI.PATCH(
 		"/:order_id",
 		ordersHandler.HandleUpdateOrder,
@@ -40,11 +50,195 @@ func AddOrdersRoutes(
 		middleware.AuthMiddleware(authEndpoint),
 	)
 
+	publicAPI.GET(
+		"/:order_id/payments",
+		paymentsHandler.HandleGetOrderPayments,
+		middleware.AuthMiddleware(authEndpoint, false),
+	)
 	publicAPI.POST("/:order_id/payments", paymentsHandler.HandleCreateCheckout)
-	publicAPI.POST("/webhooks/payment-success", paymentsHandler.HandleWebhookSuccess)
+	publicAPI.POST(
+		"/:order_id/payments/offline",
+		paymentsHandler.HandleRecordOfflinePayment,
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleWaiter),
+	)
+	publicAPI.POST(
+		"/:order_id/payments/refunds",
+		paymentsHandler.HandleRefund,
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+	publicAPI.POST("/webhooks/:provider", paymentsHandler.HandleWebhook)
+	publicAPI.POST("/webhooks/:provider/:restaurant_id", paymentsHandler.HandleWebhook)
 	publicAPI.GET(
 		"/:order_id/ws",
 		websocketHandler.HandleOrderWebsocket,
+		middleware.TokenFromQueryMiddleware("access_token"),
+		middleware.AuthMiddleware(authEndpoint, false),
+	)
+	publicAPI.GET(
+		"/:order_id/events",
+		websocketHandler.HandleOrderEvents,
+		middleware.TokenFromQueryMiddleware("access_token"),
+		middleware.AuthMiddleware(authEndpoint, false),
+	)
+}
+
+// AddPaymentProvidersRoutes registers routes managers use to configure restaurant's payment providers.
+func AddPaymentProvidersRoutes(
+	e *echo.Echo,
+	providersHandler *handlers.PaymentProvidersHandler,
+	authEndpoint string,
+) {
+	managerAPI := e.Group("/api/v1/restaurants/:restaurant_id/payment-providers",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+
+	managerAPI.GET("", providersHandler.HandleGetPaymentProviders)
+	managerAPI.PUT("/:provider", providersHandler.HandleSetPaymentProvider)
+	managerAPI.DELETE("/:provider", providersHandler.HandleDeletePaymentProvider)
+}
+
+// AddTipsRoutes registers routes managers use to configure restaurant's tips and get tip reports.
+func AddTipsRoutes(e *echo.Echo, tipsHandler *handlers.TipsHandler, authEndpoint string) {
+	managerAPI := e.Group("/api/v1/restaurants/:restaurant_id/tips",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+
+	managerAPI.GET("/settings", tipsHandler.HandleGetTipSettings)
+	managerAPI.PUT("/settings", tipsHandler.HandleSetTipSettings)
+	managerAPI.GET("/report", tipsHandler.HandleGetTipReport)
+}
+
+// AddPromotionsRoutes registers routes managers use to manage restaurant's promo codes and
+// automatic promotions.
+func AddPromotionsRoutes(
+	e *echo.Echo,
+	promotionsHandler *handlers.PromotionsHandler,
+	authEndpoint string,
+) {
+	managerAPI := e.Group("/api/v1/restaurants/:restaurant_id/promotions",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+
+	managerAPI.GET("/codes", promotionsHandler.HandleGetPromoCodes)
+	managerAPI.POST("/codes", promotionsHandler.HandleCreatePromoCode)
+	managerAPI.DELETE("/codes/:promo_code_id", promotionsHandler.HandleDeletePromoCode)
+	managerAPI.GET("/rules", promotionsHandler.HandleGetPromotionRules)
+	managerAPI.POST("/rules", promotionsHandler.HandleCreatePromotionRule)
+	managerAPI.DELETE("/rules/:rule_id", promotionsHandler.HandleDeletePromotionRule)
+}
+
+func orderIDKey(c echo.Context) string {
+	return c.Param("order_id")
+}
+
+// AddReceiptsRoutes registers routes guests use to get receipts of their completed orders and
+// managers use to configure restaurant's receipts.
+func AddReceiptsRoutes(
+	e *echo.Echo,
+	receiptsHandler *handlers.ReceiptsHandler,
+	authEndpoint string,
+) {
+	publicAPI := e.Group("/api/v1/orders")
+
+	publicAPI.GET("/:order_id/receipt", receiptsHandler.HandleGetReceipt)
+	publicAPI.POST(
+		"/:order_id/receipt/email",
+		receiptsHandler.HandleEmailReceipt,
+		middleware.AuthMiddleware(authEndpoint, false),
+		pkgMiddleware.RateLimiter(receiptEmailsLimit, receiptEmailsWindow, orderIDKey),
+	)
+
+	managerAPI := e.Group("/api/v1/restaurants/:restaurant_id/receipts",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+
+	managerAPI.GET("/settings", receiptsHandler.HandleGetReceiptSettings)
+	managerAPI.PUT("/settings", receiptsHandler.HandleSetReceiptSettings)
+}
+
+// AddPrintingRoutes registers routes managers use to configure restaurant's printers and
+// waiters use to print kitchen tickets and receipts.
+func AddPrintingRoutes(
+	e *echo.Echo,
+	printingHandler *handlers.PrintingHandler,
+	authEndpoint string,
+) {
+	managerAPI := e.Group("/api/v1/restaurants/:restaurant_id/printers",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+
+	managerAPI.GET("", printingHandler.HandleGetPrinters)
+	managerAPI.POST("", printingHandler.HandleCreatePrinter)
+	managerAPI.DELETE("/:printer_id", printingHandler.HandleDeletePrinter)
+
+	waiterAPI := e.Group("/api/v1/orders/:order_id/print",
+		middleware.AuthMiddleware(authEndpoint),
+		middleware.RoleMiddleware(authDto.RoleWaiter),
+	)
+
+	waiterAPI.POST("/tickets", printingHandler.HandlePrintKitchenTickets)
+	waiterAPI.POST("/receipt", printingHandler.HandlePrintReceipt)
+}
+
+// AddStaffFeedRoutes registers staff feed waiters and managers follow orders of their
+// restaurant with.
+func AddStaffFeedRoutes(
+	e *echo.Echo,
+	staffFeedHandler *handlers.StaffFeedHandler,
+	authEndpoint string,
+) {
+	e.GET(
+		"/api/v1/restaurants/:restaurant_id/feed/ws",
+		staffFeedHandler.HandleStaffFeedWebsocket,
+		middleware.TokenFromQueryMiddleware("access_token"),
+		middleware.AuthMiddleware(authEndpoint),
+	)
+}
+
+// AddAssistanceRoutes registers routes guests with table session use to ask staff for
+// assistance from the table, staff uses to respond to the requests and managers use to get
+// response times report.
+func AddAssistanceRoutes(
+	e *echo.Echo,
+	assistanceHandler *handlers.AssistanceHandler,
+	authEndpoint string,
+) {
+	publicAPI := e.Group("/api/v1/orders")
+
+	publicAPI.POST(
+		"/:order_id/assistance",
+		assistanceHandler.HandleRequestAssistance,
 		middleware.AuthMiddleware(authEndpoint, false),
 	)
+	publicAPI.PATCH(
+		"/:order_id/assistance/:request_id",
+		assistanceHandler.HandleUpdateAssistanceRequest,
+		middleware.AuthMiddleware(authEndpoint),
+	)
+
+	staffAPI := e.Group("/api/v1/restaurants/:restaurant_id/assistance",
+		middleware.AuthMiddleware(authEndpoint),
+	)
+
+	staffAPI.GET("", assistanceHandler.HandleGetAssistanceRequests)
+	staffAPI.GET(
+		"/report",
+		assistanceHandler.HandleGetAssistanceReport,
+		middleware.RoleMiddleware(authDto.RoleManager),
+	)
+}
+
+// AddMockCheckoutRoutes registers hosted checkout page of the mock payment provider.
+func AddMockCheckoutRoutes(e *echo.Echo, mockCheckoutHandler *handlers.MockCheckoutHandler) {
+	publicAPI := e.Group("/api/v1/orders")
+
+	publicAPI.GET("/mock-checkout/:session_id", mockCheckoutHandler.HandleGetCheckoutPage)
+	publicAPI.POST("/mock-checkout/:session_id", mockCheckoutHandler.HandleCheckoutAction)
 }
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/sessions"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssistanceService defines business logic methods for assistance requests guests send to
+// staff from the table, and reports of how quickly staff responds to them.
+type AssistanceService interface {
+	RequestAssistance(
+		ctx context.Context,
+		reqDto *dto.CreateAssistanceRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.AssistanceRequestDto, error)
+	RequestParticipantAssistance(
+		ctx context.Context,
+		reqDto *dto.CreateAssistanceRequestDto,
+	) (*dto.AssistanceRequestDto, error)
+	GetUnresolvedAssistanceRequests(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.AssistanceRequestDto, error)
+	UpdateAssistanceRequest(
+		ctx context.Context,
+		reqDto *dto.UpdateAssistanceRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.AssistanceRequestDto, error)
+	GetAssistanceReport(
+		ctx context.Context,
+		reqDto *dto.AssistanceReportRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.AssistanceReportDto, error)
+}
+
+// assistanceRequestInterval is how long participant waits before asking for the same
+// assistance again.
+const assistanceRequestInterval = time.Minute
+
+var (
+	// ErrAssistanceRequestedRecently is returned when participant asks for the same assistance
+	// again within assistanceRequestInterval.
+	ErrAssistanceRequestedRecently = errors.New("the same assistance was requested recently")
+	// errUnknownAssistanceStatus is returned when staff sets assistance request to a status it
+	// can't be moved to.
+	errUnknownAssistanceStatus = errors.New("assistance request can't be moved to status")
+)
+
+type assistanceService struct {
+	ordersRepo     repository.OrdersRepo
+	assistanceRepo repository.AssistanceRepo
+	events         events.Publisher
+	sessions       *sessions.TableSessions
+}
+
+// NewAssistanceService creates a new assistance service instance. Requests and changes of
+// their status are published to staff feed of order's restaurant, guests asking over http
+// are checked against their table sessions.
+//
+//revive:disable:unexported-return
+func NewAssistanceService(
+	ordersRepo repository.OrdersRepo,
+	assistanceRepo repository.AssistanceRepo,
+	publisher events.Publisher,
+	tableSessions *sessions.TableSessions,
+) *assistanceService {
+	return &assistanceService{
+		ordersRepo:     ordersRepo,
+		assistanceRepo: assistanceRepo,
+		events:         publisher,
+		sessions:       tableSessions,
+	}
+}
+
+//revive:enable:unexported-return
+
+// RequestAssistance checks that the request is made by a guest with table session of the
+// order or by waiter or manager of order's restaurant, and requests assistance on their
+// behalf. Guests who haven't joined the order yet ask without participant.
+func (s *assistanceService) RequestAssistance(
+	ctx context.Context,
+	reqDto *dto.CreateAssistanceRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.AssistanceRequestDto, error) {
+	requester, err := s.requester(ctx, reqDto.OrderID, reqDto.SessionToken, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	reqDto.RequestedBy = requester
+
+	return s.RequestParticipantAssistance(ctx, reqDto)
+}
+
+// RequestParticipantAssistance saves request of participant already connected to the order
+// and pushes it to staff of order's restaurant. Assistance can be requested until the order
+// is completed or cancelled, participant asking for the same assistance again has to wait
+// for assistanceRequestInterval.
+func (s *assistanceService) RequestParticipantAssistance(
+	ctx context.Context,
+	reqDto *dto.CreateAssistanceRequestDto,
+) (*dto.AssistanceRequestDto, error) {
+	table, err := s.ordersRepo.GetOrderTable(ctx, reqDto.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order table: %w", err)
+	}
+
+	request, err := s.assistanceRepo.CreateAssistanceRequest(
+		ctx,
+		reqDto,
+		time.Now().Add(-assistanceRequestInterval),
+	)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderIsFinalized) {
+			return nil, fmt.Errorf("%w: %w", ErrOrderFinalized, err)
+		}
+
+		if errors.Is(err, repository.ErrAssistanceRequestedRecently) {
+			return nil, fmt.Errorf("%w: %w", ErrAssistanceRequestedRecently, err)
+		}
+
+		return nil, fmt.Errorf("creating assistance request: %w", err)
+	}
+
+	s.publish(ctx, dto.MsgAssistanceRequested, table, request)
+
+	return request, nil
+}
+
+// GetUnresolvedAssistanceRequests returns requests of restaurant's orders that staff
+// hasn't resolved yet, oldest first.
+func (s *assistanceService) GetUnresolvedAssistanceRequests(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.AssistanceRequestDto, error) {
+	isStaff, err := isRestaurantStaff(ctx, s.ordersRepo, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaff {
+		return nil, ErrUserIsNotRestaurantStaff
+	}
+
+	requests, err := s.assistanceRepo.GetUnresolvedAssistanceRequests(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting unresolved assistance requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// UpdateAssistanceRequest acknowledges or resolves the request on behalf of waiter or
+// manager of order's restaurant, and tells the rest of the staff about it.
+func (s *assistanceService) UpdateAssistanceRequest(
+	ctx context.Context,
+	reqDto *dto.UpdateAssistanceRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.AssistanceRequestDto, error) {
+	table, err := s.ordersRepo.GetOrderTable(ctx, reqDto.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order table: %w", err)
+	}
+
+	isStaff, err := isRestaurantStaff(ctx, s.ordersRepo, claims.UserID, table.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaff {
+		return nil, ErrUserIsNotRestaurantStaff
+	}
+
+	current, err := s.assistanceRepo.GetAssistanceRequest(ctx, reqDto.OrderID, reqDto.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("getting assistance request: %w", err)
+	}
+
+	var (
+		updated *dto.AssistanceRequestDto
+		msgType dto.WSMessageType
+	)
+
+	switch reqDto.Status {
+	case db.OrdersAssistanceRequestStatusAcknowledged:
+		msgType = dto.MsgAssistanceAcknowledged
+		updated, err = s.assistanceRepo.AcknowledgeAssistanceRequest(
+			ctx,
+			reqDto.OrderID,
+			reqDto.RequestID,
+			claims.UserID,
+		)
+	case db.OrdersAssistanceRequestStatusResolved:
+		msgType = dto.MsgAssistanceResolved
+		updated, err = s.assistanceRepo.ResolveAssistanceRequest(
+			ctx,
+			reqDto.OrderID,
+			reqDto.RequestID,
+			claims.UserID,
+		)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownAssistanceStatus, reqDto.Status)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("updating assistance request: %w", err)
+	}
+
+	updated.RequestedBy = current.RequestedBy
+
+	s.publish(ctx, msgType, table, updated)
+
+	return updated, nil
+}
+
+// GetAssistanceReport reports how quickly staff acknowledged and resolved requests created
+// in the requested period, overall, by type of request and by waiter who acknowledged them.
+func (s *assistanceService) GetAssistanceReport(
+	ctx context.Context,
+	reqDto *dto.AssistanceReportRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.AssistanceReportDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	requests, err := s.assistanceRepo.GetAssistanceResponseTimes(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("getting assistance response times: %w", err)
+	}
+
+	report := &dto.AssistanceReportDto{
+		RestaurantID: reqDto.RestaurantID,
+		From:         reqDto.From,
+		To:           reqDto.To,
+		Overall:      noResponseTimes(),
+		Types:        make([]*dto.AssistanceTypeTimesDto, 0),
+		Waiters:      make([]*dto.WaiterAssistanceTimesDto, 0),
+	}
+
+	reporter := &assistanceReporter{
+		report:  report,
+		overall: newResponseTimes(&report.Overall),
+		types:   make(map[db.OrdersAssistanceRequestType]*responseTimes),
+		waiters: make(map[uuid.UUID]*responseTimes),
+	}
+	reporter.add(requests)
+
+	return report, nil
+}
+
+// requester returns participant of guest with table session of the order or of its
+// restaurant's staff member. Guests whose session doesn't tell guests apart, or who haven't
+// joined the order yet, get no participant.
+func (s *assistanceService) requester(
+	ctx context.Context,
+	orderID uuid.UUID,
+	sessionToken string,
+	claims *authDto.TokenClaimsDto,
+) (*dto.ParticipantDto, error) {
+	session, err := authorizeOrderAccess(
+		ctx,
+		s.sessions,
+		s.ordersRepo,
+		orderID,
+		sessionToken,
+		claims,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if session == nil {
+		participant, err := s.ordersRepo.SaveStaffParticipant(ctx, orderID, claims.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("saving staff participant: %w", err)
+		}
+
+		return participant, nil
+	}
+
+	if session.ParticipantID == uuid.Nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	participant, err := s.ordersRepo.GetOrderParticipant(ctx, orderID, session.ParticipantID)
+	if err != nil {
+		if errors.Is(err, repository.ErrParticipantDoesNotExist) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, fmt.Errorf("getting participant: %w", err)
+	}
+
+	return participant, nil
+}
+
+func (s *assistanceService) publish(
+	ctx context.Context,
+	msgType dto.WSMessageType,
+	table *dto.OrderTableDto,
+	request *dto.AssistanceRequestDto,
+) {
+	staffEvent := newStaffEvent(table, request.OrderID)
+	staffEvent.Assistance = request
+
+	s.events.Publish(ctx, events.NewStaffEvent(msgType, staffEvent))
+}
+
+// responseTimes adds up times staff took to respond to requests, until averages are taken.
+type responseTimes struct {
+	times         *dto.AssistanceResponseTimesDto
+	toAcknowledge time.Duration
+	toResolve     time.Duration
+}
+
+func newResponseTimes(times *dto.AssistanceResponseTimesDto) *responseTimes {
+	return &responseTimes{
+		times:         times,
+		toAcknowledge: 0,
+		toResolve:     0,
+	}
+}
+
+// noResponseTimes returns response times of no requests.
+func noResponseTimes() dto.AssistanceResponseTimesDto {
+	return dto.AssistanceResponseTimesDto{
+		RequestsCount:           0,
+		AcknowledgedCount:       0,
+		ResolvedCount:           0,
+		AvgSecondsToAcknowledge: 0,
+		AvgSecondsToResolve:     0,
+	}
+}
+
+func (t *responseTimes) add(request *dto.AssistanceResponseTimeDto) {
+	t.times.RequestsCount++
+
+	if request.AcknowledgedAt != nil {
+		t.times.AcknowledgedCount++
+		t.toAcknowledge += request.AcknowledgedAt.Sub(request.CreatedAt)
+	}
+
+	if request.ResolvedAt != nil {
+		t.times.ResolvedCount++
+		t.toResolve += request.ResolvedAt.Sub(request.CreatedAt)
+	}
+}
+
+// average sets averages of the times added up, requests not yet handled don't count.
+func (t *responseTimes) average() {
+	if t.times.AcknowledgedCount > 0 {
+		t.times.AvgSecondsToAcknowledge = t.toAcknowledge.Seconds() /
+			float64(t.times.AcknowledgedCount)
+	}
+
+	if t.times.ResolvedCount > 0 {
+		t.times.AvgSecondsToResolve = t.toResolve.Seconds() / float64(t.times.ResolvedCount)
+	}
+}
+
+// assistanceReporter adds up response times of requests into the report.
+type assistanceReporter struct {
+	report  *dto.AssistanceReportDto
+	overall *responseTimes
+	types   map[db.OrdersAssistanceRequestType]*responseTimes
+	waiters map[uuid.UUID]*responseTimes
+}
+
+func (r *assistanceReporter) add(requests []*dto.AssistanceResponseTimeDto) {
+	for _, request := range requests {
+		r.overall.add(request)
+		r.typeTimes(request.Type).add(request)
+
+		if request.AcknowledgedBy != nil {
+			r.waiterTimes(*request.AcknowledgedBy).add(request)
+		}
+	}
+
+	r.overall.average()
+
+	for _, times := range r.types {
+		times.average()
+	}
+
+	for _, times := range r.waiters {
+		times.average()
+	}
+
+	slices.SortFunc(r.report.Types, func(a, b *dto.AssistanceTypeTimesDto) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+
+	slices.SortFunc(r.report.Waiters, func(a, b *dto.WaiterAssistanceTimesDto) int {
+		return cmp.Compare(a.UserID.String(), b.UserID.String())
+	})
+}
+
+func (r *assistanceReporter) typeTimes(requestType db.OrdersAssistanceRequestType) *responseTimes {
+	times, ok := r.types[requestType]
+	if !ok {
+		typeTimes := &dto.AssistanceTypeTimesDto{
+			Type:                       requestType,
+			AssistanceResponseTimesDto: noResponseTimes(),
+		}
+		r.report.Types = append(r.report.Types, typeTimes)
+
+		times = newResponseTimes(&typeTimes.AssistanceResponseTimesDto)
+		r.types[requestType] = times
+	}
+
+	return times
+}
+
+func (r *assistanceReporter) waiterTimes(userID uuid.UUID) *responseTimes {
+	times, ok := r.waiters[userID]
+	if !ok {
+		waiterTimes := &dto.WaiterAssistanceTimesDto{
+			UserID:                     userID,
+			AssistanceResponseTimesDto: noResponseTimes(),
+		}
+		r.report.Waiters = append(r.report.Waiters, waiterTimes)
+
+		times = newResponseTimes(&waiterTimes.AssistanceResponseTimesDto)
+		r.waiters[userID] = times
+	}
+
+	return times
+}
@@ -0,0 +1,570 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type assistanceServiceTestSuite struct {
+	suite.Suite
+}
+
+func TestAssistanceServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(assistanceServiceTestSuite))
+}
+
+func (suite *assistanceServiceTestSuite) newService(
+	publisher events.Publisher,
+) *assistanceService {
+	return NewAssistanceService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockAssistanceRepo(),
+		publisher,
+		mock.NewTableSessions(),
+	)
+}
+
+func (suite *assistanceServiceTestSuite) callWaiter(
+	svc *assistanceService,
+) *dto.AssistanceRequestDto {
+	request, err := svc.RequestParticipantAssistance(
+		context.Background(),
+		&dto.CreateAssistanceRequestDto{
+			OrderID:      testOrderID,
+			Type:         db.OrdersAssistanceRequestTypeCallWaiter,
+			Message:      nil,
+			RequestedBy:  nil,
+			SessionToken: "",
+		},
+	)
+	suite.Require().NoError(err)
+
+	return request
+}
+
+func (suite *assistanceServiceTestSuite) TestRequestParticipantAssistance() {
+	pubsub := events.NewMemoryPubSub(10)
+	svc := suite.newService(pubsub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	message := "more napkins please"
+	participant := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Guest",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+
+	request, err := svc.RequestParticipantAssistance(
+		context.Background(),
+		&dto.CreateAssistanceRequestDto{
+			OrderID:      testOrderID,
+			Type:         db.OrdersAssistanceRequestTypeCustom,
+			Message:      &message,
+			RequestedBy:  participant,
+			SessionToken: "",
+		},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(testOrderID, request.OrderID)
+	suite.Equal(db.OrdersAssistanceRequestStatusOpen, request.Status)
+	suite.Equal(&message, request.Message)
+	suite.Equal(participant, request.RequestedBy)
+
+	event := <-published
+	suite.Equal(dto.MsgAssistanceRequested, event.Type)
+	suite.True(event.IsStaff())
+	suite.Equal(testRestaurantID, event.Staff.RestaurantID)
+	suite.Equal(request, event.Staff.Assistance)
+	suite.NotNil(event.Staff.Table)
+	suite.Empty(published)
+}
+
+func (suite *assistanceServiceTestSuite) TestRequestParticipantAssistance_Fail() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	reqDto := &dto.CreateAssistanceRequestDto{
+		OrderID:      uuid.New(),
+		Type:         db.OrdersAssistanceRequestTypeRequestBill,
+		Message:      nil,
+		RequestedBy:  nil,
+		SessionToken: "",
+	}
+
+	_, err := svc.RequestParticipantAssistance(context.Background(), reqDto)
+	suite.Require().ErrorIs(err, repository.ErrOrderDoesNotExist)
+
+	reqDto.OrderID = testOrderID
+
+	_, err = svc.RequestParticipantAssistance(
+		context.WithValue(context.Background(), mock.CtxFinalizedOrder, true),
+		reqDto,
+	)
+	suite.Require().ErrorIs(err, ErrOrderFinalized)
+
+	_, err = svc.RequestParticipantAssistance(
+		context.WithValue(context.Background(), mock.CtxFailCreateAssistanceRequest, true),
+		reqDto,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+}
+
+func (suite *assistanceServiceTestSuite) TestRequestParticipantAssistance_RequestedRecently() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	guest := func() *dto.ParticipantDto {
+		return &dto.ParticipantDto{
+			ID:          uuid.New(),
+			DisplayName: "Guest",
+			Role:        db.OrdersParticipantRoleGuest,
+		}
+	}
+	request := func(
+		requestType db.OrdersAssistanceRequestType,
+		participant *dto.ParticipantDto,
+	) error {
+		_, err := svc.RequestParticipantAssistance(
+			context.Background(),
+			&dto.CreateAssistanceRequestDto{
+				OrderID:      testOrderID,
+				Type:         requestType,
+				Message:      nil,
+				RequestedBy:  participant,
+				SessionToken: "",
+			},
+		)
+
+		return err
+	}
+
+	first, second := guest(), guest()
+
+	suite.Require().NoError(request(db.OrdersAssistanceRequestTypeCallWaiter, first))
+	suite.Require().ErrorIs(
+		request(db.OrdersAssistanceRequestTypeCallWaiter, first),
+		ErrAssistanceRequestedRecently,
+	)
+	suite.Require().NoError(request(db.OrdersAssistanceRequestTypeRequestBill, first))
+	suite.Require().NoError(request(db.OrdersAssistanceRequestTypeCallWaiter, second))
+
+	// requests without participant count as the same participant's
+	suite.Require().NoError(request(db.OrdersAssistanceRequestTypeCallWaiter, nil))
+	suite.Require().ErrorIs(
+		request(db.OrdersAssistanceRequestTypeCallWaiter, nil),
+		ErrAssistanceRequestedRecently,
+	)
+}
+
+func (suite *assistanceServiceTestSuite) TestRequestParticipantAssistance_ConcurrentRequests() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	participant := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Guest",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		created   int
+		throttled int
+	)
+
+	// guest taps the button several times at once
+	for range 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := svc.RequestParticipantAssistance(
+				context.Background(),
+				&dto.CreateAssistanceRequestDto{
+					OrderID:      testOrderID,
+					Type:         db.OrdersAssistanceRequestTypeCallWaiter,
+					Message:      nil,
+					RequestedBy:  participant,
+					SessionToken: "",
+				},
+			)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err == nil {
+				created++
+			} else if suite.ErrorIs(err, ErrAssistanceRequestedRecently) {
+				throttled++
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	suite.Equal(1, created)
+	suite.Equal(4, throttled)
+}
+
+func (suite *assistanceServiceTestSuite) TestRequestAssistance() {
+	tableSessions := mock.NewTableSessions()
+	session, err := tableSessions.Issue(testOrderID, testTableID)
+	suite.Require().NoError(err)
+
+	otherSession, err := tableSessions.Issue(uuid.New(), testTableID)
+	suite.Require().NoError(err)
+
+	notJoined := context.WithValue(context.Background(), mock.CtxParticipantNotJoined, true)
+
+	tests := []struct {
+		desc          string
+		ctx           context.Context
+		requestType   db.OrdersAssistanceRequestType
+		sessionToken  string
+		claims        *authDto.TokenClaimsDto
+		wantRequester *uuid.UUID
+		wantErr       error
+	}{
+		{
+			desc:          "guest with table session",
+			ctx:           context.Background(),
+			requestType:   db.OrdersAssistanceRequestTypeCallWaiter,
+			sessionToken:  session.Token,
+			claims:        nil,
+			wantRequester: &session.ParticipantID,
+			wantErr:       nil,
+		},
+		{
+			desc:          "guest who hasn't joined the order",
+			ctx:           notJoined,
+			requestType:   db.OrdersAssistanceRequestTypeRequestBill,
+			sessionToken:  session.Token,
+			claims:        nil,
+			wantRequester: nil,
+			wantErr:       nil,
+		},
+		{
+			desc:          "waiter of the restaurant",
+			ctx:           context.Background(),
+			requestType:   db.OrdersAssistanceRequestTypeCallWaiter,
+			sessionToken:  "",
+			claims:        &authDto.TokenClaimsDto{UserID: testFirstWaiterID},
+			wantRequester: &testFirstWaiterID,
+			wantErr:       nil,
+		},
+		{
+			desc:          "neither session nor token",
+			ctx:           context.Background(),
+			requestType:   db.OrdersAssistanceRequestTypeCallWaiter,
+			sessionToken:  "",
+			claims:        nil,
+			wantRequester: nil,
+			wantErr:       ErrOrderConnectionUnauthorized,
+		},
+		{
+			desc:          "session of another order",
+			ctx:           context.Background(),
+			requestType:   db.OrdersAssistanceRequestTypeCallWaiter,
+			sessionToken:  otherSession.Token,
+			claims:        nil,
+			wantRequester: nil,
+			wantErr:       ErrTableSessionNotForOrder,
+		},
+		{
+			desc:          "user from another restaurant",
+			ctx:           context.Background(),
+			requestType:   db.OrdersAssistanceRequestTypeCallWaiter,
+			sessionToken:  "",
+			claims:        &authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+			wantRequester: nil,
+			wantErr:       ErrUserIsNotRestaurantStaff,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			svc := suite.newService(events.NewMemoryPubSub(10))
+
+			request, err := svc.RequestAssistance(tt.ctx, &dto.CreateAssistanceRequestDto{
+				OrderID:      testOrderID,
+				Type:         tt.requestType,
+				Message:      nil,
+				RequestedBy:  nil,
+				SessionToken: tt.sessionToken,
+			}, tt.claims)
+			if tt.wantErr != nil {
+				suite.Require().ErrorIs(err, tt.wantErr)
+
+				return
+			}
+
+			suite.Require().NoError(err)
+
+			if tt.wantRequester == nil {
+				suite.Nil(request.RequestedBy)
+
+				return
+			}
+
+			suite.Require().NotNil(request.RequestedBy)
+			suite.Equal(*tt.wantRequester, request.RequestedBy.ID)
+		})
+	}
+}
+
+func (suite *assistanceServiceTestSuite) TestUpdateAssistanceRequest() {
+	pubsub := events.NewMemoryPubSub(10)
+	svc := suite.newService(pubsub)
+	request := suite.callWaiter(svc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	claims := &authDto.TokenClaimsDto{UserID: testFirstWaiterID}
+	reqDto := &dto.UpdateAssistanceRequestDto{
+		OrderID:   testOrderID,
+		RequestID: request.ID,
+		Status:    db.OrdersAssistanceRequestStatusAcknowledged,
+	}
+
+	acknowledged, err := svc.UpdateAssistanceRequest(context.Background(), reqDto, claims)
+	suite.Require().NoError(err)
+	suite.Equal(db.OrdersAssistanceRequestStatusAcknowledged, acknowledged.Status)
+	suite.Equal(&testFirstWaiterID, acknowledged.AcknowledgedBy)
+	suite.NotNil(acknowledged.AcknowledgedAt)
+	suite.Nil(acknowledged.ResolvedAt)
+	suite.Equal(dto.MsgAssistanceAcknowledged, (<-published).Type)
+
+	_, err = svc.UpdateAssistanceRequest(context.Background(), reqDto, claims)
+	suite.Require().ErrorIs(err, repository.ErrAssistanceRequestAlreadyHandled)
+
+	reqDto.Status = db.OrdersAssistanceRequestStatusResolved
+
+	resolved, err := svc.UpdateAssistanceRequest(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testSecondWaiterID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(db.OrdersAssistanceRequestStatusResolved, resolved.Status)
+	suite.Equal(&testFirstWaiterID, resolved.AcknowledgedBy)
+	suite.Equal(&testSecondWaiterID, resolved.ResolvedBy)
+
+	event := <-published
+	suite.Equal(dto.MsgAssistanceResolved, event.Type)
+	suite.Equal(resolved, event.Staff.Assistance)
+
+	_, err = svc.UpdateAssistanceRequest(context.Background(), reqDto, claims)
+	suite.Require().ErrorIs(err, repository.ErrAssistanceRequestAlreadyHandled)
+
+	unresolved, err := svc.GetUnresolvedAssistanceRequests(
+		context.Background(),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().NoError(err)
+	suite.Empty(unresolved)
+	suite.Empty(published)
+}
+
+func (suite *assistanceServiceTestSuite) TestUpdateAssistanceRequest_ResolveOpenRequest() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	request := suite.callWaiter(svc)
+
+	resolved, err := svc.UpdateAssistanceRequest(
+		context.Background(),
+		&dto.UpdateAssistanceRequestDto{
+			OrderID:   testOrderID,
+			RequestID: request.ID,
+			Status:    db.OrdersAssistanceRequestStatusResolved,
+		},
+		&authDto.TokenClaimsDto{UserID: testFirstWaiterID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(&testFirstWaiterID, resolved.AcknowledgedBy)
+	suite.Equal(resolved.ResolvedAt, resolved.AcknowledgedAt)
+}
+
+func (suite *assistanceServiceTestSuite) TestUpdateAssistanceRequest_Fail() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	request := suite.callWaiter(svc)
+	reqDto := &dto.UpdateAssistanceRequestDto{
+		OrderID:   testOrderID,
+		RequestID: request.ID,
+		Status:    db.OrdersAssistanceRequestStatusAcknowledged,
+	}
+
+	_, err := svc.UpdateAssistanceRequest(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotRestaurantStaff)
+
+	claims := &authDto.TokenClaimsDto{UserID: testFirstWaiterID}
+	reqDto.RequestID = uuid.New()
+
+	_, err = svc.UpdateAssistanceRequest(context.Background(), reqDto, claims)
+	suite.Require().ErrorIs(err, repository.ErrAssistanceRequestDoesNotExist)
+
+	reqDto.OrderID = uuid.New()
+
+	_, err = svc.UpdateAssistanceRequest(context.Background(), reqDto, claims)
+	suite.Require().ErrorIs(err, repository.ErrOrderDoesNotExist)
+}
+
+func (suite *assistanceServiceTestSuite) TestGetUnresolvedAssistanceRequests() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	request := suite.callWaiter(svc)
+	claims := &authDto.TokenClaimsDto{UserID: testFirstWaiterID}
+
+	got, err := svc.GetUnresolvedAssistanceRequests(
+		context.Background(),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().NoError(err)
+	suite.Equal([]*dto.AssistanceRequestDto{request}, got)
+
+	_, err = svc.GetUnresolvedAssistanceRequests(
+		context.WithValue(context.Background(), mock.CtxFailGetUnresolvedAssistanceRequests, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+
+	_, err = svc.GetUnresolvedAssistanceRequests(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotRestaurantStaff)
+
+	_, err = svc.GetUnresolvedAssistanceRequests(
+		context.WithValue(context.Background(), mock.CtxFailIsUserRestaurantMember, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Require().NotErrorIs(err, ErrUserIsNotRestaurantStaff)
+}
+
+func (suite *assistanceServiceTestSuite) TestGetAssistanceReport() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	from := time.Date(2025, 12, 5, 0, 0, 0, 0, time.UTC)
+	reqDto := &dto.AssistanceReportRequestDto{
+		RestaurantID: testRestaurantID,
+		From:         from,
+		To:           from.Add(24 * time.Hour),
+	}
+
+	got, err := svc.GetAssistanceReport(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(testRestaurantID, got.RestaurantID)
+	suite.Equal(reqDto.From, got.From)
+	suite.Equal(reqDto.To, got.To)
+	suite.Equal(dto.AssistanceResponseTimesDto{
+		RequestsCount:           4,
+		AcknowledgedCount:       3,
+		ResolvedCount:           2,
+		AvgSecondsToAcknowledge: 60,
+		AvgSecondsToResolve:     195,
+	}, got.Overall)
+	suite.Equal([]*dto.AssistanceTypeTimesDto{
+		{
+			Type: db.OrdersAssistanceRequestTypeCallWaiter,
+			AssistanceResponseTimesDto: dto.AssistanceResponseTimesDto{
+				RequestsCount:           2,
+				AcknowledgedCount:       2,
+				ResolvedCount:           1,
+				AvgSecondsToAcknowledge: 60,
+				AvgSecondsToResolve:     90,
+			},
+		},
+		{
+			Type: db.OrdersAssistanceRequestTypeCustom,
+			AssistanceResponseTimesDto: dto.AssistanceResponseTimesDto{
+				RequestsCount:           1,
+				AcknowledgedCount:       0,
+				ResolvedCount:           0,
+				AvgSecondsToAcknowledge: 0,
+				AvgSecondsToResolve:     0,
+			},
+		},
+		{
+			Type: db.OrdersAssistanceRequestTypeRequestBill,
+			AssistanceResponseTimesDto: dto.AssistanceResponseTimesDto{
+				RequestsCount:           1,
+				AcknowledgedCount:       1,
+				ResolvedCount:           1,
+				AvgSecondsToAcknowledge: 60,
+				AvgSecondsToResolve:     300,
+			},
+		},
+	}, got.Types)
+	suite.Equal([]*dto.WaiterAssistanceTimesDto{
+		{
+			UserID: testFirstWaiterID,
+			AssistanceResponseTimesDto: dto.AssistanceResponseTimesDto{
+				RequestsCount:           2,
+				AcknowledgedCount:       2,
+				ResolvedCount:           2,
+				AvgSecondsToAcknowledge: 45,
+				AvgSecondsToResolve:     195,
+			},
+		},
+		{
+			UserID: testSecondWaiterID,
+			AssistanceResponseTimesDto: dto.AssistanceResponseTimesDto{
+				RequestsCount:           1,
+				AcknowledgedCount:       1,
+				ResolvedCount:           0,
+				AvgSecondsToAcknowledge: 90,
+				AvgSecondsToResolve:     0,
+			},
+		},
+	}, got.Waiters)
+}
+
+func (suite *assistanceServiceTestSuite) TestGetAssistanceReport_Fail() {
+	svc := suite.newService(events.NewMemoryPubSub(10))
+	reqDto := &dto.AssistanceReportRequestDto{
+		RestaurantID: testRestaurantID,
+		From:         time.Now().Add(-time.Hour),
+		To:           time.Now(),
+	}
+
+	_, err := svc.GetAssistanceReport(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+
+	_, err = svc.GetAssistanceReport(
+		context.WithValue(context.Background(), mock.CtxFailGetAssistanceResponseTimes, true),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+}
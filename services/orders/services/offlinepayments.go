@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUserIsNotWaiter is returned when a user attempts an action that requires restaurant waiter privileges.
+	ErrUserIsNotWaiter = errors.New("user is not a waiter of this restaurant")
+	// ErrPaymentExceedsBalanceDue is returned when offline payment is bigger than order's outstanding balance.
+	ErrPaymentExceedsBalanceDue = errors.New("payment amount exceeds order balance due")
+	// ErrChangeOnTerminalPayment is returned when card terminal payment is tendered with more than its amount.
+	ErrChangeOnTerminalPayment = errors.New("card terminal payments can't have change due")
+)
+
+// RecordOfflinePayment records payment waiter took in cash or on restaurant's own card terminal.
+// It's saved to the same payments ledger as provider payments and settles the order the same way.
+func (s *paymentsService) RecordOfflinePayment(
+	ctx context.Context,
+	reqDto *dto.OfflinePaymentRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.PaymentDto, error) {
+	order, err := s.ordersRepo.GetOrderItems(ctx, reqDto.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	err = s.ordersRepo.IsUserRestaurantWaiter(ctx, claims.UserID, order.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotWaiter, err)
+	}
+
+	canPay, err := s.canPayForOrder(order)
+	if !canPay || err != nil {
+		return nil, err
+	}
+
+	if reqDto.AmountInCents > balanceDue(order) {
+		return nil, ErrPaymentExceedsBalanceDue
+	}
+
+	tendered := reqDto.AmountInCents
+	if reqDto.TenderedInCents != nil {
+		tendered = *reqDto.TenderedInCents
+	}
+
+	if reqDto.Method == db.OrdersPaymentProviderTerminal && tendered != reqDto.AmountInCents {
+		return nil, ErrChangeOnTerminalPayment
+	}
+
+	table, err := s.ordersRepo.GetOrderTable(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order table: %w", err)
+	}
+
+	changeDue := tendered - reqDto.AmountInCents
+	// offline payments have no provider's id, so a random one keeps them unique in the ledger
+	paymentID := uuid.New()
+
+	var payment *dto.PaymentDto
+
+	err = s.paymentsRepo.RunInTx(
+		ctx,
+		func(ordersRepo repository.OrdersRepo, paymentsRepo repository.PaymentsRepo) error {
+			err = ordersRepo.LockOrder(ctx, order.ID)
+			if err != nil {
+				return fmt.Errorf("locking order: %w", err)
+			}
+
+			payment, err = paymentsRepo.SavePayment(ctx, &dto.PaymentDto{
+				ID:                    paymentID,
+				OrderID:               order.ID,
+				AmountInCents:         reqDto.AmountInCents,
+				Provider:              reqDto.Method,
+				ProviderPaymentID:     paymentID.String(),
+				Currency:              order.Currency,
+				RefundedAmountInCents: 0,
+				Status:                db.OrdersPaymentStatusSucceeded,
+				TenderedInCents:       &tendered,
+				ChangeDueInCents:      &changeDue,
+				RecordedBy:            &claims.UserID,
+			})
+			if err != nil {
+				return fmt.Errorf("creating payment: %w", err)
+			}
+
+			err = settleOrder(ctx, ordersRepo, paymentsRepo, order.ID)
+			if err != nil {
+				return fmt.Errorf("settling order: %w", err)
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, events.NewOrderChangedEvent(order.ID))
+
+	succeeded := newStaffEvent(table, order.ID)
+	succeeded.Payment = payment
+
+	s.events.Publish(ctx, events.NewStaffEvent(dto.MsgPaymentSucceeded, succeeded))
+
+	return payment, nil
+}
+
+// isOfflinePayment reports whether payment was taken by a waiter, those are refunded by hand.
+func isOfflinePayment(payment *dto.PaymentDto) bool {
+	return payment.Provider == db.OrdersPaymentProviderCash ||
+		payment.Provider == db.OrdersPaymentProviderTerminal
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (suite *paymentsServiceTestSuite) TestRecordOfflinePayment_Success() {
+	tendered := 50
+
+	got, err := suite.svc.RecordOfflinePayment(
+		context.Background(),
+		&dto.OfflinePaymentRequestDto{
+			OrderID:         testOrderID,
+			Method:          db.OrdersPaymentProviderCash,
+			AmountInCents:   testAmount * 2,
+			TenderedInCents: &tendered,
+		},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+
+	// offline payment keeps the id its ledger reference was made from
+	suite.NotEqual(uuid.Nil, got.ID)
+	suite.NotEqual(testPaymentID, got.ID)
+
+	suite.Require().NotNil(got.TenderedInCents)
+	suite.Equal(tendered, *got.TenderedInCents)
+	suite.Require().NotNil(got.ChangeDueInCents)
+	suite.Equal(tendered-testAmount*2, *got.ChangeDueInCents)
+	suite.Require().NotNil(got.RecordedBy)
+	suite.Equal(testUserID, *got.RecordedBy)
+}
+
+func (suite *paymentsServiceTestSuite) TestRecordOfflinePayment_Error() {
+	tendered := 50
+
+	tests := []struct {
+		name       string
+		ctxFailKey mock.CtxKey
+		orderID    uuid.UUID
+		userID     uuid.UUID
+		method     db.OrdersPaymentProvider
+		amount     int
+		tendered   *int
+		wantErr    error
+	}{
+		{
+			"invalid order id",
+			"none",
+			uuid.Max,
+			testUserID,
+			db.OrdersPaymentProviderCash,
+			testAmount,
+			nil,
+			mock.ErrRepoFailed,
+		},
+		{
+			"user is not waiter",
+			"none",
+			testOrderID,
+			testUserFromAnotherRestaurantID,
+			db.OrdersPaymentProviderCash,
+			testAmount,
+			nil,
+			ErrUserIsNotWaiter,
+		},
+		{
+			"order already completed",
+			"none",
+			testCompletedOrderID,
+			testUserID,
+			db.OrdersPaymentProviderCash,
+			testAmount,
+			nil,
+			ErrOrderFinalized,
+		},
+		{
+			"amount exceeds balance due",
+			"none",
+			testOrderID,
+			testUserID,
+			db.OrdersPaymentProviderTerminal,
+			testAmount * 3,
+			nil,
+			ErrPaymentExceedsBalanceDue,
+		},
+		{
+			"change on terminal payment",
+			"none",
+			testOrderID,
+			testUserID,
+			db.OrdersPaymentProviderTerminal,
+			testAmount,
+			&tendered,
+			ErrChangeOnTerminalPayment,
+		},
+		{
+			"payment not saved",
+			mock.CtxFailSavePayment,
+			testOrderID,
+			testUserID,
+			db.OrdersPaymentProviderCash,
+			testAmount,
+			nil,
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.svc.RecordOfflinePayment(
+				ctx,
+				&dto.OfflinePaymentRequestDto{
+					OrderID:         tt.orderID,
+					Method:          tt.method,
+					AmountInCents:   tt.amount,
+					TenderedInCents: tt.tendered,
+				},
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+			suite.Nil(got)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestRecordOfflinePayment_ConcurrentSettlements() {
+	ledger := newLedgerRepo()
+	svc := NewPaymentsService(
+		ledger.orders,
+		ledger,
+		mock.NewMockProvidersRegistry(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
+
+	var wg sync.WaitGroup
+
+	// two waiters take half of the bill each at the same time
+	for range 2 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := svc.RecordOfflinePayment(
+				context.Background(),
+				&dto.OfflinePaymentRequestDto{
+					OrderID:         testOrderID,
+					Method:          db.OrdersPaymentProviderCash,
+					AmountInCents:   testAmount,
+					TenderedInCents: nil,
+				},
+				&authDto.TokenClaimsDto{UserID: testUserID},
+			)
+			suite.NoError(err)
+		}()
+	}
+
+	wg.Wait()
+
+	suite.Equal(1, ledger.completed)
+	suite.Equal([]db.OrdersPaymentReview{db.OrdersPaymentReviewUnderpaid}, ledger.reviews)
+}
+
+// ledgerOverlap is how long a transaction waits for another one to commit together with it.
+const ledgerOverlap = 50 * time.Millisecond
+
+// ledgerRepo keeps payments of the mock order like the database does in read committed
+// transactions: payments saved in a transaction are seen by others once it commits, and
+// the locked order stays locked until the transaction ends.
+type ledgerRepo struct {
+	repository.PaymentsRepo
+
+	orders     repository.OrdersRepo
+	orderLock  sync.Mutex
+	committing chan struct{}
+	mu         sync.Mutex
+	paid       int
+	completed  int
+	reviews    []db.OrdersPaymentReview
+}
+
+func newLedgerRepo() *ledgerRepo {
+	return &ledgerRepo{
+		PaymentsRepo: mock.NewMockPaymentsRepo(),
+		orders:       mock.NewMockOrdersRepo(),
+		orderLock:    sync.Mutex{},
+		committing:   make(chan struct{}),
+		mu:           sync.Mutex{},
+		paid:         0,
+		completed:    0,
+		reviews:      nil,
+	}
+}
+
+type ledgerTx struct {
+	ledger  *ledgerRepo
+	pending int
+	locked  bool
+}
+
+func (r *ledgerRepo) RunInTx(_ context.Context, fn repository.TxFunc) error {
+	tx := &ledgerTx{ledger: r, pending: 0, locked: false}
+
+	err := fn(&ledgerOrdersRepo{OrdersRepo: r.orders, tx: tx}, &ledgerPaymentsRepo{
+		PaymentsRepo: r.PaymentsRepo,
+		tx:           tx,
+	})
+
+	// transactions that aren't waiting for the order lock commit together
+	select {
+	case r.committing <- struct{}{}:
+	case <-r.committing:
+	case <-time.After(ledgerOverlap):
+	}
+
+	if err == nil {
+		r.mu.Lock()
+		r.paid += tx.pending
+		r.mu.Unlock()
+	}
+
+	if tx.locked {
+		r.orderLock.Unlock()
+	}
+
+	return err
+}
+
+type ledgerOrdersRepo struct {
+	repository.OrdersRepo
+
+	tx *ledgerTx
+}
+
+func (r *ledgerOrdersRepo) LockOrder(_ context.Context, _ uuid.UUID) error {
+	r.tx.ledger.orderLock.Lock()
+	r.tx.locked = true
+
+	return nil
+}
+
+func (r *ledgerOrdersRepo) UpdateOrder(
+	ctx context.Context,
+	reqDto *dto.UpdateOrderReqDto,
+) (*dto.OrderDto, error) {
+	if reqDto.Status != nil && *reqDto.Status == db.OrderStatusCompleted {
+		r.tx.ledger.mu.Lock()
+		r.tx.ledger.completed++
+		r.tx.ledger.mu.Unlock()
+	}
+
+	return r.OrdersRepo.UpdateOrder(ctx, reqDto)
+}
+
+func (r *ledgerOrdersRepo) SetOrderPaymentReview(
+	_ context.Context,
+	_ uuid.UUID,
+	review *db.OrdersPaymentReview,
+) error {
+	if review != nil {
+		r.tx.ledger.mu.Lock()
+		r.tx.ledger.reviews = append(r.tx.ledger.reviews, *review)
+		r.tx.ledger.mu.Unlock()
+	}
+
+	return nil
+}
+
+type ledgerPaymentsRepo struct {
+	repository.PaymentsRepo
+
+	tx *ledgerTx
+}
+
+func (r *ledgerPaymentsRepo) SavePayment(
+	ctx context.Context,
+	reqDto *dto.PaymentDto,
+) (*dto.PaymentDto, error) {
+	payment, err := r.PaymentsRepo.SavePayment(ctx, reqDto)
+	if err != nil {
+		return nil, err
+	}
+
+	r.tx.pending += reqDto.AmountInCents
+
+	return payment, nil
+}
+
+func (r *ledgerPaymentsRepo) GetOrderAmountPaid(_ context.Context, _ uuid.UUID) (int, error) {
+	r.tx.ledger.mu.Lock()
+	defer r.tx.ledger.mu.Unlock()
+
+	return r.tx.ledger.paid + r.tx.pending, nil
+}
@@ -8,7 +8,13 @@ import (
 	authDto "golang-dining-ordering/services/auth/dto"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/promotions"
 	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/sessions"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -20,7 +26,11 @@ type OrdersService interface {
 		tableID uuid.UUID,
 	) (*dto.CurrentOrderDto, error)
 	GetOrder(ctx context.Context, orderID uuid.UUID) (*dto.OrderDto, error)
-	AddItemToOrder(ctx context.Context, orderID, itemID uuid.UUID) (*dto.OrderDto, error)
+	AddItemToOrder(
+		ctx context.Context,
+		orderID, itemID uuid.UUID,
+		addedBy *dto.ParticipantDto,
+	) (*dto.OrderDto, error)
 	DeleteOrderItem(ctx context.Context, orderItemID, orderID uuid.UUID) (*dto.OrderDto, error)
 	UpdateOrder(
 		ctx context.Context,
@@ -29,6 +39,14 @@ type OrdersService interface {
 	) (*dto.OrderDto, error)
 	AssignWaiter(ctx context.Context, orderID, userID uuid.UUID) error
 	RemoveWaiter(ctx context.Context, orderID, userID, assignID uuid.UUID) error
+	ApplyPromoCode(ctx context.Context, reqDto *dto.ApplyPromoCodeRequestDto) (*dto.OrderDto, error)
+	RemovePromoCode(ctx context.Context, orderID uuid.UUID) (*dto.OrderDto, error)
+	AuthorizeOrderConnection(
+		ctx context.Context,
+		orderID uuid.UUID,
+		sessionToken, displayName string,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.ParticipantDto, error)
 }
 
 var (
@@ -44,18 +62,52 @@ var (
 	ErrUserCannotEditLockedOrder = errors.New("this user cannot edit locked orders")
 	// ErrUserCannotEditStatus is returned when the current user is not allowed to edit status of the order.
 	ErrUserCannotEditStatus = errors.New("user cannot edit status of this order")
+	// ErrPromoCodeNotActive is returned when promo code is applied outside of its validity window.
+	ErrPromoCodeNotActive = errors.New("promo code is not active")
+	// ErrPromoCodeMinSpendNotReached is returned when order's total is below promo code's
+	// minimum spend.
+	ErrPromoCodeMinSpendNotReached = errors.New("order total is below promo code minimum spend")
+	// ErrOrderConnectionUnauthorized is returned when connecting to an order, or acting on its
+	// behalf, without table session or staff token.
+	ErrOrderConnectionUnauthorized = errors.New("table session or staff token is required")
+	// ErrTableSessionNotForOrder is returned when table session was issued for another order.
+	ErrTableSessionNotForOrder = errors.New("table session is not for this order")
+	// ErrUserIsNotRestaurantStaff is returned when user is neither waiter nor manager of
+	// order's restaurant.
+	ErrUserIsNotRestaurantStaff = errors.New("user is not staff of order's restaurant")
+	// ErrDisplayNameTooLong is returned when guest's display name is over
+	// dto.MaxDisplayNameLength characters.
+	ErrDisplayNameTooLong = errors.New("display name is too long")
 )
 
+// defaultGuestDisplayName is shown for guests who didn't tell their name.
+const defaultGuestDisplayName = "Guest"
+
 type ordersService struct {
-	repo repository.OrdersRepo
+	repo           repository.OrdersRepo
+	tipsRepo       repository.TipsRepo
+	promotionsRepo repository.PromotionsRepo
+	events         events.Publisher
+	sessions       *sessions.TableSessions
 }
 
-// NewOrdersService creates a new orders service instance.
+// NewOrdersService creates a new orders service instance. Every change of an order is
+// published as an order event, guests get table sessions with table's current order.
 //
 //revive:disable:unexported-return
-func NewOrdersService(repo repository.OrdersRepo) *ordersService {
+func NewOrdersService(
+	repo repository.OrdersRepo,
+	tipsRepo repository.TipsRepo,
+	promotionsRepo repository.PromotionsRepo,
+	publisher events.Publisher,
+	tableSessions *sessions.TableSessions,
+) *ordersService {
 	return &ordersService{
-		repo: repo,
+		repo:           repo,
+		tipsRepo:       tipsRepo,
+		promotionsRepo: promotionsRepo,
+		events:         publisher,
+		sessions:       tableSessions,
 	}
 }
 
@@ -64,6 +116,26 @@ func NewOrdersService(repo repository.OrdersRepo) *ordersService {
 func (s *ordersService) GetOrCreateCurrentOrderForTable(
 	ctx context.Context,
 	tableID uuid.UUID,
+) (*dto.CurrentOrderDto, error) {
+	respDto, err := s.getOrCreateCurrentOrderForTable(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.Issue(respDto.ID, tableID)
+	if err != nil {
+		return nil, fmt.Errorf("issuing table session: %w", err)
+	}
+
+	respDto.SessionToken = session.Token
+	respDto.SessionExpiresAt = &session.ExpiresAt
+
+	return respDto, nil
+}
+
+func (s *ordersService) getOrCreateCurrentOrderForTable(
+	ctx context.Context,
+	tableID uuid.UUID,
 ) (*dto.CurrentOrderDto, error) {
 	respDto, err := s.repo.GetCurrentOrderForTable(ctx, tableID)
 	if err == nil {
@@ -84,21 +156,147 @@ func (s *ordersService) GetOrCreateCurrentOrderForTable(
 		return nil, fmt.Errorf("creating new order: %w", err)
 	}
 
+	table, err := s.repo.GetOrderTable(ctx, respDto.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting table of new order: %w", err)
+	}
+
+	opened := newStaffEvent(table, respDto.ID)
+
+	s.events.Publish(ctx, events.NewStaffEvent(dto.MsgOrderOpened, opened))
+
 	return respDto, nil
 }
 
+// AuthorizeOrderConnection checks that the connection to an order is made by a guest with
+// table session of the order or by waiter or manager of order's restaurant, and returns
+// them as participant of the order. Guests take part under the display name they chose,
+// staff under their name.
+func (s *ordersService) AuthorizeOrderConnection(
+	ctx context.Context,
+	orderID uuid.UUID,
+	sessionToken, displayName string,
+	claims *authDto.TokenClaimsDto,
+) (*dto.ParticipantDto, error) {
+	session, err := authorizeOrderAccess(ctx, s.sessions, s.repo, orderID, sessionToken, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if session != nil {
+		return s.joinAsGuest(ctx, session, displayName)
+	}
+
+	participant, err := s.repo.SaveStaffParticipant(ctx, orderID, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("saving staff participant: %w", err)
+	}
+
+	return participant, nil
+}
+
+// authorizeOrderAccess checks that the request to an order is made by a guest with table
+// session of the order or by waiter or manager of order's restaurant. It returns guest's
+// session, requests of staff get nil session.
+func authorizeOrderAccess(
+	ctx context.Context,
+	tableSessions *sessions.TableSessions,
+	repo repository.OrdersRepo,
+	orderID uuid.UUID,
+	sessionToken string,
+	claims *authDto.TokenClaimsDto,
+) (*sessions.Session, error) {
+	authErr := ErrOrderConnectionUnauthorized
+
+	if sessionToken != "" {
+		session, err := tableSessions.Verify(sessionToken)
+		if err == nil && session.OrderID == orderID {
+			return session, nil
+		}
+
+		authErr = ErrTableSessionNotForOrder
+		if err != nil {
+			authErr = fmt.Errorf("verifying table session: %w", err)
+		}
+	}
+
+	if claims == nil || claims.UserID == uuid.Nil {
+		return nil, authErr
+	}
+
+	order, err := repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching order details: %w", err)
+	}
+
+	isStaff, err := isRestaurantStaff(ctx, repo, claims.UserID, order.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isStaff {
+		return nil, ErrUserIsNotRestaurantStaff
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// joinAsGuest saves guest of the session as participant of its order. Sessions issued
+// before guests were told apart get a participant for each connection.
+func (s *ordersService) joinAsGuest(
+	ctx context.Context,
+	session *sessions.Session,
+	displayName string,
+) (*dto.ParticipantDto, error) {
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		displayName = defaultGuestDisplayName
+	}
+
+	if utf8.RuneCountInString(displayName) > dto.MaxDisplayNameLength {
+		return nil, ErrDisplayNameTooLong
+	}
+
+	participantID := session.ParticipantID
+	if participantID == uuid.Nil {
+		participantID = uuid.New()
+	}
+
+	participant, err := s.repo.SaveGuestParticipant(
+		ctx,
+		session.OrderID,
+		participantID,
+		displayName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("saving guest participant: %w", err)
+	}
+
+	return participant, nil
+}
+
 func (s *ordersService) GetOrder(ctx context.Context, orderID uuid.UUID) (*dto.OrderDto, error) {
 	respDto, err := s.repo.GetOrderItems(ctx, orderID)
 	if err != nil {
 		return nil, fmt.Errorf("getting order: %w", err)
 	}
 
+	tipSettings, err := getTipSettings(ctx, s.tipsRepo, respDto.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	respDto.TipPresets = tipSettings.Presets
+
 	return respDto, nil
 }
 
+// AddItemToOrder adds the menu item to the order, addedBy is the participant who added it
+// or nil when it's not known.
 func (s *ordersService) AddItemToOrder(
 	ctx context.Context,
 	orderID, itemID uuid.UUID,
+	addedBy *dto.ParticipantDto,
 ) (*dto.OrderDto, error) {
 	item, err := s.repo.GetMenuItem(ctx, itemID)
 	if err != nil {
@@ -118,13 +316,27 @@ func (s *ordersService) AddItemToOrder(
 		return nil, ErrOrderIsNotOpen
 	}
 
-	addedOrderItem, err := s.repo.AddItemToOrder(ctx, orderID, item)
+	table, err := s.repo.GetOrderTable(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order table: %w", err)
+	}
+
+	addedOrderItem, err := s.repo.AddItemToOrder(ctx, orderID, item, addedBy)
 	if err != nil {
 		return nil, fmt.Errorf("adding item to order: %w", err)
 	}
 
 	currentOrder.Items = append(currentOrder.Items, addedOrderItem)
 	currentOrder.TotalPriceInCents += addedOrderItem.PriceInCents
+	promotions.ApplyDiscounts(currentOrder)
+	currentOrder.BalanceDueInCents = balanceDue(currentOrder)
+
+	s.events.Publish(ctx, events.NewOrderEvent(dto.MsgAddItem, currentOrder))
+
+	added := newStaffEvent(table, orderID)
+	added.Items = []*dto.OrderItemDto{addedOrderItem}
+
+	s.events.Publish(ctx, events.NewStaffEvent(dto.MsgItemsAdded, added))
 
 	return currentOrder, nil
 }
@@ -159,6 +371,10 @@ func (s *ordersService) DeleteOrderItem(
 	}
 
 	currentOrder.TotalPriceInCents -= deletedItem.PriceInCents
+	promotions.ApplyDiscounts(currentOrder)
+	currentOrder.BalanceDueInCents = balanceDue(currentOrder)
+
+	s.events.Publish(ctx, events.NewOrderEvent(dto.MsgDeleteItem, currentOrder))
 
 	return currentOrder, nil
 }
@@ -182,13 +398,46 @@ func (s *ordersService) UpdateOrder(
 		return nil, err
 	}
 
+	// order keeps discounts it has when it stops being open, changes to promotions made while
+	// it is paid don't change what guests pay
+	if currentOrder.Status == db.OrderStatusOpen && reqDto.Status != nil &&
+		*reqDto.Status != db.OrderStatusOpen {
+		err = s.repo.SaveOrderDiscounts(ctx, currentOrder.ID, currentOrder.Discounts)
+		if err != nil {
+			return nil, fmt.Errorf("saving order discounts: %w", err)
+		}
+	}
+
+	// staff is told about orders locked for payment, table is loaded before order is changed
+	var table *dto.OrderTableDto
+
+	if reqDto.Status != nil && *reqDto.Status == db.OrderStatusLocked &&
+		currentOrder.Status != db.OrderStatusLocked {
+		table, err = s.repo.GetOrderTable(ctx, reqDto.OrderID)
+		if err != nil {
+			return nil, fmt.Errorf("getting order table: %w", err)
+		}
+	}
+
 	respDto, err := s.repo.UpdateOrder(ctx, reqDto)
 	if err != nil {
 		return nil, fmt.Errorf("updating order: %w", err)
 	}
 
+	locked := table != nil && respDto.Status == db.OrderStatusLocked
+
 	currentOrder.Status = respDto.Status
 	currentOrder.TipAmountInCents = respDto.TipAmountInCents
+	currentOrder.BalanceDueInCents = balanceDue(currentOrder)
+
+	s.events.Publish(ctx, events.NewOrderEvent(dto.MsgUpdateOrder, currentOrder))
+
+	if locked {
+		s.events.Publish(ctx, events.NewStaffEvent(
+			dto.MsgOrderLocked,
+			newStaffEvent(table, currentOrder.ID),
+		))
+	}
 
 	return currentOrder, nil
 }
@@ -209,6 +458,8 @@ func (s *ordersService) AssignWaiter(ctx context.Context, orderID, userID uuid.U
 		return fmt.Errorf("assigning waiter to order: %w", err)
 	}
 
+	s.events.Publish(ctx, events.NewOrderChangedEvent(orderID))
+
 	return nil
 }
 
@@ -231,9 +482,91 @@ func (s *ordersService) RemoveWaiter(
 		return fmt.Errorf("removing waiter from order: %w", err)
 	}
 
+	s.events.Publish(ctx, events.NewOrderChangedEvent(orderID))
+
 	return nil
 }
 
+// ApplyPromoCode applies restaurant's promo code to an open order, replacing the code applied
+// before. Codes are matched regardless of case.
+func (s *ordersService) ApplyPromoCode(
+	ctx context.Context,
+	reqDto *dto.ApplyPromoCodeRequestDto,
+) (*dto.OrderDto, error) {
+	order, err := s.repo.GetOrderItems(ctx, reqDto.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting current order: %w", err)
+	}
+
+	if order.Status != db.OrderStatusOpen {
+		return nil, ErrOrderIsNotOpen
+	}
+
+	code, err := s.promotionsRepo.GetPromoCodeByCode(
+		ctx,
+		order.RestaurantID,
+		normalizePromoCode(reqDto.Code),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting promo code: %w", err)
+	}
+
+	if !isPromoCodeActiveAt(code, time.Now()) {
+		return nil, ErrPromoCodeNotActive
+	}
+
+	if order.TotalPriceInCents < code.MinSpendInCents {
+		return nil, ErrPromoCodeMinSpendNotReached
+	}
+
+	err = s.promotionsRepo.ApplyPromoCodeToOrder(ctx, order.ID, code.ID)
+	if err != nil {
+		return nil, fmt.Errorf("applying promo code to order: %w", err)
+	}
+
+	order.PromoCode = &dto.AppliedPromoCodeDto{
+		ID:              code.ID,
+		Code:            code.Code,
+		DiscountType:    code.DiscountType,
+		DiscountValue:   code.DiscountValue,
+		MinSpendInCents: code.MinSpendInCents,
+	}
+	promotions.ApplyDiscounts(order)
+	order.BalanceDueInCents = balanceDue(order)
+
+	s.events.Publish(ctx, events.NewOrderEvent(dto.MsgApplyPromoCode, order))
+
+	return order, nil
+}
+
+// RemovePromoCode removes promo code from an open order.
+func (s *ordersService) RemovePromoCode(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.OrderDto, error) {
+	order, err := s.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting current order: %w", err)
+	}
+
+	if order.Status != db.OrderStatusOpen {
+		return nil, ErrOrderIsNotOpen
+	}
+
+	err = s.promotionsRepo.RemovePromoCodeFromOrder(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("removing promo code from order: %w", err)
+	}
+
+	order.PromoCode = nil
+	promotions.ApplyDiscounts(order)
+	order.BalanceDueInCents = balanceDue(order)
+
+	s.events.Publish(ctx, events.NewOrderEvent(dto.MsgRemovePromoCode, order))
+
+	return order, nil
+}
+
 func (s *ordersService) canUserEditOrder(
 	ctx context.Context,
 	order *dto.OrderDto,
@@ -255,6 +588,28 @@ func (s *ordersService) canUserEditOrder(
 	return true, nil
 }
 
+// balanceDue returns how much is still left to pay for the order, negative value means it's overpaid.
+func balanceDue(order *dto.OrderDto) int {
+	return order.TotalPriceInCents +
+		order.TipAmountInCents -
+		order.DiscountInCents -
+		order.AmountPaidInCents
+}
+
+// normalizePromoCode returns code the way promo codes are stored.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// isPromoCodeActiveAt reports whether t falls into promo code's validity window.
+func isPromoCodeActiveAt(code *dto.PromoCodeDto, t time.Time) bool {
+	if code.ValidFrom != nil && t.Before(*code.ValidFrom) {
+		return false
+	}
+
+	return code.ValidUntil == nil || t.Before(*code.ValidUntil)
+}
+
 func (s *ordersService) isOrderFinalized(order *dto.OrderDto) bool {
 	return order.Status == db.OrderStatusCancelled || order.Status == db.OrderStatusCompleted
 }
@@ -5,7 +5,11 @@ import (
 	authDto "golang-dining-ordering/services/auth/dto"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/sessions"
 	mock "golang-dining-ordering/test/mock/orders"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -27,7 +31,13 @@ type ordersServiceTestSuite struct {
 
 func (suite *ordersServiceTestSuite) SetupSuite() {
 	mockOrdersRepo := mock.NewMockOrdersRepo()
-	suite.svc = NewOrdersService(mockOrdersRepo)
+	suite.svc = NewOrdersService(
+		mockOrdersRepo,
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
 
 	suite.orderDto = &dto.OrderDto{
 		ID:                testOrderID,
@@ -37,6 +47,7 @@ func (suite *ordersServiceTestSuite) SetupSuite() {
 		Currency:          testCurrency,
 		TipAmountInCents:  testAmount,
 		TotalPriceInCents: testAmount,
+		BalanceDueInCents: testAmount * 2,
 		UpdatedAt:         testDateTime,
 		Items: []*dto.OrderItemDto{
 			{
@@ -57,6 +68,7 @@ func TestOrdersServiceTestSuite(t *testing.T) {
 
 func (suite *ordersServiceTestSuite) TestGetOrder_Success() {
 	want := *suite.orderDto
+	want.TipPresets = []float64{5, 12.5}
 
 	got, err := suite.svc.GetOrder(context.Background(), testOrderID)
 	suite.Require().NoError(err)
@@ -69,27 +81,41 @@ func (suite *ordersServiceTestSuite) TestGetOrder_RepoFailed() {
 	suite.Nil(got)
 }
 
-func (suite *ordersServiceTestSuite) TestGetOrCreateCurrentOrderForTable_SuccessOrderExists() {
-	want := &dto.CurrentOrderDto{
-		ID: testOrderID,
-	}
+func (suite *ordersServiceTestSuite) TestGetOrder_TipSettingsFailed() {
+	ctx := context.WithValue(context.Background(), mock.CtxFailGetTipSettings, true)
+
+	got, err := suite.svc.GetOrder(ctx, testOrderID)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Nil(got)
+}
 
+func (suite *ordersServiceTestSuite) TestGetOrCreateCurrentOrderForTable_SuccessOrderExists() {
 	got, err := suite.svc.GetOrCreateCurrentOrderForTable(context.Background(), testTableID)
 	suite.Require().NoError(err)
-	suite.Equal(want, got)
+	suite.Equal(testOrderID, got.ID)
+	suite.assertTableSession(got, testTableID)
 }
 
 func (suite *ordersServiceTestSuite) TestGetOrCreateCurrentOrderForTable_SuccessNewOrderCreated() {
-	want := &dto.CurrentOrderDto{
-		ID: testOrderID,
-	}
-
 	got, err := suite.svc.GetOrCreateCurrentOrderForTable(
 		context.Background(),
 		testCompletedOrderID,
 	)
 	suite.Require().NoError(err)
-	suite.Equal(want, got)
+	suite.Equal(testOrderID, got.ID)
+	suite.assertTableSession(got, testCompletedOrderID)
+}
+
+func (suite *ordersServiceTestSuite) assertTableSession(
+	current *dto.CurrentOrderDto,
+	tableID uuid.UUID,
+) {
+	session, err := mock.NewTableSessions().Verify(current.SessionToken)
+	suite.Require().NoError(err)
+	suite.Equal(current.ID, session.OrderID)
+	suite.Equal(tableID, session.TableID)
+	suite.Require().NotNil(current.SessionExpiresAt)
+	suite.True(session.ExpiresAt.Equal(*current.SessionExpiresAt))
 }
 
 func (suite *ordersServiceTestSuite) TestGetOrCreateCurrentOrderForTable_Error() {
@@ -114,6 +140,12 @@ func (suite *ordersServiceTestSuite) TestGetOrCreateCurrentOrderForTable_Error()
 }
 
 func (suite *ordersServiceTestSuite) TestAddItemToOrder_Success() {
+	participant := &dto.ParticipantDto{
+		ID:          uuid.New(),
+		DisplayName: "Tom",
+		Role:        db.OrdersParticipantRoleGuest,
+	}
+
 	want := *suite.orderDto
 	want.Items = append(want.Items, &dto.OrderItemDto{
 		ID:           testOrderItemID,
@@ -121,10 +153,17 @@ func (suite *ordersServiceTestSuite) TestAddItemToOrder_Success() {
 		ItemID:       testItemID,
 		Name:         testItemName,
 		PriceInCents: testAmount,
+		AddedBy:      participant,
 	})
 	want.TotalPriceInCents += 10
+	want.BalanceDueInCents += 10
 
-	got, err := suite.svc.AddItemToOrder(context.Background(), testOrderID, testItemID)
+	got, err := suite.svc.AddItemToOrder(
+		context.Background(),
+		testOrderID,
+		testItemID,
+		participant,
+	)
 	suite.Require().NoError(err)
 	suite.Equal(&want, got)
 }
@@ -146,7 +185,7 @@ func (suite *ordersServiceTestSuite) TestAddItemToOrder_Error() {
 	for _, tt := range tests {
 		suite.T().Run(tt.name, func(_ *testing.T) {
 			ctx := context.WithValue(context.Background(), tt.failCtxKey, true)
-			got, err := suite.svc.AddItemToOrder(ctx, tt.orderID, tt.itemID)
+			got, err := suite.svc.AddItemToOrder(ctx, tt.orderID, tt.itemID, nil)
 			suite.Require().Error(err)
 			suite.Nil(got)
 		})
@@ -157,6 +196,7 @@ func (suite *ordersServiceTestSuite) TestDeleteOrderItem_Success() {
 	want := *suite.orderDto
 	want.Items = []*dto.OrderItemDto{}
 	want.TotalPriceInCents = 0
+	want.BalanceDueInCents = testAmount
 	got, err := suite.svc.DeleteOrderItem(context.Background(), testOrderItemID, testOrderID)
 	suite.Require().NoError(err)
 	suite.Equal(&want, got)
@@ -198,6 +238,39 @@ func (suite *ordersServiceTestSuite) TestUpdateOrder_Success() {
 	suite.Equal(&want, got)
 }
 
+func (suite *ordersServiceTestSuite) TestUpdateOrder_SavesDiscounts() {
+	ordersRepo := mock.NewMockOrdersRepo()
+	svc := NewOrdersService(
+		ordersRepo,
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
+
+	tip := int32(testAmount) //nolint:gosec
+	_, err := svc.UpdateOrder(context.Background(), &dto.UpdateOrderReqDto{
+		OrderID:          testOrderID,
+		TipAmountInCents: &tip,
+		Status:           nil,
+	}, nil)
+	suite.Require().NoError(err)
+
+	_, saved := ordersRepo.SavedDiscounts(testOrderID)
+	suite.False(saved, "open order uses current promotions")
+
+	status := db.OrderStatusLocked
+	_, err = svc.UpdateOrder(context.Background(), &dto.UpdateOrderReqDto{
+		OrderID:          testOrderID,
+		TipAmountInCents: nil,
+		Status:           &status,
+	}, nil)
+	suite.Require().NoError(err)
+
+	_, saved = ordersRepo.SavedDiscounts(testOrderID)
+	suite.True(saved, "locked order keeps its discounts")
+}
+
 func (suite *ordersServiceTestSuite) TestUpdateOrder_Error() {
 	statusLocked := db.OrderStatusLocked
 	tip := int32(testAmount) //nolint:gosec
@@ -212,6 +285,13 @@ func (suite *ordersServiceTestSuite) TestUpdateOrder_Error() {
 		{"repo failed get order items", "none", uuid.Max, &tip, &statusLocked},
 		{"cant edit locked order", "none", testCompletedOrderID, &tip, &statusLocked},
 		{"repo failed update order", mock.CtxFailUpdateOrder, testOrderID, &tip, &statusLocked},
+		{
+			"repo failed save order discounts",
+			mock.CtxFailSaveOrderDiscounts,
+			testOrderID,
+			&tip,
+			&statusLocked,
+		},
 	}
 
 	for _, tt := range tests {
@@ -315,6 +395,174 @@ func (suite *ordersServiceTestSuite) TestAssignWaiter_Error() {
 	}
 }
 
+func (suite *ordersServiceTestSuite) TestAuthorizeOrderConnection() {
+	tableSessions := mock.NewTableSessions()
+
+	session, err := tableSessions.Issue(testOrderID, testTableID)
+	suite.Require().NoError(err)
+
+	otherSession, err := tableSessions.Issue(uuid.New(), testTableID)
+	suite.Require().NoError(err)
+
+	guest := &authDto.TokenClaimsDto{}
+
+	tests := []struct {
+		name         string
+		orderID      uuid.UUID
+		sessionToken string
+		claims       *authDto.TokenClaimsDto
+		wantErr      error
+	}{
+		{"guest with table session", testOrderID, session.Token, guest, nil},
+		{"waiter", testOrderID, "", &authDto.TokenClaimsDto{UserID: testUserID}, nil},
+		{
+			"waiter with session of another order",
+			testOrderID,
+			otherSession.Token,
+			&authDto.TokenClaimsDto{UserID: testUserID},
+			nil,
+		},
+		{"no session or staff token", testOrderID, "", guest, ErrOrderConnectionUnauthorized},
+		{"no claims", testOrderID, "", nil, ErrOrderConnectionUnauthorized},
+		{"invalid session", testOrderID, "invalid", guest, sessions.ErrInvalidToken},
+		{
+			"session of another order",
+			testOrderID,
+			otherSession.Token,
+			guest,
+			ErrTableSessionNotForOrder,
+		},
+		{
+			"staff of another restaurant",
+			testOrderID,
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+			ErrUserIsNotRestaurantStaff,
+		},
+		{
+			"order doesn't exist",
+			uuid.New(),
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserID},
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			participant, err := suite.svc.AuthorizeOrderConnection(
+				context.Background(),
+				tt.orderID,
+				tt.sessionToken,
+				"",
+				tt.claims,
+			)
+			if tt.wantErr == nil {
+				suite.Require().NoError(err)
+				suite.NotNil(participant)
+
+				return
+			}
+
+			suite.Require().ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (suite *ordersServiceTestSuite) TestAuthorizeOrderConnection_StaffCheckFailed() {
+	_, err := suite.svc.AuthorizeOrderConnection(
+		context.WithValue(context.Background(), mock.CtxFailIsUserRestaurantMember, true),
+		testOrderID,
+		"",
+		"",
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Require().NotErrorIs(err, ErrUserIsNotRestaurantStaff)
+}
+
+func (suite *ordersServiceTestSuite) TestAuthorizeOrderConnection_Participant() {
+	session, err := mock.NewTableSessions().Issue(testOrderID, testTableID)
+	suite.Require().NoError(err)
+
+	guest := &authDto.TokenClaimsDto{}
+	waiter := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	tests := []struct {
+		name        string
+		token       string
+		displayName string
+		claims      *authDto.TokenClaimsDto
+		wantID      uuid.UUID
+		wantName    string
+		wantRole    db.OrdersParticipantRole
+		wantErr     error
+	}{
+		{
+			"guest with display name",
+			session.Token,
+			"  Tom ",
+			guest,
+			session.ParticipantID,
+			"Tom",
+			db.OrdersParticipantRoleGuest,
+			nil,
+		},
+		{
+			"guest without display name",
+			session.Token,
+			"",
+			guest,
+			session.ParticipantID,
+			defaultGuestDisplayName,
+			db.OrdersParticipantRoleGuest,
+			nil,
+		},
+		{
+			"guest with too long display name",
+			session.Token,
+			strings.Repeat("a", dto.MaxDisplayNameLength+1),
+			guest,
+			uuid.Nil,
+			"",
+			"",
+			ErrDisplayNameTooLong,
+		},
+		{
+			"staff ignores display name",
+			"",
+			"Tom",
+			waiter,
+			testUserID,
+			"Test Waiter",
+			db.OrdersParticipantRoleStaff,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			participant, err := suite.svc.AuthorizeOrderConnection(
+				context.Background(),
+				testOrderID,
+				tt.token,
+				tt.displayName,
+				tt.claims,
+			)
+			if tt.wantErr != nil {
+				suite.Require().ErrorIs(err, tt.wantErr)
+
+				return
+			}
+
+			suite.Require().NoError(err)
+			suite.Equal(tt.wantID, participant.ID)
+			suite.Equal(tt.wantName, participant.DisplayName)
+			suite.Equal(tt.wantRole, participant.Role)
+		})
+	}
+}
+
 func (suite *ordersServiceTestSuite) TestRemoveWaiter_Success() {
 	err := suite.svc.RemoveWaiter(context.Background(), testOrderID, testUserID, uuid.New())
 	suite.Require().NoError(err)
@@ -338,3 +586,203 @@ func (suite *ordersServiceTestSuite) TestRemoveWaiter_Error() {
 		})
 	}
 }
+
+func (suite *ordersServiceTestSuite) TestApplyPromoCode_Success() {
+	svc := NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
+
+	order, err := svc.ApplyPromoCode(
+		context.Background(),
+		&dto.ApplyPromoCodeRequestDto{OrderID: testOrderID, Code: " save50 "},
+	)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(order.PromoCode)
+	suite.Equal("SAVE50", order.PromoCode.Code)
+	suite.Equal(testAmount/2, order.DiscountInCents)
+	suite.Equal(testAmount*2-testAmount/2, order.BalanceDueInCents)
+	suite.Require().Len(order.Discounts, 1)
+	suite.Equal(dto.DiscountSourcePromoCode, order.Discounts[0].Source)
+
+	order, err = svc.RemovePromoCode(context.Background(), testOrderID)
+	suite.Require().NoError(err)
+	suite.Nil(order.PromoCode)
+	suite.Zero(order.DiscountInCents)
+	suite.Equal(testAmount*2, order.BalanceDueInCents)
+}
+
+func (suite *ordersServiceTestSuite) TestApplyPromoCode_Error() {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		orderID uuid.UUID
+		code    string
+		wantErr error
+	}{
+		{"failed to get order", context.Background(), uuid.Nil, "SAVE50", mock.ErrRepoFailed},
+		{
+			"order is not open",
+			context.Background(),
+			testCompletedOrderID,
+			"SAVE50",
+			ErrOrderIsNotOpen,
+		},
+		{
+			"code does not exist",
+			context.Background(),
+			testOrderID,
+			"NOPE",
+			repository.ErrPromoCodeDoesNotExist,
+		},
+		{"code expired", context.Background(), testOrderID, "EXPIRED", ErrPromoCodeNotActive},
+		{
+			"minimum spend not reached",
+			context.Background(),
+			testOrderID,
+			"BIGSPEND",
+			ErrPromoCodeMinSpendNotReached,
+		},
+		{
+			"code used up",
+			context.Background(),
+			testOrderID,
+			"USEDUP",
+			repository.ErrPromoCodeUsedUp,
+		},
+		{
+			"repo failed",
+			context.WithValue(context.Background(), mock.CtxFailApplyPromoCodeToOrder, true),
+			testOrderID,
+			"SAVE50",
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			_, err := suite.svc.ApplyPromoCode(
+				tt.ctx,
+				&dto.ApplyPromoCodeRequestDto{OrderID: tt.orderID, Code: tt.code},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (suite *ordersServiceTestSuite) TestRemovePromoCode_Error() {
+	_, err := suite.svc.RemovePromoCode(context.Background(), testCompletedOrderID)
+	suite.Require().ErrorIs(err, ErrOrderIsNotOpen)
+
+	_, err = suite.svc.RemovePromoCode(context.Background(), testOrderID)
+	suite.Require().ErrorIs(err, repository.ErrOrderHasNoPromoCode)
+}
+
+func (suite *ordersServiceTestSuite) TestOrderChangesArePublished() {
+	pubsub := events.NewMemoryPubSub(10)
+	svc := NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		pubsub,
+		mock.NewTableSessions(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	order, err := svc.AddItemToOrder(context.Background(), testOrderID, testItemID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(withSeq(events.NewOrderEvent(dto.MsgAddItem, order), 1), <-published)
+	suite.True((<-published).IsStaff())
+
+	_, err = svc.AddItemToOrder(context.Background(), testCompletedOrderID, testItemID, nil)
+	suite.Require().Error(err)
+
+	order, err = svc.DeleteOrderItem(context.Background(), testOrderItemID, testOrderID)
+	suite.Require().NoError(err)
+	suite.Equal(withSeq(events.NewOrderEvent(dto.MsgDeleteItem, order), 2), <-published)
+
+	err = svc.AssignWaiter(context.Background(), testOrderID, testUserID)
+	suite.Require().NoError(err)
+	suite.Equal(withSeq(events.NewOrderChangedEvent(testOrderID), 3), <-published)
+
+	suite.Empty(published)
+}
+
+func (suite *ordersServiceTestSuite) TestStaffEventsArePublished() {
+	pubsub := events.NewMemoryPubSub(10)
+	svc := NewOrdersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockTipsRepo(),
+		mock.NewMockPromotionsRepo(),
+		pubsub,
+		mock.NewTableSessions(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	// table without current order gets a new one
+	_, err = svc.GetOrCreateCurrentOrderForTable(context.Background(), uuid.New())
+	suite.Require().NoError(err)
+
+	opened := <-published
+	suite.Equal(dto.MsgOrderOpened, opened.Type)
+	suite.Require().NotNil(opened.Staff)
+	suite.Equal(testRestaurantID, opened.Staff.RestaurantID)
+	suite.Equal(testOrderID, opened.Staff.OrderID)
+	suite.NotNil(opened.Staff.Table)
+
+	_, err = svc.AddItemToOrder(context.Background(), testOrderID, testItemID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(dto.MsgAddItem, (<-published).Type)
+
+	added := <-published
+	suite.Equal(dto.MsgItemsAdded, added.Type)
+	suite.Require().NotNil(added.Staff)
+	suite.Require().Len(added.Staff.Items, 1)
+	suite.Equal(testItemName, added.Staff.Items[0].Name)
+	suite.NotNil(added.Staff.Table)
+
+	status := db.OrderStatusLocked
+	_, err = svc.UpdateOrder(
+		context.Background(),
+		&dto.UpdateOrderReqDto{OrderID: testOrderID, TipAmountInCents: nil, Status: &status},
+		&authDto.TokenClaimsDto{},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(dto.MsgUpdateOrder, (<-published).Type)
+
+	locked := <-published
+	suite.Equal(dto.MsgOrderLocked, locked.Type)
+	suite.True(locked.IsStaff())
+	suite.Equal(int64(0), locked.Seq)
+
+	// order that is already locked isn't locked again
+	tip := int32(100)
+	_, err = svc.UpdateOrder(
+		context.WithValue(context.Background(), mock.CtxLockedOrder, true),
+		&dto.UpdateOrderReqDto{OrderID: testOrderID, TipAmountInCents: &tip, Status: nil},
+		&authDto.TokenClaimsDto{},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(dto.MsgUpdateOrder, (<-published).Type)
+
+	suite.Empty(published)
+}
+
+func withSeq(event *events.Event, seq int64) *events.Event {
+	event.Seq = seq
+
+	return event
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
 	"golang-dining-ordering/services/orders/paymentproviders"
 	"golang-dining-ordering/services/orders/repository"
-	"net/http"
+	"golang-dining-ordering/services/orders/sessions"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -20,34 +23,64 @@ type PaymentsService interface {
 		orderID uuid.UUID,
 		reqDto *dto.CheckoutSessionRequestDto,
 	) (*dto.CheckoutSessionResponseDto, error)
-	HandleWebhookSuccess(
+	RefundOrder(
 		ctx context.Context,
-		payload []byte,
-		header http.Header,
+		reqDto *dto.RefundRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.RefundDto, error)
+	HandleWebhook(ctx context.Context, reqDto *dto.WebhookDto) (*dto.ProviderEventDto, error)
+	GetOrderPayments(
+		ctx context.Context,
+		orderID uuid.UUID,
+		sessionToken string,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.OrderPaymentsDto, error)
+	RecordOfflinePayment(
+		ctx context.Context,
+		reqDto *dto.OfflinePaymentRequestDto,
+		claims *authDto.TokenClaimsDto,
 	) (*dto.PaymentDto, error)
 }
 
-// ErrOrderPriceIsZero is returned when order's total amount and tip are 0.
-var ErrOrderPriceIsZero = errors.New("order total price and tip amount are 0")
+var (
+	// ErrOrderPriceIsZero is returned when order's total amount and tip are 0.
+	ErrOrderPriceIsZero = errors.New("order total price and tip amount are 0")
+	// ErrOrderAlreadyPaid is returned when there is no outstanding balance left for the order.
+	ErrOrderAlreadyPaid = errors.New("order has no outstanding balance")
+	// ErrWebhookRestaurantMismatch is returned when webhook verified with one restaurant's
+	// credentials is for an order of another restaurant.
+	ErrWebhookRestaurantMismatch = errors.New("payment is for an order of another restaurant")
+	// ErrWebhookAlreadyHandled is returned when provider retries a webhook event that was
+	// already handled, it should be acknowledged without doing anything.
+	ErrWebhookAlreadyHandled = errors.New("webhook event is already handled")
+)
 
 type paymentsService struct {
 	ordersRepo   repository.OrdersRepo
 	paymentsRepo repository.PaymentsRepo
-	provider     paymentproviders.PaymentProvider
+	providers    paymentproviders.Registry
+	events       events.Publisher
+	sessions     *sessions.TableSessions
 }
 
-// NewPaymentsService creates a new payments service instance.
+// NewPaymentsService creates a new payments service instance. Payments and refunds publish
+// an order event of the order they changed, guests listing payments are checked against
+// their table sessions.
 //
 //revive:disable:unexported-return
 func NewPaymentsService(
 	ordersRepo repository.OrdersRepo,
 	paymentsRepo repository.PaymentsRepo,
-	provider paymentproviders.PaymentProvider,
+	providers paymentproviders.Registry,
+	publisher events.Publisher,
+	tableSessions *sessions.TableSessions,
 ) *paymentsService {
 	return &paymentsService{
 		ordersRepo:   ordersRepo,
 		paymentsRepo: paymentsRepo,
-		provider:     provider,
+		providers:    providers,
+		events:       publisher,
+		sessions:     tableSessions,
 	}
 }
 
@@ -69,42 +102,170 @@ func (s *paymentsService) CreateCheckout(
 	}
 
 	reqDto.OrderDto = order
+	reqDto.PaymentAttemptID = uuid.New()
+
+	var provider paymentproviders.PaymentProvider
+
+	if reqDto.Provider != nil {
+		provider, err = s.providers.Get(ctx, order.RestaurantID, *reqDto.Provider)
+	} else {
+		provider, err = s.providers.GetDefault(ctx, order.RestaurantID)
+	}
 
-	respDto, err := s.provider.CreateCheckoutSession(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("getting restaurant payment provider: %w", err)
+	}
+
+	respDto, err := provider.CreateCheckoutSession(ctx, reqDto)
 	if err != nil {
 		return nil, fmt.Errorf("creating checkout session: %w", err)
 	}
 
+	attempt, err := s.paymentsRepo.SavePaymentAttempt(ctx, &dto.PaymentAttemptDto{
+		ID:                reqDto.PaymentAttemptID,
+		OrderID:           order.ID,
+		Provider:          respDto.Provider,
+		ProviderSessionID: respDto.SessionID,
+		AmountInCents:     balanceDue(order),
+		Currency:          order.Currency,
+		Status:            db.OrdersPaymentAttemptStatusPending,
+		CreatedAt:         time.Time{},
+		UpdatedAt:         time.Time{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving payment attempt: %w", err)
+	}
+
+	respDto.PaymentAttemptID = attempt.ID
+	respDto.AmountInCents = attempt.AmountInCents
+
 	return respDto, nil
 }
 
-func (s *paymentsService) HandleWebhookSuccess(
+// GetOrderPayments returns order's checkout attempts together with payments they resulted in
+// to a guest with table session of the order or to waiter or manager of order's restaurant.
+// Guests aren't shown which staff member recorded offline payments.
+func (s *paymentsService) GetOrderPayments(
+	ctx context.Context,
+	orderID uuid.UUID,
+	sessionToken string,
+	claims *authDto.TokenClaimsDto,
+) (*dto.OrderPaymentsDto, error) {
+	session, err := authorizeOrderAccess(
+		ctx,
+		s.sessions,
+		s.ordersRepo,
+		orderID,
+		sessionToken,
+		claims,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	attempts, err := s.paymentsRepo.GetOrderPaymentAttempts(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order payment attempts: %w", err)
+	}
+
+	payments, err := s.paymentsRepo.GetOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order payments: %w", err)
+	}
+
+	if session != nil {
+		for _, payment := range payments {
+			payment.RecordedBy = nil
+		}
+	}
+
+	return &dto.OrderPaymentsDto{
+		Attempts: attempts,
+		Payments: payments,
+	}, nil
+}
+
+// settleOrder compares amount paid with order's total and tip. Order is completed only when
+// its balance is zero, underpaid and overpaid orders are flagged for staff review instead.
+// Callers lock the order before saving the payment, so payments committed at the same time
+// are all counted by the last one settled.
+func settleOrder(
 	ctx context.Context,
-	payload []byte,
-	header http.Header,
-) (*dto.PaymentDto, error) {
-	paymentDto, err := s.provider.VerifySuccessWebhookEvent(payload, header)
+	ordersRepo repository.OrdersRepo,
+	paymentsRepo repository.PaymentsRepo,
+	orderID uuid.UUID,
+) error {
+	order, err := ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("getting order: %w", err)
+	}
+
+	order.AmountPaidInCents, err = paymentsRepo.GetOrderAmountPaid(ctx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("verifying payment success webhook event: %w", err)
+		return fmt.Errorf("getting order amount paid: %w", err)
+	}
+
+	var review db.OrdersPaymentReview
+
+	switch balance := balanceDue(order); {
+	case balance > 0:
+		review = db.OrdersPaymentReviewUnderpaid
+	case balance < 0:
+		review = db.OrdersPaymentReviewOverpaid
+	default:
+		return completeOrder(ctx, ordersRepo, order)
 	}
 
-	respDto, err := s.paymentsRepo.SavePayment(ctx, paymentDto)
+	err = ordersRepo.SetOrderPaymentReview(ctx, orderID, &review)
 	if err != nil {
-		return nil, fmt.Errorf("creating payment: %w", err)
+		return fmt.Errorf("flagging order for payment review: %w", err)
+	}
+
+	return nil
+}
+
+func completeOrder(
+	ctx context.Context,
+	ordersRepo repository.OrdersRepo,
+	order *dto.OrderDto,
+) error {
+	// orders paid without being locked keep discounts they have when they are completed
+	if order.Status == db.OrderStatusOpen {
+		err := ordersRepo.SaveOrderDiscounts(ctx, order.ID, order.Discounts)
+		if err != nil {
+			return fmt.Errorf("saving order discounts: %w", err)
+		}
+	}
+
+	if order.PaymentReview != nil {
+		err := ordersRepo.SetOrderPaymentReview(ctx, order.ID, nil)
+		if err != nil {
+			return fmt.Errorf("clearing order payment review: %w", err)
+		}
 	}
 
 	status := db.OrderStatusCompleted
 
-	_, err = s.ordersRepo.UpdateOrder(ctx, &dto.UpdateOrderReqDto{
-		OrderID:          respDto.OrderID,
+	_, err := ordersRepo.UpdateOrder(ctx, &dto.UpdateOrderReqDto{
+		OrderID:          order.ID,
 		Status:           &status,
 		TipAmountInCents: nil,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("updating order status: %w", err)
+		return fmt.Errorf("updating order status: %w", err)
 	}
 
-	return respDto, nil
+	_, err = ordersRepo.IssueReceipt(ctx, order.ID, order.RestaurantID, receiptContents(order))
+	if err != nil {
+		return fmt.Errorf("issuing receipt: %w", err)
+	}
+
+	return nil
 }
 
 func (s *paymentsService) canPayForOrder(order *dto.OrderDto) (bool, error) {
@@ -116,5 +277,9 @@ func (s *paymentsService) canPayForOrder(order *dto.OrderDto) (bool, error) {
 		return false, ErrOrderPriceIsZero
 	}
 
+	if balanceDue(order) <= 0 {
+		return false, ErrOrderAlreadyPaid
+	}
+
 	return true, nil
 }
@@ -3,10 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	authDto "golang-dining-ordering/services/auth/dto"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
 	mock "golang-dining-ordering/test/mock/orders"
-	"net/http"
 	"testing"
 	"time"
 
@@ -32,6 +33,10 @@ var (
 	testCheckoutURL               = "http://fake-checkout-session.com/1"
 	testPaymentProvider           = db.OrdersPaymentProviderMock
 	testProviderPaymentID         = "pi_123456"
+	testProviderRefundID          = "re_123456"
+	testProviderSessionID         = "cs_123456"
+	testPaymentAttemptID          = uuid.MustParse("68686868-6868-4686-8868-686868686868")
+	testReceiptID                 = uuid.MustParse("e0e0e0e0-e0e0-4e0e-8e0e-e0e0e0e0e0e0")
 )
 
 var ErrPaymentProviderFailed = errors.New("payment provider failed")
@@ -45,8 +50,14 @@ type paymentsServiceTestSuite struct {
 func (suite *paymentsServiceTestSuite) SetupSuite() {
 	mockOrdersRepo := mock.NewMockOrdersRepo()
 	mockPaymentsRepo := mock.NewMockPaymentsRepo()
-	mockPaymentsProvider := mock.NewMockPaymentsProvider()
-	suite.svc = NewPaymentsService(mockOrdersRepo, mockPaymentsRepo, mockPaymentsProvider)
+	mockProvidersRegistry := mock.NewMockProvidersRegistry()
+	suite.svc = NewPaymentsService(
+		mockOrdersRepo,
+		mockPaymentsRepo,
+		mockProvidersRegistry,
+		events.NewMemoryPubSub(10),
+		mock.NewTableSessions(),
+	)
 }
 
 func TestPaymentsServiceTestSuite(t *testing.T) {
@@ -62,25 +73,34 @@ func (suite *paymentsServiceTestSuite) TestCreateCheckout_Success() {
 	}
 
 	want := &dto.CheckoutSessionResponseDto{
-		URL:      testCheckoutURL,
-		Provider: testPaymentProvider,
+		URL:           testCheckoutURL,
+		Provider:      testPaymentProvider,
+		SessionID:     testProviderSessionID,
+		AmountInCents: testAmount * 2,
 	}
 
 	got, err := suite.svc.CreateCheckout(context.Background(), testOrderID, req)
 	suite.Require().NoError(err)
+	suite.NotEqual(uuid.Nil, got.PaymentAttemptID)
+	suite.Equal(req.PaymentAttemptID, got.PaymentAttemptID)
+
+	want.PaymentAttemptID = got.PaymentAttemptID
 	suite.Equal(want, got)
 }
 
 func (suite *paymentsServiceTestSuite) TestCreateCheckout_Error() {
 	tests := []struct {
 		name       string
+		ctxFailKey mock.CtxKey
 		orderID    uuid.UUID
 		successURL string
 		cancelURL  string
 	}{
-		{"invalid order id", uuid.Max, "success.url", "cancel.url"},
-		{"invalid dto", testOrderID, "", ""},
-		{"order already paid", testCompletedOrderID, "success.url", "cancel.url"},
+		{"invalid order id", "none", uuid.Max, "success.url", "cancel.url"},
+		{"invalid dto", "none", testOrderID, "", ""},
+		{"order already paid", "none", testCompletedOrderID, "success.url", "cancel.url"},
+		{"provider not configured", mock.CtxFailGetProvider, testOrderID, "success.url", "c.url"},
+		{"attempt not saved", mock.CtxFailSavePaymentAttempt, testOrderID, "success.url", "c.url"},
 	}
 
 	for _, tt := range tests {
@@ -91,62 +111,90 @@ func (suite *paymentsServiceTestSuite) TestCreateCheckout_Error() {
 				CancelURL:  tt.cancelURL,
 			}
 
-			got, err := suite.svc.CreateCheckout(context.Background(), tt.orderID, req)
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+			got, err := suite.svc.CreateCheckout(ctx, tt.orderID, req)
 			suite.Require().Error(err)
 			suite.Nil(got)
 		})
 	}
 }
 
-func (suite *paymentsServiceTestSuite) TestHandleWebhookSuccess_Success() {
-	payload := []byte(`{"payment_secret": "secret"}`)
-	header := http.Header{
-		"Payment-Signature": []string{"signature"},
-	}
+func (suite *paymentsServiceTestSuite) TestGetOrderPayments_Success() {
+	got, err := suite.svc.GetOrderPayments(
+		context.Background(),
+		testOrderID,
+		"",
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
 
-	want := &dto.PaymentDto{
-		ID:                testPaymentID,
-		OrderID:           testOrderID,
-		AmountInCents:     testAmount,
-		Provider:          testPaymentProvider,
-		ProviderPaymentID: testProviderPaymentID,
-		Currency:          testCurrency,
-	}
+	suite.Require().Len(got.Attempts, 1)
+	suite.Equal(testPaymentAttemptID, got.Attempts[0].ID)
+	suite.Equal(testProviderSessionID, got.Attempts[0].ProviderSessionID)
+	suite.Equal(db.OrdersPaymentAttemptStatusSucceeded, got.Attempts[0].Status)
+
+	suite.Require().Len(got.Payments, 1)
+	suite.Equal(testPaymentID, got.Payments[0].ID)
+	suite.NotNil(got.Payments[0].RecordedBy)
+}
 
-	got, err := suite.svc.HandleWebhookSuccess(context.Background(), payload, header)
+func (suite *paymentsServiceTestSuite) TestGetOrderPayments_Guest() {
+	session, err := mock.NewTableSessions().Issue(testOrderID, testTableID)
 	suite.Require().NoError(err)
-	suite.Equal(want, got)
+
+	got, err := suite.svc.GetOrderPayments(context.Background(), testOrderID, session.Token, nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(got.Payments, 1)
+	suite.Equal(testPaymentID, got.Payments[0].ID)
+	suite.Nil(got.Payments[0].RecordedBy)
 }
 
-func (suite *paymentsServiceTestSuite) TestHandleWebhookSuccess_Error() {
-	tests := []struct {
-		name       string
-		ctxFailKey mock.CtxKey
-		payload    []byte
+func (suite *paymentsServiceTestSuite) TestGetOrderPayments_Unauthorized() {
+	otherSession, err := mock.NewTableSessions().Issue(testCompletedOrderID, testTableID)
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name         string
+		sessionToken string
+		claims       *authDto.TokenClaimsDto
+		wantErr      error
 	}{
-		{"empty payload", "none", nil},
-		{"save payment failed", "none", []byte("1")},
+		{"no session or user", "", nil, ErrOrderConnectionUnauthorized},
+		{"session of another order", otherSession.Token, nil, ErrTableSessionNotForOrder},
 		{
-			"repo failed to update order",
-			mock.CtxFailUpdateOrder,
-			[]byte(`{"payment_secret": "secret"}`),
+			"user from another restaurant",
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+			ErrUserIsNotRestaurantStaff,
 		},
 	}
 
-	for _, tt := range tests {
-		suite.T().Run(tt.name, func(_ *testing.T) {
-			header := http.Header{
-				"Payment-Signature": []string{"signature"},
-			}
-
-			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
-			got, err := suite.svc.HandleWebhookSuccess(ctx, tt.payload, header)
-			suite.Require().Error(err)
+	for _, tt := range testCases {
+		suite.Run(tt.name, func() {
+			got, err := suite.svc.GetOrderPayments(
+				context.Background(),
+				testOrderID,
+				tt.sessionToken,
+				tt.claims,
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
 			suite.Nil(got)
 		})
 	}
 }
 
+func (suite *paymentsServiceTestSuite) TestGetOrderPayments_Error() {
+	got, err := suite.svc.GetOrderPayments(
+		context.Background(),
+		uuid.Max,
+		"",
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Nil(got)
+}
+
 func (suite *paymentsServiceTestSuite) TestCanPayForOrder_Status() {
 	testCases := []struct {
 		desc        string
@@ -212,3 +260,93 @@ func (suite *paymentsServiceTestSuite) TestCanPayForOrder_Amount() {
 		})
 	}
 }
+
+func (suite *paymentsServiceTestSuite) TestSettleOrder_Success() {
+	tests := []struct {
+		name   string
+		ctxKey mock.CtxKey
+	}{
+		{"paid in full", "none"},
+		{"underpaid order flagged", mock.CtxUnderpaidOrder},
+		{"overpaid order flagged", mock.CtxOverpaidOrder},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+			err := settleOrder(ctx, suite.svc.ordersRepo, suite.svc.paymentsRepo, testOrderID)
+			suite.Require().NoError(err)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestSettleOrder_SavesDiscounts() {
+	tests := []struct {
+		name      string
+		ctxKey    mock.CtxKey
+		wantSaved bool
+	}{
+		{"open order completed", "none", true},
+		{"locked order completed", mock.CtxLockedOrder, false},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ordersRepo := mock.NewMockOrdersRepo()
+
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+			err := settleOrder(ctx, ordersRepo, suite.svc.paymentsRepo, testOrderID)
+			suite.Require().NoError(err)
+
+			_, saved := ordersRepo.SavedDiscounts(testOrderID)
+			suite.Equal(tt.wantSaved, saved)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestSettleOrder_Error() {
+	tests := []struct {
+		name    string
+		ctxKeys []mock.CtxKey
+		orderID uuid.UUID
+	}{
+		{"repo failed getting order", nil, uuid.Max},
+		{
+			"repo failed flagging underpaid order",
+			[]mock.CtxKey{mock.CtxUnderpaidOrder, mock.CtxFailSetOrderPaymentReview},
+			testOrderID,
+		},
+		{
+			"repo failed saving order discounts",
+			[]mock.CtxKey{mock.CtxFailSaveOrderDiscounts},
+			testOrderID,
+		},
+		{"repo failed completing order", []mock.CtxKey{mock.CtxFailUpdateOrder}, testOrderID},
+		{"repo failed issuing receipt", []mock.CtxKey{mock.CtxFailIssueReceipt}, testOrderID},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.Background()
+			for _, key := range tt.ctxKeys {
+				ctx = context.WithValue(ctx, key, true)
+			}
+
+			err := settleOrder(ctx, suite.svc.ordersRepo, suite.svc.paymentsRepo, tt.orderID)
+			suite.Require().Error(err)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestCanPayForOrder_AlreadyPaid() {
+	order := &dto.OrderDto{
+		Status:            db.OrderStatusLocked,
+		TotalPriceInCents: testAmount,
+		TipAmountInCents:  testAmount,
+		AmountPaidInCents: testAmount * 2,
+	}
+
+	got, err := suite.svc.canPayForOrder(order)
+	suite.Require().ErrorIs(err, ErrOrderAlreadyPaid)
+	suite.False(got)
+}
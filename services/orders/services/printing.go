@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/printing"
+	"golang-dining-ordering/services/orders/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNothingToPrint is returned when all order's items were already sent to the kitchen.
+	ErrNothingToPrint = errors.New("order has no items to send to the kitchen")
+	// ErrNoKitchenPrinter is returned when no restaurant's printer takes order's items.
+	ErrNoKitchenPrinter = errors.New("restaurant has no printer for these items")
+	// ErrNoReceiptPrinter is returned when restaurant has no printer that prints receipts.
+	ErrNoReceiptPrinter = errors.New("restaurant has no receipt printer")
+	// ErrPrinterUnavailable is returned when print job couldn't be sent to a printer.
+	ErrPrinterUnavailable = errors.New("printer is unavailable")
+	// ErrOrderIsCancelled is returned when printing tickets of a cancelled order.
+	ErrOrderIsCancelled = errors.New("order is cancelled")
+)
+
+// PrintingService defines business logic methods for restaurants' printers, kitchen tickets
+// and printed receipts.
+type PrintingService interface {
+	GetPrinters(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.PrinterDto, error)
+	CreatePrinter(
+		ctx context.Context,
+		reqDto *dto.CreatePrinterRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.PrinterDto, error)
+	DeletePrinter(
+		ctx context.Context,
+		restaurantID, printerID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) error
+	PrintKitchenTickets(
+		ctx context.Context,
+		orderID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.KitchenTicketDto, error)
+	PrintReceipt(ctx context.Context, orderID uuid.UUID, claims *authDto.TokenClaimsDto) error
+}
+
+type printingService struct {
+	ordersRepo   repository.OrdersRepo
+	printersRepo repository.PrintersRepo
+	receiptsSvc  ReceiptsService
+	printTimeout time.Duration
+}
+
+// NewPrintingService creates a new printing service instance. Print jobs are sent to
+// printers over TCP and have to be sent within the print timeout.
+//
+//revive:disable:unexported-return
+func NewPrintingService(
+	ordersRepo repository.OrdersRepo,
+	printersRepo repository.PrintersRepo,
+	receiptsSvc ReceiptsService,
+	printTimeout time.Duration,
+) *printingService {
+	return &printingService{
+		ordersRepo:   ordersRepo,
+		printersRepo: printersRepo,
+		receiptsSvc:  receiptsSvc,
+		printTimeout: printTimeout,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (s *printingService) GetPrinters(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.PrinterDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	printers, err := s.printersRepo.GetPrinters(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting printers: %w", err)
+	}
+
+	return printers, nil
+}
+
+func (s *printingService) CreatePrinter(
+	ctx context.Context,
+	reqDto *dto.CreatePrinterRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.PrinterDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	if reqDto.Port == 0 {
+		reqDto.Port = printing.DefaultPort
+	}
+
+	if reqDto.CategoryIDs == nil {
+		reqDto.CategoryIDs = []uuid.UUID{}
+	}
+
+	printer, err := s.printersRepo.CreatePrinter(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("creating printer: %w", err)
+	}
+
+	return printer, nil
+}
+
+func (s *printingService) DeletePrinter(
+	ctx context.Context,
+	restaurantID, printerID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) error {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	err = s.printersRepo.DeletePrinter(ctx, restaurantID, printerID)
+	if err != nil {
+		return fmt.Errorf("deleting printer: %w", err)
+	}
+
+	return nil
+}
+
+// PrintKitchenTickets prints order's items which weren't sent to the kitchen yet, each
+// printer gets a ticket with items of its categories. Items of tickets that failed to print
+// are sent again next time.
+func (s *printingService) PrintKitchenTickets(
+	ctx context.Context,
+	orderID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.KitchenTicketDto, error) {
+	order, err := s.ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	err = s.ordersRepo.IsUserRestaurantWaiter(ctx, claims.UserID, order.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotWaiter, err)
+	}
+
+	if order.Status == db.OrderStatusCancelled {
+		return nil, ErrOrderIsCancelled
+	}
+
+	printers, err := s.printersRepo.GetPrinters(ctx, order.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting printers: %w", err)
+	}
+
+	kitchenOrder, err := s.printersRepo.ClaimKitchenItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("claiming kitchen items: %w", err)
+	}
+
+	if len(kitchenOrder.Items) == 0 {
+		return nil, ErrNothingToPrint
+	}
+
+	routes, unrouted := printing.RouteKitchenItems(kitchenOrder.Items, printers)
+	if len(routes) == 0 {
+		err = s.releaseKitchenItems(ctx, unrouted)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrNoKitchenPrinter
+	}
+
+	tickets := make([]*dto.KitchenTicketDto, 0, len(routes))
+
+	var printErrs []error
+
+	for _, route := range routes {
+		ticket := &dto.KitchenTicketDto{
+			PrinterID:   route.Printer.ID,
+			PrinterName: route.Printer.Name,
+			OrderID:     orderID,
+			TableName:   kitchenOrder.TableName,
+			PrintedAt:   time.Now(),
+			Lines:       printing.TicketLines(route.Items),
+		}
+
+		err = s.print(ctx, route.Printer, printing.KitchenTicket(ticket))
+		if err != nil {
+			printErrs = append(printErrs, err)
+
+			err = s.releaseKitchenItems(ctx, route.Items)
+			if err != nil {
+				printErrs = append(printErrs, err)
+			}
+
+			continue
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	// items no printer takes stay unsent, so they are printed once such printer is added
+	err = s.releaseKitchenItems(ctx, unrouted)
+
+	if len(printErrs) > 0 {
+		printErrs = append(printErrs, err)
+
+		return tickets, fmt.Errorf("%w: %w", ErrPrinterUnavailable, errors.Join(printErrs...))
+	}
+
+	if err != nil {
+		return tickets, err
+	}
+
+	return tickets, nil
+}
+
+// PrintReceipt prints receipt of the completed order on restaurant's receipt printer.
+func (s *printingService) PrintReceipt(
+	ctx context.Context,
+	orderID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) error {
+	order, err := s.ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("getting order: %w", err)
+	}
+
+	err = s.ordersRepo.IsUserRestaurantWaiter(ctx, claims.UserID, order.RestaurantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIsNotWaiter, err)
+	}
+
+	printers, err := s.printersRepo.GetPrinters(ctx, order.RestaurantID)
+	if err != nil {
+		return fmt.Errorf("getting printers: %w", err)
+	}
+
+	var receiptPrinter *dto.PrinterDto
+
+	for _, printer := range printers {
+		if printer.PrintsReceipts {
+			receiptPrinter = printer
+
+			break
+		}
+	}
+
+	if receiptPrinter == nil {
+		return ErrNoReceiptPrinter
+	}
+
+	receipt, err := s.receiptsSvc.GetReceipt(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	err = s.print(ctx, receiptPrinter, printing.Receipt(receipt))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPrinterUnavailable, err)
+	}
+
+	return nil
+}
+
+func (s *printingService) print(ctx context.Context, printer *dto.PrinterDto, job []byte) error {
+	err := printing.NewTCPPrinter(printer.Host, printer.Port, s.printTimeout).Print(ctx, job)
+	if err != nil {
+		return fmt.Errorf("printing on %s: %w", printer.Name, err)
+	}
+
+	return nil
+}
+
+// releaseKitchenItems makes items to be sent to the kitchen again, even when the request
+// that claimed them was cancelled.
+func (s *printingService) releaseKitchenItems(
+	ctx context.Context,
+	items []*dto.KitchenItemDto,
+) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	orderItemIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		orderItemIDs = append(orderItemIDs, item.OrderItemID)
+	}
+
+	err := s.printersRepo.ReleaseKitchenItems(context.WithoutCancel(ctx), orderItemIDs)
+	if err != nil {
+		return fmt.Errorf("releasing kitchen items: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type printingServiceTestSuite struct {
+	suite.Suite
+}
+
+func TestPrintingServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(printingServiceTestSuite))
+}
+
+// listen starts a stand-in for restaurant's networked printers, print jobs it receives are
+// sent to the returned channel.
+func (suite *printingServiceTestSuite) listen() (*net.TCPAddr, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { _ = listener.Close() })
+
+	jobs := make(chan []byte, 10) //nolint:mnd
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			job, _ := io.ReadAll(conn)
+			_ = conn.Close()
+			jobs <- job
+		}
+	}()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	suite.Require().True(ok)
+
+	return addr, jobs
+}
+
+// unavailableAddr returns address nothing listens on.
+func (suite *printingServiceTestSuite) unavailableAddr() *net.TCPAddr {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	suite.Require().True(ok)
+	suite.Require().NoError(listener.Close())
+
+	return addr
+}
+
+func (suite *printingServiceTestSuite) newService(addr *net.TCPAddr) *printingService {
+	return NewPrintingService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPrintersRepo(addr.IP.String(), addr.Port),
+		suite.newReceiptsService(),
+		time.Second,
+	)
+}
+
+func (suite *printingServiceTestSuite) newReceiptsService() ReceiptsService {
+	return NewReceiptsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		mock.NewMockReceiptsRepo(),
+		nil,
+		mock.NewTableSessions(),
+	)
+}
+
+func (suite *printingServiceTestSuite) receive(jobs <-chan []byte) string {
+	select {
+	case job := <-jobs:
+		return string(job)
+	case <-time.After(time.Second):
+		suite.Fail("printer didn't receive the job")
+
+		return ""
+	}
+}
+
+func (suite *printingServiceTestSuite) TestPrintKitchenTickets_Success() {
+	addr, jobs := suite.listen()
+	svc := suite.newService(addr)
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	tickets, err := svc.PrintKitchenTickets(context.Background(), testOrderID, claims)
+	suite.Require().NoError(err)
+	suite.Require().Len(tickets, 1)
+	suite.Equal("Kitchen", tickets[0].PrinterName)
+	suite.Equal("T1", tickets[0].TableName)
+	suite.Equal([]*dto.KitchenTicketLineDto{{Name: testItemName, Quantity: 1}}, tickets[0].Lines)
+
+	job := suite.receive(jobs)
+	suite.Contains(job, "Table T1")
+	suite.Contains(job, "1 x "+testItemName)
+
+	_, err = svc.PrintKitchenTickets(context.Background(), testOrderID, claims)
+	suite.Require().ErrorIs(err, ErrNothingToPrint)
+}
+
+func (suite *printingServiceTestSuite) TestPrintKitchenTickets_PrinterUnavailable() {
+	svc := suite.newService(suite.unavailableAddr())
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	_, err := svc.PrintKitchenTickets(context.Background(), testOrderID, claims)
+	suite.Require().ErrorIs(err, ErrPrinterUnavailable)
+
+	// items of the failed ticket are sent again once the printer is back
+	addr, jobs := suite.listen()
+	svc.printersRepo = mock.NewMockPrintersRepo(addr.IP.String(), addr.Port)
+
+	tickets, err := svc.PrintKitchenTickets(context.Background(), testOrderID, claims)
+	suite.Require().NoError(err)
+	suite.Len(tickets, 1)
+	suite.Contains(suite.receive(jobs), testItemName)
+}
+
+func (suite *printingServiceTestSuite) TestPrintKitchenTickets_Error() {
+	addr, _ := suite.listen()
+
+	tests := []struct {
+		desc    string
+		ctxKey  mock.CtxKey
+		orderID uuid.UUID
+		userID  uuid.UUID
+		err     error
+	}{
+		{"unknown order", "none", uuid.New(), testUserID, mock.ErrRepoFailed},
+		{
+			"user is not waiter",
+			"none",
+			testOrderID,
+			testUserFromAnotherRestaurantID,
+			ErrUserIsNotWaiter,
+		},
+		{
+			"get printers failed",
+			mock.CtxFailGetPrinters,
+			testOrderID,
+			testUserID,
+			mock.ErrRepoFailed,
+		},
+		{
+			"claim items failed",
+			mock.CtxFailClaimKitchenItems,
+			testOrderID,
+			testUserID,
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+			claims := &authDto.TokenClaimsDto{UserID: tt.userID}
+
+			_, err := suite.newService(addr).PrintKitchenTickets(ctx, tt.orderID, claims)
+			suite.Require().ErrorIs(err, tt.err)
+		})
+	}
+}
+
+func (suite *printingServiceTestSuite) TestPrintKitchenTickets_NoKitchenPrinter() {
+	addr, _ := suite.listen()
+	svc := suite.newService(addr)
+	manager := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	printers, err := svc.GetPrinters(context.Background(), testRestaurantID, manager)
+	suite.Require().NoError(err)
+
+	for _, printer := range printers {
+		if !printer.PrintsReceipts {
+			err = svc.DeletePrinter(context.Background(), testRestaurantID, printer.ID, manager)
+			suite.Require().NoError(err)
+		}
+	}
+
+	_, err = svc.PrintKitchenTickets(context.Background(), testOrderID, manager)
+	suite.Require().ErrorIs(err, ErrNoKitchenPrinter)
+}
+
+func (suite *printingServiceTestSuite) TestPrintReceipt_Success() {
+	addr, jobs := suite.listen()
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	err := suite.newService(addr).PrintReceipt(context.Background(), testCompletedOrderID, claims)
+	suite.Require().NoError(err)
+
+	job := suite.receive(jobs)
+	suite.Contains(job, "000042")
+	suite.Contains(job, "TOTAL EUR")
+}
+
+func (suite *printingServiceTestSuite) TestPrintReceipt_Error() {
+	tests := []struct {
+		desc    string
+		addr    *net.TCPAddr
+		orderID uuid.UUID
+		err     error
+	}{
+		{"order not completed", nil, testOrderID, ErrOrderIsNotCompleted},
+		{
+			"printer unavailable",
+			suite.unavailableAddr(),
+			testCompletedOrderID,
+			ErrPrinterUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			addr := tt.addr
+			if addr == nil {
+				addr, _ = suite.listen()
+			}
+
+			claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+			err := suite.newService(addr).PrintReceipt(context.Background(), tt.orderID, claims)
+			suite.Require().ErrorIs(err, tt.err)
+		})
+	}
+}
+
+func (suite *printingServiceTestSuite) TestCreatePrinter() {
+	svc := suite.newService(suite.unavailableAddr())
+	manager := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	printer, err := svc.CreatePrinter(context.Background(), &dto.CreatePrinterRequestDto{
+		RestaurantID:   testRestaurantID,
+		Name:           "Grill",
+		Host:           "192.168.1.50",
+		Port:           0,
+		CategoryIDs:    nil,
+		PrintsReceipts: false,
+	}, manager)
+	suite.Require().NoError(err)
+	suite.Equal(9100, printer.Port)
+	suite.Equal([]uuid.UUID{}, printer.CategoryIDs)
+
+	_, err = svc.CreatePrinter(context.Background(), &dto.CreatePrinterRequestDto{
+		RestaurantID:   testRestaurantID,
+		Name:           "Grill",
+		Host:           "192.168.1.51",
+		Port:           9100,
+		CategoryIDs:    nil,
+		PrintsReceipts: false,
+	}, manager)
+	suite.Require().ErrorIs(err, repository.ErrPrinterAlreadyExists)
+
+	_, err = svc.GetPrinters(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+
+	err = svc.DeletePrinter(context.Background(), testRestaurantID, uuid.New(), manager)
+	suite.Require().ErrorIs(err, repository.ErrPrinterDoesNotExist)
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+
+	"github.com/google/uuid"
+)
+
+// PromotionsService defines business logic methods for restaurants' promo codes and automatic
+// promotions.
+type PromotionsService interface {
+	GetPromoCodes(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.PromoCodeDto, error)
+	CreatePromoCode(
+		ctx context.Context,
+		reqDto *dto.CreatePromoCodeRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.PromoCodeDto, error)
+	DeletePromoCode(
+		ctx context.Context,
+		restaurantID, promoCodeID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) error
+	GetPromotionRules(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.PromotionRuleDto, error)
+	CreatePromotionRule(
+		ctx context.Context,
+		reqDto *dto.CreatePromotionRuleRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.PromotionRuleDto, error)
+	DeletePromotionRule(
+		ctx context.Context,
+		restaurantID, ruleID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) error
+}
+
+var (
+	// ErrInvalidDiscountValue is returned when percentage promo code takes off more than 100%.
+	ErrInvalidDiscountValue = errors.New("percentage discount cannot be over 100")
+	// ErrInvalidValidityWindow is returned when promo code's validity ends before it starts.
+	ErrInvalidValidityWindow = errors.New("promo code validity must end after it starts")
+)
+
+// defaultPromotionTimezone is used by promotion rules created without timezone.
+const defaultPromotionTimezone = "UTC"
+
+type promotionsService struct {
+	ordersRepo     repository.OrdersRepo
+	promotionsRepo repository.PromotionsRepo
+}
+
+// NewPromotionsService creates a new promotions service instance.
+//
+//revive:disable:unexported-return
+func NewPromotionsService(
+	ordersRepo repository.OrdersRepo,
+	promotionsRepo repository.PromotionsRepo,
+) *promotionsService {
+	return &promotionsService{
+		ordersRepo:     ordersRepo,
+		promotionsRepo: promotionsRepo,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (s *promotionsService) GetPromoCodes(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.PromoCodeDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	codes, err := s.promotionsRepo.GetPromoCodes(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting promo codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+func (s *promotionsService) CreatePromoCode(
+	ctx context.Context,
+	reqDto *dto.CreatePromoCodeRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.PromoCodeDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	if reqDto.DiscountType == db.OrdersDiscountTypePercentage && reqDto.DiscountValue > 100 {
+		return nil, ErrInvalidDiscountValue
+	}
+
+	if reqDto.ValidFrom != nil && reqDto.ValidUntil != nil &&
+		!reqDto.ValidUntil.After(*reqDto.ValidFrom) {
+		return nil, ErrInvalidValidityWindow
+	}
+
+	reqDto.Code = normalizePromoCode(reqDto.Code)
+
+	code, err := s.promotionsRepo.CreatePromoCode(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("creating promo code: %w", err)
+	}
+
+	return code, nil
+}
+
+func (s *promotionsService) DeletePromoCode(
+	ctx context.Context,
+	restaurantID, promoCodeID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) error {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	err = s.promotionsRepo.DeletePromoCode(ctx, restaurantID, promoCodeID)
+	if err != nil {
+		return fmt.Errorf("deleting promo code: %w", err)
+	}
+
+	return nil
+}
+
+func (s *promotionsService) GetPromotionRules(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.PromotionRuleDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	rules, err := s.promotionsRepo.GetPromotionRules(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting promotion rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (s *promotionsService) CreatePromotionRule(
+	ctx context.Context,
+	reqDto *dto.CreatePromotionRuleRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.PromotionRuleDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	if reqDto.Timezone == "" {
+		reqDto.Timezone = defaultPromotionTimezone
+	}
+
+	rule, err := s.promotionsRepo.CreatePromotionRule(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("creating promotion rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *promotionsService) DeletePromotionRule(
+	ctx context.Context,
+	restaurantID, ruleID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) error {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	err = s.promotionsRepo.DeletePromotionRule(ctx, restaurantID, ruleID)
+	if err != nil {
+		return fmt.Errorf("deleting promotion rule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+//nolint:gochecknoglobals
+var (
+	testPromoCodeID     = uuid.MustParse("c0c0c0c0-c0c0-4c0c-8c0c-c0c0c0c0c0c0")
+	testPromotionRuleID = uuid.MustParse("d0d0d0d0-d0d0-4d0d-8d0d-d0d0d0d0d0d0")
+)
+
+type promotionsServiceTestSuite struct {
+	suite.Suite
+
+	svc *promotionsService
+}
+
+func TestPromotionsServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(promotionsServiceTestSuite))
+}
+
+func (suite *promotionsServiceTestSuite) SetupTest() {
+	suite.svc = NewPromotionsService(mock.NewMockOrdersRepo(), mock.NewMockPromotionsRepo())
+}
+
+func (suite *promotionsServiceTestSuite) TestGetPromoCodes() {
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	codes, err := suite.svc.GetPromoCodes(context.Background(), testRestaurantID, claims)
+	suite.Require().NoError(err)
+	suite.Len(codes, 1)
+
+	_, err = suite.svc.GetPromoCodes(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+
+	_, err = suite.svc.GetPromoCodes(
+		context.WithValue(context.Background(), mock.CtxFailGetPromoCodes, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+}
+
+func (suite *promotionsServiceTestSuite) TestCreatePromoCode_Success() {
+	maxUses := 100
+
+	code, err := suite.svc.CreatePromoCode(
+		context.Background(),
+		&dto.CreatePromoCodeRequestDto{
+			RestaurantID:    testRestaurantID,
+			Code:            "summer25",
+			DiscountType:    db.OrdersDiscountTypePercentage,
+			DiscountValue:   25,
+			MinSpendInCents: 2000,
+			MaxUses:         &maxUses,
+		},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal("SUMMER25", code.Code)
+	suite.Equal(25, code.DiscountValue)
+	suite.Equal(&maxUses, code.MaxUses)
+}
+
+func (suite *promotionsServiceTestSuite) TestCreatePromoCode_Error() {
+	validFrom := testDateTime
+	validUntil := testDateTime.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		userID  uuid.UUID
+		reqDto  dto.CreatePromoCodeRequestDto
+		wantErr error
+	}{
+		{
+			"user is not manager",
+			context.Background(),
+			testUserFromAnotherRestaurantID,
+			dto.CreatePromoCodeRequestDto{Code: "NEW", DiscountType: db.OrdersDiscountTypeFixed},
+			ErrUserIsNotManager,
+		},
+		{
+			"percentage over 100",
+			context.Background(),
+			testUserID,
+			dto.CreatePromoCodeRequestDto{
+				Code:          "NEW",
+				DiscountType:  db.OrdersDiscountTypePercentage,
+				DiscountValue: 101,
+			},
+			ErrInvalidDiscountValue,
+		},
+		{
+			"validity ends before it starts",
+			context.Background(),
+			testUserID,
+			dto.CreatePromoCodeRequestDto{
+				Code:         "NEW",
+				DiscountType: db.OrdersDiscountTypeFixed,
+				ValidFrom:    &validFrom,
+				ValidUntil:   &validUntil,
+			},
+			ErrInvalidValidityWindow,
+		},
+		{
+			"code already exists",
+			context.Background(),
+			testUserID,
+			dto.CreatePromoCodeRequestDto{Code: "save50", DiscountType: db.OrdersDiscountTypeFixed},
+			repository.ErrPromoCodeAlreadyExists,
+		},
+		{
+			"repo failed",
+			context.WithValue(context.Background(), mock.CtxFailCreatePromoCode, true),
+			testUserID,
+			dto.CreatePromoCodeRequestDto{Code: "NEW", DiscountType: db.OrdersDiscountTypeFixed},
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			tt.reqDto.RestaurantID = testRestaurantID
+			tt.reqDto.DiscountValue = max(tt.reqDto.DiscountValue, 1)
+
+			_, err := suite.svc.CreatePromoCode(
+				tt.ctx,
+				&tt.reqDto,
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+		})
+	}
+}
+
+func (suite *promotionsServiceTestSuite) TestDeletePromoCode() {
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	err := suite.svc.DeletePromoCode(
+		context.Background(),
+		testRestaurantID,
+		testPromoCodeID,
+		claims,
+	)
+	suite.Require().NoError(err)
+
+	err = suite.svc.DeletePromoCode(context.Background(), testRestaurantID, uuid.New(), claims)
+	suite.Require().ErrorIs(err, repository.ErrPromoCodeDoesNotExist)
+
+	err = suite.svc.DeletePromoCode(
+		context.Background(),
+		testRestaurantID,
+		testPromoCodeID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+}
+
+func (suite *promotionsServiceTestSuite) TestGetPromotionRules() {
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	rules, err := suite.svc.GetPromotionRules(context.Background(), testRestaurantID, claims)
+	suite.Require().NoError(err)
+	suite.Len(rules, 1)
+
+	_, err = suite.svc.GetPromotionRules(
+		context.WithValue(context.Background(), mock.CtxFailGetPromotionRules, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+}
+
+func (suite *promotionsServiceTestSuite) TestCreatePromotionRule() {
+	reqDto := &dto.CreatePromotionRuleRequestDto{
+		RestaurantID: testRestaurantID,
+		Name:         "2-for-1 drinks",
+		RuleType:     db.OrdersPromotionRuleTypeBuyXGetY,
+		BuyQuantity:  1,
+		FreeQuantity: 1,
+		StartsAt:     "17:00",
+		EndsAt:       "19:00",
+	}
+
+	rule, err := suite.svc.CreatePromotionRule(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(defaultPromotionTimezone, rule.Timezone)
+	suite.Equal("17:00", rule.StartsAt)
+
+	_, err = suite.svc.CreatePromotionRule(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+
+	_, err = suite.svc.CreatePromotionRule(
+		context.WithValue(context.Background(), mock.CtxFailCreatePromotionRule, true),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+}
+
+func (suite *promotionsServiceTestSuite) TestDeletePromotionRule() {
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	err := suite.svc.DeletePromotionRule(
+		context.Background(),
+		testRestaurantID,
+		testPromotionRuleID,
+		claims,
+	)
+	suite.Require().NoError(err)
+
+	err = suite.svc.DeletePromotionRule(context.Background(), testRestaurantID, uuid.New(), claims)
+	suite.Require().ErrorIs(err, repository.ErrPromotionRuleDoesNotExist)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
+
+	"github.com/google/uuid"
+)
+
+// PaymentProvidersService defines business logic methods for restaurants' payment provider settings.
+type PaymentProvidersService interface {
+	GetRestaurantPaymentProviders(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) ([]*dto.RestaurantPaymentProviderDto, error)
+	SetRestaurantPaymentProvider(
+		ctx context.Context,
+		reqDto *dto.SetPaymentProviderRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.RestaurantPaymentProviderDto, error)
+	DeleteRestaurantPaymentProvider(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		provider db.OrdersPaymentProvider,
+		claims *authDto.TokenClaimsDto,
+	) error
+}
+
+type paymentProvidersService struct {
+	ordersRepo    repository.OrdersRepo
+	providersRepo repository.PaymentProvidersRepo
+	providers     paymentproviders.Registry
+}
+
+// NewPaymentProvidersService creates a new payment providers service instance.
+//
+//revive:disable:unexported-return
+func NewPaymentProvidersService(
+	ordersRepo repository.OrdersRepo,
+	providersRepo repository.PaymentProvidersRepo,
+	providers paymentproviders.Registry,
+) *paymentProvidersService {
+	return &paymentProvidersService{
+		ordersRepo:    ordersRepo,
+		providersRepo: providersRepo,
+		providers:     providers,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (s *paymentProvidersService) GetRestaurantPaymentProviders(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.RestaurantPaymentProviderDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	providers, err := s.providersRepo.GetRestaurantPaymentProviders(ctx, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting restaurant payment providers: %w", err)
+	}
+
+	for _, p := range providers {
+		p.WebhookURL = s.providers.WebhookURL(restaurantID, p.Provider)
+	}
+
+	return providers, nil
+}
+
+func (s *paymentProvidersService) SetRestaurantPaymentProvider(
+	ctx context.Context,
+	reqDto *dto.SetPaymentProviderRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.RestaurantPaymentProviderDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	err = paymentproviders.ValidateCredentials(reqDto.Provider, reqDto.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("validating payment provider credentials: %w", err)
+	}
+
+	respDto, err := s.providersRepo.SaveRestaurantPaymentProvider(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("saving restaurant payment provider: %w", err)
+	}
+
+	respDto.WebhookURL = s.providers.WebhookURL(reqDto.RestaurantID, reqDto.Provider)
+
+	return respDto, nil
+}
+
+func (s *paymentProvidersService) DeleteRestaurantPaymentProvider(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+	claims *authDto.TokenClaimsDto,
+) error {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	err = s.providersRepo.DeleteRestaurantPaymentProvider(ctx, restaurantID, provider)
+	if err != nil {
+		return fmt.Errorf("deleting restaurant payment provider: %w", err)
+	}
+
+	return nil
+}
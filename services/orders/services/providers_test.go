@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type paymentProvidersServiceTestSuite struct {
+	suite.Suite
+
+	svc *paymentProvidersService
+}
+
+func (suite *paymentProvidersServiceTestSuite) SetupSuite() {
+	suite.svc = NewPaymentProvidersService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentProvidersRepo(),
+		mock.NewMockProvidersRegistry(),
+	)
+}
+
+func TestPaymentProvidersServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(paymentProvidersServiceTestSuite))
+}
+
+func (suite *paymentProvidersServiceTestSuite) TestGetRestaurantPaymentProviders_Success() {
+	got, err := suite.svc.GetRestaurantPaymentProviders(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Require().Len(got, 1)
+
+	suite.Equal(db.OrdersPaymentProviderStripe, got[0].Provider)
+	suite.True(got[0].IsDefault)
+	suite.Equal(
+		"http://fake-webhooks.com/stripe/"+testRestaurantID.String(),
+		got[0].WebhookURL,
+	)
+}
+
+func (suite *paymentProvidersServiceTestSuite) TestGetRestaurantPaymentProviders_NotManager() {
+	got, err := suite.svc.GetRestaurantPaymentProviders(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+	suite.Nil(got)
+}
+
+func (suite *paymentProvidersServiceTestSuite) TestSetRestaurantPaymentProvider_Success() {
+	reqDto := &dto.SetPaymentProviderRequestDto{
+		RestaurantID: testRestaurantID,
+		Provider:     db.OrdersPaymentProviderStripe,
+		Credentials: &dto.ProviderCredentialsDto{
+			SecretKey:     "sk_test",
+			WebhookSecret: "whsec_test",
+		},
+		IsDefault: true,
+	}
+
+	got, err := suite.svc.SetRestaurantPaymentProvider(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+
+	suite.Equal(db.OrdersPaymentProviderStripe, got.Provider)
+	suite.True(got.IsDefault)
+	suite.Equal(
+		"http://fake-webhooks.com/stripe/"+testRestaurantID.String(),
+		got.WebhookURL,
+	)
+}
+
+func (suite *paymentProvidersServiceTestSuite) TestSetRestaurantPaymentProvider_Error() {
+	validCredentials := &dto.ProviderCredentialsDto{
+		SecretKey:     "sk_test",
+		WebhookSecret: "whsec_test",
+	}
+
+	tests := []struct {
+		name        string
+		ctxFailKey  mock.CtxKey
+		userID      uuid.UUID
+		provider    db.OrdersPaymentProvider
+		credentials *dto.ProviderCredentialsDto
+		wantErr     error
+	}{
+		{
+			"user is not manager",
+			"none",
+			testUserFromAnotherRestaurantID,
+			db.OrdersPaymentProviderStripe,
+			validCredentials,
+			ErrUserIsNotManager,
+		},
+		{
+			"missing credentials",
+			"none",
+			testUserID,
+			db.OrdersPaymentProviderStripe,
+			&dto.ProviderCredentialsDto{SecretKey: "sk_test"},
+			paymentproviders.ErrMissingCredentials,
+		},
+		{
+			"mock can't be configured",
+			"none",
+			testUserID,
+			db.OrdersPaymentProviderMock,
+			validCredentials,
+			paymentproviders.ErrProviderNotSupported,
+		},
+		{
+			"invalid klix public key",
+			"none",
+			testUserID,
+			db.OrdersPaymentProviderKlix,
+			&dto.ProviderCredentialsDto{BrandID: "b", SecretKey: "s", PublicKey: "nope"},
+			paymentproviders.ErrInvalidKlixPublicKey,
+		},
+		{
+			"repo failed",
+			mock.CtxFailSavePaymentProvider,
+			testUserID,
+			db.OrdersPaymentProviderStripe,
+			validCredentials,
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.svc.SetRestaurantPaymentProvider(
+				ctx,
+				&dto.SetPaymentProviderRequestDto{
+					RestaurantID: testRestaurantID,
+					Provider:     tt.provider,
+					Credentials:  tt.credentials,
+					IsDefault:    false,
+				},
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+			suite.Nil(got)
+		})
+	}
+}
+
+func (suite *paymentProvidersServiceTestSuite) TestDeleteRestaurantPaymentProvider() {
+	err := suite.svc.DeleteRestaurantPaymentProvider(
+		context.Background(),
+		testRestaurantID,
+		db.OrdersPaymentProviderStripe,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+
+	err = suite.svc.DeleteRestaurantPaymentProvider(
+		context.Background(),
+		testRestaurantID,
+		db.OrdersPaymentProviderKlix,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, repository.ErrPaymentProviderDoesNotExist)
+
+	err = suite.svc.DeleteRestaurantPaymentProvider(
+		context.Background(),
+		testRestaurantID,
+		db.OrdersPaymentProviderStripe,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/mailer"
+	"golang-dining-ordering/services/orders/receipts"
+	"golang-dining-ordering/services/orders/repository"
+	"golang-dining-ordering/services/orders/sessions"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrOrderIsNotCompleted is returned when receipt is requested for an order that isn't
+	// completed yet.
+	ErrOrderIsNotCompleted = errors.New("order is not completed")
+	// ErrReceiptEmailDisabled is returned when receipt is requested by email but no mailer
+	// is configured.
+	ErrReceiptEmailDisabled = errors.New("sending receipts by email is disabled")
+)
+
+// ReceiptsService defines business logic methods for receipts of completed orders.
+type ReceiptsService interface {
+	GetReceipt(ctx context.Context, orderID uuid.UUID) (*dto.ReceiptDto, error)
+	EmailReceipt(
+		ctx context.Context,
+		reqDto *dto.EmailReceiptRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) error
+	GetReceiptSettings(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.ReceiptSettingsDto, error)
+	SetReceiptSettings(
+		ctx context.Context,
+		reqDto *dto.SetReceiptSettingsRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.ReceiptSettingsDto, error)
+}
+
+type receiptsService struct {
+	ordersRepo   repository.OrdersRepo
+	paymentsRepo repository.PaymentsRepo
+	receiptsRepo repository.ReceiptsRepo
+	mailer       mailer.Mailer
+	sessions     *sessions.TableSessions
+}
+
+// NewReceiptsService creates a new receipts service instance. Receipts can't be sent by email
+// when mailer is nil, guests send them with table session of the order.
+//
+//revive:disable:unexported-return
+func NewReceiptsService(
+	ordersRepo repository.OrdersRepo,
+	paymentsRepo repository.PaymentsRepo,
+	receiptsRepo repository.ReceiptsRepo,
+	receiptsMailer mailer.Mailer,
+	tableSessions *sessions.TableSessions,
+) *receiptsService {
+	return &receiptsService{
+		ordersRepo:   ordersRepo,
+		paymentsRepo: paymentsRepo,
+		receiptsRepo: receiptsRepo,
+		mailer:       receiptsMailer,
+		sessions:     tableSessions,
+	}
+}
+
+//revive:enable:unexported-return
+
+// GetReceipt returns receipt of the completed order, its number and contents were issued when
+// the order was completed. Receipts issued before contents were saved with them get contents
+// of the order the first time they are requested.
+func (s *receiptsService) GetReceipt(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.ReceiptDto, error) {
+	order, err := s.ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	if order.Status != db.OrderStatusCompleted {
+		return nil, ErrOrderIsNotCompleted
+	}
+
+	issued, err := s.receiptsRepo.GetReceipt(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting issued receipt: %w", err)
+	}
+
+	contents := issued.Contents
+	if contents == nil {
+		contents = receiptContents(order)
+
+		err = s.receiptsRepo.SaveReceiptContents(ctx, issued.ID, contents)
+		if err != nil {
+			return nil, fmt.Errorf("saving receipt contents: %w", err)
+		}
+	}
+
+	payments, err := s.paymentsRepo.GetOrderPayments(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order payments: %w", err)
+	}
+
+	gross := contents.SubtotalInCents - contents.DiscountInCents
+
+	respDto := &dto.ReceiptDto{
+		Number:            issued.Number,
+		OrderID:           order.ID,
+		IssuedAt:          issued.IssuedAt,
+		RestaurantName:    issued.RestaurantName,
+		RestaurantAddress: issued.RestaurantAddress,
+		Currency:          contents.Currency,
+		Lines:             contents.Lines,
+		Discounts:         contents.Discounts,
+		SubtotalInCents:   contents.SubtotalInCents,
+		DiscountInCents:   contents.DiscountInCents,
+		TaxRate:           issued.TaxRate,
+		TaxInCents:        includedTax(gross, issued.TaxRate),
+		TipInCents:        contents.TipInCents,
+		TotalInCents:      gross + contents.TipInCents,
+		Payments:          make([]*dto.ReceiptPaymentDto, 0, len(payments)),
+	}
+
+	for _, payment := range payments {
+		respDto.Payments = append(respDto.Payments, &dto.ReceiptPaymentDto{
+			Method:                payment.Provider,
+			AmountInCents:         payment.AmountInCents,
+			RefundedAmountInCents: payment.RefundedAmountInCents,
+		})
+	}
+
+	return respDto, nil
+}
+
+// EmailReceipt sends receipt of the completed order to the guest with the PDF receipt attached.
+// It's requested by a guest with table session of the order or by staff of its restaurant.
+func (s *receiptsService) EmailReceipt(
+	ctx context.Context,
+	reqDto *dto.EmailReceiptRequestDto,
+	claims *authDto.TokenClaimsDto,
+) error {
+	_, err := authorizeOrderAccess(
+		ctx,
+		s.sessions,
+		s.ordersRepo,
+		reqDto.OrderID,
+		reqDto.SessionToken,
+		claims,
+	)
+	if err != nil {
+		return err
+	}
+
+	if s.mailer == nil {
+		return ErrReceiptEmailDisabled
+	}
+
+	receipt, err := s.GetReceipt(ctx, reqDto.OrderID)
+	if err != nil {
+		return err
+	}
+
+	html, err := receipts.Render(receipt, receipts.FormatHTML)
+	if err != nil {
+		return fmt.Errorf("rendering html receipt: %w", err)
+	}
+
+	text, err := receipts.Render(receipt, receipts.FormatText)
+	if err != nil {
+		return fmt.Errorf("rendering text receipt: %w", err)
+	}
+
+	pdf, err := receipts.Render(receipt, receipts.FormatPDF)
+	if err != nil {
+		return fmt.Errorf("rendering pdf receipt: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, &mailer.Message{
+		To: reqDto.Email,
+		Subject: fmt.Sprintf(
+			"Receipt %s from %s",
+			receipts.Number(receipt),
+			receipt.RestaurantName,
+		),
+		Text: string(text),
+		HTML: string(html),
+		Attachments: []*mailer.Attachment{{
+			Filename:    receipts.Filename(receipt, receipts.FormatPDF),
+			ContentType: receipts.ContentType(receipts.FormatPDF),
+			Data:        pdf,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("sending receipt email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *receiptsService) GetReceiptSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) (*dto.ReceiptSettingsDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	respDto, err := s.receiptsRepo.GetReceiptSettings(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReceiptSettingsDoNotExist) {
+			return &dto.ReceiptSettingsDto{
+				RestaurantID: restaurantID,
+				TaxRate:      0,
+				UpdatedAt:    nil,
+			}, nil
+		}
+
+		return nil, fmt.Errorf("getting receipt settings: %w", err)
+	}
+
+	return respDto, nil
+}
+
+func (s *receiptsService) SetReceiptSettings(
+	ctx context.Context,
+	reqDto *dto.SetReceiptSettingsRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.ReceiptSettingsDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	respDto, err := s.receiptsRepo.SaveReceiptSettings(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("saving receipt settings: %w", err)
+	}
+
+	return respDto, nil
+}
+
+// receiptContents returns lines, discounts and totals of the order printed on its receipt.
+func receiptContents(order *dto.OrderDto) *dto.ReceiptContentsDto {
+	return &dto.ReceiptContentsDto{
+		Currency:        order.Currency,
+		Lines:           receiptLines(order.Items),
+		Discounts:       order.Discounts,
+		SubtotalInCents: order.TotalPriceInCents,
+		DiscountInCents: order.DiscountInCents,
+		TipInCents:      order.TipAmountInCents,
+	}
+}
+
+// receiptLines groups order items of the same menu item and price into receipt lines, in the
+// order they were first added.
+func receiptLines(items []*dto.OrderItemDto) []*dto.ReceiptLineDto {
+	type lineKey struct {
+		itemID       uuid.UUID
+		priceInCents int
+	}
+
+	lines := make([]*dto.ReceiptLineDto, 0, len(items))
+	byKey := make(map[lineKey]*dto.ReceiptLineDto, len(items))
+
+	for _, item := range items {
+		key := lineKey{itemID: item.ItemID, priceInCents: item.PriceInCents}
+
+		line, ok := byKey[key]
+		if !ok {
+			line = &dto.ReceiptLineDto{
+				Name:             item.Name,
+				Quantity:         0,
+				UnitPriceInCents: item.PriceInCents,
+				AmountInCents:    0,
+			}
+			byKey[key] = line
+			lines = append(lines, line)
+		}
+
+		line.Quantity++
+		line.AmountInCents += item.PriceInCents
+	}
+
+	return lines
+}
+
+// includedTax returns tax included in the gross amount at the rate percentage.
+func includedTax(grossInCents int, rate float64) int {
+	const percent = 100
+
+	return int(math.Round(float64(grossInCents) * rate / (percent + rate)))
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/mailer"
+	"golang-dining-ordering/services/orders/repository"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+var errMailerFailed = errors.New("mailer failed")
+
+type mockMailer struct {
+	sent []*mailer.Message
+	err  error
+}
+
+func (m *mockMailer) Send(_ context.Context, message *mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.sent = append(m.sent, message)
+
+	return nil
+}
+
+type receiptsServiceTestSuite struct {
+	suite.Suite
+}
+
+func TestReceiptsServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(receiptsServiceTestSuite))
+}
+
+func (suite *receiptsServiceTestSuite) newService(m mailer.Mailer) *receiptsService {
+	return NewReceiptsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		mock.NewMockReceiptsRepo(),
+		m,
+		mock.NewTableSessions(),
+	)
+}
+
+func (suite *receiptsServiceTestSuite) TestGetReceipt_Success() {
+	got, err := suite.newService(nil).GetReceipt(context.Background(), testCompletedOrderID)
+	suite.Require().NoError(err)
+
+	suite.Equal(42, got.Number)
+	suite.Equal(testRestaurantName, got.RestaurantName)
+	suite.Equal(testCurrency, got.Currency)
+	suite.Equal([]*dto.ReceiptLineDto{{
+		Name:             testItemName,
+		Quantity:         1,
+		UnitPriceInCents: testAmount,
+		AmountInCents:    testAmount,
+	}}, got.Lines)
+	suite.Equal(testAmount, got.SubtotalInCents)
+	suite.InDelta(21.0, got.TaxRate, 0)
+	suite.Equal(2, got.TaxInCents)
+	suite.Equal(testAmount, got.TipInCents)
+	suite.Equal(testAmount*2, got.TotalInCents)
+	suite.Require().Len(got.Payments, 1)
+	suite.Equal(db.OrdersPaymentProviderMock, got.Payments[0].Method)
+}
+
+func (suite *receiptsServiceTestSuite) TestGetReceipt_SavesContents() {
+	receiptsRepo := mock.NewMockReceiptsRepo()
+	svc := NewReceiptsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		receiptsRepo,
+		nil,
+		mock.NewTableSessions(),
+	)
+
+	got, err := svc.GetReceipt(context.Background(), testCompletedOrderID)
+	suite.Require().NoError(err)
+
+	saved, ok := receiptsRepo.SavedContents(testReceiptID)
+	suite.Require().True(ok)
+	suite.Equal(got.Lines, saved.Lines)
+	suite.Equal(testAmount, saved.SubtotalInCents)
+	suite.Equal(testAmount, saved.TipInCents)
+}
+
+func (suite *receiptsServiceTestSuite) TestGetReceipt_RendersSavedContents() {
+	receiptsRepo := mock.NewMockReceiptsRepo()
+	svc := NewReceiptsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		receiptsRepo,
+		nil,
+		mock.NewTableSessions(),
+	)
+
+	discounts := []*dto.DiscountLineDto{{
+		Source:        dto.DiscountSourcePromoCode,
+		PromotionID:   uuid.New(),
+		Name:          "WELCOME",
+		AmountInCents: 121,
+		OrderItemIDs:  nil,
+	}}
+
+	// contents saved when the receipt was issued, the order has changed since
+	err := receiptsRepo.SaveReceiptContents(
+		context.Background(),
+		testReceiptID,
+		&dto.ReceiptContentsDto{
+			Currency: testCurrency,
+			Lines: []*dto.ReceiptLineDto{{
+				Name:             "Old Menu Item",
+				Quantity:         2,
+				UnitPriceInCents: 500,
+				AmountInCents:    1000,
+			}},
+			Discounts:       discounts,
+			SubtotalInCents: 1000,
+			DiscountInCents: 121,
+			TipInCents:      100,
+		},
+	)
+	suite.Require().NoError(err)
+
+	got, err := svc.GetReceipt(context.Background(), testCompletedOrderID)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(got.Lines, 1)
+	suite.Equal("Old Menu Item", got.Lines[0].Name)
+	suite.Equal(discounts, got.Discounts)
+	suite.Equal(1000, got.SubtotalInCents)
+	suite.Equal(121, got.DiscountInCents)
+	suite.Equal(153, got.TaxInCents)
+	suite.Equal(100, got.TipInCents)
+	suite.Equal(979, got.TotalInCents)
+}
+
+func (suite *receiptsServiceTestSuite) TestGetReceipt_Error() {
+	tests := []struct {
+		desc    string
+		ctxKey  mock.CtxKey
+		orderID uuid.UUID
+		err     error
+	}{
+		{"order not completed", "none", testOrderID, ErrOrderIsNotCompleted},
+		{"unknown order", "none", uuid.New(), mock.ErrRepoFailed},
+		{
+			"receipt not issued",
+			mock.CtxReceiptNotIssued,
+			testCompletedOrderID,
+			repository.ErrReceiptDoesNotExist,
+		},
+		{"get receipt failed", mock.CtxFailGetReceipt, testCompletedOrderID, mock.ErrRepoFailed},
+		{
+			"save receipt contents failed",
+			mock.CtxFailSaveReceiptContents,
+			testCompletedOrderID,
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+
+			_, err := suite.newService(nil).GetReceipt(ctx, tt.orderID)
+			suite.Require().ErrorIs(err, tt.err)
+		})
+	}
+}
+
+// testSessionToken returns table session token for the order.
+func (suite *receiptsServiceTestSuite) testSessionToken(orderID uuid.UUID) string {
+	session, err := mock.NewTableSessions().Issue(orderID, testTableID)
+	suite.Require().NoError(err)
+
+	return session.Token
+}
+
+func (suite *receiptsServiceTestSuite) TestEmailReceipt_Success() {
+	m := &mockMailer{sent: nil, err: nil}
+
+	err := suite.newService(m).EmailReceipt(context.Background(), &dto.EmailReceiptRequestDto{
+		OrderID:      testCompletedOrderID,
+		SessionToken: suite.testSessionToken(testCompletedOrderID),
+		Email:        "guest@example.com",
+	}, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(m.sent, 1)
+
+	message := m.sent[0]
+	suite.Equal("guest@example.com", message.To)
+	suite.Equal("Receipt 000042 from "+testRestaurantName, message.Subject)
+	suite.Contains(message.Text, "TOTAL EUR")
+	suite.Contains(message.HTML, "<!DOCTYPE html>")
+	suite.Require().Len(message.Attachments, 1)
+	suite.Equal("receipt-000042.pdf", message.Attachments[0].Filename)
+	suite.Equal("application/pdf", message.Attachments[0].ContentType)
+
+	err = suite.newService(m).EmailReceipt(
+		context.Background(),
+		&dto.EmailReceiptRequestDto{OrderID: testCompletedOrderID, Email: "staff@example.com"},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Require().Len(m.sent, 2)
+}
+
+func (suite *receiptsServiceTestSuite) TestEmailReceipt_Error() {
+	reqDto := &dto.EmailReceiptRequestDto{
+		OrderID:      testCompletedOrderID,
+		SessionToken: suite.testSessionToken(testCompletedOrderID),
+		Email:        "guest@example.com",
+	}
+
+	err := suite.newService(nil).EmailReceipt(context.Background(), reqDto, nil)
+	suite.Require().ErrorIs(err, ErrReceiptEmailDisabled)
+
+	err = suite.newService(&mockMailer{sent: nil, err: errMailerFailed}).
+		EmailReceipt(context.Background(), reqDto, nil)
+	suite.Require().ErrorIs(err, errMailerFailed)
+
+	reqDto.OrderID = testOrderID
+	reqDto.SessionToken = suite.testSessionToken(testOrderID)
+
+	err = suite.newService(&mockMailer{sent: nil, err: nil}).
+		EmailReceipt(context.Background(), reqDto, nil)
+	suite.Require().ErrorIs(err, ErrOrderIsNotCompleted)
+}
+
+func (suite *receiptsServiceTestSuite) TestEmailReceipt_Unauthorized() {
+	tests := []struct {
+		desc         string
+		sessionToken string
+		claims       *authDto.TokenClaimsDto
+		err          error
+	}{
+		{"no session or token", "", nil, ErrOrderConnectionUnauthorized},
+		{
+			"session of another order",
+			suite.testSessionToken(testOrderID),
+			nil,
+			ErrTableSessionNotForOrder,
+		},
+		{
+			"user is not staff",
+			"",
+			&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+			ErrUserIsNotRestaurantStaff,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.desc, func() {
+			m := &mockMailer{sent: nil, err: nil}
+
+			err := suite.newService(m).EmailReceipt(
+				context.Background(),
+				&dto.EmailReceiptRequestDto{
+					OrderID:      testCompletedOrderID,
+					SessionToken: tt.sessionToken,
+					Email:        "guest@example.com",
+				},
+				tt.claims,
+			)
+			suite.Require().ErrorIs(err, tt.err)
+			suite.Empty(m.sent)
+		})
+	}
+}
+
+func (suite *receiptsServiceTestSuite) TestGetReceiptSettings() {
+	svc := suite.newService(nil)
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	got, err := svc.GetReceiptSettings(context.Background(), testRestaurantID, claims)
+	suite.Require().NoError(err)
+	suite.InDelta(21.0, got.TaxRate, 0)
+	suite.NotNil(got.UpdatedAt)
+
+	restaurantID := uuid.New()
+
+	got, err = svc.GetReceiptSettings(context.Background(), restaurantID, claims)
+	suite.Require().NoError(err)
+	suite.Equal(restaurantID, got.RestaurantID)
+	suite.Zero(got.TaxRate)
+	suite.Nil(got.UpdatedAt)
+
+	_, err = svc.GetReceiptSettings(
+		context.WithValue(context.Background(), mock.CtxFailGetReceiptSettings, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+
+	_, err = svc.GetReceiptSettings(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+}
+
+func (suite *receiptsServiceTestSuite) TestSetReceiptSettings() {
+	svc := suite.newService(nil)
+	taxRate := 9.5
+	reqDto := &dto.SetReceiptSettingsRequestDto{RestaurantID: testRestaurantID, TaxRate: &taxRate}
+
+	got, err := svc.SetReceiptSettings(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.InDelta(taxRate, got.TaxRate, 0)
+
+	_, err = svc.SetReceiptSettings(
+		context.WithValue(context.Background(), mock.CtxFailSaveReceiptSettings, true),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+
+	_, err = svc.SetReceiptSettings(
+		context.Background(),
+		reqDto,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+}
+
+func (suite *receiptsServiceTestSuite) TestReceiptLines() {
+	otherItemID := uuid.New()
+	items := []*dto.OrderItemDto{
+		{ItemID: testItemID, Name: "Soup", PriceInCents: 500},
+		{ItemID: otherItemID, Name: "Bread", PriceInCents: 100},
+		{ItemID: testItemID, Name: "Soup", PriceInCents: 500},
+		{ItemID: testItemID, Name: "Soup", PriceInCents: 400},
+	}
+
+	suite.Equal([]*dto.ReceiptLineDto{
+		{Name: "Soup", Quantity: 2, UnitPriceInCents: 500, AmountInCents: 1000},
+		{Name: "Bread", Quantity: 1, UnitPriceInCents: 100, AmountInCents: 100},
+		{Name: "Soup", Quantity: 1, UnitPriceInCents: 400, AmountInCents: 400},
+	}, receiptLines(items))
+}
+
+func (suite *receiptsServiceTestSuite) TestIncludedTax() {
+	suite.Equal(0, includedTax(1210, 0))
+	suite.Equal(210, includedTax(1210, 21))
+	suite.Equal(52, includedTax(1100, 5))
+}
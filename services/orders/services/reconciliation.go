@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
+	"strings"
+	"time"
+)
+
+// reconciliationSlack widens the range both sides are listed in, payments are saved once
+// provider's webhook arrives, which can be a while after the charge was created.
+const reconciliationSlack = 24 * time.Hour
+
+// ErrRestaurantUsesPlatformProvider is returned when restaurant's payments are taken with
+// platform's provider account, they are reconciled with the platform's account.
+var ErrRestaurantUsesPlatformProvider = errors.New(
+	"restaurant has no own provider account, reconcile platform's account instead",
+)
+
+// ReconciliationService defines business logic for reconciling our payments with provider's charges.
+type ReconciliationService interface {
+	Reconcile(
+		ctx context.Context,
+		reqDto *dto.ReconciliationRequestDto,
+	) (*dto.ReconciliationReportDto, error)
+}
+
+type reconciliationService struct {
+	repo      repository.ReconciliationRepo
+	providers paymentproviders.Registry
+}
+
+// NewReconciliationService creates a new reconciliation service instance.
+//
+//revive:disable:unexported-return
+func NewReconciliationService(
+	repo repository.ReconciliationRepo,
+	providers paymentproviders.Registry,
+) *reconciliationService {
+	return &reconciliationService{
+		repo:      repo,
+		providers: providers,
+	}
+}
+
+//revive:enable:unexported-return
+
+// Reconcile matches payments to provider's charges by provider's payment id and reports
+// payments provider has no charge for, charges we have no payment for and amount mismatches.
+// Pair is reported when either of its sides was created in the requested range.
+func (s *reconciliationService) Reconcile(
+	ctx context.Context,
+	reqDto *dto.ReconciliationRequestDto,
+) (*dto.ReconciliationReportDto, error) {
+	provider, err := s.getProvider(ctx, reqDto)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &dto.ReconciliationRequestDto{
+		Provider:     reqDto.Provider,
+		RestaurantID: reqDto.RestaurantID,
+		From:         reqDto.From.Add(-reconciliationSlack),
+		To:           reqDto.To.Add(reconciliationSlack),
+	}
+
+	payments, err := s.repo.GetProviderPayments(ctx, window)
+	if err != nil {
+		return nil, fmt.Errorf("getting payments: %w", err)
+	}
+
+	charges, err := provider.ListCharges(ctx, &dto.ListChargesRequestDto{
+		From: window.From,
+		To:   window.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing provider charges: %w", err)
+	}
+
+	return reconcile(reqDto, payments, charges), nil
+}
+
+//nolint:ireturn
+func (s *reconciliationService) getProvider(
+	ctx context.Context,
+	reqDto *dto.ReconciliationRequestDto,
+) (paymentproviders.PaymentProvider, error) {
+	if reqDto.RestaurantID == nil {
+		provider, err := s.providers.GetPlatform(reqDto.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("getting platform payment provider: %w", err)
+		}
+
+		return provider, nil
+	}
+
+	// platform's account holds charges of every restaurant without own credentials, so only
+	// restaurant's own account can be reconciled against its payments
+	provider, err := s.providers.GetOwn(ctx, *reqDto.RestaurantID, reqDto.Provider)
+	if err != nil {
+		if errors.Is(err, paymentproviders.ErrProviderNotConfigured) {
+			return nil, fmt.Errorf(
+				"%w: %s",
+				ErrRestaurantUsesPlatformProvider,
+				*reqDto.RestaurantID,
+			)
+		}
+
+		return nil, fmt.Errorf("getting restaurant payment provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+func reconcile(
+	reqDto *dto.ReconciliationRequestDto,
+	payments []*dto.ReconciliationPaymentDto,
+	charges []*dto.ProviderChargeDto,
+) *dto.ReconciliationReportDto {
+	report := &dto.ReconciliationReportDto{
+		Provider:     reqDto.Provider,
+		RestaurantID: reqDto.RestaurantID,
+		From:         reqDto.From,
+		To:           reqDto.To,
+		Matched:      0,
+		Rows:         []*dto.ReconciliationRowDto{},
+	}
+
+	chargesByID := make(map[string]*dto.ProviderChargeDto, len(charges))
+	for _, charge := range charges {
+		chargesByID[charge.ProviderPaymentID] = charge
+	}
+
+	paymentsByID := make(map[string]*dto.ReconciliationPaymentDto, len(payments))
+
+	for _, payment := range payments {
+		paymentsByID[payment.ProviderPaymentID] = payment
+
+		charge, ok := chargesByID[payment.ProviderPaymentID]
+
+		switch {
+		case !ok:
+			if isInRange(payment.CreatedAt, reqDto) {
+				report.Rows = append(report.Rows, reconciliationRow(
+					dto.ReconciliationMissing,
+					payment,
+					nil,
+				))
+			}
+		case !isInRange(payment.CreatedAt, reqDto) && !isInRange(charge.CreatedAt, reqDto):
+			continue
+		case charge.AmountInCents != payment.AmountInCents ||
+			!strings.EqualFold(charge.Currency, payment.Currency):
+			report.Rows = append(report.Rows, reconciliationRow(
+				dto.ReconciliationAmountMismatch,
+				payment,
+				charge,
+			))
+		default:
+			report.Matched++
+		}
+	}
+
+	for _, charge := range charges {
+		_, ok := paymentsByID[charge.ProviderPaymentID]
+		if ok || !isInRange(charge.CreatedAt, reqDto) {
+			continue
+		}
+
+		report.Rows = append(report.Rows, reconciliationRow(dto.ReconciliationExtra, nil, charge))
+	}
+
+	return report
+}
+
+// reconciliationRow creates report row from the pair, either of its sides can be nil.
+func reconciliationRow(
+	issue dto.ReconciliationIssue,
+	payment *dto.ReconciliationPaymentDto,
+	charge *dto.ProviderChargeDto,
+) *dto.ReconciliationRowDto {
+	row := &dto.ReconciliationRowDto{
+		Issue:                 issue,
+		ProviderPaymentID:     "",
+		PaymentID:             nil,
+		OrderID:               nil,
+		AmountInCents:         nil,
+		Currency:              "",
+		ProviderAmountInCents: nil,
+		ProviderCurrency:      "",
+		CreatedAt:             time.Time{},
+	}
+
+	if charge != nil {
+		row.ProviderPaymentID = charge.ProviderPaymentID
+		row.ProviderAmountInCents = &charge.AmountInCents
+		row.ProviderCurrency = charge.Currency
+		row.CreatedAt = charge.CreatedAt
+	}
+
+	if payment != nil {
+		row.ProviderPaymentID = payment.ProviderPaymentID
+		row.PaymentID = &payment.ID
+		row.OrderID = &payment.OrderID
+		row.AmountInCents = &payment.AmountInCents
+		row.Currency = payment.Currency
+		row.CreatedAt = payment.CreatedAt
+	}
+
+	return row
+}
+
+func isInRange(t time.Time, reqDto *dto.ReconciliationRequestDto) bool {
+	return !t.Before(reqDto.From) && t.Before(reqDto.To)
+}
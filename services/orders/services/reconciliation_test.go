@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type reconciliationServiceTestSuite struct {
+	suite.Suite
+
+	svc *reconciliationService
+}
+
+func (suite *reconciliationServiceTestSuite) SetupSuite() {
+	suite.svc = NewReconciliationService(
+		mock.NewMockReconciliationRepo(),
+		mock.NewMockProvidersRegistry(),
+	)
+}
+
+func TestReconciliationServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(reconciliationServiceTestSuite))
+}
+
+func testReconciliationRequest(restaurantID *uuid.UUID) *dto.ReconciliationRequestDto {
+	from := testDateTime.Truncate(24 * time.Hour)
+
+	return &dto.ReconciliationRequestDto{
+		Provider:     testPaymentProvider,
+		RestaurantID: restaurantID,
+		From:         from,
+		To:           from.AddDate(0, 0, 1),
+	}
+}
+
+func (suite *reconciliationServiceTestSuite) TestReconcile_Success() {
+	for _, restaurantID := range []*uuid.UUID{nil, &testRestaurantID} {
+		got, err := suite.svc.Reconcile(
+			context.Background(),
+			testReconciliationRequest(restaurantID),
+		)
+		suite.Require().NoError(err)
+
+		suite.Equal(1, got.Matched)
+		suite.Equal(restaurantID, got.RestaurantID)
+		suite.Require().Len(got.Rows, 3)
+
+		missing := got.Rows[0]
+		suite.Equal(dto.ReconciliationMissing, missing.Issue)
+		suite.Equal("pi_missing", missing.ProviderPaymentID)
+		suite.NotNil(missing.PaymentID)
+		suite.Nil(missing.ProviderAmountInCents)
+
+		mismatched := got.Rows[1]
+		suite.Equal(dto.ReconciliationAmountMismatch, mismatched.Issue)
+		suite.Equal(testAmount, *mismatched.AmountInCents)
+		suite.Equal(testAmount*2, *mismatched.ProviderAmountInCents)
+
+		extra := got.Rows[2]
+		suite.Equal(dto.ReconciliationExtra, extra.Issue)
+		suite.Equal("pi_extra", extra.ProviderPaymentID)
+		suite.Nil(extra.PaymentID)
+		suite.Equal(testAmount, *extra.ProviderAmountInCents)
+	}
+}
+
+func (suite *reconciliationServiceTestSuite) TestReconcile_Error() {
+	tests := []struct {
+		name       string
+		ctxFailKey mock.CtxKey
+		provider   db.OrdersPaymentProvider
+		wantErr    error
+	}{
+		{
+			"platform provider not configured",
+			"none",
+			db.OrdersPaymentProviderStripe,
+			paymentproviders.ErrProviderNotConfigured,
+		},
+		{
+			"payments not loaded",
+			mock.CtxFailGetProviderPayments,
+			testPaymentProvider,
+			mock.ErrRepoFailed,
+		},
+		{
+			"charges not listed",
+			mock.CtxFailListCharges,
+			testPaymentProvider,
+			mock.ErrPaymentProviderFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			reqDto := testReconciliationRequest(nil)
+			reqDto.Provider = tt.provider
+
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.svc.Reconcile(ctx, reqDto)
+			suite.Require().ErrorIs(err, tt.wantErr)
+			suite.Nil(got)
+		})
+	}
+}
+
+func (suite *reconciliationServiceTestSuite) TestReconcile_RestaurantUsesPlatformProvider() {
+	ctx := context.WithValue(context.Background(), mock.CtxPlatformProvider, true)
+
+	got, err := suite.svc.Reconcile(ctx, testReconciliationRequest(&testRestaurantID))
+	suite.Require().ErrorIs(err, ErrRestaurantUsesPlatformProvider)
+	suite.Nil(got)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/promotions"
+	"golang-dining-ordering/services/orders/repository"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUserIsNotManager is returned when a user attempts an action that requires restaurant manager privileges.
+	ErrUserIsNotManager = errors.New("user is not a manager of this restaurant")
+	// ErrNothingToRefund is returned when order has no payments left that could be refunded.
+	ErrNothingToRefund = errors.New("order has nothing to refund")
+	// ErrRefundExceedsAmountPaid is returned when requested refund is bigger than what's left of order payments.
+	ErrRefundExceedsAmountPaid = errors.New("refund amount exceeds amount paid for the order")
+	// ErrItemNotInOrder is returned when refund is requested for an item that doesn't belong to the order.
+	ErrItemNotInOrder = errors.New("item does not belong to this order")
+	// ErrItemAlreadyRefunded is returned when refund is requested for an item that is covered
+	// by a pending or succeeded refund.
+	ErrItemAlreadyRefunded = errors.New("item is already refunded")
+)
+
+func (s *paymentsService) RefundOrder(
+	ctx context.Context,
+	reqDto *dto.RefundRequestDto,
+	claims *authDto.TokenClaimsDto,
+) ([]*dto.RefundDto, error) {
+	order, err := s.ordersRepo.GetOrderItems(ctx, reqDto.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	err = s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, order.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	var planned []*plannedRefund
+
+	// refunds are saved while the order is locked, so concurrent requests see each other's
+	// refunds and can't refund the same items or amount twice
+	err = s.paymentsRepo.RunInTx(
+		ctx,
+		func(ordersRepo repository.OrdersRepo, paymentsRepo repository.PaymentsRepo) error {
+			err = ordersRepo.LockOrder(ctx, order.ID)
+			if err != nil {
+				return fmt.Errorf("locking order: %w", err)
+			}
+
+			planned, err = s.planRefunds(ctx, paymentsRepo, order, reqDto, claims.UserID)
+
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds := make([]*dto.RefundDto, 0, len(planned))
+
+	for _, p := range planned {
+		refund, err := s.refundPayment(ctx, order, p, reqDto)
+		if err != nil {
+			// refunds of the payments before are already made
+			if len(refunds) > 0 {
+				s.events.Publish(ctx, events.NewOrderChangedEvent(order.ID))
+			}
+
+			return nil, err
+		}
+
+		refunds = append(refunds, refund)
+	}
+
+	s.events.Publish(ctx, events.NewOrderChangedEvent(order.ID))
+
+	return refunds, nil
+}
+
+// plannedRefund is a pending refund of the payment that's yet to be sent to its provider.
+type plannedRefund struct {
+	refund   *dto.RefundDto
+	payment  *dto.PaymentDto
+	provider paymentproviders.PaymentProvider
+}
+
+// planRefunds saves pending refunds for the requested amount, newest payments are refunded
+// first and one refund can be split across several payments.
+func (s *paymentsService) planRefunds(
+	ctx context.Context,
+	paymentsRepo repository.PaymentsRepo,
+	order *dto.OrderDto,
+	reqDto *dto.RefundRequestDto,
+	userID uuid.UUID,
+) ([]*plannedRefund, error) {
+	payments, err := paymentsRepo.GetOrderPayments(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order payments: %w", err)
+	}
+
+	refundedItemIDs, err := paymentsRepo.GetOrderRefundedItemIDs(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order refunded items: %w", err)
+	}
+
+	amount, err := s.refundAmount(order, payments, refundedItemIDs, reqDto)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := make([]*plannedRefund, 0, len(payments))
+
+	for _, payment := range payments {
+		if amount == 0 {
+			break
+		}
+
+		available := refundableAmount(payment)
+		if available <= 0 {
+			continue
+		}
+
+		part := min(available, amount)
+
+		provider, err := s.providers.Get(ctx, order.RestaurantID, payment.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("getting restaurant payment provider: %w", err)
+		}
+
+		// refund is saved before provider is asked for it, so provider's webhook always finds it
+		refund, err := paymentsRepo.SaveRefund(ctx, &dto.RefundDto{
+			ID:               uuid.New(),
+			PaymentID:        payment.ID,
+			OrderID:          payment.OrderID,
+			AmountInCents:    part,
+			Currency:         payment.Currency,
+			Provider:         payment.Provider,
+			ProviderRefundID: "",
+			Status:           db.OrdersRefundStatusPending,
+			Reason:           reqDto.Reason,
+			OrderItemIDs:     reqDto.OrderItemIDs,
+			RequestedBy:      userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("saving refund: %w", err)
+		}
+
+		planned = append(planned, &plannedRefund{
+			refund:   refund,
+			payment:  payment,
+			provider: provider,
+		})
+		amount -= part
+	}
+
+	return planned, nil
+}
+
+func (s *paymentsService) refundPayment(
+	ctx context.Context,
+	order *dto.OrderDto,
+	planned *plannedRefund,
+	reqDto *dto.RefundRequestDto,
+) (*dto.RefundDto, error) {
+	refund := planned.refund
+
+	providerResp, err := planned.provider.Refund(ctx, &dto.ProviderRefundRequestDto{
+		RefundID:      refund.ID,
+		RestaurantID:  order.RestaurantID,
+		Payment:       planned.payment,
+		AmountInCents: refund.AmountInCents,
+		Reason:        reqDto.Reason,
+	})
+	if err != nil {
+		refund.Status = db.OrdersRefundStatusFailed
+
+		_, updateErr := s.paymentsRepo.UpdateRefundFromProvider(ctx, refund)
+		if updateErr != nil {
+			err = errors.Join(err, fmt.Errorf("marking refund as failed: %w", updateErr))
+		}
+
+		return nil, fmt.Errorf("refunding payment %s: %w", planned.payment.ID, err)
+	}
+
+	refund.ProviderRefundID = providerResp.ProviderRefundID
+	refund.Status = providerResp.Status
+
+	respDto, err := s.paymentsRepo.UpdateRefundFromProvider(ctx, refund)
+	if err != nil {
+		return nil, fmt.Errorf("updating refund from provider: %w", err)
+	}
+
+	if respDto.Status == db.OrdersRefundStatusSucceeded {
+		err = s.paymentsRepo.MarkPaymentRefunded(ctx, respDto.PaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("marking payment as refunded: %w", err)
+		}
+	}
+
+	return respDto, nil
+}
+
+// refundAmount returns amount to refund: sum of requested items, requested amount
+// or everything that is left of order payments if neither is provided.
+func (s *paymentsService) refundAmount(
+	order *dto.OrderDto,
+	payments []*dto.PaymentDto,
+	refundedItemIDs []uuid.UUID,
+	reqDto *dto.RefundRequestDto,
+) (int, error) {
+	refundable := 0
+	for _, payment := range payments {
+		refundable += refundableAmount(payment)
+	}
+
+	if refundable <= 0 {
+		return 0, ErrNothingToRefund
+	}
+
+	amount := refundable
+
+	switch {
+	case len(reqDto.OrderItemIDs) > 0:
+		itemsAmount, err := s.orderItemsAmount(order, refundedItemIDs, reqDto.OrderItemIDs)
+		if err != nil {
+			return 0, err
+		}
+
+		amount = itemsAmount
+	case reqDto.AmountInCents != nil:
+		amount = *reqDto.AmountInCents
+	}
+
+	if amount > refundable {
+		return 0, fmt.Errorf(
+			"%w: requested %d, refundable %d",
+			ErrRefundExceedsAmountPaid,
+			amount,
+			refundable,
+		)
+	}
+
+	return amount, nil
+}
+
+// refundableAmount returns what's left of the payment, payments refunded or disputed on
+// provider's side can't be refunded anymore. Offline payments are given back by the waiter.
+func refundableAmount(payment *dto.PaymentDto) int {
+	if payment.Status != db.OrdersPaymentStatusSucceeded || isOfflinePayment(payment) {
+		return 0
+	}
+
+	return payment.AmountInCents - payment.RefundedAmountInCents
+}
+
+// orderItemsAmount returns what was paid for the items, that is their price after discounts.
+func (s *paymentsService) orderItemsAmount(
+	order *dto.OrderDto,
+	refundedItemIDs []uuid.UUID,
+	orderItemIDs []uuid.UUID,
+) (int, error) {
+	prices := promotions.NetItemPrices(order)
+	amount := 0
+
+	for _, id := range orderItemIDs {
+		price, ok := prices[id]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrItemNotInOrder, id)
+		}
+
+		if slices.Contains(refundedItemIDs, id) {
+			return 0, fmt.Errorf("%w: %s", ErrItemAlreadyRefunded, id)
+		}
+
+		amount += price
+	}
+
+	return amount, nil
+}
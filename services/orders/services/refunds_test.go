@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func (suite *paymentsServiceTestSuite) TestRefundOrder_Success() {
+	amount := testAmount
+
+	tests := []struct {
+		name       string
+		reqDto     *dto.RefundRequestDto
+		wantAmount int
+	}{
+		{"full refund", &dto.RefundRequestDto{}, testAmount * 2},
+		{"partial amount", &dto.RefundRequestDto{AmountInCents: &amount}, testAmount},
+		{
+			"specific items",
+			&dto.RefundRequestDto{OrderItemIDs: []uuid.UUID{testOrderItemID}},
+			testAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			tt.reqDto.OrderID = testOrderID
+			tt.reqDto.Reason = "cold soup"
+
+			got, err := suite.svc.RefundOrder(
+				context.Background(),
+				tt.reqDto,
+				&authDto.TokenClaimsDto{UserID: testUserID},
+			)
+			suite.Require().NoError(err)
+			suite.Require().Len(got, 1)
+			suite.Equal(tt.wantAmount, got[0].AmountInCents)
+			suite.Equal(testPaymentID, got[0].PaymentID)
+			suite.Equal(db.OrdersRefundStatusPending, got[0].Status)
+			suite.Equal(testUserID, got[0].RequestedBy)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestRefundOrder_ProviderStatusIsKept() {
+	ctx := context.WithValue(context.Background(), mock.CtxRefundSucceeded, true)
+
+	got, err := suite.svc.RefundOrder(
+		ctx,
+		&dto.RefundRequestDto{OrderID: testOrderID, Reason: "cold soup"},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Require().Len(got, 1)
+	suite.Equal(db.OrdersRefundStatusSucceeded, got[0].Status)
+	suite.Equal(testProviderRefundID, got[0].ProviderRefundID)
+
+	ctx = context.WithValue(ctx, mock.CtxFailMarkPaymentRefunded, true)
+
+	got, err = suite.svc.RefundOrder(
+		ctx,
+		&dto.RefundRequestDto{OrderID: testOrderID, Reason: "cold soup"},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Nil(got)
+}
+
+func (suite *paymentsServiceTestSuite) TestRefundOrder_Error() {
+	tooMuch := testAmount * 3
+
+	tests := []struct {
+		name       string
+		ctxFailKey mock.CtxKey
+		orderID    uuid.UUID
+		userID     uuid.UUID
+		reqDto     *dto.RefundRequestDto
+		wantErr    error
+	}{
+		{"order not found", "none", uuid.Max, testUserID, &dto.RefundRequestDto{}, nil},
+		{
+			"user is not manager",
+			"none",
+			testOrderID,
+			testUserFromAnotherRestaurantID,
+			&dto.RefundRequestDto{},
+			ErrUserIsNotManager,
+		},
+		{
+			"amount exceeds paid",
+			"none",
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{AmountInCents: &tooMuch},
+			ErrRefundExceedsAmountPaid,
+		},
+		{
+			"item not in order",
+			"none",
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{OrderItemIDs: []uuid.UUID{uuid.Max}},
+			ErrItemNotInOrder,
+		},
+		{
+			"item already refunded",
+			mock.CtxOrderItemRefunded,
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{OrderItemIDs: []uuid.UUID{testOrderItemID}},
+			ErrItemAlreadyRefunded,
+		},
+		{
+			"provider failed",
+			mock.CtxFailRefund,
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{},
+			nil,
+		},
+		{
+			"provider not configured",
+			mock.CtxFailGetProvider,
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{},
+			paymentproviders.ErrProviderNotConfigured,
+		},
+		{
+			"repo failed saving refund",
+			mock.CtxFailSaveRefund,
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{},
+			nil,
+		},
+		{
+			"repo failed updating refund from provider",
+			mock.CtxFailUpdateRefundFromProvider,
+			testOrderID,
+			testUserID,
+			&dto.RefundRequestDto{},
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+			tt.reqDto.OrderID = tt.orderID
+
+			got, err := suite.svc.RefundOrder(
+				ctx,
+				tt.reqDto,
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().Error(err)
+			suite.Nil(got)
+
+			if tt.wantErr != nil {
+				suite.Require().ErrorIs(err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestRefundAmount_NothingToRefund() {
+	payments := []*dto.PaymentDto{
+		{
+			AmountInCents:         testAmount,
+			RefundedAmountInCents: testAmount,
+			Status:                db.OrdersPaymentStatusSucceeded,
+		},
+		{AmountInCents: testAmount, Status: db.OrdersPaymentStatusRefunded},
+		{AmountInCents: testAmount, Status: db.OrdersPaymentStatusDisputed},
+	}
+
+	got, err := suite.svc.refundAmount(&dto.OrderDto{}, payments, nil, &dto.RefundRequestDto{})
+	suite.Require().ErrorIs(err, ErrNothingToRefund)
+	suite.Zero(got)
+}
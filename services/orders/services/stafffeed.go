@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StaffFeedService defines business logic methods for restaurants' staff feeds, live events
+// of every order in the restaurant for its waiters and managers.
+type StaffFeedService interface {
+	AuthorizeStaffFeed(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) error
+}
+
+type staffFeedService struct {
+	ordersRepo repository.OrdersRepo
+}
+
+// NewStaffFeedService creates a new staff feed service instance.
+//
+//revive:disable:unexported-return
+func NewStaffFeedService(ordersRepo repository.OrdersRepo) *staffFeedService {
+	return &staffFeedService{
+		ordersRepo: ordersRepo,
+	}
+}
+
+//revive:enable:unexported-return
+
+// AuthorizeStaffFeed checks that the user is waiter or manager of the restaurant.
+func (s *staffFeedService) AuthorizeStaffFeed(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) error {
+	isStaff, err := isRestaurantStaff(ctx, s.ordersRepo, claims.UserID, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	if !isStaff {
+		return ErrUserIsNotRestaurantStaff
+	}
+
+	return nil
+}
+
+// isRestaurantStaff reports whether the user is waiter or manager of the restaurant, failed
+// lookups are returned as errors rather than taken as the user not being staff.
+func isRestaurantStaff(
+	ctx context.Context,
+	ordersRepo repository.OrdersRepo,
+	userID, restaurantID uuid.UUID,
+) (bool, error) {
+	err := ordersRepo.IsUserRestaurantWaiter(ctx, userID, restaurantID)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, repository.ErrUserIsNotRestaurantMember) {
+		return false, fmt.Errorf("checking if user is restaurant waiter: %w", err)
+	}
+
+	err = ordersRepo.IsUserRestaurantManager(ctx, userID, restaurantID)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, repository.ErrUserIsNotRestaurantMember) {
+		return false, fmt.Errorf("checking if user is restaurant manager: %w", err)
+	}
+
+	return false, nil
+}
+
+// newStaffEvent returns event of the order at the table for staff feed. Table is loaded once
+// when the event is published, feeds of every instance are filtered by it.
+func newStaffEvent(table *dto.OrderTableDto, orderID uuid.UUID) *dto.StaffEventDto {
+	return &dto.StaffEventDto{
+		RestaurantID: table.RestaurantID,
+		OrderID:      orderID,
+		Table:        table,
+		Items:        nil,
+		Payment:      nil,
+		Assistance:   nil,
+		OccurredAt:   time.Now(),
+	}
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TipsService defines business logic methods for restaurants' tip presets and tips distribution.
+type TipsService interface {
+	GetTipSettings(
+		ctx context.Context,
+		restaurantID uuid.UUID,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.TipSettingsDto, error)
+	SetTipSettings(
+		ctx context.Context,
+		reqDto *dto.SetTipSettingsRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.TipSettingsDto, error)
+	GetTipReport(
+		ctx context.Context,
+		reqDto *dto.TipReportRequestDto,
+		claims *authDto.TokenClaimsDto,
+	) (*dto.TipReportDto, error)
+}
+
+// defaultShiftLengthHours is used by shift_pool rule when manager didn't set shift length.
+const defaultShiftLengthHours = 8
+
+// defaultTipPresets returns percentages offered to guests of restaurants that haven't
+// configured tips.
+func defaultTipPresets() []float64 {
+	return []float64{10, 15, 20} //nolint:mnd
+}
+
+type tipsService struct {
+	ordersRepo repository.OrdersRepo
+	tipsRepo   repository.TipsRepo
+}
+
+// NewTipsService creates a new tips service instance.
+//
+//revive:disable:unexported-return
+func NewTipsService(
+	ordersRepo repository.OrdersRepo,
+	tipsRepo repository.TipsRepo,
+) *tipsService {
+	return &tipsService{
+		ordersRepo: ordersRepo,
+		tipsRepo:   tipsRepo,
+	}
+}
+
+//revive:enable:unexported-return
+
+func (s *tipsService) GetTipSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	claims *authDto.TokenClaimsDto,
+) (*dto.TipSettingsDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, restaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	return getTipSettings(ctx, s.tipsRepo, restaurantID)
+}
+
+func (s *tipsService) SetTipSettings(
+	ctx context.Context,
+	reqDto *dto.SetTipSettingsRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.TipSettingsDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	if reqDto.ShiftLengthHours == 0 {
+		reqDto.ShiftLengthHours = defaultShiftLengthHours
+	}
+
+	respDto, err := s.tipsRepo.SaveTipSettings(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("saving tip settings: %w", err)
+	}
+
+	return respDto, nil
+}
+
+// GetTipReport splits tips of orders completed in the requested period among waiters assigned
+// to them, using restaurant's current pool rule.
+func (s *tipsService) GetTipReport(
+	ctx context.Context,
+	reqDto *dto.TipReportRequestDto,
+	claims *authDto.TokenClaimsDto,
+) (*dto.TipReportDto, error) {
+	err := s.ordersRepo.IsUserRestaurantManager(ctx, claims.UserID, reqDto.RestaurantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUserIsNotManager, err)
+	}
+
+	settings, err := getTipSettings(ctx, s.tipsRepo, reqDto.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.tipsRepo.GetCompletedOrdersWaiters(ctx, reqDto)
+	if err != nil {
+		return nil, fmt.Errorf("getting completed orders waiters: %w", err)
+	}
+
+	report := &dto.TipReportDto{
+		RestaurantID:          reqDto.RestaurantID,
+		From:                  reqDto.From,
+		To:                    reqDto.To,
+		PoolRule:              settings.PoolRule,
+		TotalTipsInCents:      0,
+		UnassignedTipsInCents: 0,
+		Waiters:               nil,
+	}
+
+	splitter := &tipSplitter{
+		report:  report,
+		waiters: make(map[uuid.UUID]*dto.WaiterTipsDto),
+	}
+	splitter.split(groupTipOrders(rows), settings)
+
+	return report, nil
+}
+
+// getTipSettings returns restaurant's tip settings, restaurants that haven't configured them
+// get the defaults.
+func getTipSettings(
+	ctx context.Context,
+	tipsRepo repository.TipsRepo,
+	restaurantID uuid.UUID,
+) (*dto.TipSettingsDto, error) {
+	settings, err := tipsRepo.GetTipSettings(ctx, restaurantID)
+	if errors.Is(err, repository.ErrTipSettingsDoNotExist) {
+		return &dto.TipSettingsDto{
+			RestaurantID:     restaurantID,
+			Presets:          defaultTipPresets(),
+			PoolRule:         db.OrdersTipPoolRuleEqual,
+			ShiftLengthHours: defaultShiftLengthHours,
+			UpdatedAt:        nil,
+		}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting tip settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// tipOrder is a completed order with waiters that were assigned to it.
+type tipOrder struct {
+	tipAmountInCents int
+	completedAt      time.Time
+	waiters          []*dto.CompletedOrderWaiterDto
+}
+
+// groupTipOrders groups rows of completed orders' waiters by order, rows are expected to be
+// sorted by order.
+func groupTipOrders(rows []*dto.CompletedOrderWaiterDto) []*tipOrder {
+	orders := make([]*tipOrder, 0, len(rows))
+
+	var current *tipOrder
+
+	for i, row := range rows {
+		if i == 0 || row.OrderID != rows[i-1].OrderID {
+			current = &tipOrder{
+				tipAmountInCents: row.TipAmountInCents,
+				completedAt:      row.CompletedAt,
+				waiters:          nil,
+			}
+			orders = append(orders, current)
+		}
+
+		if row.UserID != nil {
+			current.waiters = append(current.waiters, row)
+		}
+	}
+
+	return orders
+}
+
+// tipSplitter adds up waiters' shares of tips into the report.
+type tipSplitter struct {
+	report  *dto.TipReportDto
+	waiters map[uuid.UUID]*dto.WaiterTipsDto
+}
+
+func (s *tipSplitter) split(orders []*tipOrder, settings *dto.TipSettingsDto) {
+	for _, order := range orders {
+		s.report.TotalTipsInCents += order.tipAmountInCents
+
+		for _, waiter := range order.waiters {
+			s.waiter(*waiter.UserID).OrdersCount++
+		}
+	}
+
+	switch settings.PoolRule {
+	case db.OrdersTipPoolRuleEqual:
+		for _, order := range orders {
+			s.splitOrder(order, equalWeight)
+		}
+	case db.OrdersTipPoolRuleTimeAssigned:
+		for _, order := range orders {
+			s.splitOrder(order, timeAssignedWeight)
+		}
+	case db.OrdersTipPoolRuleShiftPool:
+		s.splitShifts(orders, time.Duration(settings.ShiftLengthHours)*time.Hour)
+	}
+
+	s.report.Waiters = make([]*dto.WaiterTipsDto, 0, len(s.waiters))
+	for _, waiter := range s.waiters {
+		s.report.Waiters = append(s.report.Waiters, waiter)
+	}
+
+	slices.SortFunc(s.report.Waiters, func(a, b *dto.WaiterTipsDto) int {
+		return cmp.Compare(a.UserID.String(), b.UserID.String())
+	})
+}
+
+// splitOrder splits order's tip among waiters assigned to it in proportion to their weights.
+func (s *tipSplitter) splitOrder(
+	order *tipOrder,
+	weight func(order *tipOrder, waiter *dto.CompletedOrderWaiterDto) int,
+) {
+	if len(order.waiters) == 0 {
+		s.report.UnassignedTipsInCents += order.tipAmountInCents
+
+		return
+	}
+
+	weights := make([]int, 0, len(order.waiters))
+	for _, waiter := range order.waiters {
+		weights = append(weights, weight(order, waiter))
+	}
+
+	for i, share := range splitCents(order.tipAmountInCents, weights) {
+		s.waiter(*order.waiters[i].UserID).TipsInCents += share
+	}
+}
+
+// splitShifts pools tips of orders completed in the same shift and splits them equally among
+// waiters that served any order of the shift. Shifts start at midnight UTC.
+func (s *tipSplitter) splitShifts(orders []*tipOrder, shiftLength time.Duration) {
+	shifts := make(map[time.Time]*tipOrder)
+	starts := make([]time.Time, 0)
+
+	for _, order := range orders {
+		start := order.completedAt.UTC().Truncate(shiftLength)
+
+		shift, ok := shifts[start]
+		if !ok {
+			shift = &tipOrder{
+				tipAmountInCents: 0,
+				completedAt:      start,
+				waiters:          nil,
+			}
+			shifts[start] = shift
+			starts = append(starts, start)
+		}
+
+		shift.tipAmountInCents += order.tipAmountInCents
+
+		for _, waiter := range order.waiters {
+			if !slices.ContainsFunc(shift.waiters, func(w *dto.CompletedOrderWaiterDto) bool {
+				return *w.UserID == *waiter.UserID
+			}) {
+				shift.waiters = append(shift.waiters, waiter)
+			}
+		}
+	}
+
+	for _, start := range starts {
+		s.splitOrder(shifts[start], equalWeight)
+	}
+}
+
+func (s *tipSplitter) waiter(userID uuid.UUID) *dto.WaiterTipsDto {
+	waiter, ok := s.waiters[userID]
+	if !ok {
+		waiter = &dto.WaiterTipsDto{
+			UserID:      userID,
+			OrdersCount: 0,
+			TipsInCents: 0,
+		}
+		s.waiters[userID] = waiter
+	}
+
+	return waiter
+}
+
+func equalWeight(_ *tipOrder, _ *dto.CompletedOrderWaiterDto) int {
+	return 1
+}
+
+// timeAssignedWeight is the number of seconds waiter was assigned to the order before it was
+// completed, every waiter counts for at least a second.
+func timeAssignedWeight(order *tipOrder, waiter *dto.CompletedOrderWaiterDto) int {
+	return max(int(order.completedAt.Sub(waiter.AssignedAt)/time.Second), 1)
+}
+
+// splitCents splits amount in proportion to weights, so that the shares add up to the amount.
+// Cents left over after rounding down go to the largest remainders, ties go to earlier shares.
+func splitCents(amount int, weights []int) []int {
+	shares := make([]int, len(weights))
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	if total == 0 {
+		return shares
+	}
+
+	left := amount
+	remainders := make([]int, len(weights))
+
+	for i, w := range weights {
+		shares[i] = amount * w / total
+		remainders[i] = amount * w % total
+		left -= shares[i]
+	}
+
+	byRemainder := make([]int, len(weights))
+	for i := range byRemainder {
+		byRemainder[i] = i
+	}
+
+	slices.SortStableFunc(byRemainder, func(a, b int) int {
+		return cmp.Compare(remainders[b], remainders[a])
+	})
+
+	for i := range left {
+		shares[byRemainder[i]]++
+	}
+
+	return shares
+}
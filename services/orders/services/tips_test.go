@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	mock "golang-dining-ordering/test/mock/orders"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+//nolint:gochecknoglobals
+var (
+	testFirstWaiterID  = uuid.MustParse("a1a1a1a1-a1a1-4a1a-8a1a-a1a1a1a1a1a1")
+	testSecondWaiterID = uuid.MustParse("b2b2b2b2-b2b2-4b2b-8b2b-b2b2b2b2b2b2")
+	testThirdWaiterID  = uuid.MustParse("c3c3c3c3-c3c3-4c3c-8c3c-c3c3c3c3c3c3")
+)
+
+type tipsServiceTestSuite struct {
+	suite.Suite
+}
+
+func TestTipsServiceTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(tipsServiceTestSuite))
+}
+
+func (suite *tipsServiceTestSuite) newService() *tipsService {
+	return NewTipsService(mock.NewMockOrdersRepo(), mock.NewMockTipsRepo())
+}
+
+func (suite *tipsServiceTestSuite) TestGetTipSettings() {
+	svc := suite.newService()
+	claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+	got, err := svc.GetTipSettings(context.Background(), testRestaurantID, claims)
+	suite.Require().NoError(err)
+	suite.Equal([]float64{5, 12.5}, got.Presets)
+	suite.NotNil(got.UpdatedAt)
+
+	restaurantID := uuid.New()
+
+	got, err = svc.GetTipSettings(context.Background(), restaurantID, claims)
+	suite.Require().NoError(err)
+	suite.Equal(restaurantID, got.RestaurantID)
+	suite.Equal(defaultTipPresets(), got.Presets)
+	suite.Equal(db.OrdersTipPoolRuleEqual, got.PoolRule)
+	suite.Nil(got.UpdatedAt)
+
+	_, err = svc.GetTipSettings(
+		context.WithValue(context.Background(), mock.CtxFailGetTipSettings, true),
+		testRestaurantID,
+		claims,
+	)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+
+	_, err = svc.GetTipSettings(
+		context.Background(),
+		testRestaurantID,
+		&authDto.TokenClaimsDto{UserID: testUserFromAnotherRestaurantID},
+	)
+	suite.Require().ErrorIs(err, ErrUserIsNotManager)
+}
+
+func (suite *tipsServiceTestSuite) TestSetTipSettings_Success() {
+	got, err := suite.newService().SetTipSettings(
+		context.Background(),
+		&dto.SetTipSettingsRequestDto{
+			RestaurantID:     testRestaurantID,
+			Presets:          []float64{10, 20},
+			PoolRule:         db.OrdersTipPoolRuleShiftPool,
+			ShiftLengthHours: 0,
+		},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal([]float64{10, 20}, got.Presets)
+	suite.Equal(db.OrdersTipPoolRuleShiftPool, got.PoolRule)
+	suite.Equal(defaultShiftLengthHours, got.ShiftLengthHours)
+}
+
+func (suite *tipsServiceTestSuite) TestSetTipSettings_Error() {
+	tests := []struct {
+		name       string
+		ctxFailKey mock.CtxKey
+		userID     uuid.UUID
+		wantErr    error
+	}{
+		{"user is not manager", "none", testUserFromAnotherRestaurantID, ErrUserIsNotManager},
+		{"repo failed", mock.CtxFailSaveTipSettings, testUserID, mock.ErrRepoFailed},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.newService().SetTipSettings(
+				ctx,
+				&dto.SetTipSettingsRequestDto{
+					RestaurantID:     testRestaurantID,
+					Presets:          []float64{10},
+					PoolRule:         db.OrdersTipPoolRuleEqual,
+					ShiftLengthHours: 0,
+				},
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+			suite.Nil(got)
+		})
+	}
+}
+
+func (suite *tipsServiceTestSuite) TestGetTipReport_Success() {
+	tests := []struct {
+		rule           db.OrdersTipPoolRule
+		unassignedTips int
+		waiterTips     map[uuid.UUID]int
+	}{
+		{
+			db.OrdersTipPoolRuleEqual,
+			30,
+			map[uuid.UUID]int{testFirstWaiterID: 100, testSecondWaiterID: 50, testThirdWaiterID: 0},
+		},
+		{
+			db.OrdersTipPoolRuleTimeAssigned,
+			30,
+			map[uuid.UUID]int{testFirstWaiterID: 117, testSecondWaiterID: 33, testThirdWaiterID: 0},
+		},
+		{
+			// unassigned order's tip is pooled with the rest of its shift
+			db.OrdersTipPoolRuleShiftPool,
+			0,
+			map[uuid.UUID]int{testFirstWaiterID: 90, testSecondWaiterID: 90, testThirdWaiterID: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(string(tt.rule), func(_ *testing.T) {
+			svc := suite.newService()
+			claims := &authDto.TokenClaimsDto{UserID: testUserID}
+
+			_, err := svc.SetTipSettings(context.Background(), &dto.SetTipSettingsRequestDto{
+				RestaurantID:     testRestaurantID,
+				Presets:          []float64{10},
+				PoolRule:         tt.rule,
+				ShiftLengthHours: 8,
+			}, claims)
+			suite.Require().NoError(err)
+
+			got, err := svc.GetTipReport(context.Background(), newTipReportRequest(), claims)
+			suite.Require().NoError(err)
+
+			suite.Equal(tt.rule, got.PoolRule)
+			suite.Equal(180, got.TotalTipsInCents)
+			suite.Equal(tt.unassignedTips, got.UnassignedTipsInCents)
+			suite.Require().Len(got.Waiters, len(tt.waiterTips))
+
+			sum := got.UnassignedTipsInCents
+
+			for _, waiter := range got.Waiters {
+				suite.Equal(tt.waiterTips[waiter.UserID], waiter.TipsInCents, waiter.UserID)
+				sum += waiter.TipsInCents
+			}
+
+			suite.Equal(got.TotalTipsInCents, sum)
+			suite.Equal(testFirstWaiterID, got.Waiters[0].UserID)
+			suite.Equal(2, got.Waiters[0].OrdersCount)
+		})
+	}
+}
+
+func (suite *tipsServiceTestSuite) TestGetTipReport_Error() {
+	tests := []struct {
+		name       string
+		ctxFailKey mock.CtxKey
+		userID     uuid.UUID
+		wantErr    error
+	}{
+		{"user is not manager", "none", testUserFromAnotherRestaurantID, ErrUserIsNotManager},
+		{"settings repo failed", mock.CtxFailGetTipSettings, testUserID, mock.ErrRepoFailed},
+		{
+			"orders repo failed",
+			mock.CtxFailGetCompletedOrdersWaiters,
+			testUserID,
+			mock.ErrRepoFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.newService().GetTipReport(
+				ctx,
+				newTipReportRequest(),
+				&authDto.TokenClaimsDto{UserID: tt.userID},
+			)
+			suite.Require().ErrorIs(err, tt.wantErr)
+			suite.Nil(got)
+		})
+	}
+}
+
+func (suite *tipsServiceTestSuite) TestSplitCents() {
+	suite.Equal([]int{34, 33, 33}, splitCents(100, []int{1, 1, 1}))
+	suite.Equal([]int{67, 33}, splitCents(100, []int{2, 1}))
+	suite.Equal([]int{0, 1, 0}, splitCents(1, []int{1, 2, 1}))
+	suite.Equal([]int{0, 0}, splitCents(0, []int{1, 1}))
+	suite.Equal([]int{0}, splitCents(10, []int{0}))
+}
+
+func newTipReportRequest() *dto.TipReportRequestDto {
+	return &dto.TipReportRequestDto{
+		RestaurantID: testRestaurantID,
+		From:         time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
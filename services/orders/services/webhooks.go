@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	"golang-dining-ordering/services/orders/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HandleWebhook verifies provider's webhook event and applies it in the event's transaction.
+// Ignored events are returned right away, they are not recorded in the webhooks inbox.
+func (s *paymentsService) HandleWebhook(
+	ctx context.Context,
+	reqDto *dto.WebhookDto,
+) (*dto.ProviderEventDto, error) {
+	provider, restaurantID, err := s.webhookProvider(ctx, reqDto)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := provider.ParseWebhookEvent(reqDto.Payload, reqDto.Header)
+	if err != nil {
+		return nil, fmt.Errorf("verifying webhook event: %w", err)
+	}
+
+	if event.Type == dto.ProviderEventIgnored {
+		return event, nil
+	}
+
+	var (
+		order   *dto.OrderDto
+		table   *dto.OrderTableDto
+		payment *dto.PaymentDto
+	)
+
+	err = s.paymentsRepo.ProcessWebhookEvent(
+		ctx,
+		webhookEvent(reqDto, event.ID),
+		func(ordersRepo repository.OrdersRepo, paymentsRepo repository.PaymentsRepo) error {
+			h := &webhookEventHandler{
+				ordersRepo:   ordersRepo,
+				paymentsRepo: paymentsRepo,
+				provider:     reqDto.Provider,
+				restaurantID: restaurantID,
+				order:        nil,
+				table:        nil,
+				payment:      nil,
+			}
+
+			err := h.handle(ctx, event)
+			order = h.order
+			table = h.table
+			payment = h.payment
+
+			return err
+		},
+	)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	if order != nil {
+		s.events.Publish(ctx, events.NewOrderChangedEvent(order.ID))
+	}
+
+	if order != nil && payment != nil {
+		succeeded := newStaffEvent(table, payment.OrderID)
+		succeeded.Payment = payment
+
+		s.events.Publish(ctx, events.NewStaffEvent(dto.MsgPaymentSucceeded, succeeded))
+	}
+
+	return event, nil
+}
+
+// webhookProvider returns provider verifying the webhook and restaurant its events must be for.
+// Webhooks verified with platform's credentials may be for orders of any restaurant paid to the
+// platform account, so the restaurant is uuid.Nil and it's resolved from event's order.
+//
+//nolint:ireturn
+func (s *paymentsService) webhookProvider(
+	ctx context.Context,
+	reqDto *dto.WebhookDto,
+) (paymentproviders.PaymentProvider, uuid.UUID, error) {
+	if reqDto.RestaurantID != uuid.Nil {
+		provider, err := s.providers.GetOwn(ctx, reqDto.RestaurantID, reqDto.Provider)
+		if err == nil {
+			return provider, reqDto.RestaurantID, nil
+		}
+
+		if !errors.Is(err, paymentproviders.ErrProviderNotConfigured) {
+			return nil, uuid.Nil, fmt.Errorf("getting restaurant payment provider: %w", err)
+		}
+	}
+
+	provider, err := s.providers.GetPlatform(reqDto.Provider)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("getting platform payment provider: %w", err)
+	}
+
+	return provider, uuid.Nil, nil
+}
+
+// webhookEventHandler applies verified provider's event with repos bound to its transaction.
+// It remembers the order the event changed and the payment that succeeded, so they are
+// published once the transaction is committed.
+type webhookEventHandler struct {
+	ordersRepo   repository.OrdersRepo
+	paymentsRepo repository.PaymentsRepo
+	provider     db.OrdersPaymentProvider
+	restaurantID uuid.UUID
+	order        *dto.OrderDto
+	table        *dto.OrderTableDto
+	payment      *dto.PaymentDto
+}
+
+func (h *webhookEventHandler) handle(ctx context.Context, event *dto.ProviderEventDto) error {
+	switch event.Type {
+	case dto.ProviderEventPaymentSucceeded:
+		err := h.handlePaymentSucceeded(ctx, event.Payment)
+		if err != nil {
+			return err
+		}
+
+		return h.updateAttemptStatus(ctx, event, db.OrdersPaymentAttemptStatusSucceeded)
+	case dto.ProviderEventPaymentFailed:
+		// checkout stays open so the guest can try again, order is unlocked once it expires
+		_, err := h.getOrder(ctx, event.OrderID)
+		if err != nil {
+			return err
+		}
+
+		return h.updateAttemptStatus(ctx, event, db.OrdersPaymentAttemptStatusFailed)
+	case dto.ProviderEventCheckoutExpired:
+		return h.handleCheckoutClosed(ctx, event, db.OrdersPaymentAttemptStatusExpired)
+	case dto.ProviderEventCheckoutCancelled:
+		return h.handleCheckoutClosed(ctx, event, db.OrdersPaymentAttemptStatusCancelled)
+	case dto.ProviderEventPaymentRefunded:
+		return h.handlePaymentStatus(ctx, event.ProviderPaymentID, db.OrdersPaymentStatusRefunded)
+	case dto.ProviderEventPaymentDisputed:
+		return h.handlePaymentStatus(ctx, event.ProviderPaymentID, db.OrdersPaymentStatusDisputed)
+	case dto.ProviderEventRefundUpdated:
+		return h.handleRefundUpdated(ctx, event.Refund)
+	case dto.ProviderEventIgnored:
+		return nil
+	}
+
+	return nil
+}
+
+func (h *webhookEventHandler) handlePaymentSucceeded(
+	ctx context.Context,
+	paymentDto *dto.PaymentDto,
+) error {
+	_, err := h.getOrder(ctx, paymentDto.OrderID)
+	if err != nil {
+		return err
+	}
+
+	err = h.ordersRepo.LockOrder(ctx, paymentDto.OrderID)
+	if err != nil {
+		return fmt.Errorf("locking order: %w", err)
+	}
+
+	payment, err := h.paymentsRepo.SavePayment(ctx, paymentDto)
+	if err != nil {
+		return fmt.Errorf("creating payment: %w", err)
+	}
+
+	err = settleOrder(ctx, h.ordersRepo, h.paymentsRepo, payment.OrderID)
+	if err != nil {
+		return fmt.Errorf("settling order: %w", err)
+	}
+
+	table, err := h.ordersRepo.GetOrderTable(ctx, payment.OrderID)
+	if err != nil {
+		return fmt.Errorf("getting order table: %w", err)
+	}
+
+	h.table = table
+	h.payment = payment
+
+	return nil
+}
+
+// handleCheckoutClosed closes the payment attempt of expired or cancelled checkout and unlocks
+// the order locked for payment, so guests can edit it again.
+func (h *webhookEventHandler) handleCheckoutClosed(
+	ctx context.Context,
+	event *dto.ProviderEventDto,
+	status db.OrdersPaymentAttemptStatus,
+) error {
+	order, err := h.getOrder(ctx, event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	err = h.updateAttemptStatus(ctx, event, status)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != db.OrderStatusLocked {
+		return nil
+	}
+
+	orderStatus := db.OrderStatusOpen
+
+	_, err = h.ordersRepo.UpdateOrder(ctx, &dto.UpdateOrderReqDto{
+		OrderID:          order.ID,
+		Status:           &orderStatus,
+		TipAmountInCents: nil,
+	})
+	if err != nil {
+		return fmt.Errorf("unlocking order: %w", err)
+	}
+
+	return nil
+}
+
+// handlePaymentStatus marks payment refunded or disputed on provider's side, disputed orders
+// are flagged for staff review. Payments we don't know about are acknowledged and skipped.
+func (h *webhookEventHandler) handlePaymentStatus(
+	ctx context.Context,
+	providerPaymentID string,
+	status db.OrdersPaymentStatus,
+) error {
+	payment, err := h.paymentsRepo.UpdatePaymentStatus(ctx, &dto.PaymentDto{
+		ID:                    uuid.Nil,
+		OrderID:               uuid.Nil,
+		AmountInCents:         0,
+		Provider:              h.provider,
+		ProviderPaymentID:     providerPaymentID,
+		Currency:              "",
+		RefundedAmountInCents: 0,
+		Status:                status,
+		TenderedInCents:       nil,
+		ChangeDueInCents:      nil,
+		RecordedBy:            nil,
+	})
+	if errors.Is(err, repository.ErrPaymentDoesNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("updating payment status: %w", err)
+	}
+
+	_, err = h.getOrder(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if status != db.OrdersPaymentStatusDisputed {
+		return nil
+	}
+
+	review := db.OrdersPaymentReviewDisputed
+
+	err = h.ordersRepo.SetOrderPaymentReview(ctx, payment.OrderID, &review)
+	if err != nil {
+		return fmt.Errorf("flagging order for payment review: %w", err)
+	}
+
+	return nil
+}
+
+// handleRefundUpdated sets status of the refund, refunds we don't know about, e.g. made in
+// provider's dashboard, are acknowledged and skipped.
+func (h *webhookEventHandler) handleRefundUpdated(
+	ctx context.Context,
+	refundDto *dto.RefundDto,
+) error {
+	refund, err := h.paymentsRepo.UpdateRefundStatus(ctx, refundDto)
+	if errors.Is(err, repository.ErrRefundDoesNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("updating refund status: %w", err)
+	}
+
+	_, err = h.getOrder(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+
+	if refund.Status == db.OrdersRefundStatusSucceeded {
+		err = h.paymentsRepo.MarkPaymentRefunded(ctx, refund.PaymentID)
+		if err != nil {
+			return fmt.Errorf("marking payment as refunded: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateAttemptStatus moves payment attempt of the event's checkout to provided status.
+func (h *webhookEventHandler) updateAttemptStatus(
+	ctx context.Context,
+	event *dto.ProviderEventDto,
+	status db.OrdersPaymentAttemptStatus,
+) error {
+	err := h.paymentsRepo.UpdatePaymentAttemptStatus(ctx, &dto.PaymentAttemptDto{
+		ID:                event.PaymentAttemptID,
+		OrderID:           event.OrderID,
+		Provider:          h.provider,
+		ProviderSessionID: event.SessionID,
+		AmountInCents:     0,
+		Currency:          "",
+		Status:            status,
+		CreatedAt:         time.Time{},
+		UpdatedAt:         time.Time{},
+	})
+	if err != nil {
+		return fmt.Errorf("updating payment attempt status: %w", err)
+	}
+
+	return nil
+}
+
+// getOrder returns order the event is for, making sure it belongs to the restaurant
+// whose credentials verified the event.
+func (h *webhookEventHandler) getOrder(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.OrderDto, error) {
+	order, err := h.ordersRepo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting order: %w", err)
+	}
+
+	// restaurant isn't known for events verified with platform's credentials
+	if h.restaurantID != uuid.Nil && order.RestaurantID != h.restaurantID {
+		return nil, ErrWebhookRestaurantMismatch
+	}
+
+	h.order = order
+
+	return order, nil
+}
+
+func webhookEvent(reqDto *dto.WebhookDto, eventID string) *dto.WebhookEventDto {
+	return &dto.WebhookEventDto{
+		Provider:     reqDto.Provider,
+		EventID:      eventID,
+		RestaurantID: reqDto.RestaurantID,
+	}
+}
+
+// webhookError marks retries of already handled events, so they are acknowledged instead of
+// failing. Any other error rolls the event back and provider is expected to retry it.
+func webhookError(err error) error {
+	if errors.Is(err, repository.ErrWebhookEventAlreadyProcessed) ||
+		errors.Is(err, repository.ErrPaymentAlreadySaved) {
+		return fmt.Errorf("%w: %w", ErrWebhookAlreadyHandled, err)
+	}
+
+	return fmt.Errorf("processing webhook event: %w", err)
+}
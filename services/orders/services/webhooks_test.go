@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	authDto "golang-dining-ordering/services/auth/dto"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/events"
+	"golang-dining-ordering/services/orders/paymentproviders"
+	mock "golang-dining-ordering/test/mock/orders"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func (suite *paymentsServiceTestSuite) handleWebhook(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	payload string,
+) (*dto.ProviderEventDto, error) {
+	return suite.svc.HandleWebhook(ctx, &dto.WebhookDto{
+		Provider:     testPaymentProvider,
+		RestaurantID: restaurantID,
+		Payload:      []byte(payload),
+		Header:       http.Header{"Payment-Signature": []string{"signature"}},
+	})
+}
+
+func (suite *paymentsServiceTestSuite) TestHandleWebhook_Success() {
+	tests := []struct {
+		name     string
+		ctxKey   mock.CtxKey
+		payload  string
+		wantType dto.ProviderEventType
+	}{
+		{
+			"payment succeeded",
+			"none",
+			`{"type": "payment_succeeded"}`,
+			dto.ProviderEventPaymentSucceeded,
+		},
+		{"payment failed", "none", `{"type": "payment_failed"}`, dto.ProviderEventPaymentFailed},
+		{
+			"locked order checkout expired",
+			mock.CtxLockedOrder,
+			`{"type": "checkout_expired"}`,
+			dto.ProviderEventCheckoutExpired,
+		},
+		{
+			"locked order checkout cancelled",
+			mock.CtxLockedOrder,
+			`{"type": "checkout_cancelled"}`,
+			dto.ProviderEventCheckoutCancelled,
+		},
+		{
+			"open order checkout expired",
+			"none",
+			`{"type": "checkout_expired"}`,
+			dto.ProviderEventCheckoutExpired,
+		},
+		{
+			"payment refunded",
+			"none",
+			`{"type": "payment_refunded"}`,
+			dto.ProviderEventPaymentRefunded,
+		},
+		{
+			"payment disputed",
+			"none",
+			`{"type": "payment_disputed"}`,
+			dto.ProviderEventPaymentDisputed,
+		},
+		{
+			"unknown payment disputed",
+			"none",
+			`{"type": "payment_disputed", "provider_payment_id": "pi_unknown"}`,
+			dto.ProviderEventPaymentDisputed,
+		},
+		{"refund updated", "none", `{"type": "refund_updated"}`, dto.ProviderEventRefundUpdated},
+		{
+			"unknown refund updated",
+			"none",
+			`{"type": "refund_updated", "provider_refund_id": "re_unknown"}`,
+			dto.ProviderEventRefundUpdated,
+		},
+		{
+			"ignored event is not processed",
+			mock.CtxWebhookEventProcessed,
+			`{"type": "ignored"}`,
+			dto.ProviderEventIgnored,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+
+			got, err := suite.handleWebhook(ctx, testRestaurantID, tt.payload)
+			suite.Require().NoError(err)
+			suite.Equal(tt.wantType, got.Type)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestHandleWebhook_PlatformProvider() {
+	tests := []struct {
+		name         string
+		ctxKey       mock.CtxKey
+		restaurantID uuid.UUID
+	}{
+		{"platform webhook url", "none", uuid.Nil},
+		{"restaurant without own credentials", mock.CtxPlatformProvider, testRestaurantID},
+		{
+			"url of another restaurant without own credentials",
+			mock.CtxPlatformProvider,
+			testUserFromAnotherRestaurantID,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxKey, true)
+
+			got, err := suite.handleWebhook(ctx, tt.restaurantID, `{"type": "payment_succeeded"}`)
+			suite.Require().NoError(err)
+			suite.Equal(dto.ProviderEventPaymentSucceeded, got.Type)
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestHandleWebhook_ProviderNotConfigured() {
+	ctx := context.WithValue(context.Background(), mock.CtxFailGetProvider, true)
+
+	got, err := suite.svc.HandleWebhook(ctx, &dto.WebhookDto{
+		Provider:     db.OrdersPaymentProviderStripe,
+		RestaurantID: testRestaurantID,
+		Payload:      []byte(`{"type": "payment_succeeded"}`),
+		Header:       http.Header{},
+	})
+	suite.Require().ErrorIs(err, paymentproviders.ErrProviderNotConfigured)
+	suite.Nil(got)
+}
+
+func (suite *paymentsServiceTestSuite) TestPaymentSucceededIsPublishedToStaff() {
+	pubsub := events.NewMemoryPubSub(10)
+	svc := NewPaymentsService(
+		mock.NewMockOrdersRepo(),
+		mock.NewMockPaymentsRepo(),
+		mock.NewMockProvidersRegistry(),
+		pubsub,
+		mock.NewTableSessions(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published, err := pubsub.Subscribe(ctx)
+	suite.Require().NoError(err)
+
+	_, err = svc.HandleWebhook(context.Background(), &dto.WebhookDto{
+		Provider:     testPaymentProvider,
+		RestaurantID: testRestaurantID,
+		Payload:      []byte(`{"type": "payment_succeeded"}`),
+		Header:       http.Header{"Payment-Signature": []string{"signature"}},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(dto.MsgUpdateOrder, (<-published).Type)
+
+	succeeded := <-published
+	suite.Equal(dto.MsgPaymentSucceeded, succeeded.Type)
+	suite.Require().NotNil(succeeded.Staff)
+	suite.Equal(testRestaurantID, succeeded.Staff.RestaurantID)
+	suite.NotNil(succeeded.Staff.Table)
+	suite.Require().NotNil(succeeded.Staff.Payment)
+
+	_, err = svc.RecordOfflinePayment(
+		context.Background(),
+		&dto.OfflinePaymentRequestDto{
+			OrderID:         testOrderID,
+			Method:          db.OrdersPaymentProviderCash,
+			AmountInCents:   testAmount,
+			TenderedInCents: nil,
+		},
+		&authDto.TokenClaimsDto{UserID: testUserID},
+	)
+	suite.Require().NoError(err)
+	suite.Equal(dto.MsgUpdateOrder, (<-published).Type)
+
+	succeeded = <-published
+	suite.Equal(dto.MsgPaymentSucceeded, succeeded.Type)
+	suite.NotNil(succeeded.Staff.Payment)
+
+	suite.Empty(published)
+}
+
+func (suite *paymentsServiceTestSuite) TestHandleWebhook_Error() {
+	tests := []struct {
+		name         string
+		ctxFailKey   mock.CtxKey
+		restaurantID uuid.UUID
+		payload      string
+		wantErr      error
+	}{
+		{"empty payload", "none", testRestaurantID, "", mock.ErrPaymentProviderFailed},
+		{
+			"save payment failed",
+			mock.CtxFailSavePayment,
+			testRestaurantID,
+			`{"type": "payment_succeeded"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"repo failed to update order",
+			mock.CtxFailUpdateOrder,
+			testRestaurantID,
+			`{"type": "payment_succeeded"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"order of another restaurant",
+			"none",
+			testUserFromAnotherRestaurantID,
+			`{"type": "payment_succeeded"}`,
+			ErrWebhookRestaurantMismatch,
+		},
+		{
+			"failed payment for order of another restaurant",
+			"none",
+			testUserFromAnotherRestaurantID,
+			`{"type": "payment_failed"}`,
+			ErrWebhookRestaurantMismatch,
+		},
+		{
+			"unknown order",
+			"none",
+			testRestaurantID,
+			`{"type": "checkout_expired", "order_id": "` + uuid.Max.String() + `"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"update attempt of paid checkout failed",
+			mock.CtxFailUpdatePaymentAttemptStatus,
+			testRestaurantID,
+			`{"type": "payment_succeeded"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"update attempt of failed payment failed",
+			mock.CtxFailUpdatePaymentAttemptStatus,
+			testRestaurantID,
+			`{"type": "payment_failed"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"update attempt of expired checkout failed",
+			mock.CtxFailUpdatePaymentAttemptStatus,
+			testRestaurantID,
+			`{"type": "checkout_expired"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"update payment status failed",
+			mock.CtxFailUpdatePaymentStatus,
+			testRestaurantID,
+			`{"type": "payment_refunded"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"disputed payment of another restaurant",
+			"none",
+			testUserFromAnotherRestaurantID,
+			`{"type": "payment_disputed"}`,
+			ErrWebhookRestaurantMismatch,
+		},
+		{
+			"repo failed to flag disputed order",
+			mock.CtxFailSetOrderPaymentReview,
+			testRestaurantID,
+			`{"type": "payment_disputed"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"update refund status failed",
+			mock.CtxFailUpdateRefundStatus,
+			testRestaurantID,
+			`{"type": "refund_updated"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"refund of another restaurant",
+			"none",
+			testUserFromAnotherRestaurantID,
+			`{"type": "refund_updated"}`,
+			ErrWebhookRestaurantMismatch,
+		},
+		{
+			"repo failed to mark payment refunded",
+			mock.CtxFailMarkPaymentRefunded,
+			testRestaurantID,
+			`{"type": "refund_updated"}`,
+			mock.ErrRepoFailed,
+		},
+		{
+			"event already processed",
+			mock.CtxWebhookEventProcessed,
+			testRestaurantID,
+			`{"type": "refund_updated"}`,
+			ErrWebhookAlreadyHandled,
+		},
+		{
+			"payment already saved",
+			mock.CtxPaymentAlreadySaved,
+			testRestaurantID,
+			`{"type": "payment_succeeded"}`,
+			ErrWebhookAlreadyHandled,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.T().Run(tt.name, func(_ *testing.T) {
+			ctx := context.WithValue(context.Background(), tt.ctxFailKey, true)
+
+			got, err := suite.handleWebhook(ctx, tt.restaurantID, tt.payload)
+			suite.Require().Error(err)
+			suite.Nil(got)
+
+			if tt.wantErr != nil {
+				suite.Require().ErrorIs(err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *paymentsServiceTestSuite) TestHandleWebhook_UnlockOrderFailed() {
+	ctx := context.WithValue(context.Background(), mock.CtxLockedOrder, true)
+	ctx = context.WithValue(ctx, mock.CtxFailUpdateOrder, true)
+
+	got, err := suite.handleWebhook(ctx, testRestaurantID, `{"type": "checkout_expired"}`)
+	suite.Require().ErrorIs(err, mock.ErrRepoFailed)
+	suite.Nil(got)
+}
@@ -0,0 +1,120 @@
+// Package sessions issues short-lived table session tokens to guests who scanned table's QR
+// code, the token lets guest connect to the table's current order.
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// tokenType tells table session tokens apart from staff tokens signed with the same algorithm.
+const tokenType = "table_session"
+
+var (
+	// ErrMissingSecret is returned when table sessions are created without a signing secret.
+	ErrMissingSecret = errors.New("table session secret is not configured")
+	// ErrInvalidToken is returned when table session token is malformed, expired or signed
+	// with a different secret.
+	ErrInvalidToken = errors.New("invalid table session token")
+)
+
+// Session is a guest's session at the table's current order. ParticipantID identifies the
+// guest among other participants of the order, it's nil in tokens issued before guests were
+// told apart.
+type Session struct {
+	Token         string
+	OrderID       uuid.UUID
+	TableID       uuid.UUID
+	ParticipantID uuid.UUID
+	ExpiresAt     time.Time
+}
+
+type sessionClaims struct {
+	OrderID       uuid.UUID `json:"order_id"`
+	TableID       uuid.UUID `json:"table_id"`
+	ParticipantID uuid.UUID `json:"participant_id"`
+	TokenType     string    `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// TableSessions issues and verifies table session tokens signed with HMAC-SHA256.
+type TableSessions struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTableSessions creates TableSessions issuing tokens valid for ttl.
+func NewTableSessions(secret string, ttl time.Duration) (*TableSessions, error) {
+	if secret == "" {
+		return nil, ErrMissingSecret
+	}
+
+	return &TableSessions{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}, nil
+}
+
+// Issue issues a session token for the table's order, each session is a new participant of
+// the order.
+func (s *TableSessions) Issue(orderID, tableID uuid.UUID) (*Session, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl).Truncate(time.Second).UTC()
+	participantID := uuid.New()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &sessionClaims{
+		OrderID:       orderID,
+		TableID:       tableID,
+		ParticipantID: participantID,
+		TokenType:     tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{ //nolint:exhaustruct
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	tokenStr, err := token.SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("signing table session token: %w", err)
+	}
+
+	return &Session{
+		Token:         tokenStr,
+		OrderID:       orderID,
+		TableID:       tableID,
+		ParticipantID: participantID,
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// Verify returns the session of a valid token.
+func (s *TableSessions) Verify(tokenStr string) (*Session, error) {
+	var claims sessionClaims
+
+	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.TokenType != tokenType || claims.OrderID == uuid.Nil || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Session{
+		Token:         tokenStr,
+		OrderID:       claims.OrderID,
+		TableID:       claims.TableID,
+		ParticipantID: claims.ParticipantID,
+		ExpiresAt:     claims.ExpiresAt.Time,
+	}, nil
+}
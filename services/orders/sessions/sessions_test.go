@@ -0,0 +1,90 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type sessionsTestSuite struct {
+	suite.Suite
+	sessions *TableSessions
+}
+
+func TestSessionsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(sessionsTestSuite))
+}
+
+func (suite *sessionsTestSuite) SetupTest() {
+	sessions, err := NewTableSessions("table-session-secret", time.Hour)
+	suite.Require().NoError(err)
+
+	suite.sessions = sessions
+}
+
+func (suite *sessionsTestSuite) TestNewTableSessions_MissingSecret() {
+	_, err := NewTableSessions("", time.Hour)
+
+	suite.Require().ErrorIs(err, ErrMissingSecret)
+}
+
+func (suite *sessionsTestSuite) TestIssue_Verify() {
+	orderID := uuid.New()
+	tableID := uuid.New()
+
+	issued, err := suite.sessions.Issue(orderID, tableID)
+	suite.Require().NoError(err)
+	suite.WithinDuration(time.Now().Add(time.Hour), issued.ExpiresAt, 2*time.Second)
+
+	session, err := suite.sessions.Verify(issued.Token)
+	suite.Require().NoError(err)
+	suite.Equal(orderID, session.OrderID)
+	suite.Equal(tableID, session.TableID)
+	suite.NotEqual(uuid.Nil, session.ParticipantID)
+	suite.Equal(issued.ParticipantID, session.ParticipantID)
+	suite.True(issued.ExpiresAt.Equal(session.ExpiresAt))
+}
+
+func (suite *sessionsTestSuite) TestVerify_Expired() {
+	expired, err := NewTableSessions("table-session-secret", -time.Minute)
+	suite.Require().NoError(err)
+
+	issued, err := expired.Issue(uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+
+	_, err = suite.sessions.Verify(issued.Token)
+	suite.Require().ErrorIs(err, ErrInvalidToken)
+}
+
+func (suite *sessionsTestSuite) TestVerify_OtherSecret() {
+	other, err := NewTableSessions("other-secret", time.Hour)
+	suite.Require().NoError(err)
+
+	issued, err := other.Issue(uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+
+	_, err = suite.sessions.Verify(issued.Token)
+	suite.Require().ErrorIs(err, ErrInvalidToken)
+}
+
+func (suite *sessionsTestSuite) TestVerify_StaffToken() {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":    uuid.New(),
+		"order_id":   uuid.New(),
+		"token_type": "access",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	})
+
+	tokenStr, err := token.SignedString([]byte("table-session-secret"))
+	suite.Require().NoError(err)
+
+	_, err = suite.sessions.Verify(tokenStr)
+	suite.Require().ErrorIs(err, ErrInvalidToken)
+
+	_, err = suite.sessions.Verify("not-a-token")
+	suite.Require().ErrorIs(err, ErrInvalidToken)
+}
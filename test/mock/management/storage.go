@@ -34,3 +34,7 @@ func (*mockStorage) StoreMenuItemImage(
 func (*mockStorage) DeleteMenuItemImage(_ context.Context, _ string) error {
 	return nil
 }
+
+func (*mockStorage) Close() error {
+	return nil
+}
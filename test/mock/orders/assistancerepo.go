@@ -0,0 +1,225 @@
+package orders
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockAssistanceRepo struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]*dto.AssistanceRequestDto
+}
+
+// NewMockAssistanceRepo returns assistance repo that keeps requests in memory, all of them
+// are of test restaurant's orders.
+func NewMockAssistanceRepo() *mockAssistanceRepo { //nolint:revive
+	return &mockAssistanceRepo{
+		mu:       sync.Mutex{},
+		requests: make(map[uuid.UUID]*dto.AssistanceRequestDto),
+	}
+}
+
+func (r *mockAssistanceRepo) CreateAssistanceRequest(
+	ctx context.Context,
+	reqDto *dto.CreateAssistanceRequestDto,
+	since time.Time,
+) (*dto.AssistanceRequestDto, error) {
+	if v, ok := ctx.Value(CtxFailCreateAssistanceRequest).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if v, ok := ctx.Value(CtxFinalizedOrder).(bool); ok && v {
+		return nil, repository.ErrOrderIsFinalized
+	}
+
+	request := &dto.AssistanceRequestDto{
+		ID:             uuid.New(),
+		OrderID:        reqDto.OrderID,
+		Type:           reqDto.Type,
+		Message:        reqDto.Message,
+		Status:         db.OrdersAssistanceRequestStatusOpen,
+		RequestedBy:    reqDto.RequestedBy,
+		CreatedAt:      time.Now(),
+		AcknowledgedAt: nil,
+		AcknowledgedBy: nil,
+		ResolvedAt:     nil,
+		ResolvedBy:     nil,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.requests {
+		if existing.OrderID == reqDto.OrderID &&
+			existing.Type == reqDto.Type &&
+			sameParticipant(existing.RequestedBy, reqDto.RequestedBy) &&
+			!existing.CreatedAt.Before(since) {
+			return nil, repository.ErrAssistanceRequestedRecently
+		}
+	}
+
+	r.requests[request.ID] = request
+
+	stored := *request
+
+	return &stored, nil
+}
+
+func (r *mockAssistanceRepo) GetAssistanceRequest(
+	_ context.Context,
+	orderID, requestID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[requestID]
+	if !ok || request.OrderID != orderID {
+		return nil, repository.ErrAssistanceRequestDoesNotExist
+	}
+
+	stored := *request
+
+	return &stored, nil
+}
+
+func (r *mockAssistanceRepo) GetUnresolvedAssistanceRequests(
+	ctx context.Context,
+	_ uuid.UUID,
+) ([]*dto.AssistanceRequestDto, error) {
+	if v, ok := ctx.Value(CtxFailGetUnresolvedAssistanceRequests).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	requests := make([]*dto.AssistanceRequestDto, 0, len(r.requests))
+
+	for _, request := range r.requests {
+		if request.Status != db.OrdersAssistanceRequestStatusResolved {
+			stored := *request
+			requests = append(requests, &stored)
+		}
+	}
+
+	return requests, nil
+}
+
+func (r *mockAssistanceRepo) AcknowledgeAssistanceRequest(
+	_ context.Context,
+	orderID, requestID, userID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[requestID]
+	if !ok || request.OrderID != orderID ||
+		request.Status != db.OrdersAssistanceRequestStatusOpen {
+		return nil, repository.ErrAssistanceRequestAlreadyHandled
+	}
+
+	now := time.Now()
+	request.Status = db.OrdersAssistanceRequestStatusAcknowledged
+	request.AcknowledgedAt = &now
+	request.AcknowledgedBy = &userID
+
+	updated := *request
+	updated.RequestedBy = nil
+
+	return &updated, nil
+}
+
+func (r *mockAssistanceRepo) ResolveAssistanceRequest(
+	_ context.Context,
+	orderID, requestID, userID uuid.UUID,
+) (*dto.AssistanceRequestDto, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.requests[requestID]
+	if !ok || request.OrderID != orderID ||
+		request.Status == db.OrdersAssistanceRequestStatusResolved {
+		return nil, repository.ErrAssistanceRequestAlreadyHandled
+	}
+
+	now := time.Now()
+	request.Status = db.OrdersAssistanceRequestStatusResolved
+	request.ResolvedAt = &now
+	request.ResolvedBy = &userID
+
+	if request.AcknowledgedAt == nil {
+		request.AcknowledgedAt = &now
+		request.AcknowledgedBy = &userID
+	}
+
+	updated := *request
+	updated.RequestedBy = nil
+
+	return &updated, nil
+}
+
+// GetAssistanceResponseTimes returns four requests created at the start of the shift: two
+// calls for the waiter, acknowledged by first and second waiter after 30 and 90 seconds and
+// the first resolved after 90 seconds, bill request acknowledged by first waiter after a
+// minute and resolved after five, and custom request nobody acknowledged.
+func (r *mockAssistanceRepo) GetAssistanceResponseTimes(
+	ctx context.Context,
+	_ *dto.AssistanceReportRequestDto,
+) ([]*dto.AssistanceResponseTimeDto, error) {
+	if v, ok := ctx.Value(CtxFailGetAssistanceResponseTimes).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	after := func(d time.Duration) *time.Time {
+		t := testShiftStart.Add(d)
+
+		return &t
+	}
+
+	return []*dto.AssistanceResponseTimeDto{
+		{
+			Type:           db.OrdersAssistanceRequestTypeCallWaiter,
+			CreatedAt:      testShiftStart,
+			AcknowledgedAt: after(30 * time.Second),
+			AcknowledgedBy: &testFirstWaiterID,
+			ResolvedAt:     after(90 * time.Second),
+		},
+		{
+			Type:           db.OrdersAssistanceRequestTypeCallWaiter,
+			CreatedAt:      testShiftStart,
+			AcknowledgedAt: after(90 * time.Second),
+			AcknowledgedBy: &testSecondWaiterID,
+			ResolvedAt:     nil,
+		},
+		{
+			Type:           db.OrdersAssistanceRequestTypeRequestBill,
+			CreatedAt:      testShiftStart,
+			AcknowledgedAt: after(time.Minute),
+			AcknowledgedBy: &testFirstWaiterID,
+			ResolvedAt:     after(5 * time.Minute),
+		},
+		{
+			Type:           db.OrdersAssistanceRequestTypeCustom,
+			CreatedAt:      testShiftStart,
+			AcknowledgedAt: nil,
+			AcknowledgedBy: nil,
+			ResolvedAt:     nil,
+		},
+	}, nil
+}
+
+// sameParticipant reports whether both requests were made by the same participant, requests
+// without participant count as the same participant's.
+func sameParticipant(a, b *dto.ParticipantDto) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.ID == b.ID
+}
@@ -7,6 +7,7 @@ import (
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
 	"golang-dining-ordering/services/orders/repository"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,8 @@ var (
 	testOrderID          = uuid.MustParse("99999999-9999-4999-9999-999999999999")
 	testCompletedOrderID = uuid.MustParse("77777777-7777-7777-7777-777777777777")
 	testTableID          = uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
+	testTableSection     = "Terrace"
+	testWaiterID         = uuid.MustParse("12121212-1212-4121-8121-121212121212")
 	testDateTime         = time.Date(
 		2025,
 		time.December,
@@ -36,10 +39,21 @@ var (
 	testOrderItemID                 = uuid.MustParse("aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa")
 	testItemID                      = uuid.MustParse("bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb")
 	testDifferentRestaurantItemID   = uuid.MustParse("bbbbbbbb-bbbb-4bbb-8bbb-aaaaaaaaaaaa")
+	testUnavailableItemID           = uuid.MustParse("bbbbbbbb-bbbb-4bbb-8bbb-cccccccccccc")
+	testStaffDisplayName            = "Test Waiter"
+	testGuestDisplayName            = "Test Guest"
 	testCheckoutURL                 = "http://fake-checkout-session.com/1"
 	testPaymentProvider             = db.OrdersPaymentProviderMock
 	testProviderPaymentID           = "pi_123456"
+	testProviderRefundID            = "re_123456"
+	testProviderEventID             = "evt_123456"
+	testProviderSessionID           = "cs_123456"
+	testPaymentAttemptID            = uuid.MustParse("68686868-6868-4686-8868-686868686868")
 	testUserFromAnotherRestaurantID = uuid.MustParse("69696969-6969-6969-6969-696969696969")
+	testWebhooksURL                 = "http://fake-webhooks.com"
+	testMissingProviderPaymentID    = "pi_missing"
+	testMismatchedProviderPaymentID = "pi_mismatched"
+	testExtraProviderPaymentID      = "pi_extra"
 )
 
 var (
@@ -61,10 +75,119 @@ const (
 	CtxFailCreateOrderForTable CtxKey = "fail-CreateOrderForTable"
 	// CtxFailAddItemToOrder is a context key to simulate AddItemToOrder failure in tests.
 	CtxFailAddItemToOrder CtxKey = "fail-AddItemToOrder"
+	// CtxFailSetOrderPaymentReview is a context key to simulate SetOrderPaymentReview failure in tests.
+	CtxFailSetOrderPaymentReview CtxKey = "fail-SetOrderPaymentReview"
+	// CtxUnderpaidOrder is a context key to simulate order that is paid less than its total.
+	CtxUnderpaidOrder CtxKey = "underpaid-order"
+	// CtxOverpaidOrder is a context key to simulate order that is paid more than its total.
+	CtxOverpaidOrder CtxKey = "overpaid-order"
+	// CtxFailRefund is a context key to simulate payment provider Refund failure in tests.
+	CtxFailRefund CtxKey = "fail-Refund"
+	// CtxFailSaveRefund is a context key to simulate SaveRefund failure in tests.
+	CtxFailSaveRefund CtxKey = "fail-SaveRefund"
+	// CtxFailMarkPaymentRefunded is a context key to simulate MarkPaymentRefunded failure in tests.
+	CtxFailMarkPaymentRefunded CtxKey = "fail-MarkPaymentRefunded"
+	// CtxFailGetProvider is a context key to simulate restaurant without payment provider in tests.
+	CtxFailGetProvider CtxKey = "fail-GetProvider"
+	// CtxPlatformProvider is a context key to simulate restaurant paid to the platform account.
+	CtxPlatformProvider CtxKey = "platform-provider"
+	// CtxFailSavePaymentProvider is a context key to simulate SaveRestaurantPaymentProvider failure.
+	CtxFailSavePaymentProvider CtxKey = "fail-SavePaymentProvider"
+	// CtxWebhookEventProcessed is a context key to simulate webhook event that is already processed.
+	CtxWebhookEventProcessed CtxKey = "webhook-event-processed"
+	// CtxPaymentAlreadySaved is a context key to simulate payment that is already saved.
+	CtxPaymentAlreadySaved CtxKey = "payment-already-saved"
+	// CtxFailSavePayment is a context key to simulate SavePayment failure in tests.
+	CtxFailSavePayment CtxKey = "fail-SavePayment"
+	// CtxFailUpdateRefundFromProvider is a context key to simulate UpdateRefundFromProvider
+	// failure in tests.
+	CtxFailUpdateRefundFromProvider CtxKey = "fail-UpdateRefundFromProvider"
+	// CtxRefundSucceeded is a context key to simulate refund that provider makes right away.
+	CtxRefundSucceeded CtxKey = "refund-succeeded"
+	// CtxFailUpdateRefundStatus is a context key to simulate UpdateRefundStatus failure in tests.
+	CtxFailUpdateRefundStatus CtxKey = "fail-UpdateRefundStatus"
+	// CtxFailUpdatePaymentStatus is a context key to simulate UpdatePaymentStatus failure in tests.
+	CtxFailUpdatePaymentStatus CtxKey = "fail-UpdatePaymentStatus"
+	// CtxLockedOrder is a context key to simulate order that is locked for payment.
+	CtxLockedOrder CtxKey = "locked-order"
+	// CtxFailSavePaymentAttempt is a context key to simulate SavePaymentAttempt failure in tests.
+	CtxFailSavePaymentAttempt CtxKey = "fail-SavePaymentAttempt"
+	// CtxFailUpdatePaymentAttemptStatus is a context key to simulate UpdatePaymentAttemptStatus
+	// failure in tests.
+	CtxFailUpdatePaymentAttemptStatus CtxKey = "fail-UpdatePaymentAttemptStatus"
+	// CtxFailListCharges is a context key to simulate ListCharges failure in tests.
+	CtxFailListCharges CtxKey = "fail-ListCharges"
+	// CtxFailGetProviderPayments is a context key to simulate GetProviderPayments failure in tests.
+	CtxFailGetProviderPayments CtxKey = "fail-GetProviderPayments"
+	// CtxFailGetTipSettings is a context key to simulate GetTipSettings failure in tests.
+	CtxFailGetTipSettings CtxKey = "fail-GetTipSettings"
+	// CtxFailSaveTipSettings is a context key to simulate SaveTipSettings failure in tests.
+	CtxFailSaveTipSettings CtxKey = "fail-SaveTipSettings"
+	// CtxFailGetCompletedOrdersWaiters is a context key to simulate GetCompletedOrdersWaiters
+	// failure in tests.
+	CtxFailGetCompletedOrdersWaiters CtxKey = "fail-GetCompletedOrdersWaiters"
+	// CtxFailGetPromoCodes is a context key to simulate GetPromoCodes failure in tests.
+	CtxFailGetPromoCodes CtxKey = "fail-GetPromoCodes"
+	// CtxFailCreatePromoCode is a context key to simulate CreatePromoCode failure in tests.
+	CtxFailCreatePromoCode CtxKey = "fail-CreatePromoCode"
+	// CtxFailGetPromotionRules is a context key to simulate GetPromotionRules failure in tests.
+	CtxFailGetPromotionRules CtxKey = "fail-GetPromotionRules"
+	// CtxFailCreatePromotionRule is a context key to simulate CreatePromotionRule failure in tests.
+	CtxFailCreatePromotionRule CtxKey = "fail-CreatePromotionRule"
+	// CtxFailApplyPromoCodeToOrder is a context key to simulate ApplyPromoCodeToOrder failure
+	// in tests.
+	CtxFailApplyPromoCodeToOrder CtxKey = "fail-ApplyPromoCodeToOrder"
+	// CtxFailGetReceiptSettings is a context key to simulate GetReceiptSettings failure in tests.
+	CtxFailGetReceiptSettings CtxKey = "fail-GetReceiptSettings"
+	// CtxFailSaveReceiptSettings is a context key to simulate SaveReceiptSettings failure in tests.
+	CtxFailSaveReceiptSettings CtxKey = "fail-SaveReceiptSettings"
+	// CtxFailIssueReceipt is a context key to simulate IssueReceipt failure in tests.
+	CtxFailIssueReceipt CtxKey = "fail-IssueReceipt"
+	// CtxFailIsUserRestaurantMember is a context key to simulate failure of waiter and manager
+	// checks in tests.
+	CtxFailIsUserRestaurantMember CtxKey = "fail-IsUserRestaurantMember"
+	// CtxFailGetReceipt is a context key to simulate GetReceipt failure in tests.
+	CtxFailGetReceipt CtxKey = "fail-GetReceipt"
+	// CtxFailSaveReceiptContents is a context key to simulate SaveReceiptContents failure in
+	// tests.
+	CtxFailSaveReceiptContents CtxKey = "fail-SaveReceiptContents"
+	// CtxReceiptNotIssued is a context key to simulate completed order without receipt.
+	CtxReceiptNotIssued CtxKey = "receipt-not-issued"
+	// CtxFailGetPrinters is a context key to simulate GetPrinters failure in tests.
+	CtxFailGetPrinters CtxKey = "fail-GetPrinters"
+	// CtxFailCreatePrinter is a context key to simulate CreatePrinter failure in tests.
+	CtxFailCreatePrinter CtxKey = "fail-CreatePrinter"
+	// CtxFailClaimKitchenItems is a context key to simulate ClaimKitchenItems failure in tests.
+	CtxFailClaimKitchenItems CtxKey = "fail-ClaimKitchenItems"
+	// CtxFailReleaseKitchenItems is a context key to simulate ReleaseKitchenItems failure in tests.
+	CtxFailReleaseKitchenItems CtxKey = "fail-ReleaseKitchenItems"
+	// CtxFinalizedOrder is a context key to simulate order that is completed or cancelled.
+	CtxFinalizedOrder CtxKey = "finalized-order"
+	// CtxFailCreateAssistanceRequest is a context key to simulate CreateAssistanceRequest
+	// failure in tests.
+	CtxFailCreateAssistanceRequest CtxKey = "fail-CreateAssistanceRequest"
+	// CtxFailGetUnresolvedAssistanceRequests is a context key to simulate
+	// GetUnresolvedAssistanceRequests failure in tests.
+	CtxFailGetUnresolvedAssistanceRequests CtxKey = "fail-GetUnresolvedAssistanceRequests"
+	// CtxFailGetAssistanceResponseTimes is a context key to simulate
+	// GetAssistanceResponseTimes failure in tests.
+	CtxFailGetAssistanceResponseTimes CtxKey = "fail-GetAssistanceResponseTimes"
+	// CtxFailSaveOrderDiscounts is a context key to simulate SaveOrderDiscounts failure in tests.
+	CtxFailSaveOrderDiscounts CtxKey = "fail-SaveOrderDiscounts"
+	// CtxParticipantNotJoined is a context key to simulate guest who hasn't joined the order
+	// yet in tests.
+	CtxParticipantNotJoined CtxKey = "participant-not-joined"
+	// CtxOrderItemRefunded is a context key to simulate order item that is already refunded
+	// in tests.
+	CtxOrderItemRefunded CtxKey = "order-item-refunded"
 )
 
 type mockOrdersRepo struct {
 	orderDto *dto.OrderDto
+
+	mu sync.Mutex
+	// discounts holds discount lines saved for each order
+	discounts map[uuid.UUID][]*dto.DiscountLineDto
 }
 
 func NewMockOrdersRepo() *mockOrdersRepo { //nolint:revive
@@ -77,6 +200,7 @@ func NewMockOrdersRepo() *mockOrdersRepo { //nolint:revive
 			Currency:          testCurrency,
 			TipAmountInCents:  testAmount,
 			TotalPriceInCents: testAmount,
+			BalanceDueInCents: testAmount * 2, //nolint:mnd
 			UpdatedAt:         testDateTime,
 			Items: []*dto.OrderItemDto{
 				{
@@ -88,9 +212,21 @@ func NewMockOrdersRepo() *mockOrdersRepo { //nolint:revive
 				},
 			},
 		},
+		mu:        sync.Mutex{},
+		discounts: make(map[uuid.UUID][]*dto.DiscountLineDto),
 	}
 }
 
+// SavedDiscounts returns discount lines saved for the order and whether any were saved.
+func (r *mockOrdersRepo) SavedDiscounts(orderID uuid.UUID) ([]*dto.DiscountLineDto, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	discounts, ok := r.discounts[orderID]
+
+	return discounts, ok
+}
+
 func (r *mockOrdersRepo) GetCurrentOrderForTable(
 	_ context.Context,
 	tableID uuid.UUID,
@@ -134,6 +270,7 @@ func (r *mockOrdersRepo) AddItemToOrder(
 	ctx context.Context,
 	_ uuid.UUID,
 	_ *dto.OrderItemDto,
+	addedBy *dto.ParticipantDto,
 ) (*dto.OrderItemDto, error) {
 	if v, ok := ctx.Value(CtxFailAddItemToOrder).(bool); ok && v {
 		return nil, ErrRepoFailed
@@ -145,13 +282,14 @@ func (r *mockOrdersRepo) AddItemToOrder(
 		ItemID:       testItemID,
 		Name:         testItemName,
 		PriceInCents: testAmount,
+		AddedBy:      addedBy,
 	}
 
 	return orderItemDto, nil
 }
 
 func (r *mockOrdersRepo) GetOrderItems(
-	_ context.Context,
+	ctx context.Context,
 	orderID uuid.UUID,
 ) (*dto.OrderDto, error) {
 	if orderID == testCompletedOrderID {
@@ -167,9 +305,69 @@ func (r *mockOrdersRepo) GetOrderItems(
 
 	respDto := *r.orderDto
 
+	if v, ok := ctx.Value(CtxLockedOrder).(bool); ok && v {
+		respDto.Status = db.OrderStatusLocked
+	}
+
 	return &respDto, nil
 }
 
+func (r *mockOrdersRepo) LockOrder(_ context.Context, orderID uuid.UUID) error {
+	if orderID != testOrderID && orderID != testCompletedOrderID {
+		return repository.ErrOrderDoesNotExist
+	}
+
+	return nil
+}
+
+func (r *mockOrdersRepo) SaveOrderDiscounts(
+	ctx context.Context,
+	orderID uuid.UUID,
+	discounts []*dto.DiscountLineDto,
+) error {
+	if v, ok := ctx.Value(CtxFailSaveOrderDiscounts).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.discounts[orderID] = discounts
+
+	return nil
+}
+
+func (r *mockOrdersRepo) GetOrderTable(
+	_ context.Context,
+	orderID uuid.UUID,
+) (*dto.OrderTableDto, error) {
+	if orderID != testOrderID {
+		return nil, repository.ErrOrderDoesNotExist
+	}
+
+	section := testTableSection
+
+	return &dto.OrderTableDto{
+		TableID:      testTableID,
+		RestaurantID: testRestaurantID,
+		TableName:    testTableName,
+		Section:      &section,
+		WaiterIDs:    []uuid.UUID{testWaiterID},
+	}, nil
+}
+
+func (r *mockOrdersRepo) SetOrderPaymentReview(
+	ctx context.Context,
+	_ uuid.UUID,
+	_ *db.OrdersPaymentReview,
+) error {
+	if v, ok := ctx.Value(CtxFailSetOrderPaymentReview).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	return nil
+}
+
 func (r *mockOrdersRepo) GetMenuItem(
 	_ context.Context,
 	itemID uuid.UUID,
@@ -181,6 +379,10 @@ func (r *mockOrdersRepo) GetMenuItem(
 		return &item, nil
 	}
 
+	if itemID == testUnavailableItemID {
+		return nil, repository.ErrMenuItemUnavailable
+	}
+
 	if itemID != testItemID {
 		return nil, ErrRepoFailed
 	}
@@ -230,14 +432,40 @@ func (r *mockOrdersRepo) UpdateOrder(
 	}, nil
 }
 
+func (r *mockOrdersRepo) IssueReceipt(
+	ctx context.Context,
+	orderID, restaurantID uuid.UUID,
+	contents *dto.ReceiptContentsDto,
+) (*dto.IssuedReceiptDto, error) {
+	if v, ok := ctx.Value(CtxFailIssueReceipt).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return &dto.IssuedReceiptDto{
+		ID:                testReceiptID,
+		OrderID:           orderID,
+		RestaurantID:      restaurantID,
+		Number:            testReceiptNumber,
+		TaxRate:           testTaxRate,
+		IssuedAt:          testDateTime,
+		Contents:          contents,
+		RestaurantName:    testRestaurantName,
+		RestaurantAddress: testRestaurantAddress,
+	}, nil
+}
+
 func (r *mockOrdersRepo) IsUserRestaurantWaiter(
-	_ context.Context,
+	ctx context.Context,
 	userID, _ uuid.UUID,
 ) error {
-	if userID == testUserFromAnotherRestaurantID {
+	if v, ok := ctx.Value(CtxFailIsUserRestaurantMember).(bool); ok && v {
 		return ErrRepoFailed
 	}
 
+	if userID == testUserFromAnotherRestaurantID {
+		return repository.ErrUserIsNotRestaurantMember
+	}
+
 	return nil
 }
 
@@ -256,3 +484,56 @@ func (r *mockOrdersRepo) RemoveWaiter(_ context.Context, orderID, _, _ uuid.UUID
 
 	return nil
 }
+
+func (r *mockOrdersRepo) GetOrderParticipant(
+	ctx context.Context,
+	_, participantID uuid.UUID,
+) (*dto.ParticipantDto, error) {
+	if v, ok := ctx.Value(CtxParticipantNotJoined).(bool); ok && v {
+		return nil, repository.ErrParticipantDoesNotExist
+	}
+
+	return &dto.ParticipantDto{
+		ID:          participantID,
+		DisplayName: testGuestDisplayName,
+		Role:        db.OrdersParticipantRoleGuest,
+	}, nil
+}
+
+func (r *mockOrdersRepo) SaveGuestParticipant(
+	_ context.Context,
+	_, participantID uuid.UUID,
+	displayName string,
+) (*dto.ParticipantDto, error) {
+	return &dto.ParticipantDto{
+		ID:          participantID,
+		DisplayName: displayName,
+		Role:        db.OrdersParticipantRoleGuest,
+	}, nil
+}
+
+func (r *mockOrdersRepo) SaveStaffParticipant(
+	_ context.Context,
+	_, userID uuid.UUID,
+) (*dto.ParticipantDto, error) {
+	return &dto.ParticipantDto{
+		ID:          userID,
+		DisplayName: testStaffDisplayName,
+		Role:        db.OrdersParticipantRoleStaff,
+	}, nil
+}
+
+func (r *mockOrdersRepo) IsUserRestaurantManager(
+	ctx context.Context,
+	userID, _ uuid.UUID,
+) error {
+	if v, ok := ctx.Value(CtxFailIsUserRestaurantMember).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	if userID == testUserFromAnotherRestaurantID {
+		return repository.ErrUserIsNotRestaurantMember
+	}
+
+	return nil
+}
@@ -2,9 +2,14 @@ package orders
 
 import (
 	"context"
+	"encoding/json"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/paymentproviders"
 	"net/http"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type mockPaymentsProvider struct {
@@ -26,29 +31,203 @@ func (p *mockPaymentsProvider) CreateCheckoutSession(
 	}
 
 	return &dto.CheckoutSessionResponseDto{
-		URL:      testCheckoutURL,
-		Provider: p.provider,
+		URL:              testCheckoutURL,
+		Provider:         p.provider,
+		SessionID:        testProviderSessionID,
+		PaymentAttemptID: req.PaymentAttemptID,
+	}, nil
+}
+
+func (p *mockPaymentsProvider) Refund(
+	ctx context.Context,
+	req *dto.ProviderRefundRequestDto,
+) (*dto.RefundDto, error) {
+	if v, ok := ctx.Value(CtxFailRefund).(bool); ok && v {
+		return nil, ErrPaymentProviderFailed
+	}
+
+	status := db.OrdersRefundStatusPending
+	if v, ok := ctx.Value(CtxRefundSucceeded).(bool); ok && v {
+		status = db.OrdersRefundStatusSucceeded
+	}
+
+	return &dto.RefundDto{
+		ID:               req.RefundID,
+		PaymentID:        req.Payment.ID,
+		OrderID:          req.Payment.OrderID,
+		AmountInCents:    req.AmountInCents,
+		Currency:         req.Payment.Currency,
+		Provider:         p.provider,
+		ProviderRefundID: testProviderRefundID,
+		Status:           status,
+		Reason:           req.Reason,
 	}, nil
 }
 
-func (p *mockPaymentsProvider) VerifySuccessWebhookEvent(
+// ParseWebhookEvent returns event of the type sent in payload, order, session and provider's
+// payment and refund ids default to test ones. Payload that isn't json fails verification.
+func (p *mockPaymentsProvider) ParseWebhookEvent(
 	payload []byte,
 	_ http.Header,
-) (*dto.PaymentDto, error) {
-	if len(payload) == 0 {
-		return nil, ErrPaymentProviderFailed
+) (*dto.ProviderEventDto, error) {
+	var req struct {
+		Type              dto.ProviderEventType `json:"type"`
+		OrderID           *uuid.UUID            `json:"order_id"`
+		ProviderPaymentID *string               `json:"provider_payment_id"`
+		ProviderRefundID  *string               `json:"provider_refund_id"`
 	}
 
-	if len(payload) == 1 {
-		return &dto.PaymentDto{}, nil
+	err := json.Unmarshal(payload, &req)
+	if err != nil {
+		return nil, ErrPaymentProviderFailed
 	}
 
-	return &dto.PaymentDto{
-		ID:                testPaymentID,
+	event := &dto.ProviderEventDto{
+		ID:                testProviderEventID,
+		Type:              req.Type,
 		OrderID:           testOrderID,
-		AmountInCents:     testAmount,
-		Provider:          p.provider,
+		SessionID:         testProviderSessionID,
 		ProviderPaymentID: testProviderPaymentID,
-		Currency:          testCurrency,
+	}
+
+	if req.OrderID != nil {
+		event.OrderID = *req.OrderID
+	}
+
+	if req.ProviderPaymentID != nil {
+		event.ProviderPaymentID = *req.ProviderPaymentID
+	}
+
+	switch event.Type {
+	case dto.ProviderEventPaymentSucceeded:
+		event.Payment = &dto.PaymentDto{
+			ID:                testPaymentID,
+			OrderID:           event.OrderID,
+			AmountInCents:     testAmount,
+			Provider:          p.provider,
+			ProviderPaymentID: event.ProviderPaymentID,
+			Currency:          testCurrency,
+			Status:            db.OrdersPaymentStatusSucceeded,
+		}
+	case dto.ProviderEventRefundUpdated:
+		event.Refund = &dto.RefundDto{
+			AmountInCents:    testAmount,
+			Currency:         testCurrency,
+			Provider:         p.provider,
+			ProviderRefundID: testProviderRefundID,
+			Status:           db.OrdersRefundStatusSucceeded,
+		}
+
+		if req.ProviderRefundID != nil {
+			event.Refund.ProviderRefundID = *req.ProviderRefundID
+		}
+	}
+
+	return event, nil
+}
+
+// ListCharges returns test payment's charge, charge with a different amount than the payment
+// saved for it, charge there is no payment for and one charged before the test day.
+func (p *mockPaymentsProvider) ListCharges(
+	ctx context.Context,
+	_ *dto.ListChargesRequestDto,
+) ([]*dto.ProviderChargeDto, error) {
+	if v, ok := ctx.Value(CtxFailListCharges).(bool); ok && v {
+		return nil, ErrPaymentProviderFailed
+	}
+
+	return []*dto.ProviderChargeDto{
+		{
+			ProviderPaymentID: testProviderPaymentID,
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+		{
+			ProviderPaymentID: testMismatchedProviderPaymentID,
+			AmountInCents:     testAmount * 2,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+		{
+			ProviderPaymentID: testExtraProviderPaymentID,
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+		{
+			ProviderPaymentID: "pi_previous_day",
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime.Add(-24 * time.Hour),
+		},
 	}, nil
 }
+
+type mockProvidersRegistry struct {
+	provider *mockPaymentsProvider
+}
+
+func NewMockProvidersRegistry() *mockProvidersRegistry { //nolint:revive
+	return &mockProvidersRegistry{
+		provider: NewMockPaymentsProvider(),
+	}
+}
+
+//nolint:ireturn
+func (r *mockProvidersRegistry) Get(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	_ db.OrdersPaymentProvider,
+) (paymentproviders.PaymentProvider, error) {
+	if v, ok := ctx.Value(CtxFailGetProvider).(bool); ok && v {
+		return nil, paymentproviders.ErrProviderNotConfigured
+	}
+
+	if restaurantID == uuid.Max {
+		return nil, paymentproviders.ErrProviderNotConfigured
+	}
+
+	return r.provider, nil
+}
+
+// GetOwn fails for restaurants paid to the platform account, simulated with CtxPlatformProvider.
+//
+//nolint:ireturn
+func (r *mockProvidersRegistry) GetOwn(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) (paymentproviders.PaymentProvider, error) {
+	if v, ok := ctx.Value(CtxPlatformProvider).(bool); ok && v {
+		return nil, paymentproviders.ErrProviderNotConfigured
+	}
+
+	return r.Get(ctx, restaurantID, provider)
+}
+
+//nolint:ireturn
+func (r *mockProvidersRegistry) GetDefault(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (paymentproviders.PaymentProvider, error) {
+	return r.Get(ctx, restaurantID, r.provider.provider)
+}
+
+//nolint:ireturn
+func (r *mockProvidersRegistry) GetPlatform(
+	provider db.OrdersPaymentProvider,
+) (paymentproviders.PaymentProvider, error) {
+	if provider != r.provider.provider {
+		return nil, paymentproviders.ErrProviderNotConfigured
+	}
+
+	return r.provider, nil
+}
+
+func (r *mockProvidersRegistry) WebhookURL(
+	restaurantID uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) string {
+	return paymentproviders.WebhookURL(testWebhooksURL, provider, restaurantID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	db "golang-dining-ordering/services/orders/db/generated"
 	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
 
 	"github.com/google/uuid"
 )
@@ -14,20 +15,226 @@ func NewMockPaymentsRepo() *mockPaymentsRepo { //nolint:revive
 	return &mockPaymentsRepo{}
 }
 
+func (r *mockPaymentsRepo) RunInTx(_ context.Context, fn repository.TxFunc) error {
+	return fn(NewMockOrdersRepo(), r)
+}
+
+func (r *mockPaymentsRepo) ProcessWebhookEvent(
+	ctx context.Context,
+	_ *dto.WebhookEventDto,
+	handle repository.TxFunc,
+) error {
+	if v, ok := ctx.Value(CtxWebhookEventProcessed).(bool); ok && v {
+		return repository.ErrWebhookEventAlreadyProcessed
+	}
+
+	return handle(NewMockOrdersRepo(), r)
+}
+
 func (r *mockPaymentsRepo) SavePayment(
-	_ context.Context,
+	ctx context.Context,
 	reqDto *dto.PaymentDto,
 ) (*dto.PaymentDto, error) {
 	if reqDto.OrderID == uuid.Nil {
 		return nil, ErrRepoFailed
 	}
 
+	if v, ok := ctx.Value(CtxFailSavePayment).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if v, ok := ctx.Value(CtxPaymentAlreadySaved).(bool); ok && v {
+		return nil, repository.ErrPaymentAlreadySaved
+	}
+
+	id := testPaymentID
+	if reqDto.ID != uuid.Nil {
+		id = reqDto.ID
+	}
+
 	return &dto.PaymentDto{
-		ID:                testPaymentID,
+		ID:                id,
 		OrderID:           testOrderID,
 		AmountInCents:     testAmount,
 		Provider:          db.OrdersPaymentProviderMock,
 		ProviderPaymentID: testProviderPaymentID,
 		Currency:          testCurrency,
+		Status:            db.OrdersPaymentStatusSucceeded,
+		TenderedInCents:   reqDto.TenderedInCents,
+		ChangeDueInCents:  reqDto.ChangeDueInCents,
+		RecordedBy:        reqDto.RecordedBy,
+	}, nil
+}
+
+func (r *mockPaymentsRepo) GetOrderAmountPaid(ctx context.Context, _ uuid.UUID) (int, error) {
+	if v, ok := ctx.Value(CtxUnderpaidOrder).(bool); ok && v {
+		return testAmount, nil
+	}
+
+	if v, ok := ctx.Value(CtxOverpaidOrder).(bool); ok && v {
+		return testAmount * 3, nil //nolint:mnd
+	}
+
+	// mock order total and tip are both testAmount, so it's paid in full
+	return testAmount * 2, nil //nolint:mnd
+}
+
+func (r *mockPaymentsRepo) GetOrderPayments(
+	_ context.Context,
+	orderID uuid.UUID,
+) ([]*dto.PaymentDto, error) {
+	if orderID != testOrderID {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.PaymentDto{
+		{
+			ID:                    testPaymentID,
+			OrderID:               testOrderID,
+			AmountInCents:         testAmount * 2, //nolint:mnd
+			Provider:              db.OrdersPaymentProviderMock,
+			ProviderPaymentID:     testProviderPaymentID,
+			Currency:              testCurrency,
+			RefundedAmountInCents: 0,
+			Status:                db.OrdersPaymentStatusSucceeded,
+			RecordedBy:            &testWaiterID,
+		},
+	}, nil
+}
+
+func (r *mockPaymentsRepo) GetOrderRefundedItemIDs(
+	ctx context.Context,
+	_ uuid.UUID,
+) ([]uuid.UUID, error) {
+	if v, ok := ctx.Value(CtxOrderItemRefunded).(bool); ok && v {
+		return []uuid.UUID{testOrderItemID}, nil
+	}
+
+	return nil, nil
+}
+
+func (r *mockPaymentsRepo) SaveRefund(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	if v, ok := ctx.Value(CtxFailSaveRefund).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	respDto := *reqDto
+
+	return &respDto, nil
+}
+
+func (r *mockPaymentsRepo) UpdateRefundFromProvider(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	if v, ok := ctx.Value(CtxFailUpdateRefundFromProvider).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	respDto := *reqDto
+
+	return &respDto, nil
+}
+
+func (r *mockPaymentsRepo) UpdateRefundStatus(
+	ctx context.Context,
+	reqDto *dto.RefundDto,
+) (*dto.RefundDto, error) {
+	if reqDto.ProviderRefundID != testProviderRefundID && reqDto.ID == uuid.Nil {
+		return nil, repository.ErrRefundDoesNotExist
+	}
+
+	if v, ok := ctx.Value(CtxFailUpdateRefundStatus).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	respDto := *reqDto
+	respDto.PaymentID = testPaymentID
+	respDto.OrderID = testOrderID
+
+	return &respDto, nil
+}
+
+func (r *mockPaymentsRepo) MarkPaymentRefunded(ctx context.Context, _ uuid.UUID) error {
+	if v, ok := ctx.Value(CtxFailMarkPaymentRefunded).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	return nil
+}
+
+func (r *mockPaymentsRepo) UpdatePaymentStatus(
+	ctx context.Context,
+	reqDto *dto.PaymentDto,
+) (*dto.PaymentDto, error) {
+	if v, ok := ctx.Value(CtxFailUpdatePaymentStatus).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if reqDto.ProviderPaymentID != testProviderPaymentID {
+		return nil, repository.ErrPaymentDoesNotExist
+	}
+
+	return &dto.PaymentDto{
+		ID:                testPaymentID,
+		OrderID:           testOrderID,
+		AmountInCents:     testAmount * 2, //nolint:mnd
+		Provider:          reqDto.Provider,
+		ProviderPaymentID: reqDto.ProviderPaymentID,
+		Currency:          testCurrency,
+		Status:            reqDto.Status,
+	}, nil
+}
+
+func (r *mockPaymentsRepo) SavePaymentAttempt(
+	ctx context.Context,
+	reqDto *dto.PaymentAttemptDto,
+) (*dto.PaymentAttemptDto, error) {
+	if v, ok := ctx.Value(CtxFailSavePaymentAttempt).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	respDto := *reqDto
+	respDto.Status = db.OrdersPaymentAttemptStatusPending
+	respDto.CreatedAt = testDateTime
+	respDto.UpdatedAt = testDateTime
+
+	return &respDto, nil
+}
+
+func (r *mockPaymentsRepo) UpdatePaymentAttemptStatus(
+	ctx context.Context,
+	_ *dto.PaymentAttemptDto,
+) error {
+	if v, ok := ctx.Value(CtxFailUpdatePaymentAttemptStatus).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	return nil
+}
+
+func (r *mockPaymentsRepo) GetOrderPaymentAttempts(
+	_ context.Context,
+	orderID uuid.UUID,
+) ([]*dto.PaymentAttemptDto, error) {
+	if orderID != testOrderID {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.PaymentAttemptDto{
+		{
+			ID:                testPaymentAttemptID,
+			OrderID:           testOrderID,
+			Provider:          db.OrdersPaymentProviderMock,
+			ProviderSessionID: testProviderSessionID,
+			AmountInCents:     testAmount * 2, //nolint:mnd
+			Currency:          testCurrency,
+			Status:            db.OrdersPaymentAttemptStatusSucceeded,
+			CreatedAt:         testDateTime,
+			UpdatedAt:         testDateTime,
+		},
 	}, nil
 }
@@ -0,0 +1,179 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+//nolint:gochecknoglobals
+var (
+	testKitchenPrinterID = uuid.MustParse("f1f1f1f1-f1f1-4f1f-8f1f-f1f1f1f1f1f1")
+	testReceiptPrinterID = uuid.MustParse("f2f2f2f2-f2f2-4f2f-8f2f-f2f2f2f2f2f2")
+	testTableName        = "T1"
+)
+
+type mockPrintersRepo struct {
+	mu       sync.Mutex
+	printers []*dto.PrinterDto
+	sent     map[uuid.UUID]bool
+}
+
+// NewMockPrintersRepo returns printers repo where test restaurant has a kitchen printer and
+// a receipt printer, both listening on host and port, and test order has one item which
+// wasn't sent to the kitchen yet.
+func NewMockPrintersRepo(host string, port int) *mockPrintersRepo { //nolint:revive
+	return &mockPrintersRepo{
+		mu: sync.Mutex{},
+		printers: []*dto.PrinterDto{
+			{
+				ID:             testKitchenPrinterID,
+				RestaurantID:   testRestaurantID,
+				Name:           "Kitchen",
+				Host:           host,
+				Port:           port,
+				CategoryIDs:    []uuid.UUID{},
+				PrintsReceipts: false,
+				CreatedAt:      testDateTime,
+			},
+			{
+				ID:             testReceiptPrinterID,
+				RestaurantID:   testRestaurantID,
+				Name:           "Bar",
+				Host:           host,
+				Port:           port,
+				CategoryIDs:    []uuid.UUID{},
+				PrintsReceipts: true,
+				CreatedAt:      testDateTime,
+			},
+		},
+		sent: map[uuid.UUID]bool{},
+	}
+}
+
+func (r *mockPrintersRepo) GetPrinters(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.PrinterDto, error) {
+	if v, ok := ctx.Value(CtxFailGetPrinters).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	printers := make([]*dto.PrinterDto, 0, len(r.printers))
+
+	for _, printer := range r.printers {
+		if printer.RestaurantID == restaurantID {
+			printers = append(printers, printer)
+		}
+	}
+
+	return printers, nil
+}
+
+func (r *mockPrintersRepo) CreatePrinter(
+	ctx context.Context,
+	reqDto *dto.CreatePrinterRequestDto,
+) (*dto.PrinterDto, error) {
+	if v, ok := ctx.Value(CtxFailCreatePrinter).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, printer := range r.printers {
+		if printer.RestaurantID == reqDto.RestaurantID && printer.Name == reqDto.Name {
+			return nil, fmt.Errorf("%w: %s", repository.ErrPrinterAlreadyExists, reqDto.Name)
+		}
+	}
+
+	printer := &dto.PrinterDto{
+		ID:             uuid.New(),
+		RestaurantID:   reqDto.RestaurantID,
+		Name:           reqDto.Name,
+		Host:           reqDto.Host,
+		Port:           reqDto.Port,
+		CategoryIDs:    reqDto.CategoryIDs,
+		PrintsReceipts: reqDto.PrintsReceipts,
+		CreatedAt:      testDateTime,
+	}
+	r.printers = append(r.printers, printer)
+
+	return printer, nil
+}
+
+func (r *mockPrintersRepo) DeletePrinter(
+	_ context.Context,
+	restaurantID, printerID uuid.UUID,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, printer := range r.printers {
+		if printer.RestaurantID == restaurantID && printer.ID == printerID {
+			r.printers = append(r.printers[:i], r.printers[i+1:]...)
+
+			return nil
+		}
+	}
+
+	return repository.ErrPrinterDoesNotExist
+}
+
+func (r *mockPrintersRepo) ClaimKitchenItems(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.KitchenOrderDto, error) {
+	if v, ok := ctx.Value(CtxFailClaimKitchenItems).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kitchenOrder := &dto.KitchenOrderDto{
+		OrderID:      orderID,
+		RestaurantID: testRestaurantID,
+		TableName:    testTableName,
+		Items:        []*dto.KitchenItemDto{},
+	}
+
+	if orderID != testOrderID || r.sent[testOrderItemID] {
+		return kitchenOrder, nil
+	}
+
+	r.sent[testOrderItemID] = true
+	kitchenOrder.Items = append(kitchenOrder.Items, &dto.KitchenItemDto{
+		OrderItemID: testOrderItemID,
+		Name:        testItemName,
+		CategoryID:  nil,
+		CreatedAt:   testDateTime,
+	})
+
+	return kitchenOrder, nil
+}
+
+func (r *mockPrintersRepo) ReleaseKitchenItems(
+	ctx context.Context,
+	orderItemIDs []uuid.UUID,
+) error {
+	if v, ok := ctx.Value(CtxFailReleaseKitchenItems).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, orderItemID := range orderItemIDs {
+		delete(r.sent, orderItemID)
+	}
+
+	return nil
+}
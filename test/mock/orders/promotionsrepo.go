@@ -0,0 +1,232 @@
+package orders
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//nolint:gochecknoglobals
+var (
+	testPromoCodeID     = uuid.MustParse("c0c0c0c0-c0c0-4c0c-8c0c-c0c0c0c0c0c0")
+	testPromotionRuleID = uuid.MustParse("d0d0d0d0-d0d0-4d0d-8d0d-d0d0d0d0d0d0")
+)
+
+type mockPromotionsRepo struct {
+	mu sync.Mutex
+	// applied holds promo code applied to each order
+	applied map[uuid.UUID]uuid.UUID
+}
+
+// NewMockPromotionsRepo returns promotions repo where test restaurant has promo codes
+// SAVE50 (50% off), EXPIRED, BIGSPEND (minimum spend higher than test order's total)
+// and USEDUP that reached its usage limit.
+func NewMockPromotionsRepo() *mockPromotionsRepo { //nolint:revive
+	return &mockPromotionsRepo{
+		mu:      sync.Mutex{},
+		applied: make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (r *mockPromotionsRepo) GetPromoCodes(
+	ctx context.Context,
+	_ uuid.UUID,
+) ([]*dto.PromoCodeDto, error) {
+	if v, ok := ctx.Value(CtxFailGetPromoCodes).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.PromoCodeDto{newTestPromoCode("SAVE50")}, nil
+}
+
+func (r *mockPromotionsRepo) GetPromoCodeByCode(
+	_ context.Context,
+	restaurantID uuid.UUID,
+	code string,
+) (*dto.PromoCodeDto, error) {
+	if restaurantID != testRestaurantID {
+		return nil, repository.ErrPromoCodeDoesNotExist
+	}
+
+	promoCode := newTestPromoCode(code)
+
+	switch code {
+	case "SAVE50", "USEDUP":
+	case "EXPIRED":
+		validUntil := testDateTime
+		promoCode.ValidUntil = &validUntil
+	case "BIGSPEND":
+		promoCode.MinSpendInCents = 100 * testAmount //nolint:mnd
+	default:
+		return nil, repository.ErrPromoCodeDoesNotExist
+	}
+
+	return promoCode, nil
+}
+
+func (r *mockPromotionsRepo) CreatePromoCode(
+	ctx context.Context,
+	reqDto *dto.CreatePromoCodeRequestDto,
+) (*dto.PromoCodeDto, error) {
+	if v, ok := ctx.Value(CtxFailCreatePromoCode).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if reqDto.Code == "SAVE50" {
+		return nil, repository.ErrPromoCodeAlreadyExists
+	}
+
+	return &dto.PromoCodeDto{
+		ID:              uuid.New(),
+		RestaurantID:    reqDto.RestaurantID,
+		Code:            reqDto.Code,
+		DiscountType:    reqDto.DiscountType,
+		DiscountValue:   reqDto.DiscountValue,
+		MinSpendInCents: reqDto.MinSpendInCents,
+		ValidFrom:       reqDto.ValidFrom,
+		ValidUntil:      reqDto.ValidUntil,
+		MaxUses:         reqDto.MaxUses,
+		UsesCount:       0,
+		CreatedAt:       testDateTime,
+	}, nil
+}
+
+func (r *mockPromotionsRepo) DeletePromoCode(
+	_ context.Context,
+	_, promoCodeID uuid.UUID,
+) error {
+	if promoCodeID != testPromoCodeID {
+		return repository.ErrPromoCodeDoesNotExist
+	}
+
+	return nil
+}
+
+func (r *mockPromotionsRepo) GetPromotionRules(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.PromotionRuleDto, error) {
+	if v, ok := ctx.Value(CtxFailGetPromotionRules).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.PromotionRuleDto{
+		{
+			ID:           testPromotionRuleID,
+			RestaurantID: restaurantID,
+			Name:         "Happy hour",
+			RuleType:     db.OrdersPromotionRuleTypeBuyXGetY,
+			CategoryID:   nil,
+			PercentOff:   0,
+			BuyQuantity:  1,
+			FreeQuantity: 1,
+			StartsAt:     "17:00",
+			EndsAt:       "19:00",
+			Timezone:     "UTC",
+			IsActive:     true,
+			CreatedAt:    testDateTime,
+		},
+	}, nil
+}
+
+func (r *mockPromotionsRepo) CreatePromotionRule(
+	ctx context.Context,
+	reqDto *dto.CreatePromotionRuleRequestDto,
+) (*dto.PromotionRuleDto, error) {
+	if v, ok := ctx.Value(CtxFailCreatePromotionRule).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return &dto.PromotionRuleDto{
+		ID:           uuid.New(),
+		RestaurantID: reqDto.RestaurantID,
+		Name:         reqDto.Name,
+		RuleType:     reqDto.RuleType,
+		CategoryID:   reqDto.CategoryID,
+		PercentOff:   reqDto.PercentOff,
+		BuyQuantity:  reqDto.BuyQuantity,
+		FreeQuantity: reqDto.FreeQuantity,
+		StartsAt:     reqDto.StartsAt,
+		EndsAt:       reqDto.EndsAt,
+		Timezone:     reqDto.Timezone,
+		IsActive:     true,
+		CreatedAt:    testDateTime,
+	}, nil
+}
+
+func (r *mockPromotionsRepo) DeletePromotionRule(
+	_ context.Context,
+	_, ruleID uuid.UUID,
+) error {
+	if ruleID != testPromotionRuleID {
+		return repository.ErrPromotionRuleDoesNotExist
+	}
+
+	return nil
+}
+
+func (r *mockPromotionsRepo) ApplyPromoCodeToOrder(
+	ctx context.Context,
+	orderID, promoCodeID uuid.UUID,
+) error {
+	if v, ok := ctx.Value(CtxFailApplyPromoCodeToOrder).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	if promoCodeID == promoCodeIDFor("USEDUP") {
+		return repository.ErrPromoCodeUsedUp
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.applied[orderID] = promoCodeID
+
+	return nil
+}
+
+func (r *mockPromotionsRepo) RemovePromoCodeFromOrder(
+	_ context.Context,
+	orderID uuid.UUID,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.applied[orderID]; !ok {
+		return repository.ErrOrderHasNoPromoCode
+	}
+
+	delete(r.applied, orderID)
+
+	return nil
+}
+
+func newTestPromoCode(code string) *dto.PromoCodeDto {
+	return &dto.PromoCodeDto{
+		ID:              promoCodeIDFor(code),
+		RestaurantID:    testRestaurantID,
+		Code:            code,
+		DiscountType:    db.OrdersDiscountTypePercentage,
+		DiscountValue:   50, //nolint:mnd
+		MinSpendInCents: 0,
+		ValidFrom:       nil,
+		ValidUntil:      nil,
+		MaxUses:         nil,
+		UsesCount:       0,
+		CreatedAt:       testDateTime.Add(-time.Hour),
+	}
+}
+
+// promoCodeIDFor returns stable id of the test promo code, SAVE50 has testPromoCodeID.
+func promoCodeIDFor(code string) uuid.UUID {
+	if code == "SAVE50" {
+		return testPromoCodeID
+	}
+
+	return uuid.NewSHA1(testPromoCodeID, []byte(code))
+}
@@ -0,0 +1,67 @@
+package orders
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+
+	"github.com/google/uuid"
+)
+
+type mockPaymentProvidersRepo struct{}
+
+func NewMockPaymentProvidersRepo() *mockPaymentProvidersRepo { //nolint:revive
+	return &mockPaymentProvidersRepo{}
+}
+
+func (r *mockPaymentProvidersRepo) GetRestaurantPaymentProviders(
+	_ context.Context,
+	restaurantID uuid.UUID,
+) ([]*dto.RestaurantPaymentProviderDto, error) {
+	if restaurantID != testRestaurantID {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.RestaurantPaymentProviderDto{
+		{
+			RestaurantID: testRestaurantID,
+			Provider:     db.OrdersPaymentProviderStripe,
+			Credentials: &dto.ProviderCredentialsDto{
+				SecretKey:     "sk_test",
+				WebhookSecret: "whsec_test",
+			},
+			IsDefault: true,
+			UpdatedAt: testDateTime,
+		},
+	}, nil
+}
+
+func (r *mockPaymentProvidersRepo) SaveRestaurantPaymentProvider(
+	ctx context.Context,
+	reqDto *dto.SetPaymentProviderRequestDto,
+) (*dto.RestaurantPaymentProviderDto, error) {
+	if v, ok := ctx.Value(CtxFailSavePaymentProvider).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return &dto.RestaurantPaymentProviderDto{
+		RestaurantID: reqDto.RestaurantID,
+		Provider:     reqDto.Provider,
+		Credentials:  reqDto.Credentials,
+		IsDefault:    reqDto.IsDefault,
+		UpdatedAt:    testDateTime,
+	}, nil
+}
+
+func (r *mockPaymentProvidersRepo) DeleteRestaurantPaymentProvider(
+	_ context.Context,
+	_ uuid.UUID,
+	provider db.OrdersPaymentProvider,
+) error {
+	if provider != db.OrdersPaymentProviderStripe {
+		return repository.ErrPaymentProviderDoesNotExist
+	}
+
+	return nil
+}
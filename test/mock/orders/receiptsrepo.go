@@ -0,0 +1,139 @@
+package orders
+
+import (
+	"context"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+//nolint:gochecknoglobals
+var (
+	testReceiptID         = uuid.MustParse("e0e0e0e0-e0e0-4e0e-8e0e-e0e0e0e0e0e0")
+	testRestaurantAddress = "Brivibas iela 1, Riga"
+	testReceiptNumber     = 42
+	testTaxRate           = 21.0
+)
+
+type mockReceiptsRepo struct {
+	mu       sync.Mutex
+	settings *dto.ReceiptSettingsDto
+	// contents holds contents saved for each receipt
+	contents map[uuid.UUID]*dto.ReceiptContentsDto
+}
+
+// NewMockReceiptsRepo returns receipts repo where test restaurant has its tax rate
+// configured and every completed order has receipt with the same number. Receipts have no
+// contents until they are saved.
+func NewMockReceiptsRepo() *mockReceiptsRepo { //nolint:revive
+	return &mockReceiptsRepo{
+		mu: sync.Mutex{},
+		settings: &dto.ReceiptSettingsDto{
+			RestaurantID: testRestaurantID,
+			TaxRate:      testTaxRate,
+			UpdatedAt:    &testDateTime,
+		},
+		contents: make(map[uuid.UUID]*dto.ReceiptContentsDto),
+	}
+}
+
+func (r *mockReceiptsRepo) GetReceiptSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (*dto.ReceiptSettingsDto, error) {
+	if v, ok := ctx.Value(CtxFailGetReceiptSettings).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if restaurantID != testRestaurantID {
+		return nil, repository.ErrReceiptSettingsDoNotExist
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings := *r.settings
+
+	return &settings, nil
+}
+
+func (r *mockReceiptsRepo) SaveReceiptSettings(
+	ctx context.Context,
+	reqDto *dto.SetReceiptSettingsRequestDto,
+) (*dto.ReceiptSettingsDto, error) {
+	if v, ok := ctx.Value(CtxFailSaveReceiptSettings).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = &dto.ReceiptSettingsDto{
+		RestaurantID: reqDto.RestaurantID,
+		TaxRate:      *reqDto.TaxRate,
+		UpdatedAt:    &testDateTime,
+	}
+
+	settings := *r.settings
+
+	return &settings, nil
+}
+
+func (r *mockReceiptsRepo) GetReceipt(
+	ctx context.Context,
+	orderID uuid.UUID,
+) (*dto.IssuedReceiptDto, error) {
+	if v, ok := ctx.Value(CtxFailGetReceipt).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if v, ok := ctx.Value(CtxReceiptNotIssued).(bool); ok && v {
+		return nil, repository.ErrReceiptDoesNotExist
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &dto.IssuedReceiptDto{
+		ID:                testReceiptID,
+		OrderID:           orderID,
+		RestaurantID:      testRestaurantID,
+		Number:            testReceiptNumber,
+		TaxRate:           r.settings.TaxRate,
+		IssuedAt:          testDateTime,
+		Contents:          r.contents[testReceiptID],
+		RestaurantName:    testRestaurantName,
+		RestaurantAddress: testRestaurantAddress,
+	}, nil
+}
+
+func (r *mockReceiptsRepo) SaveReceiptContents(
+	ctx context.Context,
+	receiptID uuid.UUID,
+	contents *dto.ReceiptContentsDto,
+) error {
+	if v, ok := ctx.Value(CtxFailSaveReceiptContents).(bool); ok && v {
+		return ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.contents[receiptID]; !ok {
+		r.contents[receiptID] = contents
+	}
+
+	return nil
+}
+
+// SavedContents returns contents saved for the receipt and whether any were saved.
+func (r *mockReceiptsRepo) SavedContents(receiptID uuid.UUID) (*dto.ReceiptContentsDto, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contents, ok := r.contents[receiptID]
+
+	return contents, ok
+}
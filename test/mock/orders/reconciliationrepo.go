@@ -0,0 +1,52 @@
+package orders
+
+import (
+	"context"
+	"golang-dining-ordering/services/orders/dto"
+
+	"github.com/google/uuid"
+)
+
+type mockReconciliationRepo struct{}
+
+func NewMockReconciliationRepo() *mockReconciliationRepo { //nolint:revive
+	return &mockReconciliationRepo{}
+}
+
+// GetProviderPayments returns test payment, payment provider has no charge for and payment
+// saved with a different amount than provider's charge.
+func (r *mockReconciliationRepo) GetProviderPayments(
+	ctx context.Context,
+	_ *dto.ReconciliationRequestDto,
+) ([]*dto.ReconciliationPaymentDto, error) {
+	if v, ok := ctx.Value(CtxFailGetProviderPayments).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	return []*dto.ReconciliationPaymentDto{
+		{
+			ID:                testPaymentID,
+			OrderID:           testOrderID,
+			ProviderPaymentID: testProviderPaymentID,
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+		{
+			ID:                uuid.New(),
+			OrderID:           testOrderID,
+			ProviderPaymentID: testMissingProviderPaymentID,
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+		{
+			ID:                uuid.New(),
+			OrderID:           testOrderID,
+			ProviderPaymentID: testMismatchedProviderPaymentID,
+			AmountInCents:     testAmount,
+			Currency:          testCurrency,
+			CreatedAt:         testDateTime,
+		},
+	}, nil
+}
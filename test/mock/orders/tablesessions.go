@@ -0,0 +1,19 @@
+package orders
+
+import (
+	"golang-dining-ordering/services/orders/sessions"
+	"time"
+)
+
+// TestTableSessionSecret is the secret table sessions of NewTableSessions are signed with.
+const TestTableSessionSecret = "test-table-session-secret"
+
+// NewTableSessions returns table sessions valid for an hour.
+func NewTableSessions() *sessions.TableSessions {
+	tableSessions, err := sessions.NewTableSessions(TestTableSessionSecret, time.Hour)
+	if err != nil {
+		panic(err)
+	}
+
+	return tableSessions
+}
@@ -0,0 +1,141 @@
+package orders
+
+import (
+	"context"
+	db "golang-dining-ordering/services/orders/db/generated"
+	"golang-dining-ordering/services/orders/dto"
+	"golang-dining-ordering/services/orders/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//nolint:gochecknoglobals
+var (
+	testFirstWaiterID  = uuid.MustParse("a1a1a1a1-a1a1-4a1a-8a1a-a1a1a1a1a1a1")
+	testSecondWaiterID = uuid.MustParse("b2b2b2b2-b2b2-4b2b-8b2b-b2b2b2b2b2b2")
+	testThirdWaiterID  = uuid.MustParse("c3c3c3c3-c3c3-4c3c-8c3c-c3c3c3c3c3c3")
+	testShiftStart     = time.Date(2025, time.December, 5, 8, 0, 0, 0, time.UTC)
+)
+
+type mockTipsRepo struct {
+	mu       sync.Mutex
+	settings *dto.TipSettingsDto
+}
+
+// NewMockTipsRepo returns tips repo where test restaurant has its tips configured,
+// other restaurants use the defaults.
+func NewMockTipsRepo() *mockTipsRepo { //nolint:revive
+	return &mockTipsRepo{
+		mu: sync.Mutex{},
+		settings: &dto.TipSettingsDto{
+			RestaurantID:     testRestaurantID,
+			Presets:          []float64{5, 12.5},
+			PoolRule:         db.OrdersTipPoolRuleEqual,
+			ShiftLengthHours: 8, //nolint:mnd
+			UpdatedAt:        &testDateTime,
+		},
+	}
+}
+
+func (r *mockTipsRepo) GetTipSettings(
+	ctx context.Context,
+	restaurantID uuid.UUID,
+) (*dto.TipSettingsDto, error) {
+	if v, ok := ctx.Value(CtxFailGetTipSettings).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	if restaurantID != testRestaurantID {
+		return nil, repository.ErrTipSettingsDoNotExist
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings := *r.settings
+
+	return &settings, nil
+}
+
+func (r *mockTipsRepo) SaveTipSettings(
+	ctx context.Context,
+	reqDto *dto.SetTipSettingsRequestDto,
+) (*dto.TipSettingsDto, error) {
+	if v, ok := ctx.Value(CtxFailSaveTipSettings).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = &dto.TipSettingsDto{
+		RestaurantID:     reqDto.RestaurantID,
+		Presets:          reqDto.Presets,
+		PoolRule:         reqDto.PoolRule,
+		ShiftLengthHours: reqDto.ShiftLengthHours,
+		UpdatedAt:        &testDateTime,
+	}
+
+	settings := *r.settings
+
+	return &settings, nil
+}
+
+// GetCompletedOrdersWaiters returns three orders completed in the same shift: one served by
+// first and second waiter, one by first waiter only and one nobody was assigned to. Third
+// waiter served an order without tip in the next shift.
+func (r *mockTipsRepo) GetCompletedOrdersWaiters(
+	ctx context.Context,
+	_ *dto.TipReportRequestDto,
+) ([]*dto.CompletedOrderWaiterDto, error) {
+	if v, ok := ctx.Value(CtxFailGetCompletedOrdersWaiters).(bool); ok && v {
+		return nil, ErrRepoFailed
+	}
+
+	sharedOrderID := uuid.New()
+	firstWaiterOrderID := uuid.New()
+	unassignedOrderID := uuid.New()
+	nextShiftOrderID := uuid.New()
+
+	completedAt := testShiftStart.Add(2 * time.Hour)
+
+	return []*dto.CompletedOrderWaiterDto{
+		{
+			OrderID:          sharedOrderID,
+			TipAmountInCents: 100, //nolint:mnd
+			CompletedAt:      completedAt,
+			UserID:           &testFirstWaiterID,
+			AssignedAt:       completedAt.Add(-time.Hour),
+		},
+		{
+			OrderID:          sharedOrderID,
+			TipAmountInCents: 100, //nolint:mnd
+			CompletedAt:      completedAt,
+			UserID:           &testSecondWaiterID,
+			AssignedAt:       completedAt.Add(-30 * time.Minute),
+		},
+		{
+			OrderID:          firstWaiterOrderID,
+			TipAmountInCents: 50, //nolint:mnd
+			CompletedAt:      completedAt.Add(10 * time.Minute),
+			UserID:           &testFirstWaiterID,
+			AssignedAt:       completedAt.Add(-5 * time.Minute),
+		},
+		{
+			OrderID:          unassignedOrderID,
+			TipAmountInCents: 30, //nolint:mnd
+			CompletedAt:      completedAt.Add(20 * time.Minute),
+			UserID:           nil,
+			AssignedAt:       time.Time{},
+		},
+		{
+			OrderID:          nextShiftOrderID,
+			TipAmountInCents: 0,
+			CompletedAt:      testShiftStart.Add(9 * time.Hour),
+			UserID:           &testThirdWaiterID,
+			AssignedAt:       testShiftStart.Add(8 * time.Hour),
+		},
+	}, nil
+}